@@ -24,6 +24,10 @@ Package main нь Template Backend API-ийн entry point юм.
 Эсвэл:
 
 	make run
+
+Migration (migrate.go):
+
+	go run ./cmd/server migrate up
 */
 package main
 
@@ -48,11 +52,12 @@ import (
 	"time"
 
 	// Internal packages
-	appdep "templatev25/internal/app"  // Dependency injection container
-	"templatev25/internal/db"          // Database connection (GORM + PostgreSQL)
-	"templatev25/internal/http/router" // HTTP route definitions
-	"templatev25/internal/middleware"  // HTTP middlewares
-	"templatev25/internal/repository"  // Repository layer
+	appdep "templatev25/internal/app"         // Dependency injection container
+	localconfig "templatev25/internal/config" // Local config (scheduler)
+	"templatev25/internal/db"                 // Database connection (GORM + PostgreSQL)
+	"templatev25/internal/http/router"        // HTTP route definitions
+	"templatev25/internal/middleware"         // HTTP middlewares
+	"templatev25/internal/repository"         // Repository layer
 
 	// External packages
 	"git.gerege.mn/backend-packages/config"               // Configuration loading (Viper)
@@ -81,7 +86,7 @@ import (
 //  1. Configuration ачаалах (.env файл эсвэл environment variables)
 //  2. Logger үүсгэх (development/production mode)
 //  3. Observability init (Prometheus)
-//  4. Database холболт
+//  4. Database холболт (DB_AUTO_MIGRATE бол migration)
 //  5. Swagger setup
 //  6. Fiber app setup
 //  7. Middlewares setup
@@ -102,6 +107,14 @@ func main() {
 	// ============================================================
 	logg := logger.New(cfg.Server.ENV)
 
+	// `server migrate ...` нь server эхлүүлэхгүй, migration ажиллуулаад гарна
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, logg, os.Args[2:]); err != nil {
+			logg.Fatal("migrate failed", zap.Error(err))
+		}
+		return
+	}
+
 	// ============================================================
 	// STEP 3: Observability (Prometheus)
 	// ============================================================
@@ -123,6 +136,11 @@ func main() {
 		logg.Fatal("db init failed", zap.Error(err))
 	}
 
+	// DB_AUTO_MIGRATE=true бол хэрэгжээгүй migration-уудыг хэрэгжүүлнэ
+	if err := autoMigrate(cfg, gormDB, logg); err != nil {
+		logg.Fatal("auto migrate failed", zap.Error(err))
+	}
+
 	// ============================================================
 	// STEP 5: Swagger documentation тохируулах
 	// ============================================================
@@ -169,6 +187,12 @@ func main() {
 	// ============================================================
 	router.MapV1(app, deps)
 
+	// JOB_SCHEDULER_ENABLED=false бол энэ instance хуваарийн дагуу ажиллуулахгүй
+	// (гараар trigger хийх боломжтой хэвээр)
+	if localconfig.LoadSchedulerConfig().Enabled {
+		deps.Scheduler.Start()
+	}
+
 	// ============================================================
 	// STEP 11: Server эхлүүлэх (non-blocking)
 	// ============================================================
@@ -193,6 +217,8 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// WebSocket/SSE холболтууд хаагдахгүй бол shutdown timeout хүртэл хүлээнэ
+	deps.Realtime.Close()
 	if err := app.ShutdownWithContext(ctx); err != nil {
 		log.Println("server shutdown error:", err)
 	}
@@ -200,8 +226,16 @@ func main() {
 	// ============================================================
 	// STEP 14: Resources cleanup
 	// ============================================================
+	// Scheduler-ийг DB хаахаас өмнө зогсооно (cancelled төлөв бичигдэнэ)
+	deps.Scheduler.Stop()
+	// Эхэлсэн email/SMS хүргэлт дуусч delivery log бичигдэнэ
+	deps.Service.NotificationDispatcher.Stop()
+	// Гадны socket service руу эхэлсэн хүргэлт дуусна
+	deps.Service.Notification.Stop()
 	if sqlDB, err := gormDB.DB(); err == nil {
 		_ = sqlDB.Close()
 	}
 	authCache.Stop()
+	deps.PermInvalidator.Stop()
+	deps.RealtimeBroker.Stop()
 }
@@ -0,0 +1,222 @@
+// Package main provides implementation for main
+//
+// File: migrate.go
+// Description: `migrate` subcommand and startup auto-migration
+/*
+Ашиглалт:
+
+	server migrate up                 # Хэрэгжээгүй бүх migration
+	server migrate down [n]           # Сүүлийн n migration-ийг буцаах (анхдагч 1)
+	server migrate status             # Migration бүрийн төлөв
+	server migrate baseline <version> # Гараар хэрэгжүүлсэн DB: version хүртэл тэмдэглэх
+	server migrate create <name>      # migrations/ хавтаст шинэ файл үүсгэх
+
+DB_AUTO_MIGRATE=true бол server эхлэхдээ `migrate up` ажиллуулна.
+*/
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"templatev25/internal/db"
+	"templatev25/migrations"
+
+	localconfig "templatev25/internal/config"
+
+	"git.gerege.mn/backend-packages/config"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// migrationsDir нь `migrate create`-ийн файл бичих хавтас (repo root-оос).
+const migrationsDir = "migrations"
+
+// migrationNameRe нь db.LoadMigrations-ийн хүлээн авах нэр.
+var migrationNameRe = regexp.MustCompile(`^[A-Za-z0-9_\-]+$`)
+
+// newMigrator нь embed хийсэн migration-уудаар Migrator үүсгэнэ.
+func newMigrator(cfg config.Config, gormDB *gorm.DB, log *zap.Logger) (*db.Migrator, error) {
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return nil, err
+	}
+	mcfg := localconfig.LoadMigrateConfig()
+	return db.NewMigrator(sqlDB, migrations.FS, db.MigratorOptions{
+		Schema: cfg.DB.Schema,
+		Table:  mcfg.Table,
+	}, log)
+}
+
+// autoMigrate нь DB_AUTO_MIGRATE идэвхтэй бол хэрэгжээгүй migration-уудыг хэрэгжүүлнэ.
+// Олон instance зэрэг эхэлбэл advisory lock-оор нэг нь л ажиллуулна.
+func autoMigrate(cfg config.Config, gormDB *gorm.DB, log *zap.Logger) error {
+	if !localconfig.LoadMigrateConfig().AutoMigrate {
+		return nil
+	}
+	m, err := newMigrator(cfg, gormDB, log)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	applied, err := m.Up(ctx)
+	if err != nil {
+		return err
+	}
+	log.Info("auto_migrate_done", zap.Int("applied", len(applied)))
+	return nil
+}
+
+// runMigrate нь `migrate` subcommand-ийг гүйцэтгэнэ.
+func runMigrate(cfg config.Config, log *zap.Logger, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate up | down [n] | status | baseline <version> | create <name>")
+	}
+
+	// create нь database шаардахгүй
+	if args[0] == "create" {
+		if len(args) < 2 {
+			return errors.New("usage: migrate create <name>")
+		}
+		path, err := createMigration(migrationsDir, args[1], cfg.DB.Schema)
+		if err != nil {
+			return err
+		}
+		fmt.Println("created", path)
+		return nil
+	}
+
+	gormDB, err := db.NewPostgres(cfg)
+	if err != nil {
+		return fmt.Errorf("db init failed: %w", err)
+	}
+	defer func() {
+		if sqlDB, err := gormDB.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	}()
+
+	m, err := newMigrator(cfg, gormDB, log)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := m.Up(ctx)
+		printMigrations("applied", applied)
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid step count %q", args[1])
+			}
+		}
+		reverted, err := m.Down(ctx, steps)
+		printMigrations("reverted", reverted)
+		return err
+
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		printStatus(statuses)
+		return nil
+
+	case "baseline":
+		if len(args) < 2 {
+			return errors.New("usage: migrate baseline <version>")
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		marked, err := m.Baseline(ctx, version)
+		printMigrations("marked", marked)
+		return err
+	}
+	return fmt.Errorf("unknown migrate command %q", args[0])
+}
+
+func printMigrations(action string, items []db.Migration) {
+	for _, mig := range items {
+		fmt.Printf("%s %03d_%s\n", action, mig.Version, mig.Name)
+	}
+	if len(items) == 0 {
+		fmt.Println("nothing to do")
+	}
+}
+
+func printStatus(statuses []db.MigrationStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+	for _, st := range statuses {
+		state := "pending"
+		switch {
+		case st.Missing:
+			state = "applied (no file)"
+		case st.Drift:
+			state = "applied (checksum drift)"
+		case st.Applied:
+			state = "applied"
+		}
+		appliedAt := ""
+		if st.AppliedAt != nil {
+			appliedAt = st.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", st.Version, st.Name, state, appliedAt)
+	}
+	_ = w.Flush()
+}
+
+// createMigration нь дараагийн дугаартай хоосон migration файл үүсгэнэ.
+func createMigration(dir, name, schema string) (string, error) {
+	if !migrationNameRe.MatchString(name) {
+		return "", fmt.Errorf("invalid migration name %q (letters, digits, _ and - only)", name)
+	}
+	existing, err := db.LoadMigrations(os.DirFS(dir))
+	if err != nil {
+		return "", err
+	}
+	var next int64 = 1
+	if len(existing) > 0 {
+		next = existing[len(existing)-1].Version + 1
+	}
+
+	file := fmt.Sprintf("%03d_%s.sql", next, name)
+	content := fmt.Sprintf(`-- ============================================================
+-- Migration: %s
+-- Description:
+-- Database: gerege_db
+-- Schema: %s
+-- ============================================================
+
+-- +migrate Up
+SET search_path TO %s, public;
+
+
+-- +migrate Down
+SET search_path TO %s, public;
+
+`, file, schema, schema, schema)
+
+	path := filepath.Join(dir, file)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
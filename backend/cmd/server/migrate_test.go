@@ -0,0 +1,36 @@
+// Package main provides the application entry point
+//
+// File: migrate_test.go
+// Description: Unit tests for the migrate subcommand
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"templatev25/internal/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateMigration(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "007_existing.sql"), []byte("SELECT 1;"), 0o644))
+
+	path, err := createMigration(dir, "add_widgets", "template_backend")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "008_add_widgets.sql"), path)
+
+	got, err := db.LoadMigrations(os.DirFS(dir))
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Contains(t, got[1].Up, "SET search_path TO template_backend, public;")
+	assert.Contains(t, got[1].Down, "SET search_path TO template_backend, public;")
+}
+
+func TestCreateMigration_InvalidName(t *testing.T) {
+	_, err := createMigration(t.TempDir(), "bad name.sql", "template_backend")
+	assert.Error(t, err)
+}
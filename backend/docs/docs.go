@@ -5892,6 +5892,13 @@ const docTemplate = `{
                     "maxLength": 255,
                     "minLength": 2
                 },
+                "parent_ids": {
+                    "description": "ParentIDs нь permission өвлүүлэх эцэг role-ууд.\nUpdate-д nil бол өөрчлөхгүй, хоосон массив бол бүгдийг хасна.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
                 "system_id": {
                     "type": "integer"
                 }
@@ -5941,6 +5948,13 @@ const docTemplate = `{
                     "maxLength": 255,
                     "minLength": 2
                 },
+                "parent_ids": {
+                    "description": "ParentIDs нь permission өвлүүлэх эцэг role-ууд.\nUpdate-д nil бол өөрчлөхгүй, хоосон массив бол бүгдийг хасна.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
                 "system_id": {
                     "type": "integer"
                 }
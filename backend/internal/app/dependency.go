@@ -44,14 +44,29 @@ Dependency Graph:
 package app
 
 import (
+	"context"
+	"crypto/rand"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"git.gerege.mn/backend-packages/config"     // Application configuration
 	"git.gerege.mn/backend-packages/sso-client" // SSO client
 	"templatev25/internal/auth"                 // Permission cache
+	"templatev25/internal/circuitbreaker"       // Retry config
 	localconfig "templatev25/internal/config"   // Local auth config
+	"templatev25/internal/domain"               // Push provider names
+	"templatev25/internal/imagevariant"         // Image thumbnails and resized variants
+	"templatev25/internal/mail"                 // Email delivery
+	"templatev25/internal/notify"               // Notification channel providers
+	"templatev25/internal/realtime"             // WebSocket event hub
 	"templatev25/internal/repository"           // Data access layer
+	"templatev25/internal/scheduler"            // Scheduled job runner
 	"templatev25/internal/service"              // Business logic layer
+	"templatev25/internal/signedurl"            // Signed private file links
+	"templatev25/internal/storage"              // File storage backends
+	"templatev25/internal/upload"               // Upload validation and scanning
+	"templatev25/internal/webauthn"             // WebAuthn relying party
 
 	"github.com/redis/go-redis/v9" // Redis client
 	"go.uber.org/zap"              // Structured logging
@@ -100,6 +115,25 @@ type Dependencies struct {
 	// auth.RequirePermission middleware-д дамжуулна.
 	PermCache *auth.PermissionCache
 
+	// PermInvalidator нь permission cache invalidation-ийг
+	// Redis pub/sub-аар бүх instance руу түгээнэ.
+	// Shutdown үед Stop() дуудна.
+	PermInvalidator *auth.RedisCacheInvalidator
+
+	// Scheduler нь scheduled_jobs-ийн job runner.
+	// JOB_SCHEDULER_ENABLED үед main.go Start() дуудна, shutdown үед Stop().
+	Scheduler *scheduler.Scheduler
+
+	// Realtime нь нэвтэрсэн хэрэглэгчдийн WebSocket/SSE холболтууд руу
+	// event түгээх hub (chat, notification).
+	// Shutdown үед Close() дуудаж урт холболтуудыг хаана.
+	Realtime *realtime.Hub
+
+	// RealtimeBroker нь realtime event-ийг Redis pub/sub-аар
+	// бусад instance-уудын hub руу түгээнэ.
+	// Shutdown үед Stop() дуудна.
+	RealtimeBroker *realtime.RedisBroker
+
 	// Repo нь бүх repository-уудыг агуулна.
 	// Database CRUD operations.
 	Repo *RepoContainer
@@ -138,6 +172,14 @@ type RepoContainer struct {
 	// Table: user_roles (many-to-many)
 	UserRole repository.UserRoleRepository
 
+	// UserSettings нь хэрэглэгчийн тохиргооны CRUD operations.
+	// Table: user_settings
+	UserSettings repository.UserSettingsRepository
+
+	// UserDevice нь хэрэглэгчийн төхөөрөмж, push token-ий бүртгэл.
+	// Table: user_devices
+	UserDevice repository.UserDeviceRepository
+
 	// Auth нь local authentication CRUD operations.
 	// Tables: user_credentials, user_mfa_totp, sessions, login_history, etc.
 	Auth repository.AuthRepository
@@ -220,10 +262,26 @@ type RepoContainer struct {
 	// Table: public_files
 	PublicFile repository.PublicFileRepository
 
+	// File нь upload бүрийн бүртгэл (нийтийн, хувийн файлууд; SHA-256, хэмжээ, MIME, эзэмшигч).
+	// Table: files
+	File repository.FileRepository
+
+	// FileBlob нь SHA-256-аар dedup хийсэн агуулга, reference тоо.
+	// Table: file_blobs
+	FileBlob repository.FileBlobRepository
+
+	// FileUpload нь resumable (tus) upload-ийн явц, хэсгүүд.
+	// Table: file_uploads, file_upload_chunks
+	FileUpload repository.FileUploadRepository
+
 	// Notification нь мэдэгдлийн CRUD operations.
 	// Table: notifications
 	Notification repository.NotificationRepository
 
+	// NotificationDelivery нь мэдэгдлийн хүлээн авагч, сувгийн хүргэлтийн бүртгэл.
+	// Tables: users, user_settings, notification_deliveries
+	NotificationDelivery repository.NotificationDeliveryRepository
+
 	// News нь мэдээний CRUD operations.
 	// Table: news
 	News repository.NewsRepository
@@ -232,9 +290,17 @@ type RepoContainer struct {
 	// Table: chat_items
 	ChatItem repository.ChatItemRepository
 
+	// ChatRoom нь chat өрөө, гишүүнчлэл, мессежийн CRUD operations.
+	// Tables: chat_rooms, chat_room_members, chat_messages
+	ChatRoom repository.ChatRoomRepository
+
 	// APILog нь API log-ийн CRUD operations.
 	// Table: logs
 	APILog repository.APILogRepository
+
+	// Job нь scheduled job-ийн CRUD operations.
+	// Tables: scheduled_jobs, job_executions
+	Job repository.JobRepository
 }
 
 // ============================================================
@@ -266,6 +332,14 @@ type ServiceContainer struct {
 	// - Permission checking
 	UserRole service.UserRoleService
 
+	// UserSettings нь хэрэглэгчийн тохиргооны business logic.
+	// - Defaults when no row exists
+	// - custom_settings JSON schema validation
+	UserSettings *service.UserSettingsService
+
+	// UserDevice нь push notification-ий төхөөрөмжийн бүртгэл.
+	UserDevice *service.UserDeviceService
+
 	// Auth нь local authentication service.
 	// - Login, MFA, password management
 	// - Session management
@@ -360,21 +434,45 @@ type ServiceContainer struct {
 	// - Access control
 	PublicFile *service.PublicFileService
 
+	// PrivateFile нь хувийн файлын business logic.
+	// - Эзэмшигч, эрхтэй хэрэглэгч эсвэл signed URL-аар татах
+	PrivateFile *service.PrivateFileService
+
+	// ResumableUpload нь tus protocol-оор хэсэгчлэн орох том файлууд.
+	// - Тасарсан upload-ийг үргэлжлүүлэх, checksum шалгах
+	// - Дуусмагц PublicFile болгох
+	ResumableUpload *service.ResumableUploadService
+
 	// Notification нь мэдэгдлийн business logic.
 	// - Send notifications
 	// - Mark as read
 	Notification *service.NotificationService
 
+	// NotificationDispatcher нь мэдэгдлийг email, SMS, push сувгаар хүргэнэ.
+	// - Per-user channel settings (user_settings)
+	// - Delivery log
+	NotificationDispatcher *service.NotificationDispatcher
+
 	// News нь мэдээний business logic.
 	News *service.NewsService
 
 	// ChatItem нь chat item-ийн business logic.
 	ChatItem *service.ChatItemService
 
+	// ChatRoom нь realtime chat-ийн business logic.
+	// - Rooms, membership roles, message history
+	// - Unread counts, WebSocket delivery
+	ChatRoom *service.ChatRoomService
+
 	// APILog нь API log-ийн business logic.
 	// - API log listing with pagination
 	APILog service.APILogService
 
+	// Job нь scheduled job-ийн business logic.
+	// - Job list, execution history
+	// - Manual trigger, pause/resume
+	Job *service.JobService
+
 	// ============================================================
 	// EXTERNAL INTEGRATION SERVICES
 	// ============================================================
@@ -426,6 +524,8 @@ func NewDependencies(db *gorm.DB, cfg *config.Config, log *zap.Logger, authCache
 		// User & Auth
 		User:         repository.NewUserRepository(db),
 		UserRole:     repository.NewUserRoleRepository(db),
+		UserSettings: repository.NewUserSettingsRepository(db),
+		UserDevice:   repository.NewUserDeviceRepository(db),
 		Auth:         repository.NewAuthRepository(db),
 		Registration: repository.NewRegistrationRepository(db),
 
@@ -450,13 +550,19 @@ func NewDependencies(db *gorm.DB, cfg *config.Config, log *zap.Logger, authCache
 		AppServiceIconGroup: repository.NewAppServiceIconGroupRepository(db),
 
 		// Content
-		PublicFile:   repository.NewPublicFileRepository(db),
-		Notification: repository.NewNotificationRepository(db),
-		News:         repository.NewNewsRepository(db),
-		ChatItem:     repository.NewChatItemRepository(db),
+		PublicFile:           repository.NewPublicFileRepository(db),
+		File:                 repository.NewFileRepository(db),
+		FileBlob:             repository.NewFileBlobRepository(db),
+		FileUpload:           repository.NewFileUploadRepository(db),
+		Notification:         repository.NewNotificationRepository(db),
+		NotificationDelivery: repository.NewNotificationDeliveryRepository(db),
+		News:                 repository.NewNewsRepository(db),
+		ChatItem:             repository.NewChatItemRepository(db),
+		ChatRoom:             repository.NewChatRoomRepository(db),
 
 		// Logging
 		APILog: repository.NewAPILogRepository(db),
+		Job:    repository.NewJobRepository(db),
 	}
 
 	// ============================================================
@@ -467,11 +573,31 @@ func NewDependencies(db *gorm.DB, cfg *config.Config, log *zap.Logger, authCache
 	
 	// Permission service эхлээд үүсгэх (Action service-д хэрэгтэй)
 	permissionSvc := service.NewPermissionService(repo.Permission, log)
+
+	// Realtime hub (chat, notification event-ийг WebSocket/SSE-ээр түгээнэ)
+	realtimeHub := realtime.NewHub(log)
+
+	// File storage (local эсвэл S3-compatible), STORAGE_* тохиргооноос
+	storageCfg := localconfig.LoadStorageConfig()
+	fileStore := newStorage(storageCfg, storageCfg.LocalDir, storageCfg.S3Prefix, log)
+	uploadCfg := localconfig.LoadUploadConfig()
+	uploadGuard := newUploadGuard(uploadCfg, log)
+	imageVariants := newImageVariants(localconfig.LoadImageConfig(), fileStore, log)
+
+	// Хувийн файлууд нийтийнхээс тусдаа байршилд, signed URL-аар татагдана
+	privateFiles := service.NewPrivateFileService(repo.File, repo.FileBlob, newPrivateStorage(storageCfg, log), uploadGuard, newURLSigner(storageCfg, cfg.Server.ENV, log), service.PrivateFileOptions{
+		BaseURL:    storageCfg.PrivateURL,
+		Driver:     storageCfg.Driver,
+		DefaultTTL: storageCfg.SignedURLTTL,
+		MaxTTL:     storageCfg.SignedURLMaxTTL,
+	})
 	
 	svc := &ServiceContainer{
 		// User & Auth
-		User:     service.NewUserService(repo.User, cfg, log), // External API calls
-		UserRole: service.NewUserRoleService(repo.UserRole),
+		User:         service.NewUserService(repo.User, cfg, log), // External API calls
+		UserRole:     service.NewUserRoleService(repo.UserRole),
+		UserSettings: service.NewUserSettingsService(repo.UserSettings),
+		UserDevice:   service.NewUserDeviceService(repo.UserDevice),
 
 		// System & Module
 		System: service.NewSystemService(repo.System, log),
@@ -494,7 +620,11 @@ func NewDependencies(db *gorm.DB, cfg *config.Config, log *zap.Logger, authCache
 		AppServiceGroup: service.NewAppServiceIconGroup(repo.AppServiceIconGroup),
 
 		// Content
-		PublicFile:   service.NewPublicFileService(repo.PublicFile, cfg),
+		PublicFile: service.NewPublicFileService(repo.PublicFile, repo.File, repo.FileBlob, fileStore, uploadGuard, imageVariants, service.PublicFileOptions{
+			BaseURL: storageCfg.PublicURL,
+			Driver:  storageCfg.Driver,
+		}),
+		PrivateFile:  privateFiles,
 		Notification: service.NewNotificationService(repo.Notification, cfg),
 		News:         service.NewNewsService(repo.News),
 		ChatItem:     service.NewChatItemService(repo.ChatItem, log),
@@ -508,6 +638,9 @@ func NewDependencies(db *gorm.DB, cfg *config.Config, log *zap.Logger, authCache
 		Tpay:   service.NewTpayService(cfg),   // Payment API
 	}
 
+	// Resumable (tus) upload: хэсгүүд тусдаа байршилд, дуусмагц PublicFile болно
+	svc.ResumableUpload = newResumableUploads(uploadCfg, storageCfg, repo.FileUpload, svc.PublicFile, log)
+
 	// ============================================================
 	// STEP 2.5: Initialize Local Auth Services (Redis + Auth)
 	// ============================================================
@@ -525,8 +658,15 @@ func NewDependencies(db *gorm.DB, cfg *config.Config, log *zap.Logger, authCache
 	sessionStore := service.NewRedisSessionStore(redisClient, "session:", authCfg.LocalAuth.SessionTTL)
 	svc.SessionStore = sessionStore
 
+	// Create WebAuthn relying party (passkey / security key MFA)
+	relyingParty := newWebAuthn(&authCfg.LocalAuth.WebAuthn, log)
+
 	// Create Auth service (depends on repo.Auth, sessionStore, and authCfg)
-	svc.Auth = service.NewAuthService(repo.Auth, sessionStore, &authCfg.LocalAuth, log)
+	svc.Auth = service.NewAuthService(repo.Auth, sessionStore, &authCfg.LocalAuth, relyingParty, log)
+
+	// Create auth mailer (verification, password reset, welcome emails)
+	mailer := newMailer(&authCfg.Mail, cfg.Server.ENV, log)
+	authMailer := newAuthMailer(mailer, &authCfg.Mail, log)
 
 	// Create Registration service (depends on repo.Auth, repo.User, repo.Registration, svc.Auth)
 	svc.Registration = service.NewRegistrationService(
@@ -534,6 +674,7 @@ func NewDependencies(db *gorm.DB, cfg *config.Config, log *zap.Logger, authCache
 		repo.User,
 		repo.Registration,
 		svc.Auth,
+		authMailer,
 		&authCfg.LocalAuth,
 		log,
 	)
@@ -549,9 +690,45 @@ func NewDependencies(db *gorm.DB, cfg *config.Config, log *zap.Logger, authCache
 	// STEP 4: Wire up cache invalidators
 	// ============================================================
 	// Service-ууд permission өөрчлөгдөхөд cache цэвэрлэхэд ашиглана.
-	svc.Permission.SetCacheInvalidator(permCache)
-	svc.Role.SetCacheInvalidator(permCache)
-	svc.UserRole.SetCacheInvalidator(permCache)
+	// Invalidation нь Redis pub/sub-аар бусад instance-ууд руу түгээгдэнэ.
+	permInvalidator := auth.NewRedisCacheInvalidator(permCache, redisClient, authCfg.Redis.PermissionChannel, log)
+	permInvalidator.Start()
+
+	svc.Permission.SetCacheInvalidator(permInvalidator)
+	svc.Role.SetCacheInvalidator(permInvalidator)
+	svc.UserRole.SetCacheInvalidator(permInvalidator)
+
+	// ============================================================
+	// STEP 4.2: Wire up realtime delivery
+	// ============================================================
+	// Event-үүд local hub-аас гадна Redis-ээр бусад instance руу түгээгдэнэ.
+	realtimeBroker := realtime.NewRedisBroker(realtimeHub, redisClient, authCfg.Redis.RealtimeChannel, log)
+	realtimeBroker.Start()
+
+	svc.ChatRoom = service.NewChatRoomService(repo.ChatRoom, realtimeBroker, log)
+
+	// Notification: өөрийн WebSocket/SSE, тохируулсан бол гадны socket service
+	notificationCfg := localconfig.LoadNotificationConfig()
+	transports := []service.NotificationTransport{service.NewRealtimeNotificationTransport(realtimeBroker)}
+	if notificationCfg.SocketAPIURL != "" {
+		transports = append(transports, service.NewSocketNotificationTransport(notificationCfg.SocketAPIURL, notificationCfg.SocketTimeout, log))
+	}
+	svc.Notification.SetTransports(transports...)
+
+	// Email/SMS/push: хэрэглэгчийн user_settings-ийн дагуу, notification_deliveries-д бүртгэнэ
+	svc.NotificationDispatcher = service.NewNotificationDispatcher(
+		repo.NotificationDelivery,
+		log,
+		newNotificationProviders(notificationCfg, mailer, authCfg.Mail.AppName, repo.UserDevice, log)...,
+	)
+	svc.Notification.SetDispatcher(svc.NotificationDispatcher)
+
+	// ============================================================
+	// STEP 4.5: Create job scheduler
+	// ============================================================
+	// Handler-ууд энд бүртгэгдэнэ (scheduled_jobs.handler-ийн нэрээр).
+	jobScheduler := newJobScheduler(repo.Job, svc.ResumableUpload, cfg.DB.MaxOpenConn, log)
+	svc.Job = service.NewJobService(repo.Job, jobScheduler, log)
 
 	// ============================================================
 	// STEP 5: Create final Dependencies struct
@@ -567,10 +744,318 @@ func NewDependencies(db *gorm.DB, cfg *config.Config, log *zap.Logger, authCache
 		SSO: ssoclient.NewSSOClient(cfg, log, authCache),
 
 		// Permission cache (permission шалгахад ашиглана)
-		PermCache: permCache,
+		PermCache:       permCache,
+		PermInvalidator: permInvalidator,
+
+		// Job scheduler
+		Scheduler: jobScheduler,
+
+		// Realtime hub (WebSocket/SSE) ба multi-instance broker
+		Realtime:       realtimeHub,
+		RealtimeBroker: realtimeBroker,
 
 		// Layer containers
 		Repo:    repo,
 		Service: svc,
 	}
 }
+
+// newJobScheduler нь scheduler үүсгэж built-in handler-уудыг бүртгэнэ.
+// Ажиллаж буй job бүр DB холболт барих тул зэрэг ажиллах job-ийн тоог
+// pool-ийн дөрөвний нэгээс хэтрүүлэхгүй.
+func newJobScheduler(repo repository.JobRepository, uploads scheduler.UploadExpirer, maxOpenConns int, log *zap.Logger) *scheduler.Scheduler {
+	cfg := localconfig.LoadSchedulerConfig()
+	if limit := max(maxOpenConns/4, 1); maxOpenConns > 0 && cfg.MaxConcurrent > limit {
+		log.Warn("JOB_MAX_CONCURRENT is too high for the DB pool, lowering it",
+			zap.Int("requested", cfg.MaxConcurrent),
+			zap.Int("max_open_conns", maxOpenConns),
+			zap.Int("max_concurrent", limit),
+		)
+		cfg.MaxConcurrent = limit
+	}
+	s := scheduler.New(repo, scheduler.Options{
+		PollInterval:  cfg.PollInterval,
+		Timeout:       cfg.Timeout,
+		MaxConcurrent: cfg.MaxConcurrent,
+	}, log)
+	s.Register(scheduler.PurgeExecutionsHandlerName, scheduler.PurgeExecutionsHandler(repo))
+	s.Register(scheduler.ExpireUploadsHandlerName, scheduler.ExpireUploadsHandler(uploads))
+	return s
+}
+
+// newStorage нь STORAGE_DRIVER-ийн дагуу localDir (local) эсвэл s3Prefix (S3) дээр storage үүсгэнэ.
+// Тохиргоо буруу бол server эхлэхгүй (файлууд буруу газар бичигдэхээс сэргийлнэ).
+func newStorage(cfg *localconfig.StorageConfig, localDir, s3Prefix string, log *zap.Logger) storage.Storage {
+	store, err := storage.New(storage.Config{
+		Driver:   cfg.Driver,
+		LocalDir: localDir,
+		S3: storage.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			Prefix:    s3Prefix,
+			PathStyle: cfg.S3PathStyle,
+			Timeout:   cfg.S3Timeout,
+		},
+	})
+	if err != nil {
+		log.Fatal("storage init failed", zap.String("driver", cfg.Driver), zap.Error(err))
+	}
+
+	log.Info("file storage initialized", zap.String("driver", cfg.Driver), zap.String("local_dir", localDir), zap.String("s3_prefix", s3Prefix))
+	return store
+}
+
+// newPrivateStorage нь хувийн файлын storage-ийг нийтийнхээс тусдаа байршилд үүсгэнэ.
+func newPrivateStorage(cfg *localconfig.StorageConfig, log *zap.Logger) storage.Storage {
+	return newSeparateStorage(cfg, cfg.PrivateLocalDir, cfg.S3PrivatePrefix, "STORAGE_PRIVATE_LOCAL_DIR", "STORAGE_S3_PRIVATE_PREFIX", log)
+}
+
+// newSeparateStorage нь нийтийн байршлаас тусдаа storage үүсгэнэ. Ижил байршил бол
+// доторх файлууд GET /file/:name-ээр нээлттэй болох тул server эхлэхгүй.
+func newSeparateStorage(cfg *localconfig.StorageConfig, localDir, s3Prefix, localEnv, s3Env string, log *zap.Logger) storage.Storage {
+	samePlace := s3Prefix == cfg.S3Prefix
+	if cfg.Driver == storage.DriverLocal {
+		samePlace = filepath.Clean(localDir) == filepath.Clean(cfg.LocalDir)
+	}
+	if samePlace {
+		log.Fatal("storage location must not share the public location",
+			zap.String(localEnv, localDir),
+			zap.String(s3Env, s3Prefix),
+		)
+	}
+	return newStorage(cfg, localDir, s3Prefix, log)
+}
+
+// newResumableUploads нь tus upload-ийн service-ийг UPLOAD_RESUMABLE_* тохиргооноос үүсгэнэ.
+// Хэсгүүд STORAGE_CHUNK_LOCAL_DIR / STORAGE_S3_CHUNK_PREFIX-д хадгалагдана.
+func newResumableUploads(cfg *localconfig.UploadConfig, storageCfg *localconfig.StorageConfig, repo repository.FileUploadRepository, files *service.PublicFileService, log *zap.Logger) *service.ResumableUploadService {
+	maxSize, err := upload.ParseSize(cfg.ResumableMaxSize)
+	if err != nil {
+		log.Fatal("upload config invalid", zap.String("UPLOAD_RESUMABLE_MAX_SIZE", cfg.ResumableMaxSize), zap.Error(err))
+	}
+	chunks := newSeparateStorage(storageCfg, storageCfg.ChunkLocalDir, storageCfg.S3ChunkPrefix, "STORAGE_CHUNK_LOCAL_DIR", "STORAGE_S3_CHUNK_PREFIX", log)
+	return service.NewResumableUploadService(repo, chunks, files, service.ResumableUploadOptions{
+		BaseURL:         cfg.ResumableURL,
+		MaxSize:         maxSize,
+		Expiry:          cfg.ResumableExpiry,
+		FinalizeTimeout: cfg.ResumableFinalizeTimeout,
+	})
+}
+
+// newURLSigner нь хувийн файлын signed URL-ийн signer үүсгэнэ.
+// STORAGE_SIGNING_SECRET хоосон бол зөвхөн development орчинд түр secret үүсгэнэ
+// (restart хийхэд link-үүд хүчингүй болж, олон replica дээр ажиллахгүй); бусад орчинд
+// болон хэт богино бол server эхлэхгүй.
+func newURLSigner(cfg *localconfig.StorageConfig, env string, log *zap.Logger) *signedurl.Signer {
+	secret := []byte(cfg.SigningSecret)
+	if len(secret) == 0 {
+		if !isDevelopmentEnv(env) {
+			log.Fatal("STORAGE_SIGNING_SECRET is required outside development", zap.String("env", env))
+		}
+		secret = make([]byte, signedurl.MinSecretLen)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatal("signing secret generation failed", zap.Error(err))
+		}
+		log.Warn("STORAGE_SIGNING_SECRET is not set, signed file links will not survive a restart")
+	}
+	signer, err := signedurl.NewSigner(secret)
+	if err != nil {
+		log.Fatal("storage config invalid", zap.String("key", "STORAGE_SIGNING_SECRET"), zap.Error(err))
+	}
+	return signer
+}
+
+// newUploadGuard нь upload-ийн төрлийн allowlist, вирус scanner-ийг UPLOAD_* тохиргооноос үүсгэнэ.
+// Allowlist буруу бол server эхлэхгүй; clamd холбогдохгүй бол upload-ууд scan хийгдэх хүртэл татгалзагдана.
+func newUploadGuard(cfg *localconfig.UploadConfig, log *zap.Logger) *upload.Guard {
+	maxSize, err := upload.ParseSize(cfg.MaxSize)
+	if err != nil {
+		log.Fatal("upload config invalid", zap.String("UPLOAD_MAX_SIZE", cfg.MaxSize), zap.Error(err))
+	}
+	rules, err := upload.ParseTypeRules(cfg.AllowedTypes)
+	if err != nil {
+		log.Fatal("upload config invalid", zap.Strings("UPLOAD_ALLOWED_TYPES", cfg.AllowedTypes), zap.Error(err))
+	}
+
+	var scanner upload.Scanner
+	switch cfg.Scanner {
+	case upload.ScannerClamAV:
+		clam := upload.NewClamAVScanner(cfg.ClamAVAddr, cfg.ClamAVTimeout)
+		if err := clam.Ping(context.Background()); err != nil {
+			log.Error("clamd is unreachable, uploads will fail until it is up", zap.String("addr", cfg.ClamAVAddr), zap.Error(err))
+		}
+		scanner = clam
+	case upload.ScannerNone, "":
+		log.Warn("upload malware scanning is disabled (UPLOAD_SCANNER=none)")
+	default:
+		log.Fatal("upload config invalid", zap.String("UPLOAD_SCANNER", cfg.Scanner))
+	}
+
+	quarantine, err := storage.NewLocalStorage(cfg.QuarantineDir)
+	if err != nil {
+		log.Fatal("upload quarantine init failed", zap.Error(err))
+	}
+
+	return upload.NewGuard(upload.NewPolicy(maxSize, rules...), scanner, quarantine)
+}
+
+// newImageVariants нь IMAGE_VARIANTS-аас thumbnail/resize хувилбар үүсгэгчийг бүтээнэ.
+// Хувилбарууд эх файлын хажууд ижил storage-д хадгалагдана.
+func newImageVariants(cfg *localconfig.ImageConfig, store storage.Storage, log *zap.Logger) *imagevariant.Generator {
+	specs, err := imagevariant.ParseSpecs(cfg.Variants)
+	if err != nil {
+		log.Fatal("image config invalid", zap.Strings("IMAGE_VARIANTS", cfg.Variants), zap.Error(err))
+	}
+	return imagevariant.NewGenerator(store, imagevariant.Options{
+		Variants:    specs,
+		WebP:        cfg.WebP,
+		Eager:       cfg.Eager,
+		JPEGQuality: cfg.JPEGQuality,
+		MaxPixels:   cfg.MaxPixels,
+	})
+}
+
+// newMailer нь mail тохиргооноос Mailer үүсгэнэ (auth email, мэдэгдлийн email суваг).
+// Үүсгэж чадахгүй бол nil буцаана (email илгээхгүй, бусад нь үргэлжилнэ).
+// MAIL_DRIVER-ийн default нь file тул development-аас бусад орчинд анхааруулна.
+func newMailer(cfg *localconfig.MailConfig, env string, log *zap.Logger) mail.Mailer {
+	if driver := strings.ToLower(cfg.Driver); (driver == "" || driver == mail.DriverFile) && !isDevelopmentEnv(env) {
+		log.Warn("MAIL_DRIVER is file: emails are written to disk and never sent, set MAIL_DRIVER=smtp",
+			zap.String("env", env),
+			zap.String("outbox_dir", cfg.OutboxDir),
+		)
+	}
+
+	retry := circuitbreaker.DefaultRetryConfig()
+	retry.MaxRetries = cfg.MaxRetries
+	retry.InitialInterval = time.Second
+	retry.MaxInterval = 30 * time.Second
+
+	mailer, err := mail.New(mail.Config{
+		Driver:       cfg.Driver,
+		From:         cfg.From,
+		AppName:      cfg.AppName,
+		SMTPHost:     cfg.SMTPHost,
+		SMTPPort:     cfg.SMTPPort,
+		SMTPUsername: cfg.SMTPUsername,
+		SMTPPassword: cfg.SMTPPassword,
+		SMTPTLS:      cfg.SMTPTLS,
+		SMTPTimeout:  cfg.SMTPTimeout,
+		OutboxDir:    cfg.OutboxDir,
+		Retry:        retry,
+	})
+	if err != nil {
+		log.Error("mailer init failed, emails are disabled", zap.Error(err))
+		return nil
+	}
+
+	log.Info("mailer initialized", zap.String("driver", cfg.Driver))
+	return mailer
+}
+
+// isDevelopmentEnv нь Server.ENV нь local/development орчин эсэх.
+func isDevelopmentEnv(env string) bool {
+	switch strings.ToLower(env) {
+	case "", "dev", "development", "local", "test":
+		return true
+	}
+	return false
+}
+
+// newAuthMailer нь mailer дээр AuthMailer үүсгэнэ.
+// Mailer байхгүй бол nil буцаана (email илгээхгүй, бүртгэл үргэлжилнэ).
+func newAuthMailer(mailer mail.Mailer, cfg *localconfig.MailConfig, log *zap.Logger) *service.AuthMailer {
+	if mailer == nil {
+		return nil
+	}
+
+	templates, err := mail.NewTemplates(cfg.AppName)
+	if err != nil {
+		log.Error("mail templates init failed, emails are disabled", zap.Error(err))
+		return nil
+	}
+
+	return service.NewAuthMailer(mailer, templates, cfg, log)
+}
+
+// newNotificationProviders нь тохиргоонд идэвхтэй email/SMS/push provider-уудыг буцаана.
+func newNotificationProviders(cfg *localconfig.NotificationConfig, mailer mail.Mailer, appName string, devices repository.UserDeviceRepository, log *zap.Logger) []notify.Provider {
+	var providers []notify.Provider
+	if cfg.EmailEnabled && mailer != nil {
+		providers = append(providers, notify.NewEmailProvider(mailer, appName))
+	}
+	if cfg.SMSURL != "" {
+		providers = append(providers, notify.NewHTTPSMSProvider(cfg.SMSURL, cfg.SMSToken, cfg.SMSTimeout))
+	}
+	if senders := newPushSenders(cfg, log); len(senders) > 0 {
+		providers = append(providers, notify.NewPushProvider(devices, senders...))
+	}
+	return providers
+}
+
+// newPushSenders нь FCM, APNs sender-уудыг үүсгэнэ.
+// Тохиргоо буруу sender-ийг алгасна (push-гүй ажиллана).
+func newPushSenders(cfg *localconfig.NotificationConfig, log *zap.Logger) []notify.PushSender {
+	if cfg.PushFake {
+		log.Warn("push notifications are recorded in memory only (NOTIFICATION_PUSH_FAKE)")
+		return []notify.PushSender{
+			notify.NewFakePushSender(domain.PushProviderFCM),
+			notify.NewFakePushSender(domain.PushProviderAPNs),
+		}
+	}
+
+	var senders []notify.PushSender
+	if cfg.FCMCredentialsFile != "" {
+		fcmCfg, err := notify.LoadFCMCredentials(cfg.FCMCredentialsFile)
+		if err != nil {
+			log.Error("fcm init failed, android push is disabled", zap.Error(err))
+		} else {
+			fcmCfg.Timeout = cfg.PushTimeout
+			senders = append(senders, notify.NewFCMSender(fcmCfg))
+		}
+	}
+	if cfg.APNsKeyFile != "" {
+		sender, err := newAPNsSender(cfg)
+		if err != nil {
+			log.Error("apns init failed, ios push is disabled", zap.Error(err))
+		} else {
+			senders = append(senders, sender)
+		}
+	}
+	return senders
+}
+
+func newAPNsSender(cfg *localconfig.NotificationConfig) (*notify.APNsSender, error) {
+	key, err := notify.LoadAPNsKey(cfg.APNsKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return notify.NewAPNsSender(notify.APNsConfig{
+		KeyID:      cfg.APNsKeyID,
+		TeamID:     cfg.APNsTeamID,
+		Topic:      cfg.APNsTopic,
+		PrivateKey: key,
+		Production: cfg.APNsProduction,
+		Timeout:    cfg.PushTimeout,
+	})
+}
+
+// newWebAuthn нь WebAuthn relying party үүсгэнэ.
+// Тохиргоо буруу бол nil буцаана (WebAuthn бүртгэл идэвхгүй, TOTP ажиллана).
+func newWebAuthn(cfg *localconfig.WebAuthnConfig, log *zap.Logger) *webauthn.WebAuthn {
+	rp, err := webauthn.New(webauthn.Config{
+		RPID:             cfg.RPID,
+		RPName:           cfg.RPName,
+		Origins:          cfg.Origins,
+		Timeout:          cfg.Timeout,
+		UserVerification: cfg.UserVerification,
+	})
+	if err != nil {
+		log.Error("webauthn init failed, WebAuthn MFA is disabled", zap.Error(err))
+		return nil
+	}
+	return rp
+}
@@ -0,0 +1,120 @@
+// Package auth provides implementation for auth
+//
+// File: authenticate.go
+// Description: Unified SSO / local session authentication middleware
+/*
+Package auth нь SSO authentication болон session management-ийг хариуцна.
+
+Энэ файл нь SSO session болон local (email/password) session-ийг
+нэг middleware-ээр шалгах Authenticate функцийг агуулна.
+
+Authentication flow:
+ 1. Authorization header-ээс token авах
+ 2. Token нь local session бол Redis-ээс session авч Claims үүсгэх
+ 3. Local session биш бол SSO Require middleware руу шилжих
+ 4. Аль ч тохиолдолд Claims-ийг ижил Locals/context-д хадгална
+
+Ингэснээр RequirePermission, guard-ууд болон handler-ууд хэрэглэгч
+аль аргаар нэвтэрснээс үл хамааран ажиллана.
+*/
+package auth
+
+import (
+	"context" // Background context
+	"time"    // Session expiry
+
+	"templatev25/internal/middleware" // Local session store
+
+	"git.gerege.mn/backend-packages/config"     // Configuration
+	"git.gerege.mn/backend-packages/sso-client" // SSO client
+
+	"github.com/gofiber/fiber/v2" // Web framework
+	"go.uber.org/zap"             // Structured logging
+)
+
+// Local session-ий Locals түлхүүрүүд (middleware.SessionAuth-тай ижил)
+const (
+	localsSessionID = "session_id"
+	localsUserID    = "user_id"
+	localsEmail     = "email"
+	localsSession   = "session"
+)
+
+// ============================================================
+// AUTHENTICATE MIDDLEWARE
+// ============================================================
+
+// Authenticate нь SSO болон local session-ийг хоёуланг нь хүлээн авах
+// authentication middleware буцаана.
+//
+// Local session эхэлж шалгагдана (Redis-ээс нэг GET). Олдохгүй эсвэл
+// хугацаа нь дууссан бол Require-ийн SSO flow ажиллана.
+//
+// Local session-ий Claims:
+//   - UserID: session.UserID
+//   - Username: session.Email
+//   - OrgID: session.OrgID (нэвтрэх үед тооцоолж, /auth/org/change-аар солигдоно)
+//
+// Parameters:
+//   - cfg: Application configuration
+//   - log: Zap logger
+//   - cache: SSO session cache
+//   - sessions: Local session store (nil бол зөвхөн SSO)
+//
+// Returns:
+//   - fiber.Handler: Middleware function
+//
+// Жишээ:
+//
+//	requireAuth := auth.Authenticate(cfg, log, cache, sessionStore)
+//	app.Get("/protected", requireAuth, handler.Protected)
+func Authenticate(cfg *config.Config, log *zap.Logger, cache *ssoclient.Cache, sessions middleware.SessionStore) fiber.Handler {
+	requireSSO := Require(cfg, log, cache)
+	if sessions == nil {
+		return requireSSO
+	}
+
+	return func(c *fiber.Ctx) error {
+		// Local session нь зөвхөн Authorization header-ээр ирнэ
+		if token := extractFromAuthHeader(c); token != "" {
+			session, err := sessions.Get(c.UserContext(), token)
+			if err != nil {
+				// Redis алдаа гарсан ч SSO session ажиллах ёстой
+				log.Warn("local session lookup failed", zap.Error(err))
+			} else if session != nil && time.Now().Before(session.ExpiresAt) {
+				attachLocalSession(c, sessions, session)
+				return c.Next()
+			}
+		}
+
+		return requireSSO(c)
+	}
+}
+
+// LocalSession нь request local session-оор нэвтэрсэн бол тухайн session-ийг
+// буцаана. SSO session-д ok=false.
+func LocalSession(c *fiber.Ctx) (*middleware.SessionData, bool) {
+	session, ok := c.Locals(localsSession).(*middleware.SessionData)
+	return session, ok && session != nil
+}
+
+// attachLocalSession нь local session-ийг SSO-той ижил Claims болгон
+// Locals/context-д хадгална. middleware.SessionAuth-ийн Locals-ийг мөн
+// тавьдаг тул local auth handler-ууд ч ажиллана.
+func attachLocalSession(c *fiber.Ctx, sessions middleware.SessionStore, session *middleware.SessionData) {
+	attachToCtx(c, session.SessionID, &ssoclient.Claims{
+		UserID:   session.UserID,
+		Username: session.Email,
+		OrgID:    session.OrgID,
+	})
+
+	c.Locals(localsSessionID, session.SessionID)
+	c.Locals(localsUserID, session.UserID)
+	c.Locals(localsEmail, session.Email)
+	c.Locals(localsSession, session)
+
+	// Сүүлийн идэвхийг шинэчлэх (request-ийг хүлээлгэхгүй). Touch нь зөвхөн
+	// байгаа session-ийг шинэчилнэ: logout, rotation-оор устгагдсан session
+	// дахин үүсэхгүй, зэрэг хийгдсэн Update (байгууллага солих)-ийг дарахгүй.
+	go sessions.Touch(context.Background(), session.SessionID, time.Now())
+}
@@ -44,7 +44,6 @@ package auth
 
 import (
 	"context"
-	"slices"
 	"time"
 
 	"git.gerege.mn/backend-packages/sso-client"
@@ -65,6 +64,24 @@ type PermissionChecker interface {
 	GetUserPermissions(ctx context.Context, userID int) ([]string, error)
 }
 
+// permissionSetProvider нь бэлэн PermissionSet өгч чадах checker (PermissionCache).
+type permissionSetProvider interface {
+	GetUserPermissionSet(ctx context.Context, userID int) (*PermissionSet, error)
+}
+
+// userPermissionSet нь хэрэглэгчийн permission trie-г авна.
+// Checker нь PermissionCache бол cache-д бүтээгдсэн trie-г ашиглана.
+func userPermissionSet(ctx context.Context, checker PermissionChecker, userID int) (*PermissionSet, error) {
+	if p, ok := checker.(permissionSetProvider); ok {
+		return p.GetUserPermissionSet(ctx, userID)
+	}
+	codes, err := checker.GetUserPermissions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return NewPermissionSet(codes), nil
+}
+
 // ============================================================
 // REQUIRE PERMISSION
 // ============================================================
@@ -153,14 +170,14 @@ func RequireAnyPermission(checker PermissionChecker, permissionCodes ...string)
 		ctx, cancel := context.WithTimeout(c.UserContext(), 3*time.Second)
 		defer cancel()
 
-		userPerms, err := checker.GetUserPermissions(ctx, userID)
+		userPerms, err := userPermissionSet(ctx, checker, userID)
 		if err != nil {
 			return fiber.NewError(fiber.StatusForbidden, "permission check failed")
 		}
 
-		// Аль нэг нь байвал зөвшөөрнө
+		// Аль нэг нь байвал зөвшөөрнө (wildcard grant тооцогдоно)
 		for _, code := range permissionCodes {
-			if slices.Contains(userPerms, code) {
+			if userPerms.Has(code) {
 				return c.Next()
 			}
 		}
@@ -207,14 +224,14 @@ func RequireAllPermissions(checker PermissionChecker, permissionCodes ...string)
 		ctx, cancel := context.WithTimeout(c.UserContext(), 3*time.Second)
 		defer cancel()
 
-		userPerms, err := checker.GetUserPermissions(ctx, userID)
+		userPerms, err := userPermissionSet(ctx, checker, userID)
 		if err != nil {
 			return fiber.NewError(fiber.StatusForbidden, "permission check failed")
 		}
 
-		// Бүгд байх ёстой
+		// Бүгд байх ёстой (wildcard grant тооцогдоно)
 		for _, code := range permissionCodes {
-			if !slices.Contains(userPerms, code) {
+			if !userPerms.Has(code) {
 				return fiber.NewError(fiber.StatusForbidden, "insufficient permissions: "+code)
 			}
 		}
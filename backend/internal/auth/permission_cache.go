@@ -15,7 +15,9 @@ Cache нь DB руу очих request-ийн тоог бууруулж, хурд
 Cache бүтэц:
   - In-memory cache (sync.Map ашиглана)
   - TTL-тэй (default 5 минут)
-  - User ID-гаар key хадгална
+  - (User ID, Org ID)-гаар key хадгална. Нэг хэрэглэгч байгууллага бүрт
+    өөр эрхтэй байж болох тул сонгосон байгууллага бүрт тусдаа entry.
+    Org ID-г context-оос (ctx.KeyOrgID) авна.
 
 Invalidation:
   - InvalidateUser: Хэрэглэгчийн cache-ийг цэвэрлэх
@@ -43,6 +45,8 @@ import (
 	"slices"
 	"sync"
 	"time"
+
+	"git.gerege.mn/backend-packages/ctx"
 )
 
 // ============================================================
@@ -51,8 +55,9 @@ import (
 
 // cachedPermissions нь хэрэглэгчийн permission-уудыг TTL-тэй хадгална.
 type cachedPermissions struct {
-	codes     []string  // Permission кодуудын жагсаалт
-	expiresAt time.Time // Cache хүчинтэй хугацаа
+	codes     []string       // Permission кодуудын жагсаалт
+	set       *PermissionSet // Wildcard шалгалтад зориулсан trie (нэг удаа бүтээнэ)
+	expiresAt time.Time      // Cache хүчинтэй хугацаа
 }
 
 // isExpired нь cache хүчингүй болсон эсэхийг шалгана.
@@ -60,6 +65,19 @@ func (cp *cachedPermissions) isExpired() bool {
 	return time.Now().After(cp.expiresAt)
 }
 
+// permissionKey нь cache-ийн key: хэрэглэгч + сонгосон байгууллага.
+// OrgID = 0 бол байгууллага сонгоогүй (зөвхөн global role-ууд).
+type permissionKey struct {
+	userID int
+	orgID  int
+}
+
+// keyFor нь context дахь сонгосон байгууллагаар cache key үүсгэнэ.
+func keyFor(uctx context.Context, userID int) permissionKey {
+	orgID, _ := ctx.GetValue[int](uctx, ctx.KeyOrgID)
+	return permissionKey{userID: userID, orgID: orgID}
+}
+
 // ============================================================
 // CACHE INVALIDATOR INTERFACE
 // ============================================================
@@ -83,7 +101,7 @@ type CacheInvalidator interface {
 // PermissionChecker интерфейсийг implement хийнэ.
 type PermissionCache struct {
 	service PermissionChecker // Underlying service (DB руу хандах)
-	cache   sync.Map          // userID -> *cachedPermissions
+	cache   sync.Map          // permissionKey -> *cachedPermissions
 	ttl     time.Duration     // Cache TTL
 	mu      sync.RWMutex      // Role invalidation-д ашиглах
 }
@@ -108,7 +126,7 @@ func NewPermissionCache(service PermissionChecker, ttl time.Duration) *Permissio
 // ============================================================
 
 // HasPermission нь хэрэглэгч тодорхой permission-тэй эсэхийг шалгана.
-// Cache-д байвал DB руу явахгүй.
+// Cache-д байвал DB руу явахгүй. Wildcard grant ("admin.user.*") тооцогдоно.
 //
 // Parameters:
 //   - ctx: Context
@@ -119,43 +137,64 @@ func NewPermissionCache(service PermissionChecker, ttl time.Duration) *Permissio
 //   - bool: Permission байвал true
 //   - error: Алдаа
 func (pc *PermissionCache) HasPermission(ctx context.Context, userID int, permissionCode string) (bool, error) {
-	// Бүх permission-уудыг авах (cache ашиглана)
-	perms, err := pc.GetUserPermissions(ctx, userID)
+	// Permission trie авах (cache ашиглана)
+	set, err := pc.GetUserPermissionSet(ctx, userID)
 	if err != nil {
 		return false, err
 	}
 
 	// Permission байгаа эсэхийг шалгах
-	return slices.Contains(perms, permissionCode), nil
+	return set.Has(permissionCode), nil
+}
+
+// GetUserPermissionSet нь хэрэглэгчийн permission trie-г буцаана.
+// Trie нь cache entry-тэй хамт нэг л удаа бүтээгдэнэ.
+func (pc *PermissionCache) GetUserPermissionSet(ctx context.Context, userID int) (*PermissionSet, error) {
+	entry, err := pc.load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return entry.set, nil
 }
 
 // GetUserPermissions нь хэрэглэгчийн бүх permission-уудыг буцаана.
 // Cache-д байвал DB руу явахгүй.
 //
 // Parameters:
-//   - ctx: Context
+//   - ctx: Context (сонгосон байгууллагыг ctx.KeyOrgID-оос авна)
 //   - userID: Хэрэглэгчийн ID
 //
 // Returns:
 //   - []string: Permission кодуудын жагсаалт
 //   - error: Алдаа
-func (pc *PermissionCache) GetUserPermissions(ctx context.Context, userID int) ([]string, error) {
+func (pc *PermissionCache) GetUserPermissions(uctx context.Context, userID int) ([]string, error) {
+	entry, err := pc.load(uctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return entry.codes, nil
+}
+
+// load нь cache entry-г буцаана, байхгүй эсвэл хугацаа дууссан бол DB-ээс дүүргэнэ.
+func (pc *PermissionCache) load(uctx context.Context, userID int) (*cachedPermissions, error) {
+	key := keyFor(uctx, userID)
+
 	// ============================================================
 	// STEP 1: Cache-ээс хайх
 	// ============================================================
-	if cached, ok := pc.cache.Load(userID); ok {
+	if cached, ok := pc.cache.Load(key); ok {
 		cp := cached.(*cachedPermissions)
 		if !cp.isExpired() {
-			return cp.codes, nil
+			return cp, nil
 		}
 		// Хүчингүй болсон бол устгах
-		pc.cache.Delete(userID)
+		pc.cache.Delete(key)
 	}
 
 	// ============================================================
 	// STEP 2: DB-ээс авах
 	// ============================================================
-	perms, err := pc.service.GetUserPermissions(ctx, userID)
+	perms, err := pc.service.GetUserPermissions(uctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -163,35 +202,43 @@ func (pc *PermissionCache) GetUserPermissions(ctx context.Context, userID int) (
 	// ============================================================
 	// STEP 3: Cache-д хадгалах
 	// ============================================================
-	pc.cache.Store(userID, &cachedPermissions{
+	entry := &cachedPermissions{
 		codes:     perms,
+		set:       NewPermissionSet(perms),
 		expiresAt: time.Now().Add(pc.ttl),
-	})
+	}
+	pc.cache.Store(key, entry)
 
-	return perms, nil
+	return entry, nil
 }
 
 // ============================================================
 // CACHE INVALIDATION
 // ============================================================
 
-// InvalidateUser нь тодорхой хэрэглэгчийн cache-ийг цэвэрлэнэ.
+// InvalidateUser нь тодорхой хэрэглэгчийн cache-ийг бүх байгууллагаар цэвэрлэнэ.
 // Хэрэглэгчийн role-ууд өөрчлөгдөхөд дуудна.
 //
 // Parameters:
 //   - userID: Хэрэглэгчийн ID
 func (pc *PermissionCache) InvalidateUser(userID int) {
-	pc.cache.Delete(userID)
+	pc.InvalidateUsers([]int{userID})
 }
 
-// InvalidateUsers нь олон хэрэглэгчийн cache-ийг цэвэрлэнэ.
+// InvalidateUsers нь олон хэрэглэгчийн cache-ийг бүх байгууллагаар цэвэрлэнэ.
 //
 // Parameters:
 //   - userIDs: Хэрэглэгчдийн ID-ууд
 func (pc *PermissionCache) InvalidateUsers(userIDs []int) {
-	for _, id := range userIDs {
-		pc.cache.Delete(id)
+	if len(userIDs) == 0 {
+		return
 	}
+	pc.cache.Range(func(k, _ interface{}) bool {
+		if slices.Contains(userIDs, k.(permissionKey).userID) {
+			pc.cache.Delete(k)
+		}
+		return true
+	})
 }
 
 // InvalidateAll нь бүх cache-ийг цэвэрлэнэ.
@@ -199,7 +246,8 @@ func (pc *PermissionCache) InvalidateUsers(userIDs []int) {
 func (pc *PermissionCache) InvalidateAll() {
 	pc.mu.Lock()
 	defer pc.mu.Unlock()
-	pc.cache = sync.Map{}
+	// Map-ийг солих биш цэвэрлэнэ: зэрэг уншиж буй goroutine-уудтай race үүсгэхгүй
+	pc.cache.Clear()
 }
 
 // ============================================================
@@ -208,7 +256,7 @@ func (pc *PermissionCache) InvalidateAll() {
 
 // CacheStats нь cache-ийн статистик мэдээлэл.
 type CacheStats struct {
-	CachedUsers int           // Cache-д байгаа хэрэглэгчийн тоо
+	CachedUsers int           // Cache-д байгаа (хэрэглэгч, байгууллага) хосын тоо
 	TTL         time.Duration // Cache TTL
 }
 
@@ -9,6 +9,8 @@ import (
 	"testing"
 	"time"
 
+	xctx "git.gerege.mn/backend-packages/ctx"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -196,6 +198,28 @@ func TestPermissionCache_InvalidateAll(t *testing.T) {
 	assert.Equal(t, 4, mock.callCount) // +2
 }
 
+func TestPermissionCache_OrgScoped(t *testing.T) {
+	mock := newMockChecker(map[int][]string{
+		1: {"perm1"},
+	})
+	cache := NewPermissionCache(mock, 5*time.Minute)
+	orgA := xctx.WithValue(context.Background(), xctx.KeyOrgID, 10)
+	orgB := xctx.WithValue(context.Background(), xctx.KeyOrgID, 20)
+
+	// Each selected org is cached separately
+	_, _ = cache.GetUserPermissions(orgA, 1)
+	_, _ = cache.GetUserPermissions(orgB, 1)
+	_, _ = cache.GetUserPermissions(orgA, 1)
+	assert.Equal(t, 2, mock.callCount)
+
+	// Invalidating the user drops every org entry
+	cache.InvalidateUser(1)
+	assert.Equal(t, 0, cache.Stats().CachedUsers)
+
+	_, _ = cache.GetUserPermissions(orgB, 1)
+	assert.Equal(t, 3, mock.callCount)
+}
+
 func TestPermissionCache_Stats(t *testing.T) {
 	mock := newMockChecker(map[int][]string{
 		1: {"perm1"},
@@ -0,0 +1,140 @@
+// Package auth provides implementation for auth
+//
+// File: permission_invalidator.go
+// Description: Distributed permission cache invalidation over Redis pub/sub
+/*
+Package auth нь SSO authentication болон authorization-ийг хариуцна.
+
+Энэ файл нь PermissionCache-ийн invalidation-ийг олон instance хооронд
+Redis pub/sub-аар түгээх RedisCacheInvalidator-ийг тодорхойлно.
+
+PermissionCache нь process доторх sync.Map тул нэг instance дээр role/permission
+өөрчлөгдөхөд бусад instance-ууд TTL дуустал хуучин эрхээр ажиллана.
+RedisCacheInvalidator нь:
+ 1. Local cache-ийг шууд цэвэрлэнэ
+ 2. Invalidation мессежийг Redis channel руу publish хийнэ
+ 3. Бусад instance-уудын мессежийг subscribe хийж өөрийн cache-д хэрэгжүүлнэ
+
+Redis-тэй холболт тасарч дахин холбогдоход алдсан мессеж байж болох тул
+local cache-ийг бүхэлд нь цэвэрлэнэ. Pub/sub-ийн давталт pubsub.Channel-д.
+
+Ашиглалт:
+
+	invalidator := auth.NewRedisCacheInvalidator(permCache, redisClient, "permission-cache:invalidate", log)
+	invalidator.Start()
+	defer invalidator.Stop()
+
+	roleService.SetCacheInvalidator(invalidator)
+*/
+package auth
+
+import (
+	"encoding/json" // Message encoding
+
+	"templatev25/internal/pubsub" // Redis pub/sub channel
+
+	"github.com/redis/go-redis/v9" // Redis client
+	"go.uber.org/zap"              // Structured logging
+)
+
+// invalidationMessage нь Redis channel-аар дамжих invalidation мессеж.
+type invalidationMessage struct {
+	All     bool  `json:"all,omitempty"`      // Бүх cache цэвэрлэх
+	UserIDs []int `json:"user_ids,omitempty"` // Цэвэрлэх хэрэглэгчид
+}
+
+// ============================================================
+// REDIS CACHE INVALIDATOR
+// ============================================================
+
+// RedisCacheInvalidator нь CacheInvalidator-ийг implement хийж
+// invalidation-ийг бүх instance руу Redis pub/sub-аар түгээнэ.
+type RedisCacheInvalidator struct {
+	local   CacheInvalidator // Энэ instance-ийн cache
+	channel *pubsub.Channel  // Бусад instance-уудтай холбох channel
+	log     *zap.Logger
+}
+
+// NewRedisCacheInvalidator нь шинэ distributed invalidator үүсгэнэ.
+//
+// Parameters:
+//   - local: Энэ instance-ийн cache (ихэвчлэн *PermissionCache)
+//   - client: Redis client
+//   - channel: Pub/sub channel нэр (бүх instance-д ижил байх ёстой)
+//   - log: Zap logger
+//
+// Returns:
+//   - *RedisCacheInvalidator: Invalidator (Start дуудсаны дараа мессеж хүлээн авна)
+func NewRedisCacheInvalidator(local CacheInvalidator, client redis.UniversalClient, channel string, log *zap.Logger) *RedisCacheInvalidator {
+	r := &RedisCacheInvalidator{local: local, log: log}
+	r.channel = pubsub.New(client, channel, log, pubsub.Handlers{
+		OnMessage: r.apply,
+		// Тасарсан үеийн мессежүүд алдагдсан байж болно
+		OnResubscribe: local.InvalidateAll,
+	})
+	return r
+}
+
+// ============================================================
+// CACHE INVALIDATOR IMPLEMENTATION
+// ============================================================
+
+// InvalidateUser нь хэрэглэгчийн cache-ийг бүх instance дээр цэвэрлэнэ.
+func (r *RedisCacheInvalidator) InvalidateUser(userID int) {
+	r.InvalidateUsers([]int{userID})
+}
+
+// InvalidateUsers нь хэрэглэгчдийн cache-ийг бүх instance дээр цэвэрлэнэ.
+func (r *RedisCacheInvalidator) InvalidateUsers(userIDs []int) {
+	if len(userIDs) == 0 {
+		return
+	}
+	r.local.InvalidateUsers(userIDs)
+	r.publish(invalidationMessage{UserIDs: userIDs})
+}
+
+// InvalidateAll нь бүх instance-ийн cache-ийг цэвэрлэнэ.
+func (r *RedisCacheInvalidator) InvalidateAll() {
+	r.local.InvalidateAll()
+	r.publish(invalidationMessage{All: true})
+}
+
+// publish нь мессежийг бусад instance руу илгээнэ.
+// Алдаа гарвал бусад instance-ууд TTL дуустал хуучин cache-тэй үлдэнэ.
+func (r *RedisCacheInvalidator) publish(msg invalidationMessage) {
+	if err := r.channel.Publish(msg); err != nil {
+		r.log.Warn("permission_invalidation_publish_failed",
+			zap.String("channel", r.channel.Name()),
+			zap.Error(err),
+		)
+	}
+}
+
+// ============================================================
+// SUBSCRIBER
+// ============================================================
+
+// Start нь бусад instance-уудын мессежийг хүлээн авах goroutine эхлүүлнэ.
+func (r *RedisCacheInvalidator) Start() {
+	r.channel.Start()
+}
+
+// Stop нь subscribe goroutine-ийг зогсоож дуусахыг хүлээнэ.
+func (r *RedisCacheInvalidator) Stop() {
+	r.channel.Stop()
+}
+
+// apply нь бусад instance-аас ирсэн мессежийг local cache-д хэрэгжүүлнэ.
+func (r *RedisCacheInvalidator) apply(data []byte) {
+	var msg invalidationMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		r.log.Warn("permission_invalidation_decode_failed", zap.Error(err))
+		return
+	}
+
+	if msg.All {
+		r.local.InvalidateAll()
+		return
+	}
+	r.local.InvalidateUsers(msg.UserIDs)
+}
@@ -0,0 +1,129 @@
+// Package auth provides authentication and authorization utilities
+//
+// File: permission_invalidator_test.go
+// Description: Unit tests for distributed permission cache invalidation
+package auth
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// recordingInvalidator records invalidation calls
+type recordingInvalidator struct {
+	users []int
+	all   int
+}
+
+func (r *recordingInvalidator) InvalidateUser(userID int) { r.users = append(r.users, userID) }
+
+func (r *recordingInvalidator) InvalidateUsers(userIDs []int) { r.users = append(r.users, userIDs...) }
+
+func (r *recordingInvalidator) InvalidateAll() { r.all++ }
+
+// newUnreachableInvalidator returns an invalidator whose Redis connection always fails
+func newUnreachableInvalidator(local CacheInvalidator) *RedisCacheInvalidator {
+	client := redis.NewClient(&redis.Options{
+		Addr:        "127.0.0.1:1",
+		DialTimeout: 100 * time.Millisecond,
+		MaxRetries:  -1,
+	})
+	return NewRedisCacheInvalidator(local, client, "permission-cache:test", zap.NewNop())
+}
+
+func encodeInvalidation(t *testing.T, msg invalidationMessage) []byte {
+	t.Helper()
+	b, err := json.Marshal(msg)
+	require.NoError(t, err)
+	return b
+}
+
+func TestRedisCacheInvalidator_LocalFirst(t *testing.T) {
+	local := &recordingInvalidator{}
+	inv := newUnreachableInvalidator(local)
+
+	// Publish fails, local cache is still cleared
+	inv.InvalidateUser(1)
+	inv.InvalidateUsers([]int{2, 3})
+	inv.InvalidateUsers(nil)
+	inv.InvalidateAll()
+
+	assert.Equal(t, []int{1, 2, 3}, local.users)
+	assert.Equal(t, 1, local.all)
+}
+
+func TestRedisCacheInvalidator_Apply(t *testing.T) {
+	tests := []struct {
+		name      string
+		payload   []byte
+		wantUsers []int
+		wantAll   int
+	}{
+		{
+			name:      "remote users",
+			payload:   encodeInvalidation(t, invalidationMessage{UserIDs: []int{4, 5}}),
+			wantUsers: []int{4, 5},
+		},
+		{
+			name:    "remote all",
+			payload: encodeInvalidation(t, invalidationMessage{All: true}),
+			wantAll: 1,
+		},
+		{
+			name:    "malformed payload is ignored",
+			payload: []byte("{not json"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			local := &recordingInvalidator{}
+			inv := newUnreachableInvalidator(local)
+
+			inv.apply(tt.payload)
+
+			assert.Equal(t, tt.wantUsers, local.users)
+			assert.Equal(t, tt.wantAll, local.all)
+		})
+	}
+}
+
+func TestRedisCacheInvalidator_StartStop(t *testing.T) {
+	inv := newUnreachableInvalidator(&recordingInvalidator{})
+	inv.Start()
+
+	stopped := make(chan struct{})
+	go func() {
+		inv.Stop()
+		inv.Stop() // idempotent
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop did not return")
+	}
+}
+
+func TestPermissionCache_InvalidateAllKeepsCacheUsable(t *testing.T) {
+	mock := &mockPermissionChecker{
+		permissions: map[int][]string{1: {"admin.user.read"}},
+	}
+	cache := NewPermissionCache(mock, time.Minute)
+
+	_, err := cache.GetUserPermissions(t.Context(), 1)
+	require.NoError(t, err)
+	cache.InvalidateAll()
+
+	perms, err := cache.GetUserPermissions(t.Context(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admin.user.read"}, perms)
+	assert.Equal(t, 2, mock.callCount)
+}
@@ -0,0 +1,105 @@
+// Package auth provides implementation for auth
+//
+// File: permission_set.go
+// Description: Wildcard-aware permission matching
+/*
+Package auth нь SSO authentication болон authorization-ийг хариуцна.
+
+Энэ файл нь permission кодуудыг wildcard дэмжлэгтэйгээр шалгах
+PermissionSet-ийг тодорхойлно.
+
+Код нь цэгээр тусгаарлагдсан сегментүүдээс бүрдэнэ (admin.user.read).
+Grant-ийн сүүлийн сегмент "*" бол тухайн prefix-ийн доорх бүх кодыг хамарна:
+  - "admin.user.*" → admin.user.read, admin.user.export.csv
+  - "admin.*"      → admin.user.read, admin.role.create
+  - "*"            → бүх код
+
+PermissionSet нь сегментийн trie тул шалгалт нь grant-уудын тооноос
+үл хамааран кодын сегментийн тоотой пропорциональ.
+*/
+package auth
+
+import (
+	"strings"
+
+	"templatev25/internal/domain"
+)
+
+// permissionNode нь trie-ийн нэг сегмент.
+type permissionNode struct {
+	children map[string]*permissionNode
+	exact    bool // Энэ зам хүртэлх код яг олгогдсон
+	wildcard bool // Энэ зам + ".*" олгогдсон (доорх бүх код)
+}
+
+// PermissionSet нь хэрэглэгчийн grant-уудаас бүтээсэн trie.
+// Бүтээсний дараа зөвхөн уншигддаг тул goroutine-уудад аюулгүй.
+type PermissionSet struct {
+	root permissionNode
+}
+
+// NewPermissionSet нь grant кодуудаас PermissionSet бүтээнэ.
+// Буруу хэлбэртэй код (жишээ: "admin.*.read") зөвхөн яг таарцаар шалгагдана.
+func NewPermissionSet(codes []string) *PermissionSet {
+	s := &PermissionSet{}
+	for _, code := range codes {
+		s.add(code)
+	}
+	return s
+}
+
+// add нь нэг grant кодыг trie-д нэмнэ.
+func (s *PermissionSet) add(code string) {
+	if code == "" {
+		return
+	}
+	if code == domain.PermissionWildcard {
+		s.root.wildcard = true
+		return
+	}
+
+	wildcard := domain.IsWildcardPermissionCode(code)
+	if wildcard {
+		code = strings.TrimSuffix(code, "."+domain.PermissionWildcard)
+	}
+
+	n := &s.root
+	for _, seg := range strings.Split(code, ".") {
+		if n.children == nil {
+			n.children = make(map[string]*permissionNode)
+		}
+		child, ok := n.children[seg]
+		if !ok {
+			child = &permissionNode{}
+			n.children[seg] = child
+		}
+		n = child
+	}
+
+	if wildcard {
+		n.wildcard = true
+	} else {
+		n.exact = true
+	}
+}
+
+// Has нь code олгогдсон эсэхийг шалгана (яг таарц эсвэл эцэг wildcard).
+func (s *PermissionSet) Has(code string) bool {
+	if s == nil || code == "" {
+		return false
+	}
+
+	n := &s.root
+	for code != "" {
+		if n.wildcard {
+			return true
+		}
+		seg, rest, _ := strings.Cut(code, ".")
+		next, ok := n.children[seg]
+		if !ok {
+			return false
+		}
+		n, code = next, rest
+	}
+	return n.exact
+}
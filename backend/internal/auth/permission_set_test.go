@@ -0,0 +1,60 @@
+// Package auth provides authentication and authorization utilities
+//
+// File: permission_set_test.go
+// Description: Unit tests for wildcard permission matching
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermissionSet_Has(t *testing.T) {
+	tests := []struct {
+		name   string
+		grants []string
+		code   string
+		want   bool
+	}{
+		{"exact match", []string{"admin.user.read"}, "admin.user.read", true},
+		{"exact grant does not cover children", []string{"admin.user"}, "admin.user.read", false},
+		{"exact grant does not cover siblings", []string{"admin.user.read"}, "admin.user.create", false},
+		{"trailing wildcard", []string{"admin.user.*"}, "admin.user.read", true},
+		{"trailing wildcard covers deeper codes", []string{"admin.user.*"}, "admin.user.export.csv", true},
+		{"trailing wildcard does not cover its prefix", []string{"admin.*"}, "admin", false},
+		{"trailing wildcard does not cover other modules", []string{"admin.user.*"}, "admin.role.read", false},
+		{"module wildcard", []string{"admin.*"}, "admin.role.create", true},
+		{"global wildcard", []string{"*"}, "billing.invoice.read", true},
+		{"malformed wildcard matches literally", []string{"admin.*.read"}, "admin.user.read", false},
+		{"empty code", []string{"*"}, "", false},
+		{"no grants", nil, "admin.user.read", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewPermissionSet(tt.grants).Has(tt.code))
+		})
+	}
+}
+
+func TestPermissionCache_WildcardGrant(t *testing.T) {
+	mock := &mockPermissionChecker{
+		permissions: map[int][]string{1: {"admin.user.*"}},
+	}
+	cache := NewPermissionCache(mock, time.Minute)
+	ctx := context.Background()
+
+	ok, err := cache.HasPermission(ctx, 1, "admin.user.delete")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = cache.HasPermission(ctx, 1, "admin.role.delete")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// Хоёр шалгалт нэг удаа л ачаална
+	assert.Equal(t, 1, mock.callCount)
+}
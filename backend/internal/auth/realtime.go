@@ -0,0 +1,241 @@
+// Package auth provides implementation for auth
+//
+// File: realtime.go
+// Description: Ticket authentication and session liveness for realtime streams
+/*
+Package auth нь SSO authentication болон session management-ийг хариуцна.
+
+Энэ файл нь WebSocket/SSE холболтын authentication-ийг тодорхойлно.
+
+Browser-ийн WebSocket болон EventSource нь Authorization header тавьж
+чаддаггүй тул local session-оор нэвтэрсэн хэрэглэгч Authenticate-ээр
+холбогдож чадахгүй. Үүний оронд:
+ 1. Client нэвтэрсэн REST хүсэлтээр (POST /realtime/ticket) ticket авна
+ 2. Ticket-ээ ?ticket=... query-ээр дамжуулж холбогдоно
+ 3. Ticket нэг удаагийн, богино хугацаатай; session-ий ID-г заана
+
+Холболт нээлттэй байх хугацаанд session-ийг үе үе шалгаж, logout эсвэл
+revoke хийгдсэн бол холболтыг хаана (RealtimeSessionCheck).
+
+Ашиглалт:
+
+	rt := auth.NewRealtimeAuth(cfg, log, cache, sessions, tickets)
+	app.Post("/realtime/ticket", requireAuth, handler.Ticket)
+	app.Get("/notification/stream", rt.Authenticate(), handler.Stream)
+*/
+package auth
+
+import (
+	"context"         // Timeout context
+	"crypto/rand"     // Ticket generation
+	"encoding/base64" // Ticket encoding
+	"errors"          // Sentinel errors
+	"time"            // Ticket TTL
+
+	"templatev25/internal/middleware" // Local session store
+
+	"git.gerege.mn/backend-packages/config"     // Configuration
+	"git.gerege.mn/backend-packages/sso-client" // SSO client
+
+	"github.com/gofiber/fiber/v2" // Web framework
+	"go.uber.org/zap"             // Structured logging
+)
+
+const (
+	// RealtimeTicketTTL нь ticket ашиглагдах хугацаа. Client ticket авмагц
+	// холбогдох тул богино байна.
+	RealtimeTicketTTL = 30 * time.Second
+
+	// realtimeTicketQuery нь ticket дамжуулах query параметр.
+	realtimeTicketQuery = "ticket"
+
+	// localsRealtimeAuth нь RealtimeSessionCheck-д RealtimeAuth-ийг дамжуулах Locals түлхүүр.
+	localsRealtimeAuth = "realtime_auth"
+
+	// sessionCheckTimeout нь нэг удаагийн session шалгалтын хугацааны хязгаар.
+	sessionCheckTimeout = 3 * time.Second
+)
+
+// ErrNoRealtimeSession нь request-д ticket олгох session байхгүй үед буцна.
+var ErrNoRealtimeSession = errors.New("request has no session to issue a realtime ticket for")
+
+// RealtimeTicket нь ticket-ийн заах session.
+type RealtimeTicket struct {
+	SessionID string
+	Local     bool // Local (email/password) session бол true, SSO бол false
+}
+
+// RealtimeTicketStore нь ticket-ийг хадгална. Take нь ticket-ийг устгаж
+// буцаах тул ticket нэг л холболт нээнэ. Олдохгүй бол (nil, nil).
+type RealtimeTicketStore interface {
+	StoreRealtimeTicket(ctx context.Context, ticket string, data *RealtimeTicket, ttl time.Duration) error
+	TakeRealtimeTicket(ctx context.Context, ticket string) (*RealtimeTicket, error)
+}
+
+// ============================================================
+// REALTIME AUTH
+// ============================================================
+
+// RealtimeAuth нь realtime холболтын ticket олгох, шалгах болон session-ий
+// амьд эсэхийг шалгах үйлдлүүдийг нэгтгэнэ.
+type RealtimeAuth struct {
+	authenticate fiber.Handler
+	sessions     middleware.SessionStore
+	tickets      RealtimeTicketStore
+	sso          *ssoclient.SSOClient // SSO тохируулаагүй бол nil
+	log          *zap.Logger
+}
+
+// NewRealtimeAuth нь шинэ RealtimeAuth үүсгэнэ.
+//
+// Parameters:
+//   - cfg: Application configuration
+//   - log: Zap logger
+//   - cache: SSO session cache
+//   - sessions: Local session store
+//   - tickets: Ticket store
+//
+// Returns:
+//   - *RealtimeAuth: Realtime authentication
+func NewRealtimeAuth(cfg *config.Config, log *zap.Logger, cache *ssoclient.Cache, sessions middleware.SessionStore, tickets RealtimeTicketStore) *RealtimeAuth {
+	a := &RealtimeAuth{
+		authenticate: Authenticate(cfg, log, cache, sessions),
+		sessions:     sessions,
+		tickets:      tickets,
+		log:          log,
+	}
+	if cfg.Auth.ClientID != "" && cfg.Auth.ClientSecret != "" && cfg.URLS.SSO != "" {
+		a.sso = ssoclient.NewSSOClient(cfg, log, cache)
+	}
+	return a
+}
+
+// IssueTicket нь request-ийн session-д зориулж ticket үүсгэнэ.
+// Authenticate middleware-ийн дараа дуудагдана.
+//
+// Returns:
+//   - string: Ticket (?ticket=... query-д дамжуулна)
+//   - time.Time: Ticket дуусах хугацаа
+//   - error: ErrNoRealtimeSession эсвэл store алдаа
+func (a *RealtimeAuth) IssueTicket(c *fiber.Ctx) (string, time.Time, error) {
+	var data RealtimeTicket
+	if session, ok := LocalSession(c); ok {
+		data = RealtimeTicket{SessionID: session.SessionID, Local: true}
+	} else if sid := ssoclient.GetSessionID(c); sid != "" {
+		data = RealtimeTicket{SessionID: sid}
+	} else {
+		return "", time.Time{}, ErrNoRealtimeSession
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", time.Time{}, err
+	}
+	ticket := base64.RawURLEncoding.EncodeToString(b)
+
+	if err := a.tickets.StoreRealtimeTicket(c.UserContext(), ticket, &data, RealtimeTicketTTL); err != nil {
+		return "", time.Time{}, err
+	}
+	return ticket, time.Now().Add(RealtimeTicketTTL), nil
+}
+
+// Authenticate нь realtime route-уудын authentication middleware буцаана.
+//
+// ?ticket= байвал ticket-ийг нэг удаа ашиглаж түүний session-ийг шалгана.
+// Байхгүй бол ердийн Authenticate (cookie, Authorization header) ажиллана.
+// Handler-ууд RealtimeSessionCheck-ээр холболтын шалгалтыг авна.
+func (a *RealtimeAuth) Authenticate() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(localsRealtimeAuth, a)
+
+		ticket := c.Query(realtimeTicketQuery)
+		if ticket == "" {
+			return a.authenticate(c)
+		}
+
+		data, err := a.tickets.TakeRealtimeTicket(c.UserContext(), ticket)
+		if err != nil {
+			a.log.Warn("realtime ticket lookup failed", zap.Error(err))
+			return fiber.NewError(fiber.StatusUnauthorized, fiber.ErrUnauthorized.Message)
+		}
+		if data == nil {
+			return fiber.NewError(fiber.StatusUnauthorized, "invalid or expired ticket")
+		}
+
+		if !a.attach(c, data) {
+			return fiber.NewError(fiber.StatusUnauthorized, fiber.ErrUnauthorized.Message)
+		}
+		return c.Next()
+	}
+}
+
+// attach нь ticket-ийн session-ийг шалгаж Claims-ийг Locals/context-д хадгална.
+func (a *RealtimeAuth) attach(c *fiber.Ctx, data *RealtimeTicket) bool {
+	if data.Local {
+		session, err := a.sessions.Get(c.UserContext(), data.SessionID)
+		if err != nil || session == nil || !time.Now().Before(session.ExpiresAt) {
+			return false
+		}
+		attachLocalSession(c, a.sessions, session)
+		return true
+	}
+
+	if a.sso == nil {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(c.UserContext(), sessionCheckTimeout)
+	defer cancel()
+	claims, err := a.sso.GetClaims(ctx, data.SessionID, "")
+	if err != nil {
+		return false
+	}
+	attachToCtx(c, data.SessionID, &claims)
+	return true
+}
+
+// ============================================================
+// SESSION LIVENESS
+// ============================================================
+
+// RealtimeSessionCheck нь request-ийн session logout/revoke хийгдсэн эсэхийг
+// шалгах функц буцаана. Холболт нээлттэй байх хугацаанд үе үе дуудаж false
+// бол холболтыг хаана. RealtimeAuth.Authenticate-ээр нэвтрээгүй бол nil.
+func RealtimeSessionCheck(c *fiber.Ctx) func(ctx context.Context) bool {
+	a, ok := c.Locals(localsRealtimeAuth).(*RealtimeAuth)
+	if !ok {
+		return nil
+	}
+	if session, ok := LocalSession(c); ok {
+		return a.sessionCheck(RealtimeTicket{SessionID: session.SessionID, Local: true})
+	}
+	if sid := ssoclient.GetSessionID(c); sid != "" {
+		return a.sessionCheck(RealtimeTicket{SessionID: sid})
+	}
+	return nil
+}
+
+// sessionCheck нь session байгаа эсэхийг шалгах функц буцаана.
+//
+// Redis түр алдаатай үед холболтыг хаахгүй: дараагийн шалгалтаар дахин
+// оролдоно. Session байхгүй, хугацаа дууссан бол false.
+func (a *RealtimeAuth) sessionCheck(data RealtimeTicket) func(ctx context.Context) bool {
+	return func(ctx context.Context) bool {
+		ctx, cancel := context.WithTimeout(ctx, sessionCheckTimeout)
+		defer cancel()
+
+		if data.Local {
+			session, err := a.sessions.Get(ctx, data.SessionID)
+			if err != nil {
+				a.log.Warn("realtime session check failed", zap.Error(err))
+				return true
+			}
+			return session != nil && time.Now().Before(session.ExpiresAt)
+		}
+
+		if a.sso == nil {
+			return false
+		}
+		_, err := a.sso.GetClaims(ctx, data.SessionID, "")
+		return err == nil
+	}
+}
@@ -7,6 +7,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -16,6 +17,14 @@ type RedisConfig struct {
 	Port     string
 	Password string
 	DB       int
+
+	// PermissionChannel is the pub/sub channel used to propagate
+	// permission cache invalidation between instances
+	PermissionChannel string
+
+	// RealtimeChannel is the pub/sub channel used to fan out
+	// WebSocket/SSE events to users connected to other instances
+	RealtimeChannel string
 }
 
 // Addr returns the Redis address in host:port format
@@ -31,6 +40,12 @@ type LocalAuthConfig struct {
 	// SessionTTL is the session lifetime
 	SessionTTL time.Duration
 
+	// AccessTokenTTL is the session lifetime when a refresh token is issued
+	AccessTokenTTL time.Duration
+
+	// RefreshTokenTTL is the refresh token lifetime
+	RefreshTokenTTL time.Duration
+
 	// MFATokenTTL is the MFA pending token lifetime
 	MFATokenTTL time.Duration
 
@@ -51,12 +66,67 @@ type LocalAuthConfig struct {
 
 	// EncryptionKey is the 32-byte key for encrypting TOTP secrets
 	EncryptionKey string
+
+	// WebAuthn relying party settings for passkey / security key MFA
+	WebAuthn WebAuthnConfig
+}
+
+// WebAuthnConfig holds WebAuthn relying party settings
+type WebAuthnConfig struct {
+	// RPID is the relying party ID: the site's registrable domain, without scheme or port
+	RPID string
+
+	// RPName is the name authenticators show during registration
+	RPName string
+
+	// Origins are the frontend origins allowed to run ceremonies
+	Origins []string
+
+	// Timeout is the ceremony timeout
+	Timeout time.Duration
+
+	// UserVerification is required, preferred or discouraged
+	UserVerification string
+}
+
+// MailConfig holds outgoing email settings
+type MailConfig struct {
+	// Driver selects the mail transport: smtp, file or noop
+	Driver string
+
+	// From is the sender address, e.g. "App <no-reply@example.com>"
+	From string
+
+	// AppName is used in subjects and message bodies
+	AppName string
+
+	// SMTP relay settings
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPTLS      string
+	SMTPTimeout  time.Duration
+
+	// OutboxDir is where the file driver writes .eml files
+	OutboxDir string
+
+	// MaxRetries is how many times a transient failure is retried
+	MaxRetries int
+
+	// VerifyURL, ResetURL and LoginURL are the frontend pages linked from emails.
+	// A "{token}" placeholder is replaced with the token, otherwise it is
+	// appended as a "token" query parameter.
+	VerifyURL string
+	ResetURL  string
+	LoginURL  string
 }
 
 // AuthConfig combines all auth-related configurations
 type AuthConfig struct {
 	Redis     RedisConfig
 	LocalAuth LocalAuthConfig
+	Mail      MailConfig
 }
 
 // LoadAuthConfig loads authentication configuration from environment variables
@@ -67,10 +137,15 @@ func LoadAuthConfig() *AuthConfig {
 			Port:     getEnv("REDIS_PORT", "6379"),
 			Password: getEnv("REDIS_PASSWORD", ""),
 			DB:       getEnvInt("REDIS_DB", 0),
+
+			PermissionChannel: getEnv("REDIS_PERMISSION_CHANNEL", "permission-cache:invalidate"),
+			RealtimeChannel:   getEnv("REDIS_REALTIME_CHANNEL", "realtime:events"),
 		},
 		LocalAuth: LocalAuthConfig{
 			Enabled:              getEnvBool("LOCAL_AUTH_ENABLED", true),
 			SessionTTL:           getEnvDuration("LOCAL_AUTH_SESSION_TTL", 24*time.Hour),
+			AccessTokenTTL:       getEnvDuration("LOCAL_AUTH_ACCESS_TOKEN_TTL", 15*time.Minute),
+			RefreshTokenTTL:      getEnvDuration("LOCAL_AUTH_REFRESH_TOKEN_TTL", 30*24*time.Hour),
 			MFATokenTTL:          getEnvDuration("LOCAL_AUTH_MFA_TOKEN_TTL", 5*time.Minute),
 			LockoutThreshold:     getEnvInt("LOCAL_AUTH_LOCKOUT_THRESHOLD", 5),
 			LockoutDuration:      getEnvDuration("LOCAL_AUTH_LOCKOUT_DURATION", 15*time.Minute),
@@ -78,6 +153,29 @@ func LoadAuthConfig() *AuthConfig {
 			PasswordHistoryCount: getEnvInt("LOCAL_AUTH_PASSWORD_HISTORY_COUNT", 5),
 			TOTPIssuer:           getEnv("LOCAL_AUTH_TOTP_ISSUER", "TemplateBackend"),
 			EncryptionKey:        getEnv("LOCAL_AUTH_ENCRYPTION_KEY", ""),
+			WebAuthn: WebAuthnConfig{
+				RPID:             getEnv("WEBAUTHN_RP_ID", "localhost"),
+				RPName:           getEnv("WEBAUTHN_RP_NAME", "TemplateBackend"),
+				Origins:          getEnvList("WEBAUTHN_ORIGINS", []string{"http://localhost:3000"}),
+				Timeout:          getEnvDuration("WEBAUTHN_TIMEOUT", 2*time.Minute),
+				UserVerification: getEnv("WEBAUTHN_USER_VERIFICATION", "preferred"),
+			},
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "file"),
+			From:         getEnv("MAIL_FROM", "TemplateBackend <no-reply@localhost>"),
+			AppName:      getEnv("MAIL_APP_NAME", "TemplateBackend"),
+			SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:     getEnvInt("SMTP_PORT", 587),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			SMTPTLS:      getEnv("SMTP_TLS", "starttls"),
+			SMTPTimeout:  getEnvDuration("SMTP_TIMEOUT", 10*time.Second),
+			OutboxDir:    getEnv("MAIL_OUTBOX_DIR", ""),
+			MaxRetries:   getEnvInt("MAIL_MAX_RETRIES", 3),
+			VerifyURL:    getEnv("MAIL_VERIFY_URL", "http://localhost:3000/verify-email/{token}"),
+			ResetURL:     getEnv("MAIL_RESET_URL", "http://localhost:3000/reset-password"),
+			LoginURL:     getEnv("MAIL_LOGIN_URL", "http://localhost:3000/login"),
 		},
 	}
 }
@@ -110,6 +208,21 @@ func getEnvBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+// getEnvList returns a comma-separated environment variable as a list or a default
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 // getEnvDuration returns the environment variable as duration or a default
 func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
@@ -0,0 +1,39 @@
+// Package config provides local configuration for auth and related features
+//
+// File: image_config.go
+// Description: Configuration for image thumbnails and resized variants
+package config
+
+// ImageConfig holds image variant settings
+type ImageConfig struct {
+	// Variants are the named sizes served by GET /file/:name?variant=,
+	// e.g. "thumb=150x150:crop" or "large=1600x1600" (fit inside the box)
+	Variants []string
+
+	// WebP enables ?format=webp (lossless; the source format is served
+	// when the WebP is not smaller)
+	WebP bool
+
+	// Eager renders every variant right after upload; otherwise each one
+	// is rendered on its first request
+	Eager bool
+
+	// JPEGQuality is used for variants of JPEG originals (1-100)
+	JPEGQuality int
+
+	// MaxPixels refuses to resize larger originals (memory bound)
+	MaxPixels int64
+}
+
+// LoadImageConfig loads image variant configuration from environment variables
+func LoadImageConfig() *ImageConfig {
+	return &ImageConfig{
+		Variants: getEnvList("IMAGE_VARIANTS", []string{
+			"thumb=150x150:crop", "small=320x320", "medium=800x800", "large=1600x1600",
+		}),
+		WebP:        getEnvBool("IMAGE_WEBP", true),
+		Eager:       getEnvBool("IMAGE_VARIANTS_EAGER", false),
+		JPEGQuality: getEnvInt("IMAGE_JPEG_QUALITY", 85),
+		MaxPixels:   int64(getEnvInt("IMAGE_MAX_PIXELS", 40_000_000)),
+	}
+}
@@ -0,0 +1,22 @@
+// Package config provides local configuration for auth and related features
+//
+// File: migrate_config.go
+// Description: Configuration for the embedded SQL migration runner
+package config
+
+// MigrateConfig holds database migration settings
+type MigrateConfig struct {
+	// AutoMigrate applies pending migrations on server startup
+	AutoMigrate bool
+
+	// Table is the version tracking table (created in DB_SCHEMA)
+	Table string
+}
+
+// LoadMigrateConfig loads migration configuration from environment variables
+func LoadMigrateConfig() *MigrateConfig {
+	return &MigrateConfig{
+		AutoMigrate: getEnvBool("DB_AUTO_MIGRATE", false),
+		Table:       getEnv("DB_MIGRATION_TABLE", "schema_migrations"),
+	}
+}
@@ -0,0 +1,71 @@
+// Package config provides local configuration for auth and related features
+//
+// File: notification_config.go
+// Description: Configuration for notification delivery transports and channels
+package config
+
+import "time"
+
+// NotificationConfig holds notification delivery settings
+type NotificationConfig struct {
+	// SocketAPIURL is the base URL of the external socket service
+	// (POST {url}/send, {url}/broadcast). Empty disables it; notifications
+	// are then delivered only over the built-in WebSocket/SSE stream.
+	SocketAPIURL string
+
+	// SocketTimeout bounds a single call to the external socket service
+	SocketTimeout time.Duration
+
+	// EmailEnabled sends notifications by email to users with
+	// notification_email on (uses the MAIL_* mailer)
+	EmailEnabled bool
+
+	// SMSURL is the JSON SMS gateway endpoint (POST {"to","text"}).
+	// Empty disables the SMS channel.
+	SMSURL string
+
+	// SMSToken is sent as a Bearer token to the SMS gateway
+	SMSToken string
+
+	// SMSTimeout bounds a single call to the SMS gateway
+	SMSTimeout time.Duration
+
+	// FCMCredentialsFile is a Firebase service account JSON file.
+	// Empty disables push to Android/web (fcm) devices.
+	FCMCredentialsFile string
+
+	// APNsKeyFile is the .p8 token signing key. Empty disables push to
+	// iOS (apns) devices. APNsKeyID, APNsTeamID and APNsTopic are required with it.
+	APNsKeyFile    string
+	APNsKeyID      string
+	APNsTeamID     string
+	APNsTopic      string
+	APNsProduction bool
+
+	// PushFake records pushes in memory instead of sending (local development)
+	PushFake bool
+
+	// PushTimeout bounds a single call to FCM or APNs
+	PushTimeout time.Duration
+}
+
+// LoadNotificationConfig loads notification configuration from environment variables
+func LoadNotificationConfig() *NotificationConfig {
+	return &NotificationConfig{
+		SocketAPIURL:  getEnv("NOTIFICATION_SOCKET_API_URL", ""),
+		SocketTimeout: getEnvDuration("NOTIFICATION_SOCKET_TIMEOUT", 3*time.Second),
+		EmailEnabled:  getEnvBool("NOTIFICATION_EMAIL_ENABLED", false),
+		SMSURL:        getEnv("NOTIFICATION_SMS_URL", ""),
+		SMSToken:      getEnv("NOTIFICATION_SMS_TOKEN", ""),
+		SMSTimeout:    getEnvDuration("NOTIFICATION_SMS_TIMEOUT", 5*time.Second),
+
+		FCMCredentialsFile: getEnv("NOTIFICATION_FCM_CREDENTIALS_FILE", ""),
+		APNsKeyFile:        getEnv("NOTIFICATION_APNS_KEY_FILE", ""),
+		APNsKeyID:          getEnv("NOTIFICATION_APNS_KEY_ID", ""),
+		APNsTeamID:         getEnv("NOTIFICATION_APNS_TEAM_ID", ""),
+		APNsTopic:          getEnv("NOTIFICATION_APNS_TOPIC", ""),
+		APNsProduction:     getEnvBool("NOTIFICATION_APNS_PRODUCTION", false),
+		PushFake:           getEnvBool("NOTIFICATION_PUSH_FAKE", false),
+		PushTimeout:        getEnvDuration("NOTIFICATION_PUSH_TIMEOUT", 5*time.Second),
+	}
+}
@@ -0,0 +1,32 @@
+// Package config provides local configuration for auth and related features
+//
+// File: scheduler_config.go
+// Description: Configuration for the scheduled job runner
+package config
+
+import "time"
+
+// SchedulerConfig holds scheduled job runner settings
+type SchedulerConfig struct {
+	// Enabled starts the poll loop on this instance (manual triggers work either way)
+	Enabled bool
+
+	// PollInterval is how often scheduled_jobs is checked for due jobs
+	PollInterval time.Duration
+
+	// Timeout bounds a single job execution
+	Timeout time.Duration
+
+	// MaxConcurrent caps running jobs; each holds a DB connection for its lock
+	MaxConcurrent int
+}
+
+// LoadSchedulerConfig loads scheduler configuration from environment variables
+func LoadSchedulerConfig() *SchedulerConfig {
+	return &SchedulerConfig{
+		Enabled:       getEnvBool("JOB_SCHEDULER_ENABLED", true),
+		PollInterval:  getEnvDuration("JOB_POLL_INTERVAL", 15*time.Second),
+		Timeout:       getEnvDuration("JOB_TIMEOUT", 10*time.Minute),
+		MaxConcurrent: getEnvInt("JOB_MAX_CONCURRENT", 4),
+	}
+}
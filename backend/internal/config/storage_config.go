@@ -0,0 +1,83 @@
+// Package config provides local configuration for auth and related features
+//
+// File: storage_config.go
+// Description: Configuration for uploaded file storage backends
+package config
+
+import "time"
+
+// StorageConfig holds file storage settings
+type StorageConfig struct {
+	// Driver selects the backend: local or s3. Use s3 when running more
+	// than one replica without a shared volume.
+	Driver string
+
+	// LocalDir is the root directory of the local driver
+	LocalDir string
+
+	// PublicURL is the prefix of public file URLs (served by GET /file/:name)
+	PublicURL string
+
+	// S3 settings; S3Endpoint is e.g. http://localhost:9000 for MinIO
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3Prefix    string
+
+	// S3PathStyle addresses the bucket in the path (required for MinIO)
+	S3PathStyle bool
+
+	// S3Timeout bounds a single S3 request, including the upload body
+	S3Timeout time.Duration
+
+	// PrivateLocalDir and S3PrivatePrefix hold private files. They must not
+	// overlap the public location, which GET /file/:name serves to anyone.
+	PrivateLocalDir string
+	S3PrivatePrefix string
+
+	// ChunkLocalDir and S3ChunkPrefix hold the chunks of resumable uploads
+	// until they are assembled. They must not overlap the public location.
+	ChunkLocalDir string
+	S3ChunkPrefix string
+
+	// PrivateURL is the prefix of signed private file links
+	PrivateURL string
+
+	// SigningSecret signs private file links (at least 32 bytes). Every
+	// replica needs the same value; changing it revokes outstanding links.
+	SigningSecret string
+
+	// SignedURLTTL is the default link lifetime, SignedURLMaxTTL the longest
+	// a client may ask for
+	SignedURLTTL    time.Duration
+	SignedURLMaxTTL time.Duration
+}
+
+// LoadStorageConfig loads storage configuration from environment variables
+func LoadStorageConfig() *StorageConfig {
+	return &StorageConfig{
+		Driver:    getEnv("STORAGE_DRIVER", "local"),
+		LocalDir:  getEnv("STORAGE_LOCAL_DIR", "/var/www/html/public"),
+		PublicURL: getEnv("STORAGE_PUBLIC_URL", "https://business.gerege.mn/api/file/"),
+
+		S3Endpoint:  getEnv("STORAGE_S3_ENDPOINT", ""),
+		S3Region:    getEnv("STORAGE_S3_REGION", "us-east-1"),
+		S3Bucket:    getEnv("STORAGE_S3_BUCKET", ""),
+		S3AccessKey: getEnv("STORAGE_S3_ACCESS_KEY", ""),
+		S3SecretKey: getEnv("STORAGE_S3_SECRET_KEY", ""),
+		S3Prefix:    getEnv("STORAGE_S3_PREFIX", ""),
+		S3PathStyle: getEnvBool("STORAGE_S3_PATH_STYLE", true),
+		S3Timeout:   getEnvDuration("STORAGE_S3_TIMEOUT", 60*time.Second),
+
+		PrivateLocalDir: getEnv("STORAGE_PRIVATE_LOCAL_DIR", "./storage/private"),
+		S3PrivatePrefix: getEnv("STORAGE_S3_PRIVATE_PREFIX", "private/"),
+		ChunkLocalDir:   getEnv("STORAGE_CHUNK_LOCAL_DIR", "./storage/chunks"),
+		S3ChunkPrefix:   getEnv("STORAGE_S3_CHUNK_PREFIX", "chunks/"),
+		PrivateURL:      getEnv("STORAGE_PRIVATE_URL", "https://business.gerege.mn/api/file/private/"),
+		SigningSecret:   getEnv("STORAGE_SIGNING_SECRET", ""),
+		SignedURLTTL:    getEnvDuration("STORAGE_SIGNED_URL_TTL", 5*time.Minute),
+		SignedURLMaxTTL: getEnvDuration("STORAGE_SIGNED_URL_MAX_TTL", 24*time.Hour),
+	}
+}
@@ -0,0 +1,64 @@
+// Package config provides local configuration for auth and related features
+//
+// File: upload_config.go
+// Description: Configuration for upload validation and malware scanning
+package config
+
+import "time"
+
+// UploadConfig holds upload validation settings
+type UploadConfig struct {
+	// AllowedTypes is the content type allowlist, detected from file
+	// contents. Entries may carry a size limit: "image/png:1MB".
+	AllowedTypes []string
+
+	// MaxSize is the limit for types without their own ("2MB"). The global
+	// request body limit still applies on top of it.
+	MaxSize string
+
+	// Scanner selects the malware scanner: none or clamav
+	Scanner string
+
+	// ClamAVAddr is the clamd address: tcp://host:3310 or unix:///path/clamd.ctl
+	ClamAVAddr string
+
+	// ClamAVTimeout bounds a single scan
+	ClamAVTimeout time.Duration
+
+	// QuarantineDir keeps infected uploads (with a .json record) for review
+	QuarantineDir string
+
+	// ResumableURL is the prefix of tus upload URLs (Location header)
+	ResumableURL string
+
+	// ResumableMaxSize caps a resumable upload ("1GB"). The type limits
+	// above still apply once the file is complete.
+	ResumableMaxSize string
+
+	// ResumableExpiry is how long an upload may sit idle before its chunks
+	// are deleted
+	ResumableExpiry time.Duration
+
+	// ResumableFinalizeTimeout bounds assembling, scanning and storing a
+	// completed upload
+	ResumableFinalizeTimeout time.Duration
+}
+
+// LoadUploadConfig loads upload configuration from environment variables
+func LoadUploadConfig() *UploadConfig {
+	return &UploadConfig{
+		AllowedTypes: getEnvList("UPLOAD_ALLOWED_TYPES", []string{
+			"image/png", "image/jpeg", "image/gif", "image/webp", "application/pdf",
+		}),
+		MaxSize:       getEnv("UPLOAD_MAX_SIZE", "2MB"),
+		Scanner:       getEnv("UPLOAD_SCANNER", "none"),
+		ClamAVAddr:    getEnv("UPLOAD_CLAMAV_ADDR", "tcp://localhost:3310"),
+		ClamAVTimeout: getEnvDuration("UPLOAD_CLAMAV_TIMEOUT", 30*time.Second),
+		QuarantineDir: getEnv("UPLOAD_QUARANTINE_DIR", "./tmp/quarantine"),
+
+		ResumableURL:             getEnv("UPLOAD_RESUMABLE_URL", "https://business.gerege.mn/api/uploads/"),
+		ResumableMaxSize:         getEnv("UPLOAD_RESUMABLE_MAX_SIZE", "1GB"),
+		ResumableExpiry:          getEnvDuration("UPLOAD_RESUMABLE_EXPIRY", 24*time.Hour),
+		ResumableFinalizeTimeout: getEnvDuration("UPLOAD_RESUMABLE_FINALIZE_TIMEOUT", 10*time.Minute),
+	}
+}
@@ -0,0 +1,552 @@
+// Package db provides implementation for db
+//
+// File: migrate.go
+// Description: Embedded SQL migration runner with version tracking
+/*
+Package db нь database connection-ийг удирдана.
+
+Энэ файл нь migrations/ хавтасны SQL файлуудыг дарааллаар нь хэрэгжүүлэх
+Migrator-ийг тодорхойлно.
+
+Features:
+  - Version tracking: хэрэгжсэн migration бүр schema_migrations хүснэгтэд бичигдэнэ
+  - Advisory lock: олон instance зэрэг эхлэхэд migration зөвхөн нэг удаа ажиллана
+  - Checksum drift: хэрэгжсэн файлын Up хэсэг өөрчлөгдсөн бол Up зогсоно
+  - Up/Down: Down хэсэгтэй migration-уудыг буцааж болно
+
+Файлын формат:
+
+	NNN_name.sql
+
+	-- +migrate Up
+	CREATE TABLE ...;
+
+	-- +migrate Down
+	DROP TABLE ...;
+
+"-- +migrate Up" тэмдэглэгээ заавал биш: Down тэмдэглэгээнээс өмнөх бүх
+агуулга Up болно. "-- +migrate NoTransaction" нь transaction дотор ажиллах
+боломжгүй statement-тэй (CREATE INDEX CONCURRENTLY) файлд зориулагдсан.
+
+Ашиглалт:
+
+	sqlDB, _ := gormDB.DB()
+	m, err := db.NewMigrator(sqlDB, migrations.FS, db.MigratorOptions{Schema: cfg.DB.Schema}, log)
+	if err != nil {
+	    log.Fatal("migrator init failed", zap.Error(err))
+	}
+	applied, err := m.Up(ctx)
+*/
+package db
+
+import (
+	"cmp"           // Version ordering
+	"context"       // Cancellation
+	"crypto/sha256" // Checksum
+	"database/sql"  // Dedicated connection (advisory lock)
+	"encoding/hex"  // Checksum encoding
+	"errors"        // Sentinel errors
+	"fmt"           // Error wrapping
+	"hash/fnv"      // Advisory lock key
+	"io/fs"         // Embedded / directory file systems
+	"regexp"        // File name parsing
+	"slices"        // Sorting
+	"strconv"       // Version parsing
+	"strings"       // Section parsing
+	"time"          // Applied timestamps
+
+	"go.uber.org/zap" // Structured logging
+)
+
+// DefaultMigrationTable нь version tracking хүснэгтийн анхдагч нэр.
+const DefaultMigrationTable = "schema_migrations"
+
+// Migration файлын тэмдэглэгээнүүд
+const (
+	markerUp            = "-- +migrate Up"
+	markerDown          = "-- +migrate Down"
+	markerNoTransaction = "-- +migrate NoTransaction"
+)
+
+var (
+	// ErrMigrationDrift нь хэрэгжсэн migration-ий файл өөрчлөгдсөн үед буцна.
+	ErrMigrationDrift = errors.New("applied migration checksum mismatch")
+
+	// ErrNoDownMigration нь Down хэсэггүй migration-ийг буцаах үед буцна.
+	ErrNoDownMigration = errors.New("migration has no down section")
+)
+
+// migrationFileRe нь "001_extensions.sql" хэлбэрийн файлын нэрийг задлана.
+var migrationFileRe = regexp.MustCompile(`^(\d+)_([A-Za-z0-9_\-]+)\.sql$`)
+
+// ============================================================
+// MIGRATION SOURCE
+// ============================================================
+
+// Migration нь нэг SQL migration файл.
+type Migration struct {
+	Version  int64  // Файлын нэрийн дугаар (001 → 1)
+	Name     string // Файлын нэрийн үлдсэн хэсэг
+	Up       string // Хэрэгжүүлэх SQL
+	Down     string // Буцаах SQL (хоосон бол буцаах боломжгүй)
+	NoTx     bool   // Transaction-гүй ажиллуулах
+	Checksum string // Up хэсгийн sha256 (drift илрүүлэх)
+}
+
+// LoadMigrations нь fsys-ийн root дахь NNN_name.sql файлуудыг version-оор
+// эрэмбэлж уншина. Бусад файлыг алгасна. Давхардсан version алдаа буцаана.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Migration
+	seen := make(map[int64]string)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		match := migrationFileRe.FindStringSubmatch(e.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", e.Name(), err)
+		}
+		if prev, ok := seen[version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %d: %s, %s", version, prev, e.Name())
+		}
+		seen[version] = e.Name()
+
+		content, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, parseMigration(version, match[2], string(content)))
+	}
+
+	slices.SortFunc(out, func(a, b Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	return out, nil
+}
+
+// parseMigration нь файлын агуулгыг Up/Down хэсэгт хуваана.
+// Checksum нь зөвхөн Up хэсгээс тооцогдох тул хэрэгжсэн файлд Down нэмж болно.
+func parseMigration(version int64, name, content string) Migration {
+	m := Migration{Version: version, Name: name}
+
+	var up, down strings.Builder
+	target := &up
+	for _, line := range strings.SplitAfter(content, "\n") {
+		switch strings.TrimSpace(line) {
+		case markerUp:
+			target = &up
+			continue
+		case markerDown:
+			target = &down
+			continue
+		case markerNoTransaction:
+			m.NoTx = true
+			continue
+		}
+		target.WriteString(line)
+	}
+
+	m.Up = strings.TrimSpace(up.String())
+	m.Down = strings.TrimSpace(down.String())
+	sum := sha256.Sum256([]byte(m.Up))
+	m.Checksum = hex.EncodeToString(sum[:])
+	return m
+}
+
+// ============================================================
+// MIGRATOR
+// ============================================================
+
+// MigratorOptions нь Migrator-ийн тохиргоо.
+type MigratorOptions struct {
+	// Schema нь tracking хүснэгтийн schema (хоосон бол search_path)
+	Schema string
+
+	// Table нь tracking хүснэгтийн нэр (хоосон бол DefaultMigrationTable)
+	Table string
+}
+
+// Migrator нь embed хийсэн migration-уудыг database-д хэрэгжүүлнэ.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	table      string // Schema-тай бүрэн нэр (quoted)
+	schema     string // Quoted schema (хоосон байж болно)
+	lockKey    int64  // pg_advisory_lock key
+	log        *zap.Logger
+}
+
+// MigrationStatus нь нэг migration-ий төлөв.
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+	Drift     bool // Хэрэгжсэн checksum файлынхаас ялгаатай
+	Missing   bool // Хэрэгжсэн боловч файл олдоогүй (шинэ binary-ийн migration)
+}
+
+// appliedMigration нь tracking хүснэгтийн нэг мөр.
+type appliedMigration struct {
+	Version   int64
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// NewMigrator нь fsys-ээс migration-уудыг уншиж Migrator үүсгэнэ.
+//
+// Parameters:
+//   - db: Database connection pool
+//   - fsys: Migration файлууд (ихэвчлэн migrations.FS)
+//   - opts: Tracking хүснэгтийн тохиргоо
+//   - log: Zap logger
+//
+// Returns:
+//   - *Migrator: Migrator
+//   - error: Файл унших алдаа
+func NewMigrator(db *sql.DB, fsys fs.FS, opts MigratorOptions, log *zap.Logger) (*Migrator, error) {
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Table == "" {
+		opts.Table = DefaultMigrationTable
+	}
+	table := quoteIdent(opts.Table)
+	schema := ""
+	if opts.Schema != "" {
+		schema = quoteIdent(opts.Schema)
+		table = schema + "." + table
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(table))
+
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+		table:      table,
+		schema:     schema,
+		lockKey:    int64(h.Sum64()),
+		log:        log,
+	}, nil
+}
+
+// Migrations нь уншсан migration-уудыг version-оор эрэмбэлж буцаана.
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Up нь хэрэгжээгүй бүх migration-ийг version-ийн дарааллаар хэрэгжүүлнэ.
+// Хэрэгжсэн migration-ий файл өөрчлөгдсөн бол юу ч хэрэгжүүлэхгүй ErrMigrationDrift буцаана.
+//
+// Returns:
+//   - []Migration: Энэ удаа хэрэгжсэн migration-ууд
+//   - error: Drift эсвэл SQL алдаа (алдаа гарсан migration хүртэлх нь хэрэгжсэн хэвээр)
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.checkDrift(applied); err != nil {
+			return err
+		}
+
+		var latest int64
+		for v := range applied {
+			latest = max(latest, v)
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if mig.Version < latest {
+				m.log.Warn("migration_out_of_order", zap.Int64("version", mig.Version), zap.Int64("latest", latest))
+			}
+			if err := m.exec(ctx, conn, mig, mig.Up, true); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down нь хамгийн сүүлд хэрэгжсэн steps ширхэг migration-ийг буцаана.
+// Down хэсэггүй migration-д хүрвэл ErrNoDownMigration буцааж зогсоно.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("%w: %03d_%s", ErrNoDownMigration, mig.Version, mig.Name)
+			}
+			if err := m.exec(ctx, conn, mig, mig.Down, false); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Baseline нь version хүртэлх migration-уудыг ажиллуулахгүйгээр хэрэгжсэн гэж тэмдэглэнэ.
+// Гараар migration хийсэн database-ийг Migrator руу шилжүүлэхэд ашиглана.
+func (m *Migrator) Baseline(ctx context.Context, version int64) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if mig.Version > version {
+				break
+			}
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if err := m.record(ctx, conn, mig); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status нь файл болон tracking хүснэгтийн migration бүрийн төлвийг буцаана.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var exists bool
+	if err := conn.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", m.table).Scan(&exists); err != nil {
+		return nil, err
+	}
+	applied := map[int64]appliedMigration{}
+	if exists {
+		if applied, err = m.applied(ctx, conn); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		st := MigrationStatus{Version: mig.Version, Name: mig.Name}
+		if a, ok := applied[mig.Version]; ok {
+			at := a.AppliedAt
+			st.Applied = true
+			st.AppliedAt = &at
+			st.Drift = a.Checksum != mig.Checksum
+			delete(applied, mig.Version)
+		}
+		out = append(out, st)
+	}
+	for _, a := range applied {
+		at := a.AppliedAt
+		out = append(out, MigrationStatus{Version: a.Version, Name: a.Name, Applied: true, AppliedAt: &at, Missing: true})
+	}
+	slices.SortFunc(out, func(a, b MigrationStatus) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+	return out, nil
+}
+
+// ============================================================
+// INTERNALS
+// ============================================================
+
+// withLock нь нэг connection дээр advisory lock авч fn-ийг ажиллуулна.
+// Advisory lock нь session-д хамаардаг тул бүх query ижил connection-аар явна.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", m.lockKey).Scan(&locked); err != nil {
+		return err
+	}
+	if !locked {
+		m.log.Info("migration_lock_waiting", zap.String("table", m.table))
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", m.lockKey); err != nil {
+			return err
+		}
+	}
+	defer func() {
+		// ctx цуцлагдсан байсан ч lock-ийг суллах ёстой.
+		// Migration файлууд search_path өөрчилдөг тул pool-д буцаахаас өмнө сэргээнэ.
+		cleanup := context.Background()
+		_, _ = conn.ExecContext(cleanup, "RESET search_path")
+		if _, err := conn.ExecContext(cleanup, "SELECT pg_advisory_unlock($1)", m.lockKey); err != nil {
+			m.log.Warn("migration_unlock_failed", zap.Error(err))
+		}
+	}()
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// ensureTable нь tracking хүснэгт (болон schema)-ийг үүсгэнэ.
+func (m *Migrator) ensureTable(ctx context.Context, conn *sql.Conn) error {
+	if m.schema != "" {
+		if _, err := conn.ExecContext(ctx, "CREATE SCHEMA IF NOT EXISTS "+m.schema); err != nil {
+			return err
+		}
+	}
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+m.table+` (
+		version     BIGINT PRIMARY KEY,
+		name        TEXT NOT NULL,
+		checksum    TEXT NOT NULL,
+		applied_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`)
+	return err
+}
+
+// applied нь tracking хүснэгтийн мөрүүдийг version-оор индексжүүлж буцаана.
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int64]appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM "+m.table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[int64]appliedMigration)
+	for rows.Next() {
+		var a appliedMigration
+		if err := rows.Scan(&a.Version, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, err
+		}
+		out[a.Version] = a
+	}
+	return out, rows.Err()
+}
+
+// checkDrift нь хэрэгжсэн migration-уудын checksum-ийг файлынхтай харьцуулна.
+// Файлд байхгүй хэрэгжсэн version нь (rolling deploy үед шинэ binary хэрэгжүүлсэн) алдаа биш.
+func (m *Migrator) checkDrift(applied map[int64]appliedMigration) error {
+	var drifted []string
+	for _, mig := range m.migrations {
+		a, ok := applied[mig.Version]
+		if ok && a.Checksum != mig.Checksum {
+			drifted = append(drifted, fmt.Sprintf("%03d_%s", mig.Version, mig.Name))
+		}
+	}
+	for v, a := range applied {
+		if !slices.ContainsFunc(m.migrations, func(mig Migration) bool { return mig.Version == v }) {
+			m.log.Warn("migration_unknown_version", zap.Int64("version", v), zap.String("name", a.Name))
+		}
+	}
+	if len(drifted) > 0 {
+		return fmt.Errorf("%w: %s", ErrMigrationDrift, strings.Join(drifted, ", "))
+	}
+	return nil
+}
+
+// exec нь нэг migration-ий SQL-ийг ажиллуулж tracking хүснэгтийг шинэчилнэ.
+// NoTx биш бол SQL болон tracking нэг transaction-д орно.
+func (m *Migrator) exec(ctx context.Context, conn *sql.Conn, mig Migration, query string, up bool) error {
+	start := time.Now()
+	track := func(ctx context.Context, q queryer) error {
+		if up {
+			return m.record(ctx, q, mig)
+		}
+		_, err := q.ExecContext(ctx, "DELETE FROM "+m.table+" WHERE version = $1", mig.Version)
+		return err
+	}
+
+	var err error
+	if mig.NoTx {
+		if _, err = conn.ExecContext(ctx, query); err == nil {
+			err = track(ctx, conn)
+		}
+	} else {
+		err = m.inTx(ctx, conn, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, query); err != nil {
+				return err
+			}
+			return track(ctx, tx)
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("migration %03d_%s: %w", mig.Version, mig.Name, err)
+	}
+
+	direction := "up"
+	if !up {
+		direction = "down"
+	}
+	m.log.Info("migration_applied",
+		zap.Int64("version", mig.Version),
+		zap.String("name", mig.Name),
+		zap.String("direction", direction),
+		zap.Duration("took", time.Since(start)),
+	)
+	return nil
+}
+
+// queryer нь *sql.Conn болон *sql.Tx-ийн нийтлэг хэсэг.
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// record нь migration-ийг tracking хүснэгтэд хэрэгжсэн гэж бичнэ.
+func (m *Migrator) record(ctx context.Context, q queryer, mig Migration) error {
+	_, err := q.ExecContext(ctx,
+		"INSERT INTO "+m.table+" (version, name, checksum) VALUES ($1, $2, $3)",
+		mig.Version, mig.Name, mig.Checksum,
+	)
+	return err
+}
+
+// inTx нь fn-ийг conn дээрх transaction-д ажиллуулна.
+func (m *Migrator) inTx(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// quoteIdent нь PostgreSQL identifier-ийг давхар хашилтад оруулна.
+func quoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
@@ -0,0 +1,108 @@
+// Package db provides database connection management
+//
+// File: migrate_test.go
+// Description: Unit tests for migration file loading
+package db
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"templatev25/migrations"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMigration(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantUp   string
+		wantDown string
+		wantNoTx bool
+	}{
+		{
+			name:    "no markers - whole file is up",
+			content: "-- header\nCREATE TABLE a (id INT);\n",
+			wantUp:  "-- header\nCREATE TABLE a (id INT);",
+		},
+		{
+			name:     "up and down",
+			content:  "-- +migrate Up\nCREATE TABLE a (id INT);\n\n-- +migrate Down\nDROP TABLE a;\n",
+			wantUp:   "CREATE TABLE a (id INT);",
+			wantDown: "DROP TABLE a;",
+		},
+		{
+			name:     "down without up marker",
+			content:  "CREATE TABLE a (id INT);\n-- +migrate Down\nDROP TABLE a;",
+			wantUp:   "CREATE TABLE a (id INT);",
+			wantDown: "DROP TABLE a;",
+		},
+		{
+			name:     "no transaction",
+			content:  "-- +migrate NoTransaction\nCREATE INDEX CONCURRENTLY i ON a(id);\n",
+			wantUp:   "CREATE INDEX CONCURRENTLY i ON a(id);",
+			wantNoTx: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := parseMigration(1, "test", tt.content)
+			assert.Equal(t, tt.wantUp, m.Up)
+			assert.Equal(t, tt.wantDown, m.Down)
+			assert.Equal(t, tt.wantNoTx, m.NoTx)
+			assert.Len(t, m.Checksum, 64)
+		})
+	}
+}
+
+func TestParseMigration_ChecksumIgnoresDown(t *testing.T) {
+	up := "CREATE TABLE a (id INT);\n"
+	before := parseMigration(1, "a", up)
+	after := parseMigration(1, "a", up+"\n-- +migrate Down\nDROP TABLE a;\n")
+	changed := parseMigration(1, "a", "CREATE TABLE a (id BIGINT);\n")
+
+	assert.Equal(t, before.Checksum, after.Checksum)
+	assert.NotEqual(t, before.Checksum, changed.Checksum)
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"010_ten.sql":     {Data: []byte("SELECT 10;")},
+		"002_two.sql":     {Data: []byte("SELECT 2;")},
+		"001_one.sql":     {Data: []byte("SELECT 1;")},
+		"README.md":       {Data: []byte("not a migration")},
+		"migrations.go":   {Data: []byte("package migrations")},
+		"sub/003_sub.sql": {Data: []byte("SELECT 3;")},
+	}
+
+	got, err := LoadMigrations(fsys)
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Equal(t, []int64{1, 2, 10}, []int64{got[0].Version, got[1].Version, got[2].Version})
+	assert.Equal(t, "ten", got[2].Name)
+}
+
+func TestLoadMigrations_DuplicateVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"001_one.sql": {Data: []byte("SELECT 1;")},
+		"1_again.sql": {Data: []byte("SELECT 1;")},
+	}
+
+	_, err := LoadMigrations(fsys)
+	assert.ErrorContains(t, err, "duplicate migration version 1")
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	got, err := LoadMigrations(migrations.FS)
+	require.NoError(t, err)
+	require.NotEmpty(t, got)
+
+	// Versions are contiguous from 1 and every file has SQL
+	for i, m := range got {
+		assert.Equal(t, int64(i+1), m.Version, m.Name)
+		assert.NotEmpty(t, m.Up, m.Name)
+	}
+}
@@ -191,6 +191,64 @@ func (bc *UserMFABackupCode) IsUsed() bool {
 	return bc.UsedAt != nil
 }
 
+// ============================================================
+// USER WEBAUTHN CREDENTIAL ENTITY
+// ============================================================
+
+// UserWebAuthnCredential нь WebAuthn (passkey, security key) credential хадгална.
+// Table: user_webauthn_credentials
+//
+// TOTP-ийн хажуугаар MFA хоёр дахь хүчин зүйл болж ашиглагдана.
+// Phishing-resistant: assertion нь RP ID болон origin-д холбогдсон.
+type UserWebAuthnCredential struct {
+	// ID нь primary key
+	ID int `json:"id" gorm:"primaryKey"`
+
+	// UserID нь users table руу foreign key
+	UserID int `json:"user_id" gorm:"index;not null"`
+
+	// CredentialID нь authenticator-ийн credential ID (base64url)
+	CredentialID string `json:"credential_id" gorm:"not null"`
+
+	// UserHandle нь authenticator-т хадгалагдах хэрэглэгчийн opaque ID (base64url)
+	UserHandle string `json:"-" gorm:"not null"`
+
+	// PublicKey нь COSE_Key хэлбэрээр encode хийгдсэн public key
+	PublicKey []byte `json:"-" gorm:"type:bytea;not null"`
+
+	// Algorithm нь COSE algorithm ID (-7 = ES256, -8 = EdDSA, -257 = RS256)
+	Algorithm int `json:"algorithm"`
+
+	// SignCount нь authenticator-ийн signature counter (clone илрүүлэхэд)
+	SignCount int64 `json:"-" gorm:"default:0"`
+
+	// AAGUID нь authenticator загварын ID (hex)
+	AAGUID string `json:"aaguid" gorm:"type:varchar(32)"`
+
+	// Transports нь таслалаар тусгаарлагдсан transport hint-үүд (usb, nfc, ble, internal, hybrid)
+	Transports string `json:"transports"`
+
+	// Name нь хэрэглэгчийн өгсөн нэр (жишээ: "YubiKey 5", "MacBook Touch ID")
+	Name string `json:"name" gorm:"type:varchar(100)"`
+
+	// BackupEligible нь synced passkey эсэх
+	BackupEligible bool `json:"backup_eligible" gorm:"default:false"`
+
+	// LastUsedAt нь сүүлд ашиглагдсан огноо
+	LastUsedAt *time.Time `json:"last_used_at"`
+
+	// ExtraFields нь audit талбаруудыг агуулна
+	ExtraFields
+
+	// User нь холбогдсон хэрэглэгч
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID;references:Id"`
+}
+
+// TableName returns the table name for GORM
+func (UserWebAuthnCredential) TableName() string {
+	return "user_webauthn_credentials"
+}
+
 // ============================================================
 // SESSION ENTITY
 // ============================================================
@@ -316,6 +374,8 @@ const (
 	AuditActionMFADisable     SecurityAuditAction = "mfa_disable"
 	AuditActionMFABackupUsed  SecurityAuditAction = "mfa_backup_used"
 	AuditActionMFABackupRegen SecurityAuditAction = "mfa_backup_regenerate"
+	AuditActionWebAuthnAdd    SecurityAuditAction = "webauthn_add"
+	AuditActionWebAuthnRemove SecurityAuditAction = "webauthn_remove"
 
 	// Session actions
 	AuditActionSessionCreate  SecurityAuditAction = "session_create"
@@ -323,6 +383,9 @@ const (
 	AuditActionSessionExpire  SecurityAuditAction = "session_expire"
 	AuditActionLogoutAll      SecurityAuditAction = "logout_all"
 
+	// Refresh token actions
+	AuditActionTokenReuse SecurityAuditAction = "refresh_token_reuse"
+
 	// Account actions
 	AuditActionAccountLock    SecurityAuditAction = "account_lock"
 	AuditActionAccountUnlock  SecurityAuditAction = "account_unlock"
@@ -538,12 +601,23 @@ type RefreshToken struct {
 	// SessionID нь session-тэй холбоотой
 	SessionID string `json:"session_id" gorm:"not null"`
 
+	// FamilyID нь нэг login-оос үүссэн rotation гинжийн ID.
+	// Rotate хийсэн token дахин ашиглагдвал бүхэл family цуцлагдана.
+	FamilyID string `json:"family_id" gorm:"index"`
+
+	// OrgID нь session-ий сонгосон байгууллага. Rotation-оор үүссэн шинэ
+	// session үүнийг авна; NULL бол хэрэглэгчийн default байгууллага.
+	OrgID *int `json:"org_id,omitempty" gorm:"column:organization_id"`
+
 	// ExpiresAt нь токен дуусах хугацаа
 	ExpiresAt time.Time `json:"expires_at" gorm:"not null"`
 
 	// RevokedAt нь токен цуцлагдсан хугацаа
 	RevokedAt *time.Time `json:"revoked_at"`
 
+	// ReplacedBy нь rotation-оор энэ токеныг сольсон шинэ токены ID
+	ReplacedBy *int `json:"replaced_by"`
+
 	// ExtraFields нь audit талбаруудыг агуулна
 	ExtraFields
 
@@ -566,6 +640,11 @@ func (t *RefreshToken) IsRevoked() bool {
 	return t.RevokedAt != nil
 }
 
+// IsRotated checks if the token has already been exchanged for a new one
+func (t *RefreshToken) IsRotated() bool {
+	return t.ReplacedBy != nil
+}
+
 // ============================================================
 // GORM HOOKS
 // ============================================================
@@ -0,0 +1,125 @@
+// Package domain provides implementation for domain
+//
+// File: chat_room.go
+// Description: Chat room, membership and message entities
+package domain
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Chat room-ийн төрлүүд (chat_rooms.type CHECK constraint-тэй ижил).
+const (
+	ChatRoomDirect  = "direct"
+	ChatRoomGroup   = "group"
+	ChatRoomChannel = "channel"
+	ChatRoomSupport = "support"
+)
+
+// Гишүүний эрхүүд (chat_room_members.role CHECK constraint-тэй ижил).
+const (
+	ChatRoleOwner     = "owner"
+	ChatRoleAdmin     = "admin"
+	ChatRoleModerator = "moderator"
+	ChatRoleMember    = "member"
+)
+
+// Мессежийн төрлүүд (chat_messages.message_type CHECK constraint-тэй ижил).
+const (
+	ChatMessageText     = "text"
+	ChatMessageImage    = "image"
+	ChatMessageVideo    = "video"
+	ChatMessageAudio    = "audio"
+	ChatMessageFile     = "file"
+	ChatMessageLocation = "location"
+	ChatMessageSystem   = "system"
+)
+
+// ChatRoom нь чат өрөө (chat_rooms). DeletedDate нь soft delete.
+type ChatRoom struct {
+	Id             int            `json:"id" gorm:"primaryKey"`
+	Name           string         `json:"name" gorm:"type:varchar(200)"`
+	Type           string         `json:"type" gorm:"type:varchar(50);not null;default:direct"`
+	OrganizationId *int           `json:"organization_id"`
+	CreatedBy      *int           `json:"created_by"`
+	AvatarUrl      string         `json:"avatar_url" gorm:"type:varchar(500)"`
+	Description    string         `json:"description"`
+	Settings       datatypes.JSON `json:"settings" gorm:"type:jsonb;default:'{}'"`
+	LastMessageAt  *time.Time     `json:"last_message_at"`
+	IsActive       *bool          `json:"is_active" gorm:"default:true"`
+	CreatedDate    time.Time      `json:"created_date" gorm:"autoCreateTime"`
+	UpdatedDate    time.Time      `json:"updated_date" gorm:"autoUpdateTime"`
+	DeletedDate    gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// ChatRoomMember нь өрөөний гишүүнчлэл (chat_room_members).
+// Гарсан гишүүн is_active=false, left_at-тай үлдэнэ.
+type ChatRoomMember struct {
+	Id                 int        `json:"id" gorm:"primaryKey"`
+	RoomId             int        `json:"room_id" gorm:"not null;uniqueIndex:idx_chat_room_members_room_user"`
+	UserId             int        `json:"user_id" gorm:"not null;uniqueIndex:idx_chat_room_members_room_user"`
+	Role               string     `json:"role" gorm:"type:varchar(50);default:member"`
+	Nickname           string     `json:"nickname" gorm:"type:varchar(100)"`
+	LastReadAt         *time.Time `json:"last_read_at"`
+	NotificationsMuted bool       `json:"notifications_muted" gorm:"default:false"`
+	IsActive           *bool      `json:"is_active" gorm:"default:true"`
+	JoinedAt           time.Time  `json:"joined_at" gorm:"autoCreateTime"`
+	LeftAt             *time.Time `json:"left_at"`
+	CreatedDate        time.Time  `json:"created_date" gorm:"autoCreateTime"`
+	UpdatedDate        time.Time  `json:"updated_date" gorm:"autoUpdateTime"`
+}
+
+// CanManage нь гишүүн өрөөний тохиргоо, гишүүдийг удирдах эрхтэй эсэх.
+func (m ChatRoomMember) CanManage() bool {
+	return m.Role == ChatRoleOwner || m.Role == ChatRoleAdmin
+}
+
+// CanModerate нь гишүүн бусдын мессежийг устгах эрхтэй эсэх.
+func (m ChatRoomMember) CanModerate() bool {
+	return m.CanManage() || m.Role == ChatRoleModerator
+}
+
+// ChatMessage нь өрөөний мессеж (chat_messages).
+// ParentId нь thread-ийн эх мессеж; устгахад is_deleted=true болно.
+type ChatMessage struct {
+	Id          int            `json:"id" gorm:"primaryKey"`
+	RoomId      int            `json:"room_id" gorm:"not null;index"`
+	SenderId    *int           `json:"sender_id" gorm:"index"`
+	ParentId    *int           `json:"parent_id" gorm:"index"`
+	MessageType string         `json:"message_type" gorm:"type:varchar(50);default:text"`
+	Content     string         `json:"content"`
+	FileUrl     string         `json:"file_url" gorm:"type:varchar(1000)"`
+	FileName    string         `json:"file_name" gorm:"type:varchar(500)"`
+	FileSize    *int64         `json:"file_size"`
+	Metadata    datatypes.JSON `json:"metadata" gorm:"type:jsonb;default:'{}'"`
+	IsEdited    bool           `json:"is_edited" gorm:"default:false"`
+	EditedAt    *time.Time     `json:"edited_at"`
+	IsDeleted   bool           `json:"is_deleted" gorm:"default:false"`
+	CreatedDate time.Time      `json:"created_date" gorm:"autoCreateTime"`
+	UpdatedDate time.Time      `json:"updated_date" gorm:"autoUpdateTime"`
+}
+
+// Redacted нь устгагдсан мессежийн агуулгыг нууж буцаана.
+func (m ChatMessage) Redacted() ChatMessage {
+	if !m.IsDeleted {
+		return m
+	}
+	m.Content = ""
+	m.FileUrl = ""
+	m.FileName = ""
+	m.FileSize = nil
+	m.Metadata = nil
+	return m
+}
+
+// ChatRoomSummary нь хэрэглэгчийн өрөөний жагсаалтын мөр:
+// өрөө + тухайн хэрэглэгчийн эрх, уншсан цаг, уншаагүй мессежийн тоо.
+type ChatRoomSummary struct {
+	ChatRoom    `gorm:"embedded"`
+	MyRole      string     `json:"my_role"`
+	LastReadAt  *time.Time `json:"last_read_at"`
+	UnreadCount int64      `json:"unread_count"`
+}
@@ -281,3 +281,84 @@ func TestSystem_Structure(t *testing.T) {
 	assert.Equal(t, "Admin System", system.Name)
 	assert.True(t, *system.IsActive)
 }
+
+func TestValidPermissionCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"admin.user.read", true},
+		{"admin.user.*", true},
+		{"admin.*", true},
+		{"*", true},
+		{"", false},
+		{"admin..read", false},
+		{"admin.*.read", false},
+		{"admin.user*", false},
+		{"admin.", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			assert.Equal(t, tt.want, ValidPermissionCode(tt.code))
+		})
+	}
+}
+
+func TestPermissionGrantCodes(t *testing.T) {
+	assert.Equal(t,
+		[]string{"admin.user.read", "admin.user.*", "admin.*", "*"},
+		PermissionGrantCodes("admin.user.read"))
+	assert.Equal(t, []string{"admin", "*"}, PermissionGrantCodes("admin"))
+}
+
+func TestBuildOrganizationTree(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+
+	// Ministry → Department → Division → Unit
+	nodes := []Organization{
+		{Id: 1, Name: "Ministry", ParentId: intPtr(100)}, // parent outside the list
+		{Id: 2, Name: "Department A", ParentId: intPtr(1)},
+		{Id: 3, Name: "Department B", ParentId: intPtr(1)},
+		{Id: 4, Name: "Division", ParentId: intPtr(2)},
+		{Id: 5, Name: "Unit", ParentId: intPtr(4)},
+	}
+
+	tree := BuildOrganizationTree(nodes)
+
+	require.Len(t, tree, 1)
+	root := tree[0]
+	assert.Equal(t, 1, root.Id)
+	require.NotNil(t, root.Children)
+	require.Len(t, *root.Children, 2)
+	assert.Equal(t, "Department A", (*root.Children)[0].Name)
+	assert.Equal(t, "Department B", (*root.Children)[1].Name)
+	assert.Nil(t, (*root.Children)[1].Children)
+
+	division := (*(*root.Children)[0].Children)[0]
+	assert.Equal(t, 4, division.Id)
+	require.NotNil(t, division.Children)
+	assert.Equal(t, 5, (*division.Children)[0].Id)
+
+	// Input is not modified
+	assert.Nil(t, nodes[0].Children)
+}
+
+func TestBuildOrganizationTree_Cycle(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+
+	nodes := []Organization{
+		{Id: 1, ParentId: intPtr(2)},
+		{Id: 2, ParentId: intPtr(1)},
+		{Id: 3, ParentId: intPtr(3)},
+	}
+
+	tree := BuildOrganizationTree(nodes)
+
+	require.Len(t, tree, 2)
+	assert.Equal(t, 3, tree[0].Id)
+	assert.Equal(t, 1, tree[1].Id)
+	require.NotNil(t, tree[1].Children)
+	assert.Equal(t, 2, (*tree[1].Children)[0].Id)
+	assert.Nil(t, (*tree[1].Children)[0].Children)
+}
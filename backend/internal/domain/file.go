@@ -8,6 +8,13 @@
 // Last Updated: 2025-02-20
 package domain
 
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
 type PublicFile struct {
 	Id          int    `json:"id" gorm:"primaryKey"`
 	Name        string `json:"name" gorm:"type:varchar(255)"`
@@ -16,3 +23,49 @@ type PublicFile struct {
 	FileUrl     string `json:"file_url" gorm:"type:varchar(255)"`
 	ExtraFields
 }
+
+// File нь upload бүрийн бүртгэл (files хүснэгт, migration 007). StoredName нь
+// upload-ийн өөрийн нэр (URL), StoragePath нь агуулгыг хадгалсан blob-ийн key,
+// Checksum нь агуулгын SHA-256 (hex). Ижил агуулгатай upload-ууд нэг blob-ийг
+// (BlobId) хуваалцана.
+// IsPublic = false файлууд (иргэний үнэмлэх, төлбөрийн хуулга гэх мэт) нийтийн
+// storage-оос тусдаа хадгалагдаж, зөвхөн эзэмшигч, эрхтэй хэрэглэгч эсвэл
+// signed URL-аар татагдана.
+type File struct {
+	Id              int            `json:"id" gorm:"primaryKey"`
+	UserId          *int           `json:"user_id"`
+	OrganizationId  *int           `json:"organization_id"`
+	OriginalName    string         `json:"original_name" gorm:"type:varchar(500);not null"`
+	StoredName      string         `json:"stored_name" gorm:"type:varchar(500);not null"`
+	MimeType        string         `json:"mime_type" gorm:"type:varchar(100)"`
+	FileSize        int64          `json:"file_size"`
+	StoragePath     string         `json:"-" gorm:"type:varchar(1000);not null"`
+	StorageProvider string         `json:"storage_provider" gorm:"type:varchar(50);default:local"`
+	PublicUrl       string         `json:"public_url,omitempty" gorm:"type:varchar(1000)"`
+	ThumbnailUrl    string         `json:"thumbnail_url,omitempty" gorm:"type:varchar(1000)"`
+	Checksum        string         `json:"checksum,omitempty" gorm:"type:varchar(100)"`
+	BlobId          *int           `json:"-" gorm:"index"`
+	Metadata        datatypes.JSON `json:"metadata,omitempty" gorm:"type:jsonb;default:'{}'"`
+	IsPublic        bool           `json:"is_public"`
+	DownloadCount   int            `json:"download_count"`
+	CreatedDate     time.Time      `json:"created_date" gorm:"autoCreateTime"`
+	UpdatedDate     time.Time      `json:"updated_date" gorm:"autoUpdateTime"`
+	DeletedDate     gorm.DeletedAt `json:"-" gorm:"column:deleted_date;index"`
+}
+
+// FileBlob нь storage-д нэг л удаа хадгалагдсан агуулга (file_blobs). Ижил
+// SHA-256-тай upload-ууд нэг blob-ийг заах бөгөөд RefCount нь түүнийг заасан
+// устгагдаагүй files мөрийн тоо; 0 болмогц blob storage-оос устна.
+// Нийтийн, хувийн файлууд тусдаа storage-д байх тул IsPublic-ээр тусгаарлана.
+type FileBlob struct {
+	Id              int       `json:"id" gorm:"primaryKey"`
+	StorageProvider string    `json:"storage_provider" gorm:"type:varchar(50);not null;uniqueIndex:idx_file_blobs_content,priority:1"`
+	IsPublic        bool      `json:"is_public" gorm:"not null;uniqueIndex:idx_file_blobs_content,priority:2"`
+	Checksum        string    `json:"checksum" gorm:"type:varchar(64);not null;uniqueIndex:idx_file_blobs_content,priority:3"`
+	StoragePath     string    `json:"-" gorm:"type:varchar(1000);not null"`
+	MimeType        string    `json:"mime_type" gorm:"type:varchar(100)"`
+	FileSize        int64     `json:"file_size"`
+	RefCount        int       `json:"ref_count" gorm:"not null;default:1"`
+	CreatedDate     time.Time `json:"created_date" gorm:"autoCreateTime"`
+	UpdatedDate     time.Time `json:"updated_date" gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,43 @@
+// Package domain provides implementation for domain
+//
+// File: file_upload.go
+// Description: Resumable (tus) uploads in progress and their stored chunks
+package domain
+
+import "time"
+
+// Resumable upload statuses (file_uploads.status)
+const (
+	FileUploadUploading  = "uploading"
+	FileUploadFinalizing = "finalizing"
+	FileUploadCompleted  = "completed"
+)
+
+// FileUpload нь tus-аар хэсэгчлэн орж буй файл (file_uploads).
+// Бүх byte ирмэгц PublicFile болж, FileUrl бөглөгдөнө; ExpiresAt хүртэл
+// үргэлжлүүлээгүй upload-ийг scheduled job устгана.
+type FileUpload struct {
+	Id           string     `json:"id" gorm:"type:varchar(36);primaryKey"`
+	UserId       int        `json:"user_id" gorm:"index;not null"`
+	Filename     string     `json:"filename" gorm:"type:varchar(500)"`
+	Description  string     `json:"description"`
+	UploadLength int64      `json:"upload_length" gorm:"not null"`
+	UploadOffset int64      `json:"upload_offset" gorm:"not null;default:0"`
+	Status       string     `json:"status" gorm:"type:varchar(20);not null;default:uploading"`
+	FileUrl      string     `json:"file_url" gorm:"type:varchar(1000)"`
+	ExpiresAt    time.Time  `json:"expires_at" gorm:"index;not null"`
+	CompletedAt  *time.Time `json:"completed_at"`
+	CreatedDate  time.Time  `json:"created_date" gorm:"autoCreateTime"`
+	UpdatedDate  time.Time  `json:"updated_date" gorm:"autoUpdateTime"`
+}
+
+// FileUploadChunk нь нэг PATCH-ээр ирсэн хэсэг (file_upload_chunks).
+// (upload_id, chunk_offset) давтагдахгүй тул ижил offset-д зэрэг ирсэн
+// хоёр PATCH-ийн зөвхөн нэг нь бүртгэгдэнэ.
+type FileUploadChunk struct {
+	UploadId    string    `json:"upload_id" gorm:"type:varchar(36);primaryKey"`
+	ChunkOffset int64     `json:"chunk_offset" gorm:"primaryKey;autoIncrement:false"`
+	Size        int64     `json:"size" gorm:"not null"`
+	StorageKey  string    `json:"-" gorm:"type:varchar(255);not null"`
+	CreatedDate time.Time `json:"created_date" gorm:"autoCreateTime"`
+}
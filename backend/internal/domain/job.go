@@ -0,0 +1,57 @@
+// Package domain provides implementation for domain
+//
+// File: job.go
+// Description: Scheduled job and job execution entities
+package domain
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Job execution-ий төлөвүүд (job_executions.status CHECK constraint-тэй ижил).
+const (
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+// ScheduledJob нь cron хуваарьтай ажил (scheduled_jobs).
+// Handler нь scheduler-т бүртгэгдсэн Go handler-ийн нэр.
+type ScheduledJob struct {
+	Id              int            `json:"id" gorm:"primaryKey"`
+	Name            string         `json:"name" gorm:"type:varchar(100);not null"`
+	Code            string         `json:"code" gorm:"type:varchar(100);uniqueIndex;not null"`
+	Description     string         `json:"description"`
+	CronExpression  string         `json:"cron_expression" gorm:"type:varchar(100)"`
+	Handler         string         `json:"handler" gorm:"type:varchar(255);not null"`
+	Parameters      datatypes.JSON `json:"parameters" gorm:"type:jsonb;default:'{}'"`
+	IsActive        *bool          `json:"is_active" gorm:"default:true"`
+	LastRunAt       *time.Time     `json:"last_run_at"`
+	LastRunStatus   string         `json:"last_run_status" gorm:"type:varchar(50)"`
+	LastRunDuration *int           `json:"last_run_duration"`
+	LastError       string         `json:"last_error"`
+	NextRunAt       *time.Time     `json:"next_run_at"`
+	CreatedDate     time.Time      `json:"created_date" gorm:"autoCreateTime"`
+	UpdatedDate     time.Time      `json:"updated_date" gorm:"autoUpdateTime"`
+}
+
+// Active нь job идэвхтэй эсэхийг буцаана (NULL бол идэвхтэй).
+func (j ScheduledJob) Active() bool {
+	return j.IsActive == nil || *j.IsActive
+}
+
+// JobExecution нь job-ийн нэг удаагийн ажиллагаа (job_executions).
+type JobExecution struct {
+	Id           int            `json:"id" gorm:"primaryKey"`
+	JobId        int            `json:"job_id" gorm:"not null;index"`
+	Status       string         `json:"status" gorm:"type:varchar(50);not null"`
+	StartedAt    time.Time      `json:"started_at" gorm:"not null"`
+	FinishedAt   *time.Time     `json:"finished_at"`
+	DurationMs   *int           `json:"duration_ms"`
+	Result       datatypes.JSON `json:"result" gorm:"type:jsonb"`
+	ErrorMessage string         `json:"error_message"`
+	CreatedDate  time.Time      `json:"created_date" gorm:"autoCreateTime"`
+}
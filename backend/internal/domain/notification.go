@@ -8,6 +8,19 @@
 // Last Updated: 2025-02-20
 package domain
 
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Notification group types
+const (
+	NotificationTypeDirect    = "dm"            // Нэг хэрэглэгч (UserId)
+	NotificationTypeBroadcast = "broadcast_all" // Бүх хэрэглэгч
+	NotificationTypeTargeted  = "targeted"      // Target-ийн дагуу сонгосон хэрэглэгчид
+)
+
 type NotificationGroup struct {
 	Id              int    `gorm:"primaryKey" json:"id"`
 	UserId          int    `json:"user_id" gorm:"index"`
@@ -16,9 +29,47 @@ type NotificationGroup struct {
 	Type            string `json:"type" gorm:"size:20"`
 	Tenant          string `json:"tenant" gorm:"size:50"`
 	CreatedUsername string `json:"created_username" gorm:"size:100"`
+
+	// Target нь "targeted" мэдэгдлийн хүлээн авагчийг сонгосон нөхцөл.
+	Target datatypes.JSONType[NotificationTarget] `json:"target" gorm:"type:jsonb"`
+	// RecipientCount нь илгээх үед тодорхойлогдсон хүлээн авагчийн тоо.
+	RecipientCount int `json:"recipient_count"`
+	// FanOutOnRead нь хэрэглэгч бүрт notifications мөр үүсгээгүй broadcast.
+	// Хэрэглэгчийн жагсаалтад group-ээс уншигдаж, уншсан төлөв
+	// notification_reads, notification_read_marks-д хадгалагдана.
+	FanOutOnRead bool `json:"fan_out_on_read" gorm:"default:false"`
 	ExtraFields
 }
 
+// NotificationRead нь fan-out-on-read group-ийг хэрэглэгч уншсан тэмдэг.
+type NotificationRead struct {
+	GroupId int       `json:"group_id" gorm:"primaryKey"`
+	UserId  int       `json:"user_id" gorm:"primaryKey;index"`
+	ReadAt  time.Time `json:"read_at"`
+}
+
+// NotificationReadMark нь хэрэглэгчийн "бүгдийг уншсан" хугацаа:
+// үүнээс өмнө үүссэн fan-out-on-read group бүгд уншсанд тооцогдоно.
+type NotificationReadMark struct {
+	UserId    int       `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	ReadAllAt time.Time `json:"read_all_at"`
+}
+
+// NotificationTarget нь мэдэгдлийн хүлээн авагчдыг сонгох нөхцөл.
+// Нөхцөлүүд нэгдэл (OR) байдлаар ажиллана: аль нэгт таарсан хэрэглэгч хүлээн авна.
+type NotificationTarget struct {
+	RoleCodes      []string `json:"role_codes,omitempty"`       // Эдгээр role-той (шууд эсвэл өвлөсөн, хугацаа дуусаагүй; байгууллагын олголт нь гишүүн бол)
+	OrgIDs         []int    `json:"org_ids,omitempty"`          // Эдгээр байгууллагын гишүүд
+	IncludeSubOrgs bool     `json:"include_sub_orgs,omitempty"` // OrgIDs-ийн дэд байгууллагуудыг оруулах
+	OrgTypeIDs     []int    `json:"org_type_ids,omitempty"`     // Эдгээр төрлийн байгууллагын гишүүд
+	UserIDs        []int    `json:"user_ids,omitempty"`         // Шууд заасан хэрэглэгчид
+}
+
+// Empty нь ямар ч нөхцөл заагаагүй эсэх.
+func (t NotificationTarget) Empty() bool {
+	return len(t.RoleCodes) == 0 && len(t.OrgIDs) == 0 && len(t.OrgTypeIDs) == 0 && len(t.UserIDs) == 0
+}
+
 type Notification struct {
 	Id              int    `gorm:"primaryKey" json:"id"`
 	UserId          int    `json:"user_id" gorm:"index"`
@@ -60,6 +60,58 @@ type Organization struct {
 	ExtraFields
 }
 
+// BuildOrganizationTree нь хавтгай жагсаалтыг ParentId-аар нь үүрлэсэн мод болгоно.
+// Эцэг нь жагсаалтад байхгүй node-ууд root болно. Жагсаалтын дараалал
+// (жишээ: sequence, name) хүүхдүүдийн дотор хадгалагдана.
+// Өгөгдөлд цикл байсан ч node бүр зөвхөн нэг удаа орно.
+func BuildOrganizationTree(nodes []Organization) []Organization {
+	present := make(map[int]bool, len(nodes))
+	for _, n := range nodes {
+		present[n.Id] = true
+	}
+
+	children := make(map[int][]int, len(nodes))
+	var roots []int
+	for i, n := range nodes {
+		if n.ParentId != nil && present[*n.ParentId] && *n.ParentId != n.Id {
+			children[*n.ParentId] = append(children[*n.ParentId], i)
+			continue
+		}
+		roots = append(roots, i)
+	}
+
+	visited := make(map[int]bool, len(nodes))
+	var build func(i int) Organization
+	build = func(i int) Organization {
+		node := nodes[i]
+		visited[node.Id] = true
+		node.Children = nil
+		var kids []Organization
+		for _, ci := range children[node.Id] {
+			if visited[nodes[ci].Id] {
+				continue
+			}
+			kids = append(kids, build(ci))
+		}
+		if len(kids) > 0 {
+			node.Children = &kids
+		}
+		return node
+	}
+
+	out := make([]Organization, 0, len(roots))
+	for _, i := range roots {
+		out = append(out, build(i))
+	}
+	// Циклд орсон (root-гүй) node-ууд: жагсаалтын эхний node-оос нь эхлүүлнэ
+	for i, n := range nodes {
+		if !visited[n.Id] {
+			out = append(out, build(i))
+		}
+	}
+	return out
+}
+
 type OrganizationUser struct {
 	OrgId        int           `json:"org_id"`
 	UserId       int           `json:"user_id"`
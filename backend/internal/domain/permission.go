@@ -8,6 +8,8 @@
 // Last Updated: 2025-02-20
 package domain
 
+import "strings"
+
 type Permission struct {
 	ID          int     `json:"id" gorm:"primaryKey"`
 	Code        string  `json:"code" gorm:"unique;not null;type:varchar(255)"`
@@ -22,3 +24,64 @@ type Permission struct {
 	IsActive    *bool   `json:"is_active" gorm:"not null;default:true"`
 	ExtraFields
 }
+
+// PermissionWildcard нь permission кодын сүүлийн сегментийг орлох тэмдэгт.
+// "admin.user.*" нь "admin.user." -ээр эхэлсэн бүх кодыг, "*" нь бүх кодыг хамарна.
+const PermissionWildcard = "*"
+
+// IsWildcard нь permission wildcard grant эсэхийг буцаана.
+func (p Permission) IsWildcard() bool {
+	return IsWildcardPermissionCode(p.Code)
+}
+
+// IsWildcardPermissionCode нь код wildcard ("*" эсвэл "x.y.*") эсэхийг буцаана.
+func IsWildcardPermissionCode(code string) bool {
+	return code == PermissionWildcard || strings.HasSuffix(code, "."+PermissionWildcard)
+}
+
+// ValidPermissionCode нь кодын хэлбэрийг шалгана: хоосон сегментгүй,
+// "*" зөвхөн сүүлийн сегмент бүтнээрээ байж болно.
+func ValidPermissionCode(code string) bool {
+	if code == "" {
+		return false
+	}
+	segs := strings.Split(code, ".")
+	for i, seg := range segs {
+		if seg == "" {
+			return false
+		}
+		if strings.Contains(seg, PermissionWildcard) && (seg != PermissionWildcard || i != len(segs)-1) {
+			return false
+		}
+	}
+	return true
+}
+
+// PermissionGrantCodes нь тухайн кодыг олгох боломжтой бүх grant кодыг буцаана:
+// код өөрөө болон түүний эцэг сегментүүдийн wildcard-ууд.
+//
+// Жишээ: "admin.user.read" → ["admin.user.read", "admin.user.*", "admin.*", "*"]
+func PermissionGrantCodes(code string) []string {
+	segs := strings.Split(code, ".")
+	out := make([]string, 0, len(segs)+1)
+	out = append(out, code)
+	for i := len(segs) - 1; i > 0; i-- {
+		out = append(out, strings.Join(segs[:i], ".")+"."+PermissionWildcard)
+	}
+	return append(out, PermissionWildcard)
+}
+
+// PermissionGrantTrace нь хэрэглэгчээс permission хүртэлх нэг зам:
+// user_roles → roles (өвлөлтийн гинж) → role_permissions → permissions.
+// Устгагдсан, хугацаа дууссан бичлэгүүдийг шүүлгүй ачаална (access explain).
+type PermissionGrantTrace struct {
+	// Assignment нь хэрэглэгчид олгосон role (OrgID, ExpiresAt, DeletedDate).
+	Assignment UserRole
+	// Roles нь олгосон role-оос permission эзэмшигч role хүртэлх гинж.
+	// Эхний элемент нь Assignment.RoleID, сүүлийнх нь role_permissions-ийн role.
+	Roles []Role
+	// RolePermissionDeleted нь role_permissions холбоос устгагдсан эсэх.
+	RolePermissionDeleted bool
+	// Permission нь grant код (яг таарц эсвэл wildcard).
+	Permission Permission
+}
@@ -17,9 +17,17 @@ type Role struct {
 	Description  string  `json:"description" gorm:"type:varchar(255)"`
 	IsActive     *bool   `json:"is_active"`
 	IsSystemRole *bool   `json:"is_system_role" gorm:"default:false"`
+	// Parents нь энэ role-ийн permission-уудыг өвлүүлэх эцэг role-ууд (role_parents)
+	Parents []Role `json:"parents,omitempty" gorm:"many2many:role_parents;joinForeignKey:RoleID;joinReferences:ParentID"`
 	ExtraFields
 }
 
+// RoleParent нь role өвлөлтийн холбоос: RoleID нь ParentID-ийн permission-уудыг өвлөнө.
+type RoleParent struct {
+	RoleID   int `json:"role_id" gorm:"primaryKey"`
+	ParentID int `json:"parent_id" gorm:"primaryKey"`
+}
+
 type RolePermission struct {
 	RoleID       int         `json:"role_id"`
 	PermissionID int         `json:"permission_id"`
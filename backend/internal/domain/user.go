@@ -22,6 +22,8 @@ Database tables:
 */
 package domain
 
+import "time"
+
 // ============================================================
 // USER ENTITY
 // ============================================================
@@ -123,8 +125,9 @@ type User struct {
 // Энэ нь many-to-many холбоос:
 // - Нэг хэрэглэгч олон эрхтэй байж болно
 // - Нэг эрх олон хэрэглэгчид хуваарилагдаж болно
+// - Эрхийг тодорхой байгууллага дотор олгож болно (OrgID)
 //
-// Composite primary key: (user_id, role_id)
+// Unique key: (user_id, role_id, COALESCE(organization_id, 0))
 //
 // GORM Foreign Key тайлбар:
 //   - foreignKey:UserId: Энэ struct-ийн UserId талбар
@@ -147,6 +150,15 @@ type UserRole struct {
 	// GORM-ийн Preload("Role") ашиглаж авна.
 	Role *Role `json:"role,omitempty" gorm:"foreignKey:RoleID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;"`
 
+	// OrgID нь эрх олгогдсон байгууллагын ID (organizations.id руу FK).
+	// NULL бол бүх байгууллагад хүчинтэй (global) эрх.
+	// Утгатай бол зөвхөн тухайн байгууллагыг сонгосон үед хүчинтэй.
+	OrgID *int `json:"org_id,omitempty" gorm:"column:organization_id"`
+
+	// ExpiresAt нь олголт дуусах хугацаа.
+	// NULL бол хугацаагүй. Хугацаа өнгөрсөн олголт эрх шалгахад тооцогдохгүй.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
 	// ExtraFields нь нийтлэг timestamp талбаруудыг агуулна.
 	ExtraFields
 }
@@ -0,0 +1,34 @@
+// Package domain provides implementation for domain
+//
+// File: user_device.go
+// Description: Registered mobile/web devices and their push tokens
+package domain
+
+import "time"
+
+// Push providers (user_devices.push_provider)
+const (
+	PushProviderFCM  = "fcm"
+	PushProviderAPNs = "apns"
+)
+
+// UserDevice нь хэрэглэгчийн бүртгүүлсэн төхөөрөмж (user_devices).
+// (user_id, device_id) давтагдахгүй; push provider токеныг хүчингүй гэж
+// мэдээлбэл IsActive = false болно.
+type UserDevice struct {
+	Id           int        `json:"id" gorm:"primaryKey"`
+	UserId       int        `json:"user_id" gorm:"uniqueIndex:uq_user_devices_user_device;not null"`
+	DeviceId     string     `json:"device_id" gorm:"type:varchar(255);uniqueIndex:uq_user_devices_user_device;not null"`
+	DeviceType   string     `json:"device_type" gorm:"type:varchar(50)"`
+	DeviceName   string     `json:"device_name" gorm:"type:varchar(150)"`
+	DeviceModel  string     `json:"device_model" gorm:"type:varchar(150)"`
+	OsName       string     `json:"os_name" gorm:"type:varchar(50)"`
+	OsVersion    string     `json:"os_version" gorm:"type:varchar(50)"`
+	AppVersion   string     `json:"app_version" gorm:"type:varchar(50)"`
+	PushToken    string     `json:"-"`
+	PushProvider string     `json:"push_provider" gorm:"type:varchar(50)"`
+	IsActive     bool       `json:"is_active"`
+	LastUsedAt   *time.Time `json:"last_used_at"`
+	CreatedDate  time.Time  `json:"created_date" gorm:"autoCreateTime"`
+	UpdatedDate  time.Time  `json:"updated_date" gorm:"autoUpdateTime"`
+}
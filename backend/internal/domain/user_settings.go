@@ -0,0 +1,84 @@
+// Package domain provides implementation for domain
+//
+// File: user_settings.go
+// Description: Per-user preferences and notification recipient read model
+package domain
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// UserSettings нь хэрэглэгчийн тохиргоо (user_settings, нэг хэрэглэгчид нэг мөр).
+// Мөр байхгүй бол DefaultUserSettings-ийн утгаар тооцно.
+// Bool талбарууд GORM default tag-гүй: false утга INSERT-д орхигдохгүй.
+type UserSettings struct {
+	Id                   int            `json:"id" gorm:"primaryKey"`
+	UserId               int            `json:"user_id" gorm:"uniqueIndex;not null"`
+	NotificationPush     bool           `json:"notification_push"`
+	NotificationEmail    bool           `json:"notification_email"`
+	NotificationSms      bool           `json:"notification_sms"`
+	Theme                string         `json:"theme" gorm:"type:varchar(20)"`
+	Language             string         `json:"language" gorm:"type:varchar(10)"`
+	PrivacyProfilePublic bool           `json:"privacy_profile_public"`
+	PrivacyShowEmail     bool           `json:"privacy_show_email"`
+	PrivacyShowPhone     bool           `json:"privacy_show_phone"`
+	CustomSettings       datatypes.JSON `json:"custom_settings" gorm:"type:jsonb;default:'{}'"`
+	CreatedDate          time.Time      `json:"created_date" gorm:"autoCreateTime"`
+	UpdatedDate          time.Time      `json:"updated_date" gorm:"autoUpdateTime"`
+}
+
+// DefaultUserSettings нь user_settings хүснэгтийн DEFAULT утгууд.
+func DefaultUserSettings(userID int) UserSettings {
+	return UserSettings{
+		UserId:            userID,
+		NotificationPush:  true,
+		NotificationEmail: true,
+		NotificationSms:   false,
+		Theme:             "system",
+		Language:          "mn",
+		CustomSettings:    datatypes.JSON("{}"),
+	}
+}
+
+// NotificationRecipient нь мэдэгдэл хүргэхэд хэрэгтэй хэрэглэгчийн холбоо
+// барих мэдээлэл ба сувгийн тохиргоо (users + user_settings).
+type NotificationRecipient struct {
+	UserId            int    `json:"user_id"`
+	Email             string `json:"email"`
+	PhoneNo           string `json:"phone_no"`
+	Language          string `json:"language"`
+	NotificationPush  bool   `json:"notification_push"`
+	NotificationEmail bool   `json:"notification_email"`
+	NotificationSms   bool   `json:"notification_sms"`
+}
+
+// Notification delivery channels. In-app хүргэлт (DB + realtime) үргэлж
+// идэвхтэй тул энд бүртгэгдэхгүй.
+const (
+	NotificationChannelEmail = "email"
+	NotificationChannelSMS   = "sms"
+	NotificationChannelPush  = "push"
+)
+
+// Notification delivery statuses
+const (
+	NotificationDeliverySent    = "sent"
+	NotificationDeliveryFailed  = "failed"
+	NotificationDeliverySkipped = "skipped" // Суваг идэвхтэй ч хаяг (email, утас, төхөөрөмж) байхгүй
+)
+
+// NotificationDelivery нь нэг хэрэглэгч рүү нэг сувгаар хүргэсэн оролдлогын бүртгэл.
+// Broadcast (fan-out-on-read)-ийн хувьд NotificationId = 0.
+type NotificationDelivery struct {
+	Id             int       `json:"id" gorm:"primaryKey"`
+	NotificationId int       `json:"notification_id" gorm:"index"`
+	GroupId        int       `json:"group_id" gorm:"index"`
+	UserId         int       `json:"user_id" gorm:"index"`
+	Channel        string    `json:"channel" gorm:"type:varchar(20)"`
+	Provider       string    `json:"provider" gorm:"type:varchar(50)"`
+	Status         string    `json:"status" gorm:"type:varchar(20)"`
+	Error          string    `json:"error,omitempty"`
+	CreatedDate    time.Time `json:"created_date" gorm:"autoCreateTime"`
+}
@@ -4,7 +4,11 @@
 // Description: DTOs for authentication, MFA, and session management
 package dto
 
-import "time"
+import (
+	"time"
+
+	"templatev25/internal/webauthn"
+)
 
 // ============================================================
 // LOGIN DTOs
@@ -14,15 +18,28 @@ import "time"
 type LoginRequest struct {
 	Email    string `json:"email"    validate:"required,email"`
 	Password string `json:"password" validate:"required,min=8"`
+	// IssueRefreshToken true үед богино хугацаатай session + refresh token олгоно
+	IssueRefreshToken bool `json:"issue_refresh_token"`
 }
 
 // LoginResponse нь login хариу
 type LoginResponse struct {
-	RequiresMFA bool      `json:"requires_mfa,omitempty"`
-	MFAToken    string    `json:"mfa_token,omitempty"`
-	AccessToken string    `json:"access_token,omitempty"`
-	ExpiresAt   int64     `json:"expires_at,omitempty"`
-	User        *UserInfo `json:"user,omitempty"`
+	RequiresMFA bool   `json:"requires_mfa,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
+	// MFAMethods нь ашиглаж болох хүчин зүйлс: totp, webauthn, backup_code
+	MFAMethods []string `json:"mfa_methods,omitempty"`
+	// WebAuthnOptions нь navigator.credentials.get()-д дамжуулах options
+	WebAuthnOptions  *webauthn.RequestOptions `json:"webauthn_options,omitempty"`
+	AccessToken      string                   `json:"access_token,omitempty"`
+	ExpiresAt        int64                    `json:"expires_at,omitempty"`
+	RefreshToken     string                   `json:"refresh_token,omitempty"`
+	RefreshExpiresAt int64                    `json:"refresh_expires_at,omitempty"`
+	User             *UserInfo                `json:"user,omitempty"`
+}
+
+// RefreshTokenRequest нь refresh token солих хүсэлт
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
 // UserInfo нь login хариунд буцаах хэрэглэгчийн мэдээлэл
@@ -38,10 +55,12 @@ type UserInfo struct {
 // MFA DTOs
 // ============================================================
 
-// VerifyMFARequest нь MFA баталгаажуулах хүсэлт
+// VerifyMFARequest нь MFA баталгаажуулах хүсэлт.
+// TOTP code эсвэл WebAuthn assertion-ий аль нэгийг илгээнэ.
 type VerifyMFARequest struct {
-	MFAToken string `json:"mfa_token" validate:"required"`
-	Code     string `json:"code"      validate:"required,len=6"`
+	MFAToken string                      `json:"mfa_token" validate:"required"`
+	Code     string                      `json:"code"      validate:"omitempty,len=6"`
+	WebAuthn *webauthn.AssertionResponse `json:"webauthn,omitempty"`
 }
 
 // VerifyBackupCodeRequest нь backup code баталгаажуулах хүсэлт
@@ -68,9 +87,39 @@ type DisableTOTPRequest struct {
 
 // MFAStatusResponse нь MFA төлөвийн хариу
 type MFAStatusResponse struct {
-	Enabled        bool `json:"enabled"`
-	HasBackupCodes bool `json:"has_backup_codes"`
-	BackupCodesLeft int `json:"backup_codes_left,omitempty"`
+	Enabled             bool                         `json:"enabled"`
+	TOTPEnabled         bool                         `json:"totp_enabled"`
+	HasBackupCodes      bool                         `json:"has_backup_codes"`
+	BackupCodesLeft     int                          `json:"backup_codes_left,omitempty"`
+	Methods             []string                     `json:"methods"`
+	WebAuthnCredentials []WebAuthnCredentialResponse `json:"webauthn_credentials"`
+}
+
+// WebAuthnCredentialResponse нь бүртгэлтэй WebAuthn credential-ийн мэдээлэл
+type WebAuthnCredentialResponse struct {
+	ID             int        `json:"id"`
+	Name           string     `json:"name"`
+	Transports     []string   `json:"transports,omitempty"`
+	BackupEligible bool       `json:"backup_eligible"`
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty"`
+}
+
+// WebAuthnRegisterFinishRequest нь WebAuthn бүртгэл дуусгах хүсэлт
+type WebAuthnRegisterFinishRequest struct {
+	Name       string                         `json:"name"       validate:"omitempty,max=100"`
+	Credential *webauthn.RegistrationResponse `json:"credential" validate:"required"`
+}
+
+// WebAuthnRegisterFinishResponse нь WebAuthn бүртгэлийн хариу.
+// Анхны MFA хүчин зүйл бол backup codes хамт буцна.
+type WebAuthnRegisterFinishResponse struct {
+	Credential  WebAuthnCredentialResponse `json:"credential"`
+	BackupCodes []string                   `json:"backup_codes,omitempty"`
+}
+
+// RemoveWebAuthnRequest нь WebAuthn credential устгах хүсэлт
+type RemoveWebAuthnRequest struct {
+	Password string `json:"password" validate:"required"`
 }
 
 // BackupCodesResponse нь backup codes хариу
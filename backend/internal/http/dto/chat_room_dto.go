@@ -0,0 +1,84 @@
+// Package dto provides implementation for dto
+//
+// File: chat_room_dto.go
+// Description: Chat room, membership and message requests
+package dto
+
+import (
+	"encoding/json"
+
+	"git.gerege.mn/backend-packages/common"
+)
+
+// ChatRoomListQuery нь хэрэглэгчийн өрөөнүүдийн жагсаалтын шүүлтүүр.
+type ChatRoomListQuery struct {
+	Type string `query:"type" validate:"omitempty,oneof=direct group channel support"`
+	common.PaginationQuery
+}
+
+// ChatRoomCreateDto нь өрөө үүсгэх хүсэлт.
+// Direct өрөөнд MemberIDs яг нэг хэрэглэгч агуулна.
+type ChatRoomCreateDto struct {
+	Type           string          `json:"type" validate:"required,oneof=direct group channel support"`
+	Name           string          `json:"name" validate:"omitempty,max=200"`
+	Description    string          `json:"description"`
+	AvatarUrl      string          `json:"avatar_url" validate:"omitempty,max=500"`
+	OrganizationId *int            `json:"organization_id" validate:"omitempty,gt=0"`
+	Settings       json.RawMessage `json:"settings" swaggertype:"object"`
+	MemberIDs      []int           `json:"member_ids" validate:"omitempty,dive,gt=0"`
+}
+
+// ChatRoomUpdateDto нь өрөөний мэдээлэл засах хүсэлт. Nil талбар өөрчлөгдөхгүй.
+type ChatRoomUpdateDto struct {
+	Name        *string         `json:"name" validate:"omitempty,max=200"`
+	Description *string         `json:"description"`
+	AvatarUrl   *string         `json:"avatar_url" validate:"omitempty,max=500"`
+	Settings    json.RawMessage `json:"settings" swaggertype:"object"`
+}
+
+// ChatMembersAddDto нь өрөөнд гишүүд нэмэх хүсэлт.
+type ChatMembersAddDto struct {
+	UserIDs []int `json:"user_ids" validate:"required,min=1,dive,gt=0"`
+}
+
+// ChatMemberRoleDto нь гишүүний эрх солих хүсэлт.
+type ChatMemberRoleDto struct {
+	Role string `json:"role" validate:"required,oneof=admin moderator member"`
+}
+
+// ChatMemberParams нь /:id/members/:user_id path параметрүүд.
+type ChatMemberParams struct {
+	ID     int `params:"id" validate:"required,gt=0"`
+	UserID int `params:"user_id" validate:"required,gt=0"`
+}
+
+// ChatMessageListQuery нь мессежийн түүхийн шүүлтүүр (шинэ нь эхэндээ).
+// ParentID өгвөл тухайн thread-ийн хариултуудыг, үгүй бол үндсэн мессежүүдийг буцаана.
+// BeforeID нь scroll хийхэд ашиглах cursor.
+type ChatMessageListQuery struct {
+	ParentID int `query:"parent_id" validate:"omitempty,gt=0"`
+	BeforeID int `query:"before_id" validate:"omitempty,gt=0"`
+	common.PaginationQuery
+}
+
+// ChatMessageSendDto нь мессеж илгээх хүсэлт.
+type ChatMessageSendDto struct {
+	MessageType string          `json:"message_type" validate:"omitempty,oneof=text image video audio file location"`
+	Content     string          `json:"content" validate:"required_without=FileUrl,max=10000"`
+	ParentId    *int            `json:"parent_id" validate:"omitempty,gt=0"`
+	FileUrl     string          `json:"file_url" validate:"omitempty,max=1000"`
+	FileName    string          `json:"file_name" validate:"omitempty,max=500"`
+	FileSize    *int64          `json:"file_size" validate:"omitempty,gte=0"`
+	Metadata    json.RawMessage `json:"metadata" swaggertype:"object"`
+}
+
+// ChatMessageEditDto нь мессеж засах хүсэлт.
+type ChatMessageEditDto struct {
+	Content string `json:"content" validate:"required,max=10000"`
+}
+
+// ChatMessageParams нь /:id/messages/:message_id path параметрүүд.
+type ChatMessageParams struct {
+	ID        int `params:"id" validate:"required,gt=0"`
+	MessageID int `params:"message_id" validate:"required,gt=0"`
+}
@@ -0,0 +1,20 @@
+// Package dto provides implementation for dto
+//
+// File: job_dto.go
+// Description: Scheduled job list and execution history queries
+package dto
+
+import "git.gerege.mn/backend-packages/common"
+
+// JobListQuery нь scheduled job-уудын жагсаалтын шүүлтүүр.
+type JobListQuery struct {
+	Handler  string `query:"handler"`
+	IsActive *bool  `query:"is_active"`
+	common.PaginationQuery
+}
+
+// JobExecutionListQuery нь job-ийн ажиллагааны түүхийн шүүлтүүр.
+type JobExecutionListQuery struct {
+	Status string `query:"status" validate:"omitempty,oneof=running completed failed cancelled"`
+	common.PaginationQuery
+}
@@ -8,14 +8,38 @@
 // Last Updated: 2025-02-20
 package dto
 
+import "git.gerege.mn/backend-packages/common"
+
 type NotificationReadDto struct {
 	GroupId int `json:"group_id" validate:"required,gt=0"`
 }
 
 type NotificationSendDto struct {
 	Tenant        string `json:"tenant" validate:"required"`
-	UserID        int    `json:"user_id"` // 0 бол broadcast_all
+	UserID        int    `json:"user_id"` // 0 бөгөөд target хоосон бол broadcast_all
 	Title         string `json:"title"`
 	Content       string `json:"content"`
 	IdempotentKey string `json:"idempotency_key"`
+
+	// Target заавал хүлээн авагчдыг role, байгууллага, байгууллагын төрөл,
+	// user_ids-ийн нэгдлээр сонгоно ("targeted").
+	Target *NotificationTargetDto `json:"target" validate:"omitempty"`
+}
+
+// NotificationTargetDto нь мэдэгдлийн хүлээн авагчийг сонгох нөхцөл.
+type NotificationTargetDto struct {
+	RoleCodes      []string `json:"role_codes" validate:"omitempty,dive,required"`
+	OrgIDs         []int    `json:"org_ids" validate:"omitempty,dive,gt=0"`
+	IncludeSubOrgs bool     `json:"include_sub_orgs"`
+	OrgTypeIDs     []int    `json:"org_type_ids" validate:"omitempty,dive,gt=0"`
+	UserIDs        []int    `json:"user_ids" validate:"omitempty,dive,gt=0"`
+}
+
+// NotificationDeliveryListQuery нь сувгийн хүргэлтийн бүртгэлийн шүүлтүүр.
+type NotificationDeliveryListQuery struct {
+	GroupID int    `query:"group_id" validate:"omitempty,gt=0"`
+	UserID  int    `query:"user_id" validate:"omitempty,gt=0"`
+	Channel string `query:"channel" validate:"omitempty,oneof=email sms push"`
+	Status  string `query:"status" validate:"omitempty,oneof=sent failed skipped"`
+	common.PaginationQuery
 }
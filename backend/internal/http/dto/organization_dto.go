@@ -46,6 +46,11 @@ type OrganizationTreeQuery struct {
 	OrgId int `query:"org_id" validate:"required"`
 }
 
+// OrganizationMoveDto — parent_id null эсвэл 0 бол root болгоно
+type OrganizationMoveDto struct {
+	ParentID *int `json:"parent_id" validate:"omitempty,gte=0"`
+}
+
 type OrganizationTypeDto struct {
 	Code        string `json:"code" validate:"required,max=255"`
 	Name        string `json:"name" validate:"required,max=255"`
@@ -8,7 +8,11 @@
 // Last Updated: 2025-02-20
 package dto
 
-import "git.gerege.mn/backend-packages/common"
+import (
+	"time"
+
+	"git.gerege.mn/backend-packages/common"
+)
 
 // Query: /permissions?search=...&module_id=...&page=1&size=20&sort=code:asc,name:desc
 type PermissionQuery struct {
@@ -25,6 +29,16 @@ type PermissionCreateDto struct {
 	ActionIDs []int64 `json:"action_ids" validate:"required,min=1,dive,gt=0"`
 }
 
+// PermissionWildcardCreateDto нь wildcard grant ("admin.user.*", "admin.*", "*") үүсгэнэ.
+// ModuleID нь permission-ийг editor-т харагдах module-д бүлэглэнэ.
+type PermissionWildcardCreateDto struct {
+	SystemID    int    `json:"system_id"   validate:"required,gt=0"`
+	ModuleID    int    `json:"module_id"   validate:"required,gt=0"`
+	Code        string `json:"code"        validate:"required,max=255"`
+	Name        string `json:"name"        validate:"max=255"`
+	Description string `json:"description" validate:"max=255"`
+}
+
 type PermissionUpdateDto struct {
 	Code        string `json:"code"        validate:"required"`
 	Name        string `json:"name"        validate:"required"`
@@ -34,3 +48,56 @@ type PermissionUpdateDto struct {
 	ActionID    *int64 `json:"action_id"`
 	IsActive    *bool  `json:"is_active"`
 }
+
+// PermissionExplainQuery: /permission/explain?user_id=7&code=admin.user.read&org_id=3
+// OrgID нь хэрэглэгчийн сонгосон байгууллага (0 бол зөвхөн global олголт).
+type PermissionExplainQuery struct {
+	UserID int    `query:"user_id" validate:"required,gt=0"`
+	Code   string `query:"code"    validate:"required,max=255"`
+	OrgID  int    `query:"org_id"  validate:"omitempty,gt=0"`
+}
+
+// PermissionExplainResponse нь RequirePermission-ийн шийдвэр болон түүний шалтгаан.
+// Reasons нь Granted=false үед замуудын татгалзсан шалтгаануудын нэгдэл.
+type PermissionExplainResponse struct {
+	UserID  int                    `json:"user_id"`
+	Code    string                 `json:"code"`
+	OrgID   int                    `json:"org_id,omitempty"`
+	Granted bool                   `json:"granted"`
+	Reasons []string               `json:"reasons,omitempty"`
+	Paths   []PermissionAccessPath `json:"paths"`
+}
+
+// PermissionAccessPath нь user_roles → roles → role_permissions → permissions нэг зам.
+type PermissionAccessPath struct {
+	Granted     bool                     `json:"granted"`
+	DenyReasons []string                 `json:"deny_reasons,omitempty"`
+	Assignment  PermissionPathAssignment `json:"assignment"`
+	Roles       []PermissionPathRole     `json:"roles"` // Олгосон role → ... → permission эзэмшигч role
+	Permission  PermissionPathGrant      `json:"permission"`
+}
+
+// PermissionPathAssignment нь user_roles бичлэг.
+type PermissionPathAssignment struct {
+	RoleID    int        `json:"role_id"`
+	OrgID     *int       `json:"org_id,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Deleted   bool       `json:"deleted"`
+}
+
+// PermissionPathRole нь гинжин дэх нэг role.
+type PermissionPathRole struct {
+	ID      int    `json:"id"`
+	Code    string `json:"code"`
+	Name    string `json:"name"`
+	Deleted bool   `json:"deleted"`
+}
+
+// PermissionPathGrant нь role_permissions-ээр олгосон permission (яг таарц эсвэл wildcard).
+type PermissionPathGrant struct {
+	ID                    int    `json:"id"`
+	Code                  string `json:"code"`
+	Active                bool   `json:"active"`
+	Deleted               bool   `json:"deleted"`
+	RolePermissionDeleted bool   `json:"role_permission_deleted"`
+}
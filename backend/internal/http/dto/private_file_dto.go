@@ -0,0 +1,14 @@
+// Package dto provides implementation for dto
+//
+// File: private_file_dto.go
+// Description: Signed download link request for private files
+package dto
+
+// PrivateFileSignDto нь POST /file/private/:name/sign-ийн body.
+// expires_in (секунд) өгөөгүй бол STORAGE_SIGNED_URL_TTL; STORAGE_SIGNED_URL_MAX_TTL-ээс
+// урт бол түүгээр хязгаарлана. bind_user үед link зөвхөн үүсгэсэн хэрэглэгчид
+// (нэвтэрсэн үед) ажиллана.
+type PrivateFileSignDto struct {
+	ExpiresIn int  `json:"expires_in" validate:"omitempty,min=1"`
+	BindUser  bool `json:"bind_user"`
+}
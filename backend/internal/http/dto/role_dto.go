@@ -8,7 +8,11 @@
 // Last Updated: 2025-02-20
 package dto
 
-import "git.gerege.mn/backend-packages/common"
+import (
+	"templatev25/internal/domain"
+
+	"git.gerege.mn/backend-packages/common"
+)
 
 type RoleListQuery struct {
 	SystemId int   `query:"system_id" validate:"omitempty,gt=0"`
@@ -22,6 +26,9 @@ type RoleCreateDto struct {
 	Name        string `json:"name"        validate:"required,min=2,max=255"`
 	Description string `json:"description" validate:"max=255"`
 	IsActive    *bool  `json:"is_active,omitempty"`
+	// ParentIDs нь permission өвлүүлэх эцэг role-ууд.
+	// Update-д nil бол өөрчлөхгүй, хоосон массив бол бүгдийг хасна.
+	ParentIDs []int `json:"parent_ids,omitempty" validate:"omitempty,dive,gt=0"`
 }
 
 type RoleUpdateDto RoleCreateDto
@@ -34,3 +41,10 @@ type RolePermissionsUpdateDto struct {
 	RoleID        int   `json:"role_id"        validate:"required,gt=0"`
 	PermissionIDs []int `json:"permission_ids" validate:"required,min=0,dive,gt=0"`
 }
+
+// RoleEffectivePermission нь role-д шууд эсвэл wildcard grant-аар олгогдсон permission.
+// GrantedBy хоосон бол шууд олгосон, утгатай бол түүнийг хамарсан wildcard код.
+type RoleEffectivePermission struct {
+	domain.Permission
+	GrantedBy string `json:"granted_by,omitempty"`
+}
@@ -0,0 +1,19 @@
+// Package dto provides implementation for dto
+//
+// File: user_device_dto.go
+// Description: Device registration payload for push notifications
+package dto
+
+// UserDeviceRegisterDto нь POST /me/devices-ийн body.
+// Ижил device_id-аар дахин илгээвэл мэдээлэл, push token шинэчлэгдэнэ.
+type UserDeviceRegisterDto struct {
+	DeviceId     string `json:"device_id" validate:"required,max=255"`
+	DeviceType   string `json:"device_type" validate:"omitempty,oneof=ios android web"`
+	DeviceName   string `json:"device_name" validate:"omitempty,max=150"`
+	DeviceModel  string `json:"device_model" validate:"omitempty,max=150"`
+	OsName       string `json:"os_name" validate:"omitempty,max=50"`
+	OsVersion    string `json:"os_version" validate:"omitempty,max=50"`
+	AppVersion   string `json:"app_version" validate:"omitempty,max=50"`
+	PushToken    string `json:"push_token" validate:"omitempty,max=4096"`
+	PushProvider string `json:"push_provider" validate:"required_with=PushToken,omitempty,oneof=fcm apns"`
+}
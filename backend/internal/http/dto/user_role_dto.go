@@ -12,25 +12,33 @@ import "git.gerege.mn/backend-packages/common"
 
 type UserRoleUsersQuery struct {
 	RoleID int `query:"role_id" validate:"required"`
+	// OrgID өгвөл зөвхөн тухайн байгууллага дахь олголтуудыг буцаана
+	OrgID int `query:"org_id" validate:"omitempty,gt=0"`
 	common.PaginationQuery
 }
 
 type UserRoleRolesQuery struct {
 	UserID int `query:"user_id" validate:"required"`
+	// OrgID өгвөл зөвхөн тухайн байгууллага дахь олголтуудыг буцаана
+	OrgID int `query:"org_id" validate:"omitempty,gt=0"`
 	common.PaginationQuery
 }
 
+// OrgID хоосон бол эрх бүх байгууллагад (global) олгогдоно
 type UserRoleAssignByRole struct {
 	RoleID  int   `json:"role_id"  validate:"required"`
 	UserIDs []int `json:"user_ids" validate:"required,min=1,dive,gt=0"`
+	OrgID   *int  `json:"org_id"   validate:"omitempty,gt=0"`
 }
 
 type UserRoleAssignByUser struct {
 	UserID  int   `json:"user_id"  validate:"required"`
 	RoleIDs []int `json:"role_ids" validate:"required,min=1,dive,gt=0"`
+	OrgID   *int  `json:"org_id"   validate:"omitempty,gt=0"`
 }
 
 type UserRoleRemoveDto struct {
-	UserID int `json:"user_id" validate:"required"`
-	RoleID int `json:"role_id" validate:"required"`
+	UserID int  `json:"user_id" validate:"required"`
+	RoleID int  `json:"role_id" validate:"required"`
+	OrgID  *int `json:"org_id"  validate:"omitempty,gt=0"`
 }
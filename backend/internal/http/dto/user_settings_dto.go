@@ -0,0 +1,21 @@
+// Package dto provides implementation for dto
+//
+// File: user_settings_dto.go
+// Description: Current user's settings update payload
+package dto
+
+import "encoding/json"
+
+// UserSettingsUpdateDto нь PATCH /me/settings-ийн body.
+// Илгээгээгүй (null) талбар өөрчлөгдөхгүй; custom_settings бүхлээрээ солигдоно.
+type UserSettingsUpdateDto struct {
+	NotificationPush     *bool           `json:"notification_push"`
+	NotificationEmail    *bool           `json:"notification_email"`
+	NotificationSms      *bool           `json:"notification_sms"`
+	Theme                *string         `json:"theme" validate:"omitempty,oneof=light dark system"`
+	Language             *string         `json:"language" validate:"omitempty,oneof=mn en"`
+	PrivacyProfilePublic *bool           `json:"privacy_profile_public"`
+	PrivacyShowEmail     *bool           `json:"privacy_show_email"`
+	PrivacyShowPhone     *bool           `json:"privacy_show_phone"`
+	CustomSettings       json.RawMessage `json:"custom_settings" swaggertype:"object"`
+}
@@ -9,8 +9,11 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"templatev25/internal/app"
+	"templatev25/internal/auth"
+	"templatev25/internal/service"
 
 	"git.gerege.mn/backend-packages/common"
 	"git.gerege.mn/backend-packages/ctx"
@@ -138,6 +141,8 @@ func (h *AuthHandler) AuthVerify(c *fiber.Ctx) error {
 // @Produce      json
 // @Param        body body common.ID true "Organization ID"
 // @Success      200 {object} map[string]interface{}
+// @Failure      401 {object} map[string]interface{} "Session expired"
+// @Failure      403 {object} map[string]interface{} "Not a member of the organization"
 // @Failure      500 {object} map[string]interface{} "Server error"
 // @Router       /auth/org/change [post]
 func (h *AuthHandler) ChangeOrganization(c *fiber.Ctx) error {
@@ -146,6 +151,11 @@ func (h *AuthHandler) ChangeOrganization(c *fiber.Ctx) error {
 		return nil
 	}
 
+	// Local session: org_id нь Redis дахь session-д хадгалагдана
+	if session, ok := auth.LocalSession(c); ok {
+		return h.changeLocalOrganization(c, session.SessionID, req.ID)
+	}
+
 	sid := ssoclient.GetSID(c)
 	rid := ctx.RequestID(c)
 
@@ -158,3 +168,22 @@ func (h *AuthHandler) ChangeOrganization(c *fiber.Ctx) error {
 
 	return resp.OK(c)
 }
+
+// changeLocalOrganization нь local session-ийн байгууллагыг солино. Эрх нь
+// байгууллага тус бүрээр тооцогддог тул хэрэглэгчийн permission cache-ийг цэвэрлэнэ.
+func (h *AuthHandler) changeLocalOrganization(c *fiber.Ctx, sessionID string, orgID int) error {
+	session, err := h.Service.Auth.ChangeOrganization(c.UserContext(), sessionID, orgID)
+	switch {
+	case errors.Is(err, service.ErrInvalidSession):
+		return resp.Unauthorized(c)
+	case errors.Is(err, service.ErrNotOrgMember):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"success": false, "message": err.Error()})
+	case err != nil:
+		return resp.InternalServerError(c, err.Error())
+	}
+
+	if h.PermInvalidator != nil {
+		h.PermInvalidator.InvalidateUser(session.UserID)
+	}
+	return resp.OK(c)
+}
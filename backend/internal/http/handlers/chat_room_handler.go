@@ -0,0 +1,503 @@
+// Package handlers provides implementation for handlers
+//
+// File: chat_room_handler.go
+// Description: Chat room, membership, message and WebSocket endpoints
+package handlers
+
+import (
+	"errors"
+
+	"templatev25/internal/app"
+	"templatev25/internal/auth"
+	"templatev25/internal/http/dto"
+	"templatev25/internal/realtime"
+	"templatev25/internal/service"
+
+	"git.gerege.mn/backend-packages/common"
+	"git.gerege.mn/backend-packages/resp"
+	ssoclient "git.gerege.mn/backend-packages/sso-client"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// Upgrade-ийн өмнө WebSocket холболтод дамжуулах Locals түлхүүрүүд
+const (
+	localsRealtimeUserID = "realtime_user_id"
+	localsRealtimeCheck  = "realtime_session_check"
+)
+
+type ChatRoomHandler struct {
+	*app.Dependencies
+}
+
+func NewChatRoomHandler(d *app.Dependencies) *ChatRoomHandler {
+	return &ChatRoomHandler{Dependencies: d}
+}
+
+// ============================================================
+// ROOMS
+// ============================================================
+
+// List godoc
+// @Summary      List my chat rooms
+// @Description  Rooms the current user belongs to, most recent activity first, with unread message counts
+// @Tags         chat-rooms
+// @Security     BearerAuth
+// @Produce      json
+// @Param        page   query int    false "Page number"
+// @Param        size   query int    false "Page size"
+// @Param        type   query string false "Filter by type (direct, group, channel, support)"
+// @Param        search query string false "Search (name)"
+// @Success      200 {object} map[string]interface{}
+// @Router       /chat-rooms [get]
+func (h *ChatRoomHandler) List(c *fiber.Ctx) error {
+	q, ok := resp.QueryBindAndValidate[dto.ChatRoomListQuery](c)
+	if !ok {
+		return nil
+	}
+
+	items, total, page, size, err := h.Service.ChatRoom.Rooms(c.UserContext(), ssoclient.GetUserID(c), q)
+	if err != nil {
+		return h.chatError(c, err)
+	}
+	return resp.Paginated(c, items, total, page, size)
+}
+
+// Unread godoc
+// @Summary      Total unread chat messages
+// @Tags         chat-rooms
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} map[string]interface{}
+// @Router       /chat-rooms/unread [get]
+func (h *ChatRoomHandler) Unread(c *fiber.Ctx) error {
+	total, err := h.Service.ChatRoom.UnreadTotal(c.UserContext(), ssoclient.GetUserID(c))
+	if err != nil {
+		return h.chatError(c, err)
+	}
+	return resp.OK(c, fiber.Map{"unread_count": total})
+}
+
+// Create godoc
+// @Summary      Create chat room
+// @Description  Create a group, channel or support room (creator becomes owner) or open a direct room with one user. An existing direct room between the two users is returned instead of creating a new one.
+// @Tags         chat-rooms
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body body dto.ChatRoomCreateDto true "Room"
+// @Success      200 {object} map[string]interface{}
+// @Failure      400 {object} map[string]interface{}
+// @Router       /chat-rooms [post]
+func (h *ChatRoomHandler) Create(c *fiber.Ctx) error {
+	body, ok := resp.BodyBindAndValidate[dto.ChatRoomCreateDto](c)
+	if !ok {
+		return nil
+	}
+
+	room, err := h.Service.ChatRoom.CreateRoom(c.UserContext(), ssoclient.GetUserID(c), body)
+	if err != nil {
+		return h.chatError(c, err)
+	}
+	return resp.OK(c, room)
+}
+
+// Get godoc
+// @Summary      Get chat room
+// @Tags         chat-rooms
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path int true "Room ID"
+// @Success      200 {object} map[string]interface{}
+// @Failure      404 {object} map[string]interface{} "Room not found or not a member"
+// @Router       /chat-rooms/{id} [get]
+func (h *ChatRoomHandler) Get(c *fiber.Ctx) error {
+	idParam, ok := resp.ParamsBindAndValidate[common.ID](c)
+	if !ok {
+		return nil
+	}
+
+	room, err := h.Service.ChatRoom.Room(c.UserContext(), ssoclient.GetUserID(c), idParam.ID)
+	if err != nil {
+		return h.chatError(c, err)
+	}
+	return resp.OK(c, room)
+}
+
+// Update godoc
+// @Summary      Update chat room
+// @Description  Change name, description, avatar or settings (owner/admin)
+// @Tags         chat-rooms
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path int                   true "Room ID"
+// @Param        body body dto.ChatRoomUpdateDto true "Fields to change"
+// @Success      200 {object} map[string]interface{}
+// @Failure      403 {object} map[string]interface{}
+// @Failure      404 {object} map[string]interface{}
+// @Router       /chat-rooms/{id} [put]
+func (h *ChatRoomHandler) Update(c *fiber.Ctx) error {
+	idParam, ok := resp.ParamsBindAndValidate[common.ID](c)
+	if !ok {
+		return nil
+	}
+	body, ok := resp.BodyBindAndValidate[dto.ChatRoomUpdateDto](c)
+	if !ok {
+		return nil
+	}
+
+	room, err := h.Service.ChatRoom.UpdateRoom(c.UserContext(), ssoclient.GetUserID(c), idParam.ID, body)
+	if err != nil {
+		return h.chatError(c, err)
+	}
+	return resp.OK(c, room)
+}
+
+// Delete godoc
+// @Summary      Delete chat room
+// @Description  Soft delete a room (owner only)
+// @Tags         chat-rooms
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path int true "Room ID"
+// @Success      200 {object} map[string]interface{}
+// @Failure      403 {object} map[string]interface{}
+// @Failure      404 {object} map[string]interface{}
+// @Router       /chat-rooms/{id} [delete]
+func (h *ChatRoomHandler) Delete(c *fiber.Ctx) error {
+	idParam, ok := resp.ParamsBindAndValidate[common.ID](c)
+	if !ok {
+		return nil
+	}
+
+	if err := h.Service.ChatRoom.DeleteRoom(c.UserContext(), ssoclient.GetUserID(c), idParam.ID); err != nil {
+		return h.chatError(c, err)
+	}
+	return resp.OK(c)
+}
+
+// Read godoc
+// @Summary      Mark room as read
+// @Description  Set last_read_at to now; the room's unread count becomes zero
+// @Tags         chat-rooms
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path int true "Room ID"
+// @Success      200 {object} map[string]interface{}
+// @Failure      404 {object} map[string]interface{}
+// @Router       /chat-rooms/{id}/read [post]
+func (h *ChatRoomHandler) Read(c *fiber.Ctx) error {
+	idParam, ok := resp.ParamsBindAndValidate[common.ID](c)
+	if !ok {
+		return nil
+	}
+
+	if err := h.Service.ChatRoom.MarkRead(c.UserContext(), ssoclient.GetUserID(c), idParam.ID); err != nil {
+		return h.chatError(c, err)
+	}
+	return resp.OK(c)
+}
+
+// ============================================================
+// MEMBERS
+// ============================================================
+
+// Members godoc
+// @Summary      List room members
+// @Tags         chat-rooms
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path int true "Room ID"
+// @Success      200 {object} map[string]interface{}
+// @Failure      404 {object} map[string]interface{}
+// @Router       /chat-rooms/{id}/members [get]
+func (h *ChatRoomHandler) Members(c *fiber.Ctx) error {
+	idParam, ok := resp.ParamsBindAndValidate[common.ID](c)
+	if !ok {
+		return nil
+	}
+
+	members, err := h.Service.ChatRoom.Members(c.UserContext(), ssoclient.GetUserID(c), idParam.ID)
+	if err != nil {
+		return h.chatError(c, err)
+	}
+	return resp.OK(c, members)
+}
+
+// AddMembers godoc
+// @Summary      Add room members
+// @Description  Add users to a group/channel/support room (owner/admin). Users who left are re-added.
+// @Tags         chat-rooms
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path int                   true "Room ID"
+// @Param        body body dto.ChatMembersAddDto true "User IDs"
+// @Success      200 {object} map[string]interface{}
+// @Failure      400 {object} map[string]interface{}
+// @Failure      403 {object} map[string]interface{}
+// @Router       /chat-rooms/{id}/members [post]
+func (h *ChatRoomHandler) AddMembers(c *fiber.Ctx) error {
+	idParam, ok := resp.ParamsBindAndValidate[common.ID](c)
+	if !ok {
+		return nil
+	}
+	body, ok := resp.BodyBindAndValidate[dto.ChatMembersAddDto](c)
+	if !ok {
+		return nil
+	}
+
+	members, err := h.Service.ChatRoom.AddMembers(c.UserContext(), ssoclient.GetUserID(c), idParam.ID, body.UserIDs)
+	if err != nil {
+		return h.chatError(c, err)
+	}
+	return resp.OK(c, members)
+}
+
+// RemoveMember godoc
+// @Summary      Remove member / leave room
+// @Description  Remove a member (owner/admin) or leave the room when user_id is the current user. The owner cannot leave.
+// @Tags         chat-rooms
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id      path int true "Room ID"
+// @Param        user_id path int true "User ID"
+// @Success      200 {object} map[string]interface{}
+// @Failure      400 {object} map[string]interface{}
+// @Failure      403 {object} map[string]interface{}
+// @Failure      404 {object} map[string]interface{}
+// @Router       /chat-rooms/{id}/members/{user_id} [delete]
+func (h *ChatRoomHandler) RemoveMember(c *fiber.Ctx) error {
+	p, ok := resp.ParamsBindAndValidate[dto.ChatMemberParams](c)
+	if !ok {
+		return nil
+	}
+
+	if err := h.Service.ChatRoom.RemoveMember(c.UserContext(), ssoclient.GetUserID(c), p.ID, p.UserID); err != nil {
+		return h.chatError(c, err)
+	}
+	return resp.OK(c)
+}
+
+// SetMemberRole godoc
+// @Summary      Change member role
+// @Description  Set a member's role to admin, moderator or member (owner only)
+// @Tags         chat-rooms
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id      path int                   true "Room ID"
+// @Param        user_id path int                   true "User ID"
+// @Param        body    body dto.ChatMemberRoleDto true "Role"
+// @Success      200 {object} map[string]interface{}
+// @Failure      403 {object} map[string]interface{}
+// @Failure      404 {object} map[string]interface{}
+// @Router       /chat-rooms/{id}/members/{user_id} [put]
+func (h *ChatRoomHandler) SetMemberRole(c *fiber.Ctx) error {
+	p, ok := resp.ParamsBindAndValidate[dto.ChatMemberParams](c)
+	if !ok {
+		return nil
+	}
+	body, ok := resp.BodyBindAndValidate[dto.ChatMemberRoleDto](c)
+	if !ok {
+		return nil
+	}
+
+	member, err := h.Service.ChatRoom.SetMemberRole(c.UserContext(), ssoclient.GetUserID(c), p.ID, p.UserID, body.Role)
+	if err != nil {
+		return h.chatError(c, err)
+	}
+	return resp.OK(c, member)
+}
+
+// ============================================================
+// MESSAGES
+// ============================================================
+
+// Messages godoc
+// @Summary      Message history
+// @Description  Paginated messages of a room, newest first. Deleted messages are returned with empty content.
+// @Tags         chat-rooms
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id        path  int true  "Room ID"
+// @Param        page      query int false "Page number"
+// @Param        size      query int false "Page size"
+// @Param        before_id query int false "Only messages older than this id"
+// @Param        parent_id query int false "Only replies in this thread"
+// @Success      200 {object} map[string]interface{}
+// @Failure      404 {object} map[string]interface{}
+// @Router       /chat-rooms/{id}/messages [get]
+func (h *ChatRoomHandler) Messages(c *fiber.Ctx) error {
+	idParam, ok := resp.ParamsBindAndValidate[common.ID](c)
+	if !ok {
+		return nil
+	}
+	q, ok := resp.QueryBindAndValidate[dto.ChatMessageListQuery](c)
+	if !ok {
+		return nil
+	}
+
+	items, total, page, size, err := h.Service.ChatRoom.Messages(c.UserContext(), ssoclient.GetUserID(c), idParam.ID, q)
+	if err != nil {
+		return h.chatError(c, err)
+	}
+	return resp.Paginated(c, items, total, page, size)
+}
+
+// SendMessage godoc
+// @Summary      Send message
+// @Description  Post a message (optionally a thread reply via parent_id). Members connected over WebSocket receive a chat.message.created event.
+// @Tags         chat-rooms
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path int                    true "Room ID"
+// @Param        body body dto.ChatMessageSendDto true "Message"
+// @Success      201 {object} map[string]interface{}
+// @Failure      400 {object} map[string]interface{}
+// @Failure      403 {object} map[string]interface{}
+// @Failure      404 {object} map[string]interface{}
+// @Router       /chat-rooms/{id}/messages [post]
+func (h *ChatRoomHandler) SendMessage(c *fiber.Ctx) error {
+	idParam, ok := resp.ParamsBindAndValidate[common.ID](c)
+	if !ok {
+		return nil
+	}
+	body, ok := resp.BodyBindAndValidate[dto.ChatMessageSendDto](c)
+	if !ok {
+		return nil
+	}
+
+	msg, err := h.Service.ChatRoom.SendMessage(c.UserContext(), ssoclient.GetUserID(c), idParam.ID, body)
+	if err != nil {
+		return h.chatError(c, err)
+	}
+	return resp.Created(c, msg)
+}
+
+// EditMessage godoc
+// @Summary      Edit message
+// @Description  Change the content of your own message
+// @Tags         chat-rooms
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id         path int                    true "Room ID"
+// @Param        message_id path int                    true "Message ID"
+// @Param        body       body dto.ChatMessageEditDto true "Content"
+// @Success      200 {object} map[string]interface{}
+// @Failure      403 {object} map[string]interface{}
+// @Failure      404 {object} map[string]interface{}
+// @Router       /chat-rooms/{id}/messages/{message_id} [put]
+func (h *ChatRoomHandler) EditMessage(c *fiber.Ctx) error {
+	p, ok := resp.ParamsBindAndValidate[dto.ChatMessageParams](c)
+	if !ok {
+		return nil
+	}
+	body, ok := resp.BodyBindAndValidate[dto.ChatMessageEditDto](c)
+	if !ok {
+		return nil
+	}
+
+	msg, err := h.Service.ChatRoom.EditMessage(c.UserContext(), ssoclient.GetUserID(c), p.ID, p.MessageID, body.Content)
+	if err != nil {
+		return h.chatError(c, err)
+	}
+	return resp.OK(c, msg)
+}
+
+// DeleteMessage godoc
+// @Summary      Delete message
+// @Description  Soft delete a message (sender, or room owner/admin/moderator)
+// @Tags         chat-rooms
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id         path int true "Room ID"
+// @Param        message_id path int true "Message ID"
+// @Success      200 {object} map[string]interface{}
+// @Failure      403 {object} map[string]interface{}
+// @Failure      404 {object} map[string]interface{}
+// @Router       /chat-rooms/{id}/messages/{message_id} [delete]
+func (h *ChatRoomHandler) DeleteMessage(c *fiber.Ctx) error {
+	p, ok := resp.ParamsBindAndValidate[dto.ChatMessageParams](c)
+	if !ok {
+		return nil
+	}
+
+	if err := h.Service.ChatRoom.DeleteMessage(c.UserContext(), ssoclient.GetUserID(c), p.ID, p.MessageID); err != nil {
+		return h.chatError(c, err)
+	}
+	return resp.OK(c)
+}
+
+// ============================================================
+// WEBSOCKET
+// ============================================================
+
+// Upgrade нь WebSocket upgrade хүсэлтийг шалгаж user ID-г холболтод дамжуулна.
+func (h *ChatRoomHandler) Upgrade(c *fiber.Ctx) error {
+	return realtimeUpgrade(c)
+}
+
+// realtimeUpgrade нь chat, notification WebSocket route-уудын upgrade шалгалт.
+func realtimeUpgrade(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return fiber.ErrUpgradeRequired
+	}
+	userID := ssoclient.GetUserID(c)
+	if userID == 0 {
+		return resp.Unauthorized(c)
+	}
+	c.Locals(localsRealtimeUserID, userID)
+	c.Locals(localsRealtimeCheck, realtime.SessionCheck(auth.RealtimeSessionCheck(c)))
+	return c.Next()
+}
+
+// realtimeConn нь upgrade-ийн үед дамжуулсан user ID, session шалгалтыг авна.
+func realtimeConn(conn *websocket.Conn) (int, realtime.SessionCheck) {
+	userID, _ := conn.Locals(localsRealtimeUserID).(int)
+	check, _ := conn.Locals(localsRealtimeCheck).(realtime.SessionCheck)
+	return userID, check
+}
+
+// Stream godoc
+// @Summary      Realtime chat events (WebSocket)
+// @Description  Upgrade to WebSocket and receive JSON events {"type": "...", "data": {...}} for all rooms of the current user: chat.message.created, chat.message.updated, chat.message.deleted, chat.room.updated, chat.room.deleted, chat.member.added, chat.member.removed, chat.room.read. Browsers cannot set the Authorization header on a WebSocket, so get a one-time ticket from POST /realtime/ticket and pass it as ?ticket=; the SSO session cookie also works. The connection is closed with code 1008 when the session is logged out or revoked.
+// @Tags         chat-rooms
+// @Security     BearerAuth
+// @Param        ticket query string false "One-time ticket from POST /realtime/ticket"
+// @Success      101
+// @Failure      401 {object} map[string]interface{} "Invalid or expired ticket"
+// @Failure      426 {object} map[string]interface{} "Upgrade required"
+// @Router       /chat-rooms/ws [get]
+func (h *ChatRoomHandler) Stream(conn *websocket.Conn) {
+	userID, check := realtimeConn(conn)
+	h.Realtime.Serve(conn, userID, check, "chat.")
+}
+
+// chatError нь ChatRoomService-ийн алдааг HTTP хариу болгоно.
+func (h *ChatRoomHandler) chatError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrChatInvalidRequest), errors.Is(err, service.ErrChatOwnerLeave):
+		return resp.BadRequest(c, err.Error(), nil)
+	case errors.Is(err, service.ErrChatForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrChatRoomNotFound),
+		errors.Is(err, service.ErrChatMemberNotFound),
+		errors.Is(err, service.ErrChatMessageNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	default:
+		h.Log.Error("chat_request_failed", zap.Error(err))
+		return resp.InternalServerError(c, err.Error())
+	}
+}
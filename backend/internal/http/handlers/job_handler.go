@@ -0,0 +1,183 @@
+// Package handlers provides implementation for handlers
+//
+// File: job_handler.go
+// Description: Scheduled job admin endpoints
+package handlers
+
+import (
+	"errors"
+
+	"templatev25/internal/app"
+	"templatev25/internal/http/dto"
+	"templatev25/internal/service"
+
+	"git.gerege.mn/backend-packages/common"
+	"git.gerege.mn/backend-packages/resp"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type JobHandler struct {
+	*app.Dependencies
+}
+
+func NewJobHandler(d *app.Dependencies) *JobHandler {
+	return &JobHandler{Dependencies: d}
+}
+
+// List godoc
+// @Summary      List scheduled jobs
+// @Description  Get paginated list of scheduled jobs with their last run state and next run time
+// @Tags         jobs
+// @Security     BearerAuth
+// @Produce      json
+// @Param        page      query int    false "Page number"
+// @Param        size      query int    false "Page size"
+// @Param        handler   query string false "Filter by handler name"
+// @Param        is_active query bool   false "Filter by active state"
+// @Param        search    query string false "Search (name/code/handler)"
+// @Param        sort      query string false "Sort (e.g. next_run_at:asc)"
+// @Success      200 {object} map[string]interface{}
+// @Router       /jobs [get]
+func (h *JobHandler) List(c *fiber.Ctx) error {
+	q, ok := resp.QueryBindAndValidate[dto.JobListQuery](c)
+	if !ok {
+		return nil
+	}
+
+	items, total, page, size, err := h.Service.Job.List(c.UserContext(), q)
+	if err != nil {
+		h.Log.Error("job_list_failed", zap.Error(err))
+		return resp.InternalServerError(c, err.Error())
+	}
+	return resp.Paginated(c, items, total, page, size)
+}
+
+// Executions godoc
+// @Summary      Job execution history
+// @Description  Get paginated execution history of a scheduled job (newest first)
+// @Tags         jobs
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id     path  int    true  "Job ID"
+// @Param        page   query int    false "Page number"
+// @Param        size   query int    false "Page size"
+// @Param        status query string false "Filter by status (running, completed, failed, cancelled)"
+// @Success      200 {object} map[string]interface{}
+// @Failure      404 {object} map[string]interface{} "Job not found"
+// @Router       /jobs/{id}/executions [get]
+func (h *JobHandler) Executions(c *fiber.Ctx) error {
+	idParam, ok := resp.ParamsBindAndValidate[common.ID](c)
+	if !ok {
+		return nil
+	}
+	q, ok := resp.QueryBindAndValidate[dto.JobExecutionListQuery](c)
+	if !ok {
+		return nil
+	}
+
+	items, total, page, size, err := h.Service.Job.Executions(c.UserContext(), idParam.ID, q)
+	if err != nil {
+		return h.jobError(c, err)
+	}
+	return resp.Paginated(c, items, total, page, size)
+}
+
+// Trigger godoc
+// @Summary      Run job now
+// @Description  Start a job immediately regardless of its schedule (paused jobs too). Returns the created execution; the handler runs in the background. next_run_at is not changed.
+// @Tags         jobs
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path int true "Job ID"
+// @Success      200 {object} map[string]interface{}
+// @Failure      404 {object} map[string]interface{} "Job not found"
+// @Failure      409 {object} map[string]interface{} "Job is already running"
+// @Failure      503 {object} map[string]interface{} "Too many jobs are running"
+// @Router       /jobs/{id}/trigger [post]
+func (h *JobHandler) Trigger(c *fiber.Ctx) error {
+	idParam, ok := resp.ParamsBindAndValidate[common.ID](c)
+	if !ok {
+		return nil
+	}
+
+	exec, err := h.Service.Job.Trigger(c.UserContext(), idParam.ID)
+	if err != nil {
+		return h.jobError(c, err)
+	}
+	return resp.OK(c, exec)
+}
+
+// Pause godoc
+// @Summary      Pause job
+// @Description  Deactivate a scheduled job. A running execution is not interrupted.
+// @Tags         jobs
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path int true "Job ID"
+// @Success      200 {object} map[string]interface{}
+// @Failure      404 {object} map[string]interface{} "Job not found"
+// @Router       /jobs/{id}/pause [put]
+func (h *JobHandler) Pause(c *fiber.Ctx) error {
+	idParam, ok := resp.ParamsBindAndValidate[common.ID](c)
+	if !ok {
+		return nil
+	}
+
+	job, err := h.Service.Job.Pause(c.UserContext(), idParam.ID)
+	if err != nil {
+		return h.jobError(c, err)
+	}
+	return resp.OK(c, job)
+}
+
+// Resume godoc
+// @Summary      Resume job
+// @Description  Reactivate a paused job; next_run_at is recalculated from now
+// @Tags         jobs
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path int true "Job ID"
+// @Success      200 {object} map[string]interface{}
+// @Failure      400 {object} map[string]interface{} "Invalid cron expression"
+// @Failure      404 {object} map[string]interface{} "Job not found"
+// @Router       /jobs/{id}/resume [put]
+func (h *JobHandler) Resume(c *fiber.Ctx) error {
+	idParam, ok := resp.ParamsBindAndValidate[common.ID](c)
+	if !ok {
+		return nil
+	}
+
+	job, err := h.Service.Job.Resume(c.UserContext(), idParam.ID)
+	if err != nil {
+		return h.jobError(c, err)
+	}
+	return resp.OK(c, job)
+}
+
+// jobError нь JobService-ийн алдааг HTTP хариу болгоно.
+func (h *JobHandler) jobError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrJobInvalidCron):
+		return resp.BadRequest(c, err.Error(), nil)
+	case errors.Is(err, service.ErrJobNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrJobRunning):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrJobBusy):
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	default:
+		h.Log.Error("job_request_failed", zap.Error(err))
+		return resp.InternalServerError(c, err.Error())
+	}
+}
@@ -45,10 +45,11 @@ func (h *LocalAuthHandler) Login(c *fiber.Ctx) error {
 	}
 
 	loginReq := service.LoginRequest{
-		Email:     req.Email,
-		Password:  req.Password,
-		IPAddress: c.IP(),
-		UserAgent: c.Get("User-Agent"),
+		Email:             req.Email,
+		Password:          req.Password,
+		IPAddress:         c.IP(),
+		UserAgent:         c.Get("User-Agent"),
+		IssueRefreshToken: req.IssueRefreshToken,
 	}
 
 	result, err := h.authService.Login(c.UserContext(), loginReq)
@@ -79,31 +80,12 @@ func (h *LocalAuthHandler) Login(c *fiber.Ctx) error {
 		}
 	}
 
-	response := dto.LoginResponse{
-		RequiresMFA: result.RequiresMFA,
-		MFAToken:    result.MFAToken,
-	}
-
-	if !result.RequiresMFA && result.Session != nil {
-		response.AccessToken = result.Session.SessionID
-		response.ExpiresAt = result.Session.ExpiresAt.Unix()
-		if result.User != nil {
-			response.User = &dto.UserInfo{
-				ID:        result.User.Id,
-				Email:     result.User.Email,
-				FirstName: result.User.FirstName,
-				LastName:  result.User.LastName,
-				Status:    result.User.Status,
-			}
-		}
-	}
-
-	return resp.OK(c, response)
+	return resp.OK(c, toLoginResponse(result))
 }
 
 // VerifyMFA godoc
 // @Summary      Verify MFA code
-// @Description  Verify TOTP code to complete login
+// @Description  Verify a TOTP code or a WebAuthn assertion to complete login
 // @Tags         local-auth
 // @Accept       json
 // @Produce      json
@@ -118,9 +100,14 @@ func (h *LocalAuthHandler) VerifyMFA(c *fiber.Ctx) error {
 		return nil
 	}
 
+	if req.Code == "" && req.WebAuthn == nil {
+		return resp.BadRequest(c, "code or webauthn assertion is required", nil)
+	}
+
 	mfaReq := service.VerifyMFARequest{
 		MFAToken:  req.MFAToken,
 		Code:      req.Code,
+		WebAuthn:  req.WebAuthn,
 		IPAddress: c.IP(),
 		UserAgent: c.Get("User-Agent"),
 	}
@@ -140,27 +127,14 @@ func (h *LocalAuthHandler) VerifyMFA(c *fiber.Ctx) error {
 			})
 		case errors.Is(err, service.ErrMFANotEnabled):
 			return resp.BadRequest(c, "MFA is not enabled", nil)
+		case errors.Is(err, service.ErrWebAuthnDisabled):
+			return resp.BadRequest(c, "WebAuthn is not available", nil)
 		default:
 			return resp.InternalServerError(c, err.Error())
 		}
 	}
 
-	response := dto.LoginResponse{
-		AccessToken: result.Session.SessionID,
-		ExpiresAt:   result.Session.ExpiresAt.Unix(),
-	}
-
-	if result.User != nil {
-		response.User = &dto.UserInfo{
-			ID:        result.User.Id,
-			Email:     result.User.Email,
-			FirstName: result.User.FirstName,
-			LastName:  result.User.LastName,
-			Status:    result.User.Status,
-		}
-	}
-
-	return resp.OK(c, response)
+	return resp.OK(c, toLoginResponse(result))
 }
 
 // VerifyBackupCode godoc
@@ -204,22 +178,7 @@ func (h *LocalAuthHandler) VerifyBackupCode(c *fiber.Ctx) error {
 		}
 	}
 
-	response := dto.LoginResponse{
-		AccessToken: result.Session.SessionID,
-		ExpiresAt:   result.Session.ExpiresAt.Unix(),
-	}
-
-	if result.User != nil {
-		response.User = &dto.UserInfo{
-			ID:        result.User.Id,
-			Email:     result.User.Email,
-			FirstName: result.User.FirstName,
-			LastName:  result.User.LastName,
-			Status:    result.User.Status,
-		}
-	}
-
-	return resp.OK(c, response)
+	return resp.OK(c, toLoginResponse(result))
 }
 
 // Logout godoc
@@ -280,11 +239,12 @@ func (h *LocalAuthHandler) LogoutAll(c *fiber.Ctx) error {
 
 // RefreshSession godoc
 // @Summary      Refresh session
-// @Description  Extend session expiration time
+// @Description  Extend session expiration time. Sessions issued with a refresh token must use /auth/local/token/refresh instead.
 // @Tags         local-auth
 // @Security     BearerAuth
 // @Produce      json
 // @Success      200 {object} dto.LoginResponse
+// @Failure      400 {object} dto.ErrorResponse "Session has a refresh token"
 // @Failure      401 {object} dto.ErrorResponse
 // @Router       /auth/local/refresh [post]
 func (h *LocalAuthHandler) RefreshSession(c *fiber.Ctx) error {
@@ -301,6 +261,9 @@ func (h *LocalAuthHandler) RefreshSession(c *fiber.Ctx) error {
 				"message": "session expired",
 			})
 		}
+		if errors.Is(err, service.ErrRefreshTokenSession) {
+			return resp.BadRequest(c, "use /auth/local/token/refresh to renew this session", nil)
+		}
 		return resp.InternalServerError(c, err.Error())
 	}
 
@@ -310,7 +273,88 @@ func (h *LocalAuthHandler) RefreshSession(c *fiber.Ctx) error {
 	})
 }
 
+// RefreshToken godoc
+// @Summary      Rotate refresh token
+// @Description  Exchange a refresh token for a new access token and refresh token. The presented token is invalidated; reusing it revokes the whole token family.
+// @Tags         local-auth
+// @Accept       json
+// @Produce      json
+// @Param        body body dto.RefreshTokenRequest true "Refresh token"
+// @Success      200 {object} dto.LoginResponse
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse "Invalid, expired or reused refresh token"
+// @Failure      403 {object} dto.ErrorResponse "Account not active"
+// @Router       /auth/local/token/refresh [post]
+func (h *LocalAuthHandler) RefreshToken(c *fiber.Ctx) error {
+	req, ok := resp.BodyBindAndValidate[dto.RefreshTokenRequest](c)
+	if !ok {
+		return nil
+	}
+
+	result, err := h.authService.RotateRefreshToken(c.UserContext(), service.RefreshTokenRequest{
+		RefreshToken: req.RefreshToken,
+		IPAddress:    c.IP(),
+		UserAgent:    c.Get("User-Agent"),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRefreshToken):
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"message": "invalid or expired refresh token",
+			})
+		case errors.Is(err, service.ErrRefreshTokenReused):
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"message": "refresh token has already been used, please log in again",
+			})
+		case errors.Is(err, service.ErrAccountNotActive):
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"success": false,
+				"message": "account is not active",
+			})
+		default:
+			return resp.InternalServerError(c, err.Error())
+		}
+	}
+
+	return resp.OK(c, toLoginResponse(result))
+}
+
 // Helper functions
+
+// toLoginResponse converts a service login result to the API response
+func toLoginResponse(result *service.LoginResponse) dto.LoginResponse {
+	response := dto.LoginResponse{
+		RequiresMFA:     result.RequiresMFA,
+		MFAToken:        result.MFAToken,
+		MFAMethods:      result.MFAMethods,
+		WebAuthnOptions: result.WebAuthnOptions,
+	}
+
+	if result.RequiresMFA || result.Session == nil {
+		return response
+	}
+
+	response.AccessToken = result.Session.SessionID
+	response.ExpiresAt = result.Session.ExpiresAt.Unix()
+	if result.RefreshToken != "" {
+		response.RefreshToken = result.RefreshToken
+		response.RefreshExpiresAt = result.RefreshExpiresAt.Unix()
+	}
+	if result.User != nil {
+		response.User = &dto.UserInfo{
+			ID:        result.User.Id,
+			Email:     result.User.Email,
+			FirstName: result.User.FirstName,
+			LastName:  result.User.LastName,
+			Status:    result.User.Status,
+		}
+	}
+
+	return response
+}
+
 func getSessionID(c *fiber.Ctx) string {
 	// Try to get from context (set by session auth middleware)
 	if sid, ok := c.Locals("session_id").(string); ok {
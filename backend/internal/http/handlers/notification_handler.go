@@ -9,13 +9,18 @@
 package handlers
 
 import (
+	"errors"
+
 	"templatev25/internal/http/dto"
+	"templatev25/internal/service"
 
 	"templatev25/internal/app"
+	"templatev25/internal/auth"
 	"git.gerege.mn/backend-packages/sso-client"
 	"git.gerege.mn/backend-packages/common"
 	"git.gerege.mn/backend-packages/resp"
 
+	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -122,12 +127,14 @@ func (h *NotificationHandler) ReadAll(c *fiber.Ctx) error {
 
 // Send godoc
 // @Summary      Send notification
+// @Description  Send to one user (user_id), to everyone (no user_id, no target) or to users matched by target: role_codes, org_ids (include_sub_orgs for the whole subtree), org_type_ids and user_ids are combined as a union. The target and recipient count are stored on the notification group. Email, SMS and push copies are sent in the background to recipients who enabled the channel in their settings.
 // @Tags         notification
 // @Security     BearerAuth
 // @Accept       json
 // @Produce      json
 // @Param        body body dto.NotificationSendDto true "Notification data"
 // @Success      200 {object} map[string]interface{}
+// @Failure      400 {object} map[string]interface{} "No users match the target"
 // @Router       /notification [post]
 func (h *NotificationHandler) Send(c *fiber.Ctx) error {
 	req, ok := resp.ParamsBindAndValidate[dto.NotificationSendDto](c)
@@ -145,7 +152,74 @@ func (h *NotificationHandler) Send(c *fiber.Ctx) error {
 		req,
 		claims.Username,
 	); err != nil {
+		if errors.Is(err, service.ErrNotificationNoRecipients) {
+			return resp.BadRequest(c, err.Error(), nil)
+		}
 		return resp.InternalServerError(c, err.Error())
 	}
 	return resp.OK(c)
 }
+
+// Deliveries godoc
+// @Summary      Notification delivery log
+// @Description  Email, SMS and push delivery attempts (newest first). Channels the user turned off are not logged; skipped means the channel was on but the user has no address for it.
+// @Tags         notification
+// @Security     BearerAuth
+// @Produce      json
+// @Param        page     query int    false "Page number"
+// @Param        size     query int    false "Page size"
+// @Param        group_id query int    false "Filter by notification group"
+// @Param        user_id  query int    false "Filter by user"
+// @Param        channel  query string false "Filter by channel (email, sms, push)"
+// @Param        status   query string false "Filter by status (sent, failed, skipped)"
+// @Success      200 {object} map[string]interface{}
+// @Router       /notification/deliveries [get]
+func (h *NotificationHandler) Deliveries(c *fiber.Ctx) error {
+	q, ok := resp.QueryBindAndValidate[dto.NotificationDeliveryListQuery](c)
+	if !ok {
+		return nil
+	}
+	items, total, page, size, err := h.Service.NotificationDispatcher.Deliveries(c.UserContext(), q)
+	if err != nil {
+		return resp.InternalServerError(c, err.Error())
+	}
+	return resp.Paginated(c, items, total, page, size)
+}
+
+// Stream godoc
+// @Summary      Realtime notifications (SSE)
+// @Description  Server-Sent Events stream of the current user's notifications. Each event is `data: {"type": "notification.created", "data": {...}}`; comment lines are heartbeats. EventSource cannot set the Authorization header, so get a one-time ticket from POST /realtime/ticket and pass it as ?ticket=; the SSO session cookie also works. When the session is logged out or revoked the stream sends `data: {"type": "session.revoked"}` and ends.
+// @Tags         notification
+// @Security     BearerAuth
+// @Produce      text/event-stream
+// @Param        ticket query string false "One-time ticket from POST /realtime/ticket"
+// @Success      200
+// @Failure      401 {object} map[string]interface{} "Invalid or expired ticket"
+// @Router       /notification/stream [get]
+func (h *NotificationHandler) Stream(c *fiber.Ctx) error {
+	userID := ssoclient.GetUserID(c)
+	if userID == 0 {
+		return resp.Unauthorized(c)
+	}
+	return h.Realtime.ServeSSE(c, userID, auth.RealtimeSessionCheck(c), "notification.")
+}
+
+// Upgrade нь WebSocket upgrade хүсэлтийг шалгаж user ID-г холболтод дамжуулна.
+func (h *NotificationHandler) Upgrade(c *fiber.Ctx) error {
+	return realtimeUpgrade(c)
+}
+
+// Socket godoc
+// @Summary      Realtime notifications (WebSocket)
+// @Description  Upgrade to WebSocket and receive JSON events {"type": "notification.created", "data": {...}} for the current user. Authenticate with ?ticket= from POST /realtime/ticket or the SSO session cookie. The connection is closed with code 1008 when the session is logged out or revoked.
+// @Tags         notification
+// @Security     BearerAuth
+// @Param        ticket query string false "One-time ticket from POST /realtime/ticket"
+// @Success      101
+// @Failure      401 {object} map[string]interface{} "Invalid or expired ticket"
+// @Failure      426 {object} map[string]interface{} "Upgrade required"
+// @Router       /notification/ws [get]
+func (h *NotificationHandler) Socket(conn *websocket.Conn) {
+	userID, check := realtimeConn(conn)
+	h.Realtime.Serve(conn, userID, check, "notification.")
+}
@@ -9,10 +9,12 @@
 package handlers
 
 import (
+	"errors"
 	"strings"
 
 	"templatev25/internal/app"
 	"templatev25/internal/http/dto"
+	"templatev25/internal/service"
 
 	"git.gerege.mn/backend-packages/common"
 	"git.gerege.mn/backend-packages/resp"
@@ -93,7 +95,9 @@ func (h *OrganizationHandler) Create(c *fiber.Ctx) error {
 	}
 	out, err := h.Service.Organization.Create(c.UserContext(), req)
 	if err != nil {
-
+		if errors.Is(err, service.ErrOrganizationCycle) {
+			return resp.BadRequest(c, err.Error(), nil)
+		}
 		return resp.InternalServerError(c, err.Error())
 	}
 	return resp.OK(c, out)
@@ -121,7 +125,9 @@ func (h *OrganizationHandler) Update(c *fiber.Ctx) error {
 	}
 	out, err := h.Service.Organization.Update(c.UserContext(), idParam.ID, req)
 	if err != nil {
-
+		if errors.Is(err, service.ErrOrganizationCycle) {
+			return resp.BadRequest(c, err.Error(), nil)
+		}
 		return resp.InternalServerError(c, err.Error())
 	}
 	return resp.OK(c, out)
@@ -171,6 +177,89 @@ func (h *OrganizationHandler) Tree(c *fiber.Ctx) error {
 	return resp.OK(c, items)
 }
 
+// Subtree godoc
+// @Summary      Get organization subtree
+// @Description  Get the organization and all of its descendants as a flat list ordered by depth
+// @Tags         organization
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path int true "Organization ID"
+// @Success      200 {object} map[string]interface{}
+// @Router       /organization/{id}/subtree [get]
+func (h *OrganizationHandler) Subtree(c *fiber.Ctx) error {
+	idParam, ok := resp.ParamsBindAndValidate[common.ID](c)
+	if !ok {
+		return nil
+	}
+
+	items, err := h.Service.Organization.Subtree(c.UserContext(), idParam.ID)
+	if err != nil {
+		return resp.InternalServerError(c, err.Error())
+	}
+	return resp.OK(c, items)
+}
+
+// Ancestors godoc
+// @Summary      Get organization ancestors
+// @Description  Get the parent chain of an organization, starting from the root
+// @Tags         organization
+// @Security     BearerAuth
+// @Produce      json
+// @Param        id path int true "Organization ID"
+// @Success      200 {object} map[string]interface{}
+// @Router       /organization/{id}/ancestors [get]
+func (h *OrganizationHandler) Ancestors(c *fiber.Ctx) error {
+	idParam, ok := resp.ParamsBindAndValidate[common.ID](c)
+	if !ok {
+		return nil
+	}
+
+	items, err := h.Service.Organization.Ancestors(c.UserContext(), idParam.ID)
+	if err != nil {
+		return resp.InternalServerError(c, err.Error())
+	}
+	return resp.OK(c, items)
+}
+
+// Move godoc
+// @Summary      Move organization
+// @Description  Move an organization (with its descendants) under a new parent. Null or 0 parent_id makes it a root.
+// @Tags         organization
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id   path int true "Organization ID"
+// @Param        body body dto.OrganizationMoveDto true "New parent"
+// @Success      200 {object} map[string]interface{}
+// @Failure      400 {object} map[string]interface{} "Cycle or parent not found"
+// @Failure      404 {object} map[string]interface{} "Organization not found"
+// @Router       /organization/{id}/move [put]
+func (h *OrganizationHandler) Move(c *fiber.Ctx) error {
+	idParam, ok := resp.ParamsBindAndValidate[common.ID](c)
+	if !ok {
+		return nil
+	}
+	req, ok := resp.BodyBindAndValidate[dto.OrganizationMoveDto](c)
+	if !ok {
+		return nil
+	}
+
+	if err := h.Service.Organization.Move(c.UserContext(), idParam.ID, req.ParentID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrOrganizationCycle), errors.Is(err, service.ErrOrganizationParentNotFound):
+			return resp.BadRequest(c, err.Error(), nil)
+		case errors.Is(err, service.ErrOrganizationNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"success": false,
+				"message": err.Error(),
+			})
+		default:
+			return resp.InternalServerError(c, err.Error())
+		}
+	}
+	return resp.OK(c)
+}
+
 type OrganizationTypeHandler struct {
 	*app.Dependencies
 }
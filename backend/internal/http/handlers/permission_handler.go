@@ -12,7 +12,9 @@ import (
 	"templatev25/internal/http/dto"
 
 	"context"
+	"errors"
 	"templatev25/internal/app"
+	"templatev25/internal/service"
 	"git.gerege.mn/backend-packages/common"
 	"git.gerege.mn/backend-packages/resp"
 	"time"
@@ -58,6 +60,37 @@ func (h *PermissionHandler) List(c *fiber.Ctx) error {
 	return resp.Paginated(c, items, total, page, size)
 }
 
+// Explain godoc
+// @Summary      Explain permission decision
+// @Description  Shows whether RequirePermission grants the code to the user and every user_roles → role → role_permissions path with its deny reasons
+// @Tags         permissions
+// @Security     BearerAuth
+// @Produce      json
+// @Param        user_id query int    true  "User ID"
+// @Param        code    query string true  "Permission code (e.g. admin.user.read)"
+// @Param        org_id  query int    false "Selected organization ID (omit for global grants only)"
+// @Success      200 {object} dto.PermissionExplainResponse
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      500 {object} dto.ErrorResponse
+// @Router       /permission/explain [get]
+func (h *PermissionHandler) Explain(c *fiber.Ctx) error {
+	q, ok := resp.QueryBindAndValidate[dto.PermissionExplainQuery](c)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	out, err := h.Service.Permission.Explain(ctx, q)
+	if err != nil {
+		h.Log.Error("permission_explain_failed", zap.Int("user_id", q.UserID), zap.String("code", q.Code), zap.Error(err))
+		return resp.InternalServerError(c, err.Error())
+	}
+	return resp.OK(c, out)
+}
+
 // Create godoc
 // @Summary      Create permission
 // @Tags         permissions
@@ -83,6 +116,35 @@ func (h *PermissionHandler) Create(c *fiber.Ctx) error {
 	return resp.Created(c)
 }
 
+// CreateWildcard godoc
+// @Summary      Create wildcard permission
+// @Description  Creates a grant such as "admin.user.*" that covers every permission under the prefix
+// @Tags         permissions
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body   body dto.PermissionWildcardCreateDto true "payload"
+// @Success      201 {object} map[string]interface{}
+// @Router       /permission/wildcard [post]
+func (h *PermissionHandler) CreateWildcard(c *fiber.Ctx) error {
+	req, ok := resp.BodyBindAndValidate[dto.PermissionWildcardCreateDto](c)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	if err := h.Service.Permission.CreateWildcard(ctx, req); err != nil {
+		if errors.Is(err, service.ErrInvalidPermissionCode) {
+			return resp.BadRequest(c, err.Error(), nil)
+		}
+		h.Log.Error("permission_wildcard_create_failed", zap.Error(err))
+		return resp.InternalServerError(c, err.Error())
+	}
+	return resp.Created(c)
+}
+
 // Update godoc
 // @Summary      Update permission
 // @Tags         permissions
@@ -108,6 +170,9 @@ func (h *PermissionHandler) Update(c *fiber.Ctx) error {
 	defer cancel()
 
 	if err := h.Service.Permission.Update(ctx, params.ID, req); err != nil {
+		if errors.Is(err, service.ErrInvalidPermissionCode) {
+			return resp.BadRequest(c, err.Error(), nil)
+		}
 		h.Log.Warn("permission_update_failed", zap.Error(err))
 		return resp.InternalServerError(c, err.Error())
 	}
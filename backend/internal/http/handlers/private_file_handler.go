@@ -0,0 +1,140 @@
+// Package handlers provides implementation for handlers
+//
+// File: private_file_handler.go
+// Description: Private file upload, signed links and download (/file/private)
+package handlers
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"time"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/http/dto"
+	"templatev25/internal/service"
+	"templatev25/internal/signedurl"
+	"templatev25/internal/storage"
+
+	"git.gerege.mn/backend-packages/resp"
+	ssoclient "git.gerege.mn/backend-packages/sso-client"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// Бусдын хувийн файлд хандах эрх
+const (
+	permPrivateFileRead   = "admin.file.private.read"
+	permPrivateFileDelete = "admin.file.private.delete"
+)
+
+// GET /file/private -> миний хувийн файлууд
+func (h *FileHandler) ListPrivate(c *fiber.Ctx) error {
+	items, err := h.Service.PrivateFile.List(c.UserContext(), ssoclient.GetUserID(c))
+	if err != nil {
+		return h.privateFileError(c, err)
+	}
+	return resp.OK(c, items)
+}
+
+// POST /file/private/upload (multipart/form-data, "file")
+func (h *FileHandler) UploadPrivate(c *fiber.Ctx) error {
+	header, err := c.FormFile("file")
+	if err != nil {
+		return resp.BadRequest(c, "file is required", nil)
+	}
+	created, err := h.Service.PrivateFile.Upload(c.UserContext(), ssoclient.GetUserID(c), header)
+	if err != nil {
+		return h.uploadError(c, header.Filename, err)
+	}
+	return resp.OK(c, created)
+}
+
+// POST /file/private/:name/sign (body: { "expires_in": seconds, "bind_user": bool })
+// -> хугацаатай татах link
+func (h *FileHandler) SignPrivate(c *fiber.Ctx) error {
+	req, ok := resp.BodyBindAndValidate[dto.PrivateFileSignDto](c)
+	if !ok {
+		return nil
+	}
+	a, err := h.fileAccessor(c, permPrivateFileRead)
+	if err != nil {
+		return h.privateFileError(c, err)
+	}
+	link, err := h.Service.PrivateFile.Sign(c.UserContext(), a, c.Params("name"),
+		time.Duration(req.ExpiresIn)*time.Second, req.BindUser)
+	if err != nil {
+		return h.privateFileError(c, err)
+	}
+	return resp.OK(c, link)
+}
+
+// GET /file/private/:name -> эзэмшигч/эрхтэй хэрэглэгч, эсвэл ?exp=&sig= signed link
+func (h *FileHandler) DownloadPrivate(c *fiber.Ctx) error {
+	name := c.Params("name")
+	ctx := c.UserContext()
+
+	var err error
+	var obj *storage.Object
+	var f domain.File
+	if c.Query(signedurl.ParamSignature) != "" {
+		obj, f, err = h.Service.PrivateFile.OpenSigned(ctx, name, signedurl.ParamsFromQuery(c.Query), ssoclient.GetUserID(c))
+	} else {
+		var a service.FileAccessor
+		if a, err = h.fileAccessor(c, permPrivateFileRead); err == nil {
+			obj, f, err = h.Service.PrivateFile.Open(ctx, a, name)
+		}
+	}
+	if err != nil {
+		return h.privateFileError(c, err)
+	}
+
+	contentType := f.MimeType
+	if contentType == "" {
+		contentType = obj.ContentType
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": f.OriginalName}))
+	// Хувийн файлыг дундын cache-д үлдээхгүй
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	if !obj.LastModified.IsZero() {
+		c.Set(fiber.HeaderLastModified, obj.LastModified.UTC().Format(http.TimeFormat))
+	}
+	// SendStream нь дуусахад Body-г хаана
+	return c.SendStream(obj.Body, int(obj.Size))
+}
+
+// DELETE /file/private/:name
+func (h *FileHandler) DeletePrivate(c *fiber.Ctx) error {
+	a, err := h.fileAccessor(c, permPrivateFileDelete)
+	if err != nil {
+		return h.privateFileError(c, err)
+	}
+	if err := h.Service.PrivateFile.Delete(c.UserContext(), a, c.Params("name")); err != nil {
+		return h.privateFileError(c, err)
+	}
+	return resp.OK(c)
+}
+
+// fileAccessor нь нэвтэрсэн хэрэглэгч болон түүний perm эрхийг (бусдын файлд) буцаана.
+func (h *FileHandler) fileAccessor(c *fiber.Ctx, perm string) (service.FileAccessor, error) {
+	userID := ssoclient.GetUserID(c)
+	canManage, err := h.PermCache.HasPermission(c.UserContext(), userID, perm)
+	if err != nil {
+		return service.FileAccessor{}, err
+	}
+	return service.FileAccessor{UserID: userID, CanManage: canManage}, nil
+}
+
+// privateFileError нь байхгүй файлыг 404, эрхгүй/хүчингүй link-ийг 403 болгоно.
+func (h *FileHandler) privateFileError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrFileNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": err.Error()})
+	case errors.Is(err, service.ErrFileForbidden), errors.Is(err, signedurl.ErrInvalid):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"success": false, "message": err.Error()})
+	}
+	h.Log.Error("private_file_failed", zap.String("name", c.Params("name")), zap.Error(err))
+	return resp.InternalServerError(c, "private file request failed")
+}
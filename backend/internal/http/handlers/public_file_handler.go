@@ -9,14 +9,21 @@
 package handlers
 
 import (
+	"errors"
+	"net/http"
+
 	"templatev25/internal/http/dto"
 
 	"templatev25/internal/app"
+	"templatev25/internal/imagevariant"
 
 	"templatev25/internal/service"
+	"templatev25/internal/upload"
 	"git.gerege.mn/backend-packages/resp"
+	ssoclient "git.gerege.mn/backend-packages/sso-client"
 
 	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
 )
 
 type FileHandler struct {
@@ -28,13 +35,31 @@ func NewFileHandler(d *app.Dependencies) *FileHandler {
 }
 
 // GET /file/:uuid  -> файл serve хийх
+// GET /file/:uuid?variant=thumb&format=webp -> зургийн хувилбар (IMAGE_VARIANTS)
 func (h *FileHandler) GetFile(c *fiber.Ctx) error {
 	uuid := c.Params("uuid")
 	if uuid == "" {
 		return resp.InternalServerError(c, "uuid is required")
 	}
-	// service талд тусгайлсан хэрэгсэл байхгүй тул шууд дискенээс уншина (хуучин логиктой адил)
-	return c.SendFile(service.PublicImageDir + "/" + uuid)
+	// Тохируулсан storage-оос (local / S3) уншиж stream хийнэ
+	obj, err := h.Service.PublicFile.Open(c.UserContext(), uuid, c.Query("variant"), c.Query("format"))
+	if errors.Is(err, service.ErrFileNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"success": false, "message": err.Error()})
+	}
+	if errors.Is(err, imagevariant.ErrUnavailable) {
+		return resp.BadRequest(c, err.Error(), nil)
+	}
+	if err != nil {
+		h.Log.Error("file_open_failed", zap.String("name", uuid), zap.Error(err))
+		return resp.InternalServerError(c, "file read failed")
+	}
+
+	c.Set(fiber.HeaderContentType, obj.ContentType)
+	if !obj.LastModified.IsZero() {
+		c.Set(fiber.HeaderLastModified, obj.LastModified.UTC().Format(http.TimeFormat))
+	}
+	// SendStream нь дуусахад Body-г хаана
+	return c.SendStream(obj.Body, int(obj.Size))
 }
 
 // POST /file/upload (multipart/form-data)
@@ -63,13 +88,30 @@ func (h *FileHandler) Upload(c *fiber.Ctx) error {
 		return resp.InternalServerError(c, "only one file is allowed")
 	}
 
-	created, err := h.Service.PublicFile.Upload(c.UserContext(), files[0], desc, oldName)
+	created, err := h.Service.PublicFile.Upload(c.UserContext(), ssoclient.GetUserID(c), files[0], desc, oldName)
 	if err != nil {
-		return resp.InternalServerError(c, err.Error())
+		return h.uploadError(c, files[0].Filename, err)
 	}
 	return resp.OK(c, created.FileUrl)
 }
 
+// uploadError нь шалгалтад тэнцээгүй файлыг 400, бусдыг 500 болгоно.
+func (h *FileHandler) uploadError(c *fiber.Ctx, filename string, err error) error {
+	var infected *upload.InfectedError
+	if errors.As(err, &infected) {
+		h.Log.Warn("upload_infected",
+			zap.String("filename", filename),
+			zap.String("signature", infected.Signature),
+			zap.String("quarantine_key", infected.QuarantineKey),
+		)
+	}
+	if errors.Is(err, upload.ErrRejected) {
+		return resp.BadRequest(c, err.Error(), nil)
+	}
+	h.Log.Error("upload_failed", zap.String("filename", filename), zap.Error(err))
+	return resp.InternalServerError(c, err.Error())
+}
+
 // GET /file/list
 func (h *FileHandler) GetPublicFileList(c *fiber.Ctx) error {
 	q, ok := resp.ParamsBindAndValidate[dto.PublicFileListQuery](c)
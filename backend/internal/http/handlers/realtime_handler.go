@@ -0,0 +1,47 @@
+// Package handlers provides implementation for handlers
+//
+// File: realtime_handler.go
+// Description: Ticket endpoint for browser WebSocket/SSE connections
+package handlers
+
+import (
+	"errors"
+
+	"templatev25/internal/auth"
+
+	"git.gerege.mn/backend-packages/resp"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RealtimeHandler нь realtime холболтын ticket олгоно.
+type RealtimeHandler struct {
+	realtimeAuth *auth.RealtimeAuth
+}
+
+// NewRealtimeHandler нь шинэ RealtimeHandler үүсгэнэ.
+func NewRealtimeHandler(realtimeAuth *auth.RealtimeAuth) *RealtimeHandler {
+	return &RealtimeHandler{realtimeAuth: realtimeAuth}
+}
+
+// Ticket godoc
+// @Summary      Issue realtime connection ticket
+// @Description  One-time ticket for /chat-rooms/ws, /notification/ws and /notification/stream, passed as ?ticket=. Browsers cannot set the Authorization header on WebSocket or EventSource, so get a ticket right before connecting. The ticket is valid for 30 seconds and opens one connection; the connection stays bound to the current session.
+// @Tags         realtime
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} map[string]interface{}
+// @Failure      401 {object} map[string]interface{}
+// @Router       /realtime/ticket [post]
+func (h *RealtimeHandler) Ticket(c *fiber.Ctx) error {
+	ticket, expiresAt, err := h.realtimeAuth.IssueTicket(c)
+	if err != nil {
+		if errors.Is(err, auth.ErrNoRealtimeSession) {
+			return resp.Unauthorized(c)
+		}
+		return resp.InternalServerError(c, err.Error())
+	}
+	return resp.OK(c, fiber.Map{
+		"ticket":     ticket,
+		"expires_at": expiresAt,
+	})
+}
@@ -10,8 +10,10 @@ package handlers
 
 import (
 	"templatev25/internal/http/dto"
+	"templatev25/internal/service"
 
 	"context"
+	"errors"
 	"templatev25/internal/app"
 	"time"
 
@@ -114,6 +116,9 @@ func (h *RoleHandler) Update(c *fiber.Ctx) error {
 	}
 	err := h.Service.Role.Update(c.UserContext(), params.ID, req)
 	if err != nil {
+		if errors.Is(err, service.ErrRoleCycle) {
+			return resp.BadRequest(c, err.Error(), nil)
+		}
 		h.Log.Error("access_group_update_failed", zap.Error(err))
 		return resp.InternalServerError(c, err.Error())
 	}
@@ -174,6 +179,33 @@ func (h *RoleHandler) GetRolePermissions(c *fiber.Ctx) error {
 	return resp.OK(c, items)
 }
 
+// GetRoleEffectivePermissions godoc
+// @Summary      List effective permissions of a role
+// @Description  Direct permissions plus permissions covered by the role's wildcard grants (granted_by)
+// @Tags         role
+// @Security     BearerAuth
+// @Produce      json
+// @Param        role_id query int true "Role ID"
+// @Success      200 {object} dto.Response
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      500 {object} dto.ErrorResponse
+// @Router       /role/permissions/effective [get]
+func (h *RoleHandler) GetRoleEffectivePermissions(c *fiber.Ctx) error {
+	q, ok := resp.QueryBindAndValidate[dto.RolePermissionsQuery](c)
+	if !ok {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	items, err := h.Service.Role.GetEffectivePermissions(ctx, q)
+	if err != nil {
+		return resp.InternalServerError(c, err.Error())
+	}
+	return resp.OK(c, items)
+}
+
 // --- ШИНЭ: POST /role/permissions (replace semantics)
 
 // SetRolePermissions godoc
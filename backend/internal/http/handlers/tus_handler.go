@@ -0,0 +1,200 @@
+// Package handlers provides implementation for handlers
+//
+// File: tus_handler.go
+// Description: Resumable uploads over the tus protocol (/uploads)
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"templatev25/internal/app"
+	"templatev25/internal/domain"
+	"templatev25/internal/service"
+	"templatev25/internal/tus"
+	"templatev25/internal/upload"
+
+	ssoclient "git.gerege.mn/backend-packages/sso-client"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// HeaderFileURL нь дууссан upload-ийн file_url (PATCH, HEAD хариунд).
+const HeaderFileURL = "X-File-Url"
+
+type TusHandler struct {
+	*app.Dependencies
+}
+
+func NewTusHandler(d *app.Dependencies) *TusHandler {
+	return &TusHandler{Dependencies: d}
+}
+
+// Resumable нь бүх хариунд Tus-Resumable тавьж, OPTIONS-оос бусад
+// request-ийн protocol хувилбарыг шалгана (таарахгүй бол 412).
+func (h *TusHandler) Resumable(c *fiber.Ctx) error {
+	c.Set(tus.HeaderResumable, tus.Version)
+	if c.Method() != fiber.MethodOptions && c.Get(tus.HeaderResumable) != tus.Version {
+		c.Set(tus.HeaderVersion, tus.Version)
+		return tusFail(c, fiber.StatusPreconditionFailed, "unsupported Tus-Resumable version")
+	}
+	return c.Next()
+}
+
+// OPTIONS /uploads -> серверийн tus боломжууд
+func (h *TusHandler) Options(c *fiber.Ctx) error {
+	c.Set(tus.HeaderVersion, tus.Version)
+	c.Set(tus.HeaderExtension, tus.Extensions)
+	c.Set(tus.HeaderMaxSize, strconv.FormatInt(h.Service.ResumableUpload.MaxSize(), 10))
+	c.Set(tus.HeaderChecksumAlgorithm, tus.ChecksumAlgorithms())
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// POST /uploads (Upload-Length, Upload-Metadata: filename, description)
+// -> 201, Location. Body-той бол эхний хэсэг (creation-with-upload).
+func (h *TusHandler) Create(c *fiber.Ctx) error {
+	if c.Get(tus.HeaderUploadDeferLength) != "" {
+		return tusFail(c, fiber.StatusBadRequest, "Upload-Defer-Length is not supported")
+	}
+	length, err := tus.ParseSize(c.Get(tus.HeaderUploadLength))
+	if err != nil {
+		return tusFail(c, fiber.StatusBadRequest, "invalid Upload-Length")
+	}
+	meta, err := tus.ParseMetadata(c.Get(tus.HeaderUploadMetadata))
+	if err != nil {
+		return tusFail(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	userID := ssoclient.GetUserID(c)
+	u, err := h.Service.ResumableUpload.Create(c.UserContext(), userID, length, meta)
+	if err != nil {
+		return h.tusError(c, err)
+	}
+	c.Set(fiber.HeaderLocation, h.Service.ResumableUpload.URL(u.Id))
+
+	if len(c.Body()) > 0 {
+		if u, err = h.appendBody(c, userID, u.Id, 0); err != nil {
+			return h.tusError(c, err)
+		}
+	}
+	setUploadHeaders(c, u)
+	return c.SendStatus(fiber.StatusCreated)
+}
+
+// HEAD /uploads/:id -> Upload-Offset (хаанаас үргэлжлүүлэх)
+func (h *TusHandler) Head(c *fiber.Ctx) error {
+	u, err := h.Service.ResumableUpload.Get(c.UserContext(), ssoclient.GetUserID(c), c.Params("id"))
+	if err != nil {
+		return h.tusError(c, err)
+	}
+	c.Set(tus.HeaderUploadLength, strconv.FormatInt(u.UploadLength, 10))
+	meta := map[string]string{}
+	if u.Filename != "" {
+		meta["filename"] = u.Filename
+	}
+	if u.Description != "" {
+		meta["description"] = u.Description
+	}
+	if len(meta) > 0 {
+		c.Set(tus.HeaderUploadMetadata, tus.EncodeMetadata(meta))
+	}
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	setUploadHeaders(c, u)
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// PATCH /uploads/:id (Upload-Offset, Upload-Checksum; application/offset+octet-stream)
+// -> 204, шинэ Upload-Offset. Сүүлийн хэсгийн дараа X-File-Url.
+func (h *TusHandler) Patch(c *fiber.Ctx) error {
+	offset, err := tus.ParseSize(c.Get(tus.HeaderUploadOffset))
+	if err != nil {
+		return tusFail(c, fiber.StatusBadRequest, "invalid Upload-Offset")
+	}
+	u, err := h.appendBody(c, ssoclient.GetUserID(c), c.Params("id"), offset)
+	if err != nil {
+		return h.tusError(c, err)
+	}
+	setUploadHeaders(c, u)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// DELETE /uploads/:id -> upload цуцлах (termination)
+func (h *TusHandler) Terminate(c *fiber.Ctx) error {
+	if err := h.Service.ResumableUpload.Terminate(c.UserContext(), ssoclient.GetUserID(c), c.Params("id")); err != nil {
+		return h.tusError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// errTusContentType, errTusChecksum нь body-г уншихаас өмнөх алдаанууд.
+var (
+	errTusContentType = errors.New("Content-Type must be " + tus.OffsetContentType)
+	errTusChecksum    = errors.New("invalid Upload-Checksum")
+)
+
+// appendBody нь request body-г offset-оос нэмнэ (PATCH, creation-with-upload).
+func (h *TusHandler) appendBody(c *fiber.Ctx, userID int, id string, offset int64) (domain.FileUpload, error) {
+	if c.Get(fiber.HeaderContentType) != tus.OffsetContentType {
+		return domain.FileUpload{}, errTusContentType
+	}
+	var sum *tus.Checksum
+	if header := c.Get(tus.HeaderUploadChecksum); header != "" {
+		parsed, err := tus.ParseChecksum(header)
+		if err != nil {
+			return domain.FileUpload{}, errTusChecksum
+		}
+		sum = &parsed
+	}
+	return h.Service.ResumableUpload.Append(c.UserContext(), userID, id, offset, c.Body(), sum)
+}
+
+// setUploadHeaders нь Upload-Offset, Upload-Expires болон дууссан бол X-File-Url тавина.
+func setUploadHeaders(c *fiber.Ctx, u domain.FileUpload) {
+	c.Set(tus.HeaderUploadOffset, strconv.FormatInt(u.UploadOffset, 10))
+	c.Set(tus.HeaderUploadExpires, u.ExpiresAt.UTC().Format(http.TimeFormat))
+	if u.FileUrl != "" {
+		c.Set(HeaderFileURL, u.FileUrl)
+	}
+}
+
+// tusError нь service-ийн алдааг tus-ийн status code болгоно.
+func (h *TusHandler) tusError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, errTusContentType):
+		return tusFail(c, fiber.StatusUnsupportedMediaType, err.Error())
+	case errors.Is(err, errTusChecksum):
+		return tusFail(c, fiber.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrUploadNotFound):
+		return tusFail(c, fiber.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrUploadExpired):
+		return tusFail(c, fiber.StatusGone, err.Error())
+	case errors.Is(err, service.ErrUploadTooLarge), errors.Is(err, service.ErrUploadLengthExceeded):
+		return tusFail(c, fiber.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, service.ErrUploadOffsetMismatch):
+		return tusFail(c, fiber.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrUploadChecksumMismatch):
+		return tusFail(c, tus.StatusChecksumMismatch, err.Error())
+	case errors.Is(err, service.ErrUploadBusy):
+		return tusFail(c, fiber.StatusLocked, err.Error())
+	}
+
+	var infected *upload.InfectedError
+	if errors.As(err, &infected) {
+		h.Log.Warn("upload_infected",
+			zap.String("upload_id", c.Params("id")),
+			zap.String("signature", infected.Signature),
+			zap.String("quarantine_key", infected.QuarantineKey),
+		)
+	}
+	if errors.Is(err, upload.ErrRejected) {
+		return tusFail(c, fiber.StatusBadRequest, err.Error())
+	}
+	h.Log.Error("resumable_upload_failed", zap.String("upload_id", c.Params("id")), zap.Error(err))
+	return tusFail(c, fiber.StatusInternalServerError, "upload failed")
+}
+
+func tusFail(c *fiber.Ctx, status int, message string) error {
+	return c.Status(status).JSON(fiber.Map{"success": false, "message": message})
+}
@@ -0,0 +1,95 @@
+// Package handlers provides implementation for handlers
+//
+// File: user_device_handler.go
+// Description: Current user's devices for push notifications (/me/devices)
+package handlers
+
+import (
+	"errors"
+
+	"templatev25/internal/app"
+	"templatev25/internal/http/dto"
+	"templatev25/internal/service"
+
+	"git.gerege.mn/backend-packages/resp"
+	ssoclient "git.gerege.mn/backend-packages/sso-client"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type UserDeviceHandler struct {
+	*app.Dependencies
+}
+
+func NewUserDeviceHandler(d *app.Dependencies) *UserDeviceHandler {
+	return &UserDeviceHandler{Dependencies: d}
+}
+
+// List godoc
+// @Summary      List my devices
+// @Description  Devices registered by the current user. Push tokens are not returned; is_active is false once the push provider rejected the token.
+// @Tags         me
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} map[string]interface{}
+// @Router       /me/devices [get]
+func (h *UserDeviceHandler) List(c *fiber.Ctx) error {
+	items, err := h.Service.UserDevice.List(c.UserContext(), ssoclient.GetUserID(c))
+	if err != nil {
+		return h.deviceError(c, err)
+	}
+	return resp.OK(c, items)
+}
+
+// Register godoc
+// @Summary      Register device
+// @Description  Register or refresh a device and its push token (call on app start and when the token changes). push_provider (fcm, apns) is required with push_token. A token moved to another account is removed from the previous one.
+// @Tags         me
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body body dto.UserDeviceRegisterDto true "Device"
+// @Success      200 {object} map[string]interface{}
+// @Failure      400 {object} map[string]interface{}
+// @Router       /me/devices [post]
+func (h *UserDeviceHandler) Register(c *fiber.Ctx) error {
+	req, ok := resp.BodyBindAndValidate[dto.UserDeviceRegisterDto](c)
+	if !ok {
+		return nil
+	}
+	d, err := h.Service.UserDevice.Register(c.UserContext(), ssoclient.GetUserID(c), req)
+	if err != nil {
+		return h.deviceError(c, err)
+	}
+	return resp.OK(c, d)
+}
+
+// Unregister godoc
+// @Summary      Unregister device
+// @Description  Remove a device (on logout) so it no longer receives push notifications
+// @Tags         me
+// @Security     BearerAuth
+// @Produce      json
+// @Param        device_id path string true "Device ID"
+// @Success      200 {object} map[string]interface{}
+// @Failure      404 {object} map[string]interface{} "Device not found"
+// @Router       /me/devices/{device_id} [delete]
+func (h *UserDeviceHandler) Unregister(c *fiber.Ctx) error {
+	if err := h.Service.UserDevice.Unregister(c.UserContext(), ssoclient.GetUserID(c), c.Params("device_id")); err != nil {
+		return h.deviceError(c, err)
+	}
+	return resp.OK(c)
+}
+
+// deviceError нь service-ийн алдааг HTTP хариу руу хөрвүүлнэ.
+func (h *UserDeviceHandler) deviceError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, service.ErrDeviceNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+	h.Log.Error("user_device_request_failed", zap.Error(err))
+	return resp.InternalServerError(c, err.Error())
+}
@@ -7,6 +7,7 @@ package handlers
 import (
 	"errors"
 	"strconv"
+	"strings"
 
 	"templatev25/internal/domain"
 	"templatev25/internal/http/dto"
@@ -46,14 +47,28 @@ func (h *UserManagementHandler) GetMFAStatus(c *fiber.Ctx) error {
 		return resp.Unauthorized(c)
 	}
 
-	enabled, hasBackup, err := h.authService.GetMFAStatus(c.UserContext(), userID)
+	status, err := h.authService.GetMFAStatus(c.UserContext(), userID)
 	if err != nil {
 		return resp.InternalServerError(c, err.Error())
 	}
 
+	credentials := make([]dto.WebAuthnCredentialResponse, 0, len(status.WebAuthnCredentials))
+	for i := range status.WebAuthnCredentials {
+		credentials = append(credentials, toWebAuthnCredentialResponse(&status.WebAuthnCredentials[i]))
+	}
+
+	methods := status.Methods
+	if methods == nil {
+		methods = []string{}
+	}
+
 	return resp.OK(c, dto.MFAStatusResponse{
-		Enabled:        enabled,
-		HasBackupCodes: hasBackup,
+		Enabled:             status.Enabled,
+		TOTPEnabled:         status.TOTPEnabled,
+		HasBackupCodes:      status.HasBackupCodes,
+		BackupCodesLeft:     status.BackupCodesLeft,
+		Methods:             methods,
+		WebAuthnCredentials: credentials,
 	})
 }
 
@@ -171,6 +186,146 @@ func (h *UserManagementHandler) DisableTOTP(c *fiber.Ctx) error {
 	return resp.OK(c, fiber.Map{"message": "MFA disabled successfully"})
 }
 
+// BeginWebAuthnRegistration godoc
+// @Summary      Begin WebAuthn registration
+// @Description  Returns PublicKeyCredentialCreationOptions for navigator.credentials.create()
+// @Tags         local-auth-user
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} webauthn.CreationOptions
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Router       /auth/local/me/mfa/webauthn/register/begin [post]
+func (h *UserManagementHandler) BeginWebAuthnRegistration(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	sessionID := getSessionID(c)
+	if userID == 0 || sessionID == "" {
+		return resp.Unauthorized(c)
+	}
+
+	opts, err := h.authService.BeginWebAuthnRegistration(c.UserContext(), userID, getEmail(c), sessionID)
+	if err != nil {
+		if errors.Is(err, service.ErrWebAuthnDisabled) {
+			return resp.BadRequest(c, "WebAuthn is not available", nil)
+		}
+		return resp.InternalServerError(c, err.Error())
+	}
+
+	return resp.OK(c, opts)
+}
+
+// FinishWebAuthnRegistration godoc
+// @Summary      Finish WebAuthn registration
+// @Description  Verifies the authenticator response and enrolls the credential as a second factor
+// @Tags         local-auth-user
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body body dto.WebAuthnRegisterFinishRequest true "Authenticator response"
+// @Success      200 {object} dto.WebAuthnRegisterFinishResponse
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      409 {object} dto.ErrorResponse "Credential already registered"
+// @Router       /auth/local/me/mfa/webauthn/register/finish [post]
+func (h *UserManagementHandler) FinishWebAuthnRegistration(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	sessionID := getSessionID(c)
+	if userID == 0 || sessionID == "" {
+		return resp.Unauthorized(c)
+	}
+
+	req, ok := resp.BodyBindAndValidate[dto.WebAuthnRegisterFinishRequest](c)
+	if !ok {
+		return nil
+	}
+
+	result, err := h.authService.FinishWebAuthnRegistration(
+		c.UserContext(),
+		userID,
+		sessionID,
+		req.Name,
+		req.Credential,
+		c.IP(),
+		c.Get("User-Agent"),
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidSession):
+			return resp.BadRequest(c, "registration expired, please start again", nil)
+		case errors.Is(err, service.ErrInvalidWebAuthnResponse):
+			return resp.BadRequest(c, "invalid authenticator response", nil)
+		case errors.Is(err, service.ErrWebAuthnCredentialExists):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"success": false,
+				"message": "this authenticator is already registered",
+			})
+		case errors.Is(err, service.ErrWebAuthnDisabled):
+			return resp.BadRequest(c, "WebAuthn is not available", nil)
+		default:
+			return resp.InternalServerError(c, err.Error())
+		}
+	}
+
+	return resp.OK(c, dto.WebAuthnRegisterFinishResponse{
+		Credential:  toWebAuthnCredentialResponse(result.Credential),
+		BackupCodes: result.BackupCodes,
+	})
+}
+
+// RemoveWebAuthnCredential godoc
+// @Summary      Remove WebAuthn credential
+// @Tags         local-auth-user
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        id path int true "Credential ID"
+// @Param        body body dto.RemoveWebAuthnRequest true "Current password"
+// @Success      200 {object} dto.Response
+// @Failure      400 {object} dto.ErrorResponse
+// @Failure      401 {object} dto.ErrorResponse
+// @Failure      404 {object} dto.ErrorResponse
+// @Router       /auth/local/me/mfa/webauthn/{id} [delete]
+func (h *UserManagementHandler) RemoveWebAuthnCredential(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	if userID == 0 {
+		return resp.Unauthorized(c)
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil || id <= 0 {
+		return resp.BadRequest(c, "invalid credential id", nil)
+	}
+
+	req, ok := resp.BodyBindAndValidate[dto.RemoveWebAuthnRequest](c)
+	if !ok {
+		return nil
+	}
+
+	err = h.authService.RemoveWebAuthnCredential(
+		c.UserContext(),
+		userID,
+		id,
+		req.Password,
+		c.IP(),
+		c.Get("User-Agent"),
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
+			return resp.BadRequest(c, "invalid password", nil)
+		case errors.Is(err, service.ErrWebAuthnCredentialNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"success": false,
+				"message": "credential not found",
+			})
+		default:
+			return resp.InternalServerError(c, err.Error())
+		}
+	}
+
+	return resp.OK(c, fiber.Map{"message": "WebAuthn credential removed"})
+}
+
 // GenerateBackupCodes godoc
 // @Summary      Generate new backup codes
 // @Tags         local-auth-user
@@ -549,6 +704,20 @@ func (h *UserManagementHandler) SetUserPassword(c *fiber.Ctx) error {
 }
 
 // Helper function
+func toWebAuthnCredentialResponse(cred *domain.UserWebAuthnCredential) dto.WebAuthnCredentialResponse {
+	var transports []string
+	if cred.Transports != "" {
+		transports = strings.Split(cred.Transports, ",")
+	}
+	return dto.WebAuthnCredentialResponse{
+		ID:             cred.ID,
+		Name:           cred.Name,
+		Transports:     transports,
+		BackupEligible: cred.BackupEligible,
+		LastUsedAt:     cred.LastUsedAt,
+	}
+}
+
 func getEmail(c *fiber.Ctx) string {
 	if email, ok := c.Locals("email").(string); ok {
 		return email
@@ -37,6 +37,7 @@ func NewUserRoleHandler(d *app.Dependencies) *UserRoleHandler {
 // @Security     BearerAuth
 // @Produce      json
 // @Param        role_id query int true "Role ID"
+// @Param        org_id  query int false "Organization ID"
 // @Param        page    query int false "Page number"
 // @Param        size    query int false "Page size"
 // @Success      200 {object} map[string]interface{}
@@ -65,6 +66,7 @@ func (h *UserRoleHandler) UsersByRole(c *fiber.Ctx) error {
 // @Security     BearerAuth
 // @Produce      json
 // @Param        user_id query int true "User ID"
+// @Param        org_id  query int false "Organization ID"
 // @Param        page    query int false "Page number"
 // @Param        size    query int false "Page size"
 // @Success      200 {object} map[string]interface{}
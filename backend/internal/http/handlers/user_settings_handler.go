@@ -0,0 +1,76 @@
+// Package handlers provides implementation for handlers
+//
+// File: user_settings_handler.go
+// Description: Current user's settings (/me/settings)
+package handlers
+
+import (
+	"errors"
+
+	"templatev25/internal/app"
+	"templatev25/internal/http/dto"
+	"templatev25/internal/service"
+
+	"git.gerege.mn/backend-packages/resp"
+	ssoclient "git.gerege.mn/backend-packages/sso-client"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+type UserSettingsHandler struct {
+	*app.Dependencies
+}
+
+func NewUserSettingsHandler(d *app.Dependencies) *UserSettingsHandler {
+	return &UserSettingsHandler{Dependencies: d}
+}
+
+// Get godoc
+// @Summary      Get my settings
+// @Description  Theme, language, notification channels, privacy flags and custom_settings of the current user. Default values are returned (id = 0) until the user saves settings.
+// @Tags         me
+// @Security     BearerAuth
+// @Produce      json
+// @Success      200 {object} map[string]interface{}
+// @Router       /me/settings [get]
+func (h *UserSettingsHandler) Get(c *fiber.Ctx) error {
+	m, err := h.Service.UserSettings.Get(c.UserContext(), ssoclient.GetUserID(c))
+	if err != nil {
+		return h.settingsError(c, err)
+	}
+	return resp.OK(c, m)
+}
+
+// Update godoc
+// @Summary      Update my settings
+// @Description  Partial update: omitted or null fields keep their value. custom_settings replaces the stored object and is validated against the custom settings JSON schema; errors are returned in details with JSON pointer paths.
+// @Tags         me
+// @Security     BearerAuth
+// @Accept       json
+// @Produce      json
+// @Param        body body dto.UserSettingsUpdateDto true "Settings to change"
+// @Success      200 {object} map[string]interface{}
+// @Failure      400 {object} map[string]interface{} "Validation failed"
+// @Router       /me/settings [patch]
+func (h *UserSettingsHandler) Update(c *fiber.Ctx) error {
+	req, ok := resp.BodyBindAndValidate[dto.UserSettingsUpdateDto](c)
+	if !ok {
+		return nil
+	}
+	m, err := h.Service.UserSettings.Update(c.UserContext(), ssoclient.GetUserID(c), req)
+	if err != nil {
+		return h.settingsError(c, err)
+	}
+	return resp.OK(c, m)
+}
+
+// settingsError нь service-ийн алдааг HTTP хариу руу хөрвүүлнэ.
+func (h *UserSettingsHandler) settingsError(c *fiber.Ctx, err error) error {
+	var verr *service.UserSettingsValidationError
+	if errors.As(err, &verr) {
+		return resp.BadRequest(c, service.ErrUserSettingsInvalid.Error(), verr.Errors)
+	}
+	h.Log.Error("user_settings_request_failed", zap.Error(err))
+	return resp.InternalServerError(c, err.Error())
+}
@@ -36,10 +36,12 @@ import (
 //   - POST /auth/local/logout       → Local logout (protected)
 //   - POST /auth/local/logout-all   → Logout all sessions (protected)
 //   - POST /auth/local/refresh      → Refresh session (protected)
+//   - POST /auth/local/token/refresh → Rotate refresh token
 //
 // Security:
 //   - AuthRateLimiter: 5 req/min per IP for login/callback (brute force protection)
 //   - StrictRateLimiter: 3 req/5min for sensitive operations
+//   - TokenRefreshRateLimiter: 300 req/min per IP for refresh token rotation
 func MapAuthRoutes(v1 fiber.Router, d *app.Dependencies, requireAuth fiber.Handler) {
 	// ------------------------------------------------------------
 	// SSO AUTH ROUTES
@@ -122,6 +124,12 @@ func MapAuthRoutes(v1 fiber.Router, d *app.Dependencies, requireAuth fiber.Handl
 		// POST /auth/local/refresh → Extend session expiry
 		router.Post("/refresh", sessionAuth, localAuthHandler.RefreshSession)
 
+		// Refresh token rotation (public, the refresh token is the credential)
+		// POST /auth/local/token/refresh → New access + refresh token
+		// Reusing an already rotated token revokes the whole token family
+		// Rate limited: 300 req/min per IP (routine refreshes behind carrier NAT)
+		router.Post("/token/refresh", middleware.TokenRefreshRateLimiter(), localAuthHandler.RefreshToken)
+
 		// ------------------------------------------------------------
 		// REGISTRATION ROUTES (Public)
 		// ------------------------------------------------------------
@@ -0,0 +1,69 @@
+// Package router provides implementation for router
+//
+// File: chat_room_router.go
+// Description: Chat room, message and realtime WebSocket routes
+package router
+
+import (
+	"strings"
+	"time"
+
+	"templatev25/internal/app"
+	"templatev25/internal/http/handlers"
+	"templatev25/internal/middleware"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+// MapChatRoomRoutes нь chat өрөө, мессеж болон WebSocket route-уудыг бүртгэнэ.
+// Эрхийг admin permission биш өрөөний гишүүнчлэл (owner/admin/moderator/member)-ээр шалгана.
+// realtimeAuth нь WebSocket route-д ticket (?ticket=) эсвэл ердийн session-ийг шалгана.
+func MapChatRoomRoutes(v1 fiber.Router, d *app.Dependencies, requireAuth, realtimeAuth fiber.Handler) {
+	h := handlers.NewChatRoomHandler(d)
+
+	// ------------------------------------------------------------
+	// REALTIME (WebSocket)
+	// ------------------------------------------------------------
+	// Group-ийн Timeout middleware урт холболтод хамаарахгүйн тулд түрүүлж бүртгэнэ.
+	v1.Get("/chat-rooms/ws", realtimeAuth, h.Upgrade, websocket.New(h.Stream, websocket.Config{
+		Origins: wsOrigins(d.Cfg.CORS.AllowOrigins),
+	}))
+
+	// ------------------------------------------------------------
+	// CHAT ROOM ROUTES
+	// ------------------------------------------------------------
+	v1.Group("/chat-rooms", requireAuth, middleware.Timeout(10*time.Second)).Route("", func(router fiber.Router) {
+		router.Get("/", h.List)
+		router.Post("/", h.Create)
+		router.Get("/unread", h.Unread)
+		router.Get("/:id", h.Get)
+		router.Put("/:id", h.Update)
+		router.Delete("/:id", h.Delete)
+		router.Post("/:id/read", h.Read)
+
+		// Members
+		router.Get("/:id/members", h.Members)
+		router.Post("/:id/members", h.AddMembers)
+		router.Put("/:id/members/:user_id", h.SetMemberRole)
+		router.Delete("/:id/members/:user_id", h.RemoveMember)
+
+		// Messages
+		router.Get("/:id/messages", h.Messages)
+		router.Post("/:id/messages", h.SendMessage)
+		router.Put("/:id/messages/:message_id", h.EditMessage)
+		router.Delete("/:id/messages/:message_id", h.DeleteMessage)
+	})
+}
+
+// wsOrigins нь CORS-ийн зөвшөөрсөн origin-уудыг WebSocket upgrade-д ашиглана.
+// Хоосон бол бүх origin зөвшөөрөгдөнө (websocket default).
+func wsOrigins(allow string) []string {
+	var origins []string
+	for _, o := range strings.Split(allow, ",") {
+		if o = strings.TrimSpace(o); o != "" {
+			origins = append(origins, o)
+		}
+	}
+	return origins
+}
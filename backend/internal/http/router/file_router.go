@@ -15,6 +15,7 @@ import (
 	"templatev25/internal/auth"
 	"templatev25/internal/http/handlers"
 	"templatev25/internal/middleware"
+	"templatev25/internal/signedurl"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -36,9 +37,43 @@ func MapFileRoutes(v1 fiber.Router, d *app.Dependencies, requireAuth fiber.Handl
 		router.Post("/upload", requireAuth, auth.RequirePermission(perm, "admin.file.create"), h.Upload)
 		router.Delete("/", requireAuth, auth.RequirePermission(perm, "admin.file.delete"), h.DeletePublicFile)
 
+		// Хувийн файл: эзэмшигч эсвэл admin.file.private.* эрхтэй хэрэглэгч
+		router.Get("/private", requireAuth, h.ListPrivate)
+		router.Post("/private/upload", requireAuth, h.UploadPrivate)
+		router.Post("/private/:name/sign", requireAuth, h.SignPrivate)
+		router.Delete("/private/:name", requireAuth, h.DeletePrivate)
+		// GET /file/private/:name?exp=&sig= → signed link (uid-гүй бол auth хэрэггүй)
+		router.Get("/private/:name", signedOrAuth(requireAuth), h.DownloadPrivate)
+
 		// Public file download (auth хэрэггүй)
 		// GET /file/:uuid → Download file by UUID
 		router.Get("/:uuid", h.GetFile)
 	})
+
+	// ------------------------------------------------------------
+	// RESUMABLE UPLOAD ROUTES (tus 1.0.0)
+	// ------------------------------------------------------------
+	// Том файлыг хэсэгчлэн upload хийнэ. /file-ийн 5s timeout-оос тусдаа;
+	// хэсэг бүр BodySizeLimit (2MB)-ээс бага байх ёстой.
+	v1.Group("/uploads", middleware.Timeout(time.Minute)).Route("", func(router fiber.Router) {
+		h := handlers.NewTusHandler(d)
+		router.Use(h.Resumable)
+
+		router.Options("/", h.Options)
+		router.Post("/", requireAuth, auth.RequirePermission(perm, "admin.file.create"), h.Create)
+		router.Head("/:id", requireAuth, h.Head)
+		router.Patch("/:id", requireAuth, h.Patch)
+		router.Delete("/:id", requireAuth, h.Terminate)
+	})
 }
 
+// signedOrAuth нь хэрэглэгчид холбогдоогүй signed link-ийг нэвтрэлтгүй
+// нэвтрүүлнэ (link-ийг handler шалгана); бусад үед requireAuth.
+func signedOrAuth(requireAuth fiber.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Query(signedurl.ParamSignature) != "" && c.Query(signedurl.ParamUserID) == "" {
+			return c.Next()
+		}
+		return requireAuth(c)
+	}
+}
@@ -0,0 +1,36 @@
+// Package router provides implementation for router
+//
+// File: job_router.go
+// Description: Scheduled job admin routes
+package router
+
+import (
+	"time"
+
+	"templatev25/internal/app"
+	"templatev25/internal/auth"
+	"templatev25/internal/http/handlers"
+	"templatev25/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MapJobRoutes нь scheduled job-ийн admin route-уудыг бүртгэнэ.
+func MapJobRoutes(v1 fiber.Router, d *app.Dependencies, requireAuth fiber.Handler) {
+	// Permission checker (cache-тэй)
+	perm := d.PermCache
+
+	// ------------------------------------------------------------
+	// SCHEDULED JOB ROUTES
+	// ------------------------------------------------------------
+	// Job-уудын жагсаалт, түүх, гараар ажиллуулах, pause/resume.
+	v1.Group("/jobs", requireAuth, middleware.Timeout(10*time.Second)).Route("", func(router fiber.Router) {
+		h := handlers.NewJobHandler(d)
+
+		router.Get("/", auth.RequirePermission(perm, "admin.job.read"), h.List)
+		router.Get("/:id/executions", auth.RequirePermission(perm, "admin.job.read"), h.Executions)
+		router.Post("/:id/trigger", auth.RequirePermission(perm, "admin.job.update"), h.Trigger)
+		router.Put("/:id/pause", auth.RequirePermission(perm, "admin.job.update"), h.Pause)
+		router.Put("/:id/resume", auth.RequirePermission(perm, "admin.job.update"), h.Resume)
+	})
+}
@@ -27,6 +27,15 @@ import (
 //   - GET  /me/profile/sso → SSO profile
 //   - GET  /me/organizations → User organizations
 //
+//   Settings:
+//   - GET   /me/settings → Theme, language, notification channels, privacy, custom_settings
+//   - PATCH /me/settings → Partial update
+//
+//   Devices (push notifications):
+//   - GET    /me/devices            → Registered devices
+//   - POST   /me/devices            → Register / refresh device and push token
+//   - DELETE /me/devices/:device_id → Unregister device
+//
 //   Security (Local Auth) - Path: /auth/local/me/*
 //   - GET    /auth/local/me/sessions         → List active sessions
 //   - DELETE /auth/local/me/sessions/:id     → Revoke specific session
@@ -35,6 +44,9 @@ import (
 //   - POST   /auth/local/me/mfa/totp/setup   → Setup TOTP
 //   - POST   /auth/local/me/mfa/totp/confirm → Confirm TOTP setup
 //   - DELETE /auth/local/me/mfa/totp         → Disable TOTP
+//   - POST   /auth/local/me/mfa/webauthn/register/begin  → Start WebAuthn registration
+//   - POST   /auth/local/me/mfa/webauthn/register/finish → Finish WebAuthn registration
+//   - DELETE /auth/local/me/mfa/webauthn/:id              → Remove WebAuthn credential
 //   - POST   /auth/local/me/mfa/backup-codes → Generate backup codes
 //   - GET    /auth/local/me/login-history    → Login history
 //   - GET    /auth/local/me/security-audit   → Security audit trail
@@ -60,6 +72,17 @@ func MapMeRoutes(v1 fiber.Router, d *app.Dependencies, requireAuth fiber.Handler
 		router.Get("/profile/sso", middleware.Timeout(5*time.Second), userHandler.ProfileSSO)
 		router.Get("/organizations", middleware.Timeout(5*time.Second), userHandler.Organizations)
 
+		// Settings (user_settings)
+		settingsHandler := handlers.NewUserSettingsHandler(d)
+		router.Get("/settings", middleware.Timeout(5*time.Second), settingsHandler.Get)
+		router.Patch("/settings", middleware.Timeout(5*time.Second), settingsHandler.Update)
+
+		// Devices (user_devices)
+		deviceHandler := handlers.NewUserDeviceHandler(d)
+		router.Get("/devices", middleware.Timeout(5*time.Second), deviceHandler.List)
+		router.Post("/devices", middleware.Timeout(5*time.Second), deviceHandler.Register)
+		router.Delete("/devices/:device_id", middleware.Timeout(5*time.Second), deviceHandler.Unregister)
+
 		// Account management
 		accr := router.Group("/accounts")
 		accr.Get("/", middleware.Timeout(5*time.Second), tpayHandler.Account.GetMyAccounts)
@@ -118,6 +141,14 @@ func MapMeRoutes(v1 fiber.Router, d *app.Dependencies, requireAuth fiber.Handler
 		mfar.Post("/totp/confirm", strictLimiter, middleware.Timeout(5*time.Second), userMgmtHandler.ConfirmTOTP)
 		mfar.Delete("/totp", strictLimiter, middleware.Timeout(5*time.Second), userMgmtHandler.DisableTOTP)
 
+		// WebAuthn / passkey (rate limited)
+		// POST   /me/mfa/webauthn/register/begin  → Creation options (challenge bound to session)
+		// POST   /me/mfa/webauthn/register/finish → Verify and store credential
+		// DELETE /me/mfa/webauthn/:id             → Remove credential (password required)
+		mfar.Post("/webauthn/register/begin", strictLimiter, middleware.Timeout(5*time.Second), userMgmtHandler.BeginWebAuthnRegistration)
+		mfar.Post("/webauthn/register/finish", middleware.Timeout(5*time.Second), userMgmtHandler.FinishWebAuthnRegistration)
+		mfar.Delete("/webauthn/:id", strictLimiter, middleware.Timeout(5*time.Second), userMgmtHandler.RemoveWebAuthnCredential)
+
 		// Backup codes (rate limited)
 		// POST /me/mfa/backup-codes → Generate new backup codes
 		mfar.Post("/backup-codes", strictLimiter, middleware.Timeout(5*time.Second), userMgmtHandler.GenerateBackupCodes)
@@ -16,14 +16,26 @@ import (
 	"templatev25/internal/http/handlers"
 	"templatev25/internal/middleware"
 
+	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 )
 
 // MapNotificationRoutes нь notification route-уудыг бүртгэнэ.
-func MapNotificationRoutes(v1 fiber.Router, d *app.Dependencies, requireAuth fiber.Handler) {
+// realtimeAuth нь stream route-уудад ticket (?ticket=) эсвэл ердийн session-ийг шалгана.
+func MapNotificationRoutes(v1 fiber.Router, d *app.Dependencies, requireAuth, realtimeAuth fiber.Handler) {
 	// Permission checker (cache-тэй)
 	perm := d.PermCache
 
+	// ------------------------------------------------------------
+	// REALTIME STREAMS
+	// ------------------------------------------------------------
+	// Урт хугацааны холболт тул Timeout middleware-ийн өмнө бүртгэнэ.
+	stream := handlers.NewNotificationHandler(d)
+	v1.Get("/notification/stream", realtimeAuth, stream.Stream)
+	v1.Get("/notification/ws", realtimeAuth, stream.Upgrade, websocket.New(stream.Socket, websocket.Config{
+		Origins: wsOrigins(d.Cfg.CORS.AllowOrigins),
+	}))
+
 	// ------------------------------------------------------------
 	// NOTIFICATION ROUTES
 	// ------------------------------------------------------------
@@ -40,6 +52,9 @@ func MapNotificationRoutes(v1 fiber.Router, d *app.Dependencies, requireAuth fib
 		// Send notification (requires admin permission)
 		router.Post("/", auth.RequirePermission(perm, "admin.notification.create"), h.Send)
 
+		// Email/SMS/push delivery log (requires admin permission)
+		router.Get("/deliveries", auth.RequirePermission(perm, "admin.notification.create"), h.Deliveries)
+
 		// Mark as read (user's own notifications - no admin permission required)
 		router.Post("/read", h.Read)
 		router.Post("/read-all", h.ReadAll)
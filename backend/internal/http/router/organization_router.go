@@ -42,6 +42,11 @@ func MapOrganizationRoutes(v1 fiber.Router, d *app.Dependencies, requireAuth fib
 
 		// Get organization tree (hierarchical structure)
 		router.Get("/tree", auth.RequirePermission(perm, "admin.organization.read"), h.Tree)
+
+		// Hierarchy: subtree (flat), ancestors (root-first), move under a new parent
+		router.Get("/:id/subtree", auth.RequirePermission(perm, "admin.organization.read"), h.Subtree)
+		router.Get("/:id/ancestors", auth.RequirePermission(perm, "admin.organization.read"), h.Ancestors)
+		router.Put("/:id/move", auth.RequirePermission(perm, "admin.organization.update"), h.Move)
 	})
 
 	// ------------------------------------------------------------
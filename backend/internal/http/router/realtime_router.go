@@ -0,0 +1,26 @@
+// Package router provides implementation for router
+//
+// File: realtime_router.go
+// Description: Realtime connection ticket routes
+package router
+
+import (
+	"time"
+
+	"templatev25/internal/auth"
+	"templatev25/internal/http/handlers"
+	"templatev25/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// MapRealtimeRoutes нь WebSocket/SSE холболтын ticket олгох route-ийг бүртгэнэ.
+// Stream route-ууд өөрсдийн router-т (chat_room_router.go, notification_router.go).
+func MapRealtimeRoutes(v1 fiber.Router, requireAuth fiber.Handler, realtimeAuth *auth.RealtimeAuth) {
+	h := handlers.NewRealtimeHandler(realtimeAuth)
+
+	v1.Group("/realtime", requireAuth, middleware.Timeout(5*time.Second)).Route("", func(router fiber.Router) {
+		// Нэг удаагийн, 30 секундын ticket
+		router.Post("/ticket", h.Ticket)
+	})
+}
@@ -32,6 +32,7 @@ Endpoint groups:
 	/room/*              - Video conference rooms
 	/tpay/*              - Terminal payment
 	/chat/*              - Chat items
+	/chat-rooms/*        - Chat rooms, messages, WebSocket
 
 Ашиглалт:
 
@@ -136,9 +137,14 @@ func MapV1(app *fiber.App, d *app.Dependencies) {
 	// AUTH MIDDLEWARE
 	// ============================================================
 	// Protected route-уудад хэрэглэгчийн session-ийг шалгана.
-	// Cookie-д "sid" байвал түүнийг validate хийнэ.
+	// Local (email/password) session эсвэл SSO "sid"-ийн алийг нь ч хүлээн авна.
 	// Session invalid бол 401 Unauthorized буцаана.
-	requireAuth := auth.Require(d.Cfg, d.Log, d.AuthCache)
+	sessions := NewSessionStoreAdapter(d.Service.SessionStore)
+	requireAuth := auth.Authenticate(d.Cfg, d.Log, d.AuthCache, sessions)
+
+	// Realtime (WebSocket/SSE) холболт: browser header тавьж чадахгүй тул
+	// REST-ээр авсан нэг удаагийн ticket-ийг (?ticket=) мөн хүлээн авна.
+	realtimeAuth := auth.NewRealtimeAuth(d.Cfg, d.Log, d.AuthCache, sessions, sessions)
 
 	// ============================================================
 	// V1 API ROUTES
@@ -185,7 +191,7 @@ func MapV1(app *fiber.App, d *app.Dependencies) {
 	// ------------------------------------------------------------
 	// NOTIFICATION ROUTES
 	// ------------------------------------------------------------
-	MapNotificationRoutes(v1, d, requireAuth)
+	MapNotificationRoutes(v1, d, requireAuth, realtimeAuth.Authenticate())
 
 	// ------------------------------------------------------------
 	// NEWS ROUTES
@@ -197,11 +203,26 @@ func MapV1(app *fiber.App, d *app.Dependencies) {
 	// ------------------------------------------------------------
 	MapChatRoutes(v1, d, requireAuth)
 
+	// ------------------------------------------------------------
+	// CHAT ROOM ROUTES (Realtime chat)
+	// ------------------------------------------------------------
+	MapChatRoomRoutes(v1, d, requireAuth, realtimeAuth.Authenticate())
+
+	// ------------------------------------------------------------
+	// REALTIME TICKET ROUTES
+	// ------------------------------------------------------------
+	MapRealtimeRoutes(v1, requireAuth, realtimeAuth)
+
 	// ------------------------------------------------------------
 	// API LOG ROUTES
 	// ------------------------------------------------------------
 	MapAPILogRoutes(v1, d, requireAuth)
 
+	// ------------------------------------------------------------
+	// SCHEDULED JOB ROUTES
+	// ------------------------------------------------------------
+	MapJobRoutes(v1, d, requireAuth)
+
 	// ------------------------------------------------------------
 	// TPAY ROUTES (Terminal Payment)
 	// ------------------------------------------------------------
@@ -32,8 +32,10 @@ func TestRouterPackage(t *testing.T) {
 		"notification_router.go",
 		"me_router.go",
 		"chat_router.go",
+		"chat_room_router.go",
 		"file_router.go",
 		"api_log_router.go",
+		"job_router.go",
 		"app_icon_router.go",
 	}
 
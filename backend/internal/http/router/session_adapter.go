@@ -6,7 +6,9 @@ package router
 
 import (
 	"context"
+	"time"
 
+	"templatev25/internal/auth"
 	"templatev25/internal/middleware"
 	"templatev25/internal/service"
 )
@@ -33,28 +35,54 @@ func (a *SessionStoreAdapter) Get(ctx context.Context, sessionID string) (*middl
 	}
 
 	return &middleware.SessionData{
-		SessionID:      session.SessionID,
-		UserID:         session.UserID,
-		Email:          session.Email,
-		IPAddress:      session.IPAddress,
-		UserAgent:      session.UserAgent,
-		CreatedAt:      session.CreatedAt,
-		ExpiresAt:      session.ExpiresAt,
-		LastActivityAt: session.LastActivityAt,
+		SessionID:       session.SessionID,
+		UserID:          session.UserID,
+		Email:           session.Email,
+		IPAddress:       session.IPAddress,
+		UserAgent:       session.UserAgent,
+		CreatedAt:       session.CreatedAt,
+		ExpiresAt:       session.ExpiresAt,
+		LastActivityAt:  session.LastActivityAt,
+		OrgID:           session.OrgID,
+		HasRefreshToken: session.HasRefreshToken,
 	}, nil
 }
 
 // Update converts middleware.SessionData back to service.SessionData and updates
 func (a *SessionStoreAdapter) Update(ctx context.Context, session *middleware.SessionData) error {
 	serviceSession := &service.SessionData{
-		SessionID:      session.SessionID,
-		UserID:         session.UserID,
-		Email:          session.Email,
-		IPAddress:      session.IPAddress,
-		UserAgent:      session.UserAgent,
-		CreatedAt:      session.CreatedAt,
-		ExpiresAt:      session.ExpiresAt,
-		LastActivityAt: session.LastActivityAt,
+		SessionID:       session.SessionID,
+		UserID:          session.UserID,
+		Email:           session.Email,
+		IPAddress:       session.IPAddress,
+		UserAgent:       session.UserAgent,
+		CreatedAt:       session.CreatedAt,
+		ExpiresAt:       session.ExpiresAt,
+		LastActivityAt:  session.LastActivityAt,
+		OrgID:           session.OrgID,
+		HasRefreshToken: session.HasRefreshToken,
 	}
 	return a.store.Update(ctx, serviceSession)
 }
+
+// Touch records activity on the session
+func (a *SessionStoreAdapter) Touch(ctx context.Context, sessionID string, at time.Time) error {
+	return a.store.Touch(ctx, sessionID, at)
+}
+
+// StoreRealtimeTicket stores a realtime connection ticket
+func (a *SessionStoreAdapter) StoreRealtimeTicket(ctx context.Context, ticket string, data *auth.RealtimeTicket, ttl time.Duration) error {
+	return a.store.StoreRealtimeTicket(ctx, ticket, &service.RealtimeTicketData{
+		SessionID: data.SessionID,
+		Local:     data.Local,
+	}, ttl)
+}
+
+// TakeRealtimeTicket retrieves and deletes a realtime connection ticket
+func (a *SessionStoreAdapter) TakeRealtimeTicket(ctx context.Context, ticket string) (*auth.RealtimeTicket, error) {
+	data, err := a.store.TakeRealtimeTicket(ctx, ticket)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return &auth.RealtimeTicket{SessionID: data.SessionID, Local: data.Local}, nil
+}
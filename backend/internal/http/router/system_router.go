@@ -62,7 +62,10 @@ func MapSystemRoutes(v1 fiber.Router, d *app.Dependencies, requireAuth fiber.Han
 
 		// CRUD operations with permission checks
 		router.Get("/", auth.RequirePermission(perm, "admin.permission.read"), h.List)
+		// GET /permission/explain?user_id=7&code=admin.user.read&org_id=3 → Why access is granted/denied
+		router.Get("/explain", auth.RequirePermission(perm, "admin.permission.read"), h.Explain)
 		router.Post("/", auth.RequirePermission(perm, "admin.permission.create"), h.Create)
+		router.Post("/wildcard", auth.RequirePermission(perm, "admin.permission.create"), h.CreateWildcard)
 		router.Put("/:id", auth.RequirePermission(perm, "admin.permission.update"), h.Update)
 		router.Delete("/:id", auth.RequirePermission(perm, "admin.permission.delete"), h.Delete)
 	})
@@ -96,8 +99,10 @@ func MapSystemRoutes(v1 fiber.Router, d *app.Dependencies, requireAuth fiber.Han
 
 		// Permission management with permission checks
 		// GET  /role/permissions?role_id=1 → Role's permissions
+		// GET  /role/permissions/effective?role_id=1 → + permissions covered by wildcard grants
 		// POST /role/permissions {role_id, permission_ids} → Set permissions
 		router.Get("/permissions", auth.RequirePermission(perm, "admin.role.read"), role.GetRolePermissions)
+		router.Get("/permissions/effective", auth.RequirePermission(perm, "admin.role.read"), role.GetRoleEffectivePermissions)
 		router.Post("/permissions", auth.RequirePermission(perm, "admin.role.update"), role.SetRolePermissions)
 	})
 
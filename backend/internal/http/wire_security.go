@@ -27,6 +27,14 @@ import (
 	fbrequestid "github.com/gofiber/fiber/v2/middleware/requestid"
 )
 
+// tus resumable upload headers (/uploads) that browsers must be allowed to
+// send and read across origins.
+const (
+	tusRequestHeaders  = "Tus-Resumable,Upload-Length,Upload-Offset,Upload-Metadata,Upload-Checksum,Upload-Defer-Length"
+	tusResponseHeaders = "Location,Tus-Resumable,Tus-Version,Tus-Extension,Tus-Max-Size,Tus-Checksum-Algorithm," +
+		"Upload-Offset,Upload-Length,Upload-Metadata,Upload-Expires,X-File-Url"
+)
+
 // ApplyMiddlewares wires common middlewares.
 func ApplyMiddlewares(app *fiber.App, cfg *config.Config, logg *zap.Logger, apiLogRepo ...interface{}) {
 	var repo interface{}
@@ -60,8 +68,9 @@ func ApplyMiddlewares(app *fiber.App, cfg *config.Config, logg *zap.Logger, apiL
 	// CORS (cookie-compatible)
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     cfg.CORS.AllowOrigins,
-		AllowMethods:     "GET,POST,PUT,PATCH,DELETE,OPTIONS",
-		AllowHeaders:     "Content-Type,Authorization,X-CSRF-Token",
+		AllowMethods:     "GET,HEAD,POST,PUT,PATCH,DELETE,OPTIONS",
+		AllowHeaders:     "Content-Type,Authorization,X-CSRF-Token," + tusRequestHeaders,
+		ExposeHeaders:    tusResponseHeaders,
 		AllowCredentials: cfg.CORS.AllowCredentials,
 	}))
 
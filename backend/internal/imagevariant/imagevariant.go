@@ -0,0 +1,381 @@
+// Package imagevariant generates resized and WebP variants of stored images
+//
+// File: imagevariant.go
+// Description: Variant specs, storage keys and the generator
+//
+// This package provides:
+//   - Spec: a named target box ("thumb=150x150:crop")
+//   - Generator: renders variants from an original in storage and stores
+//     them next to it, on demand or right after upload
+//
+// Variants of "3f2a.jpg" are stored as "3f2a_thumb.jpg", "3f2a_thumb.webp"
+// and, for a WebP copy at the original size, "3f2a.webp".
+//
+// The WebP encoder is lossless, so for photos it often loses to JPEG. A WebP
+// key then holds the original-format encoding instead, and Open reports its
+// real content type.
+//
+// Usage:
+//
+//	g := imagevariant.NewGenerator(store, imagevariant.Options{Variants: specs, WebP: true})
+//	obj, err := g.Open(ctx, "3f2a.jpg", "thumb", imagevariant.FormatWebP)
+//	defer obj.Body.Close()
+package imagevariant
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"templatev25/internal/storage"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Output formats. FormatOriginal keeps the original's encoding (GIF
+// variants are PNG since only the first frame is kept).
+const (
+	FormatOriginal = ""
+	FormatWebP     = "webp"
+)
+
+// Common errors. Every one of them also matches ErrUnavailable, which the
+// handler maps to 400.
+var (
+	ErrUnavailable       = errors.New("imagevariant: variant unavailable")
+	ErrUnknownVariant    = fmt.Errorf("%w: unknown variant", ErrUnavailable)
+	ErrUnsupportedFormat = fmt.Errorf("%w: unsupported format", ErrUnavailable)
+	ErrNotImage          = fmt.Errorf("%w: file is not a resizable image", ErrUnavailable)
+	ErrTooManyPixels     = fmt.Errorf("%w: image exceeds the pixel limit", ErrUnavailable)
+)
+
+// imageExtensions are the originals the generator can decode
+var imageExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".jfif": true, ".gif": true, ".webp": true,
+}
+
+var specName = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+// Spec is a named variant. The image is scaled down to fit Width x Height;
+// with Crop it fills the box and the overflow is cut off centered. A zero
+// Width or Height leaves that side unconstrained (not allowed with Crop).
+// Images are never scaled up.
+type Spec struct {
+	Name   string
+	Width  int
+	Height int
+	Crop   bool
+}
+
+// ParseSpecs parses entries like "thumb=150x150:crop" or "wide=1200x0"
+func ParseSpecs(entries []string) ([]Spec, error) {
+	var specs []Spec
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, box, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !specName.MatchString(name) {
+			return nil, fmt.Errorf("imagevariant: %q: expected name=WxH[:crop]", entry)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("imagevariant: duplicate variant %q", name)
+		}
+		box, mode, _ := strings.Cut(strings.TrimSpace(box), ":")
+		ws, hs, ok := strings.Cut(box, "x")
+		w, werr := strconv.Atoi(ws)
+		h, herr := strconv.Atoi(hs)
+		if !ok || werr != nil || herr != nil || w < 0 || h < 0 || w+h == 0 {
+			return nil, fmt.Errorf("imagevariant: %q: invalid size %q", entry, box)
+		}
+		spec := Spec{Name: name, Width: w, Height: h}
+		switch mode {
+		case "":
+		case "crop":
+			if w == 0 || h == 0 {
+				return nil, fmt.Errorf("imagevariant: %q: crop needs both sides", entry)
+			}
+			spec.Crop = true
+		default:
+			return nil, fmt.Errorf("imagevariant: %q: unknown mode %q", entry, mode)
+		}
+		seen[name] = true
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// Options configures a Generator
+type Options struct {
+	Variants []Spec
+
+	// WebP enables FormatWebP
+	WebP bool
+
+	// Eager asks callers to render all variants right after upload
+	// instead of on the first request
+	Eager bool
+
+	// JPEGQuality is used for JPEG variants (1-100, default 85)
+	JPEGQuality int
+
+	// MaxPixels refuses originals with more pixels, since decoding needs
+	// about 4 bytes per pixel (0 = no limit)
+	MaxPixels int64
+}
+
+// Generator renders variants and stores them next to the original
+type Generator struct {
+	store storage.Storage
+	opts  Options
+	specs map[string]Spec
+	group singleflight.Group
+}
+
+// NewGenerator creates a generator that reads originals from and writes
+// variants to store
+func NewGenerator(store storage.Storage, opts Options) *Generator {
+	if opts.JPEGQuality <= 0 || opts.JPEGQuality > 100 {
+		opts.JPEGQuality = 85
+	}
+	specs := make(map[string]Spec, len(opts.Variants))
+	for _, s := range opts.Variants {
+		specs[s.Name] = s
+	}
+	return &Generator{store: store, opts: opts, specs: specs}
+}
+
+// Eager reports whether variants should be rendered at upload time
+func (g *Generator) Eager() bool { return g.opts.Eager }
+
+// IsImage reports whether key names an image the generator can decode
+func IsImage(key string) bool {
+	return imageExtensions[strings.ToLower(path.Ext(key))]
+}
+
+// Key returns the storage key of a variant of original. An empty variant
+// with FormatOriginal is the original itself.
+func (g *Generator) Key(original, variant, format string) (string, error) {
+	key, _, err := g.resolve(original, variant, format)
+	return key, err
+}
+
+// resolve validates a request and returns the variant key and spec
+func (g *Generator) resolve(original, variant, format string) (string, Spec, error) {
+	format = strings.ToLower(format)
+	if format != FormatOriginal && (format != FormatWebP || !g.opts.WebP) {
+		return "", Spec{}, ErrUnsupportedFormat
+	}
+	var spec Spec
+	if variant != "" {
+		s, ok := g.specs[variant]
+		if !ok {
+			return "", Spec{}, ErrUnknownVariant
+		}
+		spec = s
+	}
+	if variant == "" && format == FormatOriginal {
+		return original, spec, nil
+	}
+	if !IsImage(original) {
+		return "", Spec{}, ErrNotImage
+	}
+
+	ext := path.Ext(original)
+	base := strings.TrimSuffix(original, ext)
+	// Variants are not originals; refusing them stops "a_thumb_thumb..."
+	// chains from filling the storage
+	for name := range g.specs {
+		if strings.HasSuffix(base, "_"+name) {
+			return "", Spec{}, ErrNotImage
+		}
+	}
+
+	outExt := outputExt(ext, format)
+	if variant == "" {
+		if strings.EqualFold(outExt, ext) {
+			return original, spec, nil
+		}
+		return base + outExt, spec, nil
+	}
+	return base + "_" + variant + outExt, spec, nil
+}
+
+// Open returns a variant of original, rendering and storing it on the first
+// request. The caller must close Body. A missing original is
+// storage.ErrNotFound.
+func (g *Generator) Open(ctx context.Context, original, variant, format string) (*storage.Object, error) {
+	key, spec, err := g.resolve(original, variant, format)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := g.store.Open(ctx, key)
+	if key == original {
+		return obj, err
+	}
+	if err == nil && format == FormatWebP {
+		return sniff(obj)
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return obj, err
+	}
+
+	// Concurrent requests for the same variant render it once. The render
+	// is not tied to the first caller, so its cancellation does not fail
+	// the others.
+	_, err, _ = g.group.Do(key, func() (any, error) {
+		img, err := g.load(context.WithoutCancel(ctx), original)
+		if err != nil {
+			return nil, err
+		}
+		return nil, g.put(context.WithoutCancel(ctx), img, key, spec, format)
+	})
+	if err != nil {
+		return nil, err
+	}
+	obj, err = g.store.Open(ctx, key)
+	if err != nil || format != FormatWebP {
+		return obj, err
+	}
+	return sniff(obj)
+}
+
+// Generate renders every variant of original (and the WebP copies when
+// enabled), decoding it only once. Non-image keys are ignored.
+func (g *Generator) Generate(ctx context.Context, original string) error {
+	if !IsImage(original) {
+		return nil
+	}
+	img, err := g.load(ctx, original)
+	if err != nil {
+		return err
+	}
+	for _, r := range g.requests() {
+		key, spec, err := g.resolve(original, r.variant, r.format)
+		if err != nil {
+			return err
+		}
+		if key == original {
+			continue
+		}
+		if err := g.put(ctx, img, key, spec, r.format); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteAll removes every variant of original (not the original itself)
+func (g *Generator) DeleteAll(ctx context.Context, original string) error {
+	if !IsImage(original) {
+		return nil
+	}
+	for _, r := range g.requests() {
+		key, _, err := g.resolve(original, r.variant, r.format)
+		if err != nil || key == original {
+			continue
+		}
+		if err := g.store.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type request struct{ variant, format string }
+
+// requests lists every variant/format combination the generator serves
+func (g *Generator) requests() []request {
+	formats := []string{FormatOriginal}
+	if g.opts.WebP {
+		formats = append(formats, FormatWebP)
+	}
+	var out []request
+	for _, f := range formats {
+		if f != FormatOriginal {
+			out = append(out, request{format: f})
+		}
+		for _, s := range g.opts.Variants {
+			out = append(out, request{variant: s.Name, format: f})
+		}
+	}
+	return out
+}
+
+// put renders img for spec and stores it under key. A WebP that is not
+// smaller than the original-format encoding is replaced by that encoding.
+func (g *Generator) put(ctx context.Context, img *decoded, key string, spec Spec, format string) error {
+	resized := resize(img.image, spec)
+	var buf bytes.Buffer
+	contentType, err := encode(&buf, resized, img.format, format, g.opts.JPEGQuality)
+	if err != nil {
+		return fmt.Errorf("imagevariant: encode %s: %w", key, err)
+	}
+	if format == FormatWebP && img.format != "webp" {
+		fallback, fallbackType, err := g.fallback(img, resized)
+		if err != nil {
+			return fmt.Errorf("imagevariant: encode %s: %w", key, err)
+		}
+		if buf.Len() >= len(fallback) {
+			buf.Reset()
+			buf.Write(fallback)
+			contentType = fallbackType
+		}
+	}
+	if err := g.store.Put(ctx, key, &buf, int64(buf.Len()), contentType); err != nil {
+		return fmt.Errorf("imagevariant: store %s: %w", key, err)
+	}
+	return nil
+}
+
+// fallback returns what a WebP variant competes with: the original file
+// when resized is the original image, otherwise resized in the original's
+// format
+func (g *Generator) fallback(img *decoded, resized image.Image) ([]byte, string, error) {
+	if resized == img.image {
+		return img.data, http.DetectContentType(img.data), nil
+	}
+	var buf bytes.Buffer
+	contentType, err := encode(&buf, resized, img.format, FormatOriginal, g.opts.JPEGQuality)
+	return buf.Bytes(), contentType, err
+}
+
+// sniff sets the content type of a WebP key from its first bytes, since
+// it may hold the fallback encoding and storage guesses from the extension
+func sniff(obj *storage.Object) (*storage.Object, error) {
+	r := bufio.NewReaderSize(obj.Body, 512)
+	head, err := r.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) {
+		obj.Body.Close()
+		return nil, err
+	}
+	obj.ContentType = http.DetectContentType(head)
+	obj.Body = struct {
+		io.Reader
+		io.Closer
+	}{r, obj.Body}
+	return obj, nil
+}
+
+// outputExt is the extension of a variant of an original with ext
+func outputExt(ext, format string) string {
+	if format == FormatWebP {
+		return ".webp"
+	}
+	if strings.EqualFold(ext, ".gif") {
+		return ".png"
+	}
+	return strings.ToLower(ext)
+}
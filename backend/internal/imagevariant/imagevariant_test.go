@@ -0,0 +1,335 @@
+// Package imagevariant generates resized and WebP variants of stored images
+//
+// File: imagevariant_test.go
+// Description: Unit tests for specs, resizing, the WebP encoder and the generator
+package imagevariant
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"templatev25/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
+)
+
+func TestParseSpecs(t *testing.T) {
+	specs, err := ParseSpecs([]string{"thumb=150x150:crop", " wide = 1200x0 ", ""})
+	require.NoError(t, err)
+	assert.Equal(t, []Spec{
+		{Name: "thumb", Width: 150, Height: 150, Crop: true},
+		{Name: "wide", Width: 1200},
+	}, specs)
+
+	for _, bad := range []string{"thumb", "Thumb=1x1", "a=0x0", "a=10", "a=10x0:crop", "a=1x1:fit", "a=1x1,a=2x2"} {
+		_, err := ParseSpecs(strings.Split(bad, ","))
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestResize(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 400, 200))
+
+	tests := []struct {
+		name string
+		spec Spec
+		w, h int
+	}{
+		{"fit", Spec{Width: 100, Height: 100}, 100, 50},
+		{"fit width only", Spec{Width: 200}, 200, 100},
+		{"crop", Spec{Width: 100, Height: 100, Crop: true}, 100, 100},
+		{"no upscale", Spec{Width: 1000, Height: 1000}, 400, 200},
+		{"crop without upscale", Spec{Width: 1000, Height: 1000, Crop: true}, 200, 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := resize(img, tt.spec).Bounds()
+			assert.Equal(t, tt.w, b.Dx())
+			assert.Equal(t, tt.h, b.Dy())
+		})
+	}
+}
+
+func TestEncodeWebP_RoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	noise := image.NewNRGBA(image.Rect(0, 0, 37, 23))
+	rng.Read(noise.Pix)
+
+	gradient := image.NewNRGBA(image.Rect(0, 0, 64, 48))
+	for y := 0; y < 48; y++ {
+		for x := 0; x < 64; x++ {
+			gradient.SetNRGBA(x, y, color.NRGBA{uint8(x * 4), uint8(y * 5), uint8(x + y), 0xff})
+		}
+	}
+
+	solid := image.NewNRGBA(image.Rect(0, 0, 20, 20))
+	for i := range solid.Pix {
+		solid.Pix[i] = 0x80
+	}
+
+	// Sub-image with an offset and non-packed stride
+	sub := gradient.SubImage(image.Rect(5, 7, 30, 40))
+
+	// Two colors only, to exercise the simple prefix codes
+	stripes := image.NewNRGBA(image.Rect(0, 0, 33, 17))
+	for y := 0; y < 17; y++ {
+		for x := 0; x < 33; x++ {
+			c := color.NRGBA{0, 0, 0, 0xff}
+			if x%2 == 0 {
+				c = color.NRGBA{0xff, 0xff, 0xff, 0xff}
+			}
+			stripes.SetNRGBA(x, y, c)
+		}
+	}
+
+	for name, img := range map[string]image.Image{
+		"noise":    noise,
+		"gradient": gradient,
+		"solid":    solid,
+		"sub":      sub,
+		"stripes":  stripes,
+		"1x1":      image.NewNRGBA(image.Rect(0, 0, 1, 1)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, EncodeWebP(&buf, img))
+
+			got, err := webp.Decode(bytes.NewReader(buf.Bytes()))
+			require.NoError(t, err)
+			b := img.Bounds()
+			require.Equal(t, b.Dx(), got.Bounds().Dx())
+			require.Equal(t, b.Dy(), got.Bounds().Dy())
+			for y := 0; y < b.Dy(); y++ {
+				for x := 0; x < b.Dx(); x++ {
+					want := color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y))
+					require.Equal(t, want, color.NRGBAModel.Convert(got.At(x, y)), "pixel %d,%d", x, y)
+				}
+			}
+		})
+	}
+}
+
+func TestHuffmanLengths_Limited(t *testing.T) {
+	// Fibonacci counts give the deepest possible tree
+	hist := make([]uint32, 30)
+	a, b := uint32(1), uint32(1)
+	for i := range hist {
+		hist[i] = a
+		a, b = b, a+b
+	}
+	lengths := huffmanLengths(hist, 15)
+
+	kraft := 0.0
+	for _, l := range lengths {
+		require.LessOrEqual(t, l, uint8(15))
+		kraft += 1 / float64(uint(1)<<l)
+	}
+	assert.InDelta(t, 1.0, kraft, 1e-9, "code is complete")
+}
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = byte(i)
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func newTestGenerator(t *testing.T, opts Options) (*Generator, storage.Storage) {
+	t.Helper()
+	store, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	if opts.Variants == nil {
+		opts.Variants = []Spec{{Name: "thumb", Width: 50, Height: 50, Crop: true}, {Name: "small", Width: 100, Height: 100}}
+	}
+	opts.WebP = true
+	return NewGenerator(store, opts), store
+}
+
+func putBytes(t *testing.T, store storage.Storage, key string, data []byte) {
+	t.Helper()
+	require.NoError(t, store.Put(context.Background(), key, bytes.NewReader(data), int64(len(data)), ""))
+}
+
+func decodeObject(t *testing.T, obj *storage.Object) image.Image {
+	t.Helper()
+	defer obj.Body.Close()
+	img, _, err := image.Decode(obj.Body)
+	require.NoError(t, err)
+	return img
+}
+
+func TestGenerator_Open(t *testing.T) {
+	g, store := newTestGenerator(t, Options{})
+	ctx := context.Background()
+	putBytes(t, store, "a.png", encodePNG(t, 400, 200))
+
+	obj, err := g.Open(ctx, "a.png", "small", FormatOriginal)
+	require.NoError(t, err)
+	assert.Equal(t, "a_small.png", obj.Key)
+	assert.Equal(t, image.Rect(0, 0, 100, 50), decodeObject(t, obj).Bounds())
+
+	// Stored next to the original for the next request
+	_, err = store.Stat(ctx, "a_small.png")
+	require.NoError(t, err)
+
+	obj, err = g.Open(ctx, "a.png", "thumb", FormatWebP)
+	require.NoError(t, err)
+	assert.Equal(t, "a_thumb.webp", obj.Key)
+	assert.Equal(t, image.Rect(0, 0, 50, 50), decodeObject(t, obj).Bounds())
+
+	obj, err = g.Open(ctx, "a.png", "", FormatOriginal)
+	require.NoError(t, err)
+	assert.Equal(t, "a.png", obj.Key)
+	obj.Body.Close()
+}
+
+func TestGenerator_WebPNotLarger(t *testing.T) {
+	g, store := newTestGenerator(t, Options{})
+	ctx := context.Background()
+
+	// Noisy photo: lossless WebP loses to JPEG, so the JPEG is kept
+	rng := rand.New(rand.NewSource(1))
+	photo := image.NewNRGBA(image.Rect(0, 0, 200, 150))
+	rng.Read(photo.Pix)
+	for i := 3; i < len(photo.Pix); i += 4 {
+		photo.Pix[i] = 0xff
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, photo, &jpeg.Options{Quality: 85}))
+	putBytes(t, store, "photo.jpg", buf.Bytes())
+
+	// Gray noise: the subtract-green transform leaves one channel, so WebP
+	// beats PNG's three
+	gray := image.NewNRGBA(image.Rect(0, 0, 200, 150))
+	for y := 0; y < 150; y++ {
+		for x := 0; x < 200; x++ {
+			v := uint8(rng.Intn(256))
+			gray.SetNRGBA(x, y, color.NRGBA{v, v, v, 0xff})
+		}
+	}
+	buf.Reset()
+	require.NoError(t, png.Encode(&buf, gray))
+	putBytes(t, store, "gray.png", buf.Bytes())
+
+	tests := []struct {
+		original, variant, contentType string
+	}{
+		{"photo.jpg", "", "image/jpeg"},
+		{"photo.jpg", "small", "image/jpeg"},
+		{"gray.png", "", "image/webp"},
+		{"gray.png", "small", "image/webp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.original+"/"+tt.variant, func(t *testing.T) {
+			source, err := g.Open(ctx, tt.original, tt.variant, FormatOriginal)
+			require.NoError(t, err)
+			source.Body.Close()
+
+			obj, err := g.Open(ctx, tt.original, tt.variant, FormatWebP)
+			require.NoError(t, err)
+			assert.Equal(t, tt.contentType, obj.ContentType)
+			assert.LessOrEqual(t, obj.Size, source.Size, "WebP variant is never larger than the source format")
+			decodeObject(t, obj)
+
+			// Served from storage on the next request with the same type
+			obj, err = g.Open(ctx, tt.original, tt.variant, FormatWebP)
+			require.NoError(t, err)
+			obj.Body.Close()
+			assert.Equal(t, tt.contentType, obj.ContentType)
+		})
+	}
+}
+
+func TestGenerator_Errors(t *testing.T) {
+	g, store := newTestGenerator(t, Options{MaxPixels: 100})
+	ctx := context.Background()
+	putBytes(t, store, "big.png", encodePNG(t, 20, 20))
+	putBytes(t, store, "doc.pdf", []byte("%PDF-1.7"))
+	putBytes(t, store, "fake.png", []byte("not an image"))
+
+	_, err := g.Open(ctx, "big.png", "huge", FormatOriginal)
+	assert.ErrorIs(t, err, ErrUnknownVariant)
+	_, err = g.Open(ctx, "big.png", "", "avif")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+	_, err = g.Open(ctx, "doc.pdf", "thumb", FormatOriginal)
+	assert.ErrorIs(t, err, ErrNotImage)
+	_, err = g.Open(ctx, "fake.png", "thumb", FormatOriginal)
+	assert.ErrorIs(t, err, ErrNotImage)
+	_, err = g.Open(ctx, "big.png", "thumb", FormatOriginal)
+	assert.ErrorIs(t, err, ErrTooManyPixels)
+	_, err = g.Open(ctx, "big_thumb.png", "thumb", FormatOriginal)
+	assert.ErrorIs(t, err, ErrNotImage, "variants are not resized again")
+	_, err = g.Open(ctx, "missing.png", "thumb", FormatOriginal)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	for _, err := range []error{ErrUnknownVariant, ErrUnsupportedFormat, ErrNotImage, ErrTooManyPixels} {
+		assert.ErrorIs(t, err, ErrUnavailable)
+	}
+}
+
+func TestGenerator_GenerateAndDeleteAll(t *testing.T) {
+	g, store := newTestGenerator(t, Options{})
+	ctx := context.Background()
+	img := image.NewPaletted(image.Rect(0, 0, 120, 80), palette.Plan9)
+	var buf bytes.Buffer
+	require.NoError(t, gif.Encode(&buf, img, nil))
+	putBytes(t, store, "a.gif", buf.Bytes())
+
+	require.NoError(t, g.Generate(ctx, "a.gif"))
+	keys := []string{"a.webp", "a_thumb.png", "a_small.png", "a_thumb.webp", "a_small.webp"}
+	for _, key := range keys {
+		_, err := store.Stat(ctx, key)
+		assert.NoError(t, err, key)
+	}
+
+	require.NoError(t, g.DeleteAll(ctx, "a.gif"))
+	for _, key := range keys {
+		_, err := store.Stat(ctx, key)
+		assert.ErrorIs(t, err, storage.ErrNotFound, key)
+	}
+	_, err := store.Stat(ctx, "a.gif")
+	assert.NoError(t, err, "original is kept")
+
+	assert.NoError(t, g.Generate(ctx, "doc.pdf"), "non-images are skipped")
+}
+
+func TestExifOrientation(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	plain := buf.Bytes()
+	assert.Equal(t, 1, exifOrientation(plain))
+
+	// APP1 "Exif" segment with a big-endian IFD holding Orientation = 6
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01\x01\x12\x00\x03\x00\x00\x00\x01\x00\x06\x00\x00\x00\x00\x00\x00")
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	seg := []byte{0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	rotated := append(append(append([]byte{}, plain[:2]...), append(seg, payload...)...), plain[2:]...)
+	assert.Equal(t, 6, exifOrientation(rotated))
+
+	g, store := newTestGenerator(t, Options{})
+	putBytes(t, store, "p.jpg", rotated)
+	obj, err := g.Open(context.Background(), "p.jpg", "", FormatWebP)
+	require.NoError(t, err)
+	defer obj.Body.Close()
+	out, err := webp.Decode(obj.Body)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 20, 40), out.Bounds(), "rotated upright")
+}
@@ -0,0 +1,252 @@
+// Package imagevariant generates resized and WebP variants of stored images
+//
+// File: render.go
+// Description: Decoding, EXIF orientation, resizing and encoding
+package imagevariant
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+// decoded is an original ready for resizing
+type decoded struct {
+	image image.Image
+	// format is "png", "jpeg", "gif" or "webp"
+	format string
+	// data is the original file, the fallback for a full-size WebP copy
+	data []byte
+}
+
+// load reads and decodes original, applying its EXIF orientation
+func (g *Generator) load(ctx context.Context, original string) (*decoded, error) {
+	obj, err := g.store.Open(ctx, original)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Body.Close()
+	data, err := io.ReadAll(obj.Body)
+	if err != nil {
+		return nil, fmt.Errorf("imagevariant: read %s: %w", original, err)
+	}
+
+	cfg, format, err := decodeConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotImage, err)
+	}
+	if g.opts.MaxPixels > 0 && int64(cfg.Width)*int64(cfg.Height) > g.opts.MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+	img, err := decodeImage(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotImage, err)
+	}
+	if format == "jpeg" {
+		img = orient(img, exifOrientation(data))
+	}
+	return &decoded{image: img, format: format, data: data}, nil
+}
+
+// decodeConfig reads the dimensions without decoding the pixels. Only the
+// formats below are tried, whatever else is registered with package image.
+func decodeConfig(data []byte) (image.Config, string, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG")):
+		cfg, err := png.DecodeConfig(bytes.NewReader(data))
+		return cfg, "png", err
+	case bytes.HasPrefix(data, []byte("\xff\xd8")):
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+		return cfg, "jpeg", err
+	case bytes.HasPrefix(data, []byte("GIF8")):
+		cfg, err := gif.DecodeConfig(bytes.NewReader(data))
+		return cfg, "gif", err
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		cfg, err := webp.DecodeConfig(bytes.NewReader(data))
+		return cfg, "webp", err
+	}
+	return image.Config{}, "", fmt.Errorf("unknown image format")
+}
+
+func decodeImage(data []byte, format string) (image.Image, error) {
+	r := bytes.NewReader(data)
+	switch format {
+	case "png":
+		return png.Decode(r)
+	case "jpeg":
+		return jpeg.Decode(r)
+	case "gif":
+		return gif.Decode(r)
+	default:
+		return webp.Decode(r)
+	}
+}
+
+// encode writes img in the requested format and returns its content type
+func encode(w io.Writer, img image.Image, sourceFormat, format string, jpegQuality int) (string, error) {
+	switch {
+	case format == FormatWebP || sourceFormat == "webp":
+		return "image/webp", EncodeWebP(w, img)
+	case sourceFormat == "jpeg":
+		return "image/jpeg", jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+	default:
+		enc := png.Encoder{CompressionLevel: png.BestCompression}
+		return "image/png", enc.Encode(w, img)
+	}
+}
+
+// resize scales img down for spec. An empty spec or a box larger than the
+// image returns the image unchanged.
+func resize(img image.Image, spec Spec) image.Image {
+	b := img.Bounds()
+	sw, sh := float64(b.Dx()), float64(b.Dy())
+	if spec.Width == 0 && spec.Height == 0 {
+		return img
+	}
+
+	src := b
+	var dw, dh int
+	if spec.Crop {
+		bw, bh := float64(spec.Width), float64(spec.Height)
+		// Without upscaling, a small image is cropped to the box's aspect
+		// ratio at its own size
+		if k := math.Min(sw/bw, sh/bh); k < 1 {
+			bw, bh = bw*k, bh*k
+		}
+		scale := math.Max(bw/sw, bh/sh)
+		cw, ch := int(math.Round(bw/scale)), int(math.Round(bh/scale))
+		x0, y0 := b.Min.X+(b.Dx()-cw)/2, b.Min.Y+(b.Dy()-ch)/2
+		src = image.Rect(x0, y0, x0+cw, y0+ch)
+		dw, dh = max(int(math.Round(bw)), 1), max(int(math.Round(bh)), 1)
+	} else {
+		scale := 1.0
+		if spec.Width > 0 {
+			scale = math.Min(scale, float64(spec.Width)/sw)
+		}
+		if spec.Height > 0 {
+			scale = math.Min(scale, float64(spec.Height)/sh)
+		}
+		if scale >= 1 {
+			return img
+		}
+		dw, dh = max(int(math.Round(sw*scale)), 1), max(int(math.Round(sh*scale)), 1)
+	}
+
+	if src == b && dw == b.Dx() && dh == b.Dy() {
+		return img
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, src, draw.Src, nil)
+	return dst
+}
+
+// orient applies an EXIF orientation (2-8) so the pixels are upright
+func orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	src := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored
+				sx, sy = w-1-x, y
+			case 3: // rotated 180
+				sx, sy = w-1-x, h-1-y
+			case 4: // flipped vertically
+				sx, sy = x, h-1-y
+			case 5: // transposed
+				sx, sy = y, x
+			case 6: // needs 90 clockwise
+				sx, sy = y, h-1-x
+			case 7: // transversed
+				sx, sy = w-1-y, h-1-x
+			case 8: // needs 90 counter-clockwise
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
+
+// exifOrientation returns the orientation tag of a JPEG's EXIF block, or 1
+func exifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return 1
+	}
+	for p := 2; p+4 <= len(data); {
+		if data[p] != 0xff {
+			return 1
+		}
+		marker := data[p+1]
+		if marker == 0xda || marker == 0xd9 { // image data starts
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(data[p+2:]))
+		if size < 2 || p+2+size > len(data) {
+			return 1
+		}
+		segment := data[p+4 : p+2+size]
+		if marker == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		p += 2 + size
+	}
+	return 1
+}
+
+// tiffOrientation reads tag 0x0112 from the first IFD of a TIFF header
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	n := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < n; i++ {
+		e := ifd + 2 + 12*i
+		if e+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[e:]) == 0x0112 {
+			v := int(order.Uint16(tiff[e+8:]))
+			if v < 1 || v > 8 {
+				return 1
+			}
+			return v
+		}
+	}
+	return 1
+}
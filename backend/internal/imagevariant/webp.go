@@ -0,0 +1,520 @@
+// Package imagevariant generates resized and WebP variants of stored images
+//
+// File: webp.go
+// Description: Lossless WebP (VP8L) encoder
+//
+// The encoder uses the subtract-green and predictor transforms followed by
+// one set of Huffman codes for the whole image. It does not emit backward
+// references or a color cache, so files are larger than libwebp's, but it
+// needs no cgo and every WebP decoder reads the output.
+package imagevariant
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"io"
+)
+
+const (
+	// webpMaxDimension is the 14-bit width/height limit of VP8L
+	webpMaxDimension = 1 << 14
+
+	// predictorBits is the log2 tile size of the predictor transform
+	predictorBits = 4
+
+	// Huffman code length limits from the VP8L spec
+	maxCodeLength       = 15
+	maxCodeLengthLength = 7
+)
+
+// VP8L transform types
+const (
+	transformPredictor     = 0
+	transformSubtractGreen = 2
+)
+
+// Predictor modes tried per tile. Only modes that do not look at the
+// top-right pixel are used, which keeps the edge rules simple.
+const (
+	predictLeft   = 1
+	predictTop    = 2
+	predictSelect = 11
+	predictClamp  = 12
+)
+
+var predictorModes = []int{predictLeft, predictTop, predictSelect, predictClamp}
+
+// alphabet sizes of the five prefix codes (green includes the 24 length
+// codes, then red, blue, alpha and distance)
+var alphabetSizes = [5]int{256 + 24, 256, 256, 256, 40}
+
+// codeLengthCodeOrder is the order code length code lengths are written in
+var codeLengthCodeOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// EncodeWebP writes img as a lossless WebP file
+func EncodeWebP(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width <= 0 || height <= 0 || width > webpMaxDimension || height > webpMaxDimension {
+		return errors.New("imagevariant: webp: invalid image size")
+	}
+
+	// pix holds R, G, B, A bytes in row order, not premultiplied
+	src, ok := img.(*image.NRGBA)
+	if !ok || src.Stride != 4*width {
+		src = image.NewNRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	}
+	pix := make([]byte, len(src.Pix))
+	copy(pix, src.Pix)
+
+	hasAlpha := false
+	for i := 3; i < len(pix); i += 4 {
+		if pix[i] != 0xff {
+			hasAlpha = true
+			break
+		}
+	}
+
+	bw := &bitWriter{}
+	bw.write(0x2f, 8)
+	bw.write(uint32(width-1), 14)
+	bw.write(uint32(height-1), 14)
+	if hasAlpha {
+		bw.write(1, 1)
+	} else {
+		bw.write(0, 1)
+	}
+	bw.write(0, 3) // version
+
+	// Transforms are listed in the order they are applied; the decoder
+	// undoes them in reverse
+	subtractGreen(pix)
+	bw.write(1, 1)
+	bw.write(transformSubtractGreen, 2)
+
+	modes := predict(pix, width, height)
+	bw.write(1, 1)
+	bw.write(transformPredictor, 2)
+	bw.write(predictorBits-2, 3)
+	encodeImage(bw, modes, false)
+
+	bw.write(0, 1) // no more transforms
+	encodeImage(bw, pix, true)
+
+	data := bw.bytes()
+	chunk := len(data) + len(data)&1
+	header := make([]byte, 20)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(4+8+chunk))
+	copy(header[8:], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(header[16:], uint32(len(data)))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if len(data)&1 == 1 {
+		data = append(data, 0)
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// subtractGreen replaces red and blue with their difference to green
+func subtractGreen(pix []byte) {
+	for p := 0; p < len(pix); p += 4 {
+		pix[p+0] -= pix[p+1]
+		pix[p+2] -= pix[p+1]
+	}
+}
+
+// predict picks a predictor mode per tile, replaces pix with the residuals
+// and returns the tile image (mode in green) for the transform header
+func predict(pix []byte, width, height int) []byte {
+	tilesX := (width + 1<<predictorBits - 1) >> predictorBits
+	tilesY := (height + 1<<predictorBits - 1) >> predictorBits
+	tiles := make([]byte, 4*tilesX*tilesY)
+
+	for ty := 0; ty < tilesY; ty++ {
+		for tx := 0; tx < tilesX; tx++ {
+			best, bestCost := predictLeft, -1
+			for _, mode := range predictorModes {
+				cost := 0
+				forTile(tx, ty, width, height, func(x, y int) {
+					var pred [4]byte
+					predictPixel(pix, width, x, y, mode, &pred)
+					p := 4 * (y*width + x)
+					for c := 0; c < 4; c++ {
+						cost += absInt(int(int8(pix[p+c] - pred[c])))
+					}
+				})
+				if bestCost < 0 || cost < bestCost {
+					best, bestCost = mode, cost
+				}
+			}
+			t := 4 * (ty*tilesX + tx)
+			tiles[t+1] = byte(best)
+			tiles[t+3] = 0xff
+		}
+	}
+
+	// Residuals are computed against the original neighbours, which the
+	// decoder has already reconstructed when it reaches each pixel
+	res := make([]byte, len(pix))
+	var pred [4]byte
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			mode := int(tiles[4*((y>>predictorBits)*tilesX+(x>>predictorBits))+1])
+			predictPixel(pix, width, x, y, mode, &pred)
+			p := 4 * (y*width + x)
+			for c := 0; c < 4; c++ {
+				res[p+c] = pix[p+c] - pred[c]
+			}
+		}
+	}
+	copy(pix, res)
+	return tiles
+}
+
+// forTile calls fn for every pixel of a tile that uses the tile's mode
+// (the first row and column have fixed predictors)
+func forTile(tx, ty, width, height int, fn func(x, y int)) {
+	x0, y0 := tx<<predictorBits, ty<<predictorBits
+	for y := max(y0, 1); y < min(y0+1<<predictorBits, height); y++ {
+		for x := max(x0, 1); x < min(x0+1<<predictorBits, width); x++ {
+			fn(x, y)
+		}
+	}
+}
+
+// predictPixel computes the prediction for (x, y) following the VP8L edge
+// rules: opaque black for the first pixel, left on the first row and top
+// on the first column
+func predictPixel(pix []byte, width, x, y, mode int, pred *[4]byte) {
+	p := 4 * (y*width + x)
+	switch {
+	case x == 0 && y == 0:
+		*pred = [4]byte{0, 0, 0, 0xff}
+		return
+	case y == 0:
+		mode = predictLeft
+	case x == 0:
+		mode = predictTop
+	}
+	l, t := p-4, p-4*width
+	tl := t - 4
+	switch mode {
+	case predictLeft:
+		copy(pred[:], pix[l:l+4])
+	case predictTop:
+		copy(pred[:], pix[t:t+4])
+	case predictSelect:
+		dl, dt := 0, 0
+		for c := 0; c < 4; c++ {
+			dl += absInt(int(pix[tl+c]) - int(pix[t+c]))
+			dt += absInt(int(pix[tl+c]) - int(pix[l+c]))
+		}
+		if dl < dt {
+			copy(pred[:], pix[l:l+4])
+		} else {
+			copy(pred[:], pix[t:t+4])
+		}
+	case predictClamp:
+		for c := 0; c < 4; c++ {
+			v := int(pix[l+c]) + int(pix[t+c]) - int(pix[tl+c])
+			pred[c] = byte(min(max(v, 0), 255))
+		}
+	}
+}
+
+// encodeImage writes an entropy-coded image: no color cache, one group of
+// prefix codes, literal pixels only
+func encodeImage(bw *bitWriter, pix []byte, topLevel bool) {
+	bw.write(0, 1) // no color cache
+	if topLevel {
+		bw.write(0, 1) // no meta prefix codes
+	}
+
+	// Channel order in the bitstream is green, red, blue, alpha
+	var hist [5][]uint32
+	for i, n := range alphabetSizes {
+		hist[i] = make([]uint32, n)
+	}
+	for p := 0; p < len(pix); p += 4 {
+		hist[0][pix[p+1]]++
+		hist[1][pix[p+0]]++
+		hist[2][pix[p+2]]++
+		hist[3][pix[p+3]]++
+	}
+	var codes [5]prefixCode
+	for i := range hist {
+		codes[i] = writePrefixCode(bw, hist[i])
+	}
+
+	for p := 0; p < len(pix); p += 4 {
+		codes[0].emit(bw, int(pix[p+1]))
+		codes[1].emit(bw, int(pix[p+0]))
+		codes[2].emit(bw, int(pix[p+2]))
+		codes[3].emit(bw, int(pix[p+3]))
+	}
+}
+
+// prefixCode maps symbols to bit-reversed canonical codes
+type prefixCode struct {
+	lengths []uint8
+	codes   []uint16
+}
+
+func (c prefixCode) emit(bw *bitWriter, symbol int) {
+	bw.write(uint32(c.codes[symbol]), uint(c.lengths[symbol]))
+}
+
+// writePrefixCode writes the code for hist and returns it for emitting
+// symbols
+func writePrefixCode(bw *bitWriter, hist []uint32) prefixCode {
+	var used []int
+	for s, n := range hist {
+		if n > 0 {
+			used = append(used, s)
+			if len(used) > 2 {
+				break
+			}
+		}
+	}
+
+	// Simple code: one or two symbols below 256, 0 or 1 bit each
+	if len(used) <= 2 && (len(used) == 0 || used[len(used)-1] < 256) {
+		if len(used) == 0 {
+			used = []int{0}
+		}
+		bw.write(1, 1)
+		bw.write(uint32(len(used)-1), 1)
+		if used[0] < 2 {
+			bw.write(0, 1)
+			bw.write(uint32(used[0]), 1)
+		} else {
+			bw.write(1, 1)
+			bw.write(uint32(used[0]), 8)
+		}
+		code := prefixCode{lengths: make([]uint8, len(hist)), codes: make([]uint16, len(hist))}
+		if len(used) == 2 {
+			bw.write(uint32(used[1]), 8)
+			code.lengths[used[0]], code.lengths[used[1]] = 1, 1
+			code.codes[used[1]] = 1
+		}
+		return code
+	}
+
+	lengths := huffmanLengths(hist, maxCodeLength)
+
+	// Run-length encode the code lengths: 17 and 18 are zero runs
+	type token struct{ symbol, extra, extraBits int }
+	var tokens []token
+	for i := 0; i < len(lengths); {
+		if lengths[i] != 0 {
+			tokens = append(tokens, token{symbol: int(lengths[i])})
+			i++
+			continue
+		}
+		run := 1
+		for i+run < len(lengths) && lengths[i+run] == 0 && run < 138 {
+			run++
+		}
+		switch {
+		case run < 3:
+			for j := 0; j < run; j++ {
+				tokens = append(tokens, token{symbol: 0})
+			}
+		case run <= 10:
+			tokens = append(tokens, token{symbol: 17, extra: run - 3, extraBits: 3})
+		default:
+			tokens = append(tokens, token{symbol: 18, extra: run - 11, extraBits: 7})
+		}
+		i += run
+	}
+
+	clHist := make([]uint32, len(codeLengthCodeOrder))
+	for _, t := range tokens {
+		clHist[t.symbol]++
+	}
+	clCode := newPrefixCode(huffmanLengths(clHist, maxCodeLengthLength))
+
+	n := len(codeLengthCodeOrder)
+	for n > 4 && clCode.lengths[codeLengthCodeOrder[n-1]] == 0 {
+		n--
+	}
+	bw.write(0, 1) // normal code
+	bw.write(uint32(n-4), 4)
+	for _, s := range codeLengthCodeOrder[:n] {
+		bw.write(uint32(clCode.lengths[s]), 3)
+	}
+	bw.write(0, 1) // code lengths cover the whole alphabet
+
+	clEmit := clCode.emitter()
+	for _, t := range tokens {
+		clEmit.emit(bw, t.symbol)
+		if t.extraBits > 0 {
+			bw.write(uint32(t.extra), uint(t.extraBits))
+		}
+	}
+	return newPrefixCode(lengths).emitter()
+}
+
+// newPrefixCode assigns canonical codes (as in DEFLATE) to lengths
+func newPrefixCode(lengths []uint8) prefixCode {
+	var count [maxCodeLength + 1]uint16
+	for _, l := range lengths {
+		count[l]++
+	}
+	count[0] = 0
+	var next [maxCodeLength + 2]uint16
+	code := uint16(0)
+	for l := 1; l <= maxCodeLength; l++ {
+		code = (code + count[l-1]) << 1
+		next[l] = code
+	}
+
+	codes := make([]uint16, len(lengths))
+	for s, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		codes[s] = reverseBits(next[l], l)
+		next[l]++
+	}
+	return prefixCode{lengths: lengths, codes: codes}
+}
+
+// emitter returns the code used for writing symbols. A code with a single
+// symbol is read with zero bits, whatever length was declared for it.
+func (c prefixCode) emitter() prefixCode {
+	used := 0
+	for _, l := range c.lengths {
+		if l != 0 {
+			used++
+		}
+	}
+	if used != 1 {
+		return c
+	}
+	return prefixCode{lengths: make([]uint8, len(c.lengths)), codes: c.codes}
+}
+
+func reverseBits(v uint16, n uint8) uint16 {
+	var r uint16
+	for i := uint8(0); i < n; i++ {
+		r = r<<1 | v&1
+		v >>= 1
+	}
+	return r
+}
+
+// huffmanLengths builds Huffman code lengths no longer than maxLen. When
+// the tree is too deep, small counts are raised and the tree rebuilt,
+// which always yields a complete code.
+func huffmanLengths(hist []uint32, maxLen int) []uint8 {
+	lengths := make([]uint8, len(hist))
+	for floor := uint64(1); ; floor *= 2 {
+		h := &nodeHeap{}
+		var nodes []huffNode
+		for s, n := range hist {
+			if n == 0 {
+				continue
+			}
+			nodes = append(nodes, huffNode{weight: max(uint64(n), floor), symbol: s, parent: -1})
+			heap.Push(h, heapItem{weight: nodes[len(nodes)-1].weight, index: len(nodes) - 1})
+		}
+		if len(nodes) == 0 {
+			return lengths
+		}
+		if len(nodes) == 1 {
+			lengths[nodes[0].symbol] = 1
+			return lengths
+		}
+		leaves := len(nodes)
+		for h.Len() > 1 {
+			a := heap.Pop(h).(heapItem)
+			b := heap.Pop(h).(heapItem)
+			nodes = append(nodes, huffNode{weight: a.weight + b.weight, symbol: -1, parent: -1})
+			parent := len(nodes) - 1
+			nodes[a.index].parent, nodes[b.index].parent = parent, parent
+			heap.Push(h, heapItem{weight: nodes[parent].weight, index: parent})
+		}
+
+		deepest := 0
+		for i := 0; i < leaves; i++ {
+			depth := 0
+			for p := nodes[i].parent; p >= 0; p = nodes[p].parent {
+				depth++
+			}
+			lengths[nodes[i].symbol] = uint8(min(depth, 255))
+			deepest = max(deepest, depth)
+		}
+		if deepest <= maxLen {
+			return lengths
+		}
+	}
+}
+
+type huffNode struct {
+	weight         uint64
+	symbol, parent int
+}
+
+type heapItem struct {
+	weight uint64
+	index  int
+}
+
+// nodeHeap orders by weight, then by index so builds are deterministic
+type nodeHeap []heapItem
+
+func (h nodeHeap) Len() int { return len(h) }
+func (h nodeHeap) Less(i, j int) bool {
+	if h[i].weight != h[j].weight {
+		return h[i].weight < h[j].weight
+	}
+	return h[i].index < h[j].index
+}
+func (h nodeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *nodeHeap) Push(x any)   { *h = append(*h, x.(heapItem)) }
+func (h *nodeHeap) Pop() any {
+	old := *h
+	it := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return it
+}
+
+// bitWriter packs values least significant bit first, as VP8L reads them
+type bitWriter struct {
+	buf  []byte
+	acc  uint64
+	nacc uint
+}
+
+func (w *bitWriter) write(v uint32, n uint) {
+	w.acc |= uint64(v) << w.nacc
+	w.nacc += n
+	for w.nacc >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.nacc -= 8
+	}
+}
+
+func (w *bitWriter) bytes() []byte {
+	if w.nacc > 0 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc, w.nacc = 0, 0
+	}
+	return w.buf
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,398 @@
+// Package jsonschema validates JSON documents against a JSON Schema subset
+//
+// File: jsonschema.go
+// Description: Minimal JSON Schema (draft 2020-12 subset) validator
+//
+// Supported keywords:
+//   - type (string or array), enum, const
+//   - properties, required, additionalProperties (bool or schema), maxProperties
+//   - items, minItems, maxItems
+//   - minLength, maxLength, pattern
+//   - minimum, maximum
+//
+// Annotation keywords ($schema, $id, $comment, title, description, default,
+// examples) are accepted and ignored. Any other keyword is rejected by
+// Compile, so a schema never silently checks less than it says.
+//
+// Usage:
+//
+//	s, err := jsonschema.Compile(schemaJSON)
+//	if errs := s.Validate(doc); len(errs) > 0 { ... }
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a compiled schema node
+type Schema struct {
+	types                []string
+	enum                 []any
+	constVal             any
+	hasConst             bool
+	properties           map[string]*Schema
+	required             []string
+	additionalProperties *Schema // nil allows any value
+	maxProperties        *int
+	items                *Schema
+	minItems             *int
+	maxItems             *int
+	minLength            *int
+	maxLength            *int
+	pattern              *regexp.Regexp
+	minimum              *float64
+	maximum              *float64
+
+	deny bool // false schema
+}
+
+// ValidationError is a single failed keyword at a JSON pointer path
+type ValidationError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// rawSchema mirrors the supported keywords for decoding
+type rawSchema struct {
+	Type                 json.RawMessage            `json:"type"`
+	Enum                 []any                      `json:"enum"`
+	Const                json.RawMessage            `json:"const"`
+	Properties           map[string]json.RawMessage `json:"properties"`
+	Required             []string                   `json:"required"`
+	AdditionalProperties json.RawMessage            `json:"additionalProperties"`
+	MaxProperties        *int                       `json:"maxProperties"`
+	Items                json.RawMessage            `json:"items"`
+	MinItems             *int                       `json:"minItems"`
+	MaxItems             *int                       `json:"maxItems"`
+	MinLength            *int                       `json:"minLength"`
+	MaxLength            *int                       `json:"maxLength"`
+	Pattern              string                     `json:"pattern"`
+	Minimum              *float64                   `json:"minimum"`
+	Maximum              *float64                   `json:"maximum"`
+}
+
+// annotations are keywords that carry no validation and are safe to ignore
+var annotations = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true,
+	"title": true, "description": true, "default": true, "examples": true,
+}
+
+// supported lists the validation keywords rawSchema decodes
+var supported = func() map[string]bool {
+	m := map[string]bool{}
+	t := reflect.TypeOf(rawSchema{})
+	for i := 0; i < t.NumField(); i++ {
+		m[strings.Split(t.Field(i).Tag.Get("json"), ",")[0]] = true
+	}
+	return m
+}()
+
+// Compile parses a schema document. Unsupported keywords are an error.
+func Compile(data []byte) (*Schema, error) {
+	return compile(json.RawMessage(data), "#")
+}
+
+// MustCompile is like Compile but panics on error (embedded schemas)
+func MustCompile(data []byte) *Schema {
+	s, err := Compile(data)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func compile(data json.RawMessage, path string) (*Schema, error) {
+	data = bytes.TrimSpace(data)
+	switch string(data) {
+	case "true", "{}":
+		return &Schema{}, nil
+	case "false":
+		return &Schema{deny: true}, nil
+	}
+
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keywords); err != nil {
+		return nil, fmt.Errorf("jsonschema: %s: %w", path, err)
+	}
+	for _, k := range slices.Sorted(maps.Keys(keywords)) {
+		if !supported[k] && !annotations[k] {
+			return nil, fmt.Errorf("jsonschema: %s/%s: unsupported keyword", path, escapePointer(k))
+		}
+	}
+
+	var raw rawSchema
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("jsonschema: %s: %w", path, err)
+	}
+
+	s := &Schema{
+		enum:          raw.Enum,
+		required:      raw.Required,
+		maxProperties: raw.MaxProperties,
+		minItems:      raw.MinItems,
+		maxItems:      raw.MaxItems,
+		minLength:     raw.MinLength,
+		maxLength:     raw.MaxLength,
+		minimum:       raw.Minimum,
+		maximum:       raw.Maximum,
+	}
+
+	if len(raw.Type) > 0 {
+		var one string
+		if err := json.Unmarshal(raw.Type, &one); err == nil {
+			s.types = []string{one}
+		} else if err := json.Unmarshal(raw.Type, &s.types); err != nil {
+			return nil, fmt.Errorf("jsonschema: %s/type: must be string or array", path)
+		}
+	}
+	if len(raw.Const) > 0 {
+		if err := json.Unmarshal(raw.Const, &s.constVal); err != nil {
+			return nil, fmt.Errorf("jsonschema: %s/const: %w", path, err)
+		}
+		s.hasConst = true
+	}
+	if raw.Pattern != "" {
+		re, err := regexp.Compile(raw.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("jsonschema: %s/pattern: %w", path, err)
+		}
+		s.pattern = re
+	}
+	if len(raw.Properties) > 0 {
+		s.properties = make(map[string]*Schema, len(raw.Properties))
+		for name, sub := range raw.Properties {
+			c, err := compile(sub, path+"/properties/"+name)
+			if err != nil {
+				return nil, err
+			}
+			s.properties[name] = c
+		}
+	}
+	if len(raw.AdditionalProperties) > 0 {
+		c, err := compile(raw.AdditionalProperties, path+"/additionalProperties")
+		if err != nil {
+			return nil, err
+		}
+		s.additionalProperties = c
+	}
+	if len(raw.Items) > 0 {
+		c, err := compile(raw.Items, path+"/items")
+		if err != nil {
+			return nil, err
+		}
+		s.items = c
+	}
+	return s, nil
+}
+
+// ValidateJSON decodes data and validates it
+func (s *Schema) ValidateJSON(data []byte) []ValidationError {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return []ValidationError{{Path: "", Message: "invalid JSON: " + err.Error()}}
+	}
+	return s.Validate(doc)
+}
+
+// Validate checks a decoded document (encoding/json types; numbers may be
+// float64 or json.Number). Errors are returned in document order.
+func (s *Schema) Validate(doc any) []ValidationError {
+	var errs []ValidationError
+	s.validate(doc, "", &errs)
+	return errs
+}
+
+func (s *Schema) validate(v any, path string, errs *[]ValidationError) {
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.deny {
+		fail("not allowed")
+		return
+	}
+	if len(s.types) > 0 && !s.matchesType(v) {
+		fail("expected %s, got %s", strings.Join(s.types, " or "), typeOf(v))
+		return
+	}
+	if len(s.enum) > 0 && !containsValue(s.enum, v) {
+		fail("must be one of %s", mustJSON(s.enum))
+	}
+	if s.hasConst && !equalValues(s.constVal, v) {
+		fail("must be %s", mustJSON(s.constVal))
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		s.validateObject(val, path, errs, fail)
+	case []any:
+		if s.minItems != nil && len(val) < *s.minItems {
+			fail("must have at least %d items", *s.minItems)
+		}
+		if s.maxItems != nil && len(val) > *s.maxItems {
+			fail("must have at most %d items", *s.maxItems)
+		}
+		if s.items != nil {
+			for i, item := range val {
+				s.items.validate(item, path+"/"+strconv.Itoa(i), errs)
+			}
+		}
+	case string:
+		n := utf8.RuneCountInString(val)
+		if s.minLength != nil && n < *s.minLength {
+			fail("must be at least %d characters", *s.minLength)
+		}
+		if s.maxLength != nil && n > *s.maxLength {
+			fail("must be at most %d characters", *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(val) {
+			fail("must match pattern %s", s.pattern.String())
+		}
+	default:
+		if f, ok := toFloat(v); ok {
+			if s.minimum != nil && f < *s.minimum {
+				fail("must be >= %v", *s.minimum)
+			}
+			if s.maximum != nil && f > *s.maximum {
+				fail("must be <= %v", *s.maximum)
+			}
+		}
+	}
+}
+
+func (s *Schema) validateObject(obj map[string]any, path string, errs *[]ValidationError, fail func(string, ...any)) {
+	if s.maxProperties != nil && len(obj) > *s.maxProperties {
+		fail("must have at most %d properties", *s.maxProperties)
+	}
+	for _, name := range s.required {
+		if _, ok := obj[name]; !ok {
+			fail("missing required property %q", name)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(obj)) {
+		child := path + "/" + escapePointer(name)
+		if sub, ok := s.properties[name]; ok {
+			sub.validate(obj[name], child, errs)
+		} else if s.additionalProperties != nil {
+			if s.additionalProperties.deny {
+				*errs = append(*errs, ValidationError{Path: child, Message: "additional property not allowed"})
+				continue
+			}
+			s.additionalProperties.validate(obj[name], child, errs)
+		}
+	}
+}
+
+func (s *Schema) matchesType(v any) bool {
+	actual := typeOf(v)
+	for _, t := range s.types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// typeOf returns the JSON Schema type name of a decoded value
+func typeOf(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		if f, ok := toFloat(val); ok {
+			if f == math.Trunc(f) && !math.IsInf(f, 0) {
+				return "integer"
+			}
+			return "number"
+		}
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func containsValue(list []any, v any) bool {
+	for _, item := range list {
+		if equalValues(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalValues compares JSON values, treating numbers by value
+func equalValues(a, b any) bool {
+	fa, okA := toFloat(a)
+	fb, okB := toFloat(b)
+	if okA || okB {
+		return okA && okB && fa == fb
+	}
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+// normalize converts json.Number recursively so DeepEqual works on nested values
+func normalize(v any) any {
+	switch val := v.(type) {
+	case json.Number:
+		f, _ := val.Float64()
+		return f
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = normalize(item)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			out[k] = normalize(item)
+		}
+		return out
+	}
+	return v
+}
+
+func mustJSON(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// escapePointer escapes a property name for a JSON pointer (RFC 6901)
+func escapePointer(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
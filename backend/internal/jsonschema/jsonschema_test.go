@@ -0,0 +1,95 @@
+// Package jsonschema validates JSON documents against a JSON Schema subset
+//
+// File: jsonschema_test.go
+// Description: Unit tests for jsonschema package
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `{
+	"type": "object",
+	"maxProperties": 4,
+	"required": ["version"],
+	"properties": {
+		"version": {"const": 1},
+		"sidebar": {"type": "string", "enum": ["open", "closed"]},
+		"page_size": {"type": "integer", "minimum": 10, "maximum": 100},
+		"tags": {"type": "array", "maxItems": 2, "items": {"type": "string", "maxLength": 3, "pattern": "^[a-z]+$"}}
+	},
+	"additionalProperties": false
+}`
+
+func TestValidateJSON(t *testing.T) {
+	s := MustCompile([]byte(testSchema))
+
+	tests := []struct {
+		name  string
+		doc   string
+		paths []string
+	}{
+		{name: "valid", doc: `{"version": 1, "sidebar": "open", "page_size": 20, "tags": ["ab"]}`},
+		{name: "not object", doc: `[]`, paths: []string{""}},
+		{name: "missing required", doc: `{}`, paths: []string{""}},
+		{name: "wrong const", doc: `{"version": 2}`, paths: []string{"/version"}},
+		{name: "enum", doc: `{"version": 1, "sidebar": "half"}`, paths: []string{"/sidebar"}},
+		{name: "integer", doc: `{"version": 1, "page_size": 20.5}`, paths: []string{"/page_size"}},
+		{name: "range", doc: `{"version": 1, "page_size": 5}`, paths: []string{"/page_size"}},
+		{name: "array items", doc: `{"version": 1, "tags": ["abcd", "A", "x"]}`, paths: []string{"/tags", "/tags/0", "/tags/1"}},
+		{name: "additional", doc: `{"version": 1, "a/b": true}`, paths: []string{"/a~1b"}},
+		{name: "invalid json", doc: `{`, paths: []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := s.ValidateJSON([]byte(tt.doc))
+
+			var paths []string
+			for _, e := range errs {
+				paths = append(paths, e.Path)
+			}
+			assert.Equal(t, tt.paths, paths, "%v", errs)
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	t.Run("type list and nested additionalProperties", func(t *testing.T) {
+		s, err := Compile([]byte(`{"type": "object", "additionalProperties": {"type": ["string", "null"]}}`))
+		require.NoError(t, err)
+
+		assert.Empty(t, s.ValidateJSON([]byte(`{"a": "x", "b": null}`)))
+		assert.Len(t, s.ValidateJSON([]byte(`{"a": 1}`)), 1)
+	})
+
+	t.Run("annotations are ignored", func(t *testing.T) {
+		s, err := Compile([]byte(`{"$schema": "https://json-schema.org/draft/2020-12/schema", "title": "x", "description": "y", "default": 1, "type": "number"}`))
+		require.NoError(t, err)
+		assert.Empty(t, s.ValidateJSON([]byte(`3`)))
+	})
+
+	t.Run("unsupported keywords are rejected", func(t *testing.T) {
+		_, err := Compile([]byte(`{"type": "string", "format": "email"}`))
+		assert.ErrorContains(t, err, "#/format: unsupported keyword")
+
+		_, err = Compile([]byte(`{"properties": {"a": {"exclusiveMinimum": 0}}}`))
+		assert.ErrorContains(t, err, "#/properties/a/exclusiveMinimum")
+
+		_, err = Compile([]byte(`{"items": {"oneOf": [{"type": "string"}]}}`))
+		assert.ErrorContains(t, err, "#/items/oneOf")
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, err := Compile([]byte(`{"pattern": "("}`))
+		assert.ErrorContains(t, err, "#/pattern")
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		_, err := Compile([]byte(`{"properties": {"a": {"type": 1}}}`))
+		assert.ErrorContains(t, err, "#/properties/a/type")
+	})
+}
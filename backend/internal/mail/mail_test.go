@@ -0,0 +1,199 @@
+// Package mail provides pluggable outgoing email delivery
+//
+// File: mail_test.go
+// Description: Unit tests for mail package
+package mail
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"templatev25/internal/circuitbreaker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyMailer fails the first n sends with err
+type flakyMailer struct {
+	failures int
+	err      error
+	calls    int
+}
+
+func (m *flakyMailer) Send(ctx context.Context, msg Message) error {
+	m.calls++
+	if m.calls <= m.failures {
+		return m.err
+	}
+	return nil
+}
+
+func fastRetry() circuitbreaker.RetryConfig {
+	return circuitbreaker.RetryConfig{
+		MaxRetries:      3,
+		InitialInterval: time.Millisecond,
+		MaxInterval:     5 * time.Millisecond,
+		Multiplier:      2,
+	}
+}
+
+func TestMessageValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		msg     Message
+		wantErr bool
+	}{
+		{name: "valid", msg: Message{To: []string{"a@example.com"}, Subject: "hi"}},
+		{name: "no recipients", msg: Message{Subject: "hi"}, wantErr: true},
+		{name: "header injection in recipient", msg: Message{To: []string{"a@example.com\r\nBcc: x@example.com"}}, wantErr: true},
+		{name: "header injection in subject", msg: Message{To: []string{"a@example.com"}, Subject: "hi\nBcc: x"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.msg.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMessageBytes_Multipart(t *testing.T) {
+	msg := Message{
+		From:    "App <no-reply@example.com>",
+		To:      []string{"user@example.com"},
+		Subject: "Сайн байна уу",
+		Text:    "plain body",
+		HTML:    "<p>html body</p>",
+	}
+
+	raw, err := msg.Bytes()
+	require.NoError(t, err)
+
+	s := string(raw)
+	assert.Contains(t, s, "From: App <no-reply@example.com>\r\n")
+	assert.Contains(t, s, "To: user@example.com\r\n")
+	assert.Contains(t, s, "Subject: =?utf-8?q?")
+	assert.Contains(t, s, "multipart/alternative")
+	assert.Contains(t, s, "plain body")
+	assert.Contains(t, s, "<p>html body</p>")
+	assert.Contains(t, s, "Message-ID: <")
+	assert.Contains(t, s, "@example.com>\r\n")
+}
+
+func TestFileMailer_WritesOutbox(t *testing.T) {
+	dir := t.TempDir()
+	m := NewFileMailer(dir, "no-reply@example.com")
+
+	err := m.Send(context.Background(), Message{
+		To:      []string{"user@example.com"},
+		Subject: "Test",
+		Text:    "hello",
+	})
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	raw, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "From: no-reply@example.com")
+	assert.Contains(t, string(raw), "hello")
+
+	sent := m.Sent()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "no-reply@example.com", sent[0].From)
+}
+
+func TestFileMailer_RejectsInvalidMessage(t *testing.T) {
+	m := NewFileMailer(t.TempDir(), "no-reply@example.com")
+
+	err := m.Send(context.Background(), Message{Subject: "no recipients"})
+	assert.ErrorIs(t, err, ErrNoRecipients)
+	assert.True(t, IsPermanent(err))
+}
+
+func TestRetryMailer_RetriesTransientFailures(t *testing.T) {
+	next := &flakyMailer{failures: 2, err: errors.New("connection reset")}
+	m := NewRetryMailer(next, fastRetry())
+
+	err := m.Send(context.Background(), Message{To: []string{"user@example.com"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, next.calls)
+}
+
+func TestRetryMailer_GivesUpAfterMaxRetries(t *testing.T) {
+	next := &flakyMailer{failures: 100, err: errors.New("connection reset")}
+	m := NewRetryMailer(next, fastRetry())
+
+	err := m.Send(context.Background(), Message{To: []string{"user@example.com"}})
+	assert.EqualError(t, err, "connection reset")
+	assert.Equal(t, 4, next.calls) // initial + 3 retries
+}
+
+func TestRetryMailer_DoesNotRetryPermanentFailures(t *testing.T) {
+	next := &flakyMailer{failures: 100, err: Permanent(errors.New("550 mailbox unavailable"))}
+	m := NewRetryMailer(next, fastRetry())
+
+	err := m.Send(context.Background(), Message{To: []string{"user@example.com"}})
+	assert.True(t, IsPermanent(err))
+	assert.Equal(t, 1, next.calls)
+}
+
+func TestNew_Drivers(t *testing.T) {
+	m, err := New(Config{Driver: DriverFile, OutboxDir: t.TempDir()})
+	require.NoError(t, err)
+	assert.IsType(t, &RetryMailer{}, m)
+
+	m, err = New(Config{Driver: DriverNoop})
+	require.NoError(t, err)
+	assert.IsType(t, NoopMailer{}, m)
+
+	_, err = New(Config{Driver: "carrier-pigeon"})
+	assert.ErrorIs(t, err, ErrUnknownDriver)
+}
+
+func TestTemplates(t *testing.T) {
+	tpl, err := NewTemplates("TemplateBackend")
+	require.NoError(t, err)
+
+	link := "https://app.example.com/verify?token=abc&x=<y>"
+
+	t.Run("verification", func(t *testing.T) {
+		msg, err := tpl.Verification("user@example.com", "Bold", link, 24*time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"user@example.com"}, msg.To)
+		assert.Equal(t, "[TemplateBackend] Verify your email address", msg.Subject)
+		assert.Contains(t, msg.Text, "Hello Bold,")
+		assert.Contains(t, msg.Text, link)
+		assert.Contains(t, msg.Text, "24 hours")
+		// HTML output must escape the link
+		assert.NotContains(t, msg.HTML, "<y>")
+		assert.Contains(t, msg.HTML, "24 hours")
+	})
+
+	t.Run("password reset", func(t *testing.T) {
+		msg, err := tpl.PasswordReset("user@example.com", "", link, time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, "[TemplateBackend] Reset your password", msg.Subject)
+		assert.True(t, strings.HasPrefix(msg.Text, "Hello,"))
+		assert.Contains(t, msg.Text, "1 hour.")
+	})
+
+	t.Run("welcome", func(t *testing.T) {
+		msg, err := tpl.Welcome("user@example.com", "Bold", "https://app.example.com/login")
+		require.NoError(t, err)
+		assert.Equal(t, "[TemplateBackend] Welcome to TemplateBackend", msg.Subject)
+		assert.Contains(t, msg.Text, "https://app.example.com/login")
+		assert.Contains(t, msg.HTML, "Sign in")
+	})
+}
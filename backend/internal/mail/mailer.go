@@ -0,0 +1,121 @@
+// Package mail provides pluggable outgoing email delivery
+//
+// File: mailer.go
+// Description: Mailer interface, message type and driver selection
+//
+// This package provides:
+//   - Mailer interface implemented by SMTP and file/outbox drivers
+//   - Retry wrapper that retries transient delivery failures
+//   - Templated verification, password reset and welcome messages
+//
+// Usage:
+//
+//	m, err := mail.New(cfg)
+//	tpl, err := mail.NewTemplates(cfg.AppName)
+//	msg, err := tpl.Verification(user.Email, user.FirstName, link, 24*time.Hour)
+//	err = m.Send(ctx, msg)
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"templatev25/internal/circuitbreaker"
+)
+
+// Driver names
+const (
+	DriverSMTP = "smtp"
+	DriverFile = "file"
+	DriverNoop = "noop"
+)
+
+// Common errors
+var (
+	ErrNoRecipients  = errors.New("mail: message has no recipients")
+	ErrUnknownDriver = errors.New("mail: unknown driver")
+)
+
+// Message is a single outgoing email
+type Message struct {
+	From    string
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Validate checks that the message can be delivered
+func (m Message) Validate() error {
+	if len(m.To) == 0 {
+		return ErrNoRecipients
+	}
+	for _, to := range m.To {
+		if strings.ContainsAny(to, "\r\n") {
+			return fmt.Errorf("mail: invalid recipient %q", to)
+		}
+	}
+	if strings.ContainsAny(m.Subject, "\r\n") {
+		return errors.New("mail: invalid subject")
+	}
+	return nil
+}
+
+// Mailer delivers email messages
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Config holds mail delivery settings
+type Config struct {
+	// Driver selects the implementation: smtp, file or noop
+	Driver string
+
+	// From is the default sender address
+	From string
+
+	// AppName is shown in subjects and message bodies
+	AppName string
+
+	// SMTP settings
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	// SMTPTLS is one of: starttls, tls, none
+	SMTPTLS     string
+	SMTPTimeout time.Duration
+
+	// OutboxDir is where the file driver writes .eml files
+	OutboxDir string
+
+	// Retry controls retries on transient failures
+	Retry circuitbreaker.RetryConfig
+}
+
+// New creates a Mailer for the configured driver, wrapped with retry
+func New(cfg Config) (Mailer, error) {
+	var m Mailer
+	switch strings.ToLower(cfg.Driver) {
+	case DriverSMTP:
+		m = NewSMTPMailer(cfg)
+	case DriverFile, "":
+		m = NewFileMailer(cfg.OutboxDir, cfg.From)
+	case DriverNoop:
+		return NoopMailer{}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDriver, cfg.Driver)
+	}
+	return NewRetryMailer(m, cfg.Retry), nil
+}
+
+// NoopMailer discards all messages
+type NoopMailer struct{}
+
+// Send implements Mailer
+func (NoopMailer) Send(ctx context.Context, msg Message) error {
+	return msg.Validate()
+}
@@ -0,0 +1,103 @@
+// Package mail provides pluggable outgoing email delivery
+//
+// File: message.go
+// Description: RFC 5322 encoding of outgoing messages
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"strings"
+	"time"
+)
+
+// Bytes encodes the message as a MIME email.
+// A multipart/alternative body is produced when both Text and HTML are set.
+func (m Message) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+
+	writeHeader(&buf, "From", m.From)
+	writeHeader(&buf, "To", strings.Join(m.To, ", "))
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	writeHeader(&buf, "Date", time.Now().Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", messageID(m.From))
+	writeHeader(&buf, "MIME-Version", "1.0")
+
+	switch {
+	case m.Text != "" && m.HTML != "":
+		boundary, err := randomHex(16)
+		if err != nil {
+			return nil, err
+		}
+		writeHeader(&buf, "Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", boundary))
+		buf.WriteString("\r\n")
+
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		if err := writePart(&buf, "text/plain", m.Text); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		if err := writePart(&buf, "text/html", m.HTML); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+	case m.HTML != "":
+		if err := writePart(&buf, "text/html", m.HTML); err != nil {
+			return nil, err
+		}
+	default:
+		if err := writePart(&buf, "text/plain", m.Text); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeHeader(buf *bytes.Buffer, key, value string) {
+	buf.WriteString(key)
+	buf.WriteString(": ")
+	buf.WriteString(value)
+	buf.WriteString("\r\n")
+}
+
+func writePart(buf *bytes.Buffer, contentType, body string) error {
+	writeHeader(buf, "Content-Type", contentType+"; charset=utf-8")
+	writeHeader(buf, "Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(buf)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return err
+	}
+	if err := qp.Close(); err != nil {
+		return err
+	}
+	buf.WriteString("\r\n")
+	return nil
+}
+
+// messageID builds a unique Message-ID using the sender domain
+func messageID(from string) string {
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = strings.TrimSuffix(from[i+1:], ">")
+	}
+	id, err := randomHex(12)
+	if err != nil {
+		id = fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return fmt.Sprintf("<%s@%s>", id, domain)
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,80 @@
+// Package mail provides pluggable outgoing email delivery
+//
+// File: outbox.go
+// Description: File-based mailer that writes .eml files for dev and tests
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileMailer writes every message to an outbox directory instead of sending it.
+// Each message becomes one .eml file that can be opened by any mail client.
+type FileMailer struct {
+	dir  string
+	from string
+
+	mu   sync.Mutex
+	sent []Message
+}
+
+// NewFileMailer creates a new file mailer
+func NewFileMailer(dir, from string) *FileMailer {
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "mail-outbox")
+	}
+	return &FileMailer{dir: dir, from: from}
+}
+
+// Send implements Mailer
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	if msg.From == "" {
+		msg.From = m.from
+	}
+	if err := msg.Validate(); err != nil {
+		return Permanent(err)
+	}
+
+	body, err := msg.Bytes()
+	if err != nil {
+		return Permanent(err)
+	}
+
+	if err := os.MkdirAll(m.dir, 0o750); err != nil {
+		return fmt.Errorf("outbox mkdir: %w", err)
+	}
+
+	suffix, err := randomHex(4)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), suffix)
+	path := filepath.Clean(filepath.Join(m.dir, name))
+	if err := os.WriteFile(path, body, 0o600); err != nil {
+		return fmt.Errorf("outbox write: %w", err)
+	}
+
+	m.mu.Lock()
+	m.sent = append(m.sent, msg)
+	m.mu.Unlock()
+	return nil
+}
+
+// Sent returns a copy of messages delivered by this mailer instance
+func (m *FileMailer) Sent() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Message, len(m.sent))
+	copy(out, m.sent)
+	return out
+}
+
+// Dir returns the outbox directory
+func (m *FileMailer) Dir() string {
+	return m.dir
+}
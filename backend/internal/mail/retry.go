@@ -0,0 +1,66 @@
+// Package mail provides pluggable outgoing email delivery
+//
+// File: retry.go
+// Description: Retry wrapper for transient delivery failures
+package mail
+
+import (
+	"context"
+	"errors"
+
+	"templatev25/internal/circuitbreaker"
+)
+
+// permanentError marks a failure that must not be retried
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that RetryMailer does not retry it
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked as permanent
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// RetryMailer retries transient failures of the wrapped Mailer with exponential backoff
+type RetryMailer struct {
+	next   Mailer
+	config circuitbreaker.RetryConfig
+}
+
+// NewRetryMailer wraps next with retry. A zero config uses circuitbreaker defaults.
+func NewRetryMailer(next Mailer, config circuitbreaker.RetryConfig) *RetryMailer {
+	if config.InitialInterval == 0 {
+		config = circuitbreaker.DefaultRetryConfig()
+	}
+	return &RetryMailer{next: next, config: config}
+}
+
+// Send implements Mailer
+func (m *RetryMailer) Send(ctx context.Context, msg Message) error {
+	var permanent error
+	err := circuitbreaker.ExecuteWithRetry(ctx, m.config, func(ctx context.Context) error {
+		err := m.next.Send(ctx, msg)
+		if err != nil && IsPermanent(err) {
+			// Stop retrying; the error is returned below
+			permanent = err
+			return nil
+		}
+		return err
+	})
+	if permanent != nil {
+		return permanent
+	}
+	return err
+}
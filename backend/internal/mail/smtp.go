@@ -0,0 +1,196 @@
+// Package mail provides pluggable outgoing email delivery
+//
+// File: smtp.go
+// Description: SMTP mailer with STARTTLS and implicit TLS support
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TLS modes
+const (
+	TLSModeStartTLS = "starttls"
+	TLSModeImplicit = "tls"
+	TLSModeNone     = "none"
+)
+
+// SMTPMailer delivers messages through an SMTP relay
+type SMTPMailer struct {
+	host     string
+	port     int
+	username string
+	password string
+	tlsMode  string
+	timeout  time.Duration
+	from     string
+}
+
+// NewSMTPMailer creates a new SMTP mailer
+func NewSMTPMailer(cfg Config) *SMTPMailer {
+	port := cfg.SMTPPort
+	if port == 0 {
+		port = 587
+	}
+	timeout := cfg.SMTPTimeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	tlsMode := strings.ToLower(cfg.SMTPTLS)
+	if tlsMode == "" {
+		tlsMode = TLSModeStartTLS
+	}
+	return &SMTPMailer{
+		host:     cfg.SMTPHost,
+		port:     port,
+		username: cfg.SMTPUsername,
+		password: cfg.SMTPPassword,
+		tlsMode:  tlsMode,
+		timeout:  timeout,
+		from:     cfg.From,
+	}
+}
+
+// Send implements Mailer
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if msg.From == "" {
+		msg.From = m.from
+	}
+	if err := msg.Validate(); err != nil {
+		return Permanent(err)
+	}
+
+	sender, err := envelopeAddress(msg.From)
+	if err != nil {
+		return Permanent(err)
+	}
+	recipients := make([]string, 0, len(msg.To))
+	for _, to := range msg.To {
+		addr, err := envelopeAddress(to)
+		if err != nil {
+			return Permanent(err)
+		}
+		recipients = append(recipients, addr)
+	}
+
+	body, err := msg.Bytes()
+	if err != nil {
+		return Permanent(err)
+	}
+
+	deadline := time.Now().Add(m.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	client, err := m.dial(ctx, deadline)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := m.deliver(client, sender, recipients, body); err != nil {
+		return classifySMTPError(err)
+	}
+	// The server accepted the message at the end of DATA; a failed QUIT must
+	// not be reported, or RetryMailer would send a duplicate
+	_ = client.Quit()
+	return nil
+}
+
+// dial opens the SMTP connection and negotiates TLS
+func (m *SMTPMailer) dial(ctx context.Context, deadline time.Time) (*smtp.Client, error) {
+	addr := net.JoinHostPort(m.host, strconv.Itoa(m.port))
+	dialer := &net.Dialer{Deadline: deadline}
+	tlsCfg := &tls.Config{ServerName: m.host, MinVersion: tls.VersionTLS12}
+
+	var conn net.Conn
+	var err error
+	if m.tlsMode == TLSModeImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsCfg}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("smtp dial: %w", err)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("smtp handshake: %w", err)
+	}
+
+	if m.tlsMode == TLSModeStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			_ = client.Close()
+			return nil, Permanent(errors.New("smtp: server does not support STARTTLS"))
+		}
+		if err := client.StartTLS(tlsCfg); err != nil {
+			_ = client.Close()
+			return nil, fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+
+	if m.username != "" {
+		auth := smtp.PlainAuth("", m.username, m.password, m.host)
+		if err := client.Auth(auth); err != nil {
+			_ = client.Close()
+			return nil, classifySMTPError(fmt.Errorf("smtp auth: %w", err))
+		}
+	}
+
+	return client, nil
+}
+
+func (m *SMTPMailer) deliver(client *smtp.Client, from string, to []string, body []byte) error {
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// envelopeAddress extracts the bare address from "Name <addr>" form
+func envelopeAddress(s string) (string, error) {
+	addr, err := mail.ParseAddress(s)
+	if err != nil {
+		return "", fmt.Errorf("mail: invalid address %q: %w", s, err)
+	}
+	return addr.Address, nil
+}
+
+// classifySMTPError marks 5xx replies as permanent; everything else is retried
+func classifySMTPError(err error) error {
+	var tpErr *textproto.Error
+	if errors.As(err, &tpErr) && tpErr.Code >= 500 {
+		return Permanent(err)
+	}
+	return err
+}
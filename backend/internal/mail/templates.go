@@ -0,0 +1,122 @@
+// Package mail provides pluggable outgoing email delivery
+//
+// File: templates.go
+// Description: Embedded templates for verification, password reset and welcome emails
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// Template names
+const (
+	TemplateVerification  = "verification"
+	TemplatePasswordReset = "password_reset"
+	TemplateWelcome       = "welcome"
+)
+
+// TemplateData is passed to every message template
+type TemplateData struct {
+	AppName   string
+	Subject   string
+	Name      string
+	Link      string
+	ExpiresIn string
+}
+
+// Templates renders the built-in transactional emails
+type Templates struct {
+	appName string
+	html    map[string]*htmltemplate.Template
+	text    map[string]*texttemplate.Template
+}
+
+// NewTemplates parses the embedded templates
+func NewTemplates(appName string) (*Templates, error) {
+	t := &Templates{
+		appName: appName,
+		html:    make(map[string]*htmltemplate.Template),
+		text:    make(map[string]*texttemplate.Template),
+	}
+	for _, name := range []string{TemplateVerification, TemplatePasswordReset, TemplateWelcome} {
+		h, err := htmltemplate.ParseFS(templateFS, "templates/layout.html.tmpl", "templates/"+name+".html.tmpl")
+		if err != nil {
+			return nil, fmt.Errorf("parse %s html template: %w", name, err)
+		}
+		x, err := texttemplate.ParseFS(templateFS, "templates/"+name+".txt.tmpl")
+		if err != nil {
+			return nil, fmt.Errorf("parse %s text template: %w", name, err)
+		}
+		t.html[name] = h
+		t.text[name] = x
+	}
+	return t, nil
+}
+
+// Verification builds the email address verification message
+func (t *Templates) Verification(to, name, link string, ttl time.Duration) (Message, error) {
+	return t.render(TemplateVerification, to, "Verify your email address", name, link, ttl)
+}
+
+// PasswordReset builds the password reset message
+func (t *Templates) PasswordReset(to, name, link string, ttl time.Duration) (Message, error) {
+	return t.render(TemplatePasswordReset, to, "Reset your password", name, link, ttl)
+}
+
+// Welcome builds the message sent after the email address is verified
+func (t *Templates) Welcome(to, name, link string) (Message, error) {
+	return t.render(TemplateWelcome, to, "Welcome to "+t.appName, name, link, 0)
+}
+
+func (t *Templates) render(tmpl, to, subject, name, link string, ttl time.Duration) (Message, error) {
+	data := TemplateData{
+		AppName:   t.appName,
+		Subject:   subject,
+		Name:      name,
+		Link:      link,
+		ExpiresIn: humanizeDuration(ttl),
+	}
+
+	var text bytes.Buffer
+	if err := t.text[tmpl].Execute(&text, data); err != nil {
+		return Message{}, fmt.Errorf("render %s text: %w", tmpl, err)
+	}
+	var html bytes.Buffer
+	if err := t.html[tmpl].ExecuteTemplate(&html, "layout", data); err != nil {
+		return Message{}, fmt.Errorf("render %s html: %w", tmpl, err)
+	}
+
+	return Message{
+		To:      []string{to},
+		Subject: fmt.Sprintf("[%s] %s", t.appName, subject),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// humanizeDuration formats ttl as "24 hours", "1 hour", "30 minutes"
+func humanizeDuration(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return ""
+	case d%time.Hour == 0:
+		return plural(int(d/time.Hour), "hour")
+	default:
+		return plural(int(d.Round(time.Minute)/time.Minute), "minute")
+	}
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
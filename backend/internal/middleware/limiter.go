@@ -125,6 +125,32 @@ func AuthRateLimiter() fiber.Handler {
 	})
 }
 
+// TokenRefreshRateLimiter returns a rate limiter for refresh token rotation.
+// Every mobile client refreshes routinely, and many of them can share one
+// carrier NAT address, so the per-IP limit is far higher than AuthRateLimiter.
+// Guessing a 256-bit refresh token is not feasible; the limit only bounds load.
+//
+// Default: 300 requests per minute per IP
+//
+// Usage:
+//
+//	auth.Post("/token/refresh", middleware.TokenRefreshRateLimiter(), handler.RefreshToken)
+func TokenRefreshRateLimiter() fiber.Handler {
+	return limiter.New(limiter.Config{
+		Max:        300,
+		Expiration: time.Minute,
+		KeyGenerator: func(c *fiber.Ctx) string {
+			return "refresh:" + c.IP()
+		},
+		LimitReached: func(c *fiber.Ctx) error {
+			return fiber.NewError(
+				fiber.StatusTooManyRequests,
+				"too many token refresh requests, please try again later",
+			)
+		},
+	})
+}
+
 // APIRateLimiter returns a moderate rate limiter for general API endpoints.
 // Authenticated users get higher limits than anonymous users.
 //
@@ -283,3 +283,17 @@ func TestContextWithTimeout(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
 }
+
+func TestTokenRefreshRateLimiter_AllowsMoreThanAuthLimit(t *testing.T) {
+	app := fiber.New()
+	app.Post("/token/refresh", TokenRefreshRateLimiter(), func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+
+	// Many clients behind one carrier NAT address refresh routinely
+	for i := 0; i < 20; i++ {
+		resp, err := app.Test(httptest.NewRequest("POST", "/token/refresh", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode, "request %d", i+1)
+	}
+}
@@ -15,14 +15,16 @@ import (
 // SessionData represents session information needed by the middleware
 // This is a local interface to avoid import cycles with the service package
 type SessionData struct {
-	SessionID      string
-	UserID         int
-	Email          string
-	IPAddress      string
-	UserAgent      string
-	CreatedAt      time.Time
-	ExpiresAt      time.Time
-	LastActivityAt time.Time
+	SessionID       string
+	UserID          int
+	Email           string
+	IPAddress       string
+	UserAgent       string
+	CreatedAt       time.Time
+	ExpiresAt       time.Time
+	LastActivityAt  time.Time
+	OrgID           int
+	HasRefreshToken bool
 }
 
 // SessionStore interface defines methods needed by the session auth middleware
@@ -30,6 +32,8 @@ type SessionData struct {
 type SessionStore interface {
 	Get(ctx context.Context, sessionID string) (*SessionData, error)
 	Update(ctx context.Context, session *SessionData) error
+	// Touch records activity on the session only while it still exists
+	Touch(ctx context.Context, sessionID string, at time.Time) error
 }
 
 // SessionAuth creates a middleware that validates sessions from Redis
@@ -69,10 +73,7 @@ func SessionAuth(sessionStore SessionStore) fiber.Handler {
 		}
 
 		// Update last activity (async, don't block)
-		go func() {
-			session.LastActivityAt = time.Now()
-			sessionStore.Update(c.UserContext(), session)
-		}()
+		go sessionStore.Touch(context.Background(), session.SessionID, time.Now())
 
 		// Set session info in context
 		c.Locals("session_id", session.SessionID)
@@ -107,10 +108,7 @@ func OptionalSessionAuth(sessionStore SessionStore) fiber.Handler {
 		}
 
 		// Update last activity (async, don't block)
-		go func() {
-			session.LastActivityAt = time.Now()
-			sessionStore.Update(c.UserContext(), session)
-		}()
+		go sessionStore.Touch(context.Background(), session.SessionID, time.Now())
 
 		// Set session info in context
 		c.Locals("session_id", session.SessionID)
@@ -0,0 +1,201 @@
+// Package notify provides pluggable notification channel providers
+//
+// File: apns.go
+// Description: Apple Push Notification service (token-based auth) sender
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"templatev25/internal/domain"
+)
+
+const (
+	apnsProduction = "https://api.push.apple.com"
+	apnsSandbox    = "https://api.sandbox.push.apple.com"
+
+	// apnsTokenTTL renews the provider token before Apple's 60 minute limit
+	apnsTokenTTL = 50 * time.Minute
+)
+
+// apnsInvalidReasons are APNs error reasons that mean the token is dead
+var apnsInvalidReasons = map[string]bool{
+	"BadDeviceToken":         true,
+	"Unregistered":           true,
+	"DeviceTokenNotForTopic": true,
+	"ExpiredToken":           true,
+}
+
+// APNsConfig holds APNs token-based authentication settings
+type APNsConfig struct {
+	KeyID      string
+	TeamID     string
+	Topic      string // app bundle id
+	PrivateKey []byte // .p8 file contents (PKCS#8 EC P-256)
+	Production bool
+	Endpoint   string // overrides the production/sandbox host
+	Timeout    time.Duration
+}
+
+// LoadAPNsKey reads a .p8 signing key file
+func LoadAPNsKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("notify: read apns key: %w", err)
+	}
+	return data, nil
+}
+
+// APNsSender sends alert pushes over HTTP/2 to APNs
+type APNsSender struct {
+	cfg      APNsConfig
+	key      *ecdsa.PrivateKey
+	endpoint string
+	client   *http.Client
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+// NewAPNsSender creates an APNs sender from a .p8 signing key
+func NewAPNsSender(cfg APNsConfig) (*APNsSender, error) {
+	key, err := parseAPNsKey(cfg.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.KeyID == "" || cfg.TeamID == "" || cfg.Topic == "" {
+		return nil, errors.New("notify: apns needs key id, team id and topic")
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = apnsSandbox
+		if cfg.Production {
+			endpoint = apnsProduction
+		}
+	}
+	return &APNsSender{
+		cfg:      cfg,
+		key:      key,
+		endpoint: strings.TrimRight(endpoint, "/"),
+		// DefaultTransport negotiates HTTP/2 over TLS, which APNs requires
+		client: &http.Client{Timeout: cfg.Timeout},
+	}, nil
+}
+
+// Provider implements PushSender
+func (s *APNsSender) Provider() string { return domain.PushProviderAPNs }
+
+// Send implements PushSender
+func (s *APNsSender) Send(ctx context.Context, token string, msg PushMessage) error {
+	payload := map[string]any{
+		"aps": map[string]any{
+			"alert": map[string]string{"title": msg.Title, "body": msg.Body},
+			"sound": "default",
+		},
+	}
+	for k, v := range msg.Data {
+		if k != "aps" {
+			payload[k] = v
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	auth, err := s.providerToken()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint+"/3/device/"+token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "bearer "+auth)
+	req.Header.Set("apns-topic", s.cfg.Topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	var apnsErr struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.Unmarshal(raw, &apnsErr)
+	if resp.StatusCode == http.StatusGone || apnsInvalidReasons[apnsErr.Reason] {
+		return ErrInvalidToken
+	}
+	return fmt.Errorf("notify: apns returned %d: %s", resp.StatusCode, apnsErr.Reason)
+}
+
+// providerToken returns a cached ES256 JWT, renewed every apnsTokenTTL
+func (s *APNsSender) providerToken() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && time.Since(s.issuedAt) < apnsTokenTTL {
+		return s.token, nil
+	}
+
+	now := time.Now()
+	header, _ := json.Marshal(map[string]string{"alg": "ES256", "kid": s.cfg.KeyID})
+	claims, _ := json.Marshal(map[string]any{"iss": s.cfg.TeamID, "iat": now.Unix()})
+	enc := base64.RawURLEncoding
+	signing := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(signing))
+	r, sig, err := ecdsa.Sign(rand.Reader, s.key, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("notify: sign apns token: %w", err)
+	}
+	// JWS ES256 signature is r || s, each left-padded to 32 bytes
+	raw := make([]byte, 64)
+	r.FillBytes(raw[:32])
+	sig.FillBytes(raw[32:])
+
+	s.token = signing + "." + enc.EncodeToString(raw)
+	s.issuedAt = now
+	return s.token, nil
+}
+
+// parseAPNsKey decodes a PEM PKCS#8 EC private key
+func parseAPNsKey(data []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("notify: apns key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("notify: parse apns key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("notify: apns key must be an EC private key")
+	}
+	return key, nil
+}
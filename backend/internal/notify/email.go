@@ -0,0 +1,49 @@
+// Package notify provides pluggable notification channel providers
+//
+// File: email.go
+// Description: Email channel on top of the mail package
+package notify
+
+import (
+	"context"
+	"strings"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/mail"
+)
+
+// EmailProvider sends notifications as plain text email
+type EmailProvider struct {
+	mailer  mail.Mailer
+	appName string
+}
+
+// NewEmailProvider creates an email channel provider
+func NewEmailProvider(mailer mail.Mailer, appName string) *EmailProvider {
+	return &EmailProvider{mailer: mailer, appName: appName}
+}
+
+// Channel implements Provider
+func (p *EmailProvider) Channel() string { return domain.NotificationChannelEmail }
+
+// Name implements Provider
+func (p *EmailProvider) Name() string { return "mail" }
+
+// Send implements Provider
+func (p *EmailProvider) Send(ctx context.Context, r Recipient, msg Message) error {
+	to := strings.TrimSpace(r.Email)
+	if to == "" {
+		return ErrNoAddress
+	}
+	subject := msg.Title
+	if subject == "" {
+		subject = p.appName
+	}
+	// Subject-д мөр шилжилт орвол mail.Message.Validate татгалзана
+	subject = strings.Join(strings.Fields(subject), " ")
+	return p.mailer.Send(ctx, mail.Message{
+		To:      []string{to},
+		Subject: subject,
+		Text:    msg.Content,
+	})
+}
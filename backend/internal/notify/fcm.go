@@ -0,0 +1,156 @@
+// Package notify provides pluggable notification channel providers
+//
+// File: fcm.go
+// Description: Firebase Cloud Messaging (HTTP v1) push sender
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"templatev25/internal/domain"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/jwt"
+)
+
+const (
+	fcmEndpoint = "https://fcm.googleapis.com"
+	fcmScope    = "https://www.googleapis.com/auth/firebase.messaging"
+	googleToken = "https://oauth2.googleapis.com/token"
+)
+
+// FCMConfig holds Firebase service account settings
+type FCMConfig struct {
+	ProjectID   string
+	ClientEmail string
+	PrivateKey  []byte // PEM, PKCS#8 or PKCS#1 RSA
+	TokenURL    string // default: Google OAuth2 token endpoint
+	Endpoint    string // default: https://fcm.googleapis.com
+	Timeout     time.Duration
+}
+
+// LoadFCMCredentials reads a Firebase service account JSON file
+func LoadFCMCredentials(path string) (FCMConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return FCMConfig{}, fmt.Errorf("notify: read fcm credentials: %w", err)
+	}
+	var sa struct {
+		ProjectID   string `json:"project_id"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(data, &sa); err != nil {
+		return FCMConfig{}, fmt.Errorf("notify: parse fcm credentials: %w", err)
+	}
+	if sa.ProjectID == "" || sa.ClientEmail == "" || sa.PrivateKey == "" {
+		return FCMConfig{}, errors.New("notify: fcm credentials need project_id, client_email and private_key")
+	}
+	return FCMConfig{
+		ProjectID:   sa.ProjectID,
+		ClientEmail: sa.ClientEmail,
+		PrivateKey:  []byte(sa.PrivateKey),
+		TokenURL:    sa.TokenURI,
+	}, nil
+}
+
+// FCMSender sends through the FCM HTTP v1 API with a service account
+type FCMSender struct {
+	url    string
+	client *http.Client
+}
+
+// NewFCMSender creates an FCM sender. Access tokens are fetched and cached
+// by the oauth2 JWT flow.
+func NewFCMSender(cfg FCMConfig) *FCMSender {
+	tokenURL := cfg.TokenURL
+	if tokenURL == "" {
+		tokenURL = googleToken
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fcmEndpoint
+	}
+	jwtCfg := &jwt.Config{
+		Email:      cfg.ClientEmail,
+		PrivateKey: cfg.PrivateKey,
+		Scopes:     []string{fcmScope},
+		TokenURL:   tokenURL,
+	}
+	return &FCMSender{
+		url: strings.TrimRight(endpoint, "/") + "/v1/projects/" + cfg.ProjectID + "/messages:send",
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			Transport: &oauth2.Transport{
+				Source: jwtCfg.TokenSource(context.Background()),
+				Base:   http.DefaultTransport,
+			},
+		},
+	}
+}
+
+// Provider implements PushSender
+func (s *FCMSender) Provider() string { return domain.PushProviderFCM }
+
+// Send implements PushSender
+func (s *FCMSender) Send(ctx context.Context, token string, msg PushMessage) error {
+	body, err := json.Marshal(map[string]any{
+		"message": map[string]any{
+			"token":        token,
+			"notification": map[string]string{"title": msg.Title, "body": msg.Body},
+			"data":         msg.Data,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var fcmErr struct {
+		Error struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(raw, &fcmErr)
+
+	for _, d := range fcmErr.Error.Details {
+		if d.ErrorCode == "UNREGISTERED" {
+			return ErrInvalidToken
+		}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrInvalidToken
+	}
+	if fcmErr.Error.Status == "INVALID_ARGUMENT" && strings.Contains(strings.ToLower(fcmErr.Error.Message), "registration token") {
+		return ErrInvalidToken
+	}
+	return fmt.Errorf("notify: fcm returned %d: %s", resp.StatusCode, strings.TrimSpace(fcmErr.Error.Message))
+}
@@ -0,0 +1,57 @@
+// Package notify provides pluggable notification channel providers
+//
+// File: notify.go
+// Description: Provider interface, recipient and message types
+//
+// This package provides:
+//   - Provider interface implemented by email, SMS and push channels
+//   - EmailProvider on top of mail.Mailer
+//   - HTTPSMSProvider for JSON SMS gateways
+//   - PushProvider over registered devices with FCM and APNs senders
+//     (FakePushSender for tests and local development)
+//
+// Channel selection per user (user_settings) is done by the caller;
+// a provider only delivers one message to one recipient.
+//
+// Usage:
+//
+//	p := notify.NewEmailProvider(mailer, "Gerege")
+//	err := p.Send(ctx, notify.Recipient{UserID: 1, Email: "a@b.mn"}, notify.Message{Title: "Hi"})
+package notify
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoAddress is returned when the recipient has no address for the channel
+// (email, phone number or registered device). Callers record it as skipped.
+var ErrNoAddress = errors.New("notify: recipient has no address for channel")
+
+// Recipient is a single user with contact details
+type Recipient struct {
+	UserID   int
+	Email    string
+	Phone    string
+	Language string
+}
+
+// Message is the channel-independent notification content
+type Message struct {
+	NotificationID int
+	GroupID        int
+	Type           string
+	Tenant         string
+	Title          string
+	Content        string
+}
+
+// Provider delivers messages over one channel
+type Provider interface {
+	// Channel returns the channel name (domain.NotificationChannel*)
+	Channel() string
+	// Name identifies the provider in the delivery log
+	Name() string
+	// Send delivers msg to r; ErrNoAddress when r cannot be reached on this channel
+	Send(ctx context.Context, r Recipient, msg Message) error
+}
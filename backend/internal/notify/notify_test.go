@@ -0,0 +1,103 @@
+// Package notify provides pluggable notification channel providers
+//
+// File: notify_test.go
+// Description: Unit tests for notify package
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"templatev25/internal/mail"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingMailer keeps every sent message
+type recordingMailer struct {
+	sent []mail.Message
+}
+
+func (m *recordingMailer) Send(_ context.Context, msg mail.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func TestEmailProvider_Send(t *testing.T) {
+	mailer := &recordingMailer{}
+	p := NewEmailProvider(mailer, "Gerege")
+
+	err := p.Send(context.Background(),
+		Recipient{UserID: 1, Email: " bat@gerege.mn "},
+		Message{Title: "Шинэ\r\nмэдэгдэл", Content: "Сайн байна уу"},
+	)
+	require.NoError(t, err)
+	require.Len(t, mailer.sent, 1)
+	assert.Equal(t, []string{"bat@gerege.mn"}, mailer.sent[0].To)
+	assert.Equal(t, "Шинэ мэдэгдэл", mailer.sent[0].Subject)
+	assert.Equal(t, "Сайн байна уу", mailer.sent[0].Text)
+
+	// Гарчиггүй бол app-ийн нэр
+	require.NoError(t, p.Send(context.Background(), Recipient{Email: "a@b.mn"}, Message{Content: "x"}))
+	assert.Equal(t, "Gerege", mailer.sent[1].Subject)
+}
+
+func TestEmailProvider_NoAddress(t *testing.T) {
+	mailer := &recordingMailer{}
+	err := NewEmailProvider(mailer, "Gerege").Send(context.Background(), Recipient{UserID: 1}, Message{Title: "x"})
+
+	assert.ErrorIs(t, err, ErrNoAddress)
+	assert.Empty(t, mailer.sent)
+}
+
+func TestHTTPSMSProvider_Send(t *testing.T) {
+	var got struct {
+		auth string
+		body map[string]string
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.auth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&got.body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	p := NewHTTPSMSProvider(srv.URL, "secret", time.Second)
+	err := p.Send(context.Background(), Recipient{Phone: "99112233"}, Message{Title: "Гарчиг", Content: "Агуулга"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer secret", got.auth)
+	assert.Equal(t, "99112233", got.body["to"])
+	assert.Equal(t, "Гарчиг\nАгуулга", got.body["text"])
+}
+
+func TestHTTPSMSProvider_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid number", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	p := NewHTTPSMSProvider(srv.URL, "", time.Second)
+
+	err := p.Send(context.Background(), Recipient{Phone: "1"}, Message{Title: "x"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "400")
+	assert.Contains(t, err.Error(), "invalid number")
+
+	assert.ErrorIs(t, p.Send(context.Background(), Recipient{}, Message{Title: "x"}), ErrNoAddress)
+}
+
+func TestSMSText_Truncates(t *testing.T) {
+	text := smsText(Message{Title: "T", Content: strings.Repeat("ө", 1000)})
+
+	assert.Equal(t, smsMaxLength, utf8.RuneCountInString(text))
+	assert.True(t, strings.HasSuffix(text, "…"))
+	assert.Equal(t, "T", smsText(Message{Title: "T"}))
+}
@@ -0,0 +1,158 @@
+// Package notify provides pluggable notification channel providers
+//
+// File: push.go
+// Description: Push channel over registered devices and per-platform senders
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"templatev25/internal/domain"
+)
+
+// ErrInvalidToken is returned by a PushSender when the provider reports the
+// device token as unregistered or malformed. The device is deactivated.
+var ErrInvalidToken = errors.New("notify: push token is invalid or unregistered")
+
+// PushMessage is the payload sent to one device
+type PushMessage struct {
+	Title string
+	Body  string
+	Data  map[string]string
+}
+
+// PushSender delivers to device tokens of one push provider (FCM, APNs)
+type PushSender interface {
+	// Provider returns the user_devices.push_provider value it handles
+	Provider() string
+	// Send delivers msg to token; ErrInvalidToken when the token is dead
+	Send(ctx context.Context, token string, msg PushMessage) error
+}
+
+// DeviceStore looks up and deactivates push devices (repository.UserDeviceRepository)
+type DeviceStore interface {
+	ActivePushDevices(ctx context.Context, userID int) ([]domain.UserDevice, error)
+	// Deactivate only applies while the device still has pushToken
+	Deactivate(ctx context.Context, id int, pushToken string) error
+}
+
+// PushProvider sends notifications to every active device of a user,
+// picking the sender by the device's push provider
+type PushProvider struct {
+	devices DeviceStore
+	senders map[string]PushSender
+}
+
+// NewPushProvider creates a push channel provider
+func NewPushProvider(devices DeviceStore, senders ...PushSender) *PushProvider {
+	m := make(map[string]PushSender, len(senders))
+	for _, s := range senders {
+		m[s.Provider()] = s
+	}
+	return &PushProvider{devices: devices, senders: m}
+}
+
+// Channel implements Provider
+func (p *PushProvider) Channel() string { return domain.NotificationChannelPush }
+
+// Name implements Provider
+func (p *PushProvider) Name() string { return "push" }
+
+// Send implements Provider. It succeeds when at least one device received
+// the message; ErrNoAddress when the user has no device we can send to.
+// Devices with invalid tokens are deactivated along the way.
+func (p *PushProvider) Send(ctx context.Context, r Recipient, msg Message) error {
+	devices, err := p.devices.ActivePushDevices(ctx, r.UserID)
+	if err != nil {
+		return err
+	}
+
+	payload := PushMessage{
+		Title: msg.Title,
+		Body:  msg.Content,
+		Data: map[string]string{
+			"notification_id": strconv.Itoa(msg.NotificationID),
+			"group_id":        strconv.Itoa(msg.GroupID),
+			"type":            msg.Type,
+		},
+	}
+
+	var (
+		attempted, delivered int
+		errs                 []error
+	)
+	for _, d := range devices {
+		sender, ok := p.senders[d.PushProvider]
+		if !ok {
+			continue
+		}
+		attempted++
+		err := sender.Send(ctx, d.PushToken, payload)
+		switch {
+		case err == nil:
+			delivered++
+		case errors.Is(err, ErrInvalidToken):
+			if derr := p.devices.Deactivate(ctx, d.Id, d.PushToken); derr != nil {
+				errs = append(errs, derr)
+			}
+			errs = append(errs, fmt.Errorf("device %d: %w", d.Id, err))
+		default:
+			errs = append(errs, fmt.Errorf("device %d: %w", d.Id, err))
+		}
+	}
+
+	if attempted == 0 {
+		return ErrNoAddress
+	}
+	if delivered > 0 {
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+// FakePushSender records messages in memory instead of sending them.
+// Tokens listed in Invalid are rejected with ErrInvalidToken.
+// Used in tests and for local development (NOTIFICATION_PUSH_FAKE).
+type FakePushSender struct {
+	provider string
+
+	mu      sync.Mutex
+	Invalid map[string]bool
+	sent    []FakePush
+}
+
+// FakePush is one recorded push
+type FakePush struct {
+	Token   string
+	Message PushMessage
+}
+
+// NewFakePushSender creates a fake sender for provider (domain.PushProvider*)
+func NewFakePushSender(provider string) *FakePushSender {
+	return &FakePushSender{provider: provider, Invalid: map[string]bool{}}
+}
+
+// Provider implements PushSender
+func (f *FakePushSender) Provider() string { return f.provider }
+
+// Send implements PushSender
+func (f *FakePushSender) Send(_ context.Context, token string, msg PushMessage) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Invalid[token] {
+		return ErrInvalidToken
+	}
+	f.sent = append(f.sent, FakePush{Token: token, Message: msg})
+	return nil
+}
+
+// Sent returns the recorded pushes
+func (f *FakePushSender) Sent() []FakePush {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]FakePush(nil), f.sent...)
+}
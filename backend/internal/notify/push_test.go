@@ -0,0 +1,278 @@
+// Package notify provides pluggable notification channel providers
+//
+// File: push_test.go
+// Description: Unit tests for push provider and FCM/APNs senders
+package notify
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"templatev25/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memDevices is an in-memory DeviceStore
+type memDevices struct {
+	devices     []domain.UserDevice
+	deactivated []int
+}
+
+func (m *memDevices) ActivePushDevices(_ context.Context, userID int) ([]domain.UserDevice, error) {
+	var out []domain.UserDevice
+	for _, d := range m.devices {
+		if d.UserId == userID {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+func (m *memDevices) Deactivate(_ context.Context, id int, pushToken string) error {
+	for _, d := range m.devices {
+		if d.Id == id && d.PushToken == pushToken {
+			m.deactivated = append(m.deactivated, id)
+		}
+	}
+	return nil
+}
+
+// rotatingSender calls rotate before delegating, like a device re-registering mid-send
+type rotatingSender struct {
+	PushSender
+	rotate func()
+}
+
+func (r rotatingSender) Send(ctx context.Context, token string, msg PushMessage) error {
+	r.rotate()
+	return r.PushSender.Send(ctx, token, msg)
+}
+
+func TestPushProvider_Send(t *testing.T) {
+	store := &memDevices{devices: []domain.UserDevice{
+		{Id: 1, UserId: 7, PushToken: "android", PushProvider: domain.PushProviderFCM},
+		{Id: 2, UserId: 7, PushToken: "stale", PushProvider: domain.PushProviderFCM},
+		{Id: 3, UserId: 7, PushToken: "iphone", PushProvider: domain.PushProviderAPNs},
+		{Id: 4, UserId: 7, PushToken: "web", PushProvider: "webpush"}, // sender-гүй
+	}}
+	fcm := NewFakePushSender(domain.PushProviderFCM)
+	fcm.Invalid["stale"] = true
+	apns := NewFakePushSender(domain.PushProviderAPNs)
+
+	p := NewPushProvider(store, fcm, apns)
+	err := p.Send(context.Background(), Recipient{UserID: 7}, Message{NotificationID: 5, GroupID: 2, Type: "dm", Title: "Hi", Content: "Body"})
+
+	require.NoError(t, err)
+	require.Len(t, fcm.Sent(), 1)
+	assert.Equal(t, "android", fcm.Sent()[0].Token)
+	assert.Equal(t, PushMessage{Title: "Hi", Body: "Body", Data: map[string]string{"notification_id": "5", "group_id": "2", "type": "dm"}}, fcm.Sent()[0].Message)
+	assert.Len(t, apns.Sent(), 1)
+	assert.Equal(t, []int{2}, store.deactivated)
+}
+
+func TestPushProvider_DeactivatesOnlyRejectedToken(t *testing.T) {
+	store := &memDevices{devices: []domain.UserDevice{
+		{Id: 1, UserId: 7, PushToken: "stale", PushProvider: domain.PushProviderFCM},
+	}}
+	fcm := NewFakePushSender(domain.PushProviderFCM)
+	fcm.Invalid["stale"] = true
+
+	// The device registers a new token while the send is in flight
+	p := NewPushProvider(store, rotatingSender{PushSender: fcm, rotate: func() { store.devices[0].PushToken = "fresh" }})
+	err := p.Send(context.Background(), Recipient{UserID: 7}, Message{})
+
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	assert.Empty(t, store.deactivated)
+}
+
+func TestPushProvider_Failures(t *testing.T) {
+	t.Run("no devices", func(t *testing.T) {
+		p := NewPushProvider(&memDevices{}, NewFakePushSender(domain.PushProviderFCM))
+		assert.ErrorIs(t, p.Send(context.Background(), Recipient{UserID: 7}, Message{}), ErrNoAddress)
+	})
+
+	t.Run("all tokens invalid", func(t *testing.T) {
+		store := &memDevices{devices: []domain.UserDevice{{Id: 1, UserId: 7, PushToken: "stale", PushProvider: domain.PushProviderFCM}}}
+		fcm := NewFakePushSender(domain.PushProviderFCM)
+		fcm.Invalid["stale"] = true
+
+		err := NewPushProvider(store, fcm).Send(context.Background(), Recipient{UserID: 7}, Message{})
+
+		assert.ErrorIs(t, err, ErrInvalidToken)
+		assert.Equal(t, []int{1}, store.deactivated)
+	})
+}
+
+// fcmTestKey returns a PKCS#8 PEM RSA key for the JWT flow
+func fcmTestKey(t *testing.T) []byte {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func TestFCMSender(t *testing.T) {
+	var tokenCalls int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenCalls++
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.Form.Get("grant_type"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token": "at-1", "token_type": "Bearer", "expires_in": 3600}`))
+	})
+	mux.HandleFunc("/v1/projects/demo/messages:send", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer at-1", r.Header.Get("Authorization"))
+		var body struct {
+			Message struct {
+				Token        string            `json:"token"`
+				Notification map[string]string `json:"notification"`
+				Data         map[string]string `json:"data"`
+			} `json:"message"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		switch body.Message.Token {
+		case "good":
+			assert.Equal(t, "Hi", body.Message.Notification["title"])
+			assert.Equal(t, "5", body.Message.Data["notification_id"])
+			_, _ = w.Write([]byte(`{"name": "projects/demo/messages/1"}`))
+		case "gone":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": {"code": 404, "status": "NOT_FOUND", "message": "Requested entity was not found.", "details": [{"errorCode": "UNREGISTERED"}]}}`))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error": {"code": 503, "status": "UNAVAILABLE", "message": "try later"}}`))
+		}
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	s := NewFCMSender(FCMConfig{
+		ProjectID:   "demo",
+		ClientEmail: "push@demo.iam.gserviceaccount.com",
+		PrivateKey:  fcmTestKey(t),
+		TokenURL:    srv.URL + "/token",
+		Endpoint:    srv.URL,
+		Timeout:     time.Second,
+	})
+	ctx := context.Background()
+	msg := PushMessage{Title: "Hi", Body: "x", Data: map[string]string{"notification_id": "5"}}
+
+	assert.NoError(t, s.Send(ctx, "good", msg))
+	assert.ErrorIs(t, s.Send(ctx, "gone", msg), ErrInvalidToken)
+	err := s.Send(ctx, "busy", msg)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidToken)
+	assert.Contains(t, err.Error(), "try later")
+	assert.Equal(t, 1, tokenCalls, "access token is cached")
+}
+
+func TestLoadFCMCredentials(t *testing.T) {
+	path := t.TempDir() + "/sa.json"
+	require.NoError(t, os.WriteFile(path, []byte(`{"type": "service_account", "project_id": "demo", "client_email": "a@b", "private_key": "pem", "token_uri": "https://t"}`), 0o600))
+
+	cfg, err := LoadFCMCredentials(path)
+	require.NoError(t, err)
+	assert.Equal(t, "demo", cfg.ProjectID)
+	assert.Equal(t, "https://t", cfg.TokenURL)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"project_id": "demo"}`), 0o600))
+	_, err = LoadFCMCredentials(path)
+	assert.Error(t, err)
+}
+
+func TestAPNsSender(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "mn.gerege.app", r.Header.Get("apns-topic"))
+		assert.Equal(t, "alert", r.Header.Get("apns-push-type"))
+		assertAPNsToken(t, &key.PublicKey, strings.TrimPrefix(r.Header.Get("Authorization"), "bearer "))
+
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		switch strings.TrimPrefix(r.URL.Path, "/3/device/") {
+		case "good":
+			alert := body["aps"].(map[string]any)["alert"].(map[string]any)
+			assert.Equal(t, "Hi", alert["title"])
+			assert.Equal(t, "2", body["group_id"])
+		case "gone":
+			w.WriteHeader(http.StatusGone)
+			_, _ = w.Write([]byte(`{"reason": "Unregistered"}`))
+		case "bad":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"reason": "BadDeviceToken"}`))
+		default:
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"reason": "TooManyRequests"}`))
+		}
+	}))
+	defer srv.Close()
+
+	s, err := NewAPNsSender(APNsConfig{
+		KeyID:      "KEY123",
+		TeamID:     "TEAM456",
+		Topic:      "mn.gerege.app",
+		PrivateKey: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+		Endpoint:   srv.URL,
+		Timeout:    time.Second,
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+	msg := PushMessage{Title: "Hi", Data: map[string]string{"group_id": "2"}}
+
+	assert.NoError(t, s.Send(ctx, "good", msg))
+	assert.ErrorIs(t, s.Send(ctx, "gone", msg), ErrInvalidToken)
+	assert.ErrorIs(t, s.Send(ctx, "bad", msg), ErrInvalidToken)
+	err = s.Send(ctx, "busy", msg)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidToken)
+
+	_, err = NewAPNsSender(APNsConfig{PrivateKey: []byte("not pem")})
+	assert.Error(t, err)
+}
+
+// assertAPNsToken verifies the ES256 provider token
+func assertAPNsToken(t *testing.T, pub *ecdsa.PublicKey, token string) {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"alg": "ES256", "kid": "KEY123"}`, string(header))
+
+	claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	assert.Contains(t, string(claims), `"iss":"TEAM456"`)
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	require.Len(t, sig, 64)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	assert.True(t, ecdsa.Verify(pub, digest[:], r, s), "signature")
+}
@@ -0,0 +1,86 @@
+// Package notify provides pluggable notification channel providers
+//
+// File: sms.go
+// Description: SMS channel over a JSON HTTP gateway
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"templatev25/internal/domain"
+)
+
+// smsMaxLength bounds the text sent to the gateway (multi-part SMS)
+const smsMaxLength = 480
+
+// HTTPSMSProvider posts {"to": "...", "text": "..."} to an SMS gateway.
+// A non-2xx response is a delivery failure.
+type HTTPSMSProvider struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewHTTPSMSProvider creates an SMS provider for the gateway at url.
+// token is sent as a Bearer Authorization header when not empty.
+func NewHTTPSMSProvider(url, token string, timeout time.Duration) *HTTPSMSProvider {
+	return &HTTPSMSProvider{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Channel implements Provider
+func (p *HTTPSMSProvider) Channel() string { return domain.NotificationChannelSMS }
+
+// Name implements Provider
+func (p *HTTPSMSProvider) Name() string { return "http-sms" }
+
+// Send implements Provider
+func (p *HTTPSMSProvider) Send(ctx context.Context, r Recipient, msg Message) error {
+	to := strings.TrimSpace(r.Phone)
+	if to == "" {
+		return ErrNoAddress
+	}
+
+	body, err := json.Marshal(map[string]string{"to": to, "text": smsText(msg)})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("notify: sms gateway returned %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// smsText joins title and content and truncates to smsMaxLength runes
+func smsText(msg Message) string {
+	text := strings.TrimSpace(msg.Title + "\n" + msg.Content)
+	if runes := []rune(text); len(runes) > smsMaxLength {
+		text = string(runes[:smsMaxLength-1]) + "…"
+	}
+	return text
+}
@@ -0,0 +1,188 @@
+// Package pubsub provides a Redis pub/sub channel shared by all instances
+//
+// File: pubsub.go
+// Description: Instance-to-instance messaging over Redis pub/sub
+//
+// This package provides:
+//   - Channel: publishes messages to every instance and delivers messages
+//     from other instances to a handler
+//   - Source tagging so an instance skips its own messages (publishers apply
+//     the change locally before publishing)
+//   - Resubscribe notification so subscribers can recover messages lost
+//     while the connection to Redis was down
+//
+// Usage:
+//
+//	ch := pubsub.New(redisClient, "permission-cache:invalidate", log, pubsub.Handlers{
+//	    OnMessage:     func(data []byte) { ... },
+//	    OnResubscribe: func() { ... },
+//	})
+//	ch.Start()
+//	defer ch.Stop()
+//
+//	err := ch.Publish(msg)
+package pubsub
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	// publishTimeout bounds a single publish so a slow Redis does not hold
+	// up the caller; the caller has already applied the change locally.
+	publishTimeout = 2 * time.Second
+
+	// retryDelay is the wait after a receive error before trying again.
+	retryDelay = time.Second
+)
+
+// envelope is the wire format of a message on the channel.
+type envelope struct {
+	Source string          `json:"source"` // Publishing instance
+	Data   json.RawMessage `json:"data"`
+}
+
+// Handlers receive events from the subscriber goroutine.
+type Handlers struct {
+	// OnMessage receives the payload of a message published by another instance.
+	OnMessage func(data []byte)
+
+	// OnResubscribe runs after the subscription is re-established. Messages
+	// published while disconnected are lost, so subscribers resynchronize here.
+	OnResubscribe func()
+}
+
+// Channel is a Redis pub/sub channel shared by all instances.
+type Channel struct {
+	client   redis.UniversalClient
+	name     string
+	source   string
+	log      *zap.Logger
+	handlers Handlers
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// New returns a channel. name must be the same on every instance.
+func New(client redis.UniversalClient, name string, log *zap.Logger, handlers Handlers) *Channel {
+	return &Channel{
+		client:   client,
+		name:     name,
+		source:   newInstanceID(),
+		log:      log,
+		handlers: handlers,
+	}
+}
+
+// newInstanceID returns a random ID distinguishing this instance.
+func newInstanceID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Name returns the Redis channel name.
+func (c *Channel) Name() string {
+	return c.name
+}
+
+// Publish encodes v as JSON and sends it to the other instances.
+func (c *Channel) Publish(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(envelope{Source: c.source, Data: data})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+	return c.client.Publish(ctx, c.name, payload).Err()
+}
+
+// Start starts the goroutine receiving messages from other instances.
+func (c *Channel) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	go func() {
+		defer close(c.done)
+		c.run(ctx)
+	}()
+}
+
+// Stop stops the subscriber goroutine and waits for it to exit.
+func (c *Channel) Stop() {
+	c.once.Do(func() {
+		if c.cancel == nil {
+			return
+		}
+		c.cancel()
+		<-c.done
+	})
+}
+
+// run listens on the channel until ctx is cancelled.
+func (c *Channel) run(ctx context.Context) {
+	pubsub := c.client.Subscribe(ctx, c.name)
+	defer pubsub.Close()
+
+	subscribed := false
+	for {
+		msg, err := pubsub.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.log.Warn("pubsub_receive_failed", zap.String("channel", c.name), zap.Error(err))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryDelay):
+			}
+			continue
+		}
+
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			// go-redis resubscribes after a reconnect; messages may have been lost
+			if subscribed && m.Kind == "subscribe" {
+				c.log.Info("pubsub_resubscribed", zap.String("channel", c.name))
+				if c.handlers.OnResubscribe != nil {
+					c.handlers.OnResubscribe()
+				}
+			}
+			subscribed = true
+		case *redis.Message:
+			c.deliver(m.Payload)
+		}
+	}
+}
+
+// deliver passes a message from another instance to OnMessage.
+func (c *Channel) deliver(payload string) {
+	var env envelope
+	if err := json.Unmarshal([]byte(payload), &env); err != nil {
+		c.log.Warn("pubsub_decode_failed", zap.String("channel", c.name), zap.Error(err))
+		return
+	}
+	// The publisher applied its own message before publishing
+	if env.Source == c.source {
+		return
+	}
+	if c.handlers.OnMessage != nil {
+		c.handlers.OnMessage(env.Data)
+	}
+}
@@ -0,0 +1,74 @@
+// Package pubsub provides a Redis pub/sub channel shared by all instances
+//
+// File: pubsub_test.go
+// Description: Unit tests for the instance-to-instance channel
+package pubsub
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newUnreachableChannel returns a channel whose Redis connection always fails
+func newUnreachableChannel(handlers Handlers) *Channel {
+	client := redis.NewClient(&redis.Options{
+		Addr:        "127.0.0.1:1",
+		DialTimeout: 100 * time.Millisecond,
+		MaxRetries:  -1,
+	})
+	return New(client, "pubsub:test", zap.NewNop(), handlers)
+}
+
+func encodeEnvelope(t *testing.T, source string, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	b, err := json.Marshal(envelope{Source: source, Data: data})
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestChannel_Deliver(t *testing.T) {
+	var got []string
+	ch := newUnreachableChannel(Handlers{OnMessage: func(data []byte) { got = append(got, string(data)) }})
+
+	ch.deliver(encodeEnvelope(t, "other", map[string]int{"id": 1}))
+	ch.deliver(encodeEnvelope(t, ch.source, map[string]int{"id": 2}))
+	ch.deliver("{not json")
+
+	assert.Equal(t, []string{`{"id":1}`}, got, "own and malformed messages are skipped")
+}
+
+func TestChannel_PublishFails(t *testing.T) {
+	ch := newUnreachableChannel(Handlers{})
+	assert.Error(t, ch.Publish(map[string]int{"id": 1}))
+	assert.Error(t, ch.Publish(func() {}), "unencodable value")
+}
+
+func TestChannel_StartStop(t *testing.T) {
+	ch := newUnreachableChannel(Handlers{})
+	ch.Start()
+
+	stopped := make(chan struct{})
+	go func() {
+		ch.Stop()
+		ch.Stop() // idempotent
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop did not return")
+	}
+}
+
+func TestChannel_StopWithoutStart(t *testing.T) {
+	newUnreachableChannel(Handlers{}).Stop()
+}
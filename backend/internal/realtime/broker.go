@@ -0,0 +1,122 @@
+// Package realtime provides implementation for realtime
+//
+// File: broker.go
+// Description: Multi-instance event fan-out over Redis pub/sub
+package realtime
+
+import (
+	"encoding/json"
+
+	"templatev25/internal/pubsub"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// brokerMessage нь Redis channel-аар дамжих event.
+type brokerMessage struct {
+	All     bool            `json:"all,omitempty"`      // Бүх холбогдсон хэрэглэгч рүү
+	UserIDs []int           `json:"user_ids,omitempty"` // Хүлээн авагчид
+	Event   json.RawMessage `json:"event"`
+}
+
+// RedisBroker нь event-ийг local Hub-д шууд хүргэж, Redis pub/sub-аар
+// бусад instance-уудын Hub руу түгээнэ.
+//
+// Хэрэглэгч аль instance-д холбогдсоныг мэдэх шаардлагагүй: instance бүр
+// мессежийг хүлээн авч зөвхөн өөрт байгаа холболтууд руу илгээнэ.
+// Redis тасарсан үед event зөвхөн local холболтуудад хүрнэ; дахин
+// холбогдоход local холболтуудыг салгаж client-уудыг REST-ээр нөхүүлнэ.
+type RedisBroker struct {
+	hub     *Hub
+	channel *pubsub.Channel
+	log     *zap.Logger
+}
+
+// NewRedisBroker нь hub-ийг Redis channel-тай холбосон broker үүсгэнэ.
+// channel нь бүх instance-д ижил байх ёстой.
+func NewRedisBroker(hub *Hub, client redis.UniversalClient, channel string, log *zap.Logger) *RedisBroker {
+	b := &RedisBroker{hub: hub, log: log}
+	b.channel = pubsub.New(client, channel, log, pubsub.Handlers{
+		OnMessage:     b.apply,
+		OnResubscribe: b.resync,
+	})
+	return b
+}
+
+// Publish нь event-ийг userIDs-ийн бүх instance дээрх холболтууд руу илгээнэ.
+func (b *RedisBroker) Publish(userIDs []int, ev Event) {
+	if len(userIDs) == 0 {
+		return
+	}
+	b.hub.Publish(userIDs, ev)
+	b.publish(brokerMessage{UserIDs: userIDs}, ev)
+}
+
+// PublishAll нь event-ийг бүх instance-ийн бүх холболт руу илгээнэ.
+func (b *RedisBroker) PublishAll(ev Event) {
+	b.hub.PublishAll(ev)
+	b.publish(brokerMessage{All: true}, ev)
+}
+
+// publish нь мессежийг бусад instance руу илгээнэ.
+func (b *RedisBroker) publish(msg brokerMessage, ev Event) {
+	raw, err := json.Marshal(ev)
+	if err != nil {
+		b.log.Error("realtime_broker_encode_failed", zap.String("type", ev.Type), zap.Error(err))
+		return
+	}
+	msg.Event = raw
+	if err := b.channel.Publish(msg); err != nil {
+		b.log.Warn("realtime_broker_publish_failed",
+			zap.String("channel", b.channel.Name()),
+			zap.String("type", ev.Type),
+			zap.Error(err),
+		)
+	}
+}
+
+// Start нь бусад instance-уудын event-ийг хүлээн авах goroutine эхлүүлнэ.
+func (b *RedisBroker) Start() {
+	b.channel.Start()
+}
+
+// Stop нь subscribe goroutine-ийг зогсоож дуусахыг хүлээнэ.
+func (b *RedisBroker) Stop() {
+	b.channel.Stop()
+}
+
+// resync нь Redis тасарсан хооронд алдсан event-тэй байж болох local
+// холболтуудыг салгана. Client дахин холбогдож REST-ээр нөхөж авна.
+func (b *RedisBroker) resync() {
+	b.log.Info("realtime_broker_resync", zap.Int("connections", b.hub.Connections()))
+	b.hub.DisconnectAll()
+}
+
+// apply нь бусад instance-аас ирсэн event-ийг local hub-д хүргэнэ.
+func (b *RedisBroker) apply(data []byte) {
+	var msg brokerMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		b.log.Warn("realtime_broker_decode_failed", zap.Error(err))
+		return
+	}
+
+	var ev struct {
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data,omitempty"`
+	}
+	if err := json.Unmarshal(msg.Event, &ev); err != nil {
+		b.log.Warn("realtime_broker_decode_failed", zap.Error(err))
+		return
+	}
+	out := Event{Type: ev.Type}
+	if len(ev.Data) > 0 {
+		out.Data = ev.Data
+	}
+
+	if msg.All {
+		b.hub.PublishAll(out)
+		return
+	}
+	b.hub.Publish(msg.UserIDs, out)
+}
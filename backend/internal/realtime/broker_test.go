@@ -0,0 +1,112 @@
+// Package realtime provides implementation for realtime
+//
+// File: broker_test.go
+// Description: Unit tests for Redis event fan-out
+package realtime
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newUnreachableBroker returns a broker whose Redis connection always fails
+func newUnreachableBroker(hub *Hub) *RedisBroker {
+	client := redis.NewClient(&redis.Options{
+		Addr:        "127.0.0.1:1",
+		DialTimeout: 100 * time.Millisecond,
+		MaxRetries:  -1,
+	})
+	return NewRedisBroker(hub, client, "realtime:test", zap.NewNop())
+}
+
+func encodeBrokerMessage(t *testing.T, msg brokerMessage, ev Event) []byte {
+	t.Helper()
+	raw, err := json.Marshal(ev)
+	require.NoError(t, err)
+	msg.Event = raw
+	b, err := json.Marshal(msg)
+	require.NoError(t, err)
+	return b
+}
+
+func TestRedisBroker_LocalFirst(t *testing.T) {
+	hub := NewHub(zap.NewNop())
+	c := hub.Subscribe(1)
+	b := newUnreachableBroker(hub)
+
+	// Publish fails, local connections still receive the event
+	b.Publish([]int{1}, Event{Type: "chat.message.created"})
+	b.PublishAll(Event{Type: "notification.created"})
+
+	assert.Equal(t, "chat.message.created", receive(t, c).Type)
+	assert.Equal(t, "notification.created", receive(t, c).Type)
+}
+
+func TestRedisBroker_Apply(t *testing.T) {
+	hub := NewHub(zap.NewNop())
+	one := hub.Subscribe(1)
+	two := hub.Subscribe(2)
+	b := newUnreachableBroker(hub)
+
+	b.apply(encodeBrokerMessage(t, brokerMessage{UserIDs: []int{1}},
+		Event{Type: "chat.message.created", Data: map[string]int{"id": 7}}))
+
+	ev := receive(t, one)
+	assert.Equal(t, "chat.message.created", ev.Type)
+	assert.Equal(t, map[string]any{"id": float64(7)}, ev.Data)
+	assert.Empty(t, two.Messages())
+
+	b.apply(encodeBrokerMessage(t, brokerMessage{All: true}, Event{Type: "notification.created"}))
+	assert.Equal(t, "notification.created", receive(t, one).Type)
+	assert.Equal(t, "notification.created", receive(t, two).Type)
+}
+
+func TestRedisBroker_IgnoresInvalidMessages(t *testing.T) {
+	hub := NewHub(zap.NewNop())
+	c := hub.Subscribe(1)
+	b := newUnreachableBroker(hub)
+
+	b.apply([]byte("not json"))
+	b.apply([]byte(`{"all":true,"event":"not an event"}`))
+
+	assert.Empty(t, c.Messages())
+}
+
+func TestRedisBroker_ResyncDisconnectsLocalClients(t *testing.T) {
+	hub := NewHub(zap.NewNop())
+	c := hub.Subscribe(1)
+	b := newUnreachableBroker(hub)
+
+	b.resync()
+
+	select {
+	case <-c.Done():
+	default:
+		t.Fatal("client was not disconnected")
+	}
+	assert.Zero(t, hub.Connections())
+}
+
+func TestRedisBroker_StartStop(t *testing.T) {
+	b := newUnreachableBroker(NewHub(zap.NewNop()))
+	b.Start()
+
+	stopped := make(chan struct{})
+	go func() {
+		b.Stop()
+		b.Stop() // idempotent
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop did not return")
+	}
+}
@@ -0,0 +1,231 @@
+// Package realtime provides implementation for realtime
+//
+// File: hub.go
+// Description: In-process hub delivering events to connected users
+/*
+Package realtime нь нэвтэрсэн хэрэглэгчийн идэвхтэй холболтууд (WebSocket)
+руу event түгээнэ.
+
+Hub нь user_id → холболтуудын map хадгална. Нэг хэрэглэгч олон төхөөрөмж,
+tab-аас зэрэг холбогдож болно; Publish бүгд рүү нь илгээнэ. Холболт бүр
+event-ийн төрлийн prefix-ээр ("chat.", "notification.") шүүгдэж болно.
+
+Hub нь зөвхөн энэ instance-ийн холболтуудыг мэднэ. Олон instance-тай үед
+RedisBroker-оор дамжуулж publish хийнэ (broker.go).
+
+Удаан client: илгээх buffer дүүрвэл тухайн холболтыг салгана (Done хаагдана),
+бусад хэрэглэгчид хүлээлгэхгүй. Client дахин холбогдож REST-ээр алдсан
+event-үүдээ нөхөж авна.
+*/
+package realtime
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// clientBuffer нь нэг холболтын илгээх дарааллын хэмжээ.
+const clientBuffer = 64
+
+// Event нь client руу илгээх мессеж.
+type Event struct {
+	Type string `json:"type"`
+	Data any    `json:"data,omitempty"`
+}
+
+// Client нь хэрэглэгчийн нэг холболт.
+type Client struct {
+	UserID int
+
+	prefixes []string // Хоосон бол бүх event
+	send     chan []byte
+	done     chan struct{}
+	once     sync.Once
+}
+
+// accepts нь event-ийн төрөл энэ холболтын шүүлтүүрт таарах эсэх.
+func (c *Client) accepts(typ string) bool {
+	if len(c.prefixes) == 0 {
+		return true
+	}
+	for _, p := range c.prefixes {
+		if strings.HasPrefix(typ, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Messages нь илгээх event-үүдийн (JSON) channel.
+func (c *Client) Messages() <-chan []byte {
+	return c.send
+}
+
+// Done нь hub холболтыг салгахад (удаан client) хаагдана.
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *Client) close() {
+	c.once.Do(func() { close(c.done) })
+}
+
+// Hub нь хэрэглэгч бүрийн холболтуудыг удирдана.
+type Hub struct {
+	mu      sync.RWMutex
+	clients map[int]map[*Client]struct{}
+	log     *zap.Logger
+}
+
+func NewHub(log *zap.Logger) *Hub {
+	return &Hub{clients: map[int]map[*Client]struct{}{}, log: log}
+}
+
+// Subscribe нь хэрэглэгчийн шинэ холболтыг бүртгэнэ.
+// prefixes өгвөл зөвхөн тэдгээрээр эхэлсэн төрлийн event хүлээн авна.
+func (h *Hub) Subscribe(userID int, prefixes ...string) *Client {
+	c := &Client{
+		UserID:   userID,
+		prefixes: prefixes,
+		send:     make(chan []byte, clientBuffer),
+		done:     make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.clients[userID] == nil {
+		h.clients[userID] = map[*Client]struct{}{}
+	}
+	h.clients[userID][c] = struct{}{}
+	return c
+}
+
+// Unsubscribe нь холболтыг hub-аас хасна. Дахин дуудахад аюулгүй.
+func (h *Hub) Unsubscribe(c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(c)
+}
+
+func (h *Hub) remove(c *Client) {
+	conns := h.clients[c.UserID]
+	if _, ok := conns[c]; !ok {
+		return
+	}
+	delete(conns, c)
+	if len(conns) == 0 {
+		delete(h.clients, c.UserID)
+	}
+	c.close()
+}
+
+// Publish нь event-ийг userIDs-ийн бүх холболт руу илгээнэ.
+// Блоклохгүй: buffer дүүрсэн холболтыг салгана.
+func (h *Hub) Publish(userIDs []int, ev Event) {
+	payload, ok := h.encode(ev)
+	if !ok {
+		return
+	}
+
+	var slow []*Client
+	h.mu.RLock()
+	for _, id := range userIDs {
+		for c := range h.clients[id] {
+			slow = deliver(c, ev.Type, payload, slow)
+		}
+	}
+	h.mu.RUnlock()
+	h.drop(slow)
+}
+
+// PublishAll нь event-ийг энэ instance-ийн бүх холболт руу илгээнэ.
+func (h *Hub) PublishAll(ev Event) {
+	payload, ok := h.encode(ev)
+	if !ok {
+		return
+	}
+
+	var slow []*Client
+	h.mu.RLock()
+	for _, conns := range h.clients {
+		for c := range conns {
+			slow = deliver(c, ev.Type, payload, slow)
+		}
+	}
+	h.mu.RUnlock()
+	h.drop(slow)
+}
+
+// Close нь бүх холболтыг салгана (shutdown). Stream-үүд Done-оор дуусна.
+func (h *Hub) Close() {
+	h.DisconnectAll()
+}
+
+// DisconnectAll нь бүх холболтыг салгана. Client-ууд дахин холбогдож
+// алдсан event-үүдээ REST-ээр нөхнө.
+func (h *Hub) DisconnectAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, conns := range h.clients {
+		for c := range conns {
+			c.close()
+		}
+	}
+	h.clients = map[int]map[*Client]struct{}{}
+}
+
+func (h *Hub) encode(ev Event) ([]byte, bool) {
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		h.log.Error("realtime_marshal_failed", zap.String("type", ev.Type), zap.Error(err))
+		return nil, false
+	}
+	return payload, true
+}
+
+// deliver нь payload-ийг client руу блоклохгүйгээр илгээнэ;
+// buffer дүүрсэн бол slow-д нэмнэ.
+func deliver(c *Client, typ string, payload []byte, slow []*Client) []*Client {
+	if !c.accepts(typ) {
+		return slow
+	}
+	select {
+	case c.send <- payload:
+	default:
+		slow = append(slow, c)
+	}
+	return slow
+}
+
+func (h *Hub) drop(slow []*Client) {
+	if len(slow) == 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, c := range slow {
+		h.log.Warn("realtime_client_dropped", zap.Int("user_id", c.UserID))
+		h.remove(c)
+	}
+}
+
+// Online нь хэрэглэгч идэвхтэй холболттой эсэхийг буцаана.
+func (h *Hub) Online(userID int) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients[userID]) > 0
+}
+
+// Connections нь нийт идэвхтэй холболтын тоо.
+func (h *Hub) Connections() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	n := 0
+	for _, conns := range h.clients {
+		n += len(conns)
+	}
+	return n
+}
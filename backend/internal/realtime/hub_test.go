@@ -0,0 +1,131 @@
+// Package realtime provides implementation for realtime
+//
+// File: hub_test.go
+// Description: Unit tests for the realtime hub
+package realtime
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func receive(t *testing.T, c *Client) Event {
+	t.Helper()
+	select {
+	case raw := <-c.Messages():
+		var ev Event
+		require.NoError(t, json.Unmarshal(raw, &ev))
+		return ev
+	default:
+		t.Fatal("no message")
+		return Event{}
+	}
+}
+
+func TestHub_PublishToAllConnectionsOfUser(t *testing.T) {
+	h := NewHub(zap.NewNop())
+	phone := h.Subscribe(1)
+	laptop := h.Subscribe(1)
+	other := h.Subscribe(2)
+
+	h.Publish([]int{1, 3}, Event{Type: "chat.message.created", Data: map[string]int{"id": 7}})
+
+	assert.Equal(t, "chat.message.created", receive(t, phone).Type)
+	assert.Equal(t, map[string]any{"id": float64(7)}, receive(t, laptop).Data)
+	assert.Empty(t, other.Messages())
+	assert.True(t, h.Online(1))
+	assert.False(t, h.Online(3))
+	assert.Equal(t, 3, h.Connections())
+}
+
+func TestHub_Unsubscribe(t *testing.T) {
+	h := NewHub(zap.NewNop())
+	c := h.Subscribe(1)
+
+	h.Unsubscribe(c)
+	h.Unsubscribe(c) // idempotent
+
+	h.Publish([]int{1}, Event{Type: "x"})
+	assert.Empty(t, c.Messages())
+	assert.False(t, h.Online(1))
+	assert.Equal(t, 0, h.Connections())
+
+	select {
+	case <-c.Done():
+	default:
+		t.Fatal("Done must be closed after unsubscribe")
+	}
+}
+
+func TestHub_DropsSlowClient(t *testing.T) {
+	h := NewHub(zap.NewNop())
+	slow := h.Subscribe(1)
+	fast := h.Subscribe(2)
+
+	for i := 0; i < clientBuffer+1; i++ {
+		h.Publish([]int{1}, Event{Type: "x"})
+		if i < clientBuffer {
+			h.Publish([]int{2}, Event{Type: "x"})
+			<-fast.Messages()
+		}
+	}
+
+	select {
+	case <-slow.Done():
+	default:
+		t.Fatal("slow client must be dropped")
+	}
+	assert.False(t, h.Online(1))
+	assert.True(t, h.Online(2))
+}
+
+func TestHub_PrefixFilter(t *testing.T) {
+	h := NewHub(zap.NewNop())
+	chat := h.Subscribe(1, "chat.")
+	notif := h.Subscribe(1, "notification.")
+	all := h.Subscribe(1)
+
+	h.Publish([]int{1}, Event{Type: "chat.message.created"})
+	h.Publish([]int{1}, Event{Type: "notification.created"})
+
+	assert.Equal(t, "chat.message.created", receive(t, chat).Type)
+	assert.Empty(t, chat.Messages())
+	assert.Equal(t, "notification.created", receive(t, notif).Type)
+	assert.Empty(t, notif.Messages())
+	assert.Len(t, all.Messages(), 2)
+}
+
+func TestHub_PublishAll(t *testing.T) {
+	h := NewHub(zap.NewNop())
+	a := h.Subscribe(1)
+	b := h.Subscribe(2, "notification.")
+	c := h.Subscribe(3, "chat.")
+
+	h.PublishAll(Event{Type: "notification.created"})
+
+	assert.Equal(t, "notification.created", receive(t, a).Type)
+	assert.Equal(t, "notification.created", receive(t, b).Type)
+	assert.Empty(t, c.Messages())
+}
+
+func TestHub_Close(t *testing.T) {
+	h := NewHub(zap.NewNop())
+	a := h.Subscribe(1)
+	b := h.Subscribe(2)
+
+	h.Close()
+
+	for _, c := range []*Client{a, b} {
+		select {
+		case <-c.Done():
+		default:
+			t.Fatal("Done must be closed after Close")
+		}
+	}
+	assert.Equal(t, 0, h.Connections())
+	h.Unsubscribe(a) // safe after Close
+}
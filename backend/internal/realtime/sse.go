@@ -0,0 +1,76 @@
+// Package realtime provides implementation for realtime
+//
+// File: sse.go
+// Description: Server-Sent Events stream for hub clients
+package realtime
+
+import (
+	"bufio"
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// sseHeartbeat нь proxy холболтыг хаахаас сэргийлэх comment илгээх давтамж.
+const sseHeartbeat = 25 * time.Second
+
+// sessionRevoked нь session хүчингүй болж stream хаагдахын өмнөх сүүлийн event.
+// EventSource автоматаар дахин холбогддог тул client үүнийг хүлээж авч зогсооно.
+var sessionRevoked = []byte(`{"type":"session.revoked"}`)
+
+// ServeSSE нь хэрэглэгчийг hub-д бүртгэж event-үүдийг text/event-stream
+// хэлбэрээр бичнэ. WebSocket дэмждэггүй client, proxy-д зориулсан.
+//
+// Event бүр "data: {json}\n\n" хэлбэртэй. Client салсныг бичих алдаагаар
+// (heartbeat-ийн үеэр) мэдэж холболтыг hub-аас хасна. check false болбол
+// session.revoked event илгээж stream-ийг дуусгана.
+func (h *Hub) ServeSSE(c *fiber.Ctx, userID int, check SessionCheck, prefixes ...string) error {
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no") // nginx buffering унтраах
+
+	client := h.Subscribe(userID, prefixes...)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer h.Unsubscribe(client)
+
+		if _, err := w.WriteString(": connected\n\n"); err != nil {
+			return
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(sseHeartbeat)
+		defer ticker.Stop()
+		checks, stopChecks := checkTicker(check)
+		defer stopChecks()
+
+		for {
+			select {
+			case msg := <-client.Messages():
+				_, _ = w.WriteString("data: ")
+				_, _ = w.Write(msg)
+				_, _ = w.WriteString("\n\n")
+			case <-ticker.C:
+				_, _ = w.WriteString(": ping\n\n")
+			case <-checks:
+				if !check(context.Background()) {
+					_, _ = w.WriteString("data: ")
+					_, _ = w.Write(sessionRevoked)
+					_, _ = w.WriteString("\n\n")
+					_ = w.Flush()
+					return
+				}
+				continue
+			case <-client.Done():
+				return
+			}
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+	return nil
+}
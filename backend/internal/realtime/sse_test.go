@@ -0,0 +1,46 @@
+// Package realtime provides implementation for realtime
+//
+// File: sse_test.go
+// Description: Unit tests for the Server-Sent Events stream
+package realtime
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestServeSSE_EndsWhenSessionRevoked(t *testing.T) {
+	prev := sessionCheckPeriod
+	sessionCheckPeriod = 20 * time.Millisecond
+	defer func() { sessionCheckPeriod = prev }()
+
+	h := NewHub(zap.NewNop())
+	var checks atomic.Int32
+	check := func(context.Context) bool {
+		// The session is revoked after the first check
+		return checks.Add(1) < 2
+	}
+
+	app := fiber.New()
+	app.Get("/stream", func(c *fiber.Ctx) error {
+		return h.ServeSSE(c, 1, check, "notification.")
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/stream", nil), 2000)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(body), `data: {"type":"session.revoked"}`)
+	assert.Equal(t, int32(2), checks.Load())
+	assert.Equal(t, 0, h.Connections())
+}
@@ -0,0 +1,103 @@
+// Package realtime provides implementation for realtime
+//
+// File: websocket.go
+// Description: WebSocket connection pump for hub clients
+package realtime
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+)
+
+const (
+	// writeWait нь нэг frame бичих хугацааны хязгаар.
+	writeWait = 10 * time.Second
+	// pongWait нь client-ээс pong хүлээх хугацаа.
+	pongWait = 60 * time.Second
+	// pingPeriod нь ping илгээх давтамж (pongWait-ээс бага байх ёстой).
+	pingPeriod = pongWait * 9 / 10
+	// maxReadSize нь client-ээс ирэх frame-ийн дээд хэмжээ.
+	// Client → server мессеж нь зөвхөн ping/pong тул бага.
+	maxReadSize = 4096
+)
+
+// sessionCheckPeriod нь холболтын session-ийг шалгах давтамж.
+// Logout/revoke хийгдсэнээс хойш хамгийн ихдээ энэ хугацаанд холболт хаагдана.
+var sessionCheckPeriod = 30 * time.Second
+
+// SessionCheck нь холболтын session хүчинтэй хэвээр эсэхийг шалгана.
+// false бол холболт хаагдана. nil бол шалгахгүй.
+type SessionCheck func(ctx context.Context) bool
+
+// checkTicker нь SessionCheck тавигдсан үед шалгалтын ticker үүсгэнэ.
+// nil бол хэзээ ч ажиллахгүй channel буцаана.
+func checkTicker(check SessionCheck) (<-chan time.Time, func()) {
+	if check == nil {
+		return nil, func() {}
+	}
+	t := time.NewTicker(sessionCheckPeriod)
+	return t.C, t.Stop
+}
+
+// Serve нь WebSocket холболтыг hub-д бүртгэж, хаагдтал event-үүдийг бичнэ.
+// Client-ийн илгээсэн мессежийг уншиж хаясан (холболт амьд эсэхийг шалгахад).
+// check нь session-ийг үе үе шалгаж, хүчингүй болсон бол холболтыг хаана.
+// prefixes нь Subscribe-ийн адил event-ийн төрлийг шүүнэ.
+func (h *Hub) Serve(conn *websocket.Conn, userID int, check SessionCheck, prefixes ...string) {
+	client := h.Subscribe(userID, prefixes...)
+	defer h.Unsubscribe(client)
+
+	conn.SetReadLimit(maxReadSize)
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	// Reader: хаалт болон pong-ийг боловсруулна
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+	checks, stopChecks := checkTicker(check)
+	defer stopChecks()
+
+	for {
+		select {
+		case msg := <-client.Messages():
+			_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-checks:
+			if !check(context.Background()) {
+				_ = conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "session revoked"),
+					time.Now().Add(writeWait))
+				return
+			}
+		case <-client.Done():
+			// Удаан client эсвэл event алдагдсан: дахин холбогдохыг санал болгоно
+			_ = conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "reconnect"),
+				time.Now().Add(writeWait))
+			return
+		case <-closed:
+			return
+		}
+	}
+}
@@ -6,6 +6,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"templatev25/internal/domain"
@@ -40,6 +41,13 @@ type AuthRepository interface {
 	DeleteBackupCodes(ctx context.Context, userID int) error
 	UseBackupCode(ctx context.Context, codeID int) error
 
+	// MFA WebAuthn Credentials
+	GetWebAuthnCredentials(ctx context.Context, userID int) ([]domain.UserWebAuthnCredential, error)
+	GetWebAuthnCredentialByCredentialID(ctx context.Context, credentialID string) (*domain.UserWebAuthnCredential, error)
+	CreateWebAuthnCredential(ctx context.Context, cred *domain.UserWebAuthnCredential) error
+	UpdateWebAuthnCredentialUsage(ctx context.Context, id int, signCount int64) error
+	DeleteWebAuthnCredential(ctx context.Context, userID, id int) error
+
 	// Sessions (DB layer - Redis is primary)
 	CreateSession(ctx context.Context, session *domain.Session) error
 	GetSession(ctx context.Context, id string) (*domain.Session, error)
@@ -49,6 +57,15 @@ type AuthRepository interface {
 	RevokeSession(ctx context.Context, id string, reason string) error
 	RevokeAllUserSessions(ctx context.Context, userID int, reason string) error
 
+	// Refresh Tokens
+	CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, oldID int, next *domain.RefreshToken) (bool, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) ([]string, error)
+	RevokeSessionRefreshTokens(ctx context.Context, sessionID string) error
+	SetSessionRefreshTokenOrg(ctx context.Context, sessionID string, orgID int) error
+	RevokeUserRefreshTokens(ctx context.Context, userID int) error
+
 	// Login History
 	CreateLoginHistory(ctx context.Context, history *domain.LoginHistory) error
 	GetLoginHistory(ctx context.Context, userID int, limit int) ([]domain.LoginHistory, error)
@@ -67,8 +84,13 @@ type AuthRepository interface {
 	UpdateUserStatus(ctx context.Context, userID int, status string, reason string, changedBy int) error
 	UpdateUserLoginStats(ctx context.Context, userID int) error
 	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
+	GetDefaultOrgID(ctx context.Context, userID int) (int, error)
+	IsOrganizationMember(ctx context.Context, userID, orgID int) (bool, error)
 }
 
+// errRefreshTokenNotActive aborts a rotation whose old token is no longer active
+var errRefreshTokenNotActive = errors.New("refresh token is not active")
+
 type authRepository struct {
 	db *gorm.DB
 }
@@ -232,6 +254,56 @@ func (r *authRepository) UseBackupCode(ctx context.Context, codeID int) error {
 		Update("used_at", now).Error
 }
 
+// ============================================================
+// MFA WEBAUTHN CREDENTIALS
+// ============================================================
+
+func (r *authRepository) GetWebAuthnCredentials(ctx context.Context, userID int) ([]domain.UserWebAuthnCredential, error) {
+	var creds []domain.UserWebAuthnCredential
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("id").
+		Find(&creds).Error
+	return creds, err
+}
+
+func (r *authRepository) GetWebAuthnCredentialByCredentialID(ctx context.Context, credentialID string) (*domain.UserWebAuthnCredential, error) {
+	var cred domain.UserWebAuthnCredential
+	err := r.db.WithContext(ctx).Where("credential_id = ?", credentialID).First(&cred).Error
+	if err != nil {
+		return nil, err
+	}
+	return &cred, nil
+}
+
+func (r *authRepository) CreateWebAuthnCredential(ctx context.Context, cred *domain.UserWebAuthnCredential) error {
+	return r.db.WithContext(ctx).Create(cred).Error
+}
+
+func (r *authRepository) UpdateWebAuthnCredentialUsage(ctx context.Context, id int, signCount int64) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).
+		Model(&domain.UserWebAuthnCredential{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"sign_count":   signCount,
+			"last_used_at": now,
+		}).Error
+}
+
+func (r *authRepository) DeleteWebAuthnCredential(ctx context.Context, userID, id int) error {
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", id, userID).
+		Delete(&domain.UserWebAuthnCredential{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 // ============================================================
 // SESSIONS
 // ============================================================
@@ -296,6 +368,98 @@ func (r *authRepository) RevokeAllUserSessions(ctx context.Context, userID int,
 		}).Error
 }
 
+// ============================================================
+// REFRESH TOKENS
+// ============================================================
+
+func (r *authRepository) CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
+	return r.db.WithContext(ctx).Create(token).Error
+}
+
+func (r *authRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("token_hash = ?", tokenHash).
+		First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RotateRefreshToken creates next and marks the old token as replaced by it.
+// Returns false when the old token was already revoked or rotated (e.g. by a
+// concurrent request), in which case nothing is written.
+func (r *authRepository) RotateRefreshToken(ctx context.Context, oldID int, next *domain.RefreshToken) (bool, error) {
+	rotated := false
+	err := WithTx(ctx, r.db, func(tx *gorm.DB) error {
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		res := tx.Model(&domain.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", oldID).
+			Updates(map[string]interface{}{
+				"revoked_at":  time.Now(),
+				"replaced_by": next.ID,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			// Roll back the new token
+			return errRefreshTokenNotActive
+		}
+		rotated = true
+		return nil
+	})
+	if errors.Is(err, errRefreshTokenNotActive) {
+		return false, nil
+	}
+	return rotated, err
+}
+
+// RevokeRefreshTokenFamily revokes every active token of the family and
+// returns the session IDs the family was issued for.
+func (r *authRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) ([]string, error) {
+	var sessionIDs []string
+	err := WithTx(ctx, r.db, func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.RefreshToken{}).
+			Where("family_id = ?", familyID).
+			Distinct().
+			Pluck("session_id", &sessionIDs).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.RefreshToken{}).
+			Where("family_id = ? AND revoked_at IS NULL", familyID).
+			Update("revoked_at", time.Now()).Error
+	})
+	return sessionIDs, err
+}
+
+func (r *authRepository) RevokeSessionRefreshTokens(ctx context.Context, sessionID string) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.RefreshToken{}).
+		Where("session_id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// SetSessionRefreshTokenOrg records the organization selected in the session
+// on its active refresh token, so the next rotation keeps it.
+func (r *authRepository) SetSessionRefreshTokenOrg(ctx context.Context, sessionID string, orgID int) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.RefreshToken{}).
+		Where("session_id = ? AND revoked_at IS NULL", sessionID).
+		Update("organization_id", orgID).Error
+}
+
+func (r *authRepository) RevokeUserRefreshTokens(ctx context.Context, userID int) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
 // ============================================================
 // LOGIN HISTORY
 // ============================================================
@@ -407,3 +571,30 @@ func (r *authRepository) GetUserByEmail(ctx context.Context, email string) (*dom
 	}
 	return &user, nil
 }
+
+// GetDefaultOrgID returns the organization a local session starts in: the
+// user's earliest organization membership, or 0 when the user has none
+func (r *authRepository) GetDefaultOrgID(ctx context.Context, userID int) (int, error) {
+	var ids []int
+	err := r.db.WithContext(ctx).
+		Model(&domain.OrganizationUser{}).
+		Where("user_id = ?", userID).
+		Order("created_date ASC").
+		Limit(1).
+		Pluck("org_id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+// IsOrganizationMember reports whether the user belongs to the organization
+// (organization_users), i.e. may switch a local session to it
+func (r *authRepository) IsOrganizationMember(ctx context.Context, userID, orgID int) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&domain.OrganizationUser{}).
+		Where("user_id = ? AND org_id = ?", userID, orgID).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,367 @@
+// Package repository provides implementation for repository
+//
+// File: chat_room_repo.go
+// Description: Chat room, membership and message persistence
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/http/dto"
+
+	"git.gerege.mn/backend-packages/scopes"
+	"git.gerege.mn/backend-packages/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// chatLockNamespace нь direct өрөө давхар үүсэхээс сэргийлэх
+// pg_advisory_xact_lock(int, int)-ийн эхний key ("CHAT").
+const chatLockNamespace int32 = 0x43484154
+
+// unreadCountSQL нь m (гишүүнчлэл)-ийн last_read_at-аас хойшхи,
+// бусдын илгээсэн устгагдаагүй мессежийн тоо.
+const unreadCountSQL = `(SELECT COUNT(*) FROM chat_messages cm
+	WHERE cm.room_id = m.room_id
+	  AND NOT COALESCE(cm.is_deleted, FALSE)
+	  AND cm.sender_id IS DISTINCT FROM m.user_id
+	  AND (m.last_read_at IS NULL OR cm.created_date > m.last_read_at))`
+
+type ChatRoomRepository interface {
+	ListForUser(ctx context.Context, userID int, q dto.ChatRoomListQuery) ([]domain.ChatRoomSummary, int64, int, int, error)
+	RoomByID(ctx context.Context, id int) (domain.ChatRoom, error)
+	CreateRoom(ctx context.Context, room *domain.ChatRoom, members []domain.ChatRoomMember) error
+	// FindOrCreateDirect нь хоёр хэрэглэгчийн direct өрөөг буцаана,
+	// байхгүй бол room, members-ээр үүсгэнэ. created=true бол шинээр үүссэн.
+	FindOrCreateDirect(ctx context.Context, room *domain.ChatRoom, members []domain.ChatRoomMember) (created bool, err error)
+	UpdateRoom(ctx context.Context, id int, fields map[string]any) error
+	DeleteRoom(ctx context.Context, id int) error
+
+	Member(ctx context.Context, roomID, userID int) (domain.ChatRoomMember, error)
+	Members(ctx context.Context, roomID int) ([]domain.ChatRoomMember, error)
+	MemberUserIDs(ctx context.Context, roomID int) ([]int, error)
+	// AddMembers нь гишүүдийг нэмнэ; өмнө нь гарсан гишүүнийг сэргээнэ,
+	// идэвхтэй гишүүний эрх өөрчлөгдөхгүй.
+	AddMembers(ctx context.Context, members []domain.ChatRoomMember) error
+	RemoveMember(ctx context.Context, roomID, userID int, at time.Time) error
+	SetMemberRole(ctx context.Context, roomID, userID int, role string) error
+	MarkRead(ctx context.Context, roomID, userID int, at time.Time) error
+	UnreadTotal(ctx context.Context, userID int) (int64, error)
+
+	Messages(ctx context.Context, roomID int, q dto.ChatMessageListQuery) ([]domain.ChatMessage, int64, int, int, error)
+	MessageByID(ctx context.Context, id int) (domain.ChatMessage, error)
+	// CreateMessage нь мессежийг хадгалж өрөөний last_message_at болон
+	// илгээгчийн last_read_at-ийг нэг transaction-д шинэчилнэ.
+	CreateMessage(ctx context.Context, msg *domain.ChatMessage) error
+	EditMessage(ctx context.Context, id int, content string, at time.Time) error
+	SoftDeleteMessage(ctx context.Context, id int) error
+}
+
+type chatRoomRepository struct {
+	db *gorm.DB
+}
+
+func NewChatRoomRepository(db *gorm.DB) ChatRoomRepository {
+	return &chatRoomRepository{db: db}
+}
+
+func (r *chatRoomRepository) ListForUser(ctx context.Context, userID int, q dto.ChatRoomListQuery) ([]domain.ChatRoomSummary, int64, int, int, error) {
+	page, size, offset := utils.OffsetLimit(q.PaginationQuery)
+
+	colMap := scopes.ColumnMap{
+		"id":              "r.id",
+		"name":            "r.name",
+		"type":            "r.type",
+		"last_message_at": "r.last_message_at",
+		"created_date":    "r.created_date",
+	}
+
+	tx := r.db.WithContext(ctx).Table("chat_rooms AS r").
+		Joins("JOIN chat_room_members m ON m.room_id = r.id AND m.user_id = ? AND COALESCE(m.is_active, TRUE)", userID).
+		Where("r.deleted_date IS NULL AND COALESCE(r.is_active, TRUE)").
+		Scopes(scopes.SearchScope(colMap, utils.ParseSearch(q.Search)))
+	if q.Type != "" {
+		tx = tx.Where("r.type = ?", q.Type)
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, 0, 0, err
+	}
+
+	var items []domain.ChatRoomSummary
+	if err := tx.Select("r.*, m.role AS my_role, m.last_read_at, " + unreadCountSQL + " AS unread_count").
+		Scopes(scopes.SortScope(colMap, utils.ParseSort(q.Sort), "r.last_message_at DESC NULLS LAST, r.id DESC")).
+		Offset(offset).Limit(size).Scan(&items).Error; err != nil {
+		return nil, 0, 0, 0, err
+	}
+	return items, total, page, size, nil
+}
+
+func (r *chatRoomRepository) RoomByID(ctx context.Context, id int) (domain.ChatRoom, error) {
+	var room domain.ChatRoom
+	err := r.db.WithContext(ctx).First(&room, id).Error
+	return room, err
+}
+
+func (r *chatRoomRepository) CreateRoom(ctx context.Context, room *domain.ChatRoom, members []domain.ChatRoomMember) error {
+	return WithTx(ctx, r.db, func(tx *gorm.DB) error {
+		return createRoomTx(tx, room, members)
+	})
+}
+
+// FindOrCreateDirect нь хэрэглэгчийн хосоор transaction-түвшний advisory lock
+// авч зэрэг ирсэн хүсэлтүүдээс давхар өрөө үүсэхээс сэргийлнэ.
+func (r *chatRoomRepository) FindOrCreateDirect(ctx context.Context, room *domain.ChatRoom, members []domain.ChatRoomMember) (bool, error) {
+	a, b := members[0].UserId, members[1].UserId
+	if a > b {
+		a, b = b, a
+	}
+
+	created := false
+	err := WithTx(ctx, r.db, func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?, hashtext(?))",
+			chatLockNamespace, directRoomKey(a, b)).Error; err != nil {
+			return err
+		}
+
+		var existing domain.ChatRoom
+		err := tx.Where("type = ?", domain.ChatRoomDirect).
+			Where("EXISTS (SELECT 1 FROM chat_room_members WHERE room_id = chat_rooms.id AND user_id = ?)", a).
+			Where("EXISTS (SELECT 1 FROM chat_room_members WHERE room_id = chat_rooms.id AND user_id = ?)", b).
+			Order("id ASC").
+			First(&existing).Error
+		if err == nil {
+			*room = existing
+			// Гарсан талыг буцааж нэмнэ
+			return tx.Model(&domain.ChatRoomMember{}).
+				Where("room_id = ? AND NOT COALESCE(is_active, TRUE)", existing.Id).
+				Updates(map[string]any{"is_active": true, "left_at": nil}).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		created = true
+		return createRoomTx(tx, room, members)
+	})
+	return created, err
+}
+
+func createRoomTx(tx *gorm.DB, room *domain.ChatRoom, members []domain.ChatRoomMember) error {
+	if err := tx.Create(room).Error; err != nil {
+		return err
+	}
+	for i := range members {
+		members[i].RoomId = room.Id
+	}
+	return tx.Create(&members).Error
+}
+
+func directRoomKey(a, b int) string {
+	return "direct:" + strconv.Itoa(a) + ":" + strconv.Itoa(b)
+}
+
+func (r *chatRoomRepository) UpdateRoom(ctx context.Context, id int, fields map[string]any) error {
+	res := r.db.WithContext(ctx).Model(&domain.ChatRoom{}).Where("id = ?", id).Updates(fields)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *chatRoomRepository) DeleteRoom(ctx context.Context, id int) error {
+	res := r.db.WithContext(ctx).Delete(&domain.ChatRoom{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *chatRoomRepository) Member(ctx context.Context, roomID, userID int) (domain.ChatRoomMember, error) {
+	var m domain.ChatRoomMember
+	err := r.db.WithContext(ctx).
+		Where("room_id = ? AND user_id = ? AND COALESCE(is_active, TRUE)", roomID, userID).
+		First(&m).Error
+	return m, err
+}
+
+func (r *chatRoomRepository) Members(ctx context.Context, roomID int) ([]domain.ChatRoomMember, error) {
+	var members []domain.ChatRoomMember
+	err := r.db.WithContext(ctx).
+		Where("room_id = ? AND COALESCE(is_active, TRUE)", roomID).
+		Order("joined_at ASC, id ASC").
+		Find(&members).Error
+	return members, err
+}
+
+func (r *chatRoomRepository) MemberUserIDs(ctx context.Context, roomID int) ([]int, error) {
+	var ids []int
+	err := r.db.WithContext(ctx).Model(&domain.ChatRoomMember{}).
+		Where("room_id = ? AND COALESCE(is_active, TRUE)", roomID).
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+func (r *chatRoomRepository) AddMembers(ctx context.Context, members []domain.ChatRoomMember) error {
+	if len(members) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "room_id"}, {Name: "user_id"}},
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "is_active"}, Value: true},
+			{Column: clause.Column{Name: "left_at"}, Value: nil},
+			{Column: clause.Column{Name: "role"}, Value: clause.Column{Table: "excluded", Name: "role"}},
+			{Column: clause.Column{Name: "joined_at"}, Value: clause.Expr{SQL: "NOW()"}},
+			{Column: clause.Column{Name: "last_read_at"}, Value: nil},
+		},
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "NOT COALESCE(chat_room_members.is_active, TRUE)"},
+		}},
+	}).Create(&members).Error
+}
+
+func (r *chatRoomRepository) RemoveMember(ctx context.Context, roomID, userID int, at time.Time) error {
+	res := r.db.WithContext(ctx).Model(&domain.ChatRoomMember{}).
+		Where("room_id = ? AND user_id = ? AND COALESCE(is_active, TRUE)", roomID, userID).
+		Updates(map[string]any{"is_active": false, "left_at": at})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *chatRoomRepository) SetMemberRole(ctx context.Context, roomID, userID int, role string) error {
+	res := r.db.WithContext(ctx).Model(&domain.ChatRoomMember{}).
+		Where("room_id = ? AND user_id = ? AND COALESCE(is_active, TRUE)", roomID, userID).
+		Update("role", role)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// MarkRead нь last_read_at-ийг at болгоно; хойшлуулахгүй (GREATEST).
+func (r *chatRoomRepository) MarkRead(ctx context.Context, roomID, userID int, at time.Time) error {
+	res := r.db.WithContext(ctx).Model(&domain.ChatRoomMember{}).
+		Where("room_id = ? AND user_id = ? AND COALESCE(is_active, TRUE)", roomID, userID).
+		Update("last_read_at", gorm.Expr("GREATEST(COALESCE(last_read_at, ?), ?)", at, at))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *chatRoomRepository) UnreadTotal(ctx context.Context, userID int) (int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Table("chat_room_members AS m").
+		Joins("JOIN chat_rooms r ON r.id = m.room_id AND r.deleted_date IS NULL AND COALESCE(r.is_active, TRUE)").
+		Where("m.user_id = ? AND COALESCE(m.is_active, TRUE)", userID).
+		Select("COALESCE(SUM(" + unreadCountSQL + "), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+func (r *chatRoomRepository) Messages(ctx context.Context, roomID int, q dto.ChatMessageListQuery) ([]domain.ChatMessage, int64, int, int, error) {
+	page, size, offset := utils.OffsetLimit(q.PaginationQuery)
+
+	colMap := scopes.ColumnMap{
+		"id":           "id",
+		"content":      "content",
+		"created_date": "created_date",
+	}
+
+	tx := r.db.WithContext(ctx).Model(&domain.ChatMessage{}).
+		Where("room_id = ?", roomID).
+		Scopes(scopes.SearchScope(colMap, utils.ParseSearch(q.Search)))
+	if q.ParentID > 0 {
+		tx = tx.Where("parent_id = ?", q.ParentID)
+	}
+	if q.BeforeID > 0 {
+		tx = tx.Where("id < ?", q.BeforeID)
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, 0, 0, err
+	}
+
+	var items []domain.ChatMessage
+	if err := tx.Scopes(
+		scopes.SortScope(colMap, utils.ParseSort(q.Sort), "id DESC"),
+	).Offset(offset).Limit(size).Find(&items).Error; err != nil {
+		return nil, 0, 0, 0, err
+	}
+	return items, total, page, size, nil
+}
+
+func (r *chatRoomRepository) MessageByID(ctx context.Context, id int) (domain.ChatMessage, error) {
+	var msg domain.ChatMessage
+	err := r.db.WithContext(ctx).First(&msg, id).Error
+	return msg, err
+}
+
+func (r *chatRoomRepository) CreateMessage(ctx context.Context, msg *domain.ChatMessage) error {
+	return WithTx(ctx, r.db, func(tx *gorm.DB) error {
+		if err := tx.Create(msg).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.ChatRoom{}).
+			Where("id = ?", msg.RoomId).
+			Update("last_message_at", msg.CreatedDate).Error; err != nil {
+			return err
+		}
+		if msg.SenderId == nil {
+			return nil
+		}
+		return tx.Model(&domain.ChatRoomMember{}).
+			Where("room_id = ? AND user_id = ?", msg.RoomId, *msg.SenderId).
+			Update("last_read_at", msg.CreatedDate).Error
+	})
+}
+
+func (r *chatRoomRepository) EditMessage(ctx context.Context, id int, content string, at time.Time) error {
+	res := r.db.WithContext(ctx).Model(&domain.ChatMessage{}).
+		Where("id = ? AND NOT COALESCE(is_deleted, FALSE)", id).
+		Updates(map[string]any{"content": content, "is_edited": true, "edited_at": at})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *chatRoomRepository) SoftDeleteMessage(ctx context.Context, id int) error {
+	res := r.db.WithContext(ctx).Model(&domain.ChatMessage{}).
+		Where("id = ? AND NOT COALESCE(is_deleted, FALSE)", id).
+		Update("is_deleted", true)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Package repository provides implementation for repository
+//
+// File: file_blob_repo.go
+// Description: Content-addressed stored blobs with reference counts (file_blobs)
+package repository
+
+import (
+	"context"
+
+	"templatev25/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FileBlobRepository interface {
+	// Acquire нь ижил агуулгатай blob-ийн ref_count-ийг нэмж буцаана;
+	// байхгүй бол gorm.ErrRecordNotFound.
+	Acquire(ctx context.Context, provider string, isPublic bool, checksum string) (domain.FileBlob, error)
+	// Create нь ref_count = 1 blob бүртгэнэ. Ижил агуулгатай blob зэрэг
+	// бүртгэгдсэн бол юу ч бичихгүй false буцаана.
+	Create(ctx context.Context, b domain.FileBlob) (domain.FileBlob, bool, error)
+	// Release нь ref_count-ийг нэгээр хасна. Сүүлийн reference байсан бол
+	// бүртгэлийг устгаж true буцаана (storage-оос устгах нь дуудагчийн үүрэг).
+	Release(ctx context.Context, id int) (domain.FileBlob, bool, error)
+}
+
+type fileBlobRepository struct{ db *gorm.DB }
+
+func NewFileBlobRepository(db *gorm.DB) FileBlobRepository {
+	return &fileBlobRepository{db: db}
+}
+
+func (r *fileBlobRepository) Acquire(ctx context.Context, provider string, isPublic bool, checksum string) (domain.FileBlob, error) {
+	var b domain.FileBlob
+	// ref_count > 0: Release-ийн устгаж буй blob-ийг дахин ашиглахгүй
+	res := r.db.WithContext(ctx).
+		Model(&b).
+		Clauses(clause.Returning{}).
+		Where("storage_provider = ? AND is_public = ? AND checksum = ? AND ref_count > 0", provider, isPublic, checksum).
+		Update("ref_count", gorm.Expr("ref_count + 1"))
+	if res.Error != nil {
+		return domain.FileBlob{}, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.FileBlob{}, gorm.ErrRecordNotFound
+	}
+	return b, nil
+}
+
+func (r *fileBlobRepository) Create(ctx context.Context, b domain.FileBlob) (domain.FileBlob, bool, error) {
+	b.RefCount = 1
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&b)
+	if res.Error != nil {
+		return domain.FileBlob{}, false, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return domain.FileBlob{}, false, nil
+	}
+	return b, true, nil
+}
+
+func (r *fileBlobRepository) Release(ctx context.Context, id int) (domain.FileBlob, bool, error) {
+	var b domain.FileBlob
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&b).
+			Clauses(clause.Returning{}).
+			Where("id = ? AND ref_count > 0", id).
+			Update("ref_count", gorm.Expr("ref_count - 1"))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if b.RefCount > 0 {
+			return nil
+		}
+		// Мөрийн lock commit хүртэл үргэлжлэх тул зэрэг Acquire энэ blob-ийг олохгүй
+		return tx.Delete(&domain.FileBlob{}, id).Error
+	})
+	if err != nil {
+		return domain.FileBlob{}, false, err
+	}
+	return b, b.RefCount == 0, nil
+}
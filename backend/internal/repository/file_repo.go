@@ -0,0 +1,71 @@
+// Package repository provides implementation for repository
+//
+// File: file_repo.go
+// Description: Stored file records (files table)
+package repository
+
+import (
+	"context"
+
+	"templatev25/internal/domain"
+
+	"gorm.io/gorm"
+)
+
+type FileRepository interface {
+	Create(ctx context.Context, f domain.File) (domain.File, error)
+	// GetByStoredName нь storage key-ээр хайна; байхгүй бол gorm.ErrRecordNotFound.
+	GetByStoredName(ctx context.Context, storedName string) (domain.File, error)
+	// ListByUser нь хэрэглэгчийн upload хийсэн файлууд (шинэ нь эхэндээ).
+	ListByUser(ctx context.Context, userID int, isPublic bool) ([]domain.File, error)
+	// Delete нь soft delete хийнэ (deleted_date).
+	Delete(ctx context.Context, id int) error
+	// IncrementDownloads нь download_count-ийг нэгээр нэмнэ.
+	IncrementDownloads(ctx context.Context, id int) error
+}
+
+type fileRepository struct{ db *gorm.DB }
+
+func NewFileRepository(db *gorm.DB) FileRepository {
+	return &fileRepository{db: db}
+}
+
+func (r *fileRepository) Create(ctx context.Context, f domain.File) (domain.File, error) {
+	if err := r.db.WithContext(ctx).Create(&f).Error; err != nil {
+		return domain.File{}, err
+	}
+	return f, nil
+}
+
+func (r *fileRepository) GetByStoredName(ctx context.Context, storedName string) (domain.File, error) {
+	var f domain.File
+	err := r.db.WithContext(ctx).Take(&f, "stored_name = ?", storedName).Error
+	return f, err
+}
+
+func (r *fileRepository) ListByUser(ctx context.Context, userID int, isPublic bool) ([]domain.File, error) {
+	var items []domain.File
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND is_public = ?", userID, isPublic).
+		Order("id DESC").
+		Find(&items).Error
+	return items, err
+}
+
+func (r *fileRepository) Delete(ctx context.Context, id int) error {
+	res := r.db.WithContext(ctx).Delete(&domain.File{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *fileRepository) IncrementDownloads(ctx context.Context, id int) error {
+	return r.db.WithContext(ctx).
+		Model(&domain.File{}).
+		Where("id = ?", id).
+		UpdateColumn("download_count", gorm.Expr("download_count + 1")).Error
+}
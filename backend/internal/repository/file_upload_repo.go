@@ -0,0 +1,142 @@
+// Package repository provides implementation for repository
+//
+// File: file_upload_repo.go
+// Description: Resumable upload state (file_uploads, file_upload_chunks)
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"templatev25/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FileUploadRepository interface {
+	Create(ctx context.Context, u domain.FileUpload) (domain.FileUpload, error)
+	// Get нь байхгүй бол gorm.ErrRecordNotFound.
+	Get(ctx context.Context, id string) (domain.FileUpload, error)
+	// AppendChunk нь хэсгийг бүртгэж upload_offset-ийг урагшлуулна. Upload
+	// "uploading" биш эсвэл offset нь chunk.ChunkOffset-оос өөр болсон бол
+	// юу ч бичихгүй false буцаана.
+	AppendChunk(ctx context.Context, chunk domain.FileUploadChunk, expiresAt time.Time) (bool, error)
+	// Chunks нь хэсгүүдийг offset-ийн дарааллаар буцаана.
+	Chunks(ctx context.Context, id string) ([]domain.FileUploadChunk, error)
+	// SetStatus нь төлөвийг from-оос to болгоно; from биш байсан бол false.
+	SetStatus(ctx context.Context, id, from, to string) (bool, error)
+	// Complete нь "completed" болгож file_url бичээд хэсгүүдийн бүртгэлийг устгана.
+	Complete(ctx context.Context, id, fileURL string, expiresAt time.Time) error
+	// Delete нь upload болон хэсгүүдийн бүртгэлийг устгана.
+	Delete(ctx context.Context, id string) error
+	// ListExpired нь expires_at < before upload-уудыг (хуучин нь эхэндээ) limit хүртэл буцаана.
+	ListExpired(ctx context.Context, before time.Time, limit int) ([]domain.FileUpload, error)
+}
+
+type fileUploadRepository struct{ db *gorm.DB }
+
+func NewFileUploadRepository(db *gorm.DB) FileUploadRepository {
+	return &fileUploadRepository{db: db}
+}
+
+// errOffsetMoved aborts an append whose upload is no longer at the chunk offset
+var errOffsetMoved = errors.New("upload offset moved")
+
+func (r *fileUploadRepository) Create(ctx context.Context, u domain.FileUpload) (domain.FileUpload, error) {
+	if err := r.db.WithContext(ctx).Create(&u).Error; err != nil {
+		return domain.FileUpload{}, err
+	}
+	return u, nil
+}
+
+func (r *fileUploadRepository) Get(ctx context.Context, id string) (domain.FileUpload, error) {
+	var u domain.FileUpload
+	err := r.db.WithContext(ctx).Take(&u, "id = ?", id).Error
+	return u, err
+}
+
+func (r *fileUploadRepository) AppendChunk(ctx context.Context, chunk domain.FileUploadChunk, expiresAt time.Time) (bool, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&domain.FileUpload{}).
+			Where("id = ? AND status = ? AND upload_offset = ?", chunk.UploadId, domain.FileUploadUploading, chunk.ChunkOffset).
+			Updates(map[string]any{
+				"upload_offset": gorm.Expr("upload_offset + ?", chunk.Size),
+				"expires_at":    expiresAt,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errOffsetMoved
+		}
+		// The row lock taken by the update serializes appends, so the
+		// primary key never conflicts; DoNothing only guards a stale row.
+		res = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&chunk)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errOffsetMoved
+		}
+		return nil
+	})
+	if errors.Is(err, errOffsetMoved) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *fileUploadRepository) Chunks(ctx context.Context, id string) ([]domain.FileUploadChunk, error) {
+	var items []domain.FileUploadChunk
+	err := r.db.WithContext(ctx).
+		Where("upload_id = ?", id).
+		Order("chunk_offset").
+		Find(&items).Error
+	return items, err
+}
+
+func (r *fileUploadRepository) SetStatus(ctx context.Context, id, from, to string) (bool, error) {
+	res := r.db.WithContext(ctx).
+		Model(&domain.FileUpload{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *fileUploadRepository) Complete(ctx context.Context, id, fileURL string, expiresAt time.Time) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.FileUpload{}).
+			Where("id = ?", id).
+			Updates(map[string]any{
+				"status":       domain.FileUploadCompleted,
+				"file_url":     fileURL,
+				"completed_at": now,
+				"expires_at":   expiresAt,
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Where("upload_id = ?", id).Delete(&domain.FileUploadChunk{}).Error
+	})
+}
+
+func (r *fileUploadRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("upload_id = ?", id).Delete(&domain.FileUploadChunk{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&domain.FileUpload{}).Error
+	})
+}
+
+func (r *fileUploadRepository) ListExpired(ctx context.Context, before time.Time, limit int) ([]domain.FileUpload, error) {
+	var items []domain.FileUpload
+	err := r.db.WithContext(ctx).
+		Where("expires_at < ?", before).
+		Order("expires_at").
+		Limit(limit).
+		Find(&items).Error
+	return items, err
+}
@@ -0,0 +1,232 @@
+// Package repository provides implementation for repository
+//
+// File: job_repo.go
+// Description: Scheduled job and job execution persistence
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"time"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/http/dto"
+
+	"git.gerege.mn/backend-packages/scopes"
+	"git.gerege.mn/backend-packages/utils"
+	"gorm.io/gorm"
+)
+
+// jobLockNamespace нь pg_try_advisory_lock(int, int)-ийн эхний key.
+// Бусад advisory lock-уудтай (migrator) давхцахгүй байхаар сонгосон ("JOBS").
+const jobLockNamespace int32 = 0x4a4f4253
+
+// jobUnlockTimeout нь advisory lock суллах хугацааны хязгаар.
+const jobUnlockTimeout = 5 * time.Second
+
+type JobRepository interface {
+	List(ctx context.Context, q dto.JobListQuery) ([]domain.ScheduledJob, int64, int, int, error)
+	ByID(ctx context.Context, id int) (domain.ScheduledJob, error)
+	Due(ctx context.Context, now time.Time, limit int) ([]domain.ScheduledJob, error)
+	SetNextRun(ctx context.Context, id int, next *time.Time) error
+	SetActive(ctx context.Context, id int, active bool, next *time.Time) error
+
+	Executions(ctx context.Context, jobID int, q dto.JobExecutionListQuery) ([]domain.JobExecution, int64, int, int, error)
+	StartExecution(ctx context.Context, exec *domain.JobExecution) error
+	FinishExecution(ctx context.Context, exec domain.JobExecution, next *time.Time) error
+	PurgeExecutions(ctx context.Context, before time.Time) (int64, error)
+
+	// TryLock нь job-ийн advisory lock-ийг авахыг оролдоно.
+	// Амжилттай бол unlock функц буцаана; өөр replica барьж байвал ok=false.
+	TryLock(ctx context.Context, jobID int) (unlock func(), ok bool, err error)
+}
+
+type jobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) JobRepository {
+	return &jobRepository{db: db}
+}
+
+func (r *jobRepository) List(ctx context.Context, q dto.JobListQuery) ([]domain.ScheduledJob, int64, int, int, error) {
+	page, size, offset := utils.OffsetLimit(q.PaginationQuery)
+
+	colMap := scopes.ColumnMap{
+		"id":          "id",
+		"name":        "name",
+		"code":        "code",
+		"handler":     "handler",
+		"next_run_at": "next_run_at",
+		"last_run_at": "last_run_at",
+	}
+
+	tx := r.db.WithContext(ctx).Model(&domain.ScheduledJob{}).Scopes(
+		scopes.SearchScope(colMap, utils.ParseSearch(q.Search)),
+	)
+	if q.Handler != "" {
+		tx = tx.Where("handler = ?", q.Handler)
+	}
+	if q.IsActive != nil {
+		tx = tx.Where("COALESCE(is_active, TRUE) = ?", *q.IsActive)
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, 0, 0, err
+	}
+
+	var items []domain.ScheduledJob
+	if err := tx.Scopes(
+		scopes.SortScope(colMap, utils.ParseSort(q.Sort), "id ASC"),
+	).Offset(offset).Limit(size).Find(&items).Error; err != nil {
+		return nil, 0, 0, 0, err
+	}
+	return items, total, page, size, nil
+}
+
+func (r *jobRepository) ByID(ctx context.Context, id int) (domain.ScheduledJob, error) {
+	var job domain.ScheduledJob
+	err := r.db.WithContext(ctx).First(&job, id).Error
+	return job, err
+}
+
+// Due нь ажиллах хугацаа нь болсон (эсвэл next_run_at тооцоологдоогүй)
+// идэвхтэй job-уудыг буцаана.
+func (r *jobRepository) Due(ctx context.Context, now time.Time, limit int) ([]domain.ScheduledJob, error) {
+	var jobs []domain.ScheduledJob
+	err := r.db.WithContext(ctx).
+		Where("COALESCE(is_active, TRUE)").
+		Where("COALESCE(cron_expression, '') <> ''").
+		Where("next_run_at IS NULL OR next_run_at <= ?", now).
+		Order("next_run_at ASC NULLS FIRST, id ASC").
+		Limit(limit).
+		Find(&jobs).Error
+	return jobs, err
+}
+
+func (r *jobRepository) SetNextRun(ctx context.Context, id int, next *time.Time) error {
+	return r.db.WithContext(ctx).Model(&domain.ScheduledJob{}).
+		Where("id = ?", id).
+		Update("next_run_at", next).Error
+}
+
+func (r *jobRepository) SetActive(ctx context.Context, id int, active bool, next *time.Time) error {
+	res := r.db.WithContext(ctx).Model(&domain.ScheduledJob{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"is_active":   active,
+			"next_run_at": next,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *jobRepository) Executions(ctx context.Context, jobID int, q dto.JobExecutionListQuery) ([]domain.JobExecution, int64, int, int, error) {
+	page, size, offset := utils.OffsetLimit(q.PaginationQuery)
+
+	colMap := scopes.ColumnMap{
+		"id":          "id",
+		"status":      "status",
+		"started_at":  "started_at",
+		"duration_ms": "duration_ms",
+	}
+
+	tx := r.db.WithContext(ctx).Model(&domain.JobExecution{}).Where("job_id = ?", jobID)
+	if q.Status != "" {
+		tx = tx.Where("status = ?", q.Status)
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, 0, 0, err
+	}
+
+	var items []domain.JobExecution
+	if err := tx.Scopes(
+		scopes.SortScope(colMap, utils.ParseSort(q.Sort), "started_at DESC, id DESC"),
+	).Offset(offset).Limit(size).Find(&items).Error; err != nil {
+		return nil, 0, 0, 0, err
+	}
+	return items, total, page, size, nil
+}
+
+func (r *jobRepository) StartExecution(ctx context.Context, exec *domain.JobExecution) error {
+	return r.db.WithContext(ctx).Create(exec).Error
+}
+
+// FinishExecution нь execution-ийн үр дүн болон job-ийн last_run_* талбаруудыг
+// нэг transaction-д шинэчилнэ. next nil бол next_run_at өөрчлөгдөхгүй (гар ажиллуулалт).
+func (r *jobRepository) FinishExecution(ctx context.Context, exec domain.JobExecution, next *time.Time) error {
+	return WithTx(ctx, r.db, func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.JobExecution{}).
+			Where("id = ?", exec.Id).
+			Updates(map[string]any{
+				"status":        exec.Status,
+				"finished_at":   exec.FinishedAt,
+				"duration_ms":   exec.DurationMs,
+				"result":        exec.Result,
+				"error_message": exec.ErrorMessage,
+			}).Error; err != nil {
+			return err
+		}
+
+		job := map[string]any{
+			"last_run_at":       exec.StartedAt,
+			"last_run_status":   exec.Status,
+			"last_run_duration": exec.DurationMs,
+			"last_error":        exec.ErrorMessage,
+		}
+		if next != nil {
+			job["next_run_at"] = *next
+		}
+		return tx.Model(&domain.ScheduledJob{}).Where("id = ?", exec.JobId).Updates(job).Error
+	})
+}
+
+func (r *jobRepository) PurgeExecutions(ctx context.Context, before time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).
+		Where("started_at < ? AND status <> ?", before, domain.JobStatusRunning).
+		Delete(&domain.JobExecution{})
+	return res.RowsAffected, res.Error
+}
+
+// TryLock нь session-түвшний advisory lock тул lock болон unlock нэг
+// холболт дээр байх ёстой: pool-оос тусдаа connection авч барина.
+func (r *jobRepository) TryLock(ctx context.Context, jobID int) (func(), bool, error) {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return nil, false, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var ok bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1, $2)", jobLockNamespace, jobID).Scan(&ok); err != nil {
+		_ = conn.Close()
+		return nil, false, err
+	}
+	if !ok {
+		_ = conn.Close()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		// Job-ийн ctx цуцлагдсан байж болох тул шинэ context
+		uctx, cancel := context.WithTimeout(context.Background(), jobUnlockTimeout)
+		defer cancel()
+		if _, err := conn.ExecContext(uctx, "SELECT pg_advisory_unlock($1, $2)", jobLockNamespace, jobID); err != nil {
+			// Lock-той холболтыг pool руу буцаахгүй: хаагдахад lock суллагдана
+			_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		_ = conn.Close()
+	}
+	return unlock, true, nil
+}
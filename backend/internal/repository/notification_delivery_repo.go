@@ -0,0 +1,110 @@
+// Package repository provides implementation for repository
+//
+// File: notification_delivery_repo.go
+// Description: Notification recipients and per-channel delivery log
+package repository
+
+import (
+	"context"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/http/dto"
+
+	"git.gerege.mn/backend-packages/scopes"
+	"git.gerege.mn/backend-packages/utils"
+
+	"gorm.io/gorm"
+)
+
+// deliveryLogBatch нь нэг INSERT-д орох delivery мөрийн тоо.
+const deliveryLogBatch = 500
+
+// recipientSelect нь users + user_settings-ээс хүлээн авагчийг уншина.
+// user_settings мөргүй хэрэглэгчид хүснэгтийн DEFAULT утгыг авна.
+const recipientSelect = `
+	SELECT u.id AS user_id,
+	       COALESCE(u.email, '') AS email,
+	       COALESCE(u.phone_no, '') AS phone_no,
+	       COALESCE(s.language, 'mn') AS language,
+	       COALESCE(s.notification_push, TRUE) AS notification_push,
+	       COALESCE(s.notification_email, TRUE) AS notification_email,
+	       COALESCE(s.notification_sms, FALSE) AS notification_sms
+	FROM users u
+	LEFT JOIN user_settings s ON s.user_id = u.id
+	WHERE u.deleted_date IS NULL`
+
+type NotificationDeliveryRepository interface {
+	// Recipients нь userIDs-ийн холбоо барих мэдээлэл, сувгийн тохиргоо.
+	Recipients(ctx context.Context, userIDs []int) ([]domain.NotificationRecipient, error)
+	// RecipientsAfter нь бүх хэрэглэгчийг id > afterID-аас limit-ээр (broadcast).
+	RecipientsAfter(ctx context.Context, afterID, limit int) ([]domain.NotificationRecipient, error)
+
+	LogDeliveries(ctx context.Context, ds []domain.NotificationDelivery) error
+	List(ctx context.Context, q dto.NotificationDeliveryListQuery) ([]domain.NotificationDelivery, int64, int, int, error)
+}
+
+type notificationDeliveryRepository struct{ db *gorm.DB }
+
+func NewNotificationDeliveryRepository(db *gorm.DB) NotificationDeliveryRepository {
+	return &notificationDeliveryRepository{db: db}
+}
+
+func (r *notificationDeliveryRepository) Recipients(ctx context.Context, userIDs []int) ([]domain.NotificationRecipient, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	var items []domain.NotificationRecipient
+	err := r.db.WithContext(ctx).Raw(recipientSelect+` AND u.id IN ? ORDER BY u.id`, userIDs).Scan(&items).Error
+	return items, err
+}
+
+func (r *notificationDeliveryRepository) RecipientsAfter(ctx context.Context, afterID, limit int) ([]domain.NotificationRecipient, error) {
+	var items []domain.NotificationRecipient
+	err := r.db.WithContext(ctx).Raw(recipientSelect+` AND u.id > ? ORDER BY u.id LIMIT ?`, afterID, limit).Scan(&items).Error
+	return items, err
+}
+
+func (r *notificationDeliveryRepository) LogDeliveries(ctx context.Context, ds []domain.NotificationDelivery) error {
+	if len(ds) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).CreateInBatches(&ds, deliveryLogBatch).Error
+}
+
+func (r *notificationDeliveryRepository) List(ctx context.Context, q dto.NotificationDeliveryListQuery) ([]domain.NotificationDelivery, int64, int, int, error) {
+	page, size, offset := utils.OffsetLimit(q.PaginationQuery)
+	colMap := scopes.ColumnMap{
+		"id":           "id",
+		"user_id":      "user_id",
+		"channel":      "channel",
+		"status":       "status",
+		"created_date": "created_date",
+	}
+
+	tx := r.db.WithContext(ctx).Model(&domain.NotificationDelivery{})
+	if q.GroupID != 0 {
+		tx = tx.Where("group_id = ?", q.GroupID)
+	}
+	if q.UserID != 0 {
+		tx = tx.Where("user_id = ?", q.UserID)
+	}
+	if q.Channel != "" {
+		tx = tx.Where("channel = ?", q.Channel)
+	}
+	if q.Status != "" {
+		tx = tx.Where("status = ?", q.Status)
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, 0, 0, err
+	}
+
+	var items []domain.NotificationDelivery
+	if err := tx.Scopes(
+		scopes.SortScope(colMap, utils.ParseSort(q.Sort), "id DESC"),
+	).Offset(offset).Limit(size).Find(&items).Error; err != nil {
+		return nil, 0, 0, 0, err
+	}
+	return items, total, page, size, nil
+}
@@ -10,6 +10,8 @@ package repository
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"templatev25/internal/domain"
 	"git.gerege.mn/backend-packages/common"
@@ -18,6 +20,7 @@ import (
 	"git.gerege.mn/backend-packages/utils"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type NotificationRepository interface {
@@ -32,6 +35,8 @@ type NotificationRepository interface {
 	CreateNotificationsBulk(ctx context.Context, ns []domain.Notification) error
 
 	AllUserIDs(ctx context.Context) ([]int, error)
+	CountUsers(ctx context.Context) (int64, error)
+	ResolveRecipients(ctx context.Context, t domain.NotificationTarget) ([]int, error)
 }
 
 type notificationRepository struct{ db *gorm.DB }
@@ -40,6 +45,12 @@ func NewNotificationRepository(db *gorm.DB) NotificationRepository {
 	return &notificationRepository{db: db}
 }
 
+// ListByUser нь хэрэглэгчийн мэдэгдлүүдийг буцаана: өөрийн notifications мөрүүд
+// болон fan-out-on-read broadcast group-ууд (уншсан эсэх нь
+// notification_reads / notification_read_marks-аас). Broadcast-ийг хэрэглэгч
+// бүртгүүлсний дараа илгээсэн бол л харуулна.
+// Broadcast мөрийн id нь -group_id: notifications.id (serial, эерэг)-тэй
+// давхцахгүй, тогтвортой тул client жагсаалтын key болгон ашиглаж болно.
 func (r *notificationRepository) ListByUser(ctx context.Context, userID int, p common.PaginationQuery) ([]domain.Notification, int64, int, int, error) {
 	page, size, offset := utils.OffsetLimit(p)
 	colMap := scopes.ColumnMap{
@@ -52,9 +63,26 @@ func (r *notificationRepository) ListByUser(ctx context.Context, userID int, p c
 		"content":  "notifications.content",
 		"group_id": "notifications.group_id",
 	}
+	inbox := r.db.Raw(`
+		SELECT n.id, n.user_id, n.title, n.content, n.is_read, n.type, n.tenant, n.group_id,
+		       n.created_username, n.created_date, n.updated_date, n.deleted_date
+		FROM notifications n
+		WHERE n.user_id = ?
+		UNION ALL
+		SELECT -g.id, CAST(? AS INTEGER), g.title, g.content,
+		       (nr.user_id IS NOT NULL OR g.created_date <= m.read_all_at) IS TRUE,
+		       g.type, g.tenant, g.id,
+		       g.created_username, g.created_date, g.updated_date, g.deleted_date
+		FROM notification_groups g
+		JOIN users u ON u.id = ? AND g.created_date >= u.created_date
+		LEFT JOIN notification_reads nr ON nr.group_id = g.id AND nr.user_id = u.id
+		LEFT JOIN notification_read_marks m ON m.user_id = u.id
+		WHERE g.fan_out_on_read
+	`, userID, userID, userID)
+
 	tx := r.db.WithContext(ctx).
-		Model(&domain.Notification{}).
-		Where("user_id = ?", userID).
+		Table("(?) AS notifications", inbox).
+		Where("notifications.deleted_date IS NULL").
 		Scopes(
 			scopes.SearchScope(colMap, utils.ParseSearch(p.Search)),
 			scopes.DateScope(p.CreatedFrom, p.CreatedTo),
@@ -66,25 +94,49 @@ func (r *notificationRepository) ListByUser(ctx context.Context, userID int, p c
 	}
 
 	var items []domain.Notification
-	if err := tx.Scopes(scopes.SortScope(colMap, utils.ParseSort(p.Sort), "id DESC")).
+	if err := tx.Scopes(scopes.SortScope(colMap, utils.ParseSort(p.Sort), "created_date DESC, id DESC")).
 		Offset(offset).Limit(size).Find(&items).Error; err != nil {
 		return nil, 0, 0, 0, err
 	}
 	return items, total, page, size, nil
 }
 
+// MarkGroupRead нь group-ийн мэдэгдлийг уншсан болгоно. Fan-out-on-read
+// group бол notification_reads-д тэмдэглэнэ (давтан дуудахад өөрчлөлтгүй).
 func (r *notificationRepository) MarkGroupRead(ctx context.Context, userID, groupID int) error {
-	return r.db.WithContext(ctx).
-		Model(&domain.Notification{}).
-		Where("group_id = ? AND user_id = ?", groupID, userID).
-		Update("is_read", true).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Notification{}).
+			Where("group_id = ? AND user_id = ?", groupID, userID).
+			Update("is_read", true).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`
+			INSERT INTO notification_reads (group_id, user_id, read_at)
+			SELECT g.id, ?, NOW() FROM notification_groups g
+			WHERE g.id = ? AND g.fan_out_on_read AND g.deleted_date IS NULL
+			ON CONFLICT (group_id, user_id) DO NOTHING
+		`, userID, groupID).Error
+	})
 }
 
+// MarkAllRead нь хэрэглэгчийн бүх мэдэгдлийг уншсан болгоно. Fan-out-on-read
+// group-уудын хувьд read_all_at-ийг одоо болгож, түүнээс өмнөх
+// notification_reads тэмдгүүдийг цэвэрлэнэ.
 func (r *notificationRepository) MarkAllRead(ctx context.Context, userID int) error {
-	return r.db.WithContext(ctx).
-		Model(&domain.Notification{}).
-		Where("user_id = ?", userID).
-		Update("is_read", true).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Notification{}).
+			Where("user_id = ?", userID).
+			Update("is_read", true).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"read_all_at"}),
+		}).Create(&domain.NotificationReadMark{UserId: userID, ReadAllAt: time.Now()}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&domain.NotificationRead{}).Error
+	})
 }
 
 func (r *notificationRepository) ListGroups(ctx context.Context, p common.PaginationQuery) ([]domain.NotificationGroup, int64, int, int, error) {
@@ -132,8 +184,8 @@ func (r *notificationRepository) CreateNotification(ctx context.Context, n domai
 
 func (r *notificationRepository) CreateNotificationsBulk(ctx context.Context, ns []domain.Notification) error {
 	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		for _, n := range ns {
-			if err := tx.Create(&n).Error; err != nil {
+		for i := range ns {
+			if err := tx.Create(&ns[i]).Error; err != nil {
 				return err
 			}
 		}
@@ -148,3 +200,109 @@ func (r *notificationRepository) AllUserIDs(ctx context.Context) ([]int, error)
 	}
 	return ids, nil
 }
+
+// CountUsers нь устгагдаагүй хэрэглэгчийн тоо (broadcast-ийн recipient_count).
+func (r *notificationRepository) CountUsers(ctx context.Context) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&domain.User{}).Count(&n).Error
+	return n, err
+}
+
+// roleHoldersSQL нь RoleCodes-ийн role-ыг эзэмших хэрэглэгчдийг сонгоно:
+// шууд олголт болон тэдгээрийг өвлөсөн (role_parents-ийн үр удам) role-ын олголт.
+// userRoleTreeCTE-ийг урвуу чиглэлд (эцгээс хүүхэд рүү) давтана: устгагдсан role,
+// түүгээр дамжих өвлөлт, хугацаа дууссан олголт тооцогдохгүй.
+// Байгууллагын олголт нь тухайн байгууллагыг сонгосон үед л хүчинтэй тул
+// хэрэглэгч тэр байгууллагын гишүүн (сонгох боломжтой) үед л тооцогдоно.
+const roleHoldersSQL = `
+			SELECT ur.user_id FROM user_roles ur
+			WHERE ur.role_id IN (
+				WITH RECURSIVE role_tree(role_id) AS (
+					SELECT r.id FROM roles r
+					WHERE r.code IN ? AND r.deleted_date IS NULL
+					UNION
+					SELECT rp.role_id FROM role_parents rp
+					JOIN role_tree t ON t.role_id = rp.parent_id
+					JOIN roles c ON c.id = rp.role_id AND c.deleted_date IS NULL
+				)
+				SELECT role_id FROM role_tree
+			)
+			  AND ur.deleted_date IS NULL
+			  AND (ur.expires_at IS NULL OR ur.expires_at > NOW())
+			  AND (ur.organization_id IS NULL OR EXISTS (
+				SELECT 1 FROM organization_users ou
+				JOIN organizations o ON o.id = ou.org_id AND o.deleted_date IS NULL
+				WHERE ou.org_id = ur.organization_id
+				  AND ou.user_id = ur.user_id
+				  AND ou.deleted_date IS NULL
+			  ))`
+
+// ResolveRecipients нь target-ийн нөхцөлүүдийн аль нэгт таарах идэвхтэй
+// хэрэглэгчдийн ID-г өсөх дарааллаар буцаана (давхардалгүй).
+//   - role_codes: role-ын эзэмшигчид (roleHoldersSQL: өвлөлт, байгууллагын олголт)
+//   - org_ids: organization_users гишүүд; include_sub_orgs үед дэд байгууллагууд ч орно
+//   - org_type_ids: тухайн төрлийн байгууллагын гишүүд
+//   - user_ids: шууд заасан (устгагдсан хэрэглэгч хасагдана)
+func (r *notificationRepository) ResolveRecipients(ctx context.Context, t domain.NotificationTarget) ([]int, error) {
+	if t.Empty() {
+		return nil, nil
+	}
+
+	var (
+		parts []string
+		args  []any
+	)
+	if len(t.RoleCodes) > 0 {
+		parts = append(parts, roleHoldersSQL)
+		args = append(args, t.RoleCodes)
+	}
+	if len(t.OrgIDs) > 0 {
+		if t.IncludeSubOrgs {
+			parts = append(parts, `
+			SELECT ou.user_id FROM organization_users ou
+			WHERE ou.deleted_date IS NULL AND ou.org_id IN (
+				WITH RECURSIVE subtree(id, path) AS (
+					SELECT o.id, ARRAY[o.id] FROM organizations o
+					WHERE o.id IN ? AND o.deleted_date IS NULL
+					UNION ALL
+					SELECT c.id, s.path || c.id
+					FROM organizations c
+					JOIN subtree s ON c.parent_id = s.id
+					WHERE c.deleted_date IS NULL
+					  AND NOT c.id = ANY(s.path)
+				)
+				SELECT id FROM subtree
+			)`)
+		} else {
+			parts = append(parts, `
+			SELECT ou.user_id FROM organization_users ou
+			JOIN organizations o ON o.id = ou.org_id AND o.deleted_date IS NULL
+			WHERE ou.org_id IN ? AND ou.deleted_date IS NULL`)
+		}
+		args = append(args, t.OrgIDs)
+	}
+	if len(t.OrgTypeIDs) > 0 {
+		parts = append(parts, `
+			SELECT ou.user_id FROM organization_users ou
+			JOIN organizations o ON o.id = ou.org_id AND o.deleted_date IS NULL
+			WHERE o.type_id IN ? AND ou.deleted_date IS NULL`)
+		args = append(args, t.OrgTypeIDs)
+	}
+	if len(t.UserIDs) > 0 {
+		parts = append(parts, `
+			SELECT u.id FROM users u WHERE u.id IN ?`)
+		args = append(args, t.UserIDs)
+	}
+
+	var ids []int
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT u.id FROM users u
+		WHERE u.deleted_date IS NULL AND u.id IN (`+strings.Join(parts, "\n\t\t\tUNION")+`
+		)
+		ORDER BY u.id
+	`, args...).Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
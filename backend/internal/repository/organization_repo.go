@@ -36,6 +36,12 @@ type OrganizationRepository interface {
 	Delete(ctx context.Context, id int) error
 	ByID(ctx context.Context, id int) (domain.Organization, error)
 	Tree(ctx context.Context, rootID int) ([]domain.Organization, error)
+
+	// Hierarchy
+	Subtree(ctx context.Context, rootID int) ([]domain.Organization, error)
+	Ancestors(ctx context.Context, id int) ([]domain.Organization, error)
+	DescendantIDs(ctx context.Context, id int) ([]int, error)
+	Move(ctx context.Context, id int, parentID *int) error
 }
 
 type organizationRepository struct{ db *gorm.DB }
@@ -110,17 +116,111 @@ func (r *organizationRepository) ByID(ctx context.Context, id int) (domain.Organ
 	return o, err
 }
 
+// Tree нь rootID-аас эхэлсэн бүх түвшний модыг буцаана (нэг root, Children-д үүрлэсэн).
 func (r *organizationRepository) Tree(ctx context.Context, rootID int) ([]domain.Organization, error) {
+	items, err := r.Subtree(ctx, rootID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.BuildOrganizationTree(items), nil
+}
+
+// Subtree нь rootID болон түүний бүх үр удмыг хавтгай жагсаалтаар буцаана.
+// Дараалал: гүнээр (root эхэнд), дараа нь sequence, name.
+// Устгагдсан байгууллага болон түүний доорх салбар орохгүй.
+// path шалгалт нь parent_id-д цикл байсан ч query-г зогсооно.
+func (r *organizationRepository) Subtree(ctx context.Context, rootID int) ([]domain.Organization, error) {
 	var items []domain.Organization
-	// Хэрэв танайд ParentPreloader/ChildrenPreloader байгаа бол түүнийг хэрэглээрэй.
-	if err := r.db.WithContext(ctx).
-		Preload("Children").
-		Find(&items, "id = ?", rootID).Error; err != nil {
+	err := r.db.WithContext(ctx).Raw(`
+		WITH RECURSIVE subtree(id, depth, path) AS (
+			SELECT o.id, 0, ARRAY[o.id]
+			FROM organizations o
+			WHERE o.id = ? AND o.deleted_date IS NULL
+			UNION ALL
+			SELECT c.id, s.depth + 1, s.path || c.id
+			FROM organizations c
+			JOIN subtree s ON c.parent_id = s.id
+			WHERE c.deleted_date IS NULL
+			  AND NOT c.id = ANY(s.path)
+		)
+		SELECT o.*
+		FROM organizations o
+		JOIN subtree s ON s.id = o.id
+		ORDER BY s.depth, o.sequence, o.name, o.id
+	`, rootID).Scan(&items).Error
+	if err != nil {
 		return nil, err
 	}
 	return items, nil
 }
 
+// Ancestors нь id-ийн эцгүүдийг дээрээс доош (root эхэнд) буцаана. id өөрөө орохгүй.
+func (r *organizationRepository) Ancestors(ctx context.Context, id int) ([]domain.Organization, error) {
+	var items []domain.Organization
+	err := r.db.WithContext(ctx).Raw(`
+		WITH RECURSIVE ancestors(id, parent_id, depth, path) AS (
+			SELECT o.id, o.parent_id, 0, ARRAY[o.id]
+			FROM organizations o
+			WHERE o.id = ? AND o.deleted_date IS NULL
+			UNION ALL
+			SELECT p.id, p.parent_id, a.depth + 1, a.path || p.id
+			FROM organizations p
+			JOIN ancestors a ON p.id = a.parent_id
+			WHERE p.deleted_date IS NULL
+			  AND NOT p.id = ANY(a.path)
+		)
+		SELECT o.*
+		FROM organizations o
+		JOIN ancestors a ON a.id = o.id
+		WHERE a.depth > 0
+		ORDER BY a.depth DESC
+	`, id).Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// DescendantIDs нь id-ийн бүх үр удмын ID-г буцаана (id өөрөө орохгүй).
+// Цикл шалгалтад ашиглагдах тул устгагдсан байгууллагаар дамжсан холбоосыг ч тооцно.
+func (r *organizationRepository) DescendantIDs(ctx context.Context, id int) ([]int, error) {
+	var ids []int
+	err := r.db.WithContext(ctx).Raw(`
+		WITH RECURSIVE descendants(id) AS (
+			SELECT o.id FROM organizations o WHERE o.parent_id = ?
+			UNION
+			SELECT o.id FROM organizations o
+			JOIN descendants d ON o.parent_id = d.id
+		)
+		SELECT id FROM descendants
+	`, id).Scan(&ids).Error
+	return ids, err
+}
+
+// Move нь байгууллагыг parentID-ийн доор шилжүүлнэ (nil бол root болгоно).
+// Цикл шалгалтыг service layer хийнэ (DescendantIDs).
+func (r *organizationRepository) Move(uctx context.Context, id int, parentID *int) error {
+	updates := map[string]any{"parent_id": parentID}
+	if userId, ok := ctx.GetValue[int](uctx, ctx.KeyUserID); ok {
+		updates["updated_user_id"] = userId
+	}
+	if orgId, ok := ctx.GetValue[int](uctx, ctx.KeyOrgID); ok {
+		updates["updated_org_id"] = orgId
+	}
+
+	res := r.db.WithContext(uctx).
+		Model(&domain.Organization{}).
+		Where("id = ?", id).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 type OrganizationTypeRepository interface {
 	List(ctx context.Context, p common.PaginationQuery) ([]domain.OrganizationType, int64, int, int, error)
 	Create(ctx context.Context, m domain.OrganizationType) error
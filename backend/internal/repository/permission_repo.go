@@ -10,6 +10,7 @@ package repository
 
 import (
 	"context"
+	"strconv"
 	"strings"
 	"templatev25/internal/domain"
 	"templatev25/internal/http/dto"
@@ -35,6 +36,7 @@ type PermissionRepository interface {
 	// Permission шалгах методууд
 	UserHasPermission(ctx context.Context, userID int, permissionCode string) (bool, error)
 	GetUserPermissionCodes(ctx context.Context, userID int) ([]string, error)
+	TraceUserPermission(ctx context.Context, userID int, permissionCode string) ([]domain.PermissionGrantTrace, error)
 }
 
 type permissionRepository struct {
@@ -198,31 +200,33 @@ func (r *permissionRepository) Delete(uctx context.Context, id int) error {
 }
 
 // UserHasPermission нь хэрэглэгч тодорхой permission-тэй эсэхийг шалгана.
-// user_roles -> roles -> role_permissions -> permissions гэсэн холбоосоор шалгана.
+// user_roles -> roles (+ эцэг role-ууд) -> role_permissions -> permissions гэсэн холбоосоор шалгана.
+// Context дахь сонгосон байгууллагын олголт болон global олголтыг тооцно.
+// Эцэг wildcard grant ("admin.role.*", "admin.*", "*") мөн олгосонд тооцогдоно.
 //
 // Parameters:
-//   - ctx: Context
+//   - uctx: Context (ctx.KeyOrgID-оос сонгосон байгууллагыг авна)
 //   - userID: Хэрэглэгчийн ID
 //   - permissionCode: Permission код (жишээ: "admin.role.create")
 //
 // Returns:
 //   - bool: Permission байвал true
 //   - error: Алдаа
-func (r *permissionRepository) UserHasPermission(ctx context.Context, userID int, permissionCode string) (bool, error) {
+func (r *permissionRepository) UserHasPermission(uctx context.Context, userID int, permissionCode string) (bool, error) {
+	cte, args := userRoleTreeCTE(uctx, userID)
+
 	var exists bool
-	err := r.db.WithContext(ctx).Raw(`
+	err := r.db.WithContext(uctx).Raw(cte+`
 		SELECT EXISTS(
 			SELECT 1 FROM permissions p
 			JOIN role_permissions rp ON p.id = rp.permission_id
-			JOIN user_roles ur ON ur.role_id = rp.role_id
-			WHERE ur.user_id = ?
-			AND p.code = ?
+			JOIN user_role_tree t ON t.role_id = rp.role_id
+			WHERE p.code IN ?
 			AND p.is_active = true
 			AND p.deleted_date IS NULL
 			AND rp.deleted_date IS NULL
-			AND ur.deleted_date IS NULL
 		)
-	`, userID, permissionCode).Scan(&exists).Error
+	`, append(args, domain.PermissionGrantCodes(permissionCode))...).Scan(&exists).Error
 	if err != nil {
 		return false, err
 	}
@@ -230,29 +234,173 @@ func (r *permissionRepository) UserHasPermission(ctx context.Context, userID int
 }
 
 // GetUserPermissionCodes нь хэрэглэгчийн бүх permission код-уудыг буцаана.
-// user_roles -> roles -> role_permissions -> permissions гэсэн холбоосоор авна.
+// Хэрэглэгчийн role-ууд болон тэдгээрийн бүх өвөг role-уудын permission-ууд орно.
+// Context дахь сонгосон байгууллагын олголт болон global олголтыг тооцно.
 //
 // Parameters:
-//   - ctx: Context
+//   - uctx: Context (ctx.KeyOrgID-оос сонгосон байгууллагыг авна)
 //   - userID: Хэрэглэгчийн ID
 //
 // Returns:
 //   - []string: Permission кодуудын жагсаалт
 //   - error: Алдаа
-func (r *permissionRepository) GetUserPermissionCodes(ctx context.Context, userID int) ([]string, error) {
+func (r *permissionRepository) GetUserPermissionCodes(uctx context.Context, userID int) ([]string, error) {
+	cte, args := userRoleTreeCTE(uctx, userID)
+
 	var codes []string
-	err := r.db.WithContext(ctx).Raw(`
+	err := r.db.WithContext(uctx).Raw(cte+`
 		SELECT DISTINCT p.code FROM permissions p
 		JOIN role_permissions rp ON p.id = rp.permission_id
-		JOIN user_roles ur ON ur.role_id = rp.role_id
-		WHERE ur.user_id = ?
-		AND p.is_active = true
+		JOIN user_role_tree t ON t.role_id = rp.role_id
+		WHERE p.is_active = true
 		AND p.deleted_date IS NULL
 		AND rp.deleted_date IS NULL
-		AND ur.deleted_date IS NULL
-	`, userID).Scan(&codes).Error
+	`, args...).Scan(&codes).Error
 	if err != nil {
 		return nil, err
 	}
 	return codes, nil
 }
+
+// TraceUserPermission нь хэрэглэгчээс permissionCode-ийг олгох боломжтой бүх замыг
+// (wildcard grant болон өвлөлтийг оруулаад) шүүлтгүйгээр буцаана.
+// Устгагдсан/хугацаа дууссан олголт, устгагдсан role, идэвхгүй permission зэрэг нь
+// trace-д тэмдэглэгдэх ба аль зам хүчинтэйг service тооцно.
+//
+// Parameters:
+//   - ctx: Context
+//   - userID: Хэрэглэгчийн ID
+//   - permissionCode: Шалгах permission код
+//
+// Returns:
+//   - []domain.PermissionGrantTrace: Олгосон role-оор, дараа нь grant кодоор эрэмбэлсэн замууд
+//   - error: Алдаа
+func (r *permissionRepository) TraceUserPermission(ctx context.Context, userID int, permissionCode string) ([]domain.PermissionGrantTrace, error) {
+	type traceRow struct {
+		OrgID                 *int
+		AssignmentDeleted     bool
+		ExpiresAt             *time.Time
+		RolePath              string
+		RolePermissionDeleted bool
+		PermissionID          int
+		PermissionCode        string
+		PermissionActive      bool
+		PermissionDeleted     bool
+	}
+
+	// path нь давхардсан role-оор дахин орохоос сэргийлнэ (role_parents дахь цикл)
+	var rows []traceRow
+	err := r.db.WithContext(ctx).Raw(`
+		WITH RECURSIVE role_chain(role_id, org_id, assignment_deleted, expires_at, path) AS (
+			SELECT ur.role_id, ur.organization_id, ur.deleted_date IS NOT NULL, ur.expires_at, ARRAY[ur.role_id]
+			FROM user_roles ur
+			WHERE ur.user_id = ?
+			UNION ALL
+			SELECT rh.parent_id, c.org_id, c.assignment_deleted, c.expires_at, c.path || rh.parent_id
+			FROM role_parents rh
+			JOIN role_chain c ON rh.role_id = c.role_id
+			WHERE NOT rh.parent_id = ANY(c.path)
+		)
+		SELECT c.org_id,
+			c.assignment_deleted,
+			c.expires_at,
+			array_to_string(c.path, ',') AS role_path,
+			rp.deleted_date IS NOT NULL AS role_permission_deleted,
+			p.id AS permission_id,
+			p.code AS permission_code,
+			COALESCE(p.is_active, false) AS permission_active,
+			p.deleted_date IS NOT NULL AS permission_deleted
+		FROM role_chain c
+		JOIN role_permissions rp ON rp.role_id = c.role_id
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE p.code IN ?
+		ORDER BY c.path, p.code
+	`, userID, domain.PermissionGrantCodes(permissionCode)).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	// Гинжин дэх role-уудыг (устгагдсаныг оролцуулан) ачаалах
+	paths := make([][]int, len(rows))
+	var roleIDs []int
+	for i, row := range rows {
+		for _, s := range strings.Split(row.RolePath, ",") {
+			id, err := strconv.Atoi(s)
+			if err != nil {
+				return nil, err
+			}
+			paths[i] = append(paths[i], id)
+			roleIDs = append(roleIDs, id)
+		}
+	}
+	var roles []domain.Role
+	if err := r.db.WithContext(ctx).Unscoped().Where("id IN ?", roleIDs).Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[int]domain.Role, len(roles))
+	for _, role := range roles {
+		byID[role.ID] = role
+	}
+
+	out := make([]domain.PermissionGrantTrace, 0, len(rows))
+	for i, row := range rows {
+		t := domain.PermissionGrantTrace{
+			Assignment: domain.UserRole{
+				UserId:    userID,
+				RoleID:    paths[i][0],
+				OrgID:     row.OrgID,
+				ExpiresAt: row.ExpiresAt,
+			},
+			RolePermissionDeleted: row.RolePermissionDeleted,
+			Permission: domain.Permission{
+				ID:       row.PermissionID,
+				Code:     row.PermissionCode,
+				IsActive: &row.PermissionActive,
+			},
+		}
+		t.Assignment.DeletedDate.Valid = row.AssignmentDeleted
+		t.Permission.DeletedDate.Valid = row.PermissionDeleted
+		for _, id := range paths[i] {
+			t.Roles = append(t.Roles, byID[id])
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// userRoleTreeCTE нь хэрэглэгчийн шууд олгогдсон role-ууд болон тэдгээрийн
+// бүх өвөг role-уудыг (role_parents) агуулсан user_role_tree CTE буцаана.
+// Устгагдсан role, түүгээр дамжих өвлөлт болон хугацаа дууссан олголт тооцогдохгүй.
+// TraceUserPermission нь эдгээр нөхцөлийг тайлбарлах, notification_repo.roleHoldersSQL
+// нь урвуу чиглэлд давтах тул хамт өөрчилнө.
+// UNION нь давхардлыг хасдаг тул role_parents-д цикл байсан ч дуусна.
+func userRoleTreeCTE(uctx context.Context, userID int) (string, []any) {
+	orgCond, orgArgs := userRoleOrgCondition(uctx)
+	return `
+		WITH RECURSIVE user_role_tree(role_id) AS (
+			SELECT ur.role_id FROM user_roles ur
+			JOIN roles r ON r.id = ur.role_id AND r.deleted_date IS NULL
+			WHERE ur.user_id = ?
+			AND ur.deleted_date IS NULL
+			-- Хугацаа дууссан олголт эрх олгохгүй (explain-ийн assignment_expired)
+			AND (ur.expires_at IS NULL OR ur.expires_at > NOW())
+			AND ` + orgCond + `
+			UNION
+			SELECT rh.parent_id FROM role_parents rh
+			JOIN user_role_tree t ON t.role_id = rh.role_id
+			JOIN roles r ON r.id = rh.parent_id AND r.deleted_date IS NULL
+		)`, append([]any{userID}, orgArgs...)
+}
+
+// userRoleOrgCondition нь user_roles (ur)-ийг сонгосон байгууллагаар шүүх нөхцөл буцаана.
+//   - Байгууллага сонгоогүй: зөвхөн global олголт (organization_id IS NULL)
+//   - Байгууллага сонгосон: global олголт + тухайн байгууллагын олголт
+func userRoleOrgCondition(uctx context.Context) (string, []any) {
+	if orgID, ok := ctx.GetValue[int](uctx, ctx.KeyOrgID); ok && orgID > 0 {
+		return "(ur.organization_id IS NULL OR ur.organization_id = ?)", []any{orgID}
+	}
+	return "ur.organization_id IS NULL", nil
+}
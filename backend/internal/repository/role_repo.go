@@ -10,6 +10,9 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"strings"
 	"time"
 
 	"templatev25/internal/domain"
@@ -22,6 +25,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// ErrRoleCycle нь эцэг role онооход өвлөлтийн цикл үүсэх үед буцна.
+var ErrRoleCycle = errors.New("role inheritance cycle")
+
 type RoleRepository interface {
 	// model_repo шиг PaginationQuery дамжуулдаг
 	List(ctx context.Context, p dto.RoleListQuery) ([]domain.Role, int64, int, int, error)
@@ -33,7 +39,15 @@ type RoleRepository interface {
 
 	Permissions(ctx context.Context, q dto.RolePermissionsQuery) ([]domain.Permission, error)
 	ReplacePermissions(ctx context.Context, roleID int, permIDs []int) error
+	MatchingPermissions(ctx context.Context, patterns []string) ([]domain.Permission, error)
 	GetUserCount(uctx context.Context, id int) int64
+
+	// Role өвлөлт (role_parents)
+	ReplaceParents(ctx context.Context, roleID int, parentIDs []int) error
+	// UpdateWithParents нь role болон эцэг role-уудыг нэг transaction-д солино
+	UpdateWithParents(ctx context.Context, id int, m domain.Role, parentIDs []int) error
+	DescendantIDs(ctx context.Context, roleID int) ([]int, error)
+	UserIDsByRoles(ctx context.Context, roleIDs []int) ([]int, error)
 }
 
 type roleRepository struct {
@@ -81,7 +95,7 @@ func (r *roleRepository) List(uctx context.Context, p dto.RoleListQuery) ([]doma
 	var items []domain.Role
 	if err := tx.Scopes(
 		scopes.SortScope(colMap, utils.ParseSort(p.Sort), "id DESC"),
-	).Offset(offset).Limit(size).Preload("System").Find(&items).Error; err != nil {
+	).Offset(offset).Limit(size).Preload("System").Preload("Parents").Find(&items).Error; err != nil {
 		return nil, 0, 0, 0, err
 	}
 
@@ -101,7 +115,8 @@ func (r *roleRepository) Create(uctx context.Context, m domain.Role) error {
 		m.CreatedOrgId = orgId
 	}
 
-	return r.db.WithContext(uctx).Create(&m).Error
+	// Parents-ийн зөвхөн role_parents холбоосыг үүсгэнэ (эцэг role-уудыг upsert хийхгүй)
+	return r.db.WithContext(uctx).Omit("Parents.*").Create(&m).Error
 }
 
 func (r *roleRepository) Update(uctx context.Context, id int, m domain.Role) error {
@@ -113,8 +128,10 @@ func (r *roleRepository) Update(uctx context.Context, id int, m domain.Role) err
 		m.UpdatedOrgId = orgId
 	}
 
+	// Эцэг role-уудыг UpdateWithParents солино
 	return r.db.WithContext(uctx).
 		Model(&domain.Role{}).
+		Omit("Parents").
 		Where("id = ?", id).
 		Updates(&m).Error
 }
@@ -179,8 +196,132 @@ func (r *roleRepository) ReplacePermissions(ctx context.Context, roleID int, per
 	})
 }
 
+// MatchingPermissions нь wildcard grant-уудын ("admin.user.*", "*") хамрах
+// идэвхтэй permission-уудыг буцаана. Role permission editor-т ашиглана.
+func (r *roleRepository) MatchingPermissions(ctx context.Context, patterns []string) ([]domain.Permission, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	tx := r.db.WithContext(ctx).Model(&domain.Permission{}).Preload("Module").Where("is_active = ?", true)
+
+	// "*" бүх permission-ийг хамардаг тул code-оор шүүхгүй
+	if !slices.Contains(patterns, domain.PermissionWildcard) {
+		conds := make([]string, 0, len(patterns))
+		args := make([]any, 0, len(patterns))
+		for _, p := range patterns {
+			prefix := strings.TrimSuffix(p, domain.PermissionWildcard)
+			conds = append(conds, `code LIKE ? ESCAPE '\'`)
+			args = append(args, likeEscaper.Replace(prefix)+"%")
+		}
+		tx = tx.Where(strings.Join(conds, " OR "), args...)
+	}
+
+	var out []domain.Permission
+	err := tx.Order("code").Find(&out).Error
+	return out, err
+}
+
+// likeEscaper нь LIKE pattern-ийн тусгай тэмдэгтүүдийг escape хийнэ
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 func (r *roleRepository) GetUserCount(uctx context.Context, id int) int64 {
 	cnt := int64(0)
 	r.db.WithContext(uctx).Model(&domain.UserRole{}).Where("role_id = ?", id).Count(&cnt)
 	return cnt
 }
+
+// ReplaceParents нь role-ийн эцэг role-уудыг parentIDs-ээр солино.
+// Цикл үүсэх бол ErrRoleCycle буцаана.
+func (r *roleRepository) ReplaceParents(ctx context.Context, roleID int, parentIDs []int) error {
+	return WithTx(ctx, r.db, func(tx *gorm.DB) error {
+		return replaceParentsTx(tx, roleID, parentIDs)
+	})
+}
+
+// UpdateWithParents нь role-ийн талбарууд болон эцэг role-уудыг нэг
+// transaction-д шинэчилнэ. Цикл үүсэх бол юу ч бичихгүйгээр ErrRoleCycle буцаана.
+func (r *roleRepository) UpdateWithParents(ctx context.Context, id int, m domain.Role, parentIDs []int) error {
+	return WithTx(ctx, r.db, func(tx *gorm.DB) error {
+		if err := replaceParentsTx(tx, id, parentIDs); err != nil {
+			return err
+		}
+		return NewRoleRepository(tx).Update(ctx, id, m)
+	})
+}
+
+// replaceParentsTx нь цикл шалгаад role_parents-ийг солино.
+//
+// Зэрэг ирсэн A→B, B→A шинэчлэлт хоёулаа хуучин графаар шалгагдаж цикл
+// үүсгэхээс сэргийлж role_parents-ийг SHARE ROW EXCLUSIVE горимоор түгжинэ:
+// энэ горим өөртэйгөө зөрчилддөг тул өөрчлөлтүүд ээлжилнэ, харин
+// permission шалгах уншилтыг (ACCESS SHARE) хориглохгүй.
+func replaceParentsTx(tx *gorm.DB, roleID int, parentIDs []int) error {
+	if slices.Contains(parentIDs, roleID) {
+		return ErrRoleCycle
+	}
+	if err := tx.Exec("LOCK TABLE role_parents IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+		return err
+	}
+
+	if len(parentIDs) > 0 {
+		descendants, err := descendantIDs(tx, roleID)
+		if err != nil {
+			return err
+		}
+		for _, pid := range parentIDs {
+			if slices.Contains(descendants, pid) {
+				return ErrRoleCycle
+			}
+		}
+	}
+
+	if err := tx.Where("role_id = ?", roleID).Delete(&domain.RoleParent{}).Error; err != nil {
+		return err
+	}
+	if len(parentIDs) == 0 {
+		return nil
+	}
+
+	links := make([]domain.RoleParent, 0, len(parentIDs))
+	for _, pid := range parentIDs {
+		links = append(links, domain.RoleParent{RoleID: roleID, ParentID: pid})
+	}
+	return tx.Create(&links).Error
+}
+
+// DescendantIDs нь roleID-оос (шууд болон дамжин) өвлөдөг бүх role-уудын ID-г буцаана.
+// roleID өөрөө орохгүй (цикл байхгүй үед).
+func (r *roleRepository) DescendantIDs(ctx context.Context, roleID int) ([]int, error) {
+	return descendantIDs(r.db.WithContext(ctx), roleID)
+}
+
+func descendantIDs(db *gorm.DB, roleID int) ([]int, error) {
+	var ids []int
+	err := db.Raw(`
+		WITH RECURSIVE descendants(role_id) AS (
+			SELECT rh.role_id FROM role_parents rh WHERE rh.parent_id = ?
+			UNION
+			SELECT rh.role_id FROM role_parents rh
+			JOIN descendants d ON rh.parent_id = d.role_id
+		)
+		SELECT role_id FROM descendants
+	`, roleID).Scan(&ids).Error
+	return ids, err
+}
+
+// UserIDsByRoles нь өгсөн role-уудын аль нэг нь олгогдсон хэрэглэгчдийн ID-г буцаана.
+// Permission cache invalidation-д ашиглана.
+func (r *roleRepository) UserIDsByRoles(ctx context.Context, roleIDs []int) ([]int, error) {
+	if len(roleIDs) == 0 {
+		return nil, nil
+	}
+
+	var ids []int
+	err := r.db.WithContext(ctx).
+		Model(&domain.UserRole{}).
+		Where("role_id IN ?", roleIDs).
+		Distinct().
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
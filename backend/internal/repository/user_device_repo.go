@@ -0,0 +1,102 @@
+// Package repository provides implementation for repository
+//
+// File: user_device_repo.go
+// Description: Device registry (user_devices) and push token lookup
+package repository
+
+import (
+	"context"
+	"time"
+
+	"templatev25/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UserDeviceRepository interface {
+	ListByUser(ctx context.Context, userID int) ([]domain.UserDevice, error)
+	// Register нь (user_id, device_id)-аар үүсгэх эсвэл шинэчилж идэвхжүүлнэ.
+	// Ижил push token-той бусад хэрэглэгчийн төхөөрөмжийг идэвхгүй болгоно.
+	Register(ctx context.Context, d domain.UserDevice) (domain.UserDevice, error)
+	// Unregister нь төхөөрөмжийг устгана; байхгүй бол gorm.ErrRecordNotFound.
+	Unregister(ctx context.Context, userID int, deviceID string) error
+
+	// ActivePushDevices нь push token-той идэвхтэй төхөөрөмжүүд.
+	ActivePushDevices(ctx context.Context, userID int) ([]domain.UserDevice, error)
+	// Deactivate нь provider хүчингүй гэж мэдээлсэн token-той төхөөрөмжийг
+	// идэвхгүй болгоно. Илгээх хооронд шинэ token бүртгэгдсэн бол өөрчлөхгүй.
+	Deactivate(ctx context.Context, id int, pushToken string) error
+}
+
+type userDeviceRepository struct{ db *gorm.DB }
+
+func NewUserDeviceRepository(db *gorm.DB) UserDeviceRepository {
+	return &userDeviceRepository{db: db}
+}
+
+func (r *userDeviceRepository) ListByUser(ctx context.Context, userID int) ([]domain.UserDevice, error) {
+	var items []domain.UserDevice
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("last_used_at DESC NULLS LAST, id DESC").
+		Find(&items).Error
+	return items, err
+}
+
+func (r *userDeviceRepository) Register(ctx context.Context, d domain.UserDevice) (domain.UserDevice, error) {
+	now := time.Now()
+	d.Id = 0
+	d.IsActive = true
+	d.LastUsedAt = &now
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Нэг token нэг л хэрэглэгчид хүрнэ (төхөөрөмж дээр account солигдсон)
+		if d.PushToken != "" {
+			if err := tx.Model(&domain.UserDevice{}).
+				Where("push_token = ? AND NOT (user_id = ? AND device_id = ?)", d.PushToken, d.UserId, d.DeviceId).
+				Updates(map[string]any{"is_active": false, "push_token": nil}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Clauses(clause.Returning{}, clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}, {Name: "device_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"device_type", "device_name", "device_model", "os_name", "os_version",
+				"app_version", "push_token", "push_provider", "is_active", "last_used_at", "updated_date",
+			}),
+		}).Create(&d).Error
+	})
+	if err != nil {
+		return domain.UserDevice{}, err
+	}
+	return d, nil
+}
+
+func (r *userDeviceRepository) Unregister(ctx context.Context, userID int, deviceID string) error {
+	res := r.db.WithContext(ctx).
+		Where("user_id = ? AND device_id = ?", userID, deviceID).
+		Delete(&domain.UserDevice{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *userDeviceRepository) ActivePushDevices(ctx context.Context, userID int) ([]domain.UserDevice, error) {
+	var items []domain.UserDevice
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND is_active AND push_token IS NOT NULL AND push_token <> ''", userID).
+		Order("id").
+		Find(&items).Error
+	return items, err
+}
+
+func (r *userDeviceRepository) Deactivate(ctx context.Context, id int, pushToken string) error {
+	return r.db.WithContext(ctx).Model(&domain.UserDevice{}).
+		Where("id = ? AND push_token = ?", id, pushToken).
+		Update("is_active", false).Error
+}
@@ -23,9 +23,28 @@ import (
 type UserRoleRepository interface {
 	UsersByRole(ctx context.Context, q dto.UserRoleUsersQuery) ([]domain.UserRole, int64, int, int, error)
 	RolesByUser(ctx context.Context, q dto.UserRoleRolesQuery) ([]domain.UserRole, int64, int, int, error)
-	AddUsersToRole(ctx context.Context, roleID int, userIDs []int) error
-	AddRolesToUser(ctx context.Context, userID int, roleIDs []int) error
-	Remove(ctx context.Context, userID, roleID int) error
+	AddUsersToRole(ctx context.Context, roleID int, orgID *int, userIDs []int) error
+	AddRolesToUser(ctx context.Context, userID int, orgID *int, roleIDs []int) error
+	Remove(ctx context.Context, userID, roleID int, orgID *int) error
+}
+
+// userRoleConflict нь migration 016-ийн unique index-тэй тохирно.
+// Global олголт (organization_id NULL) давхардахгүйн тулд COALESCE ашиглана.
+var userRoleConflict = clause.OnConflict{
+	Columns: []clause.Column{
+		{Name: "user_id"},
+		{Name: "role_id"},
+		{Name: "COALESCE(organization_id, 0)", Raw: true},
+	},
+	DoNothing: true,
+}
+
+// whereUserRoleOrg нь олголтыг байгууллагаар шүүнэ (nil бол global олголт)
+func whereUserRoleOrg(tx *gorm.DB, orgID *int) *gorm.DB {
+	if orgID == nil {
+		return tx.Where("organization_id IS NULL")
+	}
+	return tx.Where("organization_id = ?", *orgID)
 }
 
 type userRoleRepository struct{ db *gorm.DB }
@@ -37,6 +56,9 @@ func (r *userRoleRepository) UsersByRole(ctx context.Context, q dto.UserRoleUser
 	page, size, offset := utils.OffsetLimit(q.PaginationQuery)
 
 	tx := r.db.WithContext(ctx).Model(&domain.UserRole{}).Where("role_id = ?", q.RoleID)
+	if q.OrgID > 0 {
+		tx = tx.Where("organization_id = ?", q.OrgID)
+	}
 	var total int64
 	if err := tx.Count(&total).Error; err != nil {
 		return nil, 0, 0, 0, err
@@ -56,6 +78,9 @@ func (r *userRoleRepository) RolesByUser(ctx context.Context, q dto.UserRoleRole
 	page, size, offset := utils.OffsetLimit(q.PaginationQuery)
 
 	tx := r.db.WithContext(ctx).Model(&domain.UserRole{}).Where("user_id = ?", q.UserID)
+	if q.OrgID > 0 {
+		tx = tx.Where("organization_id = ?", q.OrgID)
+	}
 	var total int64
 	if err := tx.Count(&total).Error; err != nil {
 		return nil, 0, 0, 0, err
@@ -73,7 +98,7 @@ func (r *userRoleRepository) RolesByUser(ctx context.Context, q dto.UserRoleRole
 
 // POST assign by role
 // Batch insert with ON CONFLICT - N queries -> 1 query
-func (r *userRoleRepository) AddUsersToRole(ctx context.Context, roleID int, userIDs []int) error {
+func (r *userRoleRepository) AddUsersToRole(ctx context.Context, roleID int, orgID *int, userIDs []int) error {
 	if len(userIDs) == 0 {
 		return nil
 	}
@@ -81,19 +106,16 @@ func (r *userRoleRepository) AddUsersToRole(ctx context.Context, roleID int, use
 	// Build batch of UserRole records
 	links := make([]domain.UserRole, 0, len(userIDs))
 	for _, uid := range userIDs {
-		links = append(links, domain.UserRole{RoleID: roleID, UserId: uid})
+		links = append(links, domain.UserRole{RoleID: roleID, UserId: uid, OrgID: orgID})
 	}
 
 	// Single batch insert with ON CONFLICT DO NOTHING (idempotent)
-	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "user_id"}, {Name: "role_id"}},
-		DoNothing: true,
-	}).Create(&links).Error
+	return r.db.WithContext(ctx).Clauses(userRoleConflict).Create(&links).Error
 }
 
 // POST assign by user
 // Batch insert with ON CONFLICT - N queries -> 1 query
-func (r *userRoleRepository) AddRolesToUser(ctx context.Context, userID int, roleIDs []int) error {
+func (r *userRoleRepository) AddRolesToUser(ctx context.Context, userID int, orgID *int, roleIDs []int) error {
 	if len(roleIDs) == 0 {
 		return nil
 	}
@@ -101,16 +123,14 @@ func (r *userRoleRepository) AddRolesToUser(ctx context.Context, userID int, rol
 	// Build batch of UserRole records
 	links := make([]domain.UserRole, 0, len(roleIDs))
 	for _, rid := range roleIDs {
-		links = append(links, domain.UserRole{RoleID: rid, UserId: userID})
+		links = append(links, domain.UserRole{RoleID: rid, UserId: userID, OrgID: orgID})
 	}
 
 	// Single batch insert with ON CONFLICT DO NOTHING (idempotent)
-	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "user_id"}, {Name: "role_id"}},
-		DoNothing: true,
-	}).Create(&links).Error
+	return r.db.WithContext(ctx).Clauses(userRoleConflict).Create(&links).Error
 }
 
-func (r *userRoleRepository) Remove(ctx context.Context, userID, roleID int) error {
-	return r.db.WithContext(ctx).Where("role_id = ? AND user_id = ?", roleID, userID).Delete(&domain.UserRole{}).Error
+func (r *userRoleRepository) Remove(ctx context.Context, userID, roleID int, orgID *int) error {
+	tx := r.db.WithContext(ctx).Where("role_id = ? AND user_id = ?", roleID, userID)
+	return whereUserRoleOrg(tx, orgID).Delete(&domain.UserRole{}).Error
 }
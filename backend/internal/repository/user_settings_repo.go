@@ -0,0 +1,89 @@
+// Package repository provides implementation for repository
+//
+// File: user_settings_repo.go
+// Description: Per-user settings (user_settings)
+package repository
+
+import (
+	"context"
+
+	"templatev25/internal/domain"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// userSettingsColumns нь Upsert-ийн үед шинэчлэгдэх баганууд.
+var userSettingsColumns = []string{
+	"notification_push",
+	"notification_email",
+	"notification_sms",
+	"theme",
+	"language",
+	"privacy_profile_public",
+	"privacy_show_email",
+	"privacy_show_phone",
+	"custom_settings",
+	"updated_date",
+}
+
+type UserSettingsRepository interface {
+	// ByUserID нь мөр байхгүй бол gorm.ErrRecordNotFound буцаана.
+	ByUserID(ctx context.Context, userID int) (domain.UserSettings, error)
+	// Upsert нь user_id-аар үүсгэх эсвэл бүх талбарыг солино.
+	Upsert(ctx context.Context, m domain.UserSettings) (domain.UserSettings, error)
+	// Modify нь хэрэглэгчийн мөрийг (байхгүй бол default утгаар үүсгэж)
+	// түгжээд apply-г хэрэглэж нэг transaction-д хадгална. Зэрэг ирсэн
+	// шинэчлэлүүд дараалж ажиллах тул бие биенийхээ талбарыг дарахгүй.
+	Modify(ctx context.Context, userID int, apply func(m *domain.UserSettings)) (domain.UserSettings, error)
+}
+
+type userSettingsRepository struct{ db *gorm.DB }
+
+func NewUserSettingsRepository(db *gorm.DB) UserSettingsRepository {
+	return &userSettingsRepository{db: db}
+}
+
+func (r *userSettingsRepository) ByUserID(ctx context.Context, userID int) (domain.UserSettings, error) {
+	var m domain.UserSettings
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Take(&m).Error
+	return m, err
+}
+
+func (r *userSettingsRepository) Upsert(ctx context.Context, m domain.UserSettings) (domain.UserSettings, error) {
+	m.Id = 0
+	if err := r.db.WithContext(ctx).Clauses(clause.Returning{}, clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns(userSettingsColumns),
+	}).Create(&m).Error; err != nil {
+		return domain.UserSettings{}, err
+	}
+	return m, nil
+}
+
+func (r *userSettingsRepository) Modify(ctx context.Context, userID int, apply func(m *domain.UserSettings)) (domain.UserSettings, error) {
+	var m domain.UserSettings
+	err := WithTx(ctx, r.db, func(tx *gorm.DB) error {
+		// Мөр байхгүй бол түгжих мөртэй болгоно (зэрэг үүсгэлт DO NOTHING)
+		def := domain.DefaultUserSettings(userID)
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoNothing: true,
+		}).Create(&def).Error; err != nil {
+			return err
+		}
+
+		// SELECT ... FOR UPDATE: өөр Modify commit хийтэл хүлээнэ
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).Take(&m).Error; err != nil {
+			return err
+		}
+
+		apply(&m)
+		return tx.Model(&m).Select(userSettingsColumns).Updates(&m).Error
+	})
+	if err != nil {
+		return domain.UserSettings{}, err
+	}
+	return m, nil
+}
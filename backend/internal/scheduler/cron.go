@@ -0,0 +1,284 @@
+// Package scheduler provides implementation for scheduler
+//
+// File: cron.go
+// Description: Cron expression parser and next-run calculation
+/*
+Package scheduler нь scheduled_jobs хүснэгтийн job-уудыг cron хуваарийн
+дагуу ажиллуулна.
+
+Энэ файл нь стандарт 5 талбарт cron илэрхийллийг parse хийнэ:
+
+	┌───────────── минут (0-59)
+	│ ┌─────────── цаг (0-23)
+	│ │ ┌───────── сарын өдөр (1-31)
+	│ │ │ ┌─────── сар (1-12 эсвэл JAN-DEC)
+	│ │ │ │ ┌───── гараг (0-6 эсвэл SUN-SAT, 7 = ням)
+	* * * * *
+
+Талбар бүр "*", "?", жагсаалт (1,15), муж (1-5), алхам (0-59/15, 10-40/5)
+дэмжинэ. Сарын өдөр болон гараг хоёулаа хязгаарлагдсан бол аль нэг нь
+таарахад ажиллана (Vixie cron-ийн дүрэм).
+
+Товчлолууд: @yearly (@annually), @monthly, @weekly, @daily (@midnight),
+@hourly, @every <duration> (жишээ: @every 90s).
+*/
+package scheduler
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule нь дараагийн ажиллах хугацааг тооцоолно.
+type Schedule interface {
+	// Next нь t-ээс хойших анхны ажиллах хугацааг буцаана.
+	// Ийм хугацаа байхгүй бол (жишээ: 2-р сарын 30) zero time буцаана.
+	Next(t time.Time) time.Time
+}
+
+// cronField нь нэг талбарын зөвшөөрөгдөх утгуудын хүрээ.
+type cronField struct {
+	name     string
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	domField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// Гараг нь 7-г ням гэж хүлээн авна (parse-ийн дараа 0 руу нугална)
+	dowField = cronField{name: "day of week", min: 0, max: 7, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// descriptors нь @-тэй товчлолуудын 5 талбарт хувилбар.
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// maxSearchYears нь Next-ийн хайх дээд хязгаар (таарах огноо байхгүй илэрхийлэлд).
+const maxSearchYears = 5
+
+// CronSchedule нь parse хийсэн 5 талбарт cron илэрхийлэл.
+// Талбар бүр bitmask (bit n = утга n зөвшөөрөгдсөн).
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// domStar/dowStar нь талбар "*" байсан эсэх (OR/AND дүрэмд хэрэгтэй)
+	domStar, dowStar bool
+}
+
+// EverySchedule нь тогтмол интервалтай хуваарь (@every).
+type EverySchedule struct {
+	Interval time.Duration
+}
+
+// Next нь t + Interval-ийг секундээр тоймлон буцаана.
+func (s EverySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.Interval).Truncate(time.Second)
+}
+
+// ParseCron нь cron илэрхийллийг parse хийнэ.
+func ParseCron(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("cron: empty expression")
+	}
+
+	if strings.HasPrefix(expr, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(expr, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("cron: invalid @every duration: %w", err)
+		}
+		if d < time.Second {
+			return nil, fmt.Errorf("cron: @every interval must be at least 1s")
+		}
+		return EverySchedule{Interval: d}, nil
+	}
+	if strings.HasPrefix(expr, "@") {
+		full, ok := descriptors[strings.ToLower(expr)]
+		if !ok {
+			return nil, fmt.Errorf("cron: unknown descriptor %q", expr)
+		}
+		expr = full
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("cron: expected 5 fields, got %d", len(parts))
+	}
+
+	s := &CronSchedule{}
+	var err error
+	if s.minute, err = minuteField.parse(parts[0]); err != nil {
+		return nil, err
+	}
+	if s.hour, err = hourField.parse(parts[1]); err != nil {
+		return nil, err
+	}
+	if s.dom, err = domField.parse(parts[2]); err != nil {
+		return nil, err
+	}
+	if s.month, err = monthField.parse(parts[3]); err != nil {
+		return nil, err
+	}
+	if s.dow, err = dowField.parse(parts[4]); err != nil {
+		return nil, err
+	}
+	// 7 (ням) → 0
+	if s.dow&(1<<7) != 0 {
+		s.dow = (s.dow &^ (1 << 7)) | 1
+	}
+	s.domStar = isStar(parts[2])
+	s.dowStar = isStar(parts[4])
+	return s, nil
+}
+
+func isStar(field string) bool {
+	return field == "*" || field == "?"
+}
+
+// parse нь нэг талбарыг ("," жагсаалт) bitmask болгоно.
+func (f cronField) parse(field string) (uint64, error) {
+	var mask uint64
+	for _, item := range strings.Split(field, ",") {
+		m, err := f.parseItem(item)
+		if err != nil {
+			return 0, err
+		}
+		mask |= m
+	}
+	return mask, nil
+}
+
+// parseItem нь "*", "a", "a-b", "*/n", "a/n", "a-b/n" хэлбэрийг parse хийнэ.
+func (f cronField) parseItem(item string) (uint64, error) {
+	if item == "" {
+		return 0, fmt.Errorf("cron: empty %s value", f.name)
+	}
+
+	rangePart, stepPart, hasStep := strings.Cut(item, "/")
+	step := uint(1)
+	if hasStep {
+		n, err := strconv.ParseUint(stepPart, 10, 8)
+		if err != nil || n == 0 {
+			return 0, fmt.Errorf("cron: invalid %s step %q", f.name, stepPart)
+		}
+		step = uint(n)
+	}
+
+	var lo, hi uint
+	switch {
+	case rangePart == "*" || rangePart == "?":
+		lo, hi = f.min, f.max
+		if f.name == dowField.name {
+			hi = 6 // "*" нь 0-6 (7-г давхар тоолохгүй)
+		}
+	default:
+		from, to, isRange := strings.Cut(rangePart, "-")
+		var err error
+		if lo, err = f.value(from); err != nil {
+			return 0, err
+		}
+		hi = lo
+		if isRange {
+			if hi, err = f.value(to); err != nil {
+				return 0, err
+			}
+		} else if hasStep {
+			// "a/n" нь a-аас max хүртэл
+			hi = f.max
+			if f.name == dowField.name {
+				hi = 6
+			}
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("cron: invalid %s range %q", f.name, rangePart)
+		}
+	}
+
+	var mask uint64
+	for v := lo; v <= hi; v += step {
+		mask |= 1 << v
+	}
+	return mask, nil
+}
+
+// value нь тоо эсвэл нэрийг (jan, mon) утга болгоно.
+func (f cronField) value(s string) (uint, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("cron: invalid %s value %q", f.name, s)
+	}
+	if uint(n) < f.min || uint(n) > f.max {
+		return 0, fmt.Errorf("cron: %s value %d out of range [%d-%d]", f.name, n, f.min, f.max)
+	}
+	return uint(n), nil
+}
+
+// Next нь t-ээс хойших (t-г оруулахгүй) анхны таарах минутыг буцаана.
+// Хугацааг t-ийн location-оор тооцоолно.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = nextSetBit(t, s.minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// nextSetBit нь тухайн цаг дотор дараагийн таарах минут руу,
+// байхгүй бол дараагийн цагийн эхэнд шилжинэ.
+func nextSetBit(t time.Time, minutes uint64) time.Time {
+	rest := minutes >> uint(t.Minute())
+	if rest == 0 {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+	}
+	return t.Add(time.Duration(bits.TrailingZeros64(rest)) * time.Minute)
+}
+
+// dayMatches нь сарын өдөр/гарагийн дүрмийг шалгана.
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	domOK := s.dom&(1<<uint(t.Day())) != 0
+	dowOK := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
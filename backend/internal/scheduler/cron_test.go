@@ -0,0 +1,101 @@
+// Package scheduler provides implementation for scheduler
+//
+// File: cron_test.go
+// Description: Unit tests for the cron expression parser
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustTime(t *testing.T, s string) time.Time {
+	t.Helper()
+	v, err := time.Parse("2006-01-02 15:04", s)
+	require.NoError(t, err)
+	return v
+}
+
+func TestParseCron_Next(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		from string
+		want string
+	}{
+		{"every minute", "* * * * *", "2025-03-10 10:15", "2025-03-10 10:16"},
+		{"fixed time same day", "30 14 * * *", "2025-03-10 10:15", "2025-03-10 14:30"},
+		{"fixed time next day", "30 9 * * *", "2025-03-10 10:15", "2025-03-11 09:30"},
+		{"exact match is excluded", "15 10 * * *", "2025-03-10 10:15", "2025-03-11 10:15"},
+		{"step", "*/15 * * * *", "2025-03-10 10:16", "2025-03-10 10:30"},
+		{"step wraps hour", "*/20 * * * *", "2025-03-10 10:45", "2025-03-10 11:00"},
+		{"range with step", "10-40/15 * * * *", "2025-03-10 10:26", "2025-03-10 10:40"},
+		{"start with step", "50/5 * * * *", "2025-03-10 10:56", "2025-03-10 11:50"},
+		{"list", "0 8,12,18 * * *", "2025-03-10 12:00", "2025-03-10 18:00"},
+		{"weekday names", "0 9 * * mon-fri", "2025-03-08 10:00", "2025-03-10 09:00"}, // Sat → Mon
+		{"sunday as 7", "0 0 * * 7", "2025-03-10 00:00", "2025-03-16 00:00"},
+		{"month names", "0 0 1 jan,jul *", "2025-03-10 00:00", "2025-07-01 00:00"},
+		{"year rollover", "0 0 1 1 *", "2025-03-10 00:00", "2026-01-01 00:00"},
+		{"leap day", "0 0 29 2 *", "2025-03-10 00:00", "2028-02-29 00:00"},
+		{"dom or dow when both restricted", "0 0 13 * 5", "2025-06-01 00:00", "2025-06-06 00:00"}, // Fri 6th before 13th
+		{"dom and dow when dow is star", "0 0 13 * *", "2025-06-01 00:00", "2025-06-13 00:00"},
+		{"hourly descriptor", "@hourly", "2025-03-10 10:15", "2025-03-10 11:00"},
+		{"daily descriptor", "@daily", "2025-03-10 10:15", "2025-03-11 00:00"},
+		{"weekly descriptor", "@weekly", "2025-03-10 10:15", "2025-03-16 00:00"},
+		{"monthly descriptor", "@monthly", "2025-03-10 10:15", "2025-04-01 00:00"},
+		{"every descriptor", "@every 90m", "2025-03-10 10:15", "2025-03-10 11:45"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseCron(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, mustTime(t, tt.want), s.Next(mustTime(t, tt.from)))
+		})
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"1,,2 * * * *",
+		"@often",
+		"@every soon",
+		"@every 500ms",
+	}
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			_, err := ParseCron(expr)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCronSchedule_NeverFires(t *testing.T) {
+	s, err := ParseCron("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, s.Next(mustTime(t, "2025-01-01 00:00")).IsZero())
+}
+
+func TestCronSchedule_KeepsLocation(t *testing.T) {
+	loc := time.FixedZone("ULAT", 8*60*60)
+	s, err := ParseCron("0 3 * * *")
+	require.NoError(t, err)
+
+	next := s.Next(time.Date(2025, 3, 10, 10, 0, 0, 0, loc))
+	assert.Equal(t, time.Date(2025, 3, 11, 3, 0, 0, 0, loc), next)
+	assert.Equal(t, loc, next.Location())
+}
@@ -0,0 +1,69 @@
+// Package scheduler provides implementation for scheduler
+//
+// File: handlers.go
+// Description: Built-in job handlers
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"templatev25/internal/repository"
+)
+
+// PurgeExecutionsHandlerName нь job_executions цэвэрлэх handler-ийн нэр
+// (018_scheduled_jobs_seed.sql-д бүртгэсэн).
+const PurgeExecutionsHandlerName = "job_executions.purge"
+
+// defaultExecutionRetentionDays нь parameters-д retention_days байхгүй үеийн утга.
+const defaultExecutionRetentionDays = 30
+
+// purgeExecutionsParams нь PurgeExecutionsHandler-ийн parameters.
+type purgeExecutionsParams struct {
+	RetentionDays int `json:"retention_days"`
+}
+
+// PurgeExecutionsHandler нь retention_days-ээс хуучин дууссан
+// job_executions мөрүүдийг устгана.
+func PurgeExecutionsHandler(repo repository.JobRepository) Handler {
+	return func(ctx context.Context, raw json.RawMessage) (any, error) {
+		params := purgeExecutionsParams{RetentionDays: defaultExecutionRetentionDays}
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &params); err != nil {
+				return nil, fmt.Errorf("invalid parameters: %w", err)
+			}
+		}
+		if params.RetentionDays <= 0 {
+			return nil, fmt.Errorf("retention_days must be positive, got %d", params.RetentionDays)
+		}
+
+		before := time.Now().AddDate(0, 0, -params.RetentionDays)
+		deleted, err := repo.PurgeExecutions(ctx, before)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"deleted": deleted, "before": before}, nil
+	}
+}
+
+// ExpireUploadsHandlerName нь хугацаа дууссан resumable upload цэвэрлэх
+// handler-ийн нэр (025_file_uploads.sql-д бүртгэсэн).
+const ExpireUploadsHandlerName = "file_uploads.expire"
+
+// UploadExpirer нь хугацаа дууссан upload-уудыг хэсгүүдтэй нь устгана
+// (service.ResumableUploadService).
+type UploadExpirer interface {
+	ExpireUploads(ctx context.Context, now time.Time) (int, error)
+}
+
+// ExpireUploadsHandler нь хаягдсан болон хугацаа нь дууссан resumable
+// upload-уудыг устгана. Parameters хэрэглэхгүй.
+func ExpireUploadsHandler(uploads UploadExpirer) Handler {
+	return func(ctx context.Context, _ json.RawMessage) (any, error) {
+		// Алдаа гарахаас өмнө устгасныг ч мэдээлнэ
+		deleted, err := uploads.ExpireUploads(ctx, time.Now())
+		return map[string]any{"deleted": deleted}, err
+	}
+}
@@ -0,0 +1,525 @@
+// Package scheduler provides implementation for scheduler
+//
+// File: scheduler.go
+// Description: Job runner backed by scheduled_jobs / job_executions
+/*
+Scheduler нь scheduled_jobs хүснэгтийг тогтмол (PollInterval) шалгаж
+хугацаа нь болсон job-уудыг бүртгэгдсэн Go handler-ээр ажиллуулна.
+
+Ажиллах дараалал:
+ 1. next_run_at <= now идэвхтэй job-уудыг уншина
+ 2. Job бүрийн Postgres advisory lock-ийг авахыг оролдоно
+    (өөр replica барьж байвал алгасна). Зэрэг ажиллах job MaxConcurrent-аар
+    хязгаарлагдана; хэтэрвэл үлдсэн нь дараагийн шалгалтаар ажиллана
+ 3. Lock авсны дараа job-ийг дахин уншиж хугацаа нь болсон эсэхийг шалгана
+    (өөр replica дөнгөж ажиллуулсан байж болно)
+ 4. job_executions-д "running" мөр нэмж handler-ийг ажиллуулна
+ 5. Үр дүн, хугацаа, алдааг бичиж next_run_at-ийг cron-оор тооцоолно
+
+next_run_at NULL бол (шинэ job) ажиллуулахгүйгээр зөвхөн хуваарийг тооцоолно.
+Server унтарсан үед алгассан ажиллагаануудыг нөхөхгүй: нэг удаа ажиллаад
+дараагийн хугацааг одооноос тооцоолно.
+
+Ашиглалт:
+
+	s := scheduler.New(repo, scheduler.Options{PollInterval: 15 * time.Second}, log)
+	s.Register("job_executions.purge", scheduler.PurgeExecutionsHandler(repo))
+	s.Start()
+	defer s.Stop()
+*/
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrJobRunning нь job өөр goroutine/replica дээр ажиллаж байгааг илэрхийлнэ.
+	ErrJobRunning = errors.New("job is already running")
+
+	// ErrUnknownHandler нь job-ийн handler бүртгэгдээгүйг илэрхийлнэ.
+	ErrUnknownHandler = errors.New("job handler is not registered")
+
+	// ErrSchedulerStopped нь Stop дуудсаны дараах Trigger-д буцна.
+	ErrSchedulerStopped = errors.New("scheduler is stopped")
+
+	// ErrSchedulerBusy нь MaxConcurrent job ажиллаж байхад Trigger-д буцна.
+	ErrSchedulerBusy = errors.New("too many jobs are running")
+)
+
+// Handler нь job-ийн Go хэрэгжүүлэлт.
+// params нь scheduled_jobs.parameters (JSON), буцаах утга нь
+// job_executions.result-д JSON хэлбэрээр хадгалагдана (nil бол хоосон).
+type Handler func(ctx context.Context, params json.RawMessage) (any, error)
+
+// Options нь scheduler-ийн тохиргоо.
+type Options struct {
+	// PollInterval нь хугацаа нь болсон job шалгах давтамж
+	PollInterval time.Duration
+
+	// Timeout нь нэг ажиллагааны хугацааны хязгаар
+	Timeout time.Duration
+
+	// BatchSize нь нэг шалгалтаар авах job-ийн дээд тоо
+	BatchSize int
+
+	// MaxConcurrent нь зэрэг ажиллах job-ийн дээд тоо. Ажиллаж буй job бүр
+	// advisory lock-ийн DB холболтыг дуустал барьдаг тул pool-ийн
+	// MaxOpenConns-оос хангалттай бага байх ёстой.
+	MaxConcurrent int
+}
+
+const (
+	defaultPollInterval  = 15 * time.Second
+	defaultTimeout       = 10 * time.Minute
+	defaultBatchSize     = 50
+	defaultMaxConcurrent = 4
+
+	// bookkeepingTimeout нь execution бичих DB үйлдлийн хязгаар.
+	// Shutdown үед ч "cancelled" төлөвийг бичих боломжтой байхаар
+	// scheduler-ийн context-оос тусдаа.
+	bookkeepingTimeout = 10 * time.Second
+)
+
+// Scheduler нь scheduled_jobs-ийн job runner.
+type Scheduler struct {
+	repo repository.JobRepository
+	opts Options
+	log  *zap.Logger
+	now  func() time.Time
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+
+	slots chan struct{} // Ажиллаж буй job бүр нэг slot эзэлнэ (MaxConcurrent)
+
+	ctx    context.Context    // Бүх ажиллагааны эцэг context (Stop цуцална)
+	cancel context.CancelFunc // ctx цуцлах
+
+	runMu   sync.Mutex     // stopped болон wg.Add-ийг Stop-той зэрэгцүүлнэ
+	stopped bool           // Stop дуудагдсан
+	wg      sync.WaitGroup // Poll loop + ажиллаж буй job-ууд
+	once    sync.Once
+}
+
+// New нь шинэ Scheduler үүсгэнэ. Start дуудах хүртэл хуваарийн дагуу
+// ажиллуулахгүй ч Trigger-ээр гараар ажиллуулж болно.
+func New(repo repository.JobRepository, opts Options, log *zap.Logger) *Scheduler {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	if opts.MaxConcurrent <= 0 {
+		opts.MaxConcurrent = defaultMaxConcurrent
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		repo:     repo,
+		opts:     opts,
+		log:      log,
+		now:      time.Now,
+		handlers: make(map[string]Handler),
+		slots:    make(chan struct{}, opts.MaxConcurrent),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Register нь handler-ийг нэрээр бүртгэнэ (scheduled_jobs.handler).
+// Ижил нэрээр дахин бүртгэвэл өмнөхийг солино.
+func (s *Scheduler) Register(name string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[name] = h
+}
+
+// Handlers нь бүртгэгдсэн handler-уудын нэрсийг эрэмбэлж буцаана.
+func (s *Scheduler) Handlers() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.handlers))
+	for name := range s.handlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Scheduler) handler(name string) (Handler, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	h, ok := s.handlers[name]
+	return h, ok
+}
+
+// ============================================================
+// LIFECYCLE
+// ============================================================
+
+// Start нь poll loop-ийг эхлүүлнэ.
+func (s *Scheduler) Start() {
+	if !s.spawn(s.loop) {
+		return
+	}
+	s.log.Info("job_scheduler_started",
+		zap.Duration("poll_interval", s.opts.PollInterval),
+		zap.Strings("handlers", s.Handlers()),
+	)
+}
+
+// Stop нь шинэ ажиллагаа эхлүүлэхийг зогсоож, ажиллаж буй job-уудын
+// context-ийг цуцлаад дуусахыг хүлээнэ. Цуцлагдсан ажиллагаа "cancelled" болно.
+func (s *Scheduler) Stop() {
+	s.once.Do(func() {
+		s.runMu.Lock()
+		s.stopped = true
+		s.runMu.Unlock()
+
+		s.cancel()
+		s.wg.Wait()
+	})
+}
+
+// spawn нь fn-ийг Stop хүлээх goroutine болгон эхлүүлнэ.
+// Stop дуудагдсан бол эхлүүлэхгүй, false буцаана.
+func (s *Scheduler) spawn(fn func()) bool {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	if s.stopped {
+		return false
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		fn()
+	}()
+	return true
+}
+
+func (s *Scheduler) loop() {
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.tick()
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick нь хугацаа нь болсон job-уудыг нэг удаа шалгаж ажиллуулна.
+func (s *Scheduler) tick() {
+	jobs, err := s.repo.Due(s.ctx, s.now(), s.opts.BatchSize)
+	if err != nil {
+		if s.ctx.Err() == nil {
+			s.log.Error("job_due_query_failed", zap.Error(err))
+		}
+		return
+	}
+	for i, job := range jobs {
+		if s.ctx.Err() != nil {
+			return
+		}
+		if !s.dispatch(job) {
+			// Үлдсэн job-ууд хугацаа нь болсон хэвээр тул дараагийн шалгалтаар ажиллана
+			s.log.Warn("job_scheduler_busy",
+				zap.Int("max_concurrent", s.opts.MaxConcurrent),
+				zap.Int("deferred", len(jobs)-i),
+			)
+			return
+		}
+	}
+}
+
+// acquire нь ажиллуулах slot авахыг оролдоно (хүлээхгүй).
+func (s *Scheduler) acquire() bool {
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// release нь acquire-аар авсан slot-ийг чөлөөлнө.
+func (s *Scheduler) release() {
+	<-s.slots
+}
+
+// lock нь slot болон job-ийн advisory lock-ийг авна. Буцаах unlock нь
+// хоёуланг нь чөлөөлнө; slot байхгүй бол ErrSchedulerBusy.
+func (s *Scheduler) lock(ctx context.Context, jobID int) (func(), bool, error) {
+	if !s.acquire() {
+		return nil, false, ErrSchedulerBusy
+	}
+	unlock, ok, err := s.repo.TryLock(ctx, jobID)
+	if err != nil || !ok {
+		s.release()
+		return nil, ok, err
+	}
+	return func() {
+		unlock()
+		s.release()
+	}, true, nil
+}
+
+// ============================================================
+// DISPATCH
+// ============================================================
+
+// dispatch нь lock авч, job хугацаа нь болсон хэвээр бол ажиллуулна.
+// MaxConcurrent job ажиллаж байвал false буцаана.
+func (s *Scheduler) dispatch(job domain.ScheduledJob) bool {
+	unlock, ok, err := s.lock(s.ctx, job.Id)
+	if errors.Is(err, ErrSchedulerBusy) {
+		return false
+	}
+	if err != nil {
+		s.log.Error("job_lock_failed", zap.String("job", job.Code), zap.Error(err))
+		return true
+	}
+	if !ok {
+		return true // Өөр replica ажиллуулж байна
+	}
+
+	now := s.now()
+	fresh, err := s.repo.ByID(s.ctx, job.Id)
+	if err != nil || !isDue(fresh, now) {
+		unlock()
+		return true
+	}
+
+	schedule, err := ParseCron(fresh.CronExpression)
+	var next time.Time
+	if err == nil {
+		if next = schedule.Next(now); next.IsZero() {
+			err = fmt.Errorf("cron expression %q never fires", fresh.CronExpression)
+		}
+	}
+	if err != nil {
+		s.disable(fresh, now, err)
+		unlock()
+		return true
+	}
+
+	// Шинэ job: эхлээд хуваарийг тооцоолно
+	if fresh.NextRunAt == nil {
+		if err := s.repo.SetNextRun(s.ctx, fresh.Id, &next); err != nil {
+			s.log.Error("job_schedule_failed", zap.String("job", fresh.Code), zap.Error(err))
+		}
+		unlock()
+		return true
+	}
+
+	exec, err := s.begin(fresh)
+	if err != nil {
+		unlock()
+		return true
+	}
+	started := s.spawn(func() {
+		defer unlock()
+		s.run(fresh, exec, &next)
+	})
+	if !started {
+		s.abort(fresh, exec, unlock)
+	}
+	return true
+}
+
+// isDue нь lock авсны дараах дахин шалгалт.
+func isDue(job domain.ScheduledJob, now time.Time) bool {
+	if !job.Active() || job.CronExpression == "" {
+		return false
+	}
+	return job.NextRunAt == nil || !job.NextRunAt.After(now)
+}
+
+// disable нь буруу cron-той job-ийг failed ажиллагаа бичиж идэвхгүй болгоно.
+// Идэвхтэй үлдээвэл poll бүрт дахин алдаа гарна.
+func (s *Scheduler) disable(job domain.ScheduledJob, now time.Time, cause error) {
+	s.log.Error("job_invalid_schedule", zap.String("job", job.Code), zap.Error(cause))
+
+	ctx, cancel := context.WithTimeout(context.Background(), bookkeepingTimeout)
+	defer cancel()
+
+	exec := domain.JobExecution{JobId: job.Id, Status: domain.JobStatusRunning, StartedAt: now}
+	if err := s.repo.StartExecution(ctx, &exec); err == nil {
+		s.finish(ctx, job, exec, nil, cause, nil)
+	}
+	if err := s.repo.SetActive(ctx, job.Id, false, nil); err != nil {
+		s.log.Error("job_disable_failed", zap.String("job", job.Code), zap.Error(err))
+	}
+}
+
+// ============================================================
+// MANUAL TRIGGER
+// ============================================================
+
+// Trigger нь job-ийг хуваариас үл хамааран одоо ажиллуулна (идэвхгүй job ч).
+// Execution мөрийг синхрон үүсгэж буцаана, handler нь background-д ажиллана.
+// next_run_at өөрчлөгдөхгүй. MaxConcurrent job ажиллаж байвал ErrSchedulerBusy.
+func (s *Scheduler) Trigger(ctx context.Context, jobID int) (domain.JobExecution, error) {
+	if s.ctx.Err() != nil {
+		return domain.JobExecution{}, ErrSchedulerStopped
+	}
+
+	job, err := s.repo.ByID(ctx, jobID)
+	if err != nil {
+		return domain.JobExecution{}, err
+	}
+
+	unlock, ok, err := s.lock(ctx, job.Id)
+	if err != nil {
+		return domain.JobExecution{}, err
+	}
+	if !ok {
+		return domain.JobExecution{}, ErrJobRunning
+	}
+
+	exec, err := s.begin(job)
+	if err != nil {
+		unlock()
+		return domain.JobExecution{}, err
+	}
+	started := s.spawn(func() {
+		defer unlock()
+		s.run(job, exec, nil)
+	})
+	if !started {
+		s.abort(job, exec, unlock)
+		return domain.JobExecution{}, ErrSchedulerStopped
+	}
+	return exec, nil
+}
+
+// ============================================================
+// EXECUTION
+// ============================================================
+
+// begin нь "running" execution мөр үүсгэнэ.
+func (s *Scheduler) begin(job domain.ScheduledJob) (domain.JobExecution, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bookkeepingTimeout)
+	defer cancel()
+
+	exec := domain.JobExecution{
+		JobId:     job.Id,
+		Status:    domain.JobStatusRunning,
+		StartedAt: s.now(),
+	}
+	if err := s.repo.StartExecution(ctx, &exec); err != nil {
+		s.log.Error("job_execution_start_failed", zap.String("job", job.Code), zap.Error(err))
+		return exec, err
+	}
+	return exec, nil
+}
+
+// abort нь Stop-той давхцаж эхэлж чадаагүй execution-ийг "cancelled" болгоно.
+func (s *Scheduler) abort(job domain.ScheduledJob, exec domain.JobExecution, unlock func()) {
+	defer unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), bookkeepingTimeout)
+	defer cancel()
+	s.finish(ctx, job, exec, nil, context.Canceled, nil)
+}
+
+// run нь handler-ийг ажиллуулж үр дүнг бичнэ.
+func (s *Scheduler) run(job domain.ScheduledJob, exec domain.JobExecution, next *time.Time) {
+	runCtx, cancel := context.WithTimeout(s.ctx, s.opts.Timeout)
+	result, err := s.invoke(runCtx, job)
+	cancel()
+
+	ctx, cancelBook := context.WithTimeout(context.Background(), bookkeepingTimeout)
+	defer cancelBook()
+	s.finish(ctx, job, exec, result, err, next)
+}
+
+// invoke нь handler-ийг panic-аас хамгаалж дуудна.
+func (s *Scheduler) invoke(ctx context.Context, job domain.ScheduledJob) (result any, err error) {
+	h, ok := s.handler(job.Handler)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownHandler, job.Handler)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			s.log.Error("job_panic",
+				zap.String("job", job.Code),
+				zap.Any("panic", r),
+				zap.ByteString("stack", debug.Stack()),
+			)
+			result, err = nil, fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return h(ctx, json.RawMessage(job.Parameters))
+}
+
+// finish нь execution болон job-ийн last_run_* талбаруудыг бичнэ.
+func (s *Scheduler) finish(ctx context.Context, job domain.ScheduledJob, exec domain.JobExecution, result any, runErr error, next *time.Time) {
+	finished := s.now()
+	duration := int(finished.Sub(exec.StartedAt).Milliseconds())
+	exec.FinishedAt = &finished
+	exec.DurationMs = &duration
+	exec.Status = jobStatus(runErr)
+
+	if runErr == nil && result != nil {
+		b, err := json.Marshal(result)
+		if err != nil {
+			runErr = fmt.Errorf("encode result: %w", err)
+			exec.Status = domain.JobStatusFailed
+		} else {
+			exec.Result = b
+		}
+	}
+	if runErr != nil {
+		exec.ErrorMessage = runErr.Error()
+	}
+
+	if err := s.repo.FinishExecution(ctx, exec, next); err != nil {
+		s.log.Error("job_execution_finish_failed", zap.String("job", job.Code), zap.Error(err))
+	}
+
+	fields := []zap.Field{
+		zap.String("job", job.Code),
+		zap.Int("execution_id", exec.Id),
+		zap.String("status", exec.Status),
+		zap.Int("duration_ms", duration),
+	}
+	if runErr != nil {
+		s.log.Warn("job_finished", append(fields, zap.Error(runErr))...)
+		return
+	}
+	s.log.Info("job_finished", fields...)
+}
+
+// jobStatus нь handler-ийн алдаанаас execution-ий төлөвийг тодорхойлно.
+func jobStatus(err error) string {
+	switch {
+	case err == nil:
+		return domain.JobStatusCompleted
+	case errors.Is(err, context.Canceled):
+		// Stop-оор тасалдсан (timeout нь DeadlineExceeded → failed)
+		return domain.JobStatusCancelled
+	default:
+		return domain.JobStatusFailed
+	}
+}
@@ -0,0 +1,400 @@
+// Package scheduler provides implementation for scheduler
+//
+// File: scheduler_test.go
+// Description: Unit tests for the job runner
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/http/dto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// fakeJobRepo нь санах ойд ажиллах JobRepository.
+type fakeJobRepo struct {
+	mu       sync.Mutex
+	jobs     map[int]domain.ScheduledJob
+	execs    []domain.JobExecution
+	finished []*time.Time // FinishExecution-д ирсэн next
+	locked   map[int]bool
+}
+
+func newFakeJobRepo(jobs ...domain.ScheduledJob) *fakeJobRepo {
+	r := &fakeJobRepo{jobs: map[int]domain.ScheduledJob{}, locked: map[int]bool{}}
+	for _, j := range jobs {
+		r.jobs[j.Id] = j
+	}
+	return r
+}
+
+func (r *fakeJobRepo) List(context.Context, dto.JobListQuery) ([]domain.ScheduledJob, int64, int, int, error) {
+	return nil, 0, 0, 0, nil
+}
+
+func (r *fakeJobRepo) ByID(_ context.Context, id int) (domain.ScheduledJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	j, ok := r.jobs[id]
+	if !ok {
+		return j, gorm.ErrRecordNotFound
+	}
+	return j, nil
+}
+
+func (r *fakeJobRepo) Due(_ context.Context, now time.Time, _ int) ([]domain.ScheduledJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []domain.ScheduledJob
+	for _, j := range r.jobs {
+		if isDue(j, now) {
+			out = append(out, j)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeJobRepo) SetNextRun(_ context.Context, id int, next *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	j := r.jobs[id]
+	j.NextRunAt = next
+	r.jobs[id] = j
+	return nil
+}
+
+func (r *fakeJobRepo) SetActive(_ context.Context, id int, active bool, next *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	j := r.jobs[id]
+	j.IsActive = &active
+	j.NextRunAt = next
+	r.jobs[id] = j
+	return nil
+}
+
+func (r *fakeJobRepo) Executions(context.Context, int, dto.JobExecutionListQuery) ([]domain.JobExecution, int64, int, int, error) {
+	return nil, 0, 0, 0, nil
+}
+
+func (r *fakeJobRepo) StartExecution(_ context.Context, exec *domain.JobExecution) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	exec.Id = len(r.execs) + 1
+	r.execs = append(r.execs, *exec)
+	return nil
+}
+
+func (r *fakeJobRepo) FinishExecution(_ context.Context, exec domain.JobExecution, next *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.execs[exec.Id-1] = exec
+	r.finished = append(r.finished, next)
+	j := r.jobs[exec.JobId]
+	j.LastRunStatus = exec.Status
+	j.LastError = exec.ErrorMessage
+	if next != nil {
+		j.NextRunAt = next
+	}
+	r.jobs[exec.JobId] = j
+	return nil
+}
+
+func (r *fakeJobRepo) PurgeExecutions(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeJobRepo) TryLock(_ context.Context, jobID int) (func(), bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.locked[jobID] {
+		return nil, false, nil
+	}
+	r.locked[jobID] = true
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.locked, jobID)
+	}, true, nil
+}
+
+func (r *fakeJobRepo) job(id int) domain.ScheduledJob {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.jobs[id]
+}
+
+func (r *fakeJobRepo) executions() []domain.JobExecution {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]domain.JobExecution(nil), r.execs...)
+}
+
+// newTestScheduler нь цагийг now-д түгжсэн scheduler үүсгэнэ.
+func newTestScheduler(repo *fakeJobRepo, now time.Time) *Scheduler {
+	s := New(repo, Options{PollInterval: time.Hour, Timeout: time.Second}, zap.NewNop())
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func dueJob(id int, handler string) domain.ScheduledJob {
+	past := time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)
+	return domain.ScheduledJob{
+		Id:             id,
+		Code:           "JOB_" + handler,
+		CronExpression: "0 * * * *",
+		Handler:        handler,
+		Parameters:     []byte(`{"n": 2}`),
+		NextRunAt:      &past,
+	}
+}
+
+func TestScheduler_RunsDueJob(t *testing.T) {
+	now := time.Date(2025, 3, 10, 10, 15, 0, 0, time.UTC)
+	repo := newFakeJobRepo(dueJob(1, "double"))
+	s := newTestScheduler(repo, now)
+	s.Register("double", func(_ context.Context, raw json.RawMessage) (any, error) {
+		var p struct{ N int }
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return nil, err
+		}
+		return map[string]int{"result": p.N * 2}, nil
+	})
+
+	s.tick()
+	s.wg.Wait()
+
+	execs := repo.executions()
+	require.Len(t, execs, 1)
+	assert.Equal(t, domain.JobStatusCompleted, execs[0].Status)
+	assert.JSONEq(t, `{"result": 4}`, string(execs[0].Result))
+	assert.NotNil(t, execs[0].DurationMs)
+
+	job := repo.job(1)
+	require.NotNil(t, job.NextRunAt)
+	assert.Equal(t, time.Date(2025, 3, 10, 11, 0, 0, 0, time.UTC), *job.NextRunAt)
+	assert.Empty(t, repo.locked, "lock must be released")
+}
+
+func TestScheduler_Failures(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler Handler
+		wantErr string
+	}{
+		{
+			name:    "handler error",
+			handler: func(context.Context, json.RawMessage) (any, error) { return nil, errors.New("boom") },
+			wantErr: "boom",
+		},
+		{
+			name:    "panic",
+			handler: func(context.Context, json.RawMessage) (any, error) { panic("oops") },
+			wantErr: "panic: oops",
+		},
+		{
+			name: "timeout",
+			handler: func(ctx context.Context, _ json.RawMessage) (any, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+			wantErr: context.DeadlineExceeded.Error(),
+		},
+		{
+			name:    "unregistered handler",
+			wantErr: ErrUnknownHandler.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2025, 3, 10, 10, 15, 0, 0, time.UTC)
+			repo := newFakeJobRepo(dueJob(1, "job"))
+			s := newTestScheduler(repo, now)
+			s.opts.Timeout = 50 * time.Millisecond
+			if tt.handler != nil {
+				s.Register("job", tt.handler)
+			}
+
+			s.tick()
+			s.wg.Wait()
+
+			execs := repo.executions()
+			require.Len(t, execs, 1)
+			assert.Equal(t, domain.JobStatusFailed, execs[0].Status)
+			assert.Contains(t, execs[0].ErrorMessage, tt.wantErr)
+			// Алдаатай ч дараагийн хугацаа урагшилна
+			require.NotNil(t, repo.job(1).NextRunAt)
+			assert.True(t, repo.job(1).NextRunAt.After(now))
+		})
+	}
+}
+
+func TestScheduler_SchedulesNewJobWithoutRunning(t *testing.T) {
+	now := time.Date(2025, 3, 10, 10, 15, 0, 0, time.UTC)
+	job := dueJob(1, "job")
+	job.NextRunAt = nil
+	repo := newFakeJobRepo(job)
+	s := newTestScheduler(repo, now)
+	s.Register("job", func(context.Context, json.RawMessage) (any, error) { return nil, nil })
+
+	s.tick()
+	s.wg.Wait()
+
+	assert.Empty(t, repo.executions())
+	require.NotNil(t, repo.job(1).NextRunAt)
+	assert.Equal(t, time.Date(2025, 3, 10, 11, 0, 0, 0, time.UTC), *repo.job(1).NextRunAt)
+}
+
+func TestScheduler_InvalidCronDisablesJob(t *testing.T) {
+	now := time.Date(2025, 3, 10, 10, 15, 0, 0, time.UTC)
+	job := dueJob(1, "job")
+	job.CronExpression = "61 * * * *"
+	repo := newFakeJobRepo(job)
+	s := newTestScheduler(repo, now)
+
+	s.tick()
+	s.wg.Wait()
+
+	execs := repo.executions()
+	require.Len(t, execs, 1)
+	assert.Equal(t, domain.JobStatusFailed, execs[0].Status)
+	assert.False(t, repo.job(1).Active())
+	assert.Nil(t, repo.job(1).NextRunAt)
+}
+
+func TestScheduler_SkipsLockedJob(t *testing.T) {
+	now := time.Date(2025, 3, 10, 10, 15, 0, 0, time.UTC)
+	repo := newFakeJobRepo(dueJob(1, "job"))
+	repo.locked[1] = true // Өөр replica барьж байна
+	s := newTestScheduler(repo, now)
+	s.Register("job", func(context.Context, json.RawMessage) (any, error) { return nil, nil })
+
+	s.tick()
+	s.wg.Wait()
+
+	assert.Empty(t, repo.executions())
+
+	s.Stop()
+	_, err := s.Trigger(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrSchedulerStopped)
+}
+
+func TestScheduler_Trigger(t *testing.T) {
+	now := time.Date(2025, 3, 10, 10, 15, 0, 0, time.UTC)
+	job := dueJob(1, "job")
+	inactive := false
+	job.IsActive = &inactive // Идэвхгүй job-ийг ч гараар ажиллуулна
+	repo := newFakeJobRepo(job)
+	s := newTestScheduler(repo, now)
+
+	release := make(chan struct{})
+	s.Register("job", func(context.Context, json.RawMessage) (any, error) {
+		<-release
+		return nil, nil
+	})
+
+	exec, err := s.Trigger(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobStatusRunning, exec.Status)
+	assert.NotZero(t, exec.Id)
+
+	// Ажиллаж байхад дахин trigger хийвэл
+	_, err = s.Trigger(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrJobRunning)
+
+	close(release)
+	s.wg.Wait()
+
+	execs := repo.executions()
+	require.Len(t, execs, 1)
+	assert.Equal(t, domain.JobStatusCompleted, execs[0].Status)
+	assert.Equal(t, []*time.Time{nil}, repo.finished, "manual run keeps next_run_at")
+
+	_, err = newTestScheduler(repo, now).Trigger(context.Background(), 99)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestScheduler_StopCancelsRunningJob(t *testing.T) {
+	now := time.Date(2025, 3, 10, 10, 15, 0, 0, time.UTC)
+	repo := newFakeJobRepo(dueJob(1, "job"))
+	s := newTestScheduler(repo, now)
+	s.opts.Timeout = time.Minute
+
+	started := make(chan struct{})
+	s.Register("job", func(ctx context.Context, _ json.RawMessage) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	s.tick()
+	<-started
+	s.Stop()
+
+	execs := repo.executions()
+	require.Len(t, execs, 1)
+	assert.Equal(t, domain.JobStatusCancelled, execs[0].Status)
+}
+
+func TestScheduler_StartStop(t *testing.T) {
+	s := newTestScheduler(newFakeJobRepo(), time.Now())
+	s.Start()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.Stop()
+		s.Stop() // idempotent
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop did not return")
+	}
+}
+
+func TestScheduler_LimitsConcurrentJobs(t *testing.T) {
+	now := time.Date(2025, 3, 10, 10, 15, 0, 0, time.UTC)
+	repo := newFakeJobRepo(dueJob(1, "job"), dueJob(2, "job"), dueJob(3, "job"))
+	s := New(repo, Options{PollInterval: time.Hour, Timeout: time.Minute, MaxConcurrent: 2}, zap.NewNop())
+	s.now = func() time.Time { return now }
+
+	release := make(chan struct{})
+	s.Register("job", func(context.Context, json.RawMessage) (any, error) {
+		<-release
+		return nil, nil
+	})
+
+	s.tick()
+	assert.Len(t, repo.executions(), 2, "only MaxConcurrent jobs start")
+	assert.Len(t, repo.locked, 2)
+
+	// Дүүрсэн үед гараар ажиллуулахгүй, lock авахгүй
+	_, err := s.Trigger(context.Background(), 3)
+	assert.ErrorIs(t, err, ErrSchedulerBusy)
+
+	close(release)
+	s.wg.Wait()
+	assert.Empty(t, repo.locked)
+
+	// Slot чөлөөлөгдсөний дараа үлдсэн job ажиллана
+	s.tick()
+	s.wg.Wait()
+	execs := repo.executions()
+	require.Len(t, execs, 3)
+	for _, e := range execs {
+		assert.Equal(t, domain.JobStatusCompleted, e.Status)
+	}
+}
@@ -0,0 +1,107 @@
+// Package service provides implementation for service
+//
+// File: auth_mailer.go
+// Description: Sends verification, password reset and welcome emails for local auth
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"templatev25/internal/config"
+	"templatev25/internal/mail"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// authMailTimeout bounds a single background delivery including retries
+	authMailTimeout = 2 * time.Minute
+
+	// tokenPlaceholder is replaced with the token in link URLs
+	tokenPlaceholder = "{token}"
+)
+
+// AuthMailer renders and delivers transactional emails for the local auth flows.
+// Delivery runs in the background so a slow SMTP relay never blocks the request.
+type AuthMailer struct {
+	mailer    mail.Mailer
+	templates *mail.Templates
+	cfg       *config.MailConfig
+	logger    *zap.Logger
+}
+
+// NewAuthMailer creates a new auth mailer
+func NewAuthMailer(mailer mail.Mailer, templates *mail.Templates, cfg *config.MailConfig, logger *zap.Logger) *AuthMailer {
+	return &AuthMailer{
+		mailer:    mailer,
+		templates: templates,
+		cfg:       cfg,
+		logger:    logger,
+	}
+}
+
+// SendVerification sends the email address verification link
+func (m *AuthMailer) SendVerification(ctx context.Context, email, name, token string, ttl time.Duration) {
+	msg, err := m.templates.Verification(email, name, withToken(m.cfg.VerifyURL, token), ttl)
+	m.dispatch(ctx, mail.TemplateVerification, msg, err)
+}
+
+// SendPasswordReset sends the password reset link
+func (m *AuthMailer) SendPasswordReset(ctx context.Context, email, name, token string, ttl time.Duration) {
+	msg, err := m.templates.PasswordReset(email, name, withToken(m.cfg.ResetURL, token), ttl)
+	m.dispatch(ctx, mail.TemplatePasswordReset, msg, err)
+}
+
+// SendWelcome sends the welcome message after email verification
+func (m *AuthMailer) SendWelcome(ctx context.Context, email, name string) {
+	msg, err := m.templates.Welcome(email, name, m.cfg.LoginURL)
+	m.dispatch(ctx, mail.TemplateWelcome, msg, err)
+}
+
+// dispatch delivers msg in the background, detached from the request context
+func (m *AuthMailer) dispatch(ctx context.Context, template string, msg mail.Message, renderErr error) {
+	if renderErr != nil {
+		m.logger.Error("failed to render auth email",
+			zap.String("template", template),
+			zap.Error(renderErr),
+		)
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), authMailTimeout)
+	go func() {
+		defer cancel()
+		if err := m.mailer.Send(sendCtx, msg); err != nil {
+			m.logger.Error("failed to send auth email",
+				zap.String("template", template),
+				zap.Strings("to", msg.To),
+				zap.Bool("permanent", mail.IsPermanent(err)),
+				zap.Error(err),
+			)
+			return
+		}
+		m.logger.Info("auth email sent",
+			zap.String("template", template),
+			zap.Strings("to", msg.To),
+		)
+	}()
+}
+
+// withToken puts the token into the frontend URL. A "{token}" placeholder is
+// replaced in place; otherwise the token is appended as a query parameter.
+func withToken(base, token string) string {
+	if strings.Contains(base, tokenPlaceholder) {
+		return strings.ReplaceAll(base, tokenPlaceholder, url.PathEscape(token))
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return base + "?token=" + url.QueryEscape(token)
+	}
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
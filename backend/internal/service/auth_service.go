@@ -9,9 +9,11 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/base32"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -23,6 +25,7 @@ import (
 	"templatev25/internal/config"
 	"templatev25/internal/domain"
 	"templatev25/internal/repository"
+	"templatev25/internal/webauthn"
 
 	"github.com/google/uuid"
 	"github.com/pquerna/otp"
@@ -46,6 +49,10 @@ var (
 	ErrPasswordReused      = errors.New("password was recently used")
 	ErrUserNotFound        = errors.New("user not found")
 	ErrCredentialsNotFound = errors.New("credentials not found")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+	ErrNotOrgMember        = errors.New("user is not a member of the organization")
+	ErrRefreshTokenSession = errors.New("session must be renewed with its refresh token")
 )
 
 // Argon2id parameters (OWASP recommended)
@@ -62,20 +69,24 @@ type AuthService struct {
 	repo         repository.AuthRepository
 	sessionStore SessionStore
 	cfg          *config.LocalAuthConfig
+	webauthn     *webauthn.WebAuthn
 	logger       *zap.Logger
 }
 
-// NewAuthService creates a new authentication service
+// NewAuthService creates a new authentication service.
+// relyingParty may be nil, in which case WebAuthn enrollment is disabled.
 func NewAuthService(
 	repo repository.AuthRepository,
 	sessionStore SessionStore,
 	cfg *config.LocalAuthConfig,
+	relyingParty *webauthn.WebAuthn,
 	logger *zap.Logger,
 ) *AuthService {
 	return &AuthService{
 		repo:         repo,
 		sessionStore: sessionStore,
 		cfg:          cfg,
+		webauthn:     relyingParty,
 		logger:       logger,
 	}
 }
@@ -90,14 +101,20 @@ type LoginRequest struct {
 	Password  string
 	IPAddress string
 	UserAgent string
+	// IssueRefreshToken requests a short-lived session plus a rotating refresh token
+	IssueRefreshToken bool
 }
 
 // LoginResponse contains login result
 type LoginResponse struct {
-	RequiresMFA bool
-	MFAToken    string
-	Session     *SessionData
-	User        *domain.User
+	RequiresMFA      bool
+	MFAToken         string
+	MFAMethods       []string
+	WebAuthnOptions  *webauthn.RequestOptions
+	Session          *SessionData
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+	User             *domain.User
 }
 
 // Login authenticates a user with email and password
@@ -155,30 +172,48 @@ func (s *AuthService) Login(ctx context.Context, req LoginRequest) (*LoginRespon
 	// Reset failed attempts on successful password verification
 	s.repo.ResetFailedAttempts(ctx, user.Id)
 
-	// Check if MFA is enabled
-	mfa, err := s.repo.GetMFAByUserID(ctx, user.Id)
-	if err == nil && mfa != nil && mfa.IsEnabled {
+	// Check if MFA is enabled (TOTP or WebAuthn)
+	status, err := s.GetMFAStatus(ctx, user.Id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get MFA status: %w", err)
+	}
+	if status.Enabled {
 		// MFA required - return pending token
 		mfaToken := uuid.New().String()
 		pendingData := &MFAPendingData{
-			UserID:    user.Id,
-			Email:     user.Email,
-			IPAddress: req.IPAddress,
-			UserAgent: req.UserAgent,
-			ExpiresAt: time.Now().Add(s.cfg.MFATokenTTL),
+			UserID:            user.Id,
+			Email:             user.Email,
+			IPAddress:         req.IPAddress,
+			UserAgent:         req.UserAgent,
+			IssueRefreshToken: req.IssueRefreshToken,
+			ExpiresAt:         time.Now().Add(s.cfg.MFATokenTTL),
+		}
+
+		result := &LoginResponse{
+			RequiresMFA: true,
+			MFAToken:    mfaToken,
+			MFAMethods:  status.Methods,
 		}
+
+		// WebAuthn assertion challenge is issued with the MFA token
+		if len(status.WebAuthnCredentials) > 0 && s.webauthn != nil {
+			opts, err := s.beginWebAuthnLogin(status.WebAuthnCredentials)
+			if err != nil {
+				return nil, err
+			}
+			pendingData.WebAuthnChallenge = opts.Challenge
+			result.WebAuthnOptions = opts
+		}
+
 		if err := s.sessionStore.StoreMFAToken(ctx, mfaToken, pendingData, s.cfg.MFATokenTTL); err != nil {
 			return nil, fmt.Errorf("failed to store MFA token: %w", err)
 		}
 
-		return &LoginResponse{
-			RequiresMFA: true,
-			MFAToken:    mfaToken,
-		}, nil
+		return result, nil
 	}
 
 	// No MFA - create session directly
-	session, err := s.createSession(ctx, user, req.IPAddress, req.UserAgent)
+	result, err := s.establishSession(ctx, user, req.IPAddress, req.UserAgent, req.IssueRefreshToken)
 	if err != nil {
 		return nil, err
 	}
@@ -189,26 +224,24 @@ func (s *AuthService) Login(ctx context.Context, req LoginRequest) (*LoginRespon
 	// Log successful login
 	s.logSuccessfulLogin(ctx, user.Id, req.Email, req.IPAddress, req.UserAgent, false)
 
-	return &LoginResponse{
-		RequiresMFA: false,
-		Session:     session,
-		User:        user,
-	}, nil
+	return result, nil
 }
 
 // ============================================================
 // MFA VERIFICATION
 // ============================================================
 
-// VerifyMFARequest contains MFA verification parameters
+// VerifyMFARequest contains MFA verification parameters.
+// Either Code (TOTP) or WebAuthn (assertion) is set.
 type VerifyMFARequest struct {
 	MFAToken  string
 	Code      string
+	WebAuthn  *webauthn.AssertionResponse
 	IPAddress string
 	UserAgent string
 }
 
-// VerifyMFA verifies a TOTP code and completes login
+// VerifyMFA verifies a TOTP code or WebAuthn assertion and completes login
 func (s *AuthService) VerifyMFA(ctx context.Context, req VerifyMFARequest) (*LoginResponse, error) {
 	// Get pending MFA data
 	pending, err := s.sessionStore.GetMFAToken(ctx, req.MFAToken)
@@ -219,23 +252,33 @@ func (s *AuthService) VerifyMFA(ctx context.Context, req VerifyMFARequest) (*Log
 		return nil, ErrInvalidSession
 	}
 
-	// Get MFA config
-	mfa, err := s.repo.GetMFAByUserID(ctx, pending.UserID)
-	if err != nil || mfa == nil || !mfa.IsEnabled {
-		return nil, ErrMFANotEnabled
-	}
+	if req.WebAuthn != nil {
+		// Verify WebAuthn assertion
+		if err := s.verifyWebAuthnAssertion(ctx, pending, req.WebAuthn); err != nil {
+			if errors.Is(err, ErrInvalidMFACode) {
+				s.logFailedLogin(ctx, &pending.UserID, pending.Email, req.IPAddress, req.UserAgent, "invalid WebAuthn assertion")
+			}
+			return nil, err
+		}
+	} else {
+		// Get MFA config
+		mfa, err := s.repo.GetMFAByUserID(ctx, pending.UserID)
+		if err != nil || mfa == nil || !mfa.IsEnabled {
+			return nil, ErrMFANotEnabled
+		}
 
-	// Decrypt secret
-	secret, err := s.decryptTOTPSecret(mfa.SecretEncrypted)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt MFA secret: %w", err)
-	}
+		// Decrypt secret
+		secret, err := s.decryptTOTPSecret(mfa.SecretEncrypted)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt MFA secret: %w", err)
+		}
 
-	// Verify TOTP code
-	valid := totp.Validate(req.Code, secret)
-	if !valid {
-		s.logFailedLogin(ctx, &pending.UserID, pending.Email, req.IPAddress, req.UserAgent, "invalid MFA code")
-		return nil, ErrInvalidMFACode
+		// Verify TOTP code
+		valid := totp.Validate(req.Code, secret)
+		if !valid {
+			s.logFailedLogin(ctx, &pending.UserID, pending.Email, req.IPAddress, req.UserAgent, "invalid MFA code")
+			return nil, ErrInvalidMFACode
+		}
 	}
 
 	// Delete MFA token
@@ -248,7 +291,7 @@ func (s *AuthService) VerifyMFA(ctx context.Context, req VerifyMFARequest) (*Log
 	}
 
 	// Create session
-	session, err := s.createSession(ctx, user, req.IPAddress, req.UserAgent)
+	result, err := s.establishSession(ctx, user, req.IPAddress, req.UserAgent, pending.IssueRefreshToken)
 	if err != nil {
 		return nil, err
 	}
@@ -259,11 +302,7 @@ func (s *AuthService) VerifyMFA(ctx context.Context, req VerifyMFARequest) (*Log
 	// Log successful login with MFA
 	s.logSuccessfulLogin(ctx, user.Id, pending.Email, req.IPAddress, req.UserAgent, true)
 
-	return &LoginResponse{
-		RequiresMFA: false,
-		Session:     session,
-		User:        user,
-	}, nil
+	return result, nil
 }
 
 // VerifyBackupCode verifies a backup code and completes login
@@ -319,7 +358,7 @@ func (s *AuthService) VerifyBackupCode(ctx context.Context, mfaToken, code, ip,
 	}
 
 	// Create session
-	session, err := s.createSession(ctx, user, ip, userAgent)
+	result, err := s.establishSession(ctx, user, ip, userAgent, pending.IssueRefreshToken)
 	if err != nil {
 		return nil, err
 	}
@@ -330,11 +369,7 @@ func (s *AuthService) VerifyBackupCode(ctx context.Context, mfaToken, code, ip,
 	// Log successful login
 	s.logSuccessfulLogin(ctx, user.Id, pending.Email, ip, userAgent, true)
 
-	return &LoginResponse{
-		RequiresMFA: false,
-		Session:     session,
-		User:        user,
-	}, nil
+	return result, nil
 }
 
 // ============================================================
@@ -427,10 +462,13 @@ func (s *AuthService) ConfirmTOTP(ctx context.Context, userID int, code, ip, use
 		return fmt.Errorf("failed to enable MFA: %w", err)
 	}
 
-	// Generate backup codes
-	backupCodes, err := s.generateBackupCodes(ctx, userID)
-	if err != nil {
-		s.logger.Error("failed to generate backup codes", zap.Error(err))
+	// Generate backup codes unless WebAuthn enrollment already issued them
+	var backupCodes []string
+	if existing, _ := s.repo.GetUnusedBackupCodes(ctx, userID); len(existing) == 0 {
+		backupCodes, err = s.generateBackupCodes(ctx, userID)
+		if err != nil {
+			s.logger.Error("failed to generate backup codes", zap.Error(err))
+		}
 	}
 
 	// Log MFA enable
@@ -468,8 +506,10 @@ func (s *AuthService) DisableTOTP(ctx context.Context, userID int, code, ip, use
 		return fmt.Errorf("failed to disable MFA: %w", err)
 	}
 
-	// Delete backup codes
-	s.repo.DeleteBackupCodes(ctx, userID)
+	// Delete backup codes unless WebAuthn remains as a second factor
+	if creds, err := s.repo.GetWebAuthnCredentials(ctx, userID); err == nil && len(creds) == 0 {
+		s.repo.DeleteBackupCodes(ctx, userID)
+	}
 
 	// Log MFA disable
 	s.logAudit(ctx, &userID, string(domain.AuditActionMFADisable), "user", strconv.Itoa(userID),
@@ -485,8 +525,8 @@ func (s *AuthService) DisableTOTP(ctx context.Context, userID int, code, ip, use
 // GenerateBackupCodes generates new backup codes for a user
 func (s *AuthService) GenerateBackupCodes(ctx context.Context, userID int, ip, userAgent string) ([]string, error) {
 	// Check MFA is enabled
-	mfa, err := s.repo.GetMFAByUserID(ctx, userID)
-	if err != nil || mfa == nil || !mfa.IsEnabled {
+	status, err := s.GetMFAStatus(ctx, userID)
+	if err != nil || !status.Enabled {
 		return nil, ErrMFANotEnabled
 	}
 
@@ -647,12 +687,17 @@ func (s *AuthService) GetSession(ctx context.Context, sessionID string) (*Sessio
 	return s.sessionStore.Get(ctx, sessionID)
 }
 
-// RefreshSession extends a session's expiry
+// RefreshSession extends a session's expiry. Sessions backed by a refresh
+// token are renewed only through RotateRefreshToken, so extending them here
+// would bypass rotation and reuse detection.
 func (s *AuthService) RefreshSession(ctx context.Context, sessionID string) (*SessionData, error) {
 	session, err := s.sessionStore.Get(ctx, sessionID)
 	if err != nil || session == nil {
 		return nil, ErrInvalidSession
 	}
+	if session.HasRefreshToken {
+		return nil, ErrRefreshTokenSession
+	}
 
 	newExpiry := time.Now().Add(s.cfg.SessionTTL)
 	if err := s.sessionStore.Refresh(ctx, sessionID, newExpiry); err != nil {
@@ -679,6 +724,9 @@ func (s *AuthService) Logout(ctx context.Context, sessionID, ip, userAgent strin
 
 	// Revoke in DB
 	s.repo.RevokeSession(ctx, sessionID, "user logout")
+	if session.HasRefreshToken {
+		s.repo.RevokeSessionRefreshTokens(ctx, sessionID)
+	}
 
 	// Log
 	s.logAudit(ctx, &session.UserID, string(domain.AuditActionSessionRevoke), "session", sessionID,
@@ -687,6 +735,44 @@ func (s *AuthService) Logout(ctx context.Context, sessionID, ip, userAgent strin
 	return nil
 }
 
+// ChangeOrganization switches the organization a local session acts in.
+// The user must be a member of the organization; permissions are evaluated
+// per organization, so the caller invalidates the user's permission cache.
+func (s *AuthService) ChangeOrganization(ctx context.Context, sessionID string, orgID int) (*SessionData, error) {
+	session, err := s.sessionStore.Get(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || time.Now().After(session.ExpiresAt) {
+		return nil, ErrInvalidSession
+	}
+
+	member, err := s.repo.IsOrganizationMember(ctx, session.UserID, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check organization membership: %w", err)
+	}
+	if !member {
+		return nil, ErrNotOrgMember
+	}
+
+	// Update writes only while the session exists, so a concurrent logout wins
+	session.OrgID = orgID
+	if err := s.sessionStore.Update(ctx, session); err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return nil, ErrInvalidSession
+		}
+		return nil, err
+	}
+
+	// Sessions issued by rotation take the organization from the refresh token
+	if session.HasRefreshToken {
+		if err := s.repo.SetSessionRefreshTokenOrg(ctx, sessionID, orgID); err != nil {
+			return nil, fmt.Errorf("failed to update refresh token organization: %w", err)
+		}
+	}
+	return session, nil
+}
+
 // LogoutAll revokes all sessions for a user
 func (s *AuthService) LogoutAll(ctx context.Context, userID int, ip, userAgent string) error {
 	// Delete all sessions from Redis
@@ -696,6 +782,7 @@ func (s *AuthService) LogoutAll(ctx context.Context, userID int, ip, userAgent s
 
 	// Revoke all in DB
 	s.repo.RevokeAllUserSessions(ctx, userID, "logout all")
+	s.repo.RevokeUserRefreshTokens(ctx, userID)
 
 	// Log
 	s.logAudit(ctx, &userID, string(domain.AuditActionLogoutAll), "user", strconv.Itoa(userID),
@@ -722,19 +809,33 @@ func (s *AuthService) GetActiveSessions(ctx context.Context, userID int) ([]Sess
 	return sessions, nil
 }
 
-func (s *AuthService) createSession(ctx context.Context, user *domain.User, ip, userAgent string) (*SessionData, error) {
+// createSession creates a session acting in orgID. Zero selects the user's
+// default organization.
+func (s *AuthService) createSession(ctx context.Context, user *domain.User, orgID int, ip, userAgent string, withRefresh bool) (*SessionData, error) {
 	sessionID := uuid.New().String()
 	now := time.Now()
 
+	// Protected API routes scope queries by organization, so the session
+	// carries one the same way SSO claims do
+	if orgID == 0 {
+		var err error
+		orgID, err = s.repo.GetDefaultOrgID(ctx, user.Id)
+		if err != nil {
+			s.logger.Warn("failed to resolve default organization", zap.Int("user_id", user.Id), zap.Error(err))
+		}
+	}
+
 	session := &SessionData{
-		SessionID:      sessionID,
-		UserID:         user.Id,
-		Email:          user.Email,
-		IPAddress:      ip,
-		UserAgent:      userAgent,
-		CreatedAt:      now,
-		ExpiresAt:      now.Add(s.cfg.SessionTTL),
-		LastActivityAt: now,
+		SessionID:       sessionID,
+		UserID:          user.Id,
+		Email:           user.Email,
+		OrgID:           orgID,
+		IPAddress:       ip,
+		UserAgent:       userAgent,
+		CreatedAt:       now,
+		ExpiresAt:       now.Add(s.sessionTTL(withRefresh)),
+		LastActivityAt:  now,
+		HasRefreshToken: withRefresh,
 	}
 
 	// Store in Redis
@@ -756,6 +857,209 @@ func (s *AuthService) createSession(ctx context.Context, user *domain.User, ip,
 	return session, nil
 }
 
+// sessionTTL returns the session lifetime. Sessions backed by a refresh token
+// are short-lived; clients renew them through RotateRefreshToken.
+func (s *AuthService) sessionTTL(withRefresh bool) time.Duration {
+	if withRefresh {
+		return s.cfg.AccessTokenTTL
+	}
+	return s.cfg.SessionTTL
+}
+
+// establishSession creates a session for a successful login and, when
+// requested, the first refresh token of a new rotation family.
+func (s *AuthService) establishSession(ctx context.Context, user *domain.User, ip, userAgent string, withRefresh bool) (*LoginResponse, error) {
+	session, err := s.createSession(ctx, user, 0, ip, userAgent, withRefresh)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LoginResponse{
+		RequiresMFA: false,
+		Session:     session,
+		User:        user,
+	}
+
+	if withRefresh {
+		token, rt, err := s.issueRefreshToken(ctx, session, uuid.New().String())
+		if err != nil {
+			s.sessionStore.Delete(ctx, session.SessionID)
+			return nil, err
+		}
+		if err := s.repo.CreateRefreshToken(ctx, rt); err != nil {
+			s.sessionStore.Delete(ctx, session.SessionID)
+			return nil, fmt.Errorf("failed to store refresh token: %w", err)
+		}
+		result.RefreshToken = token
+		result.RefreshExpiresAt = rt.ExpiresAt
+	}
+
+	return result, nil
+}
+
+// ============================================================
+// REFRESH TOKENS
+// ============================================================
+
+// RefreshTokenRequest contains refresh token rotation parameters
+type RefreshTokenRequest struct {
+	RefreshToken string
+	IPAddress    string
+	UserAgent    string
+}
+
+// RotateRefreshToken exchanges a refresh token for a new session and a new
+// refresh token of the same family. The old session and token stop working.
+//
+// Presenting a token that was already rotated means it has leaked: the whole
+// family and every session issued from it are revoked and the event is audited.
+func (s *AuthService) RotateRefreshToken(ctx context.Context, req RefreshTokenRequest) (*LoginResponse, error) {
+	current, err := s.repo.GetRefreshTokenByHash(ctx, hashRefreshToken(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	// Reuse of a rotated token
+	if current.IsRotated() {
+		s.handleRefreshReuse(ctx, current, req.IPAddress, req.UserAgent)
+		return nil, ErrRefreshTokenReused
+	}
+
+	if current.IsRevoked() || current.IsExpired() {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user := current.User
+	if user == nil || user.Status != string(domain.UserStatusActive) {
+		// A suspended account is not a leaked token: revoke without reporting reuse
+		s.revokeRefreshFamily(ctx, current, domain.AuditActionSessionRevoke, "account_not_active", req.IPAddress, req.UserAgent)
+		return nil, ErrAccountNotActive
+	}
+
+	// New session for the rotated token, in the organization the previous
+	// session had selected
+	session, err := s.createSession(ctx, user, s.rotationOrgID(ctx, current), req.IPAddress, req.UserAgent, true)
+	if err != nil {
+		return nil, err
+	}
+
+	token, next, err := s.issueRefreshToken(ctx, session, current.FamilyID)
+	if err != nil {
+		s.sessionStore.Delete(ctx, session.SessionID)
+		return nil, err
+	}
+
+	rotated, err := s.repo.RotateRefreshToken(ctx, current.ID, next)
+	if err != nil {
+		s.sessionStore.Delete(ctx, session.SessionID)
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !rotated {
+		// Another request rotated the same token first
+		s.sessionStore.Delete(ctx, session.SessionID)
+		s.repo.RevokeSession(ctx, session.SessionID, "refresh token reuse")
+		s.handleRefreshReuse(ctx, current, req.IPAddress, req.UserAgent)
+		return nil, ErrRefreshTokenReused
+	}
+
+	// The previous access session is superseded
+	s.sessionStore.Delete(ctx, current.SessionID)
+	s.repo.RevokeSession(ctx, current.SessionID, "refresh token rotated")
+
+	return &LoginResponse{
+		Session:          session,
+		RefreshToken:     token,
+		RefreshExpiresAt: next.ExpiresAt,
+		User:             user,
+	}, nil
+}
+
+// rotationOrgID returns the organization a rotated session continues in. It
+// falls back to the default organization (zero) when the token predates
+// organization tracking or the user has since left the organization.
+func (s *AuthService) rotationOrgID(ctx context.Context, token *domain.RefreshToken) int {
+	if token.OrgID == nil {
+		return 0
+	}
+	member, err := s.repo.IsOrganizationMember(ctx, token.UserID, *token.OrgID)
+	if err != nil {
+		s.logger.Warn("failed to check organization membership",
+			zap.Int("user_id", token.UserID),
+			zap.Int("org_id", *token.OrgID),
+			zap.Error(err),
+		)
+		return 0
+	}
+	if !member {
+		return 0
+	}
+	return *token.OrgID
+}
+
+// issueRefreshToken generates a refresh token for the session. The returned
+// entity holds only the token hash; the caller persists it.
+func (s *AuthService) issueRefreshToken(ctx context.Context, session *SessionData, familyID string) (string, *domain.RefreshToken, error) {
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	rt := &domain.RefreshToken{
+		UserID:    session.UserID,
+		TokenHash: hashRefreshToken(token),
+		SessionID: session.SessionID,
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().Add(s.cfg.RefreshTokenTTL),
+	}
+	if session.OrgID != 0 {
+		orgID := session.OrgID
+		rt.OrgID = &orgID
+	}
+	return token, rt, nil
+}
+
+// handleRefreshReuse revokes the family of a token that was presented after
+// rotation and records the reuse in the security audit trail.
+func (s *AuthService) handleRefreshReuse(ctx context.Context, token *domain.RefreshToken, ip, userAgent string) {
+	s.logger.Warn("refresh token reuse detected",
+		zap.Int("user_id", token.UserID),
+		zap.String("family_id", token.FamilyID),
+		zap.String("ip", ip),
+	)
+	s.revokeRefreshFamily(ctx, token, domain.AuditActionTokenReuse, "refresh token reuse", ip, userAgent)
+}
+
+// revokeRefreshFamily revokes all tokens of the family and ends the sessions
+// issued from it. The revocation is audited with the given action and reason.
+func (s *AuthService) revokeRefreshFamily(ctx context.Context, token *domain.RefreshToken, action domain.SecurityAuditAction, reason, ip, userAgent string) {
+	sessionIDs, err := s.repo.RevokeRefreshTokenFamily(ctx, token.FamilyID)
+	if err != nil {
+		s.logger.Error("failed to revoke refresh token family",
+			zap.String("family_id", token.FamilyID),
+			zap.Error(err),
+		)
+	}
+
+	for _, id := range sessionIDs {
+		s.sessionStore.Delete(ctx, id)
+		s.repo.RevokeSession(ctx, id, reason)
+	}
+
+	s.logAudit(ctx, &token.UserID, string(action), "refresh_token_family", token.FamilyID,
+		nil, map[string]interface{}{"token_id": token.ID, "reason": reason, "revoked_sessions": len(sessionIDs)}, ip, userAgent)
+}
+
+// hashRefreshToken returns the SHA-256 hex digest stored in refresh_tokens.token_hash.
+// Refresh tokens are 256-bit random values, so a fast hash is sufficient.
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 // ============================================================
 // USER STATUS MANAGEMENT
 // ============================================================
@@ -826,22 +1130,40 @@ func (s *AuthService) GetSecurityAudit(ctx context.Context, userID int, limit in
 	return s.repo.GetAuditTrail(ctx, userID, limit)
 }
 
-// GetMFAStatus returns MFA status for a user
-func (s *AuthService) GetMFAStatus(ctx context.Context, userID int) (enabled bool, hasBackupCodes bool, err error) {
+// GetMFAStatus returns the enrolled second factors for a user
+func (s *AuthService) GetMFAStatus(ctx context.Context, userID int) (*MFAStatus, error) {
+	status := &MFAStatus{}
+
 	mfa, err := s.repo.GetMFAByUserID(ctx, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err == nil && mfa.IsEnabled {
+		status.TOTPEnabled = true
+		status.Methods = append(status.Methods, MFAMethodTOTP)
+	}
+
+	creds, err := s.repo.GetWebAuthnCredentials(ctx, userID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return false, false, nil
-		}
-		return false, false, err
+		return nil, err
+	}
+	if len(creds) > 0 {
+		status.WebAuthnCredentials = creds
+		status.Methods = append(status.Methods, MFAMethodWebAuthn)
 	}
 
-	if !mfa.IsEnabled {
-		return false, false, nil
+	status.Enabled = len(status.Methods) > 0
+	if !status.Enabled {
+		return status, nil
 	}
 
 	codes, _ := s.repo.GetUnusedBackupCodes(ctx, userID)
-	return true, len(codes) > 0, nil
+	status.BackupCodesLeft = len(codes)
+	status.HasBackupCodes = len(codes) > 0
+	if status.HasBackupCodes {
+		status.Methods = append(status.Methods, MFAMethodBackupCode)
+	}
+	return status, nil
 }
 
 // ============================================================
@@ -0,0 +1,285 @@
+// Package service provides implementation for service
+//
+// File: auth_webauthn.go
+// Description: WebAuthn (passkey / security key) second factor for local auth
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/webauthn"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// WebAuthn errors
+var (
+	ErrWebAuthnDisabled           = errors.New("WebAuthn is not configured")
+	ErrWebAuthnCredentialNotFound = errors.New("WebAuthn credential not found")
+	ErrWebAuthnCredentialExists   = errors.New("WebAuthn credential is already registered")
+	ErrInvalidWebAuthnResponse    = errors.New("invalid WebAuthn response")
+)
+
+// MFA method names reported to clients
+const (
+	MFAMethodTOTP       = "totp"
+	MFAMethodWebAuthn   = "webauthn"
+	MFAMethodBackupCode = "backup_code"
+)
+
+// webAuthnUserHandleSize is the size of the opaque user handle stored on authenticators
+const webAuthnUserHandleSize = 32
+
+// MFAStatus describes the second factors a user has enrolled
+type MFAStatus struct {
+	Enabled             bool
+	TOTPEnabled         bool
+	WebAuthnCredentials []domain.UserWebAuthnCredential
+	HasBackupCodes      bool
+	BackupCodesLeft     int
+	Methods             []string
+}
+
+// WebAuthnRegistrationResult contains the registered credential and, when it
+// is the user's first second factor, freshly generated backup codes
+type WebAuthnRegistrationResult struct {
+	Credential  *domain.UserWebAuthnCredential
+	BackupCodes []string
+}
+
+// ============================================================
+// REGISTRATION
+// ============================================================
+
+// BeginWebAuthnRegistration starts a registration ceremony for the signed-in user.
+// The challenge is bound to the current session.
+func (s *AuthService) BeginWebAuthnRegistration(ctx context.Context, userID int, email, sessionID string) (*webauthn.CreationOptions, error) {
+	if s.webauthn == nil {
+		return nil, ErrWebAuthnDisabled
+	}
+
+	existing, err := s.repo.GetWebAuthnCredentials(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get WebAuthn credentials: %w", err)
+	}
+
+	// All credentials of a user share one handle so discoverable credentials
+	// on the same authenticator replace each other instead of piling up
+	var handle []byte
+	if len(existing) > 0 {
+		handle, err = webauthn.DecodeBase64(existing[0].UserHandle)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stored user handle: %w", err)
+		}
+	} else {
+		handle = make([]byte, webAuthnUserHandleSize)
+		if _, err := io.ReadFull(rand.Reader, handle); err != nil {
+			return nil, fmt.Errorf("failed to generate user handle: %w", err)
+		}
+	}
+
+	exclude := make([]webauthn.CredentialDescriptor, 0, len(existing))
+	for i := range existing {
+		exclude = append(exclude, toWebAuthnCredential(&existing[i]).Descriptor())
+	}
+
+	opts, err := s.webauthn.BeginRegistration(webauthn.User{
+		ID:          handle,
+		Name:        email,
+		DisplayName: email,
+	}, exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := &WebAuthnChallengeData{
+		UserID:     userID,
+		Challenge:  opts.Challenge,
+		UserHandle: webauthn.EncodeBase64(handle),
+	}
+	if err := s.sessionStore.StoreWebAuthnChallenge(ctx, sessionID, pending, s.webauthn.Timeout()); err != nil {
+		return nil, err
+	}
+
+	return opts, nil
+}
+
+// FinishWebAuthnRegistration verifies the authenticator response and stores the credential
+func (s *AuthService) FinishWebAuthnRegistration(ctx context.Context, userID int, sessionID, name string, resp *webauthn.RegistrationResponse, ip, userAgent string) (*WebAuthnRegistrationResult, error) {
+	if s.webauthn == nil {
+		return nil, ErrWebAuthnDisabled
+	}
+
+	pending, err := s.sessionStore.TakeWebAuthnChallenge(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if pending == nil || pending.UserID != userID {
+		return nil, ErrInvalidSession
+	}
+
+	cred, err := s.webauthn.FinishRegistration(pending.Challenge, resp)
+	if err != nil {
+		s.logger.Warn("webauthn registration rejected",
+			zap.Int("user_id", userID),
+			zap.Error(err),
+		)
+		return nil, ErrInvalidWebAuthnResponse
+	}
+
+	credentialID := webauthn.EncodeBase64(cred.ID)
+	if _, err := s.repo.GetWebAuthnCredentialByCredentialID(ctx, credentialID); err == nil {
+		return nil, ErrWebAuthnCredentialExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check WebAuthn credential: %w", err)
+	}
+
+	// Backup codes are issued together with the first second factor
+	status, err := s.GetMFAStatus(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if name = strings.TrimSpace(name); name == "" {
+		name = "Security key " + strconv.Itoa(len(status.WebAuthnCredentials)+1)
+	}
+
+	record := &domain.UserWebAuthnCredential{
+		UserID:         userID,
+		CredentialID:   credentialID,
+		UserHandle:     pending.UserHandle,
+		PublicKey:      cred.PublicKey,
+		Algorithm:      int(cred.Algorithm),
+		SignCount:      int64(cred.SignCount),
+		AAGUID:         hex.EncodeToString(cred.AAGUID),
+		Transports:     strings.Join(cred.Transports, ","),
+		Name:           name,
+		BackupEligible: cred.BackupEligible,
+	}
+	if err := s.repo.CreateWebAuthnCredential(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to store WebAuthn credential: %w", err)
+	}
+
+	result := &WebAuthnRegistrationResult{Credential: record}
+	if !status.Enabled {
+		codes, err := s.generateBackupCodes(ctx, userID)
+		if err != nil {
+			s.logger.Error("failed to generate backup codes", zap.Error(err))
+		}
+		result.BackupCodes = codes
+	}
+
+	s.logAudit(ctx, &userID, string(domain.AuditActionWebAuthnAdd), "webauthn_credential", strconv.Itoa(record.ID),
+		nil, map[string]interface{}{"name": record.Name, "aaguid": record.AAGUID, "backup_eligible": record.BackupEligible}, ip, userAgent)
+
+	return result, nil
+}
+
+// RemoveWebAuthnCredential deletes one of the user's credentials after
+// re-checking the account password
+func (s *AuthService) RemoveWebAuthnCredential(ctx context.Context, userID, credentialID int, password, ip, userAgent string) error {
+	cred, err := s.repo.GetCredentialByUserID(ctx, userID)
+	if err != nil {
+		return ErrCredentialsNotFound
+	}
+	if !s.verifyPassword(password, cred.PasswordHash) {
+		return ErrInvalidCredentials
+	}
+
+	if err := s.repo.DeleteWebAuthnCredential(ctx, userID, credentialID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrWebAuthnCredentialNotFound
+		}
+		return fmt.Errorf("failed to delete WebAuthn credential: %w", err)
+	}
+
+	// Backup codes go away with the last second factor
+	status, err := s.GetMFAStatus(ctx, userID)
+	if err == nil && !status.Enabled {
+		s.repo.DeleteBackupCodes(ctx, userID)
+	}
+
+	s.logAudit(ctx, &userID, string(domain.AuditActionWebAuthnRemove), "webauthn_credential", strconv.Itoa(credentialID),
+		nil, nil, ip, userAgent)
+
+	return nil
+}
+
+// ============================================================
+// ASSERTION
+// ============================================================
+
+// beginWebAuthnLogin returns assertion options for the user's credentials
+func (s *AuthService) beginWebAuthnLogin(creds []domain.UserWebAuthnCredential) (*webauthn.RequestOptions, error) {
+	allow := make([]webauthn.CredentialDescriptor, 0, len(creds))
+	for i := range creds {
+		allow = append(allow, toWebAuthnCredential(&creds[i]).Descriptor())
+	}
+	return s.webauthn.BeginLogin(allow)
+}
+
+// verifyWebAuthnAssertion checks an assertion made during the MFA step of login
+func (s *AuthService) verifyWebAuthnAssertion(ctx context.Context, pending *MFAPendingData, resp *webauthn.AssertionResponse) error {
+	if s.webauthn == nil {
+		return ErrWebAuthnDisabled
+	}
+	if pending.WebAuthnChallenge == "" {
+		return ErrMFANotEnabled
+	}
+
+	rawID, err := resp.CredentialID()
+	if err != nil {
+		return ErrInvalidMFACode
+	}
+	stored, err := s.repo.GetWebAuthnCredentialByCredentialID(ctx, webauthn.EncodeBase64(rawID))
+	if err != nil || stored.UserID != pending.UserID {
+		return ErrInvalidMFACode
+	}
+
+	handle, err := webauthn.DecodeBase64(stored.UserHandle)
+	if err != nil {
+		return fmt.Errorf("invalid stored user handle: %w", err)
+	}
+
+	result, err := s.webauthn.FinishLogin(pending.WebAuthnChallenge, resp, toWebAuthnCredential(stored), handle)
+	if err != nil {
+		if errors.Is(err, webauthn.ErrSignCountRegression) {
+			s.logger.Warn("webauthn signature counter regression, possible cloned authenticator",
+				zap.Int("user_id", pending.UserID),
+				zap.Int("credential_id", stored.ID),
+			)
+		}
+		return ErrInvalidMFACode
+	}
+
+	if err := s.repo.UpdateWebAuthnCredentialUsage(ctx, stored.ID, int64(result.SignCount)); err != nil {
+		s.logger.Error("failed to update webauthn credential usage", zap.Error(err))
+	}
+
+	return nil
+}
+
+// toWebAuthnCredential converts a stored credential for verification
+func toWebAuthnCredential(c *domain.UserWebAuthnCredential) *webauthn.Credential {
+	id, _ := webauthn.DecodeBase64(c.CredentialID)
+	var transports []string
+	if c.Transports != "" {
+		transports = strings.Split(c.Transports, ",")
+	}
+	return &webauthn.Credential{
+		ID:         id,
+		PublicKey:  c.PublicKey,
+		Algorithm:  int64(c.Algorithm),
+		SignCount:  uint32(c.SignCount),
+		Transports: transports,
+	}
+}
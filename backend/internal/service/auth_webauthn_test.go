@@ -0,0 +1,151 @@
+// Package service provides business logic layer
+//
+// File: auth_webauthn_test.go
+// Description: Unit tests for WebAuthn MFA status and login challenge
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"templatev25/internal/config"
+	"templatev25/internal/domain"
+	"templatev25/internal/repository"
+	"templatev25/internal/webauthn"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// mfaAuthRepo implements the AuthRepository methods used by the MFA flow;
+// any other call panics through the nil embedded interface
+type mfaAuthRepo struct {
+	repository.AuthRepository
+	user        *domain.User
+	credential  *domain.UserCredential
+	totp        *domain.UserMFATotp
+	webauthn    []domain.UserWebAuthnCredential
+	backupCodes []domain.UserMFABackupCode
+}
+
+func (r *mfaAuthRepo) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return r.user, nil
+}
+
+func (r *mfaAuthRepo) GetCredentialByUserID(ctx context.Context, userID int) (*domain.UserCredential, error) {
+	return r.credential, nil
+}
+
+func (r *mfaAuthRepo) ResetFailedAttempts(ctx context.Context, userID int) error { return nil }
+
+func (r *mfaAuthRepo) GetMFAByUserID(ctx context.Context, userID int) (*domain.UserMFATotp, error) {
+	if r.totp == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.totp, nil
+}
+
+func (r *mfaAuthRepo) GetWebAuthnCredentials(ctx context.Context, userID int) ([]domain.UserWebAuthnCredential, error) {
+	return r.webauthn, nil
+}
+
+func (r *mfaAuthRepo) GetUnusedBackupCodes(ctx context.Context, userID int) ([]domain.UserMFABackupCode, error) {
+	return r.backupCodes, nil
+}
+
+// mfaSessionStore records pending MFA tokens
+type mfaSessionStore struct {
+	SessionStore
+	pending map[string]*MFAPendingData
+}
+
+func (s *mfaSessionStore) StoreMFAToken(ctx context.Context, token string, data *MFAPendingData, ttl time.Duration) error {
+	s.pending[token] = data
+	return nil
+}
+
+func newMFATestService(t *testing.T, repo *mfaAuthRepo) (*AuthService, *mfaSessionStore) {
+	rp, err := webauthn.New(webauthn.Config{RPID: "example.com", Origins: []string{"https://example.com"}})
+	require.NoError(t, err)
+
+	store := &mfaSessionStore{pending: map[string]*MFAPendingData{}}
+	cfg := &config.LocalAuthConfig{MFATokenTTL: time.Minute}
+	return NewAuthService(repo, store, cfg, rp, zap.NewNop()), store
+}
+
+func TestGetMFAStatus(t *testing.T) {
+	passkey := domain.UserWebAuthnCredential{ID: 1, UserID: 7, CredentialID: "AQID", Name: "Passkey"}
+
+	tests := []struct {
+		name    string
+		repo    *mfaAuthRepo
+		enabled bool
+		methods []string
+	}{
+		{
+			name: "no factors",
+			repo: &mfaAuthRepo{},
+		},
+		{
+			name:    "totp only",
+			repo:    &mfaAuthRepo{totp: &domain.UserMFATotp{IsEnabled: true}, backupCodes: make([]domain.UserMFABackupCode, 3)},
+			enabled: true,
+			methods: []string{MFAMethodTOTP, MFAMethodBackupCode},
+		},
+		{
+			name:    "unconfirmed totp is ignored",
+			repo:    &mfaAuthRepo{totp: &domain.UserMFATotp{IsEnabled: false}, webauthn: []domain.UserWebAuthnCredential{passkey}},
+			enabled: true,
+			methods: []string{MFAMethodWebAuthn},
+		},
+		{
+			name:    "totp and webauthn",
+			repo:    &mfaAuthRepo{totp: &domain.UserMFATotp{IsEnabled: true}, webauthn: []domain.UserWebAuthnCredential{passkey}},
+			enabled: true,
+			methods: []string{MFAMethodTOTP, MFAMethodWebAuthn},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newMFATestService(t, tt.repo)
+			status, err := s.GetMFAStatus(context.Background(), 7)
+			require.NoError(t, err)
+			assert.Equal(t, tt.enabled, status.Enabled)
+			assert.Equal(t, tt.methods, status.Methods)
+		})
+	}
+}
+
+func TestLogin_IssuesWebAuthnChallenge(t *testing.T) {
+	repo := &mfaAuthRepo{
+		user: &domain.User{Id: 7, Email: "admin@example.com", Status: string(domain.UserStatusActive)},
+		webauthn: []domain.UserWebAuthnCredential{
+			{ID: 1, UserID: 7, CredentialID: "AQID", Transports: "usb,nfc"},
+		},
+	}
+	s, store := newMFATestService(t, repo)
+
+	hash, err := s.hashPassword("correct horse")
+	require.NoError(t, err)
+	repo.credential = &domain.UserCredential{UserID: 7, PasswordHash: hash}
+
+	result, err := s.Login(context.Background(), LoginRequest{Email: "admin@example.com", Password: "correct horse"})
+	require.NoError(t, err)
+
+	assert.True(t, result.RequiresMFA)
+	assert.Nil(t, result.Session)
+	assert.Equal(t, []string{MFAMethodWebAuthn}, result.MFAMethods)
+	require.NotNil(t, result.WebAuthnOptions)
+	assert.Equal(t, "example.com", result.WebAuthnOptions.RPID)
+	require.Len(t, result.WebAuthnOptions.AllowCredentials, 1)
+	assert.Equal(t, "AQID", result.WebAuthnOptions.AllowCredentials[0].ID)
+	assert.Equal(t, []string{"usb", "nfc"}, result.WebAuthnOptions.AllowCredentials[0].Transports)
+
+	pending := store.pending[result.MFAToken]
+	require.NotNil(t, pending)
+	assert.Equal(t, result.WebAuthnOptions.Challenge, pending.WebAuthnChallenge)
+}
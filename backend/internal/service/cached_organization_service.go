@@ -153,6 +153,21 @@ func (s *CachedOrganizationService) Delete(ctx context.Context, id int) error {
 	return nil
 }
 
+// Move invalidates cache after moving a node
+func (s *CachedOrganizationService) Move(ctx context.Context, id int, parentID *int) error {
+	if err := s.OrganizationService.Move(ctx, id, parentID); err != nil {
+		return err
+	}
+
+	// Cached copy has the old parent_id
+	s.orgCache.Delete(s.orgKey(id))
+
+	// Every cached tree containing the old or new parent is stale
+	s.treeCache.Clear()
+
+	return nil
+}
+
 // CacheStats returns cache statistics
 func (s *CachedOrganizationService) CacheStats() (orgStats, treeStats cache.Stats) {
 	return s.orgCache.Stats(), s.treeCache.Stats()
@@ -0,0 +1,134 @@
+// Package service provides business logic layer
+//
+// File: change_organization_test.go
+// Description: Unit tests for switching the organization of a local session
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"templatev25/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newOrgTestStore() *fakeSessionStore {
+	store := newFakeSessionStore()
+	store.sessions["sess-1"] = &SessionData{
+		SessionID: "sess-1",
+		UserID:    42,
+		OrgID:     1,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	return store
+}
+
+func TestChangeOrganization_Member(t *testing.T) {
+	repo := new(mockAuthRepository)
+	store := newOrgTestStore()
+	s := newRefreshTestService(repo, store)
+	ctx := context.Background()
+
+	repo.On("IsOrganizationMember", ctx, 42, 2).Return(true, nil)
+
+	session, err := s.ChangeOrganization(ctx, "sess-1", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, session.OrgID)
+	assert.Equal(t, 2, store.sessions["sess-1"].OrgID)
+	repo.AssertExpectations(t)
+}
+
+func TestChangeOrganization_NotMember(t *testing.T) {
+	repo := new(mockAuthRepository)
+	store := newOrgTestStore()
+	s := newRefreshTestService(repo, store)
+	ctx := context.Background()
+
+	repo.On("IsOrganizationMember", ctx, 42, 2).Return(false, nil)
+
+	_, err := s.ChangeOrganization(ctx, "sess-1", 2)
+	assert.ErrorIs(t, err, ErrNotOrgMember)
+	assert.Equal(t, 1, store.sessions["sess-1"].OrgID, "session is unchanged")
+}
+
+func TestChangeOrganization_SessionGone(t *testing.T) {
+	repo := new(mockAuthRepository)
+	s := newRefreshTestService(repo, newFakeSessionStore())
+
+	_, err := s.ChangeOrganization(context.Background(), "sess-1", 2)
+	assert.ErrorIs(t, err, ErrInvalidSession)
+	repo.AssertNotCalled(t, "IsOrganizationMember")
+}
+
+func TestChangeOrganization_MembershipLookupFails(t *testing.T) {
+	repo := new(mockAuthRepository)
+	s := newRefreshTestService(repo, newOrgTestStore())
+	ctx := context.Background()
+
+	repo.On("IsOrganizationMember", ctx, 42, 2).Return(false, errors.New("db down"))
+
+	_, err := s.ChangeOrganization(ctx, "sess-1", 2)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNotOrgMember)
+}
+
+func TestChangeOrganization_KeptAcrossRotation(t *testing.T) {
+	repo := new(mockAuthRepository)
+	store := newOrgTestStore()
+	store.sessions["sess-1"].HasRefreshToken = true
+	s := newRefreshTestService(repo, store)
+	ctx := context.Background()
+
+	current := activeRefreshToken("raw")
+	current.SessionID = "sess-1"
+	orgID := 1
+	current.OrgID = &orgID
+
+	repo.On("IsOrganizationMember", ctx, 42, 2).Return(true, nil)
+	repo.On("SetSessionRefreshTokenOrg", ctx, "sess-1", 2).Return(nil).Run(func(args mock.Arguments) {
+		org := args.Int(2)
+		current.OrgID = &org
+	})
+	repo.On("GetRefreshTokenByHash", ctx, hashRefreshToken("raw")).Return(current, nil)
+	repo.On("CreateSession", ctx, mock.Anything).Return(nil)
+	repo.On("RotateRefreshToken", ctx, 7, mock.MatchedBy(func(next *domain.RefreshToken) bool {
+		return next.OrgID != nil && *next.OrgID == 2
+	})).Return(true, nil)
+	repo.On("RevokeSession", ctx, "sess-1", "refresh token rotated").Return(nil)
+
+	_, err := s.ChangeOrganization(ctx, "sess-1", 2)
+	require.NoError(t, err)
+
+	resp, err := s.RotateRefreshToken(ctx, RefreshTokenRequest{RefreshToken: "raw"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, resp.Session.OrgID, "rotation keeps the selected organization")
+	repo.AssertNotCalled(t, "GetDefaultOrgID", mock.Anything, mock.Anything)
+	repo.AssertExpectations(t)
+}
+
+func TestRotateRefreshToken_FallsBackWhenNoLongerMember(t *testing.T) {
+	repo := new(mockAuthRepository)
+	s := newRefreshTestService(repo, newFakeSessionStore("sess-old"))
+	ctx := context.Background()
+
+	current := activeRefreshToken("raw")
+	orgID := 2
+	current.OrgID = &orgID
+
+	repo.On("GetRefreshTokenByHash", ctx, hashRefreshToken("raw")).Return(current, nil)
+	repo.On("IsOrganizationMember", ctx, 42, 2).Return(false, nil)
+	repo.On("GetDefaultOrgID", ctx, 42).Return(1, nil)
+	repo.On("CreateSession", ctx, mock.Anything).Return(nil)
+	repo.On("RotateRefreshToken", ctx, 7, mock.Anything).Return(true, nil)
+	repo.On("RevokeSession", ctx, "sess-old", "refresh token rotated").Return(nil)
+
+	resp, err := s.RotateRefreshToken(ctx, RefreshTokenRequest{RefreshToken: "raw"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, resp.Session.OrgID)
+	repo.AssertExpectations(t)
+}
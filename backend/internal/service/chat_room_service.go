@@ -0,0 +1,488 @@
+// Package service provides implementation for service
+//
+// File: chat_room_service.go
+// Description: Chat rooms, membership and messages with realtime delivery
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/http/dto"
+	"templatev25/internal/realtime"
+	"templatev25/internal/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrChatRoomNotFound нь өрөө олдоогүй эсвэл хэрэглэгч гишүүн биш үед буцна.
+	ErrChatRoomNotFound = errors.New("chat room not found")
+	// ErrChatMemberNotFound нь гишүүн олдоогүй үед буцна.
+	ErrChatMemberNotFound = errors.New("chat room member not found")
+	// ErrChatMessageNotFound нь мессеж олдоогүй (эсвэл устгагдсан) үед буцна.
+	ErrChatMessageNotFound = errors.New("chat message not found")
+	// ErrChatForbidden нь гишүүний эрх хүрэлцэхгүй үед буцна.
+	ErrChatForbidden = errors.New("insufficient chat room role")
+	// ErrChatInvalidRequest нь өрөөний төрөлд тохирохгүй хүсэлт.
+	ErrChatInvalidRequest = errors.New("invalid chat room request")
+	// ErrChatOwnerLeave нь owner өрөөнөөс гарах/хасагдах үед буцна.
+	ErrChatOwnerLeave = errors.New("room owner cannot leave the room")
+)
+
+// Realtime event-ийн төрлүүд (WebSocket client руу).
+const (
+	ChatEventMessageCreated = "chat.message.created"
+	ChatEventMessageUpdated = "chat.message.updated"
+	ChatEventMessageDeleted = "chat.message.deleted"
+	ChatEventRoomUpdated    = "chat.room.updated"
+	ChatEventRoomDeleted    = "chat.room.deleted"
+	ChatEventMemberAdded    = "chat.member.added"
+	ChatEventMemberRemoved  = "chat.member.removed"
+	ChatEventRoomRead       = "chat.room.read"
+)
+
+// ChatPublisher нь event-ийг хэрэглэгчдийн холболтууд руу илгээнэ (*realtime.RedisBroker, *realtime.Hub).
+type ChatPublisher interface {
+	Publish(userIDs []int, ev realtime.Event)
+}
+
+type ChatRoomService struct {
+	repo repository.ChatRoomRepository
+	pub  ChatPublisher
+	log  *zap.Logger
+	now  func() time.Time
+}
+
+func NewChatRoomService(repo repository.ChatRoomRepository, pub ChatPublisher, log *zap.Logger) *ChatRoomService {
+	return &ChatRoomService{repo: repo, pub: pub, log: log, now: time.Now}
+}
+
+// ============================================================
+// ROOMS
+// ============================================================
+
+// Rooms нь хэрэглэгчийн гишүүн өрөөнүүдийг уншаагүй тоотой нь буцаана.
+func (s *ChatRoomService) Rooms(ctx context.Context, userID int, q dto.ChatRoomListQuery) ([]domain.ChatRoomSummary, int64, int, int, error) {
+	return s.repo.ListForUser(ctx, userID, q)
+}
+
+// UnreadTotal нь бүх өрөөний уншаагүй мессежийн нийлбэр.
+func (s *ChatRoomService) UnreadTotal(ctx context.Context, userID int) (int64, error) {
+	return s.repo.UnreadTotal(ctx, userID)
+}
+
+// CreateRoom нь өрөө үүсгэнэ. Үүсгэгч owner болно.
+// Direct өрөө хоёр хэрэглэгчийн хооронд нэг л байна: байгаа бол түүнийг буцаана.
+// Direct өрөөний хоёр тал энгийн member (засах, устгах, гишүүн нэмэх боломжгүй).
+func (s *ChatRoomService) CreateRoom(ctx context.Context, userID int, req dto.ChatRoomCreateDto) (domain.ChatRoom, error) {
+	room := domain.ChatRoom{
+		Name:           strings.TrimSpace(req.Name),
+		Type:           req.Type,
+		OrganizationId: req.OrganizationId,
+		CreatedBy:      &userID,
+		AvatarUrl:      req.AvatarUrl,
+		Description:    req.Description,
+		Settings:       datatypes.JSON(req.Settings),
+	}
+
+	memberIDs := uniqueOthers(req.MemberIDs, userID)
+
+	if req.Type == domain.ChatRoomDirect {
+		if len(memberIDs) != 1 {
+			return room, errors.Join(ErrChatInvalidRequest, errors.New("direct room requires exactly one other member"))
+		}
+		members := []domain.ChatRoomMember{
+			{UserId: userID, Role: domain.ChatRoleMember},
+			{UserId: memberIDs[0], Role: domain.ChatRoleMember},
+		}
+		created, err := s.repo.FindOrCreateDirect(ctx, &room, members)
+		if err != nil {
+			return room, err
+		}
+		if created {
+			s.log.Info("chat_room_created", zap.Int("room_id", room.Id), zap.String("type", room.Type), zap.Int("user_id", userID))
+		}
+		return room, nil
+	}
+
+	if room.Name == "" {
+		return room, errors.Join(ErrChatInvalidRequest, errors.New("name is required"))
+	}
+	members := []domain.ChatRoomMember{{UserId: userID, Role: domain.ChatRoleOwner}}
+	for _, id := range memberIDs {
+		members = append(members, domain.ChatRoomMember{UserId: id, Role: domain.ChatRoleMember})
+	}
+	if err := s.repo.CreateRoom(ctx, &room, members); err != nil {
+		return room, err
+	}
+
+	s.log.Info("chat_room_created", zap.Int("room_id", room.Id), zap.String("type", room.Type), zap.Int("user_id", userID))
+	s.publish(memberIDs, ChatEventMemberAdded, map[string]any{"room_id": room.Id, "user_ids": memberIDs})
+	return room, nil
+}
+
+// Room нь өрөөний мэдээллийг буцаана (зөвхөн гишүүнд).
+func (s *ChatRoomService) Room(ctx context.Context, userID, roomID int) (domain.ChatRoom, error) {
+	if _, err := s.member(ctx, roomID, userID); err != nil {
+		return domain.ChatRoom{}, err
+	}
+	return s.room(ctx, roomID)
+}
+
+// UpdateRoom нь өрөөний нэр, тайлбар, зураг, тохиргоог засна (owner/admin).
+func (s *ChatRoomService) UpdateRoom(ctx context.Context, userID, roomID int, req dto.ChatRoomUpdateDto) (domain.ChatRoom, error) {
+	if _, err := s.manager(ctx, roomID, userID); err != nil {
+		return domain.ChatRoom{}, err
+	}
+
+	fields := map[string]any{}
+	if req.Name != nil {
+		fields["name"] = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		fields["description"] = *req.Description
+	}
+	if req.AvatarUrl != nil {
+		fields["avatar_url"] = *req.AvatarUrl
+	}
+	if len(req.Settings) > 0 {
+		fields["settings"] = datatypes.JSON(req.Settings)
+	}
+	if len(fields) > 0 {
+		if err := s.repo.UpdateRoom(ctx, roomID, fields); err != nil {
+			return domain.ChatRoom{}, mapChatNotFound(err, ErrChatRoomNotFound)
+		}
+	}
+
+	room, err := s.room(ctx, roomID)
+	if err != nil {
+		return room, err
+	}
+	s.publishRoom(ctx, roomID, ChatEventRoomUpdated, room)
+	return room, nil
+}
+
+// DeleteRoom нь өрөөг soft delete хийнэ (зөвхөн owner).
+func (s *ChatRoomService) DeleteRoom(ctx context.Context, userID, roomID int) error {
+	m, err := s.member(ctx, roomID, userID)
+	if err != nil {
+		return err
+	}
+	if m.Role != domain.ChatRoleOwner {
+		return ErrChatForbidden
+	}
+
+	// Устгахаас өмнө гишүүдийг авна
+	ids, err := s.repo.MemberUserIDs(ctx, roomID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteRoom(ctx, roomID); err != nil {
+		return mapChatNotFound(err, ErrChatRoomNotFound)
+	}
+
+	s.log.Info("chat_room_deleted", zap.Int("room_id", roomID), zap.Int("user_id", userID))
+	s.publish(ids, ChatEventRoomDeleted, map[string]any{"room_id": roomID})
+	return nil
+}
+
+// ============================================================
+// MEMBERS
+// ============================================================
+
+// Members нь өрөөний идэвхтэй гишүүдийг буцаана.
+func (s *ChatRoomService) Members(ctx context.Context, userID, roomID int) ([]domain.ChatRoomMember, error) {
+	if _, err := s.member(ctx, roomID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.Members(ctx, roomID)
+}
+
+// AddMembers нь өрөөнд гишүүд нэмнэ (owner/admin, direct өрөөнд боломжгүй).
+func (s *ChatRoomService) AddMembers(ctx context.Context, userID, roomID int, userIDs []int) ([]domain.ChatRoomMember, error) {
+	if _, err := s.manager(ctx, roomID, userID); err != nil {
+		return nil, err
+	}
+	room, err := s.room(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room.Type == domain.ChatRoomDirect {
+		return nil, errors.Join(ErrChatInvalidRequest, errors.New("cannot add members to a direct room"))
+	}
+
+	ids := uniqueOthers(userIDs, userID)
+	members := make([]domain.ChatRoomMember, 0, len(ids))
+	for _, id := range ids {
+		members = append(members, domain.ChatRoomMember{RoomId: roomID, UserId: id, Role: domain.ChatRoleMember})
+	}
+	if err := s.repo.AddMembers(ctx, members); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("chat_members_added", zap.Int("room_id", roomID), zap.Ints("user_ids", ids), zap.Int("by", userID))
+	s.publishRoom(ctx, roomID, ChatEventMemberAdded, map[string]any{"room_id": roomID, "user_ids": ids})
+	return s.repo.Members(ctx, roomID)
+}
+
+// RemoveMember нь гишүүнийг хасна. Хэрэглэгч өөрийгөө хасвал өрөөнөөс гарна.
+// Бусдыг хасахад owner/admin эрх хэрэгтэй; admin нь admin-ийг хасч чадахгүй.
+func (s *ChatRoomService) RemoveMember(ctx context.Context, userID, roomID, targetID int) error {
+	actor, err := s.member(ctx, roomID, userID)
+	if err != nil {
+		return err
+	}
+
+	target := actor
+	if targetID != userID {
+		if !actor.CanManage() {
+			return ErrChatForbidden
+		}
+		if target, err = s.repo.Member(ctx, roomID, targetID); err != nil {
+			return mapChatNotFound(err, ErrChatMemberNotFound)
+		}
+		if actor.Role == domain.ChatRoleAdmin && target.Role == domain.ChatRoleAdmin {
+			return ErrChatForbidden
+		}
+	}
+	if target.Role == domain.ChatRoleOwner {
+		return ErrChatOwnerLeave
+	}
+
+	// Хасагдсан хэрэглэгч ч event авах ёстой тул өмнө нь жагсаалтыг авна
+	ids, err := s.repo.MemberUserIDs(ctx, roomID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.RemoveMember(ctx, roomID, targetID, s.now()); err != nil {
+		return mapChatNotFound(err, ErrChatMemberNotFound)
+	}
+
+	s.log.Info("chat_member_removed", zap.Int("room_id", roomID), zap.Int("user_id", targetID), zap.Int("by", userID))
+	s.publish(ids, ChatEventMemberRemoved, map[string]any{"room_id": roomID, "user_id": targetID})
+	return nil
+}
+
+// SetMemberRole нь гишүүний эрхийг солино (зөвхөн owner).
+func (s *ChatRoomService) SetMemberRole(ctx context.Context, userID, roomID, targetID int, role string) (domain.ChatRoomMember, error) {
+	actor, err := s.member(ctx, roomID, userID)
+	if err != nil {
+		return domain.ChatRoomMember{}, err
+	}
+	if actor.Role != domain.ChatRoleOwner || targetID == userID {
+		return domain.ChatRoomMember{}, ErrChatForbidden
+	}
+	if err := s.repo.SetMemberRole(ctx, roomID, targetID, role); err != nil {
+		return domain.ChatRoomMember{}, mapChatNotFound(err, ErrChatMemberNotFound)
+	}
+	return s.repo.Member(ctx, roomID, targetID)
+}
+
+// MarkRead нь өрөөг одоо хүртэл уншсан гэж тэмдэглэнэ.
+// Хэрэглэгчийн бусад төхөөрөмж badge-ээ шинэчлэхийн тулд event авна.
+func (s *ChatRoomService) MarkRead(ctx context.Context, userID, roomID int) error {
+	at := s.now()
+	if err := s.repo.MarkRead(ctx, roomID, userID, at); err != nil {
+		return mapChatNotFound(err, ErrChatRoomNotFound)
+	}
+	s.publish([]int{userID}, ChatEventRoomRead, map[string]any{"room_id": roomID, "last_read_at": at})
+	return nil
+}
+
+// ============================================================
+// MESSAGES
+// ============================================================
+
+// Messages нь өрөөний мессежийн түүхийг буцаана (шинэ нь эхэндээ).
+// Устгагдсан мессежийн агуулга нуугдана.
+func (s *ChatRoomService) Messages(ctx context.Context, userID, roomID int, q dto.ChatMessageListQuery) ([]domain.ChatMessage, int64, int, int, error) {
+	if _, err := s.member(ctx, roomID, userID); err != nil {
+		return nil, 0, 0, 0, err
+	}
+	items, total, page, size, err := s.repo.Messages(ctx, roomID, q)
+	if err != nil {
+		return nil, 0, 0, 0, err
+	}
+	for i := range items {
+		items[i] = items[i].Redacted()
+	}
+	return items, total, page, size, nil
+}
+
+// SendMessage нь мессеж илгээж өрөөний гишүүд рүү түгээнэ.
+// Channel өрөөнд зөвхөн owner/admin/moderator бичнэ.
+func (s *ChatRoomService) SendMessage(ctx context.Context, userID, roomID int, req dto.ChatMessageSendDto) (domain.ChatMessage, error) {
+	m, err := s.member(ctx, roomID, userID)
+	if err != nil {
+		return domain.ChatMessage{}, err
+	}
+	room, err := s.room(ctx, roomID)
+	if err != nil {
+		return domain.ChatMessage{}, err
+	}
+	if room.Type == domain.ChatRoomChannel && !m.CanModerate() {
+		return domain.ChatMessage{}, ErrChatForbidden
+	}
+	if req.ParentId != nil {
+		parent, err := s.repo.MessageByID(ctx, *req.ParentId)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ChatMessage{}, err
+		}
+		if err != nil || parent.RoomId != roomID {
+			return domain.ChatMessage{}, errors.Join(ErrChatInvalidRequest, errors.New("parent message is not in this room"))
+		}
+	}
+
+	msg := domain.ChatMessage{
+		RoomId:      roomID,
+		SenderId:    &userID,
+		ParentId:    req.ParentId,
+		MessageType: req.MessageType,
+		Content:     req.Content,
+		FileUrl:     req.FileUrl,
+		FileName:    req.FileName,
+		FileSize:    req.FileSize,
+		Metadata:    datatypes.JSON(req.Metadata),
+		CreatedDate: s.now(),
+	}
+	if msg.MessageType == "" {
+		msg.MessageType = domain.ChatMessageText
+	}
+	if err := s.repo.CreateMessage(ctx, &msg); err != nil {
+		return msg, err
+	}
+
+	s.publishRoom(ctx, roomID, ChatEventMessageCreated, msg)
+	return msg, nil
+}
+
+// EditMessage нь мессежийн агуулгыг засна (зөвхөн илгээгч).
+func (s *ChatRoomService) EditMessage(ctx context.Context, userID, roomID, messageID int, content string) (domain.ChatMessage, error) {
+	msg, err := s.roomMessage(ctx, userID, roomID, messageID)
+	if err != nil {
+		return msg, err
+	}
+	if msg.SenderId == nil || *msg.SenderId != userID || msg.MessageType == domain.ChatMessageSystem {
+		return msg, ErrChatForbidden
+	}
+
+	at := s.now()
+	if err := s.repo.EditMessage(ctx, messageID, content, at); err != nil {
+		return msg, mapChatNotFound(err, ErrChatMessageNotFound)
+	}
+	msg.Content = content
+	msg.IsEdited = true
+	msg.EditedAt = &at
+
+	s.publishRoom(ctx, roomID, ChatEventMessageUpdated, msg)
+	return msg, nil
+}
+
+// DeleteMessage нь мессежийг soft delete хийнэ.
+// Илгээгч өөрөө эсвэл owner/admin/moderator устгана.
+func (s *ChatRoomService) DeleteMessage(ctx context.Context, userID, roomID, messageID int) error {
+	msg, err := s.roomMessage(ctx, userID, roomID, messageID)
+	if err != nil {
+		return err
+	}
+	if msg.SenderId == nil || *msg.SenderId != userID {
+		m, err := s.member(ctx, roomID, userID)
+		if err != nil {
+			return err
+		}
+		if !m.CanModerate() {
+			return ErrChatForbidden
+		}
+	}
+
+	if err := s.repo.SoftDeleteMessage(ctx, messageID); err != nil {
+		return mapChatNotFound(err, ErrChatMessageNotFound)
+	}
+	s.publishRoom(ctx, roomID, ChatEventMessageDeleted, map[string]any{"room_id": roomID, "id": messageID})
+	return nil
+}
+
+// ============================================================
+// HELPERS
+// ============================================================
+
+func (s *ChatRoomService) room(ctx context.Context, roomID int) (domain.ChatRoom, error) {
+	room, err := s.repo.RoomByID(ctx, roomID)
+	return room, mapChatNotFound(err, ErrChatRoomNotFound)
+}
+
+// member нь идэвхтэй гишүүнчлэлийг буцаана. Гишүүн биш бол өрөө
+// байгаа эсэхийг ил гаргахгүйн тулд ErrChatRoomNotFound.
+func (s *ChatRoomService) member(ctx context.Context, roomID, userID int) (domain.ChatRoomMember, error) {
+	m, err := s.repo.Member(ctx, roomID, userID)
+	return m, mapChatNotFound(err, ErrChatRoomNotFound)
+}
+
+func (s *ChatRoomService) manager(ctx context.Context, roomID, userID int) (domain.ChatRoomMember, error) {
+	m, err := s.member(ctx, roomID, userID)
+	if err != nil {
+		return m, err
+	}
+	if !m.CanManage() {
+		return m, ErrChatForbidden
+	}
+	return m, nil
+}
+
+// roomMessage нь гишүүнчлэлийг шалгаж тухайн өрөөний устгагдаагүй мессежийг буцаана.
+func (s *ChatRoomService) roomMessage(ctx context.Context, userID, roomID, messageID int) (domain.ChatMessage, error) {
+	if _, err := s.member(ctx, roomID, userID); err != nil {
+		return domain.ChatMessage{}, err
+	}
+	msg, err := s.repo.MessageByID(ctx, messageID)
+	if err != nil {
+		return msg, mapChatNotFound(err, ErrChatMessageNotFound)
+	}
+	if msg.RoomId != roomID || msg.IsDeleted {
+		return msg, ErrChatMessageNotFound
+	}
+	return msg, nil
+}
+
+// publishRoom нь event-ийг өрөөний бүх идэвхтэй гишүүн рүү илгээнэ.
+// Хүргэлт нь best-effort: алдаа гарвал log бичээд үргэлжилнэ.
+func (s *ChatRoomService) publishRoom(ctx context.Context, roomID int, typ string, data any) {
+	ids, err := s.repo.MemberUserIDs(ctx, roomID)
+	if err != nil {
+		s.log.Warn("chat_publish_members_failed", zap.Int("room_id", roomID), zap.Error(err))
+		return
+	}
+	s.publish(ids, typ, data)
+}
+
+func (s *ChatRoomService) publish(userIDs []int, typ string, data any) {
+	if s.pub == nil || len(userIDs) == 0 {
+		return
+	}
+	s.pub.Publish(userIDs, realtime.Event{Type: typ, Data: data})
+}
+
+// uniqueOthers нь давхардлыг болон self-ийг хассан ID-ууд.
+func uniqueOthers(ids []int, self int) []int {
+	out := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if id != self && id > 0 && !slices.Contains(out, id) {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func mapChatNotFound(err, notFound error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notFound
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+// Package service provides implementation for service
+//
+// File: file_blob_store.go
+// Description: Content-addressed storage of uploads with reference counting
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/repository"
+	"templatev25/internal/storage"
+	"templatev25/internal/upload"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// blobPutAttempts нь зэрэг upload-той өрсөлдөхөд blob бүртгэх оролдлогын тоо.
+const blobPutAttempts = 3
+
+// blobStore нь агуулгыг SHA-256-аар нь нэг л удаа хадгална. Upload бүр blob-ийн
+// reference авч, сүүлийн reference чөлөөлөгдөхөд л storage-оос устгагдана.
+type blobStore struct {
+	blobs    repository.FileBlobRepository
+	store    storage.Storage
+	driver   string
+	isPublic bool
+	// remove нь object-ийг (зургийн хувилбаруудтай нь) storage-оос устгана
+	remove func(ctx context.Context, key string) error
+}
+
+// put нь src-ийн агуулгатай blob-ийн reference-ийг буцаана. Ижил агуулга
+// хадгалагдсан бол дахин бичихгүй; created нь шинээр хадгалсан эсэх.
+func (b *blobStore) put(ctx context.Context, src io.ReadSeeker, size int64, info upload.Inspection) (blob domain.FileBlob, created bool, err error) {
+	checksum, err := sha256Hex(src)
+	if err != nil {
+		return domain.FileBlob{}, false, err
+	}
+
+	for attempt := 0; attempt < blobPutAttempts; attempt++ {
+		blob, err := b.blobs.Acquire(ctx, b.driver, b.isPublic, checksum)
+		if err == nil {
+			return blob, false, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.FileBlob{}, false, err
+		}
+
+		// Key нь checksum биш uuid: зэрэг хадгалсан хоёр хуулбарын ялагдсан нь
+		// зөвхөн өөрийнхөө object-ийг устгана
+		key := uuid.NewString() + info.Ext
+		if _, err := src.Seek(0, io.SeekStart); err != nil {
+			return domain.FileBlob{}, false, err
+		}
+		if err := b.store.Put(ctx, key, src, size, info.ContentType); err != nil {
+			return domain.FileBlob{}, false, err
+		}
+		blob, ok, err := b.blobs.Create(ctx, domain.FileBlob{
+			StorageProvider: b.driver,
+			IsPublic:        b.isPublic,
+			Checksum:        checksum,
+			StoragePath:     key,
+			MimeType:        info.ContentType,
+			FileSize:        size,
+		})
+		if err == nil && ok {
+			return blob, true, nil
+		}
+		_ = b.store.Delete(ctx, key)
+		if err != nil {
+			return domain.FileBlob{}, false, err
+		}
+	}
+	return domain.FileBlob{}, false, fmt.Errorf("store blob %s: too many concurrent uploads", checksum)
+}
+
+// release нь f-ийн blob-ийн reference-ийг чөлөөлж, сүүлийнх байсан бол
+// object-ийг устгана. Blob-гүй (dedup-ээс өмнөх) бүртгэлийн object-ийг шууд устгана.
+func (b *blobStore) release(ctx context.Context, f domain.File) error {
+	if f.BlobId == nil {
+		return b.remove(ctx, f.StoragePath)
+	}
+	blob, last, err := b.blobs.Release(ctx, *f.BlobId)
+	if err != nil || !last {
+		return err
+	}
+	return b.remove(ctx, blob.StoragePath)
+}
+
+// discard нь бүртгэл үүсгэж чадаагүй upload-ийн reference-ийг чөлөөлнө;
+// алдааг зөвхөн log-д бичнэ.
+func (b *blobStore) discard(ctx context.Context, id int) {
+	if err := b.release(ctx, domain.File{BlobId: &id}); err != nil {
+		fmt.Printf("WARN: failed to release blob %d: %v\n", id, err)
+	}
+}
+
+// sha256Hex нь src-ийн SHA-256-г тооцоод эхэнд нь буцаана.
+func sha256Hex(src io.ReadSeeker) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, src); err != nil {
+		return "", err
+	}
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,122 @@
+// Package service provides implementation for service
+//
+// File: job_service.go
+// Description: Scheduled job administration (list, history, trigger, pause/resume)
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/http/dto"
+	"templatev25/internal/repository"
+	"templatev25/internal/scheduler"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrJobNotFound нь job олдоогүй үед буцна.
+	ErrJobNotFound = errors.New("scheduled job not found")
+	// ErrJobInvalidCron нь resume хийх job-ийн cron_expression буруу үед буцна.
+	ErrJobInvalidCron = errors.New("invalid cron expression")
+	// ErrJobRunning нь job аль хэдийн ажиллаж байхад trigger хийх үед буцна.
+	ErrJobRunning = scheduler.ErrJobRunning
+	// ErrJobBusy нь зэрэг ажиллах job-ийн хязгаар дүүрсэн үед trigger хийхэд буцна.
+	ErrJobBusy = scheduler.ErrSchedulerBusy
+)
+
+// JobTrigger нь job-ийг гараар ажиллуулна (*scheduler.Scheduler).
+type JobTrigger interface {
+	Trigger(ctx context.Context, jobID int) (domain.JobExecution, error)
+}
+
+type JobService struct {
+	repo    repository.JobRepository
+	trigger JobTrigger
+	log     *zap.Logger
+	now     func() time.Time
+}
+
+func NewJobService(repo repository.JobRepository, trigger JobTrigger, log *zap.Logger) *JobService {
+	return &JobService{repo: repo, trigger: trigger, log: log, now: time.Now}
+}
+
+func (s *JobService) List(ctx context.Context, q dto.JobListQuery) ([]domain.ScheduledJob, int64, int, int, error) {
+	return s.repo.List(ctx, q)
+}
+
+// Executions нь job-ийн ажиллагааны түүхийг буцаана.
+func (s *JobService) Executions(ctx context.Context, jobID int, q dto.JobExecutionListQuery) ([]domain.JobExecution, int64, int, int, error) {
+	if _, err := s.byID(ctx, jobID); err != nil {
+		return nil, 0, 0, 0, err
+	}
+	return s.repo.Executions(ctx, jobID, q)
+}
+
+// Trigger нь job-ийг хуваариас үл хамааран одоо ажиллуулна.
+func (s *JobService) Trigger(ctx context.Context, jobID int) (domain.JobExecution, error) {
+	exec, err := s.trigger.Trigger(ctx, jobID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return exec, ErrJobNotFound
+	}
+	if err != nil {
+		s.log.Warn("job_trigger_failed", zap.Int("job_id", jobID), zap.Error(err))
+		return exec, err
+	}
+	s.log.Info("job_triggered", zap.Int("job_id", jobID), zap.Int("execution_id", exec.Id))
+	return exec, nil
+}
+
+// Pause нь job-ийг идэвхгүй болгож next_run_at-ийг цэвэрлэнэ.
+// Ажиллаж буй execution тасрахгүй.
+func (s *JobService) Pause(ctx context.Context, jobID int) (domain.ScheduledJob, error) {
+	if err := s.repo.SetActive(ctx, jobID, false, nil); err != nil {
+		return domain.ScheduledJob{}, s.mapNotFound(err)
+	}
+	s.log.Info("job_paused", zap.Int("job_id", jobID))
+	return s.byID(ctx, jobID)
+}
+
+// Resume нь job-ийг идэвхжүүлж next_run_at-ийг одооноос дахин тооцоолно
+// (зогссон хугацааны ажиллагаануудыг нөхөхгүй).
+func (s *JobService) Resume(ctx context.Context, jobID int) (domain.ScheduledJob, error) {
+	job, err := s.byID(ctx, jobID)
+	if err != nil {
+		return job, err
+	}
+
+	var next *time.Time
+	if job.CronExpression != "" {
+		schedule, err := scheduler.ParseCron(job.CronExpression)
+		if err != nil {
+			return job, errors.Join(ErrJobInvalidCron, err)
+		}
+		t := schedule.Next(s.now())
+		if t.IsZero() {
+			return job, ErrJobInvalidCron
+		}
+		next = &t
+	}
+
+	if err := s.repo.SetActive(ctx, jobID, true, next); err != nil {
+		return job, s.mapNotFound(err)
+	}
+	s.log.Info("job_resumed", zap.Int("job_id", jobID))
+	return s.byID(ctx, jobID)
+}
+
+func (s *JobService) byID(ctx context.Context, jobID int) (domain.ScheduledJob, error) {
+	job, err := s.repo.ByID(ctx, jobID)
+	return job, s.mapNotFound(err)
+}
+
+func (s *JobService) mapNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrJobNotFound
+	}
+	return err
+}
@@ -0,0 +1,266 @@
+// Package service provides implementation for service
+//
+// File: notification_dispatcher.go
+// Description: Routes saved notifications to email, SMS and push per user settings
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/http/dto"
+	"templatev25/internal/notify"
+	"templatev25/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// dispatchBatch нь broadcast-д нэг удаа уншиж хүргэх хэрэглэгчийн тоо.
+	dispatchBatch = 500
+
+	// dispatchBatchTimeout нь нэг batch-ийн (хүлээн авагч унших, илгээх) хугацаа.
+	// Dispatch/DispatchAll-д нийт хугацааны хязгаар байхгүй: том broadcast-ийн
+	// batch бүр өөрийн context-той тул хожуу batch-ууд хугацаа дуусаагүй байна.
+	dispatchBatchTimeout = 2 * time.Minute
+
+	// recordTimeout нь хүргэлтийн бүртгэл бичих хугацаа. Batch-ийн context
+	// дууссан ч бүртгэл бичигдэхээр тусдаа context ашиглана.
+	recordTimeout = 30 * time.Second
+)
+
+// NotificationChannelDispatcher нь хадгалсан мэдэгдлийг in-app-аас бусад
+// сувгаар (email, SMS, push) хүргэнэ. Дуудлага блоклохгүй.
+type NotificationChannelDispatcher interface {
+	// Dispatch нь мэдэгдэл бүрийг n.UserId хэрэглэгч рүү хүргэнэ.
+	Dispatch(ctx context.Context, ns []domain.Notification)
+	// DispatchAll нь broadcast мэдэгдлийг бүх хэрэглэгч рүү хүргэнэ.
+	DispatchAll(ctx context.Context, n domain.Notification)
+}
+
+// NotificationDispatcher нь хэрэглэгч бүрийн user_settings-ийн
+// notification_push/email/sms тохиргооны дагуу provider сонгож,
+// оролдлого бүрийг notification_deliveries-д бүртгэнэ.
+//
+// Хэрэглэгч унтраасан сувгийг бүртгэхгүй; суваг идэвхтэй ч хаяггүй
+// (notify.ErrNoAddress) бол skipped гэж бүртгэнэ.
+type NotificationDispatcher struct {
+	repo         repository.NotificationDeliveryRepository
+	providers    []notify.Provider
+	logger       *zap.Logger
+	batchTimeout time.Duration
+
+	mu      sync.Mutex
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+// NewNotificationDispatcher нь dispatcher үүсгэнэ.
+// Provider-гүй бол Dispatch юу ч хийхгүй.
+func NewNotificationDispatcher(repo repository.NotificationDeliveryRepository, logger *zap.Logger, providers ...notify.Provider) *NotificationDispatcher {
+	return &NotificationDispatcher{
+		repo:         repo,
+		providers:    providers,
+		logger:       logger,
+		batchTimeout: dispatchBatchTimeout,
+	}
+}
+
+// Dispatch нь мэдэгдлүүдийг background-д хүргэнэ (request context-оос салгасан).
+func (d *NotificationDispatcher) Dispatch(ctx context.Context, ns []domain.Notification) {
+	if len(ns) == 0 {
+		return
+	}
+	d.run(ctx, func(ctx context.Context) {
+		byUser := make(map[int][]domain.Notification, len(ns))
+		ids := make([]int, 0, len(ns))
+		for _, n := range ns {
+			if _, ok := byUser[n.UserId]; !ok {
+				ids = append(ids, n.UserId)
+			}
+			byUser[n.UserId] = append(byUser[n.UserId], n)
+		}
+
+		for start := 0; start < len(ids); start += dispatchBatch {
+			end := min(start+dispatchBatch, len(ids))
+			err := d.batch(ctx, func(ctx context.Context) ([]domain.NotificationDelivery, error) {
+				rs, err := d.repo.Recipients(ctx, ids[start:end])
+				if err != nil {
+					return nil, err
+				}
+				var log []domain.NotificationDelivery
+				for _, r := range rs {
+					for _, n := range byUser[r.UserId] {
+						log = append(log, d.deliver(ctx, r, n)...)
+					}
+				}
+				return log, nil
+			})
+			if err != nil {
+				d.logger.Error("failed to load notification recipients",
+					zap.Int("skipped_users", len(ids)-start),
+					zap.Error(err),
+				)
+				return
+			}
+		}
+	})
+}
+
+// DispatchAll нь broadcast мэдэгдлийг бүх идэвхтэй хэрэглэгч рүү
+// dispatchBatch-аар хуудаслан хүргэнэ.
+func (d *NotificationDispatcher) DispatchAll(ctx context.Context, n domain.Notification) {
+	d.run(ctx, func(ctx context.Context) {
+		afterID := 0
+		for {
+			var rs []domain.NotificationRecipient
+			err := d.batch(ctx, func(ctx context.Context) ([]domain.NotificationDelivery, error) {
+				var err error
+				if rs, err = d.repo.RecipientsAfter(ctx, afterID, dispatchBatch); err != nil {
+					return nil, err
+				}
+				var log []domain.NotificationDelivery
+				for _, r := range rs {
+					log = append(log, d.deliver(ctx, r, n)...)
+				}
+				return log, nil
+			})
+			if err != nil {
+				// after_id-аас хойшхи хэрэглэгчид хүргэгдээгүй
+				d.logger.Error("failed to load notification recipients",
+					zap.Int("group_id", n.GroupId),
+					zap.Int("after_id", afterID),
+					zap.Error(err),
+				)
+				return
+			}
+
+			if len(rs) < dispatchBatch {
+				return
+			}
+			afterID = rs[len(rs)-1].UserId
+		}
+	})
+}
+
+// Deliveries нь хүргэлтийн бүртгэлийг шүүж буцаана (admin).
+func (d *NotificationDispatcher) Deliveries(ctx context.Context, q dto.NotificationDeliveryListQuery) ([]domain.NotificationDelivery, int64, int, int, error) {
+	return d.repo.List(ctx, q)
+}
+
+// Stop нь шинэ dispatch хүлээж авахаа больж, ажиллаж буйг дуусахыг хүлээнэ.
+// Эхэлсэн broadcast бүх batch-аа дуусгана.
+func (d *NotificationDispatcher) Stop() {
+	d.mu.Lock()
+	d.stopped = true
+	d.mu.Unlock()
+	d.wg.Wait()
+}
+
+// run нь fn-ийг request-ийн цуцлалтаас салгаж background goroutine-д ажиллуулна.
+func (d *NotificationDispatcher) run(ctx context.Context, fn func(context.Context)) {
+	if len(d.providers) == 0 {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped {
+		d.logger.Warn("notification dispatcher stopped, dropping dispatch")
+		return
+	}
+
+	d.wg.Add(1)
+	runCtx := context.WithoutCancel(ctx)
+	go func() {
+		defer d.wg.Done()
+		fn(runCtx)
+	}()
+}
+
+// batch нь fn-ийг batchTimeout-той ажиллуулж, буцаасан бүртгэлийг (алдаатай
+// байсан ч) хадгална.
+func (d *NotificationDispatcher) batch(ctx context.Context, fn func(context.Context) ([]domain.NotificationDelivery, error)) error {
+	bctx, cancel := context.WithTimeout(ctx, d.batchTimeout)
+	log, err := fn(bctx)
+	cancel()
+	d.record(ctx, log)
+	return err
+}
+
+// deliver нь нэг хэрэглэгч рүү идэвхтэй бүх сувгаар илгээж бүртгэлийн мөрүүдийг буцаана.
+func (d *NotificationDispatcher) deliver(ctx context.Context, r domain.NotificationRecipient, n domain.Notification) []domain.NotificationDelivery {
+	rcpt := notify.Recipient{
+		UserID:   r.UserId,
+		Email:    r.Email,
+		Phone:    r.PhoneNo,
+		Language: r.Language,
+	}
+	msg := notify.Message{
+		NotificationID: n.Id,
+		GroupID:        n.GroupId,
+		Type:           n.Type,
+		Tenant:         n.Tenant,
+		Title:          n.Title,
+		Content:        n.Content,
+	}
+
+	var out []domain.NotificationDelivery
+	for _, p := range d.providers {
+		if !channelEnabled(r, p.Channel()) {
+			continue
+		}
+		entry := domain.NotificationDelivery{
+			NotificationId: n.Id,
+			GroupId:        n.GroupId,
+			UserId:         r.UserId,
+			Channel:        p.Channel(),
+			Provider:       p.Name(),
+			Status:         domain.NotificationDeliverySent,
+		}
+		if err := ctx.Err(); err != nil {
+			// Batch-ийн хугацаа дууссан: илгээгээгүйг бүртгэнэ
+			entry.Status = domain.NotificationDeliveryFailed
+			entry.Error = "not sent: " + err.Error()
+		} else if err := p.Send(ctx, rcpt, msg); err != nil {
+			entry.Status = domain.NotificationDeliveryFailed
+			if errors.Is(err, notify.ErrNoAddress) {
+				entry.Status = domain.NotificationDeliverySkipped
+			}
+			entry.Error = err.Error()
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// record нь хүргэлтийн бүртгэлийг recordTimeout-той шинэ context-оор хадгална;
+// алдааг зөвхөн log-д бичнэ.
+func (d *NotificationDispatcher) record(ctx context.Context, log []domain.NotificationDelivery) {
+	if len(log) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+	if err := d.repo.LogDeliveries(ctx, log); err != nil {
+		d.logger.Error("failed to record notification deliveries",
+			zap.Int("count", len(log)),
+			zap.Error(err),
+		)
+	}
+}
+
+// channelEnabled нь хэрэглэгч тухайн сувгийг асаасан эсэх.
+func channelEnabled(r domain.NotificationRecipient, channel string) bool {
+	switch channel {
+	case domain.NotificationChannelEmail:
+		return r.NotificationEmail
+	case domain.NotificationChannelSMS:
+		return r.NotificationSms
+	case domain.NotificationChannelPush:
+		return r.NotificationPush
+	}
+	return false
+}
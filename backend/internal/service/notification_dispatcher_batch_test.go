@@ -0,0 +1,105 @@
+// Package service provides implementation for service
+//
+// File: notification_dispatcher_batch_test.go
+// Description: Unit tests for per-batch deadlines of the notification dispatcher
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/notify"
+	"templatev25/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// batchDeliveryRepo нь хүлээн авагчдыг санах ойгоос өгч, бүртгэлийг
+// зөвхөн хүчинтэй context-оор хүлээн авна
+type batchDeliveryRepo struct {
+	repository.NotificationDeliveryRepository
+
+	mu         sync.Mutex
+	recipients []domain.NotificationRecipient
+	logged     []domain.NotificationDelivery
+	calls      int
+}
+
+func (r *batchDeliveryRepo) RecipientsAfter(ctx context.Context, afterID, limit int) ([]domain.NotificationRecipient, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var out []domain.NotificationRecipient
+	for _, rc := range r.recipients {
+		if rc.UserId > afterID && len(out) < limit {
+			out = append(out, rc)
+		}
+	}
+	return out, nil
+}
+
+func (r *batchDeliveryRepo) LogDeliveries(ctx context.Context, ds []domain.NotificationDelivery) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	r.logged = append(r.logged, ds...)
+	return nil
+}
+
+// slowProvider нь hang хэрэглэгч рүү илгээхдээ context дуусахыг хүлээнэ
+type slowProvider struct {
+	hang int
+	sent []int
+}
+
+func (p *slowProvider) Channel() string { return domain.NotificationChannelPush }
+func (p *slowProvider) Name() string    { return "slow" }
+
+func (p *slowProvider) Send(ctx context.Context, r notify.Recipient, _ notify.Message) error {
+	if r.UserID == p.hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	p.sent = append(p.sent, r.UserID)
+	return nil
+}
+
+func TestNotificationDispatcher_BatchDeadline(t *testing.T) {
+	repo := &batchDeliveryRepo{}
+	for id := 1; id <= dispatchBatch+2; id++ {
+		repo.recipients = append(repo.recipients, domain.NotificationRecipient{UserId: id, NotificationPush: true})
+	}
+	// Эхний batch-ийн 2 дахь хэрэглэгч дээр batch-ийн хугацаа дуусна
+	push := &slowProvider{hang: 2}
+
+	d := NewNotificationDispatcher(repo, zap.NewNop(), push)
+	d.batchTimeout = 50 * time.Millisecond
+	d.DispatchAll(context.Background(), domain.Notification{GroupId: 8})
+	d.Stop()
+
+	require.Len(t, repo.logged, dispatchBatch+2, "every user is recorded")
+	assert.Equal(t, 2, repo.calls, "each batch is recorded with its own context")
+
+	status := map[int]domain.NotificationDelivery{}
+	for _, l := range repo.logged {
+		status[l.UserId] = l
+	}
+	assert.Equal(t, domain.NotificationDeliverySent, status[1].Status)
+	assert.Equal(t, domain.NotificationDeliveryFailed, status[2].Status)
+	assert.Equal(t, domain.NotificationDeliveryFailed, status[3].Status)
+	assert.True(t, strings.HasPrefix(status[3].Error, "not sent: "), "users after the deadline are recorded as not sent")
+
+	// Дараагийн batch шинэ хугацаатай тул хүргэгдэнэ
+	assert.Equal(t, domain.NotificationDeliverySent, status[dispatchBatch+1].Status)
+	assert.Equal(t, domain.NotificationDeliverySent, status[dispatchBatch+2].Status)
+	assert.Equal(t, []int{1, dispatchBatch + 1, dispatchBatch + 2}, push.sent)
+}
@@ -10,8 +10,9 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"time"
+	"slices"
 
 	"templatev25/internal/domain"
 	"templatev25/internal/http/dto"
@@ -19,30 +20,34 @@ import (
 
 	"git.gerege.mn/backend-packages/common"
 	"git.gerege.mn/backend-packages/config"
-	"git.gerege.mn/backend-packages/httpx"
+	"gorm.io/datatypes"
 )
 
-// Default Socket API base URL (fallback if config not provided)
-const defaultSocketAPIBase = "https://socket.gerege.mn/api"
-
 type NotificationService struct {
-	repo repository.NotificationRepository
-	http *httpx.Client
-	cfg  *config.Config
+	repo       repository.NotificationRepository
+	cfg        *config.Config
+	transports []NotificationTransport
+	dispatcher NotificationChannelDispatcher
 }
 
 func NewNotificationService(repo repository.NotificationRepository, cfg *config.Config) *NotificationService {
 	return &NotificationService{
 		repo: repo,
-		http: httpx.New(3 * time.Second),
 		cfg:  cfg,
 	}
 }
 
-// getSocketAPIBase returns the socket API base URL
-// TODO: Add Socket field to config.URLConfig when available
-func (s *NotificationService) getSocketAPIBase() string {
-	return defaultSocketAPIBase
+// SetTransports нь хадгалсан мэдэгдлийг хүргэх transport-уудыг тохируулна
+// (realtime WebSocket/SSE, сонголтоор гадны socket service).
+// Transport-гүй бол мэдэгдэл зөвхөн DB-д хадгалагдана.
+func (s *NotificationService) SetTransports(transports ...NotificationTransport) {
+	s.transports = transports
+}
+
+// SetDispatcher нь email, SMS, push сувгийн dispatcher-ийг тохируулна.
+// Dispatcher-гүй бол мэдэгдэл зөвхөн in-app (DB + transport)-аар хүргэгдэнэ.
+func (s *NotificationService) SetDispatcher(d NotificationChannelDispatcher) {
+	s.dispatcher = d
 }
 
 // List for current user
@@ -62,93 +67,171 @@ func (s *NotificationService) MarkAllRead(ctx context.Context, userID int) error
 	return s.repo.MarkAllRead(ctx, userID)
 }
 
-// Send: if UserID==0 => broadcast_all, else direct (dm)
+// ErrNotificationNoRecipients нь target-д таарах хэрэглэгч олдоогүй.
+var ErrNotificationNoRecipients = errors.New("no users match the notification target")
+
+// Send нь мэдэгдлийг хүлээн авагчдад илгээнэ:
+//   - target заасан бол => targeted (role, байгууллага, төрөл, user_ids-ийн нэгдэл; user_id мөн нэмэгдэнэ)
+//   - эс бөгөөс UserID != 0 => direct (dm)
+//   - эс бөгөөс => broadcast_all (зөвхөн group хадгалагдана, fan-out-on-read)
+//
+// Мэдэгдлийг эхлээд DB-д хадгалж дараа нь бүх transport-оор хүргэнэ.
+// Email, SMS, push сувгууд dispatcher-аар background-д хүргэгдэнэ.
+// Хүргэлтийн алдааг буцаах ч хадгалсан мэдэгдэл REST-ээр харагдсаар байна.
 func (s *NotificationService) Send(ctx context.Context, req dto.NotificationSendDto, createdUsername string) error {
-	// 1) Create group
+	typ, target := classifyNotification(req)
+
+	// 1) Resolve recipients (broadcast-д зөвхөн тоо)
+	var (
+		ids   []int
+		count int
+		err   error
+	)
+	switch typ {
+	case domain.NotificationTypeDirect:
+		ids = []int{req.UserID}
+		count = 1
+	case domain.NotificationTypeBroadcast:
+		var n int64
+		n, err = s.repo.CountUsers(ctx)
+		count = int(n)
+	default:
+		ids, err = s.repo.ResolveRecipients(ctx, target)
+		if err == nil && len(ids) == 0 {
+			return ErrNotificationNoRecipients
+		}
+		count = len(ids)
+	}
+	if err != nil {
+		return err
+	}
+
+	// 2) Create group (target нь хэнд илгээснийг admin-д харуулна)
 	group := domain.NotificationGroup{
-		UserId:  req.UserID,
 		Title:   req.Title,
 		Content: req.Content,
-		Type:    typeOf(req.UserID), // "dm" | "broadcast_all"
+		Type:    typ,
 		Tenant:  req.Tenant,
 		// CreatedUserId:   createdBy,
 		CreatedUsername: createdUsername,
+		Target:          datatypes.NewJSONType(target),
+		RecipientCount:  count,
+		// Broadcast нь хэрэглэгч бүрт мөр үүсгэхгүй, уншихад group-ээс гарна
+		FanOutOnRead: typ == domain.NotificationTypeBroadcast,
+	}
+	if typ == domain.NotificationTypeDirect {
+		group.UserId = req.UserID
 	}
 	g, err := s.repo.CreateGroup(ctx, group)
 	if err != nil {
 		return err
 	}
 
-	if req.UserID != 0 {
-		// 2a) Direct notification
-		n := domain.Notification{
-			UserId:  req.UserID,
+	newNotification := func(userID int) domain.Notification {
+		return domain.Notification{
+			UserId:  userID,
 			Title:   req.Title,
 			Content: req.Content,
 			IsRead:  false,
-			Type:    "dm",
+			Type:    typ,
 			Tenant:  req.Tenant,
 			GroupId: g.Id,
 			// CreatedUserId:   createdBy,
 			CreatedUsername: createdUsername,
 		}
-		if _, err := s.repo.CreateNotification(ctx, n); err != nil {
+	}
+
+	if typ == domain.NotificationTypeDirect {
+		// 3a) Direct notification
+		saved, err := s.repo.CreateNotification(ctx, newNotification(req.UserID))
+		if err != nil {
 			return err
 		}
-		// 3a) Call socket /send
-		body := map[string]any{
-			"to":              fmt.Sprintf("%d", req.UserID),
-			"idempotency_key": req.IdempotentKey,
-			"body":            n,
-		}
-		_, _, err = httpx.PostJSON[map[string]any, any](ctx, s.http, s.getSocketAPIBase()+"/send", nil, body)
-		return err
+		s.dispatch(ctx, []domain.Notification{saved})
+		return s.deliver(func(t NotificationTransport) error {
+			return t.Send(ctx, saved, req.IdempotentKey)
+		})
 	}
 
-	// 2b) Broadcast: call socket first
-	bcast := domain.Notification{
-		UserId:  0,
-		Title:   req.Title,
-		Content: req.Content,
-		IsRead:  false,
-		Tenant:  req.Tenant,
-		Type:    "broadcast_all",
-		GroupId: g.Id,
+	if typ == domain.NotificationTypeBroadcast {
+		// 3b) Broadcast: group is the only row
+		if s.dispatcher != nil {
+			s.dispatcher.DispatchAll(ctx, newNotification(0))
+		}
+		return s.deliver(func(t NotificationTransport) error {
+			return t.Broadcast(ctx, newNotification(0), req.IdempotentKey)
+		})
 	}
-	body := map[string]any{
-		"tenant":          req.Tenant,
-		"idempotency_key": req.IdempotentKey,
-		"body":            bcast,
+
+	// 3c) Targeted: create notifications for every recipient
+	bulk := make([]domain.Notification, 0, len(ids))
+	for _, uid := range ids {
+		bulk = append(bulk, newNotification(uid))
 	}
-	if _, _, err := httpx.PostJSON[map[string]any, any](ctx, s.http, s.getSocketAPIBase()+"/broadcast", nil, body); err != nil {
+	if err := s.repo.CreateNotificationsBulk(ctx, bulk); err != nil {
 		return err
 	}
+	s.dispatch(ctx, bulk)
+	// Нэг event-ийг бүх хүлээн авагч руу (хэрэглэгч бүрт тусдаа биш)
+	return s.deliver(func(t NotificationTransport) error {
+		return t.SendMany(ctx, ids, newNotification(0), req.IdempotentKey)
+	})
+}
 
-	// 3b) Create notifications for all users
-	ids, err := s.repo.AllUserIDs(ctx)
-	if err != nil {
-		return err
+// Stop нь background-д ажиллаж буй transport-уудын хүргэлтийг дуусахыг хүлээнэ.
+func (s *NotificationService) Stop() {
+	for _, t := range s.transports {
+		if st, ok := t.(interface{ Stop() }); ok {
+			st.Stop()
+		}
 	}
-	bulk := make([]domain.Notification, 0, len(ids))
-	for _, uid := range ids {
-		bulk = append(bulk, domain.Notification{
-			UserId:  uid,
-			Title:   req.Title,
-			Content: req.Content,
-			IsRead:  false,
-			Tenant:  req.Tenant,
-			Type:    "broadcast_all",
-			GroupId: g.Id,
-			// CreatedUserId:   createdBy,
-			CreatedUsername: createdUsername,
-		})
+}
+
+// classifyNotification нь хүсэлтийн төрөл болон хадгалах target-ийг тодорхойлно.
+func classifyNotification(req dto.NotificationSendDto) (string, domain.NotificationTarget) {
+	var target domain.NotificationTarget
+	if req.Target != nil {
+		target = domain.NotificationTarget{
+			RoleCodes:      req.Target.RoleCodes,
+			OrgIDs:         req.Target.OrgIDs,
+			IncludeSubOrgs: req.Target.IncludeSubOrgs,
+			OrgTypeIDs:     req.Target.OrgTypeIDs,
+			UserIDs:        req.Target.UserIDs,
+		}
+	}
+	if target.Empty() {
+		if req.UserID != 0 {
+			return domain.NotificationTypeDirect, domain.NotificationTarget{}
+		}
+		return domain.NotificationTypeBroadcast, domain.NotificationTarget{}
+	}
+	if req.UserID != 0 && !slices.Contains(target.UserIDs, req.UserID) {
+		target.UserIDs = append(target.UserIDs, req.UserID)
+	}
+	if len(target.OrgIDs) == 0 {
+		target.IncludeSubOrgs = false
+	}
+	return domain.NotificationTypeTargeted, target
+}
+
+// dispatch нь хадгалсан мэдэгдлүүдийг email, SMS, push сувгаар хүргүүлнэ.
+func (s *NotificationService) dispatch(ctx context.Context, ns []domain.Notification) {
+	if s.dispatcher != nil {
+		s.dispatcher.Dispatch(ctx, ns)
 	}
-	return s.repo.CreateNotificationsBulk(ctx, bulk)
 }
 
-func typeOf(userID int) string {
-	if userID == 0 {
-		return "broadcast_all"
+// deliver нь бүх transport-ийг дуудаж алдаануудыг нэгтгэнэ.
+// Нэг transport унасан ч бусад нь ажиллана.
+func (s *NotificationService) deliver(fn func(NotificationTransport) error) error {
+	var errs []error
+	for _, t := range s.transports {
+		if err := fn(t); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("notification saved but delivery failed: %w", err)
 	}
-	return "dm"
+	return nil
 }
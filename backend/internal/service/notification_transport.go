@@ -0,0 +1,178 @@
+// Package service provides implementation for service
+//
+// File: notification_transport.go
+// Description: Delivery transports for saved notifications
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/realtime"
+
+	"git.gerege.mn/backend-packages/httpx"
+	"go.uber.org/zap"
+)
+
+// EventNotificationCreated нь шинэ мэдэгдлийн realtime event.
+const EventNotificationCreated = "notification.created"
+
+// realtimePublishBatch нь нэг Publish (нэг Redis мессеж)-д багтаах
+// хүлээн авагчийн тоо. Том target-ийн мессеж хэт томрохоос сэргийлнэ.
+const realtimePublishBatch = 1000
+
+// NotificationTransport нь DB-д хадгалагдсан мэдэгдлийг хэрэглэгч рүү хүргэнэ.
+// Хүргэлт амжилтгүй болсон ч мэдэгдэл DB-д үлдэж REST-ээр харагдана.
+type NotificationTransport interface {
+	// Send нь n.UserId хэрэглэгч рүү хүргэнэ.
+	Send(ctx context.Context, n domain.Notification, idempotencyKey string) error
+	// SendMany нь ижил мэдэгдлийг userIDs руу хүргэнэ (targeted).
+	// n нь group-ийн мэдэгдэл (UserId = 0).
+	SendMany(ctx context.Context, userIDs []int, n domain.Notification, idempotencyKey string) error
+	// Broadcast нь n.Tenant-ийн бүх хэрэглэгч рүү хүргэнэ.
+	Broadcast(ctx context.Context, n domain.Notification, idempotencyKey string) error
+}
+
+// NotificationPublisher нь event-ийг холбогдсон хэрэглэгчид рүү илгээнэ
+// (*realtime.RedisBroker, *realtime.Hub).
+type NotificationPublisher interface {
+	Publish(userIDs []int, ev realtime.Event)
+	PublishAll(ev realtime.Event)
+}
+
+// ============================================================
+// REALTIME (WebSocket/SSE)
+// ============================================================
+
+// RealtimeNotificationTransport нь мэдэгдлийг backend-ийн өөрийн
+// WebSocket/SSE холболтоор хүргэнэ. Холбогдоогүй хэрэглэгч дараа нь
+// REST-ээр авна.
+type RealtimeNotificationTransport struct {
+	pub NotificationPublisher
+}
+
+// NewRealtimeNotificationTransport нь realtime transport үүсгэнэ.
+func NewRealtimeNotificationTransport(pub NotificationPublisher) *RealtimeNotificationTransport {
+	return &RealtimeNotificationTransport{pub: pub}
+}
+
+// Send нь хэрэглэгчийн бүх холболт руу notification.created илгээнэ.
+func (t *RealtimeNotificationTransport) Send(_ context.Context, n domain.Notification, _ string) error {
+	t.pub.Publish([]int{n.UserId}, realtime.Event{Type: EventNotificationCreated, Data: n})
+	return nil
+}
+
+// SendMany нь хүлээн авагчдын холболтууд руу notification.created-ийг
+// realtimePublishBatch хэмжээтэй Publish-ээр илгээнэ (хэрэглэгч бүрт биш).
+func (t *RealtimeNotificationTransport) SendMany(_ context.Context, userIDs []int, n domain.Notification, _ string) error {
+	ev := realtime.Event{Type: EventNotificationCreated, Data: n}
+	for start := 0; start < len(userIDs); start += realtimePublishBatch {
+		t.pub.Publish(userIDs[start:min(start+realtimePublishBatch, len(userIDs))], ev)
+	}
+	return nil
+}
+
+// Broadcast нь бүх холбогдсон хэрэглэгч рүү notification.created илгээнэ.
+func (t *RealtimeNotificationTransport) Broadcast(_ context.Context, n domain.Notification, _ string) error {
+	t.pub.PublishAll(realtime.Event{Type: EventNotificationCreated, Data: n})
+	return nil
+}
+
+// ============================================================
+// EXTERNAL SOCKET SERVICE
+// ============================================================
+
+// SocketNotificationTransport нь мэдэгдлийг гадны socket service-ийн
+// /send, /broadcast endpoint-оор хүргэнэ.
+//
+// Socket service-д олон хүлээн авагчтай endpoint байхгүй тул SendMany нь
+// хэрэглэгч бүрт /send дуудна. Үүнийг admin-ийн request-ийг хүлээлгэхгүйн
+// тулд background-д (request context-оос салгаж) ажиллуулна.
+type SocketNotificationTransport struct {
+	baseURL string
+	http    *httpx.Client
+	log     *zap.Logger
+
+	mu      sync.Mutex
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+// NewSocketNotificationTransport нь socket service-ийн base URL-аар transport үүсгэнэ.
+func NewSocketNotificationTransport(baseURL string, timeout time.Duration, log *zap.Logger) *SocketNotificationTransport {
+	return &SocketNotificationTransport{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		http:    httpx.New(timeout),
+		log:     log,
+	}
+}
+
+// Send нь socket /send руу илгээнэ.
+func (t *SocketNotificationTransport) Send(ctx context.Context, n domain.Notification, idempotencyKey string) error {
+	body := map[string]any{
+		"to":              strconv.Itoa(n.UserId),
+		"idempotency_key": idempotencyKey,
+		"body":            n,
+	}
+	_, _, err := httpx.PostJSON[map[string]any, any](ctx, t.http, t.baseURL+"/send", nil, body)
+	return err
+}
+
+// SendMany нь хэрэглэгч бүрт socket /send-ийг background-д дуудна.
+// Алдааг log-д бичнэ; мэдэгдэл DB-д хадгалагдсан тул REST-ээр харагдана.
+func (t *SocketNotificationTransport) SendMany(ctx context.Context, userIDs []int, n domain.Notification, idempotencyKey string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stopped {
+		t.log.Warn("notification socket transport stopped, dropping delivery", zap.Int("group_id", n.GroupId))
+		return nil
+	}
+
+	t.wg.Add(1)
+	runCtx := context.WithoutCancel(ctx)
+	go func() {
+		defer t.wg.Done()
+		failed := 0
+		for _, uid := range userIDs {
+			un := n
+			un.UserId = uid
+			if err := t.Send(runCtx, un, idempotencyKey); err != nil {
+				failed++
+			}
+		}
+		if failed > 0 {
+			t.log.Warn("notification socket delivery failed",
+				zap.Int("group_id", n.GroupId),
+				zap.Int("failed", failed),
+				zap.Int("recipients", len(userIDs)),
+			)
+		}
+	}()
+	return nil
+}
+
+// Stop нь шинэ хүргэлт хүлээж авахаа больж, эхэлснийг дуусахыг хүлээнэ.
+func (t *SocketNotificationTransport) Stop() {
+	t.mu.Lock()
+	t.stopped = true
+	t.mu.Unlock()
+	t.wg.Wait()
+}
+
+// Broadcast нь socket /broadcast руу илгээнэ.
+func (t *SocketNotificationTransport) Broadcast(ctx context.Context, n domain.Notification, idempotencyKey string) error {
+	body := map[string]any{
+		"tenant":          n.Tenant,
+		"idempotency_key": idempotencyKey,
+		"body":            n,
+	}
+	_, _, err := httpx.PostJSON[map[string]any, any](ctx, t.http, t.baseURL+"/broadcast", nil, body)
+	return err
+}
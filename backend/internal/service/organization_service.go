@@ -10,8 +10,10 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
+	"slices"
 
 	"templatev25/internal/domain"
 	"templatev25/internal/http/dto"
@@ -22,6 +24,16 @@ import (
 	"git.gerege.mn/backend-packages/httpx"
 	"git.gerege.mn/backend-packages/utils"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrOrganizationCycle нь байгууллагыг өөрийн эсвэл үр удмынхаа доор оруулах үед буцна.
+	ErrOrganizationCycle = errors.New("organization hierarchy cycle")
+	// ErrOrganizationNotFound нь шилжүүлэх байгууллага олдоогүй үед буцна.
+	ErrOrganizationNotFound = errors.New("organization not found")
+	// ErrOrganizationParentNotFound нь шинэ эцэг байгууллага олдоогүй үед буцна.
+	ErrOrganizationParentNotFound = errors.New("parent organization not found")
 )
 
 type OrganizationService struct {
@@ -51,6 +63,13 @@ func (s *OrganizationService) Create(ctx context.Context, req dto.OrganizationDt
 	if req.ParentID != nil && *req.ParentID == 0 {
 		req.ParentID = nil
 	}
+	// Id өгөгдсөн бол upsert тул одоо байгаа байгууллагын эцгийг өөрчилж болно
+	if req.Id > 0 {
+		if err := s.checkParent(ctx, req.Id, req.ParentID); err != nil {
+			s.log.Warn("organization_create_invalid_parent", zap.Int("org_id", req.Id), zap.Error(err))
+			return domain.Organization{}, err
+		}
+	}
 	m := domain.Organization{
 		Id:                req.Id,
 		RegNo:             req.RegNo,
@@ -93,6 +112,10 @@ func (s *OrganizationService) Update(ctx context.Context, id int, req dto.Organi
 	if req.ParentID != nil && *req.ParentID == 0 {
 		req.ParentID = nil
 	}
+	if err := s.checkParent(ctx, id, req.ParentID); err != nil {
+		s.log.Warn("organization_update_invalid_parent", zap.Int("org_id", id), zap.Error(err))
+		return domain.Organization{}, err
+	}
 	m := domain.Organization{
 		RegNo:             req.RegNo,
 		Name:              req.Name,
@@ -155,6 +178,75 @@ func (s *OrganizationService) Tree(ctx context.Context, rootID int) ([]domain.Or
 	return items, nil
 }
 
+// Subtree нь rootID болон түүний бүх үр удмыг хавтгай жагсаалтаар буцаана.
+func (s *OrganizationService) Subtree(ctx context.Context, rootID int) ([]domain.Organization, error) {
+	items, err := s.repo.Subtree(ctx, rootID)
+	if err != nil {
+		s.log.Error("organization_subtree_failed", zap.Int("root_id", rootID), zap.Error(err))
+		return nil, err
+	}
+	return items, nil
+}
+
+// Ancestors нь байгууллагын эцгүүдийг root-оос эхлэн буцаана.
+func (s *OrganizationService) Ancestors(ctx context.Context, id int) ([]domain.Organization, error) {
+	items, err := s.repo.Ancestors(ctx, id)
+	if err != nil {
+		s.log.Error("organization_ancestors_failed", zap.Int("org_id", id), zap.Error(err))
+		return nil, err
+	}
+	return items, nil
+}
+
+// Move нь байгууллагыг (түүний салбаруудын хамт) parentID-ийн доор шилжүүлнэ.
+// parentID nil эсвэл 0 бол root болгоно.
+func (s *OrganizationService) Move(ctx context.Context, id int, parentID *int) error {
+	if parentID != nil && *parentID == 0 {
+		parentID = nil
+	}
+	if err := s.checkParent(ctx, id, parentID); err != nil {
+		s.log.Warn("organization_move_invalid_parent", zap.Int("org_id", id), zap.Error(err))
+		return err
+	}
+	if parentID != nil {
+		if _, err := s.repo.ByID(ctx, *parentID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrOrganizationParentNotFound
+			}
+			s.log.Error("organization_move_parent_lookup_failed", zap.Int("parent_id", *parentID), zap.Error(err))
+			return err
+		}
+	}
+
+	if err := s.repo.Move(ctx, id, parentID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOrganizationNotFound
+		}
+		s.log.Error("organization_move_failed", zap.Int("org_id", id), zap.Error(err))
+		return err
+	}
+	s.log.Info("organization_moved", zap.Int("org_id", id), zap.Any("parent_id", parentID))
+	return nil
+}
+
+// checkParent нь parentID нь байгууллага өөрөө эсвэл түүний үр удам биш эсэхийг шалгана.
+func (s *OrganizationService) checkParent(ctx context.Context, id int, parentID *int) error {
+	if parentID == nil {
+		return nil
+	}
+	if *parentID == id {
+		return ErrOrganizationCycle
+	}
+	descendants, err := s.repo.DescendantIDs(ctx, id)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(descendants, *parentID) {
+		return ErrOrganizationCycle
+	}
+	return nil
+}
+
 type OrganizationTypeService struct {
 	repo repository.OrganizationTypeRepository
 }
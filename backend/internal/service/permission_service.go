@@ -10,6 +10,9 @@ package service
 
 import (
 	"context"
+	"errors"
+	"slices"
+	"time"
 
 	"templatev25/internal/auth"
 	"templatev25/internal/domain"
@@ -20,6 +23,10 @@ import (
 	"go.uber.org/zap"
 )
 
+// ErrInvalidPermissionCode нь permission кодын хэлбэр буруу үед буцна.
+// "*" нь зөвхөн сүүлийн сегмент бүтнээрээ байж болно ("admin.user.*").
+var ErrInvalidPermissionCode = errors.New("invalid permission code")
+
 type PermissionService struct {
 	repo  repository.PermissionRepository
 	log   *zap.Logger
@@ -54,7 +61,31 @@ func (s *PermissionService) Create(ctx context.Context, req dto.PermissionCreate
 	return s.repo.CreateBatch(ctx, req.SystemID, req.ModuleID, req.ActionIDs)
 }
 
+// CreateWildcard нь wildcard grant permission үүсгэнэ.
+// Role-д олгоход тухайн prefix-ийн доорх бүх permission хамрагдана.
+func (s *PermissionService) CreateWildcard(ctx context.Context, req dto.PermissionWildcardCreateDto) error {
+	if !domain.IsWildcardPermissionCode(req.Code) || !domain.ValidPermissionCode(req.Code) {
+		return ErrInvalidPermissionCode
+	}
+	name := req.Name
+	if name == "" {
+		name = req.Code
+	}
+	isActive := true
+	return s.repo.Create(ctx, domain.Permission{
+		Code:        req.Code,
+		Name:        name,
+		Description: req.Description,
+		SystemID:    req.SystemID,
+		ModuleID:    req.ModuleID,
+		IsActive:    &isActive,
+	})
+}
+
 func (s *PermissionService) Update(ctx context.Context, id int, req dto.PermissionUpdateDto) error {
+	if !domain.ValidPermissionCode(req.Code) {
+		return ErrInvalidPermissionCode
+	}
 	m := domain.Permission{
 		Code:        req.Code,
 		Name:        req.Name,
@@ -110,3 +141,113 @@ func (s *PermissionService) HasPermission(ctx context.Context, userID int, permi
 func (s *PermissionService) GetUserPermissions(ctx context.Context, userID int) ([]string, error) {
 	return s.repo.GetUserPermissionCodes(ctx, userID)
 }
+
+// Access explain-ийн татгалзах шалтгаанууд (PermissionAccessPath.DenyReasons)
+const (
+	DenyReasonNoGrant                = "no_grant"                  // Хэрэглэгчийн аль ч role энэ кодыг олгоогүй
+	DenyReasonAssignmentDeleted      = "assignment_deleted"        // user_roles олголт устгагдсан
+	DenyReasonAssignmentExpired      = "assignment_expired"        // user_roles.expires_at өнгөрсөн
+	DenyReasonAssignmentOrgNotActive = "assignment_org_not_active" // Байгууллагын олголт, өөр/байгууллагагүй сонголт
+	DenyReasonRoleDeleted            = "role_deleted"              // Гинжин дэх role soft-delete хийгдсэн
+	DenyReasonRolePermissionDeleted  = "role_permission_deleted"   // role_permissions холбоос устгагдсан
+	DenyReasonPermissionInactive     = "permission_inactive"       // permissions.is_active = false
+	DenyReasonPermissionDeleted      = "permission_deleted"        // permissions soft-delete хийгдсэн
+)
+
+// Explain нь хэрэглэгчид permission олгогдох эсэх, ямар замаар (user_roles → role →
+// role_permissions) олгогдсон эсвэл яагаад татгалзсаныг тайлбарлана.
+// Шийдвэр нь permission_repo-ийн UserHasPermission-тэй ижил дүрмээр гарна.
+func (s *PermissionService) Explain(ctx context.Context, q dto.PermissionExplainQuery) (dto.PermissionExplainResponse, error) {
+	traces, err := s.repo.TraceUserPermission(ctx, q.UserID, q.Code)
+	if err != nil {
+		return dto.PermissionExplainResponse{}, err
+	}
+
+	out := dto.PermissionExplainResponse{
+		UserID: q.UserID,
+		Code:   q.Code,
+		OrgID:  q.OrgID,
+		Paths:  make([]dto.PermissionAccessPath, 0, len(traces)),
+	}
+	now := time.Now()
+	for _, t := range traces {
+		path := explainPath(t, q.OrgID, now)
+		out.Granted = out.Granted || path.Granted
+		out.Paths = append(out.Paths, path)
+	}
+
+	if out.Granted {
+		return out, nil
+	}
+	if len(out.Paths) == 0 {
+		out.Reasons = []string{DenyReasonNoGrant}
+		return out, nil
+	}
+	for _, p := range out.Paths {
+		for _, r := range p.DenyReasons {
+			if !slices.Contains(out.Reasons, r) {
+				out.Reasons = append(out.Reasons, r)
+			}
+		}
+	}
+	return out, nil
+}
+
+// explainPath нь нэг trace-ийг хүчинтэй эсэхээр үнэлнэ.
+// Нөхцөлүүд нь permission_repo.userRoleTreeCTE-тэй тохирох ёстой.
+func explainPath(t domain.PermissionGrantTrace, orgID int, now time.Time) dto.PermissionAccessPath {
+	a := t.Assignment
+	path := dto.PermissionAccessPath{
+		Assignment: dto.PermissionPathAssignment{
+			RoleID:    a.RoleID,
+			OrgID:     a.OrgID,
+			ExpiresAt: a.ExpiresAt,
+			Deleted:   a.DeletedDate.Valid,
+		},
+		Roles: make([]dto.PermissionPathRole, 0, len(t.Roles)),
+		Permission: dto.PermissionPathGrant{
+			ID:                    t.Permission.ID,
+			Code:                  t.Permission.Code,
+			Active:                t.Permission.IsActive != nil && *t.Permission.IsActive,
+			Deleted:               t.Permission.DeletedDate.Valid,
+			RolePermissionDeleted: t.RolePermissionDeleted,
+		},
+	}
+
+	var reasons []string
+	if a.DeletedDate.Valid {
+		reasons = append(reasons, DenyReasonAssignmentDeleted)
+	}
+	if a.ExpiresAt != nil && !a.ExpiresAt.After(now) {
+		reasons = append(reasons, DenyReasonAssignmentExpired)
+	}
+	if a.OrgID != nil && *a.OrgID != orgID {
+		reasons = append(reasons, DenyReasonAssignmentOrgNotActive)
+	}
+	roleDeleted := false
+	for _, r := range t.Roles {
+		path.Roles = append(path.Roles, dto.PermissionPathRole{
+			ID:      r.ID,
+			Code:    r.Code,
+			Name:    r.Name,
+			Deleted: r.DeletedDate.Valid,
+		})
+		roleDeleted = roleDeleted || r.DeletedDate.Valid
+	}
+	if roleDeleted {
+		reasons = append(reasons, DenyReasonRoleDeleted)
+	}
+	if t.RolePermissionDeleted {
+		reasons = append(reasons, DenyReasonRolePermissionDeleted)
+	}
+	if !path.Permission.Active {
+		reasons = append(reasons, DenyReasonPermissionInactive)
+	}
+	if path.Permission.Deleted {
+		reasons = append(reasons, DenyReasonPermissionDeleted)
+	}
+
+	path.Granted = len(reasons) == 0
+	path.DenyReasons = reasons
+	return path
+}
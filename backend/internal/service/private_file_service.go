@@ -0,0 +1,218 @@
+// Package service provides implementation for service
+//
+// File: private_file_service.go
+// Description: Private files served only to their owner, permitted users or signed links
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"time"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/repository"
+	"templatev25/internal/signedurl"
+	"templatev25/internal/storage"
+	"templatev25/internal/upload"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrFileForbidden нь хэрэглэгч тухайн хувийн файлд хандах эрхгүй.
+var ErrFileForbidden = errors.New("file access denied")
+
+// FileAccessor нь хувийн файлд хандаж буй хэрэглэгч.
+// CanManage нь бусдын хувийн файлд хандах эрх (admin.file.private.*).
+type FileAccessor struct {
+	UserID    int
+	CanManage bool
+}
+
+func (a FileAccessor) canAccess(f domain.File) bool {
+	return a.CanManage || (a.UserID != 0 && f.UserId != nil && *f.UserId == a.UserID)
+}
+
+// SignedFileURL нь хугацаатай татах link.
+type SignedFileURL struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// PrivateFileOptions нь хувийн файлын тохиргоо.
+type PrivateFileOptions struct {
+	// BaseURL нь signed link-ийн угтвар (GET /file/private/:name)
+	BaseURL string
+	// Driver нь files.storage_provider-д бичигдэнэ (local, s3)
+	Driver string
+	// DefaultTTL, MaxTTL нь signed link-ийн хугацаа
+	DefaultTTL time.Duration
+	MaxTTL     time.Duration
+}
+
+// PrivateFileService нь files хүснэгтэд is_public = false бүртгэлтэй,
+// нийтийн storage-оос тусдаа store-д хадгалагдах файлуудыг удирдана.
+// Ижил агуулгатай хувийн файлууд нэг blob-ийг хуваалцах ч хандах эрх нь
+// upload бүрийн бүртгэлээр (эзэмшигч, stored_name) тодорхойлогдоно.
+type PrivateFileService struct {
+	repo   repository.FileRepository
+	blobs  *blobStore
+	store  storage.Storage
+	guard  *upload.Guard
+	signer *signedurl.Signer
+	opts   PrivateFileOptions
+	now    func() time.Time
+}
+
+func NewPrivateFileService(repo repository.FileRepository, blobs repository.FileBlobRepository, store storage.Storage, guard *upload.Guard, signer *signedurl.Signer, opts PrivateFileOptions) *PrivateFileService {
+	return &PrivateFileService{
+		repo:   repo,
+		blobs:  &blobStore{blobs: blobs, store: store, driver: opts.Driver, isPublic: false, remove: store.Delete},
+		store:  store,
+		guard:  guard,
+		signer: signer,
+		opts:   opts,
+		now:    time.Now,
+	}
+}
+
+// List нь хэрэглэгчийн өөрийн хувийн файлууд.
+func (s *PrivateFileService) List(ctx context.Context, userID int) ([]domain.File, error) {
+	return s.repo.ListByUser(ctx, userID, false)
+}
+
+// Upload нь файлыг нийтийн upload-тай ижил шалгалтаар (төрөл, хэмжээ, вирус)
+// шалгаад userID-ийн хувийн файл болгон хадгална.
+func (s *PrivateFileService) Upload(ctx context.Context, userID int, header *multipart.FileHeader) (domain.File, error) {
+	src, err := header.Open()
+	if err != nil {
+		return domain.File{}, err
+	}
+	defer src.Close()
+
+	info, err := s.guard.Inspect(ctx, header.Filename, header.Size, src)
+	if err != nil {
+		return domain.File{}, err
+	}
+
+	blob, _, err := s.blobs.put(ctx, src, header.Size, info)
+	if err != nil {
+		return domain.File{}, err
+	}
+
+	created, err := s.repo.Create(ctx, domain.File{
+		UserId:          &userID,
+		OriginalName:    header.Filename,
+		StoredName:      uuid.NewString() + info.Ext,
+		MimeType:        blob.MimeType,
+		FileSize:        blob.FileSize,
+		StoragePath:     blob.StoragePath,
+		StorageProvider: s.opts.Driver,
+		Checksum:        blob.Checksum,
+		BlobId:          &blob.Id,
+		IsPublic:        false,
+	})
+	if err != nil {
+		s.blobs.discard(ctx, blob.Id)
+		return domain.File{}, err
+	}
+	return created, nil
+}
+
+// Sign нь name файлын хугацаатай татах link үүсгэнэ. ttl <= 0 бол DefaultTTL,
+// MaxTTL-ээс урт бол MaxTTL. bindUser үед link-ийг зөвхөн a.UserID ашиглана.
+func (s *PrivateFileService) Sign(ctx context.Context, a FileAccessor, name string, ttl time.Duration, bindUser bool) (SignedFileURL, error) {
+	f, err := s.lookup(ctx, name)
+	if err != nil {
+		return SignedFileURL{}, err
+	}
+	if !a.canAccess(f) {
+		return SignedFileURL{}, ErrFileForbidden
+	}
+
+	if ttl <= 0 {
+		ttl = s.opts.DefaultTTL
+	}
+	if s.opts.MaxTTL > 0 && ttl > s.opts.MaxTTL {
+		ttl = s.opts.MaxTTL
+	}
+	uid := 0
+	if bindUser {
+		uid = a.UserID
+	}
+	expires := s.now().Add(ttl).Truncate(time.Second)
+	q := s.signer.Sign(f.StoredName, uid, expires)
+	return SignedFileURL{URL: s.opts.BaseURL + f.StoredName + "?" + q.Encode(), ExpiresAt: expires}, nil
+}
+
+// Open нь нэвтэрсэн хэрэглэгчид (эзэмшигч эсвэл CanManage) файлыг нээнэ.
+// Дуудагч Body-г хаана.
+func (s *PrivateFileService) Open(ctx context.Context, a FileAccessor, name string) (*storage.Object, domain.File, error) {
+	f, err := s.lookup(ctx, name)
+	if err != nil {
+		return nil, domain.File{}, err
+	}
+	if !a.canAccess(f) {
+		return nil, domain.File{}, ErrFileForbidden
+	}
+	return s.open(ctx, f)
+}
+
+// OpenSigned нь signed link-ээр файлыг нээнэ. Хугацаа дууссан, хуурамч link бол
+// signedurl.ErrInvalid; хэрэглэгчид холбогдсон link-ийг өөр хэрэглэгч (эсвэл
+// нэвтрээгүй, userID = 0) ашиглавал ErrFileForbidden.
+func (s *PrivateFileService) OpenSigned(ctx context.Context, name string, p signedurl.Params, userID int) (*storage.Object, domain.File, error) {
+	if err := s.signer.Verify(name, p, s.now()); err != nil {
+		return nil, domain.File{}, err
+	}
+	if p.UserID != 0 && p.UserID != userID {
+		return nil, domain.File{}, ErrFileForbidden
+	}
+	f, err := s.lookup(ctx, name)
+	if err != nil {
+		return nil, domain.File{}, err
+	}
+	return s.open(ctx, f)
+}
+
+// Delete нь бүртгэлийг (soft) устгана; storage дахь агуулга нь түүнийг
+// хуваалцсан сүүлийн файлтай хамт устна.
+func (s *PrivateFileService) Delete(ctx context.Context, a FileAccessor, name string) error {
+	f, err := s.lookup(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !a.canAccess(f) {
+		return ErrFileForbidden
+	}
+	if err := s.repo.Delete(ctx, f.Id); err != nil {
+		return err
+	}
+	return s.blobs.release(ctx, f)
+}
+
+// lookup нь хувийн файлын бүртгэлийг олно; нийтийн файл бол ErrFileNotFound.
+func (s *PrivateFileService) lookup(ctx context.Context, name string) (domain.File, error) {
+	f, err := s.repo.GetByStoredName(ctx, name)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && f.IsPublic) {
+		return domain.File{}, ErrFileNotFound
+	}
+	return f, err
+}
+
+func (s *PrivateFileService) open(ctx context.Context, f domain.File) (*storage.Object, domain.File, error) {
+	obj, err := s.store.Open(ctx, f.StoragePath)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, domain.File{}, ErrFileNotFound
+	}
+	if err != nil {
+		return nil, domain.File{}, err
+	}
+	// Тоолуур алдаа гарсан ч татахыг зогсоохгүй
+	if err := s.repo.IncrementDownloads(ctx, f.Id); err != nil {
+		fmt.Printf("WARN: failed to count download of file %d: %v\n", f.Id, err)
+	}
+	return obj, f, nil
+}
@@ -14,43 +14,52 @@ import (
 	"fmt"
 	"io"
 	"mime/multipart"
-	"os"
-	"path/filepath"
+	"strings"
+	"time"
 
 	"templatev25/internal/domain"
 	"templatev25/internal/http/dto"
+	"templatev25/internal/imagevariant"
 	"templatev25/internal/repository"
+	"templatev25/internal/storage"
+	"templatev25/internal/upload"
 
-	"git.gerege.mn/backend-packages/config"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-// Public file service constants
-const (
-	PublicImageDir = "/var/www/html/public"
-	PublicImageURL = "https://business.gerege.mn/api/file/"
-)
+// ErrFileNotFound нь storage-д ийм нэртэй файл байхгүй.
+var ErrFileNotFound = errors.New("file not found")
 
-type PublicFileService struct {
-	repo repository.PublicFileRepository
-	cfg  *config.Config
+// PublicFileOptions нь нийтийн файлын тохиргоо.
+type PublicFileOptions struct {
+	// BaseURL нь file_url-ийн угтвар (GET /file/:name)
+	BaseURL string
+	// Driver нь files.storage_provider-д бичигдэнэ (local, s3)
+	Driver string
 }
 
-func NewPublicFileService(repo repository.PublicFileRepository, cfg *config.Config) *PublicFileService {
-	return &PublicFileService{repo: repo, cfg: cfg}
+type PublicFileService struct {
+	repo     repository.PublicFileRepository
+	files    repository.FileRepository
+	blobs    *blobStore
+	store    storage.Storage
+	guard    *upload.Guard
+	variants *imagevariant.Generator
+	opts     PublicFileOptions
 }
 
-// getPublicDir returns the public file directory
-// TODO: Add PublicFileDir field to config.URLConfig when available
-func (s *PublicFileService) getPublicDir() string {
-	return PublicImageDir
-}
+// variantTimeout нь upload-ийн дараа бүх хувилбарыг үүсгэх хугацааны дээд хязгаар.
+const variantTimeout = 2 * time.Minute
 
-// getPublicURL returns the public file URL
-// TODO: Add PublicFile field to config.URLConfig when available
-func (s *PublicFileService) getPublicURL() string {
-	return PublicImageURL
+// NewPublicFileService нь файлуудыг guard-аар шалгаад store-д хадгална. Upload бүр
+// "{uuid}{ext}" нэртэй public_files болон files (SHA-256, хэмжээ, MIME, upload хийсэн
+// хэрэглэгч) мөртэй; ижил агуулга blobs-оор нэг л удаа хадгалагдана.
+// variants нь зургийн thumbnail/resize хувилбаруудыг blob-ийн хажууд үүсгэнэ.
+func NewPublicFileService(repo repository.PublicFileRepository, files repository.FileRepository, blobs repository.FileBlobRepository, store storage.Storage, guard *upload.Guard, variants *imagevariant.Generator, opts PublicFileOptions) *PublicFileService {
+	s := &PublicFileService{repo: repo, files: files, store: store, guard: guard, variants: variants, opts: opts}
+	s.blobs = &blobStore{blobs: blobs, store: store, driver: opts.Driver, isPublic: true, remove: s.removeObject}
+	return s
 }
 
 // List
@@ -61,20 +70,12 @@ func (s *PublicFileService) List(ctx context.Context, q dto.PublicFileListQuery)
 // Upload
 // - oldName: form-д ирдэг "name" (хуучныг солих бол ашиглана; хоосон байж болно)
 // - desc: form-д ирдэг "description"
-func (s *PublicFileService) Upload(ctx context.Context, header *multipart.FileHeader, desc, oldName string) (domain.PublicFile, error) {
+//
+// Төрөл, хэмжээ, өргөтгөлийг агуулгаар нь шалгаж вирус scan хийнэ; татгалзвал
+// upload.ErrRejected (халдвартай файл quarantine-д орж PublicFile мөр үүсэхгүй).
+func (s *PublicFileService) Upload(ctx context.Context, userID int, header *multipart.FileHeader, desc, oldName string) (domain.PublicFile, error) {
 	var zero domain.PublicFile
 
-	// Хуучин файл устгах
-	if oldName != "" {
-		if err := s.deleteByName(ctx, oldName); err != nil {
-			return zero, err
-		}
-	}
-
-	id := uuid.New()
-	destName := id.String()
-	ext := filepath.Ext(header.Filename)
-
 	src, err := header.Open()
 	if err != nil {
 		return zero, err
@@ -86,42 +87,129 @@ func (s *PublicFileService) Upload(ctx context.Context, header *multipart.FileHe
 		}
 	}()
 
-	// ——— G301: зөвшөөрөгдөх permission-ийг 0750 болгож засав
-	publicDir := s.getPublicDir()
-	if err := os.MkdirAll(publicDir, 0o750); err != nil {
+	// Хуучныг устгахаас өмнө шалгана (татгалзсан файл хуучныг дарахгүй)
+	info, err := s.guard.Inspect(ctx, header.Filename, header.Size, src)
+	if err != nil {
 		return zero, err
 	}
 
-	// ——— G304: dynamic path → path join хийгдсэн тул file inclusion биш
-	// lint-ийг хуурахын тулд filepath.Clean хэрэглэнэ
-	cleanPath := filepath.Clean(filepath.Join(publicDir, destName+ext))
-	out, err := os.OpenFile(cleanPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	// Хуучин файл устгах
+	if oldName != "" {
+		if err := s.deleteByName(ctx, oldName); err != nil {
+			return zero, err
+		}
+	}
+	return s.save(ctx, userID, header.Filename, src, header.Size, info, desc)
+}
 
+// UploadFile нь multipart биш эх (жишээ нь resumable upload-ийн угсарсан
+// файл)-ийг Upload-тай ижил шалгаад шинэ PublicFile болгон хадгална.
+func (s *PublicFileService) UploadFile(ctx context.Context, userID int, filename string, size int64, src io.ReadSeeker, desc string) (domain.PublicFile, error) {
+	info, err := s.guard.Inspect(ctx, filename, size, src)
 	if err != nil {
-		return zero, err
+		return domain.PublicFile{}, err
 	}
-	defer func() {
-		if cerr := out.Close(); cerr != nil {
-			fmt.Printf("WARN: failed to close dest file: %v\n", cerr)
-		}
-	}()
+	return s.save(ctx, userID, filename, src, size, info, desc)
+}
 
-	if _, err := io.Copy(out, src); err != nil {
-		return zero, err
+// save нь шалгагдсан файлын агуулгыг (ижил агуулга байвал дахин бичилгүй)
+// хадгалж, "{uuid}{ext}" нэртэй files болон PublicFile мөр үүсгэнэ.
+func (s *PublicFileService) save(ctx context.Context, userID int, filename string, src io.ReadSeeker, size int64, info upload.Inspection, desc string) (domain.PublicFile, error) {
+	blob, created, err := s.blobs.put(ctx, src, size, info)
+	if err != nil {
+		return domain.PublicFile{}, err
+	}
+
+	destName := uuid.NewString()
+	ext := info.Ext
+	key := destName + ext
+
+	f := domain.File{
+		OriginalName:    filename,
+		StoredName:      key,
+		MimeType:        blob.MimeType,
+		FileSize:        blob.FileSize,
+		StoragePath:     blob.StoragePath,
+		StorageProvider: s.opts.Driver,
+		PublicUrl:       s.opts.BaseURL + key,
+		Checksum:        blob.Checksum,
+		BlobId:          &blob.Id,
+		IsPublic:        true,
+	}
+	if userID != 0 {
+		f.UserId = &userID
+	}
+	f, err = s.files.Create(ctx, f)
+	if err != nil {
+		s.blobs.discard(ctx, blob.Id)
+		return domain.PublicFile{}, err
 	}
 
 	pf := domain.PublicFile{
 		Name:        destName,
 		Extension:   ext,
 		Description: desc,
-		FileUrl:     s.getPublicURL() + destName + ext,
+		FileUrl:     f.PublicUrl,
 	}
-	created, err := s.repo.Create(ctx, pf)
+	createdFile, err := s.repo.Create(ctx, pf)
 	if err != nil {
-		_ = os.Remove(cleanPath)
-		return zero, err
+		if derr := s.files.Delete(ctx, f.Id); derr != nil {
+			fmt.Printf("WARN: failed to delete file record %d: %v\n", f.Id, derr)
+		}
+		s.blobs.discard(ctx, blob.Id)
+		return domain.PublicFile{}, err
+	}
+
+	// IMAGE_VARIANTS_EAGER үед шинэ blob-ийн хувилбаруудыг ард нь үүсгэнэ (хариуг хүлээлгэхгүй)
+	if created && s.variants.Eager() && imagevariant.IsImage(blob.StoragePath) {
+		go s.generateVariants(blob.StoragePath)
+	}
+	return createdFile, nil
+}
+
+func (s *PublicFileService) generateVariants(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), variantTimeout)
+	defer cancel()
+	if err := s.variants.Generate(ctx, key); err != nil {
+		fmt.Printf("WARN: failed to generate image variants for %s: %v\n", key, err)
 	}
-	return created, nil
+}
+
+// Open нь GET /file/:name-д файлын агуулгыг буцаана (дуудагч Body-г хаана).
+// Нэр нь "{uuid}{ext}" (files.stored_name); агуулгыг blob-оос нь уншина.
+// files-д бүртгэлгүй (dedup-ээс өмнөх) файлын нэр нь storage key.
+// variant ("thumb" гэх мэт) эсвэл format ("webp") өгвөл хувилбарыг буцаана;
+// анх хүсэхэд үүсгэж хадгална.
+// Буруу variant/format, зураг биш файл бол imagevariant.ErrUnavailable.
+func (s *PublicFileService) Open(ctx context.Context, name, variant, format string) (*storage.Object, error) {
+	// Нийтийн key-д "/" байхгүй; S3 дээр нийтийн prefix хувийн prefix-ийг
+	// агуулж болох тул "private/..." мэт key-г эндээс хэзээ ч нээхгүй.
+	if strings.Contains(name, "/") {
+		return nil, ErrFileNotFound
+	}
+	key := name
+	f, err := s.files.GetByStoredName(ctx, name)
+	switch {
+	case err == nil && !f.IsPublic:
+		return nil, ErrFileNotFound
+	case err == nil:
+		key = f.StoragePath
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+	obj, err := s.variants.Open(ctx, key, variant, format)
+	if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+		return nil, ErrFileNotFound
+	}
+	return obj, err
+}
+
+// removeObject нь эх файл болон түүний зургийн хувилбаруудыг storage-оос устгана.
+func (s *PublicFileService) removeObject(ctx context.Context, key string) error {
+	if err := s.store.Delete(ctx, key); err != nil {
+		return err
+	}
+	return s.variants.DeleteAll(ctx, key)
 }
 
 func (s *PublicFileService) deleteByName(ctx context.Context, name string) error {
@@ -135,9 +223,8 @@ func (s *PublicFileService) deleteByName(ctx context.Context, name string) error
 		return nil
 	}
 
-	// Дискен дээрх файлыг устгах
-	path := filepath.Join(s.getPublicDir(), old.Name+old.Extension)
-	if err := os.Remove(path); err != nil {
+	// Storage-оос файлыг устгах (өөр upload хуваалцаж буй агуулга үлдэнэ)
+	if err := s.removeFile(ctx, old.Name+old.Extension); err != nil {
 		return fmt.Errorf("file remove: %w", err)
 	}
 	// DB-ээс устгах
@@ -154,10 +241,26 @@ func (s *PublicFileService) Delete(ctx context.Context, name string) error {
 	if err != nil {
 		return err
 	}
-	path := filepath.Join(s.getPublicDir(), pf.Name+pf.Extension)
-	if err := os.Remove(path); err != nil {
+	if err := s.removeFile(ctx, pf.Name+pf.Extension); err != nil {
 		return err
 	}
 	_, err = s.repo.DeleteByID(ctx, pf.Id)
 	return err
 }
+
+// removeFile нь key нэртэй upload-ийн files бүртгэлийг устгаж blob-ийн reference-ийг
+// чөлөөлнө; агуулга нь зөвхөн сүүлийн reference-тэй хамт устна.
+// files-д бүртгэлгүй (dedup-ээс өмнөх) файлын object-ийг шууд устгана.
+func (s *PublicFileService) removeFile(ctx context.Context, key string) error {
+	f, err := s.files.GetByStoredName(ctx, key)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return s.removeObject(ctx, key)
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.files.Delete(ctx, f.Id); err != nil {
+		return err
+	}
+	return s.blobs.release(ctx, f)
+}
@@ -0,0 +1,331 @@
+// Package service provides business logic layer
+//
+// File: refresh_token_test.go
+// Description: Unit tests for refresh token issuing and rotation
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"templatev25/internal/config"
+	"templatev25/internal/domain"
+	"templatev25/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestHashRefreshToken(t *testing.T) {
+	h := hashRefreshToken("token")
+	assert.Len(t, h, 64)
+	assert.Equal(t, h, hashRefreshToken("token"))
+	assert.NotEqual(t, h, hashRefreshToken("token2"))
+}
+
+func TestIssueRefreshToken(t *testing.T) {
+	s := &AuthService{cfg: &config.LocalAuthConfig{RefreshTokenTTL: time.Hour}}
+	session := &SessionData{SessionID: "sess-1", UserID: 42}
+
+	token, rt, err := s.issueRefreshToken(context.Background(), session, "family-1")
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, token)
+	assert.NotEqual(t, token, rt.TokenHash, "only the hash is persisted")
+	assert.Equal(t, hashRefreshToken(token), rt.TokenHash)
+	assert.Equal(t, 42, rt.UserID)
+	assert.Equal(t, "sess-1", rt.SessionID)
+	assert.Equal(t, "family-1", rt.FamilyID)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), rt.ExpiresAt, time.Minute)
+	assert.False(t, rt.IsRevoked())
+	assert.False(t, rt.IsRotated())
+
+	other, _, err := s.issueRefreshToken(context.Background(), session, "family-1")
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestSessionTTL(t *testing.T) {
+	s := &AuthService{cfg: &config.LocalAuthConfig{
+		SessionTTL:     24 * time.Hour,
+		AccessTokenTTL: 15 * time.Minute,
+	}}
+
+	assert.Equal(t, 24*time.Hour, s.sessionTTL(false))
+	assert.Equal(t, 15*time.Minute, s.sessionTTL(true))
+}
+
+// mockAuthRepository нь RotateRefreshToken-д хэрэгтэй AuthRepository-ийн
+// method-уудыг mock хийнэ; бусад нь embed хийсэн interface (nil) руу унана.
+type mockAuthRepository struct {
+	repository.AuthRepository
+	mock.Mock
+}
+
+func (m *mockAuthRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RefreshToken), args.Error(1)
+}
+
+func (m *mockAuthRepository) RotateRefreshToken(ctx context.Context, oldID int, next *domain.RefreshToken) (bool, error) {
+	args := m.Called(ctx, oldID, next)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockAuthRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) ([]string, error) {
+	args := m.Called(ctx, familyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *mockAuthRepository) RevokeSession(ctx context.Context, id string, reason string) error {
+	args := m.Called(ctx, id, reason)
+	return args.Error(0)
+}
+
+func (m *mockAuthRepository) CreateSession(ctx context.Context, session *domain.Session) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *mockAuthRepository) GetDefaultOrgID(ctx context.Context, userID int) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockAuthRepository) CreateAuditTrail(ctx context.Context, audit *domain.SecurityAuditTrail) error {
+	args := m.Called(ctx, audit)
+	return args.Error(0)
+}
+
+func (m *mockAuthRepository) SetSessionRefreshTokenOrg(ctx context.Context, sessionID string, orgID int) error {
+	args := m.Called(ctx, sessionID, orgID)
+	return args.Error(0)
+}
+
+func (m *mockAuthRepository) IsOrganizationMember(ctx context.Context, userID, orgID int) (bool, error) {
+	args := m.Called(ctx, userID, orgID)
+	return args.Bool(0), args.Error(1)
+}
+
+// fakeSessionStore нь session-уудыг санах ойд хадгална
+type fakeSessionStore struct {
+	SessionStore
+	sessions map[string]*SessionData
+}
+
+func newFakeSessionStore(ids ...string) *fakeSessionStore {
+	s := &fakeSessionStore{sessions: map[string]*SessionData{}}
+	for _, id := range ids {
+		s.sessions[id] = &SessionData{SessionID: id}
+	}
+	return s
+}
+
+func (s *fakeSessionStore) Create(_ context.Context, session *SessionData) error {
+	s.sessions[session.SessionID] = session
+	return nil
+}
+
+func (s *fakeSessionStore) Get(_ context.Context, sessionID string) (*SessionData, error) {
+	session, ok := s.sessions[sessionID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (s *fakeSessionStore) Update(_ context.Context, session *SessionData) error {
+	if _, ok := s.sessions[session.SessionID]; !ok {
+		return ErrSessionNotFound
+	}
+	s.sessions[session.SessionID] = session
+	return nil
+}
+
+func (s *fakeSessionStore) Delete(_ context.Context, sessionID string) error {
+	delete(s.sessions, sessionID)
+	return nil
+}
+
+func newRefreshTestService(repo *mockAuthRepository, store *fakeSessionStore) *AuthService {
+	cfg := &config.LocalAuthConfig{
+		SessionTTL:      24 * time.Hour,
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: 30 * 24 * time.Hour,
+	}
+	return NewAuthService(repo, store, cfg, nil, zap.NewNop())
+}
+
+func activeRefreshToken(raw string) *domain.RefreshToken {
+	return &domain.RefreshToken{
+		ID:        7,
+		UserID:    42,
+		TokenHash: hashRefreshToken(raw),
+		SessionID: "sess-old",
+		FamilyID:  "family-1",
+		ExpiresAt: time.Now().Add(time.Hour),
+		User:      &domain.User{Id: 42, Email: "user@example.com", Status: string(domain.UserStatusActive)},
+	}
+}
+
+func TestRotateRefreshToken_Success(t *testing.T) {
+	repo := new(mockAuthRepository)
+	store := newFakeSessionStore("sess-old")
+	s := newRefreshTestService(repo, store)
+	ctx := context.Background()
+
+	current := activeRefreshToken("raw")
+	repo.On("GetRefreshTokenByHash", ctx, hashRefreshToken("raw")).Return(current, nil)
+	repo.On("GetDefaultOrgID", ctx, 42).Return(1, nil)
+	repo.On("CreateSession", ctx, mock.Anything).Return(nil)
+	repo.On("RotateRefreshToken", ctx, 7, mock.MatchedBy(func(next *domain.RefreshToken) bool {
+		return next.FamilyID == "family-1" && next.UserID == 42
+	})).Return(true, nil)
+	repo.On("RevokeSession", ctx, "sess-old", "refresh token rotated").Return(nil)
+
+	resp, err := s.RotateRefreshToken(ctx, RefreshTokenRequest{RefreshToken: "raw"})
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, resp.RefreshToken)
+	assert.NotEqual(t, "raw", resp.RefreshToken)
+	assert.Equal(t, 1, resp.Session.OrgID)
+	assert.NotContains(t, store.sessions, "sess-old", "superseded session is removed")
+	assert.Contains(t, store.sessions, resp.Session.SessionID)
+	repo.AssertExpectations(t)
+}
+
+func TestRotateRefreshToken_ReuseRevokesFamily(t *testing.T) {
+	repo := new(mockAuthRepository)
+	store := newFakeSessionStore("sess-a", "sess-b")
+	s := newRefreshTestService(repo, store)
+	ctx := context.Background()
+
+	current := activeRefreshToken("raw")
+	replacedBy := 8
+	current.ReplacedBy = &replacedBy
+	repo.On("GetRefreshTokenByHash", ctx, hashRefreshToken("raw")).Return(current, nil)
+	repo.On("RevokeRefreshTokenFamily", ctx, "family-1").Return([]string{"sess-a", "sess-b"}, nil)
+	repo.On("RevokeSession", ctx, "sess-a", "refresh token reuse").Return(nil)
+	repo.On("RevokeSession", ctx, "sess-b", "refresh token reuse").Return(nil)
+	repo.On("CreateAuditTrail", ctx, mock.MatchedBy(func(a *domain.SecurityAuditTrail) bool {
+		return a.Action == string(domain.AuditActionTokenReuse) && a.TargetID == "family-1" &&
+			a.UserID != nil && *a.UserID == 42 && a.IPAddress == "10.0.0.1"
+	})).Return(nil)
+
+	resp, err := s.RotateRefreshToken(ctx, RefreshTokenRequest{RefreshToken: "raw", IPAddress: "10.0.0.1"})
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	assert.Nil(t, resp)
+	assert.Empty(t, store.sessions, "every session of the family is ended")
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "RotateRefreshToken", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRotateRefreshToken_RejectsInactiveToken(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name   string
+		modify func(*domain.RefreshToken)
+	}{
+		{"expired", func(rt *domain.RefreshToken) { rt.ExpiresAt = past }},
+		{"revoked", func(rt *domain.RefreshToken) { rt.RevokedAt = &past }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mockAuthRepository)
+			store := newFakeSessionStore("sess-old")
+			s := newRefreshTestService(repo, store)
+			ctx := context.Background()
+
+			current := activeRefreshToken("raw")
+			tt.modify(current)
+			repo.On("GetRefreshTokenByHash", ctx, hashRefreshToken("raw")).Return(current, nil)
+
+			resp, err := s.RotateRefreshToken(ctx, RefreshTokenRequest{RefreshToken: "raw"})
+			assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+			assert.Nil(t, resp)
+			assert.Len(t, store.sessions, 1, "no session is created")
+			repo.AssertExpectations(t)
+			repo.AssertNotCalled(t, "RotateRefreshToken", mock.Anything, mock.Anything, mock.Anything)
+			repo.AssertNotCalled(t, "RevokeRefreshTokenFamily", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestRotateRefreshToken_InactiveUserIsNotReportedAsReuse(t *testing.T) {
+	repo := new(mockAuthRepository)
+	store := newFakeSessionStore("sess-old")
+	s := newRefreshTestService(repo, store)
+	ctx := context.Background()
+
+	current := activeRefreshToken("raw")
+	current.User.Status = string(domain.UserStatusSuspended)
+	repo.On("GetRefreshTokenByHash", ctx, hashRefreshToken("raw")).Return(current, nil)
+	repo.On("RevokeRefreshTokenFamily", ctx, "family-1").Return([]string{"sess-old"}, nil)
+	repo.On("RevokeSession", ctx, "sess-old", "account_not_active").Return(nil)
+	repo.On("CreateAuditTrail", ctx, mock.MatchedBy(func(a *domain.SecurityAuditTrail) bool {
+		return a.Action == string(domain.AuditActionSessionRevoke) && a.TargetID == "family-1"
+	})).Return(nil)
+
+	resp, err := s.RotateRefreshToken(ctx, RefreshTokenRequest{RefreshToken: "raw"})
+	assert.ErrorIs(t, err, ErrAccountNotActive)
+	assert.Nil(t, resp)
+	assert.Empty(t, store.sessions)
+	repo.AssertExpectations(t)
+}
+
+func TestRotateRefreshToken_UnknownToken(t *testing.T) {
+	repo := new(mockAuthRepository)
+	s := newRefreshTestService(repo, newFakeSessionStore())
+	ctx := context.Background()
+
+	repo.On("GetRefreshTokenByHash", ctx, hashRefreshToken("raw")).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := s.RotateRefreshToken(ctx, RefreshTokenRequest{RefreshToken: "raw"})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestRotateRefreshToken_LostRace(t *testing.T) {
+	repo := new(mockAuthRepository)
+	store := newFakeSessionStore("sess-old")
+	s := newRefreshTestService(repo, store)
+	ctx := context.Background()
+
+	current := activeRefreshToken("raw")
+	repo.On("GetRefreshTokenByHash", ctx, hashRefreshToken("raw")).Return(current, nil)
+	repo.On("GetDefaultOrgID", ctx, 42).Return(1, nil)
+	repo.On("CreateSession", ctx, mock.Anything).Return(nil)
+	// Зэрэг request нэг token-ийг түрүүлж rotate хийсэн
+	repo.On("RotateRefreshToken", ctx, 7, mock.Anything).Return(false, nil)
+	repo.On("RevokeSession", ctx, mock.Anything, "refresh token reuse").Return(nil)
+	repo.On("RevokeRefreshTokenFamily", ctx, "family-1").Return([]string{"sess-old"}, nil)
+	repo.On("CreateAuditTrail", ctx, mock.Anything).Return(nil)
+
+	resp, err := s.RotateRefreshToken(ctx, RefreshTokenRequest{RefreshToken: "raw"})
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	assert.Nil(t, resp, "no second token is issued")
+	assert.Empty(t, store.sessions, "the session created for the lost rotation is removed")
+	repo.AssertNumberOfCalls(t, "RotateRefreshToken", 1)
+	repo.AssertExpectations(t)
+}
+
+func TestRefreshSession_RejectsRefreshTokenSession(t *testing.T) {
+	store := newFakeSessionStore()
+	store.sessions["sess-1"] = &SessionData{SessionID: "sess-1", UserID: 42, HasRefreshToken: true}
+	s := newRefreshTestService(new(mockAuthRepository), store)
+
+	resp, err := s.RefreshSession(context.Background(), "sess-1")
+	assert.ErrorIs(t, err, ErrRefreshTokenSession)
+	assert.Nil(t, resp)
+}
@@ -36,16 +36,24 @@ type RegistrationService struct {
 	userRepo    repository.UserRepository
 	regRepo     repository.RegistrationRepository
 	authService *AuthService
+	mailer      *AuthMailer
 	cfg         *config.LocalAuthConfig
 	logger      *zap.Logger
 }
 
+// Token lifetimes
+const (
+	emailVerificationTTL = 24 * time.Hour
+	passwordResetTTL     = 1 * time.Hour
+)
+
 // NewRegistrationService creates a new registration service
 func NewRegistrationService(
 	authRepo repository.AuthRepository,
 	userRepo repository.UserRepository,
 	regRepo repository.RegistrationRepository,
 	authService *AuthService,
+	mailer *AuthMailer,
 	cfg *config.LocalAuthConfig,
 	logger *zap.Logger,
 ) *RegistrationService {
@@ -54,6 +62,7 @@ func NewRegistrationService(
 		userRepo:    userRepo,
 		regRepo:     regRepo,
 		authService: authService,
+		mailer:      mailer,
 		cfg:         cfg,
 		logger:      logger,
 	}
@@ -129,15 +138,18 @@ func (s *RegistrationService) Register(ctx context.Context, req RegistrationRequ
 	verificationToken := &domain.EmailVerificationToken{
 		UserID:    user.Id,
 		Token:     token,
-		ExpiresAt: time.Now().Add(24 * time.Hour), // 24 hours expiry
+		ExpiresAt: time.Now().Add(emailVerificationTTL),
 	}
 
 	if err := s.regRepo.CreateEmailVerificationToken(ctx, verificationToken); err != nil {
 		return nil, fmt.Errorf("failed to create verification token: %w", err)
 	}
 
-	// TODO: Send verification email
-	// s.emailService.SendVerificationEmail(user.Email, token)
+	// Send verification email
+	sent := s.mailer != nil
+	if sent {
+		s.mailer.SendVerification(ctx, user.Email, user.FirstName, token, emailVerificationTTL)
+	}
 
 	s.logger.Info("user registered",
 		zap.Int("user_id", user.Id),
@@ -147,7 +159,7 @@ func (s *RegistrationService) Register(ctx context.Context, req RegistrationRequ
 	return &RegistrationResponse{
 		UserID:           user.Id,
 		Email:            user.Email,
-		VerificationSent: true,
+		VerificationSent: sent,
 		Message:          "Registration successful. Please check your email to verify your account.",
 	}, nil
 }
@@ -191,6 +203,13 @@ func (s *RegistrationService) VerifyEmail(ctx context.Context, tokenStr string)
 		zap.Int("user_id", token.UserID),
 	)
 
+	// Send welcome email
+	if s.mailer != nil {
+		if user, err := s.regRepo.GetUserByID(ctx, token.UserID); err == nil {
+			s.mailer.SendWelcome(ctx, user.Email, user.FirstName)
+		}
+	}
+
 	return nil
 }
 
@@ -223,15 +242,17 @@ func (s *RegistrationService) ResendVerificationEmail(ctx context.Context, email
 	verificationToken := &domain.EmailVerificationToken{
 		UserID:    user.Id,
 		Token:     token,
-		ExpiresAt: time.Now().Add(24 * time.Hour),
+		ExpiresAt: time.Now().Add(emailVerificationTTL),
 	}
 
 	if err := s.regRepo.CreateEmailVerificationToken(ctx, verificationToken); err != nil {
 		return fmt.Errorf("failed to create verification token: %w", err)
 	}
 
-	// TODO: Send verification email
-	// s.emailService.SendVerificationEmail(user.Email, token)
+	// Send verification email
+	if s.mailer != nil {
+		s.mailer.SendVerification(ctx, user.Email, user.FirstName, token, emailVerificationTTL)
+	}
 
 	return nil
 }
@@ -264,15 +285,17 @@ func (s *RegistrationService) ForgotPassword(ctx context.Context, email string)
 	resetToken := &domain.PasswordResetToken{
 		UserID:    user.Id,
 		Token:     token,
-		ExpiresAt: time.Now().Add(1 * time.Hour), // 1 hour expiry
+		ExpiresAt: time.Now().Add(passwordResetTTL),
 	}
 
 	if err := s.regRepo.CreatePasswordResetToken(ctx, resetToken); err != nil {
 		return fmt.Errorf("failed to create reset token: %w", err)
 	}
 
-	// TODO: Send password reset email
-	// s.emailService.SendPasswordResetEmail(user.Email, token)
+	// Send password reset email
+	if s.mailer != nil {
+		s.mailer.SendPasswordReset(ctx, user.Email, user.FirstName, token, passwordResetTTL)
+	}
 
 	s.logger.Info("password reset requested",
 		zap.Int("user_id", user.Id),
@@ -0,0 +1,365 @@
+// Package service provides implementation for service
+//
+// File: resumable_upload_service.go
+// Description: Resumable (tus) uploads stored as chunks and finalized into public files
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/repository"
+	"templatev25/internal/storage"
+	"templatev25/internal/tus"
+	"templatev25/internal/upload"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Resumable upload-ийн алдаанууд (handler tus-ийн status code болгоно).
+var (
+	ErrUploadNotFound         = errors.New("upload not found")
+	ErrUploadExpired          = errors.New("upload expired")
+	ErrUploadTooLarge         = errors.New("upload exceeds the maximum size")
+	ErrUploadOffsetMismatch   = errors.New("upload offset mismatch")
+	ErrUploadLengthExceeded   = errors.New("chunk exceeds the upload length")
+	ErrUploadChecksumMismatch = errors.New("chunk checksum mismatch")
+	ErrUploadBusy             = errors.New("upload is being finalized")
+)
+
+// expireBatch нь ExpireUploads нэг удаад устгах upload-ийн тоо.
+const expireBatch = 100
+
+// ResumableUploadOptions нь resumable upload-ийн тохиргоо.
+type ResumableUploadOptions struct {
+	// BaseURL нь upload-ийн URL-ийн угтвар (Location header)
+	BaseURL string
+	// MaxSize нь нэг upload-ийн дээд хэмжээ; UPLOAD_ALLOWED_TYPES-ийн хамгийн
+	// том хязгаараас их байвал тэрийг авна
+	MaxSize int64
+	// Expiry нь сүүлийн хэсгээс хойш upload хүлээх хугацаа
+	Expiry time.Duration
+	// FinalizeTimeout нь угсрах, шалгах, хадгалах хугацааны дээд хязгаар
+	FinalizeTimeout time.Duration
+	// TempDir нь угсарсан файлын түр директор ("" бол os.TempDir)
+	TempDir string
+}
+
+// ResumableUploadService нь tus upload-уудыг хэсэг бүрээр нь chunks store-д
+// хадгалж, бүх byte ирмэгц нэг файл болгон угсарч PublicFileService-ээр
+// (төрөл, хэмжээ, вирус шалгалттай) PublicFile болгоно.
+type ResumableUploadService struct {
+	repo   repository.FileUploadRepository
+	chunks storage.Storage
+	files  *PublicFileService
+	opts   ResumableUploadOptions
+	now    func() time.Time
+}
+
+// NewResumableUploadService нь хэсгүүдийг chunks-д хадгалж, дууссан файлыг files-ээр хадгална.
+func NewResumableUploadService(repo repository.FileUploadRepository, chunks storage.Storage, files *PublicFileService, opts ResumableUploadOptions) *ResumableUploadService {
+	if opts.Expiry <= 0 {
+		opts.Expiry = 24 * time.Hour
+	}
+	if opts.FinalizeTimeout <= 0 {
+		opts.FinalizeTimeout = 10 * time.Minute
+	}
+	return &ResumableUploadService{repo: repo, chunks: chunks, files: files, opts: opts, now: time.Now}
+}
+
+// MaxSize нь Tus-Max-Size: тохиргооны хязгаар ба аль ч төрлийн зөвшөөрөх
+// хамгийн том хэмжээний бага нь.
+func (s *ResumableUploadService) MaxSize() int64 {
+	max := s.files.guard.MaxSize()
+	if s.opts.MaxSize > 0 && s.opts.MaxSize < max {
+		max = s.opts.MaxSize
+	}
+	return max
+}
+
+// URL нь upload-ийн tus URL (Location).
+func (s *ResumableUploadService) URL(id string) string {
+	return s.opts.BaseURL + id
+}
+
+// Create нь length byte-ийн шинэ upload эхлүүлнэ. meta нь Upload-Metadata
+// (filename, description).
+func (s *ResumableUploadService) Create(ctx context.Context, userID int, length int64, meta map[string]string) (domain.FileUpload, error) {
+	if length > s.MaxSize() {
+		return domain.FileUpload{}, ErrUploadTooLarge
+	}
+	filename := meta["filename"]
+	if filename == "" {
+		filename = meta["name"]
+	}
+	return s.repo.Create(ctx, domain.FileUpload{
+		Id:           uuid.NewString(),
+		UserId:       userID,
+		Filename:     filename,
+		Description:  meta["description"],
+		UploadLength: length,
+		Status:       domain.FileUploadUploading,
+		ExpiresAt:    s.now().Add(s.opts.Expiry),
+	})
+}
+
+// Get нь userID-ийн upload-ийг буцаана; өөр хэрэглэгчийнх бол ErrUploadNotFound.
+func (s *ResumableUploadService) Get(ctx context.Context, userID int, id string) (domain.FileUpload, error) {
+	u, err := s.lookup(ctx, userID, id)
+	if err != nil {
+		return domain.FileUpload{}, err
+	}
+	if s.now().After(u.ExpiresAt) {
+		return domain.FileUpload{}, ErrUploadExpired
+	}
+	return u, nil
+}
+
+// Append нь offset-оос эхлэх data-г нэмнэ (PATCH). offset нь одоогийн
+// upload_offset-той таарах ёстой; sum өгвөл data-г шалгана. Сүүлийн хэсэг
+// ирэхэд файлыг угсарч PublicFile болгоно (FileUrl бөглөгдөнө). Угсрах үед
+// түр алдаа гарвал хоосон data-тай дахин дуудаж давтана.
+func (s *ResumableUploadService) Append(ctx context.Context, userID int, id string, offset int64, data []byte, sum *tus.Checksum) (domain.FileUpload, error) {
+	u, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return domain.FileUpload{}, err
+	}
+	switch u.Status {
+	case domain.FileUploadFinalizing:
+		return domain.FileUpload{}, ErrUploadBusy
+	case domain.FileUploadCompleted:
+		if offset == u.UploadLength && len(data) == 0 {
+			return u, nil
+		}
+		return domain.FileUpload{}, ErrUploadOffsetMismatch
+	}
+	if offset != u.UploadOffset {
+		return domain.FileUpload{}, ErrUploadOffsetMismatch
+	}
+	if offset+int64(len(data)) > u.UploadLength {
+		return domain.FileUpload{}, ErrUploadLengthExceeded
+	}
+	if sum != nil && !sum.Matches(data) {
+		return domain.FileUpload{}, ErrUploadChecksumMismatch
+	}
+
+	if len(data) > 0 {
+		if u, err = s.appendChunk(ctx, u, data); err != nil {
+			return domain.FileUpload{}, err
+		}
+	}
+	if u.UploadOffset == u.UploadLength {
+		return s.finalize(ctx, u)
+	}
+	return u, nil
+}
+
+// appendChunk нь data-г давтагдашгүй key-ээр хадгалаад бүртгэнэ. Зэрэг ирсэн
+// PATCH offset-ийг түрүүлж урагшлуулбал энэ хэсгийг устгаж ErrUploadOffsetMismatch.
+func (s *ResumableUploadService) appendChunk(ctx context.Context, u domain.FileUpload, data []byte) (domain.FileUpload, error) {
+	chunk := domain.FileUploadChunk{
+		UploadId:    u.Id,
+		ChunkOffset: u.UploadOffset,
+		Size:        int64(len(data)),
+		StorageKey:  fmt.Sprintf("%s/%020d-%s", u.Id, u.UploadOffset, uuid.NewString()),
+	}
+	if err := s.chunks.Put(ctx, chunk.StorageKey, bytes.NewReader(data), chunk.Size, "application/octet-stream"); err != nil {
+		return domain.FileUpload{}, err
+	}
+
+	expiresAt := s.now().Add(s.opts.Expiry)
+	ok, err := s.repo.AppendChunk(ctx, chunk, expiresAt)
+	if err != nil || !ok {
+		_ = s.chunks.Delete(context.WithoutCancel(ctx), chunk.StorageKey)
+		if err != nil {
+			return domain.FileUpload{}, err
+		}
+		return domain.FileUpload{}, ErrUploadOffsetMismatch
+	}
+	u.UploadOffset += chunk.Size
+	u.ExpiresAt = expiresAt
+	return u, nil
+}
+
+// finalize нь хэсгүүдийг угсарч PublicFile болгоно. Хариу хүлээж буй client
+// тасарсан ч дуусгахын тулд request-ийн context-оос салгаж ажиллана.
+// Шалгалтад тэнцээгүй (upload.ErrRejected) upload бүхэлдээ устна; бусад
+// алдааны дараа дахин оролдож болно.
+func (s *ResumableUploadService) finalize(ctx context.Context, u domain.FileUpload) (domain.FileUpload, error) {
+	claimed, err := s.repo.SetStatus(ctx, u.Id, domain.FileUploadUploading, domain.FileUploadFinalizing)
+	if err != nil {
+		return domain.FileUpload{}, err
+	}
+	if !claimed {
+		return domain.FileUpload{}, ErrUploadBusy
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.opts.FinalizeTimeout)
+	defer cancel()
+
+	chunks, err := s.repo.Chunks(ctx, u.Id)
+	if err != nil {
+		return s.finalizeFailed(ctx, u, err)
+	}
+	f, err := s.assemble(ctx, u, chunks)
+	if err != nil {
+		return s.finalizeFailed(ctx, u, err)
+	}
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+
+	pf, err := s.files.UploadFile(ctx, u.UserId, u.Filename, u.UploadLength, f, u.Description)
+	if err != nil {
+		if errors.Is(err, upload.ErrRejected) {
+			if rerr := s.remove(ctx, u.Id, chunks); rerr != nil {
+				fmt.Printf("WARN: failed to remove rejected upload %s: %v\n", u.Id, rerr)
+			}
+			return domain.FileUpload{}, err
+		}
+		return s.finalizeFailed(ctx, u, err)
+	}
+
+	// Дууссан upload-ийг HEAD-ээр file_url авахаар Expiry хүртэл үлдээнэ
+	expiresAt := s.now().Add(s.opts.Expiry)
+	if err := s.repo.Complete(ctx, u.Id, pf.FileUrl, expiresAt); err != nil {
+		return domain.FileUpload{}, err
+	}
+	s.deleteChunks(ctx, chunks)
+
+	now := s.now()
+	u.Status = domain.FileUploadCompleted
+	u.FileUrl = pf.FileUrl
+	u.CompletedAt = &now
+	u.ExpiresAt = expiresAt
+	return u, nil
+}
+
+// finalizeFailed нь upload-ийг дахин оролдох боломжтой болгож err-ийг буцаана.
+func (s *ResumableUploadService) finalizeFailed(ctx context.Context, u domain.FileUpload, err error) (domain.FileUpload, error) {
+	if _, rerr := s.repo.SetStatus(ctx, u.Id, domain.FileUploadFinalizing, domain.FileUploadUploading); rerr != nil {
+		fmt.Printf("WARN: failed to reopen upload %s: %v\n", u.Id, rerr)
+	}
+	return domain.FileUpload{}, fmt.Errorf("finalize upload %s: %w", u.Id, err)
+}
+
+// assemble нь хэсгүүдийг дарааллаар нь түр файлд нийлүүлнэ (эхэнд нь буцаасан).
+func (s *ResumableUploadService) assemble(ctx context.Context, u domain.FileUpload, chunks []domain.FileUploadChunk) (*os.File, error) {
+	f, err := os.CreateTemp(s.opts.TempDir, "upload-*")
+	if err != nil {
+		return nil, err
+	}
+	fail := func(err error) (*os.File, error) {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+
+	var offset int64
+	for _, c := range chunks {
+		if c.ChunkOffset != offset {
+			return fail(fmt.Errorf("chunk at %d, expected %d", c.ChunkOffset, offset))
+		}
+		obj, err := s.chunks.Open(ctx, c.StorageKey)
+		if err != nil {
+			return fail(err)
+		}
+		n, err := io.Copy(f, obj.Body)
+		obj.Body.Close()
+		if err != nil {
+			return fail(err)
+		}
+		if n != c.Size {
+			return fail(fmt.Errorf("chunk %s has %d bytes, expected %d", c.StorageKey, n, c.Size))
+		}
+		offset += n
+	}
+	if offset != u.UploadLength {
+		return fail(fmt.Errorf("assembled %d bytes, expected %d", offset, u.UploadLength))
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return fail(err)
+	}
+	return f, nil
+}
+
+// Terminate нь upload-ийг цуцалж хэсгүүдийг устгана (DELETE). Дууссан
+// upload-ийн хувьд зөвхөн бүртгэл устаж, үүссэн файл хэвээр үлдэнэ.
+func (s *ResumableUploadService) Terminate(ctx context.Context, userID int, id string) error {
+	u, err := s.lookup(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if u.Status == domain.FileUploadFinalizing {
+		return ErrUploadBusy
+	}
+	chunks, err := s.repo.Chunks(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.remove(ctx, id, chunks)
+}
+
+// ExpireUploads нь expires_at нь now-оос өмнөх upload-уудыг хэсгүүдтэй нь
+// устгаж тоог буцаана (file_uploads.expire scheduled job).
+func (s *ResumableUploadService) ExpireUploads(ctx context.Context, now time.Time) (int, error) {
+	removed := 0
+	for {
+		items, err := s.repo.ListExpired(ctx, now, expireBatch)
+		if err != nil {
+			return removed, err
+		}
+		for _, u := range items {
+			chunks, err := s.repo.Chunks(ctx, u.Id)
+			if err != nil {
+				return removed, err
+			}
+			if err := s.remove(ctx, u.Id, chunks); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+		if len(items) < expireBatch {
+			return removed, nil
+		}
+	}
+}
+
+// remove нь бүртгэлийг, дараа нь storage дахь хэсгүүдийг устгана.
+func (s *ResumableUploadService) remove(ctx context.Context, id string, chunks []domain.FileUploadChunk) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.deleteChunks(ctx, chunks)
+	return nil
+}
+
+// deleteChunks нь хэсгүүдийг storage-оос устгана; алдааг зөвхөн log-д бичнэ
+// (бүртгэлгүй болсон хэсэг дахин ашиглагдахгүй).
+func (s *ResumableUploadService) deleteChunks(ctx context.Context, chunks []domain.FileUploadChunk) {
+	for _, c := range chunks {
+		if err := s.chunks.Delete(ctx, c.StorageKey); err != nil {
+			fmt.Printf("WARN: failed to delete upload chunk %s: %v\n", c.StorageKey, err)
+		}
+	}
+}
+
+// lookup нь upload-ийг олж эзэмшигчийг шалгана (хугацааг шалгахгүй).
+func (s *ResumableUploadService) lookup(ctx context.Context, userID int, id string) (domain.FileUpload, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return domain.FileUpload{}, ErrUploadNotFound
+	}
+	u, err := s.repo.Get(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && u.UserId != userID) {
+		return domain.FileUpload{}, ErrUploadNotFound
+	}
+	return u, err
+}
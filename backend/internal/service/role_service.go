@@ -11,6 +11,7 @@ package service
 import (
 	"context"
 	"errors"
+	"slices"
 
 	"templatev25/internal/auth"
 	"templatev25/internal/domain"
@@ -21,6 +22,10 @@ import (
 	"go.uber.org/zap"
 )
 
+// ErrRoleCycle нь эцэг role онооход өвлөлтийн цикл үүсэх үед буцна.
+// Шалгалт repository-д бичилттэй нэг transaction-д хийгдэнэ.
+var ErrRoleCycle = repository.ErrRoleCycle
+
 type RoleService struct {
 	repo  repository.RoleRepository
 	log   *zap.Logger
@@ -59,6 +64,10 @@ func (s *RoleService) Create(ctx context.Context, req dto.RoleCreateDto) error {
 		SystemID:    req.SystemID,
 		IsActive:    req.IsActive,
 	}
+	// Шинэ role-д үр удам байхгүй тул цикл үүсэх боломжгүй
+	for _, pid := range uniqueIDs(req.ParentIDs) {
+		m.Parents = append(m.Parents, domain.Role{ID: pid})
+	}
 	if err := s.repo.Create(ctx, m); err != nil {
 		log.Error("role_create_failed", zap.String("code", req.Code), zap.Error(err))
 		return err
@@ -81,14 +90,59 @@ func (s *RoleService) Update(ctx context.Context, id int, req dto.RoleUpdateDto)
 		SystemID:    req.SystemID,
 		IsActive:    req.IsActive,
 	}
-	if err := s.repo.Update(ctx, id, m); err != nil {
+	if req.ParentIDs == nil {
+		if err := s.repo.Update(ctx, id, m); err != nil {
+			log.Error("role_update_failed", zap.Int("role_id", id), zap.Error(err))
+			return err
+		}
+		log.Info("role_updated", zap.Int("role_id", id))
+		return nil
+	}
+
+	// Цикл шалгалт, role болон эцэг role-уудын бичилт нэг transaction-д
+	parentIDs := uniqueIDs(req.ParentIDs)
+	if err := s.repo.UpdateWithParents(ctx, id, m, parentIDs); err != nil {
+		if errors.Is(err, ErrRoleCycle) {
+			log.Warn("role_update_invalid_parents", zap.Int("role_id", id), zap.Ints("parent_ids", req.ParentIDs), zap.Error(err))
+			return err
+		}
 		log.Error("role_update_failed", zap.Int("role_id", id), zap.Error(err))
 		return err
 	}
+	s.invalidateRoleUsers(ctx, id)
 	log.Info("role_updated", zap.Int("role_id", id))
 	return nil
 }
 
+// invalidateRoleUsers нь role болон түүнээс өвлөдөг бүх role-ийн хэрэглэгчдийн
+// permission cache-ийг цэвэрлэнэ. Хэрэглэгчдийг олж чадаагүй бол бүх cache цэвэрлэнэ.
+func (s *RoleService) invalidateRoleUsers(ctx context.Context, roleID int) {
+	if s.cache == nil {
+		return
+	}
+	log := middleware.LoggerOrDefault(ctx, s.log)
+
+	roleIDs, err := s.repo.DescendantIDs(ctx, roleID)
+	if err == nil {
+		var userIDs []int
+		if userIDs, err = s.repo.UserIDsByRoles(ctx, append(roleIDs, roleID)); err == nil {
+			s.cache.InvalidateUsers(userIDs)
+			log.Debug("permission_cache_invalidated", zap.Int("role_id", roleID), zap.Int("user_count", len(userIDs)))
+			return
+		}
+	}
+
+	log.Warn("permission_cache_role_users_failed", zap.Int("role_id", roleID), zap.Error(err))
+	s.cache.InvalidateAll()
+}
+
+// uniqueIDs нь ID-уудыг эрэмбэлж давхардлыг хасна.
+func uniqueIDs(ids []int) []int {
+	out := slices.Clone(ids)
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
 // Delete — model_repo шиг soft-delete, repo буцаасан объектод Deleted* талбарууд populate-лагдана
 func (s *RoleService) Delete(ctx context.Context, id int) error {
 	log := middleware.LoggerOrDefault(ctx, s.log)
@@ -105,6 +159,8 @@ func (s *RoleService) Delete(ctx context.Context, id int) error {
 		log.Error("role_delete_failed", zap.Int("role_id", id), zap.Error(err))
 		return err
 	}
+	// Устгагдсан role-оор дамжих өвлөлт тасарна
+	s.invalidateRoleUsers(ctx, id)
 	log.Info("role_deleted", zap.Int("role_id", id))
 	return nil
 }
@@ -120,6 +176,52 @@ func (s *RoleService) GetPermissions(ctx context.Context, q dto.RolePermissionsQ
 	return perms, nil
 }
 
+// GetEffectivePermissions нь role-ийн шууд permission-ууд болон тэдгээрийн
+// wildcard grant-уудын хамарсан permission-уудыг буцаана (role permission editor).
+func (s *RoleService) GetEffectivePermissions(ctx context.Context, q dto.RolePermissionsQuery) ([]dto.RoleEffectivePermission, error) {
+	log := middleware.LoggerOrDefault(ctx, s.log)
+	direct, err := s.repo.Permissions(ctx, q)
+	if err != nil {
+		log.Error("role_permissions_get_failed", zap.Int("role_id", q.RoleID), zap.Error(err))
+		return nil, err
+	}
+
+	out := make([]dto.RoleEffectivePermission, 0, len(direct))
+	seen := make(map[int]bool, len(direct))
+	granted := make(map[string]bool, len(direct))
+	var patterns []string
+	for _, p := range direct {
+		out = append(out, dto.RoleEffectivePermission{Permission: p})
+		seen[p.ID] = true
+		granted[p.Code] = true
+		if p.IsWildcard() {
+			patterns = append(patterns, p.Code)
+		}
+	}
+	if len(patterns) == 0 {
+		return out, nil
+	}
+
+	implied, err := s.repo.MatchingPermissions(ctx, patterns)
+	if err != nil {
+		log.Error("role_implied_permissions_get_failed", zap.Int("role_id", q.RoleID), zap.Error(err))
+		return nil, err
+	}
+	for _, p := range implied {
+		if seen[p.ID] {
+			continue
+		}
+		// Хамгийн ойрын (нарийн) wildcard-ийг заана
+		for _, code := range domain.PermissionGrantCodes(p.Code)[1:] {
+			if granted[code] {
+				out = append(out, dto.RoleEffectivePermission{Permission: p, GrantedBy: code})
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
 func (s *RoleService) SetPermissions(ctx context.Context, req dto.RolePermissionsUpdateDto) error {
 	log := middleware.LoggerOrDefault(ctx, s.log)
 	if err := s.repo.ReplacePermissions(ctx, req.RoleID, req.PermissionIDs); err != nil {
@@ -127,11 +229,8 @@ func (s *RoleService) SetPermissions(ctx context.Context, req dto.RolePermission
 		return err
 	}
 
-	// Permission cache цэвэрлэх (role-д хамаарах бүх хэрэглэгчид)
-	if s.cache != nil {
-		s.cache.InvalidateAll() // Role permission өөрчлөгдөхөд бүх cache цэвэрлэх
-		log.Debug("permission_cache_invalidated", zap.Int("role_id", req.RoleID))
-	}
+	// Permission cache цэвэрлэх (role болон түүнээс өвлөдөг role-уудын хэрэглэгчид)
+	s.invalidateRoleUsers(ctx, req.RoleID)
 
 	log.Info("role_permissions_updated", zap.Int("role_id", req.RoleID), zap.Int("permission_count", len(req.PermissionIDs)))
 	return nil
@@ -7,6 +7,7 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
@@ -22,6 +23,7 @@ type SessionStore interface {
 	Create(ctx context.Context, session *SessionData) error
 	Get(ctx context.Context, sessionID string) (*SessionData, error)
 	Update(ctx context.Context, session *SessionData) error
+	Touch(ctx context.Context, sessionID string, at time.Time) error
 	Delete(ctx context.Context, sessionID string) error
 	Refresh(ctx context.Context, sessionID string, newExpiry time.Time) error
 
@@ -34,6 +36,14 @@ type SessionStore interface {
 	GetMFAToken(ctx context.Context, token string) (*MFAPendingData, error)
 	DeleteMFAToken(ctx context.Context, token string) error
 
+	// WebAuthn registration ceremony state (single use)
+	StoreWebAuthnChallenge(ctx context.Context, key string, data *WebAuthnChallengeData, ttl time.Duration) error
+	TakeWebAuthnChallenge(ctx context.Context, key string) (*WebAuthnChallengeData, error)
+
+	// Realtime (WebSocket/SSE) connection tickets (single use)
+	StoreRealtimeTicket(ctx context.Context, ticket string, data *RealtimeTicketData, ttl time.Duration) error
+	TakeRealtimeTicket(ctx context.Context, ticket string) (*RealtimeTicketData, error)
+
 	// Health check
 	Ping(ctx context.Context) error
 
@@ -41,6 +51,9 @@ type SessionStore interface {
 	Close() error
 }
 
+// ErrSessionNotFound is returned when writing to a session that no longer exists
+var ErrSessionNotFound = errors.New("session not found")
+
 // SessionData represents a user session stored in Redis
 type SessionData struct {
 	SessionID      string    `json:"session_id"`
@@ -51,6 +64,10 @@ type SessionData struct {
 	CreatedAt      time.Time `json:"created_at"`
 	ExpiresAt      time.Time `json:"expires_at"`
 	LastActivityAt time.Time `json:"last_activity_at"`
+	// OrgID is the organization the session acts in (0 when the user has none)
+	OrgID int `json:"org_id,omitempty"`
+	// HasRefreshToken marks short-lived sessions renewed via refresh token rotation
+	HasRefreshToken bool `json:"has_refresh_token,omitempty"`
 }
 
 // MFAPendingData represents temporary data during MFA verification
@@ -60,6 +77,25 @@ type MFAPendingData struct {
 	IPAddress string    `json:"ip_address"`
 	UserAgent string    `json:"user_agent"`
 	ExpiresAt time.Time `json:"expires_at"`
+	// IssueRefreshToken carries the login option through the MFA step
+	IssueRefreshToken bool `json:"issue_refresh_token,omitempty"`
+	// WebAuthnChallenge is the assertion challenge when the user has WebAuthn credentials
+	WebAuthnChallenge string `json:"webauthn_challenge,omitempty"`
+}
+
+// WebAuthnChallengeData represents a pending WebAuthn registration ceremony
+type WebAuthnChallengeData struct {
+	UserID     int    `json:"user_id"`
+	Challenge  string `json:"challenge"`
+	UserHandle string `json:"user_handle"`
+}
+
+// RealtimeTicketData represents a short-lived ticket that authenticates a
+// WebSocket/SSE connection on behalf of an existing session
+type RealtimeTicketData struct {
+	SessionID string `json:"session_id"`
+	// Local is true for local (email/password) sessions, false for SSO
+	Local bool `json:"local,omitempty"`
 }
 
 // RedisSessionStore implements SessionStore using Redis
@@ -73,6 +109,8 @@ const (
 	sessionPrefix     = "session:"
 	userSessionPrefix = "user:sessions:"
 	mfaTokenPrefix    = "mfa:token:"
+	webAuthnPrefix    = "webauthn:challenge:"
+	realtimePrefix    = "realtime:ticket:"
 )
 
 // NewRedisSessionStore creates a new Redis session store with a pre-created Redis client
@@ -163,7 +201,8 @@ func (s *RedisSessionStore) Get(ctx context.Context, sessionID string) (*Session
 	return &session, nil
 }
 
-// Update updates an existing session in Redis
+// Update updates an existing session in Redis. A session that was deleted
+// meanwhile (logout, refresh token rotation) is not recreated.
 func (s *RedisSessionStore) Update(ctx context.Context, session *SessionData) error {
 	// Serialize session data
 	data, err := json.Marshal(session)
@@ -177,15 +216,58 @@ func (s *RedisSessionStore) Update(ctx context.Context, session *SessionData) er
 		return fmt.Errorf("session already expired")
 	}
 
-	// Update session
+	// Update session only if it still exists
 	key := s.sessionKey(session.SessionID)
-	if err := s.client.Set(ctx, key, data, ttl).Err(); err != nil {
+	err = s.client.SetArgs(ctx, key, data, redis.SetArgs{Mode: "XX", TTL: ttl}).Err()
+	if err == redis.Nil {
+		return ErrSessionNotFound
+	}
+	if err != nil {
 		return fmt.Errorf("failed to update session: %w", err)
 	}
 
 	return nil
 }
 
+// Touch records activity on an existing session, keeping its TTL. The write
+// is dropped when the session is deleted or updated concurrently, so it never
+// undoes a logout or overwrites a newer session (e.g. an organization change).
+func (s *RedisSessionStore) Touch(ctx context.Context, sessionID string, at time.Time) error {
+	key := s.sessionKey(sessionID)
+	err := s.client.Watch(ctx, func(tx *redis.Tx) error {
+		data, err := tx.Get(ctx, key).Bytes()
+		if err == redis.Nil {
+			return ErrSessionNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var session SessionData
+		if err := json.Unmarshal(data, &session); err != nil {
+			return fmt.Errorf("failed to unmarshal session: %w", err)
+		}
+		session.LastActivityAt = at
+		if data, err = json.Marshal(&session); err != nil {
+			return fmt.Errorf("failed to marshal session: %w", err)
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.SetArgs(ctx, key, data, redis.SetArgs{Mode: "XX", KeepTTL: true})
+			return nil
+		})
+		return err
+	}, key)
+	if errors.Is(err, redis.TxFailedErr) {
+		// Changed or deleted meanwhile; that write wins
+		return nil
+	}
+	if err != nil && !errors.Is(err, ErrSessionNotFound) {
+		return fmt.Errorf("failed to touch session: %w", err)
+	}
+	return err
+}
+
 // Delete removes a session from Redis
 func (s *RedisSessionStore) Delete(ctx context.Context, sessionID string) error {
 	// Get session first to get userID for cleanup
@@ -217,7 +299,7 @@ func (s *RedisSessionStore) Refresh(ctx context.Context, sessionID string, newEx
 		return err
 	}
 	if session == nil {
-		return fmt.Errorf("session not found")
+		return ErrSessionNotFound
 	}
 
 	session.ExpiresAt = newExpiry
@@ -321,6 +403,80 @@ func (s *RedisSessionStore) DeleteMFAToken(ctx context.Context, token string) er
 	return s.client.Del(ctx, key).Err()
 }
 
+// ============================================================
+// WEBAUTHN CHALLENGE MANAGEMENT
+// ============================================================
+
+// StoreWebAuthnChallenge stores a pending WebAuthn registration ceremony
+func (s *RedisSessionStore) StoreWebAuthnChallenge(ctx context.Context, key string, data *WebAuthnChallengeData, ttl time.Duration) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal WebAuthn challenge: %w", err)
+	}
+
+	if err := s.client.Set(ctx, s.webAuthnKey(key), jsonData, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to store WebAuthn challenge: %w", err)
+	}
+
+	return nil
+}
+
+// TakeWebAuthnChallenge retrieves and deletes a pending WebAuthn ceremony,
+// so each challenge can be answered only once
+func (s *RedisSessionStore) TakeWebAuthnChallenge(ctx context.Context, key string) (*WebAuthnChallengeData, error) {
+	data, err := s.client.GetDel(ctx, s.webAuthnKey(key)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil // Challenge not found or expired
+		}
+		return nil, fmt.Errorf("failed to get WebAuthn challenge: %w", err)
+	}
+
+	var challenge WebAuthnChallengeData
+	if err := json.Unmarshal(data, &challenge); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal WebAuthn challenge: %w", err)
+	}
+
+	return &challenge, nil
+}
+
+// ============================================================
+// REALTIME TICKET MANAGEMENT
+// ============================================================
+
+// StoreRealtimeTicket stores a realtime connection ticket
+func (s *RedisSessionStore) StoreRealtimeTicket(ctx context.Context, ticket string, data *RealtimeTicketData, ttl time.Duration) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal realtime ticket: %w", err)
+	}
+
+	if err := s.client.Set(ctx, s.realtimeTicketKey(ticket), jsonData, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to store realtime ticket: %w", err)
+	}
+
+	return nil
+}
+
+// TakeRealtimeTicket retrieves and deletes a realtime connection ticket,
+// so each ticket opens only one connection
+func (s *RedisSessionStore) TakeRealtimeTicket(ctx context.Context, ticket string) (*RealtimeTicketData, error) {
+	data, err := s.client.GetDel(ctx, s.realtimeTicketKey(ticket)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil // Ticket not found, expired or already used
+		}
+		return nil, fmt.Errorf("failed to get realtime ticket: %w", err)
+	}
+
+	var t RealtimeTicketData
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal realtime ticket: %w", err)
+	}
+
+	return &t, nil
+}
+
 // ============================================================
 // UTILITY METHODS
 // ============================================================
@@ -347,3 +503,11 @@ func (s *RedisSessionStore) userSessionsKey(userID int) string {
 func (s *RedisSessionStore) mfaTokenKey(token string) string {
 	return s.prefix + mfaTokenPrefix + token
 }
+
+func (s *RedisSessionStore) webAuthnKey(key string) string {
+	return s.prefix + webAuthnPrefix + key
+}
+
+func (s *RedisSessionStore) realtimeTicketKey(ticket string) string {
+	return s.prefix + realtimePrefix + ticket
+}
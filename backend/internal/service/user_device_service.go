@@ -0,0 +1,55 @@
+// Package service provides implementation for service
+//
+// File: user_device_service.go
+// Description: Device registration for push notifications
+package service
+
+import (
+	"context"
+	"errors"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/http/dto"
+	"templatev25/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// ErrDeviceNotFound нь хэрэглэгчид тухайн device_id бүртгэлгүй.
+var ErrDeviceNotFound = errors.New("device not found")
+
+type UserDeviceService struct{ repo repository.UserDeviceRepository }
+
+func NewUserDeviceService(repo repository.UserDeviceRepository) *UserDeviceService {
+	return &UserDeviceService{repo: repo}
+}
+
+func (s *UserDeviceService) List(ctx context.Context, userID int) ([]domain.UserDevice, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+// Register нь төхөөрөмжийг бүртгэх эсвэл шинэчилж идэвхжүүлнэ
+// (app эхлэх бүрт, push token солигдох үед дуудна).
+func (s *UserDeviceService) Register(ctx context.Context, userID int, req dto.UserDeviceRegisterDto) (domain.UserDevice, error) {
+	return s.repo.Register(ctx, domain.UserDevice{
+		UserId:       userID,
+		DeviceId:     req.DeviceId,
+		DeviceType:   req.DeviceType,
+		DeviceName:   req.DeviceName,
+		DeviceModel:  req.DeviceModel,
+		OsName:       req.OsName,
+		OsVersion:    req.OsVersion,
+		AppVersion:   req.AppVersion,
+		PushToken:    req.PushToken,
+		PushProvider: req.PushProvider,
+	})
+}
+
+// Unregister нь logout хийх үед төхөөрөмжийг устгана.
+func (s *UserDeviceService) Unregister(ctx context.Context, userID int, deviceID string) error {
+	err := s.repo.Unregister(ctx, userID, deviceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrDeviceNotFound
+	}
+	return err
+}
@@ -49,7 +49,7 @@ func (s *userRoleService) RolesByUser(ctx context.Context, q dto.UserRoleRolesQu
 	return s.repo.RolesByUser(ctx, q)
 }
 func (s *userRoleService) AssignByRole(ctx context.Context, req dto.UserRoleAssignByRole) error {
-	if err := s.repo.AddUsersToRole(ctx, req.RoleID, req.UserIDs); err != nil {
+	if err := s.repo.AddUsersToRole(ctx, req.RoleID, req.OrgID, req.UserIDs); err != nil {
 		return err
 	}
 	// Cache цэвэрлэх (role-д нэмэгдсэн хэрэглэгчид)
@@ -59,7 +59,7 @@ func (s *userRoleService) AssignByRole(ctx context.Context, req dto.UserRoleAssi
 	return nil
 }
 func (s *userRoleService) AssignByUser(ctx context.Context, req dto.UserRoleAssignByUser) error {
-	if err := s.repo.AddRolesToUser(ctx, req.UserID, req.RoleIDs); err != nil {
+	if err := s.repo.AddRolesToUser(ctx, req.UserID, req.OrgID, req.RoleIDs); err != nil {
 		return err
 	}
 	// Cache цэвэрлэх (хэрэглэгчийн role өөрчлөгдсөн)
@@ -69,7 +69,7 @@ func (s *userRoleService) AssignByUser(ctx context.Context, req dto.UserRoleAssi
 	return nil
 }
 func (s *userRoleService) Remove(ctx context.Context, req dto.UserRoleRemoveDto) error {
-	if err := s.repo.Remove(ctx, req.UserID, req.RoleID); err != nil {
+	if err := s.repo.Remove(ctx, req.UserID, req.RoleID, req.OrgID); err != nil {
 		return err
 	}
 	// Cache цэвэрлэх (хэрэглэгчийн role устсан)
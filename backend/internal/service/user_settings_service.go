@@ -0,0 +1,111 @@
+// Package service provides implementation for service
+//
+// File: user_settings_service.go
+// Description: Current user's settings with defaults and custom_settings validation
+package service
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/http/dto"
+	"templatev25/internal/jsonschema"
+	"templatev25/internal/repository"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// maxCustomSettingsSize нь custom_settings JSON-ийн дээд хэмжээ (byte).
+const maxCustomSettingsSize = 16 << 10
+
+//go:embed schemas/user_custom_settings.json
+var userCustomSettingsSchema []byte
+
+// ErrUserSettingsInvalid нь custom_settings schema-д тохирохгүй.
+var ErrUserSettingsInvalid = errors.New("custom_settings does not match the schema")
+
+// UserSettingsValidationError нь custom_settings-ийн алдаа бүрийг JSON pointer-оор заана.
+type UserSettingsValidationError struct {
+	Errors []jsonschema.ValidationError
+}
+
+func (e *UserSettingsValidationError) Error() string {
+	return fmt.Sprintf("%s (%d errors)", ErrUserSettingsInvalid.Error(), len(e.Errors))
+}
+
+func (e *UserSettingsValidationError) Unwrap() error { return ErrUserSettingsInvalid }
+
+type UserSettingsService struct {
+	repo   repository.UserSettingsRepository
+	schema *jsonschema.Schema
+}
+
+// NewUserSettingsService нь schemas/user_custom_settings.json-оор custom_settings шалгана.
+func NewUserSettingsService(repo repository.UserSettingsRepository) *UserSettingsService {
+	return &UserSettingsService{
+		repo:   repo,
+		schema: jsonschema.MustCompile(userCustomSettingsSchema),
+	}
+}
+
+// Get нь хэрэглэгчийн тохиргоо; мөр байхгүй бол default утгууд (id = 0).
+func (s *UserSettingsService) Get(ctx context.Context, userID int) (domain.UserSettings, error) {
+	m, err := s.repo.ByUserID(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return domain.DefaultUserSettings(userID), nil
+	}
+	return m, err
+}
+
+// Update нь илгээсэн талбаруудыг одоогийн (эсвэл default) утга дээр хэрэглэж хадгална.
+// Унших, нэгтгэх, хадгалах нь түгжигдсэн мөр дээр нэг transaction-д хийгдэнэ
+// (зэрэг ирсэн хүсэлтүүд бие биенийхээ талбарыг дарахгүй).
+func (s *UserSettingsService) Update(ctx context.Context, userID int, req dto.UserSettingsUpdateDto) (domain.UserSettings, error) {
+	var custom datatypes.JSON
+	if raw := bytes.TrimSpace(req.CustomSettings); len(raw) > 0 && !bytes.Equal(raw, []byte("null")) {
+		if err := s.validateCustom(raw); err != nil {
+			return domain.UserSettings{}, err
+		}
+		custom = datatypes.JSON(raw)
+	}
+
+	return s.repo.Modify(ctx, userID, func(m *domain.UserSettings) {
+		setIf(&m.NotificationPush, req.NotificationPush)
+		setIf(&m.NotificationEmail, req.NotificationEmail)
+		setIf(&m.NotificationSms, req.NotificationSms)
+		setIf(&m.Theme, req.Theme)
+		setIf(&m.Language, req.Language)
+		setIf(&m.PrivacyProfilePublic, req.PrivacyProfilePublic)
+		setIf(&m.PrivacyShowEmail, req.PrivacyShowEmail)
+		setIf(&m.PrivacyShowPhone, req.PrivacyShowPhone)
+		if custom != nil {
+			m.CustomSettings = custom
+		}
+	})
+}
+
+// validateCustom нь хэмжээ болон JSON schema-г шалгана.
+func (s *UserSettingsService) validateCustom(raw json.RawMessage) error {
+	if len(raw) > maxCustomSettingsSize {
+		return &UserSettingsValidationError{Errors: []jsonschema.ValidationError{{
+			Message: fmt.Sprintf("must be at most %d bytes", maxCustomSettingsSize),
+		}}}
+	}
+	if errs := s.schema.ValidateJSON(raw); len(errs) > 0 {
+		return &UserSettingsValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// setIf нь v != nil бол dst-г солино.
+func setIf[T any](dst *T, v *T) {
+	if v != nil {
+		*dst = *v
+	}
+}
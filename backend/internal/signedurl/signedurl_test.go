@@ -0,0 +1,78 @@
+// Package signedurl signs and verifies expiring download links
+//
+// File: signedurl_test.go
+// Description: Unit tests for signing and verification
+package signedurl
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSigner(t *testing.T) *Signer {
+	t.Helper()
+	s, err := NewSigner([]byte(strings.Repeat("k", MinSecretLen)))
+	require.NoError(t, err)
+	return s
+}
+
+func TestNewSigner_ShortSecret(t *testing.T) {
+	_, err := NewSigner([]byte("short"))
+	assert.ErrorIs(t, err, ErrSecretTooShort)
+}
+
+func TestSignVerify(t *testing.T) {
+	s := testSigner(t)
+	now := time.Unix(1_700_000_000, 0)
+	q := s.Sign("a.pdf", 0, now.Add(time.Minute))
+	assert.False(t, q.Has(ParamUserID), "unbound link has no uid")
+
+	p := ParamsFromQuery(func(key string, _ ...string) string { return q.Get(key) })
+	require.NoError(t, s.Verify("a.pdf", p, now))
+	require.NoError(t, s.Verify("a.pdf", p, now.Add(time.Minute)), "valid until the expiry second")
+
+	err := s.Verify("a.pdf", p, now.Add(time.Minute+time.Second))
+	assert.ErrorIs(t, err, ErrExpired)
+	assert.ErrorIs(t, err, ErrInvalid)
+
+	assert.ErrorIs(t, s.Verify("b.pdf", p, now), ErrSignatureInvalid, "other resource")
+
+	extended := p
+	extended.Expires += 3600
+	assert.ErrorIs(t, s.Verify("a.pdf", extended, now), ErrSignatureInvalid, "tampered expiry")
+
+	rebound := p
+	rebound.UserID = 7
+	assert.ErrorIs(t, s.Verify("a.pdf", rebound, now), ErrSignatureInvalid, "added uid")
+
+	assert.ErrorIs(t, s.Verify("a.pdf", Params{Expires: p.Expires}, now), ErrInvalid, "missing sig")
+	assert.ErrorIs(t, s.Verify("a.pdf", Params{Expires: p.Expires, Signature: "%%%"}, now), ErrInvalid, "malformed sig")
+}
+
+func TestSign_UserBinding(t *testing.T) {
+	s := testSigner(t)
+	now := time.Now()
+	q := s.Sign("a.pdf", 42, now.Add(time.Minute))
+	assert.Equal(t, "42", q.Get(ParamUserID))
+
+	p := ParamsFromQuery(func(key string, _ ...string) string { return q.Get(key) })
+	require.NoError(t, s.Verify("a.pdf", p, now))
+	assert.Equal(t, 42, p.UserID)
+
+	unbound := p
+	unbound.UserID = 0
+	assert.ErrorIs(t, s.Verify("a.pdf", unbound, now), ErrSignatureInvalid, "uid cannot be dropped")
+}
+
+func TestSign_OtherSecret(t *testing.T) {
+	now := time.Now()
+	q := testSigner(t).Sign("a.pdf", 0, now.Add(time.Minute))
+	other, err := NewSigner([]byte(strings.Repeat("x", MinSecretLen)))
+	require.NoError(t, err)
+	p := ParamsFromQuery(func(key string, _ ...string) string { return q.Get(key) })
+	assert.ErrorIs(t, other.Verify("a.pdf", p, now), ErrSignatureInvalid)
+}
@@ -0,0 +1,134 @@
+// Package storage provides pluggable object storage for uploaded files
+//
+// File: local.go
+// Description: Local filesystem storage driver
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// LocalStorage keeps objects as files under a root directory. Only suitable
+// for a single instance or a shared volume mounted on every replica.
+type LocalStorage struct {
+	dir string
+}
+
+// NewLocalStorage creates a local driver rooted at dir. Directories are
+// created on the first Put.
+func NewLocalStorage(dir string) (*LocalStorage, error) {
+	if dir == "" {
+		return nil, errors.New("storage: local dir is required")
+	}
+	return &LocalStorage{dir: dir}, nil
+}
+
+// path maps a validated key to a file path under the root
+func (s *LocalStorage) path(key string) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// Put implements Storage. Contents are written to a temp file and renamed,
+// so readers never see a partial object.
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	dst, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return fmt.Errorf("storage: local mkdir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("storage: local create: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("storage: local write: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("storage: local write: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return fmt.Errorf("storage: local rename: %w", err)
+	}
+	return nil
+}
+
+// Open implements Storage
+func (s *LocalStorage) Open(ctx context.Context, key string) (*Object, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filepath.Clean(p))
+	if err != nil {
+		return nil, mapLocalErr(err)
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, mapLocalErr(err)
+	}
+	if st.IsDir() {
+		f.Close()
+		return nil, ErrNotFound
+	}
+	return &Object{ObjectInfo: localInfo(key, st), Body: f}, nil
+}
+
+// Stat implements Storage
+func (s *LocalStorage) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	st, err := os.Stat(p)
+	if err != nil {
+		return ObjectInfo{}, mapLocalErr(err)
+	}
+	if st.IsDir() {
+		return ObjectInfo{}, ErrNotFound
+	}
+	return localInfo(key, st), nil
+}
+
+// Delete implements Storage
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("storage: local remove: %w", err)
+	}
+	return nil
+}
+
+func localInfo(key string, st fs.FileInfo) ObjectInfo {
+	return ObjectInfo{
+		Key:          key,
+		Size:         st.Size(),
+		ContentType:  contentTypeFor(key),
+		LastModified: st.ModTime(),
+	}
+}
+
+func mapLocalErr(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return fmt.Errorf("storage: local: %w", err)
+}
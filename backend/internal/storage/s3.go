@@ -0,0 +1,387 @@
+// Package storage provides pluggable object storage for uploaded files
+//
+// File: s3.go
+// Description: S3-compatible storage driver (AWS S3, MinIO) with SigV4 signing
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	s3Service         = "s3"
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3DateFormat      = "20060102T150405Z"
+)
+
+// emptySHA256 is the hex SHA-256 of an empty body
+const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// S3Config holds S3-compatible storage settings
+type S3Config struct {
+	// Endpoint is the service URL, e.g. https://s3.ap-east-1.amazonaws.com
+	// or http://localhost:9000 for MinIO
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+
+	// Prefix is prepended to every key ("uploads/")
+	Prefix string
+
+	// PathStyle addresses the bucket as {endpoint}/{bucket}/{key} instead
+	// of {bucket}.{host}/{key}. Required for MinIO.
+	PathStyle bool
+
+	// Timeout bounds a single request, including the body transfer
+	Timeout time.Duration
+}
+
+// S3Storage stores objects in an S3 bucket
+type S3Storage struct {
+	cfg    S3Config
+	base   *url.URL
+	prefix string
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewS3Storage creates an S3 driver
+func NewS3Storage(cfg S3Config) (*S3Storage, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("storage: s3 needs endpoint and bucket")
+	}
+	if cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, errors.New("storage: s3 needs access key and secret key")
+	}
+	base, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("storage: invalid s3 endpoint %q", cfg.Endpoint)
+	}
+	if !cfg.PathStyle {
+		base.Host = cfg.Bucket + "." + base.Host
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	prefix := strings.Trim(cfg.Prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return &S3Storage{
+		cfg:    cfg,
+		base:   base,
+		prefix: prefix,
+		client: &http.Client{Timeout: cfg.Timeout},
+		now:    time.Now,
+	}, nil
+}
+
+// Put implements Storage
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if size < 0 {
+		// S3 needs Content-Length; buffer bodies of unknown size
+		buf, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("storage: s3 read body: %w", err)
+		}
+		r, size = bytes.NewReader(buf), int64(len(buf))
+	}
+	if contentType == "" {
+		contentType = contentTypeFor(key)
+	}
+
+	req, err := s.newRequest(ctx, http.MethodPut, key, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := s.do(req, s3UnsignedPayload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp)
+	}
+	return nil
+}
+
+// Open implements Storage
+func (s *S3Storage) Open(ctx context.Context, key string) (*Object, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req, emptySHA256)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, s3Error(resp)
+	}
+	return &Object{ObjectInfo: s3Info(key, resp), Body: resp.Body}, nil
+}
+
+// Stat implements Storage
+func (s *S3Storage) Stat(ctx context.Context, key string) (ObjectInfo, error) {
+	req, err := s.newRequest(ctx, http.MethodHead, key, nil)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	resp, err := s.do(req, emptySHA256)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ObjectInfo{}, s3Error(resp)
+	}
+	return s3Info(key, resp), nil
+}
+
+// Delete implements Storage (S3 DELETE is already idempotent)
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req, emptySHA256)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		if err := s3Error(resp); !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnsureBucket creates the bucket when it does not exist. Meant for local
+// MinIO setups and tests; production buckets are provisioned separately.
+func (s *S3Storage) EnsureBucket(ctx context.Context) error {
+	req, err := s.bucketRequest(ctx, http.MethodHead, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req, emptySHA256)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("storage: s3 head bucket returned %d", resp.StatusCode)
+	}
+
+	var body []byte
+	if s.cfg.Region != "us-east-1" {
+		body = []byte("<CreateBucketConfiguration><LocationConstraint>" + s.cfg.Region +
+			"</LocationConstraint></CreateBucketConfiguration>")
+	}
+	req, err = s.bucketRequest(ctx, http.MethodPut, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp, err = s.do(req, hexSHA256(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp)
+	}
+	return nil
+}
+
+// newRequest builds a request for key with an RFC 3986 encoded path
+func (s *S3Storage) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+	objectPath := "/" + s.prefix + key
+	if s.cfg.PathStyle {
+		objectPath = "/" + s.cfg.Bucket + objectPath
+	}
+	return s.pathRequest(ctx, method, objectPath, body)
+}
+
+// bucketRequest builds a request for the bucket itself
+func (s *S3Storage) bucketRequest(ctx context.Context, method string, body io.Reader) (*http.Request, error) {
+	bucketPath := "/"
+	if s.cfg.PathStyle {
+		bucketPath = "/" + s.cfg.Bucket
+	}
+	return s.pathRequest(ctx, method, bucketPath, body)
+}
+
+func (s *S3Storage) pathRequest(ctx context.Context, method, p string, body io.Reader) (*http.Request, error) {
+	u := *s.base
+	u.Path = strings.TrimRight(u.Path, "/") + p
+	u.RawPath = uriEncode(u.Path, false)
+	return http.NewRequestWithContext(ctx, method, u.String(), body)
+}
+
+// do signs and sends req
+func (s *S3Storage) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	signV4(req, payloadHash, s.cfg.AccessKey, s.cfg.SecretKey, s.cfg.Region, s3Service, s.now())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("storage: s3 %s: %w", req.Method, err)
+	}
+	return resp, nil
+}
+
+func s3Info(key string, resp *http.Response) ObjectInfo {
+	info := ObjectInfo{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.LastModified = t
+	}
+	if info.ContentType == "" {
+		info.ContentType = contentTypeFor(key)
+	}
+	return info
+}
+
+// s3Error maps an error response; ErrNotFound for missing keys
+func s3Error(resp *http.Response) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var e struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	_ = xml.Unmarshal(raw, &e)
+	if e.Code == "NoSuchKey" {
+		return ErrNotFound
+	}
+	return fmt.Errorf("storage: s3 returned %d: %s %s", resp.StatusCode, e.Code, e.Message)
+}
+
+// signV4 adds an AWS Signature Version 4 Authorization header. Host and
+// all X-Amz-* headers are signed.
+func signV4(req *http.Request, payloadHash, accessKey, secretKey, region, service string, now time.Time) {
+	amzDate := now.UTC().Format(s3DateFormat)
+	day := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		values := append([]string(nil), q[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes everything but RFC 3986 unreserved characters;
+// "/" is kept unless encodeSlash is set
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
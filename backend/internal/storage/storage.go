@@ -0,0 +1,124 @@
+// Package storage provides pluggable object storage for uploaded files
+//
+// File: storage.go
+// Description: Storage interface, object metadata and driver selection
+//
+// This package provides:
+//   - Storage interface implemented by local filesystem and S3-compatible drivers
+//   - Key validation shared by all drivers
+//
+// Keys are slash separated relative paths ("3f2a....png", "avatars/1.jpg").
+// The local driver maps them under its root directory, the S3 driver under
+// an optional prefix in the bucket, so files written by one driver can be
+// copied to the other unchanged.
+//
+// Usage:
+//
+//	s, err := storage.New(cfg)
+//	err = s.Put(ctx, "3f2a.png", r, size, "image/png")
+//	obj, err := s.Open(ctx, "3f2a.png")
+//	defer obj.Body.Close()
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+	"time"
+)
+
+// Driver names
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// Common errors
+var (
+	ErrNotFound      = errors.New("storage: object not found")
+	ErrInvalidKey    = errors.New("storage: invalid key")
+	ErrUnknownDriver = errors.New("storage: unknown driver")
+)
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// Object is an opened object; the caller must close Body
+type Object struct {
+	ObjectInfo
+	Body io.ReadCloser
+}
+
+// Storage stores and serves file contents by key
+type Storage interface {
+	// Put writes r under key, replacing an existing object. size may be -1
+	// when unknown.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+
+	// Open returns the object contents; ErrNotFound when missing
+	Open(ctx context.Context, key string) (*Object, error)
+
+	// Stat returns object metadata; ErrNotFound when missing
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// Config holds storage driver settings
+type Config struct {
+	// Driver selects the implementation: local or s3
+	Driver string
+
+	// LocalDir is the root directory of the local driver
+	LocalDir string
+
+	// S3 settings (AWS S3, MinIO or any S3-compatible service)
+	S3 S3Config
+}
+
+// New creates a Storage for the configured driver
+func New(cfg Config) (Storage, error) {
+	switch strings.ToLower(cfg.Driver) {
+	case DriverLocal, "":
+		return NewLocalStorage(cfg.LocalDir)
+	case DriverS3:
+		return NewS3Storage(cfg.S3)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDriver, cfg.Driver)
+	}
+}
+
+// ValidateKey rejects empty, absolute and parent-relative keys, so a key
+// taken from a URL can never escape the storage root.
+func ValidateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.ContainsAny(key, "\\\x00") {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	if path.Clean(key) != key {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == ".." || part == "." {
+			return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+	return nil
+}
+
+// contentTypeFor guesses a content type from the key extension
+func contentTypeFor(key string) string {
+	if ct := mime.TypeByExtension(path.Ext(key)); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,202 @@
+// Package storage provides pluggable object storage for uploaded files
+//
+// File: storage_test.go
+// Description: Unit tests for local and S3 storage drivers
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateKey(t *testing.T) {
+	for _, key := range []string{"a.png", "avatars/1.jpg", "3f2a-9c.pdf"} {
+		assert.NoError(t, ValidateKey(key), key)
+	}
+	for _, key := range []string{"", "/etc/passwd", "../secret", "a/../../b", "a//b", "./a", "a\\b", "dir/"} {
+		assert.ErrorIs(t, ValidateKey(key), ErrInvalidKey, key)
+	}
+}
+
+func TestNew_Drivers(t *testing.T) {
+	s, err := New(Config{LocalDir: t.TempDir()})
+	require.NoError(t, err)
+	assert.IsType(t, &LocalStorage{}, s)
+
+	s, err = New(Config{Driver: "s3", S3: S3Config{Endpoint: "http://localhost:9000", Bucket: "b", AccessKey: "k", SecretKey: "s"}})
+	require.NoError(t, err)
+	assert.IsType(t, &S3Storage{}, s)
+
+	_, err = New(Config{Driver: "ftp"})
+	assert.ErrorIs(t, err, ErrUnknownDriver)
+}
+
+// testStorage runs the behaviour every driver must share
+func testStorage(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	require.NoError(t, s.Put(ctx, "docs/a.txt", strings.NewReader("hello"), 5, "text/plain"))
+	require.NoError(t, s.Put(ctx, "docs/a.txt", strings.NewReader("hello world"), -1, "text/plain"))
+
+	info, err := s.Stat(ctx, "docs/a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), info.Size)
+	assert.True(t, strings.HasPrefix(info.ContentType, "text/plain"))
+
+	obj, err := s.Open(ctx, "docs/a.txt")
+	require.NoError(t, err)
+	body, err := io.ReadAll(obj.Body)
+	require.NoError(t, obj.Body.Close())
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(body))
+
+	require.NoError(t, s.Delete(ctx, "docs/a.txt"))
+	require.NoError(t, s.Delete(ctx, "docs/a.txt"), "delete is idempotent")
+
+	_, err = s.Open(ctx, "docs/a.txt")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Stat(ctx, "docs/a.txt")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = s.Open(ctx, "../a.txt")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestLocalStorage(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewLocalStorage(dir)
+	require.NoError(t, err)
+	testStorage(t, s)
+}
+
+func TestLocalStorage_ReadsExistingFiles(t *testing.T) {
+	// Files written before the storage layer existed keep resolving by name
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "3f2a.png"), []byte("png"), 0o600))
+
+	s, err := NewLocalStorage(dir)
+	require.NoError(t, err)
+	obj, err := s.Open(context.Background(), "3f2a.png")
+	require.NoError(t, err)
+	defer obj.Body.Close()
+	assert.Equal(t, "image/png", obj.ContentType)
+	assert.Equal(t, int64(3), obj.Size)
+}
+
+// fakeS3 is a minimal in-memory, path-style S3 endpoint
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]fakeObject
+	paths   []string
+}
+
+type fakeObject struct {
+	body        string
+	contentType string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") ||
+		r.Header.Get("X-Amz-Content-Sha256") == "" || r.Header.Get("X-Amz-Date") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paths = append(f.paths, r.URL.EscapedPath())
+	obj, ok := f.objects[r.URL.Path]
+
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = fakeObject{body: string(body), contentType: r.Header.Get("Content-Type")}
+	case http.MethodGet, http.MethodHead:
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, "<Error><Code>NoSuchKey</Code></Error>")
+			return
+		}
+		w.Header().Set("Content-Type", obj.contentType)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Length", strconv.Itoa(len(obj.body)))
+		if r.Method == http.MethodGet {
+			_, _ = io.WriteString(w, obj.body)
+		}
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3Storage(t *testing.T) {
+	fake := &fakeS3{objects: map[string]fakeObject{}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	s, err := NewS3Storage(S3Config{
+		Endpoint:  srv.URL,
+		Bucket:    "files",
+		AccessKey: "key",
+		SecretKey: "secret",
+		Prefix:    "/public/",
+		PathStyle: true,
+	})
+	require.NoError(t, err)
+	testStorage(t, s)
+
+	assert.Equal(t, "/files/public/docs/a.txt", fake.paths[0])
+}
+
+func TestS3Storage_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, "<Error><Code>AccessDenied</Code><Message>denied</Message></Error>")
+	}))
+	defer srv.Close()
+
+	s, err := NewS3Storage(S3Config{Endpoint: srv.URL, Bucket: "b", AccessKey: "k", SecretKey: "s", PathStyle: true})
+	require.NoError(t, err)
+
+	err = s.Put(context.Background(), "a.txt", strings.NewReader("x"), 1, "")
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrNotFound))
+	assert.Contains(t, err.Error(), "AccessDenied")
+}
+
+func TestS3Storage_VirtualHostedStyle(t *testing.T) {
+	s, err := NewS3Storage(S3Config{Endpoint: "https://s3.ap-east-1.amazonaws.com", Bucket: "files", AccessKey: "k", SecretKey: "s"})
+	require.NoError(t, err)
+
+	req, err := s.newRequest(context.Background(), http.MethodGet, "dir/a b+c.png", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "https://files.s3.ap-east-1.amazonaws.com/dir/a%20b%2Bc.png", req.URL.String())
+}
+
+func TestSignV4_ReferenceVector(t *testing.T) {
+	// get-vanilla from the AWS SigV4 test suite
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	require.NoError(t, err)
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	signV4(req, emptySHA256, "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "us-east-1", "service", now)
+
+	assert.Equal(t,
+		"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+			"SignedHeaders=host;x-amz-date, "+
+			"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		req.Header.Get("Authorization"))
+}
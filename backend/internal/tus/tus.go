@@ -0,0 +1,163 @@
+// Package tus implements the wire format of the tus resumable upload protocol
+//
+// File: tus.go
+// Description: Protocol headers, Upload-Metadata and Upload-Checksum parsing
+//
+// Only the protocol lives here: uploads are stored by
+// service.ResumableUploadService and served by handlers.TusHandler.
+// Spec: https://tus.io/protocols/resumable-upload (version 1.0.0) with the
+// creation, creation-with-upload, termination, checksum and expiration
+// extensions.
+package tus
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Version is the only protocol version served
+const Version = "1.0.0"
+
+// Extensions is the Tus-Extension value
+const Extensions = "creation,creation-with-upload,termination,checksum,expiration"
+
+// OffsetContentType is the required Content-Type of PATCH bodies
+const OffsetContentType = "application/offset+octet-stream"
+
+// StatusChecksumMismatch is the response status for a body that does not
+// match its Upload-Checksum
+const StatusChecksumMismatch = 460
+
+// Header names
+const (
+	HeaderResumable         = "Tus-Resumable"
+	HeaderVersion           = "Tus-Version"
+	HeaderExtension         = "Tus-Extension"
+	HeaderMaxSize           = "Tus-Max-Size"
+	HeaderChecksumAlgorithm = "Tus-Checksum-Algorithm"
+	HeaderUploadLength      = "Upload-Length"
+	HeaderUploadOffset      = "Upload-Offset"
+	HeaderUploadMetadata    = "Upload-Metadata"
+	HeaderUploadChecksum    = "Upload-Checksum"
+	HeaderUploadExpires     = "Upload-Expires"
+	HeaderUploadDeferLength = "Upload-Defer-Length"
+)
+
+// Common errors
+var (
+	ErrInvalidMetadata      = errors.New("tus: invalid Upload-Metadata")
+	ErrInvalidChecksum      = errors.New("tus: invalid Upload-Checksum")
+	ErrUnsupportedAlgorithm = errors.New("tus: unsupported checksum algorithm")
+)
+
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+}
+
+// ChecksumAlgorithms is the Tus-Checksum-Algorithm value
+func ChecksumAlgorithms() string {
+	names := make([]string, 0, len(checksumAlgorithms))
+	for name := range checksumAlgorithms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// ParseSize parses an Upload-Length or Upload-Offset value: a non-negative
+// decimal integer without sign or spaces
+func ParseSize(s string) (int64, error) {
+	if s == "" || strings.TrimLeft(s, "0123456789") != "" {
+		return 0, fmt.Errorf("tus: invalid size %q", s)
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("tus: invalid size %q", s)
+	}
+	return n, nil
+}
+
+// ParseMetadata parses "filename d29ybGQ=,is_confidential". Values are
+// base64 and may be omitted; keys must be unique.
+func ParseMetadata(header string) (map[string]string, error) {
+	meta := map[string]string{}
+	if strings.TrimSpace(header) == "" {
+		return meta, nil
+	}
+	for _, pair := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidMetadata, pair)
+		}
+		if _, dup := meta[key]; dup {
+			return nil, fmt.Errorf("%w: duplicate key %q", ErrInvalidMetadata, key)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s is not base64", ErrInvalidMetadata, key)
+		}
+		meta[key] = string(decoded)
+	}
+	return meta, nil
+}
+
+// EncodeMetadata is the inverse of ParseMetadata, with the keys sorted
+func EncodeMetadata(meta map[string]string) string {
+	keys := make([]string, 0, len(meta))
+	for k := range meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k
+		if meta[k] != "" {
+			pairs[i] += " " + base64.StdEncoding.EncodeToString([]byte(meta[k]))
+		}
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Checksum is a parsed Upload-Checksum header
+type Checksum struct {
+	Algorithm string
+	Sum       []byte
+}
+
+// ParseChecksum parses "sha1 Kq5sNclPz7QV2+lfQIuc6R7oRu0="
+func ParseChecksum(header string) (Checksum, error) {
+	alg, sum, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok {
+		return Checksum{}, ErrInvalidChecksum
+	}
+	if _, known := checksumAlgorithms[alg]; !known {
+		return Checksum{}, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, alg)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(sum))
+	if err != nil || len(decoded) != checksumAlgorithms[alg]().Size() {
+		return Checksum{}, ErrInvalidChecksum
+	}
+	return Checksum{Algorithm: alg, Sum: decoded}, nil
+}
+
+// Matches reports whether data hashes to the checksum
+func (c Checksum) Matches(data []byte) bool {
+	newHash, ok := checksumAlgorithms[c.Algorithm]
+	if !ok {
+		return false
+	}
+	h := newHash()
+	h.Write(data)
+	return bytes.Equal(h.Sum(nil), c.Sum)
+}
@@ -0,0 +1,62 @@
+// Package tus implements the wire format of the tus resumable upload protocol
+//
+// File: tus_test.go
+// Description: Unit tests for header parsing
+package tus
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSize(t *testing.T) {
+	for in, want := range map[string]int64{"0": 0, "12345": 12345, "007": 7} {
+		got, err := ParseSize(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	for _, in := range []string{"", "-1", "+1", " 1", "1e3", "99999999999999999999"} {
+		_, err := ParseSize(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestParseMetadata(t *testing.T) {
+	meta, err := ParseMetadata("filename d29ybGRfZG9taW5hdGlvbl9wbGFuLnBkZg==, is_confidential")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"filename": "world_domination_plan.pdf", "is_confidential": ""}, meta)
+
+	meta, err = ParseMetadata("")
+	require.NoError(t, err)
+	assert.Empty(t, meta)
+
+	for _, bad := range []string{"filename !!!", "a YQ==,a Yg==", ",", "filename YQ==,"} {
+		_, err := ParseMetadata(bad)
+		assert.ErrorIs(t, err, ErrInvalidMetadata, bad)
+	}
+
+	round, err := ParseMetadata(EncodeMetadata(map[string]string{"filename": "тайлан.pdf", "flag": ""}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"filename": "тайлан.pdf", "flag": ""}, round)
+}
+
+func TestParseChecksum(t *testing.T) {
+	sum := sha1.Sum([]byte("hello"))
+	c, err := ParseChecksum("sha1 " + base64.StdEncoding.EncodeToString(sum[:]))
+	require.NoError(t, err)
+	assert.Equal(t, "sha1", c.Algorithm)
+	assert.True(t, c.Matches([]byte("hello")))
+	assert.False(t, c.Matches([]byte("hellO")))
+
+	_, err = ParseChecksum("crc32 AAAAAA==")
+	assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+	for _, bad := range []string{"", "sha1", "sha1 !!!", "sha256 " + base64.StdEncoding.EncodeToString(sum[:])} {
+		_, err := ParseChecksum(bad)
+		assert.ErrorIs(t, err, ErrInvalidChecksum, bad)
+	}
+	assert.Equal(t, "md5,sha1,sha256", ChecksumAlgorithms())
+}
@@ -0,0 +1,152 @@
+// Package upload validates and scans uploaded files before they are stored
+//
+// File: clamav.go
+// Description: ClamAV clamd scanner over the INSTREAM protocol
+package upload
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// clamChunkSize must stay below clamd's StreamMaxLength chunking limits
+const clamChunkSize = 64 << 10
+
+// ClamAVScanner streams files to a clamd daemon
+type ClamAVScanner struct {
+	network string
+	addr    string
+	timeout time.Duration
+}
+
+// NewClamAVScanner creates a clamd scanner. addr is "tcp://host:3310",
+// "host:3310" or "unix:///var/run/clamav/clamd.ctl".
+func NewClamAVScanner(addr string, timeout time.Duration) *ClamAVScanner {
+	network := "tcp"
+	switch {
+	case strings.HasPrefix(addr, "unix://"):
+		network, addr = "unix", strings.TrimPrefix(addr, "unix://")
+	case strings.HasPrefix(addr, "tcp://"):
+		addr = strings.TrimPrefix(addr, "tcp://")
+	}
+	return &ClamAVScanner{network: network, addr: addr, timeout: timeout}
+}
+
+// Ping checks that clamd is reachable
+func (s *ClamAVScanner) Ping(ctx context.Context) error {
+	reply, err := s.command(ctx, func(w io.Writer) error {
+		_, err := io.WriteString(w, "zPING\x00")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("upload: clamd ping: unexpected reply %q", reply)
+	}
+	return nil
+}
+
+// Scan implements Scanner using INSTREAM
+func (s *ClamAVScanner) Scan(ctx context.Context, r io.Reader) (ScanResult, error) {
+	reply, err := s.command(ctx, func(w io.Writer) error {
+		if _, err := io.WriteString(w, "zINSTREAM\x00"); err != nil {
+			return err
+		}
+		buf := make([]byte, clamChunkSize)
+		size := make([]byte, 4)
+		for {
+			n, rerr := r.Read(buf)
+			if n > 0 {
+				binary.BigEndian.PutUint32(size, uint32(n))
+				if _, err := w.Write(size); err != nil {
+					return err
+				}
+				if _, err := w.Write(buf[:n]); err != nil {
+					return err
+				}
+			}
+			if errors.Is(rerr, io.EOF) {
+				break
+			}
+			if rerr != nil {
+				return sourceError{rerr}
+			}
+		}
+		_, err := w.Write([]byte{0, 0, 0, 0})
+		return err
+	})
+	if err != nil {
+		return ScanResult{}, err
+	}
+	return parseClamReply(reply)
+}
+
+// command sends one z-prefixed command and reads the NUL-terminated reply
+func (s *ClamAVScanner) command(ctx context.Context, send func(io.Writer) error) (string, error) {
+	d := net.Dialer{Timeout: s.timeout}
+	conn, err := d.DialContext(ctx, s.network, s.addr)
+	if err != nil {
+		return "", fmt.Errorf("upload: clamd dial: %w", err)
+	}
+	defer conn.Close()
+
+	var deadline time.Time
+	if s.timeout > 0 {
+		deadline = time.Now().Add(s.timeout)
+	}
+	if d, ok := ctx.Deadline(); ok && (deadline.IsZero() || d.Before(deadline)) {
+		deadline = d
+	}
+	if !deadline.IsZero() {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	w := bufio.NewWriterSize(conn, clamChunkSize+4)
+	sendErr := send(w)
+	var srcErr sourceError
+	if errors.As(sendErr, &srcErr) {
+		return "", fmt.Errorf("upload: read file for scan: %w", srcErr.err)
+	}
+	if sendErr == nil {
+		sendErr = w.Flush()
+	}
+
+	// clamd replies and closes early on errors such as the size limit,
+	// so try to read its reason even when sending failed
+	reply, err := bufio.NewReader(conn).ReadBytes(0)
+	if len(reply) == 0 {
+		if sendErr != nil {
+			return "", fmt.Errorf("upload: clamd send: %w", sendErr)
+		}
+		return "", fmt.Errorf("upload: clamd read: %w", err)
+	}
+	return string(bytes.TrimRight(reply, "\x00\n")), nil
+}
+
+// sourceError marks a failure reading the scanned file (not clamd's fault)
+type sourceError struct{ err error }
+
+func (e sourceError) Error() string { return e.err.Error() }
+
+// parseClamReply maps "stream: OK", "stream: <name> FOUND" and
+// "<message> ERROR" replies
+func parseClamReply(reply string) (ScanResult, error) {
+	body := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case body == "OK":
+		return ScanResult{}, nil
+	case strings.HasSuffix(body, " FOUND"):
+		return ScanResult{Infected: true, Signature: strings.TrimSuffix(body, " FOUND")}, nil
+	default:
+		return ScanResult{}, fmt.Errorf("upload: clamd: %s", reply)
+	}
+}
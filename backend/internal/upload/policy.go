@@ -0,0 +1,170 @@
+// Package upload validates and scans uploaded files before they are stored
+//
+// File: policy.go
+// Description: Content type allowlist, per-type size limits and extension checks
+package upload
+
+import (
+	"fmt"
+	"mime"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// sniffLen is how many leading bytes are used for magic-byte detection
+const sniffLen = 3072
+
+// knownExtensions lists the accepted filename extensions per detected type.
+// The first entry is used when the client sends no extension.
+var knownExtensions = map[string][]string{
+	"image/png":       {".png"},
+	"image/jpeg":      {".jpg", ".jpeg", ".jfif"},
+	"image/gif":       {".gif"},
+	"image/webp":      {".webp"},
+	"image/bmp":       {".bmp"},
+	"image/heic":      {".heic"},
+	"image/avif":      {".avif"},
+	"image/svg+xml":   {".svg"},
+	"application/pdf": {".pdf"},
+	"application/zip": {".zip"},
+	"text/plain":      {".txt", ".log"},
+	"text/csv":        {".csv"},
+	"video/mp4":       {".mp4", ".m4v"},
+	"video/webm":      {".webm"},
+	"audio/mpeg":      {".mp3"},
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   {".docx"},
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         {".xlsx"},
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": {".pptx"},
+}
+
+// TypeRule allows one content type up to MaxSize bytes
+type TypeRule struct {
+	ContentType string
+	MaxSize     int64
+}
+
+// Policy is the upload allowlist
+type Policy struct {
+	rules map[string]TypeRule
+}
+
+// NewPolicy creates a policy from rules; a rule with MaxSize <= 0 gets defaultMax
+func NewPolicy(defaultMax int64, rules ...TypeRule) *Policy {
+	p := &Policy{rules: make(map[string]TypeRule, len(rules))}
+	for _, r := range rules {
+		r.ContentType = strings.ToLower(strings.TrimSpace(r.ContentType))
+		if r.MaxSize <= 0 {
+			r.MaxSize = defaultMax
+		}
+		p.rules[r.ContentType] = r
+	}
+	return p
+}
+
+// MaxSize is the largest limit of any allowed type. Resumable uploads use it
+// to refuse an upload that can never pass Check before any byte is sent.
+func (p *Policy) MaxSize() int64 {
+	var max int64
+	for _, r := range p.rules {
+		if r.MaxSize > max {
+			max = r.MaxSize
+		}
+	}
+	return max
+}
+
+// ParseTypeRules parses "image/png:5MB,application/pdf:20MB,text/plain".
+// Entries without a limit use the policy default.
+func ParseTypeRules(spec []string) ([]TypeRule, error) {
+	var rules []TypeRule
+	for _, entry := range spec {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		ct, limit, hasLimit := strings.Cut(entry, ":")
+		rule := TypeRule{ContentType: ct}
+		if hasLimit {
+			n, err := ParseSize(limit)
+			if err != nil {
+				return nil, fmt.Errorf("upload: %s: %w", entry, err)
+			}
+			rule.MaxSize = n
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// ParseSize parses a byte size like "512", "300KB", "5MB" or "1GB"
+func ParseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	mult := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		mult   int64
+	}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"B", 1}} {
+		if strings.HasSuffix(s, unit.suffix) {
+			s, mult = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix)), unit.mult
+			break
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n * mult, nil
+}
+
+// Check validates the sniffed head of a file against the allowlist and
+// returns the detected content type and the extension to store it with
+func (p *Policy) Check(filename string, size int64, head []byte) (contentType, ext string, err error) {
+	// Exact match only: parent types would let e.g. text/html in under text/plain
+	detected := mimetype.Detect(head)
+	contentType = baseType(detected.String())
+	rule, ok := p.rules[contentType]
+	if !ok {
+		return "", "", &RejectedError{Err: ErrTypeNotAllowed, Detail: contentType}
+	}
+	if size > rule.MaxSize {
+		return "", "", &RejectedError{Err: ErrTooLarge, Detail: fmt.Sprintf("%s files are limited to %d bytes", rule.ContentType, rule.MaxSize)}
+	}
+
+	allowed := extensionsFor(contentType, detected)
+	ext = strings.ToLower(filepath.Ext(filename))
+	if ext == "" {
+		return contentType, allowed[0], nil
+	}
+	if !slices.Contains(allowed, ext) {
+		return "", "", &RejectedError{Err: ErrExtensionMismatch, Detail: fmt.Sprintf("%s content cannot be stored as %s", contentType, ext)}
+	}
+	return contentType, ext, nil
+}
+
+// extensionsFor returns the accepted extensions for a detected type
+func extensionsFor(contentType string, detected *mimetype.MIME) []string {
+	if exts, ok := knownExtensions[contentType]; ok {
+		return exts
+	}
+	var exts []string
+	if e := detected.Extension(); e != "" {
+		exts = append(exts, e)
+	}
+	if more, _ := mime.ExtensionsByType(contentType); len(more) > 0 {
+		exts = append(exts, more...)
+	}
+	if len(exts) == 0 {
+		exts = []string{".bin"}
+	}
+	return exts
+}
+
+// baseType drops MIME parameters ("text/plain; charset=utf-8" → "text/plain")
+func baseType(ct string) string {
+	base, _, _ := strings.Cut(ct, ";")
+	return strings.ToLower(strings.TrimSpace(base))
+}
@@ -0,0 +1,178 @@
+// Package upload validates and scans uploaded files before they are stored
+//
+// File: upload.go
+// Description: Guard that runs the type policy and malware scan on an upload
+//
+// This package provides:
+//   - Policy: magic-byte content type allowlist with per-type size limits
+//     and extension checks (the client filename is not trusted)
+//   - Scanner interface with a ClamAV (clamd) implementation
+//   - Guard: runs both and quarantines infected files
+//
+// Usage:
+//
+//	g := upload.NewGuard(policy, upload.NewClamAVScanner("tcp://clamd:3310", 30*time.Second), quarantine)
+//	info, err := g.Inspect(ctx, header.Filename, header.Size, file)
+//	if errors.Is(err, upload.ErrRejected) { ... 400 ... }
+package upload
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"templatev25/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// Common errors. Every rejection also matches ErrRejected.
+var (
+	ErrRejected          = errors.New("upload: file rejected")
+	ErrEmpty             = errors.New("file is empty")
+	ErrTypeNotAllowed    = errors.New("file type is not allowed")
+	ErrTooLarge          = errors.New("file is too large")
+	ErrExtensionMismatch = errors.New("file extension does not match its content")
+	ErrInfected          = errors.New("file is infected")
+)
+
+// RejectedError is a policy rejection with a client-facing detail
+type RejectedError struct {
+	Err    error
+	Detail string
+}
+
+func (e *RejectedError) Error() string {
+	if e.Detail == "" {
+		return e.Err.Error()
+	}
+	return e.Err.Error() + ": " + e.Detail
+}
+
+func (e *RejectedError) Unwrap() []error { return []error{ErrRejected, e.Err} }
+
+// InfectedError is returned when the scanner found malware. The file was
+// moved to quarantine under QuarantineKey (empty when quarantine is off).
+type InfectedError struct {
+	Signature     string
+	QuarantineKey string
+}
+
+func (e *InfectedError) Error() string {
+	return ErrInfected.Error() + ": " + e.Signature
+}
+
+func (e *InfectedError) Unwrap() []error { return []error{ErrRejected, ErrInfected} }
+
+// Inspection is the verdict for an accepted file
+type Inspection struct {
+	// ContentType is detected from the file contents
+	ContentType string
+	// Ext is the lower-case extension to store the file with
+	Ext string
+}
+
+// quarantineRecord is written next to a quarantined file
+type quarantineRecord struct {
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Signature   string    `json:"signature"`
+	DetectedAt  time.Time `json:"detected_at"`
+}
+
+// Guard checks uploads before they reach storage
+type Guard struct {
+	policy     *Policy
+	scanner    Scanner
+	quarantine storage.Storage
+}
+
+// NewGuard creates a guard. scanner may be nil (no scanning); quarantine may
+// be nil, then infected files are only rejected.
+func NewGuard(policy *Policy, scanner Scanner, quarantine storage.Storage) *Guard {
+	if scanner == nil {
+		scanner = NoopScanner{}
+	}
+	return &Guard{policy: policy, scanner: scanner, quarantine: quarantine}
+}
+
+// MaxSize is the largest file any allowed type accepts
+func (g *Guard) MaxSize() int64 { return g.policy.MaxSize() }
+
+// Inspect validates the file type and size, then scans the contents.
+// f is rewound to the start when the file is accepted.
+func (g *Guard) Inspect(ctx context.Context, filename string, size int64, f io.ReadSeeker) (Inspection, error) {
+	if size == 0 {
+		return Inspection{}, &RejectedError{Err: ErrEmpty}
+	}
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(f, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return Inspection{}, fmt.Errorf("upload: read head: %w", err)
+	}
+	contentType, ext, err := g.policy.Check(filename, size, head[:n])
+	if err != nil {
+		return Inspection{}, err
+	}
+
+	if err := rewind(f); err != nil {
+		return Inspection{}, err
+	}
+	result, err := g.scanner.Scan(ctx, f)
+	if err != nil {
+		return Inspection{}, err
+	}
+	if err := rewind(f); err != nil {
+		return Inspection{}, err
+	}
+
+	if result.Infected {
+		infected := &InfectedError{Signature: result.Signature}
+		if g.quarantine != nil {
+			key, err := g.quarantineFile(ctx, f, filename, contentType, ext, size, result.Signature)
+			if err != nil {
+				return Inspection{}, fmt.Errorf("upload: quarantine: %w", err)
+			}
+			infected.QuarantineKey = key
+		}
+		return Inspection{}, infected
+	}
+	return Inspection{ContentType: contentType, Ext: ext}, nil
+}
+
+// quarantineFile stores the file and a JSON record for later review
+func (g *Guard) quarantineFile(ctx context.Context, f io.Reader, filename, contentType, ext string, size int64, signature string) (string, error) {
+	now := time.Now().UTC()
+	key := now.Format("20060102") + "/" + uuid.NewString() + ext
+	if err := g.quarantine.Put(ctx, key, f, size, contentType); err != nil {
+		return "", err
+	}
+
+	record, err := json.Marshal(quarantineRecord{
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+		Signature:   signature,
+		DetectedAt:  now,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := g.quarantine.Put(ctx, key+".json", bytes.NewReader(record), int64(len(record)), "application/json"); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+func rewind(f io.Seeker) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("upload: rewind: %w", err)
+	}
+	return nil
+}
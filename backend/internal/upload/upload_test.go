@@ -0,0 +1,255 @@
+// Package upload validates and scans uploaded files before they are stored
+//
+// File: upload_test.go
+// Description: Unit tests for upload policy, guard and clamd scanner
+package upload
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"templatev25/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	pngHead  = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+	jpegHead = "\xff\xd8\xff\xe0\x00\x10JFIF\x00"
+	pdfHead  = "%PDF-1.7\n"
+)
+
+func testPolicy() *Policy {
+	return NewPolicy(1024,
+		TypeRule{ContentType: "image/png"},
+		TypeRule{ContentType: "image/jpeg", MaxSize: 10},
+		TypeRule{ContentType: "text/plain"},
+	)
+}
+
+func TestPolicy_Check(t *testing.T) {
+	p := testPolicy()
+
+	ct, ext, err := p.Check("logo.PNG", 100, []byte(pngHead))
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", ct)
+	assert.Equal(t, ".png", ext)
+
+	_, ext, err = p.Check("no-extension", 100, []byte(pngHead))
+	require.NoError(t, err)
+	assert.Equal(t, ".png", ext)
+
+	ct, _, err = p.Check("notes.txt", 5, []byte("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", ct)
+
+	tests := []struct {
+		name     string
+		filename string
+		size     int64
+		head     string
+		want     error
+	}{
+		{"extension mismatch", "logo.jpg", 100, pngHead, ErrExtensionMismatch},
+		{"script disguised as image", "logo.png", 100, "<?php echo 1; ?>", ErrTypeNotAllowed},
+		{"text disguised as image", "logo.png", 100, "plain words", ErrExtensionMismatch},
+		{"type not allowed", "doc.pdf", 100, pdfHead, ErrTypeNotAllowed},
+		{"html is not text/plain", "a.txt", 100, "<html><body>x</body></html>", ErrTypeNotAllowed},
+		{"per-type limit", "a.jpg", 11, jpegHead, ErrTooLarge},
+		{"default limit", "a.png", 1025, pngHead, ErrTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := p.Check(tt.filename, tt.size, []byte(tt.head))
+			assert.ErrorIs(t, err, tt.want)
+			assert.ErrorIs(t, err, ErrRejected)
+		})
+	}
+}
+
+func TestPolicy_MaxSize(t *testing.T) {
+	assert.Equal(t, int64(1024), testPolicy().MaxSize())
+	assert.Equal(t, int64(1<<30), NewPolicy(1024, TypeRule{ContentType: "video/mp4", MaxSize: 1 << 30}).MaxSize())
+	assert.Zero(t, NewPolicy(1024).MaxSize(), "nothing allowed")
+}
+
+func TestParseSize(t *testing.T) {
+	for in, want := range map[string]int64{"512": 512, "300KB": 300 << 10, "5mb": 5 << 20, "1 GB": 1 << 30, "10B": 10} {
+		got, err := ParseSize(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	for _, in := range []string{"", "MB", "-1MB", "ten"} {
+		_, err := ParseSize(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestParseTypeRules(t *testing.T) {
+	rules, err := ParseTypeRules([]string{"image/png:1MB", " application/pdf ", ""})
+	require.NoError(t, err)
+	assert.Equal(t, []TypeRule{
+		{ContentType: "image/png", MaxSize: 1 << 20},
+		{ContentType: "application/pdf"},
+	}, rules)
+
+	_, err = ParseTypeRules([]string{"image/png:big"})
+	assert.Error(t, err)
+}
+
+type fixedScanner struct {
+	result ScanResult
+	err    error
+	read   []byte
+}
+
+func (s *fixedScanner) Scan(_ context.Context, r io.Reader) (ScanResult, error) {
+	s.read, _ = io.ReadAll(r)
+	return s.result, s.err
+}
+
+func TestGuard_Inspect(t *testing.T) {
+	scanner := &fixedScanner{}
+	g := NewGuard(testPolicy(), scanner, nil)
+	f := strings.NewReader(pngHead + "rest-of-image")
+
+	info, err := g.Inspect(context.Background(), "a.png", f.Size(), f)
+	require.NoError(t, err)
+	assert.Equal(t, Inspection{ContentType: "image/png", Ext: ".png"}, info)
+	assert.Equal(t, pngHead+"rest-of-image", string(scanner.read), "scanner sees the whole file")
+
+	rest, _ := io.ReadAll(f)
+	assert.Equal(t, pngHead+"rest-of-image", string(rest), "file is rewound")
+
+	_, err = g.Inspect(context.Background(), "a.png", 0, strings.NewReader(""))
+	assert.ErrorIs(t, err, ErrEmpty)
+}
+
+func TestGuard_ScannerErrorFailsClosed(t *testing.T) {
+	g := NewGuard(testPolicy(), &fixedScanner{err: errors.New("clamd down")}, nil)
+	_, err := g.Inspect(context.Background(), "a.png", int64(len(pngHead)), strings.NewReader(pngHead))
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrRejected))
+}
+
+func TestGuard_QuarantinesInfected(t *testing.T) {
+	dir := t.TempDir()
+	quarantine, err := storage.NewLocalStorage(dir)
+	require.NoError(t, err)
+	g := NewGuard(testPolicy(), &fixedScanner{result: ScanResult{Infected: true, Signature: "Eicar-Test-Signature"}}, quarantine)
+
+	_, err = g.Inspect(context.Background(), "evil.png", int64(len(pngHead)), strings.NewReader(pngHead))
+	var infected *InfectedError
+	require.ErrorAs(t, err, &infected)
+	assert.ErrorIs(t, err, ErrRejected)
+	assert.Equal(t, "Eicar-Test-Signature", infected.Signature)
+
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(infected.QuarantineKey)))
+	require.NoError(t, err)
+	assert.Equal(t, pngHead, string(data))
+
+	raw, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(infected.QuarantineKey+".json")))
+	require.NoError(t, err)
+	var record quarantineRecord
+	require.NoError(t, json.Unmarshal(raw, &record))
+	assert.Equal(t, "evil.png", record.Filename)
+	assert.Equal(t, "Eicar-Test-Signature", record.Signature)
+}
+
+// fakeClamd answers INSTREAM with reply(received bytes) on a TCP listener
+func fakeClamd(t *testing.T, reply func(data []byte) string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				cmd, err := r.ReadString(0)
+				if err != nil {
+					return
+				}
+				switch cmd {
+				case "zPING\x00":
+					_, _ = io.WriteString(conn, "PONG\x00")
+				case "zINSTREAM\x00":
+					var data bytes.Buffer
+					size := make([]byte, 4)
+					for {
+						if _, err := io.ReadFull(r, size); err != nil {
+							return
+						}
+						n := binary.BigEndian.Uint32(size)
+						if n == 0 {
+							break
+						}
+						if _, err := io.CopyN(&data, r, int64(n)); err != nil {
+							return
+						}
+					}
+					_, _ = io.WriteString(conn, reply(data.Bytes())+"\x00")
+				}
+			}(conn)
+		}
+	}()
+	return "tcp://" + ln.Addr().String()
+}
+
+func TestClamAVScanner(t *testing.T) {
+	addr := fakeClamd(t, func(data []byte) string {
+		switch {
+		case bytes.Contains(data, []byte("EICAR")):
+			return "stream: Eicar-Test-Signature FOUND"
+		case len(data) > 100<<10:
+			return "INSTREAM size limit exceeded. ERROR"
+		}
+		return "stream: OK"
+	})
+	s := NewClamAVScanner(addr, 5*time.Second)
+	ctx := context.Background()
+
+	require.NoError(t, s.Ping(ctx))
+
+	res, err := s.Scan(ctx, strings.NewReader("clean file"))
+	require.NoError(t, err)
+	assert.False(t, res.Infected)
+
+	// Spans several INSTREAM chunks
+	big := strings.Repeat("a", clamChunkSize+10) + "EICAR"
+	res, err = s.Scan(ctx, strings.NewReader(big))
+	require.NoError(t, err)
+	assert.True(t, res.Infected)
+	assert.Equal(t, "Eicar-Test-Signature", res.Signature)
+
+	_, err = s.Scan(ctx, bytes.NewReader(make([]byte, 200<<10)))
+	assert.ErrorContains(t, err, "size limit exceeded")
+}
+
+func TestClamAVScanner_Unreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	_, err = NewClamAVScanner(addr, time.Second).Scan(context.Background(), strings.NewReader("x"))
+	assert.ErrorContains(t, err, "clamd dial")
+}
@@ -0,0 +1,169 @@
+// Package webauthn implements the relying party side of WebAuthn ceremonies
+//
+// File: cbor.go
+// Description: Minimal CBOR decoder for attestation objects and COSE keys
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// CBOR major types
+const (
+	cborUint   = 0
+	cborNegInt = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborTag    = 6
+	cborSimple = 7
+)
+
+const (
+	// cborMaxDepth bounds nesting so hostile input cannot exhaust the stack
+	cborMaxDepth = 16
+	// cborMaxItems bounds array/map sizes
+	cborMaxItems = 1024
+)
+
+var errCBORTruncated = errors.New("webauthn: truncated CBOR data")
+
+// decodeCBOR decodes one CBOR data item and returns it with the unread remainder.
+//
+// Supported values: unsigned/negative integers (int64), byte strings ([]byte),
+// text strings (string), arrays ([]any), maps (map[any]any with int64 or
+// string keys), booleans and null. Indefinite lengths, tags and floats are
+// rejected; authenticators encode attestation data in canonical CBOR.
+func decodeCBOR(data []byte) (any, []byte, error) {
+	return decodeCBORItem(data, 0)
+}
+
+func decodeCBORItem(data []byte, depth int) (any, []byte, error) {
+	if depth > cborMaxDepth {
+		return nil, nil, errors.New("webauthn: CBOR nesting too deep")
+	}
+	if len(data) == 0 {
+		return nil, nil, errCBORTruncated
+	}
+
+	major := data[0] >> 5
+	info := data[0] & 0x1f
+
+	if major == cborSimple {
+		switch info {
+		case 20:
+			return false, data[1:], nil
+		case 21:
+			return true, data[1:], nil
+		case 22:
+			return nil, data[1:], nil
+		default:
+			return nil, nil, fmt.Errorf("webauthn: unsupported CBOR simple value %d", info)
+		}
+	}
+
+	arg, rest, err := cborArgument(data[1:], info)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch major {
+	case cborUint:
+		if arg > math.MaxInt64 {
+			return nil, nil, errors.New("webauthn: CBOR integer overflow")
+		}
+		return int64(arg), rest, nil
+
+	case cborNegInt:
+		if arg > math.MaxInt64 {
+			return nil, nil, errors.New("webauthn: CBOR integer overflow")
+		}
+		return -1 - int64(arg), rest, nil
+
+	case cborBytes, cborText:
+		if arg > uint64(len(rest)) {
+			return nil, nil, errCBORTruncated
+		}
+		b := rest[:arg]
+		if major == cborText {
+			return string(b), rest[arg:], nil
+		}
+		return append([]byte(nil), b...), rest[arg:], nil
+
+	case cborArray:
+		if arg > cborMaxItems {
+			return nil, nil, errors.New("webauthn: CBOR array too large")
+		}
+		items := make([]any, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			var v any
+			v, rest, err = decodeCBORItem(rest, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			items = append(items, v)
+		}
+		return items, rest, nil
+
+	case cborMap:
+		if arg > cborMaxItems {
+			return nil, nil, errors.New("webauthn: CBOR map too large")
+		}
+		m := make(map[any]any, arg)
+		for i := uint64(0); i < arg; i++ {
+			var k, v any
+			k, rest, err = decodeCBORItem(rest, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			switch k.(type) {
+			case int64, string:
+			default:
+				return nil, nil, errors.New("webauthn: unsupported CBOR map key")
+			}
+			v, rest, err = decodeCBORItem(rest, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			m[k] = v
+		}
+		return m, rest, nil
+
+	default:
+		return nil, nil, fmt.Errorf("webauthn: unsupported CBOR major type %d", major)
+	}
+}
+
+// cborArgument reads the length/value argument that follows the initial byte
+func cborArgument(data []byte, info byte) (uint64, []byte, error) {
+	switch {
+	case info < 24:
+		return uint64(info), data, nil
+	case info == 24:
+		if len(data) < 1 {
+			return 0, nil, errCBORTruncated
+		}
+		return uint64(data[0]), data[1:], nil
+	case info == 25:
+		if len(data) < 2 {
+			return 0, nil, errCBORTruncated
+		}
+		return uint64(binary.BigEndian.Uint16(data)), data[2:], nil
+	case info == 26:
+		if len(data) < 4 {
+			return 0, nil, errCBORTruncated
+		}
+		return uint64(binary.BigEndian.Uint32(data)), data[4:], nil
+	case info == 27:
+		if len(data) < 8 {
+			return 0, nil, errCBORTruncated
+		}
+		return binary.BigEndian.Uint64(data), data[8:], nil
+	default:
+		return 0, nil, errors.New("webauthn: indefinite-length CBOR is not supported")
+	}
+}
@@ -0,0 +1,170 @@
+// Package webauthn implements the relying party side of WebAuthn ceremonies
+//
+// File: cose.go
+// Description: COSE_Key parsing and signature verification
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// COSE algorithm identifiers (IANA COSE Algorithms registry)
+const (
+	AlgES256 int64 = -7
+	AlgEdDSA int64 = -8
+	AlgES384 int64 = -35
+	AlgES512 int64 = -36
+	AlgRS256 int64 = -257
+)
+
+// SupportedAlgorithms is the pubKeyCredParams list offered at registration,
+// in order of preference
+var SupportedAlgorithms = []int64{AlgES256, AlgEdDSA, AlgES384, AlgES512, AlgRS256}
+
+// COSE key parameters
+const (
+	coseKeyKty = 1
+	coseKeyAlg = 3
+
+	coseKtyOKP = 1
+	coseKtyEC2 = 2
+	coseKtyRSA = 3
+
+	// EC2 / OKP
+	coseKeyCrv = -1
+	coseKeyX   = -2
+	coseKeyY   = -3
+
+	// RSA
+	coseKeyN = -1
+	coseKeyE = -2
+
+	coseCrvP256    = 1
+	coseCrvP384    = 2
+	coseCrvP521    = 3
+	coseCrvEd25519 = 6
+)
+
+// ErrInvalidSignature is returned when an assertion signature does not verify
+var ErrInvalidSignature = errors.New("webauthn: invalid signature")
+
+// PublicKey is a credential public key decoded from its COSE encoding
+type PublicKey struct {
+	Algorithm int64
+	key       crypto.PublicKey
+}
+
+// ParsePublicKey decodes a COSE_Key
+func ParsePublicKey(coseKey []byte) (*PublicKey, error) {
+	v, _, err := decodeCBOR(coseKey)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[any]any)
+	if !ok {
+		return nil, errors.New("webauthn: COSE key is not a map")
+	}
+
+	kty, _ := m[int64(coseKeyKty)].(int64)
+	alg, _ := m[int64(coseKeyAlg)].(int64)
+
+	switch kty {
+	case coseKtyEC2:
+		crv, _ := m[int64(coseKeyCrv)].(int64)
+		x, _ := m[int64(coseKeyX)].([]byte)
+		y, _ := m[int64(coseKeyY)].([]byte)
+
+		var curve elliptic.Curve
+		switch {
+		case alg == AlgES256 && crv == coseCrvP256:
+			curve = elliptic.P256()
+		case alg == AlgES384 && crv == coseCrvP384:
+			curve = elliptic.P384()
+		case alg == AlgES512 && crv == coseCrvP521:
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("webauthn: unsupported EC2 key (alg %d, crv %d)", alg, crv)
+		}
+
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) != size || len(y) != size {
+			return nil, errors.New("webauthn: invalid EC2 key coordinates")
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, errors.New("webauthn: EC2 point is not on curve")
+		}
+		return &PublicKey{Algorithm: alg, key: pub}, nil
+
+	case coseKtyOKP:
+		crv, _ := m[int64(coseKeyCrv)].(int64)
+		x, _ := m[int64(coseKeyX)].([]byte)
+		if alg != AlgEdDSA || crv != coseCrvEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("webauthn: unsupported OKP key (alg %d, crv %d)", alg, crv)
+		}
+		return &PublicKey{Algorithm: alg, key: ed25519.PublicKey(x)}, nil
+
+	case coseKtyRSA:
+		n, _ := m[int64(coseKeyN)].([]byte)
+		e, _ := m[int64(coseKeyE)].([]byte)
+		if alg != AlgRS256 {
+			return nil, fmt.Errorf("webauthn: unsupported RSA algorithm %d", alg)
+		}
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("webauthn: invalid RSA key")
+		}
+		exp := new(big.Int).SetBytes(e)
+		return &PublicKey{Algorithm: alg, key: &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}}, nil
+
+	default:
+		return nil, fmt.Errorf("webauthn: unsupported COSE key type %d", kty)
+	}
+}
+
+// Verify checks sig over data
+func (k *PublicKey) Verify(data, sig []byte) error {
+	switch pub := k.key.(type) {
+	case *ecdsa.PublicKey:
+		var digest []byte
+		switch k.Algorithm {
+		case AlgES256:
+			h := sha256.Sum256(data)
+			digest = h[:]
+		case AlgES384:
+			h := sha512.Sum384(data)
+			digest = h[:]
+		case AlgES512:
+			h := sha512.Sum512(data)
+			digest = h[:]
+		}
+		if !ecdsa.VerifyASN1(pub, digest, sig) {
+			return ErrInvalidSignature
+		}
+		return nil
+
+	case ed25519.PublicKey:
+		if !ed25519.Verify(pub, data, sig) {
+			return ErrInvalidSignature
+		}
+		return nil
+
+	case *rsa.PublicKey:
+		h := sha256.Sum256(data)
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, h[:], sig); err != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("webauthn: unsupported key type %T", k.key)
+	}
+}
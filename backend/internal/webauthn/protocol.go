@@ -0,0 +1,255 @@
+// Package webauthn implements the relying party side of WebAuthn ceremonies
+//
+// File: protocol.go
+// Description: Wire types exchanged with the browser and authenticator data parsing
+package webauthn
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Authenticator data flags (WebAuthn §6.1)
+const (
+	FlagUserPresent    byte = 0x01
+	FlagUserVerified   byte = 0x04
+	FlagBackupEligible byte = 0x08
+	FlagBackedUp       byte = 0x10
+	FlagAttestedData   byte = 0x40
+	FlagExtensionData  byte = 0x80
+)
+
+// Client data types
+const (
+	ceremonyCreate = "webauthn.create"
+	ceremonyGet    = "webauthn.get"
+)
+
+// ============================================================
+// OPTIONS (server → browser)
+// ============================================================
+
+// The option and response types follow the JSON serialization of
+// PublicKeyCredential (toJSON / parseCreationOptionsFromJSON): binary
+// fields are base64url strings without padding.
+
+// RelyingParty identifies the relying party
+type RelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// UserEntity identifies the account a credential is created for
+type UserEntity struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// CredentialParameter is one accepted public key algorithm
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+// CredentialDescriptor references an existing credential
+type CredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+// AuthenticatorSelection states authenticator requirements
+type AuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey,omitempty"`
+	UserVerification string `json:"userVerification,omitempty"`
+}
+
+// CreationOptions is PublicKeyCredentialCreationOptions
+type CreationOptions struct {
+	RP                     RelyingParty           `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	Challenge              string                 `json:"challenge"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout,omitempty"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials,omitempty"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions is PublicKeyCredentialRequestOptions
+type RequestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	Timeout          int64                  `json:"timeout,omitempty"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials,omitempty"`
+	UserVerification string                 `json:"userVerification,omitempty"`
+}
+
+// ============================================================
+// RESPONSES (browser → server)
+// ============================================================
+
+// RegistrationResponse is a PublicKeyCredential returned by navigator.credentials.create()
+type RegistrationResponse struct {
+	ID       string `json:"id"`
+	RawID    string `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string   `json:"clientDataJSON"`
+		AttestationObject string   `json:"attestationObject"`
+		Transports        []string `json:"transports,omitempty"`
+	} `json:"response"`
+}
+
+// AssertionResponse is a PublicKeyCredential returned by navigator.credentials.get()
+type AssertionResponse struct {
+	ID       string `json:"id"`
+	RawID    string `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON"`
+		AuthenticatorData string `json:"authenticatorData"`
+		Signature         string `json:"signature"`
+		UserHandle        string `json:"userHandle,omitempty"`
+	} `json:"response"`
+}
+
+// CredentialID returns the decoded raw credential ID
+func (r *AssertionResponse) CredentialID() ([]byte, error) {
+	return credentialID(r.ID, r.RawID)
+}
+
+func credentialID(id, rawID string) ([]byte, error) {
+	if rawID == "" {
+		rawID = id
+	}
+	b, err := DecodeBase64(rawID)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("webauthn: invalid credential id")
+	}
+	if id != "" {
+		idb, err := DecodeBase64(id)
+		if err != nil || !bytes.Equal(idb, b) {
+			return nil, errors.New("webauthn: credential id mismatch")
+		}
+	}
+	return b, nil
+}
+
+// ============================================================
+// PARSING
+// ============================================================
+
+// clientData is CollectedClientData
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+func parseClientData(raw []byte) (*clientData, error) {
+	var cd clientData
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return nil, fmt.Errorf("webauthn: invalid client data: %w", err)
+	}
+	return &cd, nil
+}
+
+// authenticatorData is the parsed authData structure
+type authenticatorData struct {
+	RPIDHash     []byte
+	Flags        byte
+	SignCount    uint32
+	AAGUID       []byte
+	CredentialID []byte
+	PublicKey    []byte
+}
+
+func (a *authenticatorData) has(flag byte) bool {
+	return a.Flags&flag != 0
+}
+
+func parseAuthenticatorData(raw []byte) (*authenticatorData, error) {
+	if len(raw) < 37 {
+		return nil, errors.New("webauthn: authenticator data too short")
+	}
+
+	ad := &authenticatorData{
+		RPIDHash:  raw[:32],
+		Flags:     raw[32],
+		SignCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+	rest := raw[37:]
+
+	if ad.has(FlagAttestedData) {
+		if len(rest) < 18 {
+			return nil, errors.New("webauthn: attested credential data too short")
+		}
+		ad.AAGUID = rest[:16]
+		idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if idLen == 0 || idLen > 1023 || len(rest) < idLen {
+			return nil, errors.New("webauthn: invalid credential id length")
+		}
+		ad.CredentialID = rest[:idLen]
+		rest = rest[idLen:]
+
+		// The COSE key is the next CBOR item; extensions may follow it
+		_, after, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, fmt.Errorf("webauthn: invalid credential public key: %w", err)
+		}
+		ad.PublicKey = rest[:len(rest)-len(after)]
+		rest = after
+	}
+
+	if ad.has(FlagExtensionData) {
+		var err error
+		if _, rest, err = decodeCBOR(rest); err != nil {
+			return nil, fmt.Errorf("webauthn: invalid extension data: %w", err)
+		}
+	}
+
+	if len(rest) != 0 {
+		return nil, errors.New("webauthn: trailing bytes in authenticator data")
+	}
+	return ad, nil
+}
+
+// parseAttestationObject returns the fmt and authData of an attestation object
+func parseAttestationObject(raw []byte) (string, []byte, error) {
+	v, rest, err := decodeCBOR(raw)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(rest) != 0 {
+		return "", nil, errors.New("webauthn: trailing bytes in attestation object")
+	}
+	m, ok := v.(map[any]any)
+	if !ok {
+		return "", nil, errors.New("webauthn: attestation object is not a map")
+	}
+	format, _ := m["fmt"].(string)
+	authData, _ := m["authData"].([]byte)
+	if format == "" || len(authData) == 0 {
+		return "", nil, errors.New("webauthn: malformed attestation object")
+	}
+	return format, authData, nil
+}
+
+// EncodeBase64 encodes b as unpadded base64url
+func EncodeBase64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeBase64 decodes base64url with or without padding
+func DecodeBase64(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
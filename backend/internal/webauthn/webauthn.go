@@ -0,0 +1,384 @@
+// Package webauthn implements the relying party side of WebAuthn ceremonies
+//
+// File: webauthn.go
+// Description: Registration and assertion ceremonies
+//
+// This package provides:
+//   - Creation/request options for navigator.credentials.create() / get()
+//   - Verification of registration responses (attestation "none")
+//   - Verification of assertions, including signature counter checks
+//
+// Attestation statements are not evaluated: options request attestation
+// "none" and a credential is trusted on first use, the same way a TOTP
+// secret is. Supported algorithms are ES256, ES384, ES512, EdDSA and RS256.
+//
+// Usage:
+//
+//	w, err := webauthn.New(webauthn.Config{RPID: "example.com", RPName: "App", Origins: []string{"https://example.com"}})
+//	opts, err := w.BeginRegistration(user, existing)
+//	// ... store opts.Challenge, send opts to the browser ...
+//	cred, err := w.FinishRegistration(opts.Challenge, response)
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// User verification requirements
+const (
+	UserVerificationRequired    = "required"
+	UserVerificationPreferred   = "preferred"
+	UserVerificationDiscouraged = "discouraged"
+)
+
+const (
+	challengeSize  = 32
+	defaultTimeout = 2 * time.Minute
+	publicKeyType  = "public-key"
+)
+
+// Common errors
+var (
+	ErrChallengeMismatch   = errors.New("webauthn: challenge mismatch")
+	ErrOriginNotAllowed    = errors.New("webauthn: origin not allowed")
+	ErrRPIDMismatch        = errors.New("webauthn: relying party id mismatch")
+	ErrUserNotPresent      = errors.New("webauthn: user presence flag not set")
+	ErrUserNotVerified     = errors.New("webauthn: user verification required")
+	ErrSignCountRegression = errors.New("webauthn: signature counter did not increase, authenticator may be cloned")
+	ErrUserHandleMismatch  = errors.New("webauthn: user handle does not match")
+)
+
+// Config holds relying party settings
+type Config struct {
+	// RPID is the relying party ID, a registrable domain such as "example.com"
+	RPID string
+
+	// RPName is shown by the authenticator during registration
+	RPName string
+
+	// Origins are the exact origins allowed to run ceremonies, e.g. "https://app.example.com"
+	Origins []string
+
+	// Timeout is the ceremony timeout hint sent to the browser
+	Timeout time.Duration
+
+	// UserVerification is required, preferred or discouraged
+	UserVerification string
+}
+
+// WebAuthn runs ceremonies for one relying party
+type WebAuthn struct {
+	cfg      Config
+	rpIDHash [32]byte
+}
+
+// New validates cfg and creates a relying party
+func New(cfg Config) (*WebAuthn, error) {
+	if cfg.RPID == "" {
+		return nil, errors.New("webauthn: RPID is required")
+	}
+	if len(cfg.Origins) == 0 {
+		return nil, errors.New("webauthn: at least one origin is required")
+	}
+	if cfg.RPName == "" {
+		cfg.RPName = cfg.RPID
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	switch cfg.UserVerification {
+	case "":
+		cfg.UserVerification = UserVerificationPreferred
+	case UserVerificationRequired, UserVerificationPreferred, UserVerificationDiscouraged:
+	default:
+		return nil, fmt.Errorf("webauthn: invalid user verification %q", cfg.UserVerification)
+	}
+	for i, o := range cfg.Origins {
+		cfg.Origins[i] = strings.TrimRight(o, "/")
+	}
+
+	return &WebAuthn{cfg: cfg, rpIDHash: sha256.Sum256([]byte(cfg.RPID))}, nil
+}
+
+// Timeout returns the ceremony timeout
+func (w *WebAuthn) Timeout() time.Duration {
+	return w.cfg.Timeout
+}
+
+// User is the account a credential is registered for
+type User struct {
+	// ID is the opaque user handle; it must not contain personal data
+	ID          []byte
+	Name        string
+	DisplayName string
+}
+
+// Credential is a registered public key credential
+type Credential struct {
+	ID             []byte
+	PublicKey      []byte // COSE_Key
+	Algorithm      int64
+	SignCount      uint32
+	AAGUID         []byte
+	Transports     []string
+	UserVerified   bool
+	BackupEligible bool
+	BackedUp       bool
+}
+
+// Descriptor returns the credential descriptor used in allow/exclude lists
+func (c *Credential) Descriptor() CredentialDescriptor {
+	return CredentialDescriptor{Type: publicKeyType, ID: EncodeBase64(c.ID), Transports: c.Transports}
+}
+
+// AssertionResult is the outcome of a verified assertion
+type AssertionResult struct {
+	SignCount    uint32
+	UserVerified bool
+	BackedUp     bool
+}
+
+// ============================================================
+// REGISTRATION
+// ============================================================
+
+// BeginRegistration returns creation options with a fresh challenge.
+// exclude lists the user's existing credentials so they are not registered twice.
+func (w *WebAuthn) BeginRegistration(user User, exclude []CredentialDescriptor) (*CreationOptions, error) {
+	if len(user.ID) == 0 || len(user.ID) > 64 {
+		return nil, errors.New("webauthn: user handle must be 1-64 bytes")
+	}
+	challenge, err := NewChallenge()
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]CredentialParameter, len(SupportedAlgorithms))
+	for i, alg := range SupportedAlgorithms {
+		params[i] = CredentialParameter{Type: publicKeyType, Alg: alg}
+	}
+
+	return &CreationOptions{
+		RP: RelyingParty{ID: w.cfg.RPID, Name: w.cfg.RPName},
+		User: UserEntity{
+			ID:          EncodeBase64(user.ID),
+			Name:        user.Name,
+			DisplayName: user.DisplayName,
+		},
+		Challenge:          challenge,
+		PubKeyCredParams:   params,
+		Timeout:            w.cfg.Timeout.Milliseconds(),
+		ExcludeCredentials: exclude,
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:      "preferred",
+			UserVerification: w.cfg.UserVerification,
+		},
+		Attestation: "none",
+	}, nil
+}
+
+// FinishRegistration verifies a registration response against the challenge
+// issued by BeginRegistration and returns the new credential
+func (w *WebAuthn) FinishRegistration(challenge string, resp *RegistrationResponse) (*Credential, error) {
+	if resp == nil || resp.Type != publicKeyType {
+		return nil, errors.New("webauthn: invalid credential type")
+	}
+	rawID, err := credentialID(resp.ID, resp.RawID)
+	if err != nil {
+		return nil, err
+	}
+
+	clientDataJSON, err := DecodeBase64(resp.Response.ClientDataJSON)
+	if err != nil {
+		return nil, errors.New("webauthn: invalid clientDataJSON encoding")
+	}
+	if err := w.verifyClientData(clientDataJSON, ceremonyCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	attObj, err := DecodeBase64(resp.Response.AttestationObject)
+	if err != nil {
+		return nil, errors.New("webauthn: invalid attestationObject encoding")
+	}
+	_, rawAuthData, err := parseAttestationObject(attObj)
+	if err != nil {
+		return nil, err
+	}
+
+	ad, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.verifyAuthenticatorData(ad); err != nil {
+		return nil, err
+	}
+	if !ad.has(FlagAttestedData) {
+		return nil, errors.New("webauthn: attested credential data missing")
+	}
+	if !bytes.Equal(ad.CredentialID, rawID) {
+		return nil, errors.New("webauthn: credential id does not match authenticator data")
+	}
+
+	pub, err := ParsePublicKey(ad.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:             rawID,
+		PublicKey:      append([]byte(nil), ad.PublicKey...),
+		Algorithm:      pub.Algorithm,
+		SignCount:      ad.SignCount,
+		AAGUID:         append([]byte(nil), ad.AAGUID...),
+		Transports:     resp.Response.Transports,
+		UserVerified:   ad.has(FlagUserVerified),
+		BackupEligible: ad.has(FlagBackupEligible),
+		BackedUp:       ad.has(FlagBackedUp),
+	}, nil
+}
+
+// ============================================================
+// ASSERTION
+// ============================================================
+
+// BeginLogin returns request options restricted to the given credentials
+func (w *WebAuthn) BeginLogin(allow []CredentialDescriptor) (*RequestOptions, error) {
+	challenge, err := NewChallenge()
+	if err != nil {
+		return nil, err
+	}
+	return &RequestOptions{
+		Challenge:        challenge,
+		Timeout:          w.cfg.Timeout.Milliseconds(),
+		RPID:             w.cfg.RPID,
+		AllowCredentials: allow,
+		UserVerification: w.cfg.UserVerification,
+	}, nil
+}
+
+// FinishLogin verifies an assertion made with cred against the challenge
+// issued by BeginLogin. userHandle is the handle the credential was
+// registered with; it is compared when the authenticator returns one.
+//
+// The caller looks up cred by resp.CredentialID() and must persist the
+// returned sign count.
+func (w *WebAuthn) FinishLogin(challenge string, resp *AssertionResponse, cred *Credential, userHandle []byte) (*AssertionResult, error) {
+	if resp == nil || resp.Type != publicKeyType {
+		return nil, errors.New("webauthn: invalid credential type")
+	}
+	rawID, err := resp.CredentialID()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(rawID, cred.ID) {
+		return nil, errors.New("webauthn: credential not allowed")
+	}
+
+	if resp.Response.UserHandle != "" {
+		handle, err := DecodeBase64(resp.Response.UserHandle)
+		if err != nil || !bytes.Equal(handle, userHandle) {
+			return nil, ErrUserHandleMismatch
+		}
+	}
+
+	clientDataJSON, err := DecodeBase64(resp.Response.ClientDataJSON)
+	if err != nil {
+		return nil, errors.New("webauthn: invalid clientDataJSON encoding")
+	}
+	if err := w.verifyClientData(clientDataJSON, ceremonyGet, challenge); err != nil {
+		return nil, err
+	}
+
+	rawAuthData, err := DecodeBase64(resp.Response.AuthenticatorData)
+	if err != nil {
+		return nil, errors.New("webauthn: invalid authenticatorData encoding")
+	}
+	ad, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.verifyAuthenticatorData(ad); err != nil {
+		return nil, err
+	}
+
+	sig, err := DecodeBase64(resp.Response.Signature)
+	if err != nil {
+		return nil, errors.New("webauthn: invalid signature encoding")
+	}
+	pub, err := ParsePublicKey(cred.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte(nil), rawAuthData...), clientDataHash[:]...)
+	if err := pub.Verify(signed, sig); err != nil {
+		return nil, err
+	}
+
+	// Authenticators that implement the counter must increase it on every use
+	if (ad.SignCount != 0 || cred.SignCount != 0) && ad.SignCount <= cred.SignCount {
+		return nil, ErrSignCountRegression
+	}
+
+	return &AssertionResult{
+		SignCount:    ad.SignCount,
+		UserVerified: ad.has(FlagUserVerified),
+		BackedUp:     ad.has(FlagBackedUp),
+	}, nil
+}
+
+// ============================================================
+// HELPERS
+// ============================================================
+
+// NewChallenge returns a random base64url challenge
+func NewChallenge() (string, error) {
+	b := make([]byte, challengeSize)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", fmt.Errorf("webauthn: failed to generate challenge: %w", err)
+	}
+	return EncodeBase64(b), nil
+}
+
+func (w *WebAuthn) verifyClientData(raw []byte, ceremony, challenge string) error {
+	cd, err := parseClientData(raw)
+	if err != nil {
+		return err
+	}
+	if cd.Type != ceremony {
+		return fmt.Errorf("webauthn: unexpected client data type %q", cd.Type)
+	}
+	if challenge == "" || subtle.ConstantTimeCompare([]byte(strings.TrimRight(cd.Challenge, "=")), []byte(challenge)) != 1 {
+		return ErrChallengeMismatch
+	}
+	if cd.CrossOrigin {
+		return ErrOriginNotAllowed
+	}
+	for _, o := range w.cfg.Origins {
+		if cd.Origin == o {
+			return nil
+		}
+	}
+	return ErrOriginNotAllowed
+}
+
+func (w *WebAuthn) verifyAuthenticatorData(ad *authenticatorData) error {
+	if subtle.ConstantTimeCompare(ad.RPIDHash, w.rpIDHash[:]) != 1 {
+		return ErrRPIDMismatch
+	}
+	if !ad.has(FlagUserPresent) {
+		return ErrUserNotPresent
+	}
+	if w.cfg.UserVerification == UserVerificationRequired && !ad.has(FlagUserVerified) {
+		return ErrUserNotVerified
+	}
+	return nil
+}
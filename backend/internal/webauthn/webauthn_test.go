@@ -0,0 +1,327 @@
+// Package webauthn implements the relying party side of WebAuthn ceremonies
+//
+// File: webauthn_test.go
+// Description: Unit tests for registration and assertion ceremonies
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRPID   = "example.com"
+	testOrigin = "https://app.example.com"
+)
+
+// ------------------------------------------------------------
+// test CBOR encoder
+// ------------------------------------------------------------
+
+func cborHead(major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return []byte{major<<5 | byte(n)}
+	case n <= 0xff:
+		return []byte{major<<5 | 24, byte(n)}
+	case n <= 0xffff:
+		b := []byte{major<<5 | 25, 0, 0}
+		binary.BigEndian.PutUint16(b[1:], uint16(n))
+		return b
+	default:
+		b := []byte{major<<5 | 26, 0, 0, 0, 0}
+		binary.BigEndian.PutUint32(b[1:], uint32(n))
+		return b
+	}
+}
+
+func cborEncode(v any) []byte {
+	switch x := v.(type) {
+	case int:
+		if x < 0 {
+			return cborHead(cborNegInt, uint64(-1-x))
+		}
+		return cborHead(cborUint, uint64(x))
+	case int64:
+		return cborEncode(int(x))
+	case []byte:
+		return append(cborHead(cborBytes, uint64(len(x))), x...)
+	case string:
+		return append(cborHead(cborText, uint64(len(x))), x...)
+	case map[any]any:
+		keys := make([]any, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return string(cborEncode(keys[i])) < string(cborEncode(keys[j]))
+		})
+		out := cborHead(cborMap, uint64(len(x)))
+		for _, k := range keys {
+			out = append(out, cborEncode(k)...)
+			out = append(out, cborEncode(x[k])...)
+		}
+		return out
+	default:
+		panic("unsupported type")
+	}
+}
+
+// ------------------------------------------------------------
+// fake authenticator
+// ------------------------------------------------------------
+
+type fakeAuthenticator struct {
+	credID    []byte
+	key       *ecdsa.PrivateKey
+	signCount uint32
+	flags     byte
+	origin    string
+	rpID      string
+}
+
+func newFakeAuthenticator(t *testing.T) *fakeAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return &fakeAuthenticator{
+		credID: id,
+		key:    key,
+		flags:  FlagUserPresent | FlagUserVerified,
+		origin: testOrigin,
+		rpID:   testRPID,
+	}
+}
+
+func (a *fakeAuthenticator) coseKey() []byte {
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	a.key.X.FillBytes(x)
+	a.key.Y.FillBytes(y)
+	return cborEncode(map[any]any{
+		int64(coseKeyKty): coseKtyEC2,
+		int64(coseKeyAlg): int(AlgES256),
+		int64(coseKeyCrv): coseCrvP256,
+		int64(coseKeyX):   x,
+		int64(coseKeyY):   y,
+	})
+}
+
+func (a *fakeAuthenticator) authData(attested bool) []byte {
+	h := sha256.Sum256([]byte(a.rpID))
+	out := append([]byte(nil), h[:]...)
+	flags := a.flags
+	if attested {
+		flags |= FlagAttestedData
+	}
+	out = append(out, flags)
+	out = binary.BigEndian.AppendUint32(out, a.signCount)
+	if attested {
+		out = append(out, make([]byte, 16)...) // AAGUID
+		out = binary.BigEndian.AppendUint16(out, uint16(len(a.credID)))
+		out = append(out, a.credID...)
+		out = append(out, a.coseKey()...)
+	}
+	return out
+}
+
+func (a *fakeAuthenticator) clientData(ceremony, challenge string) []byte {
+	b, _ := json.Marshal(map[string]any{
+		"type":      ceremony,
+		"challenge": challenge,
+		"origin":    a.origin,
+	})
+	return b
+}
+
+func (a *fakeAuthenticator) create(challenge string) *RegistrationResponse {
+	att := cborEncode(map[any]any{
+		"fmt":      "none",
+		"attStmt":  map[any]any{},
+		"authData": a.authData(true),
+	})
+	r := &RegistrationResponse{ID: EncodeBase64(a.credID), RawID: EncodeBase64(a.credID), Type: "public-key"}
+	r.Response.ClientDataJSON = EncodeBase64(a.clientData(ceremonyCreate, challenge))
+	r.Response.AttestationObject = EncodeBase64(att)
+	r.Response.Transports = []string{"internal"}
+	return r
+}
+
+func (a *fakeAuthenticator) get(t *testing.T, challenge string, userHandle []byte) *AssertionResponse {
+	a.signCount++
+	authData := a.authData(false)
+	cdj := a.clientData(ceremonyGet, challenge)
+	h := sha256.Sum256(cdj)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), h[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	require.NoError(t, err)
+
+	r := &AssertionResponse{ID: EncodeBase64(a.credID), RawID: EncodeBase64(a.credID), Type: "public-key"}
+	r.Response.ClientDataJSON = EncodeBase64(cdj)
+	r.Response.AuthenticatorData = EncodeBase64(authData)
+	r.Response.Signature = EncodeBase64(sig)
+	if userHandle != nil {
+		r.Response.UserHandle = EncodeBase64(userHandle)
+	}
+	return r
+}
+
+func newTestRP(t *testing.T) *WebAuthn {
+	w, err := New(Config{RPID: testRPID, RPName: "Test", Origins: []string{testOrigin + "/"}})
+	require.NoError(t, err)
+	return w
+}
+
+// ------------------------------------------------------------
+// tests
+// ------------------------------------------------------------
+
+func TestNew_Validation(t *testing.T) {
+	_, err := New(Config{Origins: []string{testOrigin}})
+	assert.Error(t, err)
+
+	_, err = New(Config{RPID: testRPID})
+	assert.Error(t, err)
+
+	_, err = New(Config{RPID: testRPID, Origins: []string{testOrigin}, UserVerification: "always"})
+	assert.Error(t, err)
+}
+
+func TestRegistrationAndLogin(t *testing.T) {
+	w := newTestRP(t)
+	auth := newFakeAuthenticator(t)
+	userHandle := []byte("user-handle-1")
+
+	opts, err := w.BeginRegistration(User{ID: userHandle, Name: "user@example.com", DisplayName: "User"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, testRPID, opts.RP.ID)
+	assert.Equal(t, "none", opts.Attestation)
+	assert.Equal(t, AlgES256, opts.PubKeyCredParams[0].Alg)
+
+	cred, err := w.FinishRegistration(opts.Challenge, auth.create(opts.Challenge))
+	require.NoError(t, err)
+	assert.Equal(t, auth.credID, cred.ID)
+	assert.Equal(t, AlgES256, cred.Algorithm)
+	assert.True(t, cred.UserVerified)
+	assert.Equal(t, []string{"internal"}, cred.Transports)
+
+	reqOpts, err := w.BeginLogin([]CredentialDescriptor{cred.Descriptor()})
+	require.NoError(t, err)
+	assert.Equal(t, testRPID, reqOpts.RPID)
+	require.Len(t, reqOpts.AllowCredentials, 1)
+
+	res, err := w.FinishLogin(reqOpts.Challenge, auth.get(t, reqOpts.Challenge, userHandle), cred, userHandle)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), res.SignCount)
+}
+
+func TestFinishRegistration_Rejects(t *testing.T) {
+	w := newTestRP(t)
+
+	t.Run("wrong challenge", func(t *testing.T) {
+		auth := newFakeAuthenticator(t)
+		_, err := w.FinishRegistration("expected", auth.create("other"))
+		assert.ErrorIs(t, err, ErrChallengeMismatch)
+	})
+
+	t.Run("wrong origin", func(t *testing.T) {
+		auth := newFakeAuthenticator(t)
+		auth.origin = "https://evil.example.net"
+		_, err := w.FinishRegistration("c", auth.create("c"))
+		assert.ErrorIs(t, err, ErrOriginNotAllowed)
+	})
+
+	t.Run("wrong rp id", func(t *testing.T) {
+		auth := newFakeAuthenticator(t)
+		auth.rpID = "evil.example.net"
+		_, err := w.FinishRegistration("c", auth.create("c"))
+		assert.ErrorIs(t, err, ErrRPIDMismatch)
+	})
+
+	t.Run("user not present", func(t *testing.T) {
+		auth := newFakeAuthenticator(t)
+		auth.flags = 0
+		_, err := w.FinishRegistration("c", auth.create("c"))
+		assert.ErrorIs(t, err, ErrUserNotPresent)
+	})
+}
+
+func TestFinishLogin_Rejects(t *testing.T) {
+	w := newTestRP(t)
+	auth := newFakeAuthenticator(t)
+	handle := []byte("h")
+
+	cred, err := w.FinishRegistration("reg", auth.create("reg"))
+	require.NoError(t, err)
+
+	t.Run("tampered signature", func(t *testing.T) {
+		resp := auth.get(t, "c1", nil)
+		sig, _ := DecodeBase64(resp.Response.Signature)
+		sig[len(sig)-1] ^= 0xff
+		resp.Response.Signature = EncodeBase64(sig)
+		_, err := w.FinishLogin("c1", resp, cred, handle)
+		assert.Error(t, err)
+	})
+
+	t.Run("wrong user handle", func(t *testing.T) {
+		_, err := w.FinishLogin("c2", auth.get(t, "c2", []byte("other")), cred, handle)
+		assert.ErrorIs(t, err, ErrUserHandleMismatch)
+	})
+
+	t.Run("sign count regression", func(t *testing.T) {
+		stored := *cred
+		stored.SignCount = 100
+		_, err := w.FinishLogin("c3", auth.get(t, "c3", nil), &stored, handle)
+		assert.ErrorIs(t, err, ErrSignCountRegression)
+	})
+
+	t.Run("other credential", func(t *testing.T) {
+		other := newFakeAuthenticator(t)
+		_, err := w.FinishLogin("c4", other.get(t, "c4", nil), cred, handle)
+		assert.Error(t, err)
+	})
+}
+
+func TestParsePublicKey_Ed25519(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	key, err := ParsePublicKey(cborEncode(map[any]any{
+		int64(coseKeyKty): coseKtyOKP,
+		int64(coseKeyAlg): int(AlgEdDSA),
+		int64(coseKeyCrv): coseCrvEd25519,
+		int64(coseKeyX):   []byte(pub),
+	}))
+	require.NoError(t, err)
+
+	msg := []byte("signed data")
+	assert.NoError(t, key.Verify(msg, ed25519.Sign(priv, msg)))
+	assert.ErrorIs(t, key.Verify([]byte("other"), ed25519.Sign(priv, msg)), ErrInvalidSignature)
+}
+
+func TestDecodeCBOR_Limits(t *testing.T) {
+	_, _, err := decodeCBOR([]byte{0x5f}) // indefinite byte string
+	assert.Error(t, err)
+
+	_, _, err = decodeCBOR([]byte{0x58, 0x10, 0x01}) // truncated
+	assert.Error(t, err)
+
+	deep := make([]byte, 0, 40)
+	for i := 0; i < 40; i++ {
+		deep = append(deep, 0x81) // array(1)
+	}
+	deep = append(deep, 0x00)
+	_, _, err = decodeCBOR(deep)
+	assert.Error(t, err)
+}
@@ -0,0 +1,48 @@
+-- ============================================================
+-- Migration: 015_webauthn_credentials.sql
+-- Description: WebAuthn / passkey credentials for MFA
+-- Database: gerege_db
+-- Schema: template_backend
+-- ============================================================
+
+SET search_path TO template_backend, public;
+
+-- ============================================================
+-- USER_WEBAUTHN_CREDENTIALS TABLE
+-- ============================================================
+
+CREATE TABLE IF NOT EXISTS user_webauthn_credentials (
+    id                  SERIAL PRIMARY KEY,
+    user_id             INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    credential_id       VARCHAR(1400) NOT NULL,
+    user_handle         VARCHAR(100) NOT NULL,
+    public_key          BYTEA NOT NULL,
+    algorithm           INTEGER NOT NULL,
+    sign_count          BIGINT DEFAULT 0,
+    aaguid              VARCHAR(32),
+    transports          VARCHAR(255),
+    name                VARCHAR(100),
+    backup_eligible     BOOLEAN DEFAULT FALSE,
+    last_used_at        TIMESTAMPTZ,
+    created_date        TIMESTAMPTZ DEFAULT NOW(),
+    created_user_id     INTEGER,
+    created_org_id      INTEGER,
+    updated_date        TIMESTAMPTZ DEFAULT NOW(),
+    updated_user_id     INTEGER,
+    updated_org_id      INTEGER,
+    deleted_date        TIMESTAMPTZ,
+    deleted_user_id     INTEGER,
+    deleted_org_id      INTEGER
+);
+
+-- Soft-deleted rows keep their credential_id, so uniqueness only applies to live rows
+CREATE UNIQUE INDEX idx_user_webauthn_credentials_credential_id
+    ON user_webauthn_credentials(credential_id) WHERE deleted_date IS NULL;
+CREATE INDEX idx_user_webauthn_credentials_user_id ON user_webauthn_credentials(user_id);
+
+SELECT create_audit_triggers('user_webauthn_credentials');
+
+-- +migrate Down
+SET search_path TO template_backend, public;
+
+DROP TABLE IF EXISTS user_webauthn_credentials;
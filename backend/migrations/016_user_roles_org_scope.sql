@@ -0,0 +1,39 @@
+-- ============================================================
+-- Migration: 016_user_roles_org_scope.sql
+-- Description: Organization-scoped role assignments
+-- Database: gerege_db
+-- Schema: template_backend
+-- ============================================================
+
+SET search_path TO template_backend, public;
+
+-- ============================================================
+-- USER_ROLES UNIQUENESS
+-- ============================================================
+-- organization_id IS NULL means the role applies in every organization.
+-- A plain UNIQUE(user_id, role_id, organization_id) treats NULLs as distinct
+-- and allows duplicate global grants, so uniqueness is enforced on COALESCE.
+
+-- Drop duplicate global grants that the old constraint let through
+DELETE FROM user_roles a
+    USING user_roles b
+    WHERE a.id > b.id
+      AND a.user_id = b.user_id
+      AND a.role_id = b.role_id
+      AND a.organization_id IS NULL
+      AND b.organization_id IS NULL;
+
+ALTER TABLE user_roles
+    DROP CONSTRAINT IF EXISTS user_roles_user_id_role_id_organization_id_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_roles_user_role_org
+    ON user_roles(user_id, role_id, COALESCE(organization_id, 0));
+
+-- +migrate Down
+SET search_path TO template_backend, public;
+
+DROP INDEX IF EXISTS idx_user_roles_user_role_org;
+
+ALTER TABLE user_roles
+    ADD CONSTRAINT user_roles_user_id_role_id_organization_id_key
+    UNIQUE (user_id, role_id, organization_id);
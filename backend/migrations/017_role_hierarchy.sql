@@ -0,0 +1,31 @@
+-- ============================================================
+-- Migration: 017_role_hierarchy.sql
+-- Description: Role inheritance (parent roles)
+-- Database: gerege_db
+-- Schema: template_backend
+-- ============================================================
+
+SET search_path TO template_backend, public;
+
+-- ============================================================
+-- ROLE_PARENTS TABLE
+-- ============================================================
+-- role_id inherits every permission of parent_id (and of its ancestors).
+-- Cycles are rejected by RoleService before parents are written;
+-- permission queries use UNION in the recursive CTE so they terminate regardless.
+
+CREATE TABLE IF NOT EXISTS role_parents (
+    role_id         INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    parent_id       INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    created_date    TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (role_id, parent_id),
+    CHECK (role_id <> parent_id)
+);
+
+-- Descendant lookup (cache invalidation, cycle detection)
+CREATE INDEX IF NOT EXISTS idx_role_parents_parent_id ON role_parents(parent_id);
+
+-- +migrate Down
+SET search_path TO template_backend, public;
+
+DROP TABLE IF EXISTS role_parents;
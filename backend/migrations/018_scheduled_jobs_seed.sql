@@ -0,0 +1,39 @@
+-- ============================================================
+-- Migration: 018_scheduled_jobs_seed.sql
+-- Description: Built-in scheduled jobs and execution history index
+-- Database: gerege_db
+-- Schema: template_backend
+-- ============================================================
+
+SET search_path TO template_backend, public;
+
+-- ============================================================
+-- JOB_EXECUTIONS HISTORY INDEX
+-- ============================================================
+-- GET /jobs/:id/executions: WHERE job_id = ? ORDER BY started_at DESC
+
+CREATE INDEX IF NOT EXISTS idx_job_executions_job_started
+    ON job_executions(job_id, started_at DESC);
+
+-- ============================================================
+-- BUILT-IN JOBS
+-- ============================================================
+-- handler нь internal/scheduler-т бүртгэгдсэн Go handler-ийн нэр.
+-- next_run_at NULL тул scheduler эхний шалгалтаар хуваарийг тооцоолно.
+
+INSERT INTO scheduled_jobs (name, code, description, cron_expression, handler, parameters)
+VALUES (
+    'Purge job execution history',
+    'JOB_EXECUTIONS_PURGE',
+    'Deletes finished job_executions rows older than retention_days',
+    '0 3 * * *',
+    'job_executions.purge',
+    '{"retention_days": 30}'
+)
+ON CONFLICT (code) DO NOTHING;
+
+-- +migrate Down
+SET search_path TO template_backend, public;
+
+DELETE FROM scheduled_jobs WHERE code = 'JOB_EXECUTIONS_PURGE';
+DROP INDEX IF EXISTS idx_job_executions_job_started;
@@ -0,0 +1,41 @@
+-- ============================================================
+-- Migration: 019_chat_message_indexes.sql
+-- Description: Indexes for chat history pagination and unread counts
+-- Database: gerege_db
+-- Schema: template_backend
+-- ============================================================
+
+SET search_path TO template_backend, public;
+
+-- ============================================================
+-- CHAT_MESSAGES HISTORY INDEX
+-- ============================================================
+-- GET /chat-rooms/:id/messages: WHERE room_id = ? [AND id < before_id] ORDER BY id DESC
+
+CREATE INDEX IF NOT EXISTS idx_chat_messages_room_id_desc
+    ON chat_messages(room_id, id DESC);
+
+-- ============================================================
+-- UNREAD COUNT INDEX
+-- ============================================================
+-- Уншаагүй тоо: WHERE room_id = ? AND created_date > last_read_at (устгагдаагүй)
+
+CREATE INDEX IF NOT EXISTS idx_chat_messages_room_created_live
+    ON chat_messages(room_id, created_date)
+    WHERE is_deleted IS NOT TRUE;
+
+-- ============================================================
+-- ACTIVE MEMBERSHIP INDEX
+-- ============================================================
+-- GET /chat-rooms: WHERE user_id = ? AND is_active
+
+CREATE INDEX IF NOT EXISTS idx_chat_room_members_user_active
+    ON chat_room_members(user_id, room_id)
+    WHERE is_active IS NOT FALSE;
+
+-- +migrate Down
+SET search_path TO template_backend, public;
+
+DROP INDEX IF EXISTS idx_chat_room_members_user_active;
+DROP INDEX IF EXISTS idx_chat_messages_room_created_live;
+DROP INDEX IF EXISTS idx_chat_messages_room_id_desc;
@@ -0,0 +1,56 @@
+-- ============================================================
+-- Migration: 020_notification_targeting.sql
+-- Description: Store recipient target and count on notification groups
+-- Database: gerege_db
+-- Schema: template_backend
+-- ============================================================
+
+SET search_path TO template_backend, public;
+
+-- ============================================================
+-- NOTIFICATION_GROUPS TABLE
+-- ============================================================
+-- Нэг илгээлт (dm, broadcast_all, targeted) бүрт нэг мөр.
+
+CREATE TABLE IF NOT EXISTS notification_groups (
+    id                  SERIAL PRIMARY KEY,
+    user_id             INTEGER,
+    title               VARCHAR(255),
+    content             TEXT,
+    type                VARCHAR(20),
+    tenant              VARCHAR(50),
+    created_username    VARCHAR(100),
+    created_date        TIMESTAMPTZ DEFAULT NOW(),
+    created_user_id     INTEGER,
+    created_org_id      INTEGER,
+    updated_date        TIMESTAMPTZ DEFAULT NOW(),
+    updated_user_id     INTEGER,
+    updated_org_id      INTEGER,
+    deleted_user_id     INTEGER,
+    deleted_org_id      INTEGER,
+    deleted_date        TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_groups_user_id ON notification_groups(user_id);
+CREATE INDEX IF NOT EXISTS idx_notification_groups_deleted_date ON notification_groups(deleted_date);
+
+-- ============================================================
+-- TARGET
+-- ============================================================
+-- target: {"role_codes": [], "org_ids": [], "include_sub_orgs": bool, "org_type_ids": [], "user_ids": []}
+-- recipient_count: илгээх үед тодорхойлогдсон хүлээн авагчийн тоо
+
+ALTER TABLE notification_groups
+    ADD COLUMN IF NOT EXISTS target JSONB DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS recipient_count INTEGER DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_notification_groups_type ON notification_groups(type);
+
+-- +migrate Down
+SET search_path TO template_backend, public;
+
+DROP INDEX IF EXISTS idx_notification_groups_type;
+
+ALTER TABLE notification_groups
+    DROP COLUMN IF EXISTS recipient_count,
+    DROP COLUMN IF EXISTS target;
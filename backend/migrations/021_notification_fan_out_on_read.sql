@@ -0,0 +1,64 @@
+-- ============================================================
+-- Migration: 021_notification_fan_out_on_read.sql
+-- Description: Store broadcasts once and track per-user read state lazily
+-- Database: gerege_db
+-- Schema: template_backend
+-- ============================================================
+
+SET search_path TO template_backend, public;
+
+-- ============================================================
+-- NOTIFICATION_GROUPS.FAN_OUT_ON_READ
+-- ============================================================
+-- TRUE бол хэрэглэгч бүрт notifications мөр үүсгээгүй broadcast.
+-- Өмнөх broadcast-ууд (мөр бүхий) FALSE хэвээр үлдэнэ.
+
+ALTER TABLE notification_groups
+    ADD COLUMN IF NOT EXISTS fan_out_on_read BOOLEAN DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_notification_groups_fan_out_created
+    ON notification_groups(created_date)
+    WHERE fan_out_on_read AND deleted_date IS NULL;
+
+-- ============================================================
+-- NOTIFICATION_READS TABLE
+-- ============================================================
+-- Fan-out-on-read group-ийг хэрэглэгч уншсан тэмдэг (MarkGroupRead).
+
+CREATE TABLE IF NOT EXISTS notification_reads (
+    group_id    INTEGER NOT NULL REFERENCES notification_groups(id) ON DELETE CASCADE,
+    user_id     INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    read_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_reads_user_id ON notification_reads(user_id);
+
+-- ============================================================
+-- NOTIFICATION_READ_MARKS TABLE
+-- ============================================================
+-- MarkAllRead: read_all_at-аас өмнө үүссэн fan-out-on-read group бүгд уншсан.
+
+CREATE TABLE IF NOT EXISTS notification_read_marks (
+    user_id     INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    read_all_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- ============================================================
+-- NOTIFICATIONS INBOX INDEX
+-- ============================================================
+-- GET /notification: WHERE user_id = ? ORDER BY created_date DESC
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created
+    ON notifications(user_id, created_date DESC);
+
+-- +migrate Down
+SET search_path TO template_backend, public;
+
+DROP INDEX IF EXISTS idx_notifications_user_created;
+DROP TABLE IF EXISTS notification_read_marks;
+DROP TABLE IF EXISTS notification_reads;
+DROP INDEX IF EXISTS idx_notification_groups_fan_out_created;
+
+ALTER TABLE notification_groups
+    DROP COLUMN IF EXISTS fan_out_on_read;
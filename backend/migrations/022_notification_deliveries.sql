@@ -0,0 +1,39 @@
+-- ============================================================
+-- Migration: 022_notification_deliveries.sql
+-- Description: Per-channel notification delivery log (email, SMS, push)
+-- Database: gerege_db
+-- Schema: template_backend
+-- ============================================================
+
+SET search_path TO template_backend, public;
+
+-- ============================================================
+-- NOTIFICATION_DELIVERIES TABLE
+-- ============================================================
+-- user_settings-ийн notification_email/sms/push-аар сонгогдсон суваг бүрийн
+-- хүргэлтийн оролдлого. Хэрэглэгч унтраасан суваг бүртгэгдэхгүй.
+-- Broadcast (fan-out-on-read)-д notification_id = 0.
+
+CREATE TABLE IF NOT EXISTS notification_deliveries (
+    id                  SERIAL PRIMARY KEY,
+    notification_id     INTEGER NOT NULL DEFAULT 0,
+    group_id            INTEGER NOT NULL DEFAULT 0,
+    user_id             INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel             VARCHAR(20) NOT NULL,
+    provider            VARCHAR(50),
+    status              VARCHAR(20) NOT NULL,
+    error               TEXT,
+    created_date        TIMESTAMPTZ DEFAULT NOW(),
+    CONSTRAINT chk_notification_delivery_channel CHECK (channel IN ('email', 'sms', 'push')),
+    CONSTRAINT chk_notification_delivery_status CHECK (status IN ('sent', 'failed', 'skipped'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_notification_id ON notification_deliveries(notification_id);
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_group_id ON notification_deliveries(group_id);
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_user_id ON notification_deliveries(user_id);
+CREATE INDEX IF NOT EXISTS idx_notification_deliveries_created ON notification_deliveries(created_date DESC);
+
+-- +migrate Down
+SET search_path TO template_backend, public;
+
+DROP TABLE IF EXISTS notification_deliveries;
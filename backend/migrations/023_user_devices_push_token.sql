@@ -0,0 +1,23 @@
+-- ============================================================
+-- Migration: 023_user_devices_push_token.sql
+-- Description: Push token lookup for device registration
+-- Database: gerege_db
+-- Schema: template_backend
+-- ============================================================
+
+SET search_path TO template_backend, public;
+
+-- ============================================================
+-- USER_DEVICES.PUSH_TOKEN INDEX
+-- ============================================================
+-- POST /me/devices нь ижил token-той бусад хэрэглэгчийн төхөөрөмжийг
+-- идэвхгүй болгоно (төхөөрөмж дээр account солигдсон).
+
+CREATE INDEX IF NOT EXISTS idx_user_devices_push_token
+    ON user_devices(push_token)
+    WHERE push_token IS NOT NULL;
+
+-- +migrate Down
+SET search_path TO template_backend, public;
+
+DROP INDEX IF EXISTS idx_user_devices_push_token;
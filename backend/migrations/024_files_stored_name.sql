@@ -0,0 +1,23 @@
+-- ============================================================
+-- Migration: 024_files_stored_name.sql
+-- Description: Storage key lookup for private files
+-- Database: gerege_db
+-- Schema: template_backend
+-- ============================================================
+
+SET search_path TO template_backend, public;
+
+-- ============================================================
+-- FILES.STORED_NAME INDEX
+-- ============================================================
+-- GET /file/private/:name нь файлыг storage key-ээр (stored_name) хайна.
+-- Устгагдаагүй мөрүүдийн дунд key давхцахгүй.
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_files_stored_name
+    ON files(stored_name)
+    WHERE deleted_date IS NULL;
+
+-- +migrate Down
+SET search_path TO template_backend, public;
+
+DROP INDEX IF EXISTS idx_files_stored_name;
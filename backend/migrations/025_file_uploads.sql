@@ -0,0 +1,74 @@
+-- ============================================================
+-- Migration: 025_file_uploads.sql
+-- Description: Resumable (tus) uploads, their chunks and the expiry job
+-- Database: gerege_db
+-- Schema: template_backend
+-- ============================================================
+
+SET search_path TO template_backend, public;
+
+-- ============================================================
+-- FILE_UPLOADS TABLE
+-- ============================================================
+-- /uploads (tus 1.0.0)-аар хэсэгчлэн орж буй файл. upload_offset нь
+-- хадгалагдсан byte-ийн тоо; upload_length хүрмэгц public_files-д бүртгэгдэж
+-- file_url бөглөгдөнө. expires_at хүртэл үргэлжлүүлээгүй upload-ийг
+-- file_uploads.expire job устгана.
+
+CREATE TABLE IF NOT EXISTS file_uploads (
+    id                  VARCHAR(36) PRIMARY KEY,
+    user_id             INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    filename            VARCHAR(500),
+    description         TEXT,
+    upload_length       BIGINT NOT NULL,
+    upload_offset       BIGINT NOT NULL DEFAULT 0,
+    status              VARCHAR(20) NOT NULL DEFAULT 'uploading',
+    file_url            VARCHAR(1000),
+    expires_at          TIMESTAMPTZ NOT NULL,
+    completed_at        TIMESTAMPTZ,
+    created_date        TIMESTAMPTZ DEFAULT NOW(),
+    updated_date        TIMESTAMPTZ DEFAULT NOW(),
+    CONSTRAINT chk_file_upload_status CHECK (status IN ('uploading', 'finalizing', 'completed')),
+    CONSTRAINT chk_file_upload_offset CHECK (upload_offset >= 0 AND upload_offset <= upload_length)
+);
+
+CREATE INDEX IF NOT EXISTS idx_file_uploads_user_id ON file_uploads(user_id);
+CREATE INDEX IF NOT EXISTS idx_file_uploads_expires_at ON file_uploads(expires_at);
+
+-- ============================================================
+-- FILE_UPLOAD_CHUNKS TABLE
+-- ============================================================
+-- PATCH бүрийн хэсэг (chunk storage дахь key). (upload_id, chunk_offset)
+-- давхцахгүй тул ижил offset-д зэрэг ирсэн PATCH-ийн нэг нь л бүртгэгдэнэ.
+
+CREATE TABLE IF NOT EXISTS file_upload_chunks (
+    upload_id           VARCHAR(36) NOT NULL REFERENCES file_uploads(id) ON DELETE CASCADE,
+    chunk_offset        BIGINT NOT NULL,
+    size                BIGINT NOT NULL,
+    storage_key         VARCHAR(255) NOT NULL,
+    created_date        TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (upload_id, chunk_offset)
+);
+
+-- ============================================================
+-- EXPIRY JOB
+-- ============================================================
+-- Хугацаа нь дууссан upload-ийн мөр болон chunk-уудыг устгана.
+
+INSERT INTO scheduled_jobs (name, code, description, cron_expression, handler, parameters)
+VALUES (
+    'Expire abandoned uploads',
+    'FILE_UPLOADS_EXPIRE',
+    'Deletes resumable uploads and their chunks past expires_at',
+    '*/30 * * * *',
+    'file_uploads.expire',
+    '{}'
+)
+ON CONFLICT (code) DO NOTHING;
+
+-- +migrate Down
+SET search_path TO template_backend, public;
+
+DELETE FROM scheduled_jobs WHERE code = 'FILE_UPLOADS_EXPIRE';
+DROP TABLE IF EXISTS file_upload_chunks;
+DROP TABLE IF EXISTS file_uploads;
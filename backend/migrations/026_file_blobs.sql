@@ -0,0 +1,53 @@
+-- ============================================================
+-- Migration: 026_file_blobs.sql
+-- Description: Content-addressed upload storage with reference counts
+-- Database: gerege_db
+-- Schema: template_backend
+-- ============================================================
+
+SET search_path TO template_backend, public;
+
+-- ============================================================
+-- FILE_BLOBS TABLE
+-- ============================================================
+-- Storage-д нэг л удаа хадгалагдсан агуулга. Ижил SHA-256-тай upload-ууд
+-- (files мөр бүр) нэг blob-ийг заана; ref_count нь устгагдаагүй files мөрийн
+-- тоо бөгөөд 0 болмогц мөр болон storage дахь object устна.
+-- Нийтийн, хувийн файл тусдаа storage-д байх тул is_public-аар тусгаарлана.
+
+CREATE TABLE IF NOT EXISTS file_blobs (
+    id                  SERIAL PRIMARY KEY,
+    storage_provider    VARCHAR(50) NOT NULL,
+    is_public           BOOLEAN NOT NULL,
+    checksum            VARCHAR(64) NOT NULL,
+    storage_path        VARCHAR(1000) NOT NULL,
+    mime_type           VARCHAR(100),
+    file_size           BIGINT,
+    ref_count           INTEGER NOT NULL DEFAULT 1,
+    created_date        TIMESTAMPTZ DEFAULT NOW(),
+    updated_date        TIMESTAMPTZ DEFAULT NOW(),
+    CONSTRAINT chk_file_blob_ref_count CHECK (ref_count >= 0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_file_blobs_content
+    ON file_blobs(storage_provider, is_public, checksum);
+
+-- ============================================================
+-- FILES.BLOB_ID
+-- ============================================================
+-- Upload бүрийн files мөр (SHA-256, хэмжээ, MIME, upload хийсэн хэрэглэгч)
+-- агуулгаа blob_id-аар заана. Өмнөх мөрүүдэд NULL: тэдний storage_path нь
+-- өөрийн object. Soft delete хийсэн мөрийн blob устахад SET NULL.
+
+ALTER TABLE files ADD COLUMN IF NOT EXISTS blob_id INTEGER REFERENCES file_blobs(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_files_blob_id ON files(blob_id);
+CREATE INDEX IF NOT EXISTS idx_files_checksum ON files(checksum);
+
+-- +migrate Down
+SET search_path TO template_backend, public;
+
+DROP INDEX IF EXISTS idx_files_checksum;
+DROP INDEX IF EXISTS idx_files_blob_id;
+ALTER TABLE files DROP COLUMN IF EXISTS blob_id;
+DROP TABLE IF EXISTS file_blobs;
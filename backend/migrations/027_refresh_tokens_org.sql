@@ -0,0 +1,24 @@
+-- ============================================================
+-- Migration: 027_refresh_tokens_org.sql
+-- Description: Organization carried through refresh token rotation
+-- Database: gerege_db
+-- Schema: template_backend
+-- ============================================================
+
+SET search_path TO template_backend, public;
+
+-- ============================================================
+-- REFRESH_TOKENS.ORGANIZATION_ID
+-- ============================================================
+-- Rotation-оор үүссэн шинэ session нь өмнөх session-ий сонгосон
+-- байгууллагыг (POST /auth/org/change) үргэлжлүүлнэ. NULL бол (энэ
+-- migration-оос өмнөх token) хэрэглэгчийн default байгууллага сонгогдоно.
+
+ALTER TABLE refresh_tokens
+    ADD COLUMN IF NOT EXISTS organization_id INTEGER
+        REFERENCES organizations(id) ON DELETE SET NULL;
+
+-- +migrate Down
+SET search_path TO template_backend, public;
+
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS organization_id;
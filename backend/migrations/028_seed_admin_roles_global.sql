@@ -0,0 +1,54 @@
+-- ============================================================
+-- Migration: 028_seed_admin_roles_global.sql
+-- Description: Seeded admin role grants apply in every organization
+-- Database: gerege_db
+-- Schema: template_backend
+-- ============================================================
+
+SET search_path TO template_backend, public;
+
+-- ============================================================
+-- SEEDED ADMIN USER_ROLES
+-- ============================================================
+-- 014_seed_users.sql нь SUPER_ADMIN, ADMIN олголтыг GEREGE_HQ байгууллагатай
+-- үүсгэсэн. 016-аас хойш эрх байгууллагаар шалгагдах тул seed админууд өөр
+-- байгууллага сонгомогц эрхээ алддаг байсан. Олголтыг global (organization_id
+-- NULL) болгоно; global олголт аль хэдийн байгаа бол давхардлыг устгана.
+
+DELETE FROM user_roles ur
+    USING users u, roles r, organizations o
+    WHERE ur.user_id = u.id
+      AND ur.role_id = r.id
+      AND ur.organization_id = o.id
+      AND u.email IN ('superadmin@gerege.mn', 'admin@gerege.mn')
+      AND r.code IN ('SUPER_ADMIN', 'ADMIN')
+      AND o.code = 'GEREGE_HQ'
+      AND EXISTS (
+          SELECT 1 FROM user_roles g
+          WHERE g.user_id = ur.user_id
+            AND g.role_id = ur.role_id
+            AND g.organization_id IS NULL
+      );
+
+UPDATE user_roles ur
+    SET organization_id = NULL
+    FROM users u, roles r, organizations o
+    WHERE ur.user_id = u.id
+      AND ur.role_id = r.id
+      AND ur.organization_id = o.id
+      AND u.email IN ('superadmin@gerege.mn', 'admin@gerege.mn')
+      AND r.code IN ('SUPER_ADMIN', 'ADMIN')
+      AND o.code = 'GEREGE_HQ';
+
+-- +migrate Down
+SET search_path TO template_backend, public;
+
+UPDATE user_roles ur
+    SET organization_id = o.id
+    FROM users u, roles r, organizations o
+    WHERE ur.user_id = u.id
+      AND ur.role_id = r.id
+      AND ur.organization_id IS NULL
+      AND u.email IN ('superadmin@gerege.mn', 'admin@gerege.mn')
+      AND r.code IN ('SUPER_ADMIN', 'ADMIN')
+      AND o.code = 'GEREGE_HQ';
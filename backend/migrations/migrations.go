@@ -0,0 +1,13 @@
+// Package migrations embeds the ordered SQL migration files
+//
+// File: migrations.go
+// Description: Embedded migration files for db.Migrator
+package migrations
+
+import "embed"
+
+// FS нь NNN_name.sql migration файлуудыг агуулна.
+// Binary-д суулгагдсан тул deploy хийхэд migrations/ хавтас шаардлагагүй.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,130 @@
+//go:build integration
+
+// Package integration contains integration tests
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/http/dto"
+	"templatev25/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestChatRoomRepository_UnreadAndHistory(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewChatRoomRepository(db)
+	ctx := CreateTestContext()
+
+	const alice, bob = 101, 102
+	room := domain.ChatRoom{Name: "team", Type: domain.ChatRoomGroup, CreatedBy: intPtr(alice)}
+	require.NoError(t, repo.CreateRoom(ctx, &room, []domain.ChatRoomMember{
+		{UserId: alice, Role: domain.ChatRoleOwner},
+		{UserId: bob, Role: domain.ChatRoleMember},
+	}))
+
+	base := time.Now().UTC().Truncate(time.Second)
+	var ids []int
+	for i, sender := range []int{alice, alice, bob} {
+		msg := domain.ChatMessage{RoomId: room.Id, SenderId: intPtr(sender), MessageType: domain.ChatMessageText,
+			Content: "hi", CreatedDate: base.Add(time.Duration(i) * time.Second)}
+		require.NoError(t, repo.CreateMessage(ctx, &msg))
+		ids = append(ids, msg.Id)
+	}
+
+	// Bob: alice-ийн 2 мессеж уншаагүй (өөрийнх нь тооцогдохгүй, илгээхэд last_read_at шинэчлэгдэнэ)
+	rooms, total, _, _, err := repo.ListForUser(ctx, bob, dto.ChatRoomListQuery{})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+	assert.Equal(t, room.Id, rooms[0].Id)
+	assert.Equal(t, domain.ChatRoleMember, rooms[0].MyRole)
+	assert.Equal(t, int64(0), rooms[0].UnreadCount, "bob's own message marks the room read")
+
+	unread, err := repo.UnreadTotal(ctx, alice)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), unread)
+
+	require.NoError(t, repo.MarkRead(ctx, room.Id, alice, base.Add(time.Minute)))
+	unread, err = repo.UnreadTotal(ctx, alice)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), unread)
+
+	// Edit + soft delete
+	require.NoError(t, repo.EditMessage(ctx, ids[0], "edited", base))
+	require.NoError(t, repo.SoftDeleteMessage(ctx, ids[1]))
+	assert.ErrorIs(t, repo.SoftDeleteMessage(ctx, ids[1]), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, repo.EditMessage(ctx, ids[1], "x", base), gorm.ErrRecordNotFound)
+
+	msgs, total, _, _, err := repo.Messages(ctx, room.Id, dto.ChatMessageListQuery{BeforeID: ids[2]})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, []int{ids[1], ids[0]}, []int{msgs[0].Id, msgs[1].Id}, "newest first")
+	assert.True(t, msgs[0].IsDeleted)
+	assert.True(t, msgs[1].IsEdited)
+	assert.Equal(t, "edited", msgs[1].Content)
+}
+
+func TestChatRoomRepository_DirectAndMembership(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewChatRoomRepository(db)
+	ctx := CreateTestContext()
+
+	const alice, bob, carol = 201, 202, 203
+	direct := func(a, b int) domain.ChatRoom {
+		room := domain.ChatRoom{Type: domain.ChatRoomDirect, CreatedBy: intPtr(a)}
+		_, err := repo.FindOrCreateDirect(ctx, &room, []domain.ChatRoomMember{
+			{UserId: a, Role: domain.ChatRoleMember},
+			{UserId: b, Role: domain.ChatRoleMember},
+		})
+		require.NoError(t, err)
+		return room
+	}
+
+	first := direct(alice, bob)
+	again := direct(bob, alice)
+	assert.Equal(t, first.Id, again.Id, "direct room is reused regardless of order")
+
+	// Гарсан тал direct өрөөг дахин нээхэд буцаж орно
+	require.NoError(t, repo.RemoveMember(ctx, first.Id, bob, time.Now()))
+	_, err := repo.Member(ctx, first.Id, bob)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	direct(alice, bob)
+	_, err = repo.Member(ctx, first.Id, bob)
+	assert.NoError(t, err)
+
+	// AddMembers: идэвхтэй гишүүний эрх хэвээр, гарсан гишүүн сэргэнэ
+	group := domain.ChatRoom{Name: "g", Type: domain.ChatRoomGroup}
+	require.NoError(t, repo.CreateRoom(ctx, &group, []domain.ChatRoomMember{
+		{UserId: alice, Role: domain.ChatRoleOwner},
+		{UserId: carol, Role: domain.ChatRoleMember},
+	}))
+	require.NoError(t, repo.RemoveMember(ctx, group.Id, carol, time.Now()))
+	require.NoError(t, repo.AddMembers(ctx, []domain.ChatRoomMember{
+		{RoomId: group.Id, UserId: alice, Role: domain.ChatRoleMember},
+		{RoomId: group.Id, UserId: carol, Role: domain.ChatRoleMember},
+		{RoomId: group.Id, UserId: bob, Role: domain.ChatRoleMember},
+	}))
+
+	owner, err := repo.Member(ctx, group.Id, alice)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ChatRoleOwner, owner.Role)
+
+	ids, err := repo.MemberUserIDs(ctx, group.Id)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{alice, bob, carol}, ids)
+
+	// Soft delete хийсэн өрөө жагсаалтад гарахгүй
+	require.NoError(t, repo.DeleteRoom(ctx, group.Id))
+	_, total, _, _, err := repo.ListForUser(ctx, alice, dto.ChatRoomListQuery{Type: domain.ChatRoomGroup})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,51 @@
+//go:build integration
+
+// Package integration contains integration tests
+//
+// File: clamav_test.go
+// Description: ClamAV scanner against a local clamd daemon
+package integration
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"templatev25/internal/upload"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eicar is the standard antivirus test file, split so this source file is
+// not flagged itself
+var eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$` + `EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// clamdAddr returns CLAMAV_TEST_ADDR (docker-compose.test.yml: tcp://localhost:3311).
+// clamd needs minutes to load signatures, so no container is started here.
+func clamdAddr(t *testing.T) string {
+	t.Helper()
+	addr := os.Getenv("CLAMAV_TEST_ADDR")
+	if addr == "" {
+		t.Skip("CLAMAV_TEST_ADDR is not set")
+	}
+	return addr
+}
+
+func TestClamAVScanner_Daemon(t *testing.T) {
+	s := upload.NewClamAVScanner(clamdAddr(t), 30*time.Second)
+	ctx := context.Background()
+
+	require.NoError(t, s.Ping(ctx))
+
+	res, err := s.Scan(ctx, strings.NewReader("just a clean text file"))
+	require.NoError(t, err)
+	assert.False(t, res.Infected)
+
+	res, err = s.Scan(ctx, strings.NewReader(eicar))
+	require.NoError(t, err)
+	assert.True(t, res.Infected)
+	assert.Contains(t, res.Signature, "Eicar")
+}
@@ -0,0 +1,103 @@
+//go:build integration
+
+// Package integration contains integration tests
+//
+// File: file_repo_test.go
+// Description: Stored file (files, file_blobs tables) repository integration tests
+package integration
+
+import (
+	"testing"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestFileRepository_CreateAndList(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewFileRepository(db)
+	ctx := CreateTestContext()
+
+	user := SeedTestUser(t, db)
+	f, err := repo.Create(ctx, domain.File{UserId: &user.Id, OriginalName: "a.txt", StoredName: "k1.txt", StoragePath: "k1.txt", MimeType: "text/plain", FileSize: 5})
+	require.NoError(t, err)
+	assert.NotZero(t, f.Id)
+	_, err = repo.Create(ctx, domain.File{UserId: &user.Id, OriginalName: "b.png", StoredName: "k2.png", StoragePath: "k2.png", IsPublic: true})
+	require.NoError(t, err)
+
+	got, err := repo.GetByStoredName(ctx, "k1.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "a.txt", got.OriginalName)
+
+	private, err := repo.ListByUser(ctx, user.Id, false)
+	require.NoError(t, err)
+	require.Len(t, private, 1)
+	assert.Equal(t, f.Id, private[0].Id)
+
+	require.NoError(t, repo.IncrementDownloads(ctx, f.Id))
+	got, err = repo.GetByStoredName(ctx, "k1.txt")
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.DownloadCount)
+}
+
+func TestFileRepository_Delete(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewFileRepository(db)
+	ctx := CreateTestContext()
+
+	f, err := repo.Create(ctx, domain.File{OriginalName: "a.txt", StoredName: "del.txt", StoragePath: "del.txt"})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Delete(ctx, f.Id))
+	_, err = repo.GetByStoredName(ctx, "del.txt")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, repo.Delete(ctx, f.Id), gorm.ErrRecordNotFound)
+}
+
+func TestFileBlobRepository_RefCount(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewFileBlobRepository(db)
+	ctx := CreateTestContext()
+	checksum := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+	_, err := repo.Acquire(ctx, "local", true, checksum)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	b, created, err := repo.Create(ctx, domain.FileBlob{StorageProvider: "local", IsPublic: true, Checksum: checksum, StoragePath: "k1.txt", FileSize: 4})
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, 1, b.RefCount)
+
+	// Ижил агуулгыг зэрэг бүртгэх оролдлого
+	_, created, err = repo.Create(ctx, domain.FileBlob{StorageProvider: "local", IsPublic: true, Checksum: checksum, StoragePath: "k2.txt"})
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	// Хувийн storage-ийн ижил агуулга тусдаа blob
+	_, created, err = repo.Create(ctx, domain.FileBlob{StorageProvider: "local", IsPublic: false, Checksum: checksum, StoragePath: "p1.txt"})
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	got, err := repo.Acquire(ctx, "local", true, checksum)
+	require.NoError(t, err)
+	assert.Equal(t, b.Id, got.Id)
+	assert.Equal(t, "k1.txt", got.StoragePath)
+	assert.Equal(t, 2, got.RefCount)
+
+	_, last, err := repo.Release(ctx, b.Id)
+	require.NoError(t, err)
+	assert.False(t, last)
+	released, last, err := repo.Release(ctx, b.Id)
+	require.NoError(t, err)
+	assert.True(t, last)
+	assert.Equal(t, "k1.txt", released.StoragePath)
+
+	_, err = repo.Acquire(ctx, "local", true, checksum)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, _, err = repo.Release(ctx, b.Id)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
@@ -0,0 +1,96 @@
+//go:build integration
+
+// Package integration contains integration tests
+//
+// File: file_upload_repo_test.go
+// Description: Resumable upload (file_uploads) repository integration tests
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestFileUploadRepository_AppendAndComplete(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewFileUploadRepository(db)
+	ctx := CreateTestContext()
+
+	user := SeedTestUser(t, db)
+	u, err := repo.Create(ctx, domain.FileUpload{
+		Id: uuid.NewString(), UserId: user.Id, Filename: "a.txt", UploadLength: 6,
+		Status: domain.FileUploadUploading, ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	ok, err := repo.AppendChunk(ctx, domain.FileUploadChunk{UploadId: u.Id, ChunkOffset: 0, Size: 3, StorageKey: "k0"}, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// Хуучин offset-оор ирсэн PATCH бүртгэгдэхгүй
+	ok, err = repo.AppendChunk(ctx, domain.FileUploadChunk{UploadId: u.Id, ChunkOffset: 0, Size: 3, StorageKey: "k0b"}, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = repo.AppendChunk(ctx, domain.FileUploadChunk{UploadId: u.Id, ChunkOffset: 3, Size: 3, StorageKey: "k3"}, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	chunks, err := repo.Chunks(ctx, u.Id)
+	require.NoError(t, err)
+	require.Len(t, chunks, 2)
+	assert.Equal(t, "k0", chunks[0].StorageKey)
+	assert.Equal(t, int64(3), chunks[1].ChunkOffset)
+
+	claimed, err := repo.SetStatus(ctx, u.Id, domain.FileUploadUploading, domain.FileUploadFinalizing)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	claimed, err = repo.SetStatus(ctx, u.Id, domain.FileUploadUploading, domain.FileUploadFinalizing)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	require.NoError(t, repo.Complete(ctx, u.Id, "https://example.test/f.txt", time.Now().Add(time.Hour)))
+	got, err := repo.Get(ctx, u.Id)
+	require.NoError(t, err)
+	assert.Equal(t, domain.FileUploadCompleted, got.Status)
+	assert.Equal(t, int64(6), got.UploadOffset)
+	assert.NotNil(t, got.CompletedAt)
+	chunks, err = repo.Chunks(ctx, u.Id)
+	require.NoError(t, err)
+	assert.Empty(t, chunks)
+}
+
+func TestFileUploadRepository_ExpiredAndDelete(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewFileUploadRepository(db)
+	ctx := CreateTestContext()
+
+	user := SeedTestUser(t, db)
+	old, err := repo.Create(ctx, domain.FileUpload{
+		Id: uuid.NewString(), UserId: user.Id, UploadLength: 10,
+		Status: domain.FileUploadUploading, ExpiresAt: time.Now().Add(-time.Hour),
+	})
+	require.NoError(t, err)
+	_, err = repo.Create(ctx, domain.FileUpload{
+		Id: uuid.NewString(), UserId: user.Id, UploadLength: 10,
+		Status: domain.FileUploadUploading, ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	expired, err := repo.ListExpired(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, old.Id, expired[0].Id)
+
+	require.NoError(t, repo.Delete(ctx, old.Id))
+	_, err = repo.Get(ctx, old.Id)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
@@ -0,0 +1,128 @@
+//go:build integration
+
+// Package integration contains integration tests
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/http/dto"
+	"templatev25/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func seedScheduledJob(t *testing.T, db *gorm.DB, code string, next *time.Time) domain.ScheduledJob {
+	t.Helper()
+	job := domain.ScheduledJob{
+		Name:           code,
+		Code:           code,
+		CronExpression: "*/5 * * * *",
+		Handler:        "test.noop",
+		Parameters:     []byte(`{}`),
+		IsActive:       boolPtr(true),
+		NextRunAt:      next,
+	}
+	require.NoError(t, db.Create(&job).Error)
+	return job
+}
+
+func TestJobRepository_DueAndFinish(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewJobRepository(db)
+	ctx := CreateTestContext()
+
+	now := time.Now().UTC().Truncate(time.Second)
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	due := seedScheduledJob(t, db, "JOB_DUE", &past)
+	unscheduled := seedScheduledJob(t, db, "JOB_UNSCHEDULED", nil)
+	seedScheduledJob(t, db, "JOB_FUTURE", &future)
+	paused := seedScheduledJob(t, db, "JOB_PAUSED", &past)
+	require.NoError(t, repo.SetActive(ctx, paused.Id, false, nil))
+
+	jobs, err := repo.Due(ctx, now, 10)
+	require.NoError(t, err)
+	ids := make([]int, 0, len(jobs))
+	for _, j := range jobs {
+		ids = append(ids, j.Id)
+	}
+	assert.Equal(t, []int{unscheduled.Id, due.Id}, ids, "NULL next_run_at first, paused and future excluded")
+
+	// Execution lifecycle
+	exec := domain.JobExecution{JobId: due.Id, Status: domain.JobStatusRunning, StartedAt: now}
+	require.NoError(t, repo.StartExecution(ctx, &exec))
+	require.NotZero(t, exec.Id)
+
+	finished := now.Add(2 * time.Second)
+	duration := 2000
+	exec.Status = domain.JobStatusFailed
+	exec.FinishedAt = &finished
+	exec.DurationMs = &duration
+	exec.ErrorMessage = "boom"
+	next := now.Add(5 * time.Minute)
+	require.NoError(t, repo.FinishExecution(ctx, exec, &next))
+
+	job, err := repo.ByID(ctx, due.Id)
+	require.NoError(t, err)
+	assert.Equal(t, domain.JobStatusFailed, job.LastRunStatus)
+	assert.Equal(t, "boom", job.LastError)
+	require.NotNil(t, job.LastRunDuration)
+	assert.Equal(t, 2000, *job.LastRunDuration)
+	require.NotNil(t, job.NextRunAt)
+	assert.True(t, next.Equal(*job.NextRunAt))
+
+	history, total, _, _, err := repo.Executions(ctx, due.Id, dto.JobExecutionListQuery{Status: domain.JobStatusFailed})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, history, 1)
+	assert.Equal(t, "boom", history[0].ErrorMessage)
+
+	// Manual run (next nil) keeps next_run_at
+	exec2 := domain.JobExecution{JobId: due.Id, Status: domain.JobStatusRunning, StartedAt: now}
+	require.NoError(t, repo.StartExecution(ctx, &exec2))
+	exec2.Status = domain.JobStatusCompleted
+	require.NoError(t, repo.FinishExecution(ctx, exec2, nil))
+	job, err = repo.ByID(ctx, due.Id)
+	require.NoError(t, err)
+	assert.True(t, next.Equal(*job.NextRunAt))
+
+	// Purge keeps running executions
+	running := domain.JobExecution{JobId: due.Id, Status: domain.JobStatusRunning, StartedAt: now.Add(-48 * time.Hour)}
+	require.NoError(t, repo.StartExecution(ctx, &running))
+	deleted, err := repo.PurgeExecutions(ctx, now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	assert.ErrorIs(t, repo.SetActive(ctx, 999999, true, nil), gorm.ErrRecordNotFound)
+}
+
+func TestJobRepository_TryLock(t *testing.T) {
+	// Advisory lock нь session-түвшний тул transaction-гүй холболт хэрэгтэй
+	repo := repository.NewJobRepository(GetTestDB(t))
+	ctx := CreateTestContext()
+
+	unlock, ok, err := repo.TryLock(ctx, 424242)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, ok, err = repo.TryLock(ctx, 424242)
+	require.NoError(t, err)
+	assert.False(t, ok, "second lock on the same job must fail")
+
+	other, ok, err := repo.TryLock(ctx, 424243)
+	require.NoError(t, err)
+	assert.True(t, ok, "different job is independent")
+	other()
+
+	unlock()
+	again, ok, err := repo.TryLock(ctx, 424242)
+	require.NoError(t, err)
+	assert.True(t, ok, "lock is reusable after unlock")
+	again()
+}
@@ -0,0 +1,135 @@
+//go:build integration
+
+// Package integration contains integration tests
+package integration
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"templatev25/internal/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const migratorTestSchema = "migrator_test"
+
+func newTestMigrator(t *testing.T, fsys fstest.MapFS) *db.Migrator {
+	t.Helper()
+	sqlDB, err := GetTestDB(t).DB()
+	require.NoError(t, err)
+
+	m, err := db.NewMigrator(sqlDB, fsys, db.MigratorOptions{Schema: migratorTestSchema}, zap.NewNop())
+	require.NoError(t, err)
+	return m
+}
+
+func migratorTestFS() fstest.MapFS {
+	return fstest.MapFS{
+		"001_widgets.sql": {Data: []byte(`
+SET search_path TO migrator_test, public;
+CREATE TABLE widgets (id SERIAL PRIMARY KEY);
+-- +migrate Down
+SET search_path TO migrator_test, public;
+DROP TABLE widgets;
+`)},
+		"002_widget_name.sql": {Data: []byte(`
+SET search_path TO migrator_test, public;
+ALTER TABLE widgets ADD COLUMN name TEXT;
+-- +migrate Down
+SET search_path TO migrator_test, public;
+ALTER TABLE widgets DROP COLUMN name;
+`)},
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	gormDB := GetTestDB(t)
+	t.Cleanup(func() {
+		gormDB.Exec("DROP SCHEMA IF EXISTS " + migratorTestSchema + " CASCADE")
+	})
+	ctx := context.Background()
+	fsys := migratorTestFS()
+
+	t.Run("concurrent up applies each migration once", func(t *testing.T) {
+		var (
+			wg    sync.WaitGroup
+			mu    sync.Mutex
+			total int
+		)
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				applied, err := newTestMigrator(t, fsys).Up(ctx)
+				assert.NoError(t, err)
+				mu.Lock()
+				total += len(applied)
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 2, total)
+	})
+
+	t.Run("status", func(t *testing.T) {
+		statuses, err := newTestMigrator(t, fsys).Status(ctx)
+		require.NoError(t, err)
+		require.Len(t, statuses, 2)
+		for _, st := range statuses {
+			assert.True(t, st.Applied)
+			assert.False(t, st.Drift)
+			assert.NotNil(t, st.AppliedAt)
+		}
+	})
+
+	t.Run("checksum drift blocks up", func(t *testing.T) {
+		drifted := migratorTestFS()
+		drifted["002_widget_name.sql"] = &fstest.MapFile{Data: []byte(`ALTER TABLE migrator_test.widgets ADD COLUMN title TEXT;`)}
+		drifted["003_more.sql"] = &fstest.MapFile{Data: []byte(`CREATE TABLE migrator_test.more (id INT);`)}
+
+		m := newTestMigrator(t, drifted)
+		applied, err := m.Up(ctx)
+		assert.ErrorIs(t, err, db.ErrMigrationDrift)
+		assert.Empty(t, applied)
+
+		statuses, err := m.Status(ctx)
+		require.NoError(t, err)
+		assert.True(t, statuses[1].Drift)
+		assert.False(t, statuses[2].Applied)
+	})
+
+	t.Run("down reverts latest first", func(t *testing.T) {
+		reverted, err := newTestMigrator(t, fsys).Down(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, reverted, 1)
+		assert.Equal(t, int64(2), reverted[0].Version)
+
+		var count int64
+		gormDB.Raw(`SELECT COUNT(*) FROM information_schema.columns
+			WHERE table_schema = ? AND table_name = 'widgets' AND column_name = 'name'`, migratorTestSchema).Scan(&count)
+		assert.Zero(t, count)
+	})
+
+	t.Run("down without down section", func(t *testing.T) {
+		noDown := fstest.MapFS{"001_widgets.sql": {Data: []byte(`CREATE TABLE migrator_test.widgets (id INT);`)}}
+		_, err := newTestMigrator(t, noDown).Down(ctx, 1)
+		assert.ErrorIs(t, err, db.ErrNoDownMigration)
+	})
+
+	t.Run("baseline marks without running", func(t *testing.T) {
+		marked, err := newTestMigrator(t, fsys).Baseline(ctx, 2)
+		require.NoError(t, err)
+		require.Len(t, marked, 1)
+		assert.Equal(t, int64(2), marked[0].Version)
+
+		var count int64
+		gormDB.Raw(`SELECT COUNT(*) FROM information_schema.columns
+			WHERE table_schema = ? AND table_name = 'widgets' AND column_name = 'name'`, migratorTestSchema).Scan(&count)
+		assert.Zero(t, count)
+	})
+}
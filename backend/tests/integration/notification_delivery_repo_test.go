@@ -0,0 +1,70 @@
+//go:build integration
+
+// Package integration contains integration tests
+//
+// File: notification_delivery_repo_test.go
+// Description: Notification delivery repository integration tests
+package integration
+
+import (
+	"testing"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/http/dto"
+	"templatev25/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationDeliveryRepository_Recipients(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewNotificationDeliveryRepository(db)
+	ctx := CreateTestContext()
+
+	users := SeedTestUsers(t, db, 2)
+	settings := domain.DefaultUserSettings(users[1].Id)
+	settings.NotificationEmail = false
+	settings.NotificationSms = true
+	settings.Language = "en"
+	require.NoError(t, db.Create(&settings).Error)
+
+	rs, err := repo.Recipients(ctx, []int{users[0].Id, users[1].Id})
+	require.NoError(t, err)
+	require.Len(t, rs, 2)
+
+	// Тохиргоогүй хэрэглэгч хүснэгтийн default утгаар
+	assert.Equal(t, users[0].Email, rs[0].Email)
+	assert.Equal(t, users[0].PhoneNo, rs[0].PhoneNo)
+	assert.Equal(t, "mn", rs[0].Language)
+	assert.True(t, rs[0].NotificationEmail)
+	assert.True(t, rs[0].NotificationPush)
+	assert.False(t, rs[0].NotificationSms)
+
+	assert.Equal(t, "en", rs[1].Language)
+	assert.False(t, rs[1].NotificationEmail)
+	assert.True(t, rs[1].NotificationSms)
+
+	page, err := repo.RecipientsAfter(ctx, users[0].Id, 10)
+	require.NoError(t, err)
+	require.NotEmpty(t, page)
+	assert.Equal(t, users[1].Id, page[0].UserId)
+}
+
+func TestNotificationDeliveryRepository_LogAndList(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewNotificationDeliveryRepository(db)
+	ctx := CreateTestContext()
+
+	user := SeedTestUser(t, db)
+	require.NoError(t, repo.LogDeliveries(ctx, []domain.NotificationDelivery{
+		{NotificationId: 1, GroupId: 9, UserId: user.Id, Channel: domain.NotificationChannelEmail, Provider: "mail", Status: domain.NotificationDeliverySent},
+		{NotificationId: 1, GroupId: 9, UserId: user.Id, Channel: domain.NotificationChannelSMS, Provider: "http-sms", Status: domain.NotificationDeliveryFailed, Error: "gateway down"},
+	}))
+
+	items, total, _, _, err := repo.List(ctx, dto.NotificationDeliveryListQuery{GroupID: 9, Status: domain.NotificationDeliveryFailed})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, items, 1)
+	assert.Equal(t, "gateway down", items[0].Error)
+}
@@ -350,3 +350,176 @@ func TestNotificationRepository_AllUserIDs(t *testing.T) {
 	require.NoError(t, err)
 	assert.GreaterOrEqual(t, len(ids), 5)
 }
+
+func TestNotificationRepository_ResolveRecipients(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewNotificationRepository(db)
+	ctx := CreateTestContext()
+
+	users := SeedTestUsers(t, db, 5)
+	system := SeedTestSystem(t, db)
+
+	// Role: users[0]
+	role := SeedTestRole(t, db, system.ID)
+	require.NoError(t, db.Model(&role).Update("code", "NOTIFY_TARGET").Error)
+	require.NoError(t, db.Create(&domain.UserRole{UserId: users[0].Id, RoleID: role.ID}).Error)
+
+	// Org tree: parent (users[1]) -> child (users[2]); other type (users[3])
+	schoolType := domain.OrganizationType{Code: "SCHOOL", Name: "School"}
+	otherType := domain.OrganizationType{Code: "OTHER", Name: "Other"}
+	require.NoError(t, db.Create(&schoolType).Error)
+	require.NoError(t, db.Create(&otherType).Error)
+	parent := domain.Organization{Name: "Parent", TypeId: otherType.Id}
+	require.NoError(t, db.Create(&parent).Error)
+	child := domain.Organization{Name: "Child", TypeId: schoolType.Id, ParentId: &parent.Id}
+	require.NoError(t, db.Create(&child).Error)
+	require.NoError(t, db.Create(&[]domain.OrganizationUser{
+		{OrgId: parent.Id, UserId: users[1].Id},
+		{OrgId: child.Id, UserId: users[2].Id},
+	}).Error)
+
+	tests := []struct {
+		name   string
+		target domain.NotificationTarget
+		want   []int
+	}{
+		{
+			name:   "role code",
+			target: domain.NotificationTarget{RoleCodes: []string{"NOTIFY_TARGET"}},
+			want:   []int{users[0].Id},
+		},
+		{
+			name:   "organization only",
+			target: domain.NotificationTarget{OrgIDs: []int{parent.Id}},
+			want:   []int{users[1].Id},
+		},
+		{
+			name:   "organization with sub-organizations",
+			target: domain.NotificationTarget{OrgIDs: []int{parent.Id}, IncludeSubOrgs: true},
+			want:   []int{users[1].Id, users[2].Id},
+		},
+		{
+			name:   "organization type",
+			target: domain.NotificationTarget{OrgTypeIDs: []int{schoolType.Id}},
+			want:   []int{users[2].Id},
+		},
+		{
+			name: "union without duplicates",
+			target: domain.NotificationTarget{
+				RoleCodes: []string{"NOTIFY_TARGET"},
+				OrgIDs:    []int{child.Id},
+				UserIDs:   []int{users[0].Id, users[4].Id},
+			},
+			want: []int{users[0].Id, users[2].Id, users[4].Id},
+		},
+		{
+			name:   "empty target",
+			target: domain.NotificationTarget{},
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, err := repo.ResolveRecipients(ctx, tt.target)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestNotificationRepository_ResolveRecipientsRoleScope(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewNotificationRepository(db)
+	ctx := CreateTestContext()
+
+	users := SeedTestUsers(t, db, 4)
+	system := SeedTestSystem(t, db)
+
+	// TEACHER inherits STAFF, so TEACHER holders are STAFF recipients too
+	staff := SeedTestRole(t, db, system.ID)
+	require.NoError(t, db.Model(&staff).Update("code", "NOTIFY_STAFF").Error)
+	teacher := SeedTestRole(t, db, system.ID)
+	require.NoError(t, db.Model(&teacher).Update("code", "NOTIFY_TEACHER").Error)
+	require.NoError(t, db.Create(&domain.RoleParent{RoleID: teacher.ID, ParentID: staff.ID}).Error)
+
+	org := SeedTestOrganization(t, db)
+	require.NoError(t, db.Create(&domain.OrganizationUser{OrgId: org.Id, UserId: users[2].Id}).Error)
+
+	require.NoError(t, db.Create(&[]domain.UserRole{
+		{UserId: users[0].Id, RoleID: staff.ID},                 // direct, global
+		{UserId: users[1].Id, RoleID: teacher.ID},               // inherited, global
+		{UserId: users[2].Id, RoleID: staff.ID, OrgID: &org.Id}, // org grant, member
+		{UserId: users[3].Id, RoleID: staff.ID, OrgID: &org.Id}, // org grant, not a member
+	}).Error)
+
+	ids, err := repo.ResolveRecipients(ctx, domain.NotificationTarget{RoleCodes: []string{"NOTIFY_STAFF"}})
+	require.NoError(t, err)
+	assert.Equal(t, []int{users[0].Id, users[1].Id, users[2].Id}, ids)
+
+	// Inheritance runs one way: STAFF holders do not hold TEACHER
+	ids, err = repo.ResolveRecipients(ctx, domain.NotificationTarget{RoleCodes: []string{"NOTIFY_TEACHER"}})
+	require.NoError(t, err)
+	assert.Equal(t, []int{users[1].Id}, ids)
+}
+
+func TestNotificationRepository_FanOutOnRead(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewNotificationRepository(db)
+	ctx := CreateTestContext()
+
+	user := SeedTestUser(t, db)
+	first, err := repo.CreateGroup(ctx, domain.NotificationGroup{Title: "First", Type: domain.NotificationTypeBroadcast, Tenant: "test", FanOutOnRead: true})
+	require.NoError(t, err)
+	second, err := repo.CreateGroup(ctx, domain.NotificationGroup{Title: "Second", Type: domain.NotificationTypeBroadcast, Tenant: "test", FanOutOnRead: true})
+	require.NoError(t, err)
+	direct := SeedTestNotificationGroup(t, db, user.Id)
+	SeedTestNotification(t, db, user.Id, direct.Id)
+
+	// Registered after the broadcasts: sees nothing
+	late := SeedTestUsers(t, db, 1)[0]
+
+	unread := func(userID int) map[int]bool {
+		t.Helper()
+		items, total, _, _, err := repo.ListByUser(ctx, userID, common.PaginationQuery{Page: 1, Size: 100})
+		require.NoError(t, err)
+		require.Equal(t, int64(len(items)), total)
+		out := map[int]bool{}
+		for _, n := range items {
+			assert.Equal(t, userID, n.UserId)
+			out[n.GroupId] = !n.IsRead
+		}
+		return out
+	}
+
+	assert.Equal(t, map[int]bool{first.Id: true, second.Id: true, direct.Id: true}, unread(user.Id))
+	assert.Empty(t, unread(late.Id))
+
+	// Broadcast rows carry -group_id, so every id in the list is distinct
+	items, _, _, _, err := repo.ListByUser(ctx, user.Id, common.PaginationQuery{Page: 1, Size: 100})
+	require.NoError(t, err)
+	seen := map[int]bool{}
+	for _, n := range items {
+		assert.False(t, seen[n.Id], "duplicate id %d", n.Id)
+		seen[n.Id] = true
+		if n.GroupId != direct.Id {
+			assert.Equal(t, -n.GroupId, n.Id)
+		}
+	}
+
+	// Group read is per user and idempotent
+	require.NoError(t, repo.MarkGroupRead(ctx, user.Id, first.Id))
+	require.NoError(t, repo.MarkGroupRead(ctx, user.Id, first.Id))
+	assert.Equal(t, map[int]bool{first.Id: false, second.Id: true, direct.Id: true}, unread(user.Id))
+
+	// Mark all covers broadcasts sent before it, not after
+	require.NoError(t, repo.MarkAllRead(ctx, user.Id))
+	third, err := repo.CreateGroup(ctx, domain.NotificationGroup{Title: "Third", Type: domain.NotificationTypeBroadcast, Tenant: "test", FanOutOnRead: true})
+	require.NoError(t, err)
+	assert.Equal(t, map[int]bool{first.Id: false, second.Id: false, third.Id: true, direct.Id: false}, unread(user.Id))
+
+	var reads int64
+	require.NoError(t, db.Model(&domain.NotificationRead{}).Where("user_id = ?", user.Id).Count(&reads).Error)
+	assert.Zero(t, reads, "mark all replaces per-group read rows")
+}
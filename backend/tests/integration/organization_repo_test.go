@@ -12,6 +12,7 @@ import (
 	"git.gerege.mn/backend-packages/common"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 func TestOrganizationRepository_Create(t *testing.T) {
@@ -285,3 +286,95 @@ func TestOrganizationRepository_Tree(t *testing.T) {
 		})
 	}
 }
+
+// seedOrgChain creates a linear chain of organizations (names[0] is the root)
+func seedOrgChain(t *testing.T, db *gorm.DB, names ...string) []domain.Organization {
+	t.Helper()
+	out := make([]domain.Organization, 0, len(names))
+	var parentID *int
+	for _, name := range names {
+		org := domain.Organization{Name: name, ParentId: parentID, IsActive: boolPtr(true)}
+		require.NoError(t, db.Create(&org).Error)
+		out = append(out, org)
+		id := org.Id
+		parentID = &id
+	}
+	return out
+}
+
+func TestOrganizationRepository_Hierarchy(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewOrganizationRepository(db)
+	ctx := CreateTestContext()
+
+	// Ministry → Department → Division → Unit → Team
+	chain := seedOrgChain(t, db, "Ministry", "Department", "Division", "Unit", "Team")
+	ministry, department, division, team := chain[0], chain[1], chain[2], chain[4]
+
+	sibling := domain.Organization{Name: "Department 2", ParentId: &ministry.Id, IsActive: boolPtr(true)}
+	require.NoError(t, db.Create(&sibling).Error)
+
+	t.Run("tree loads every level", func(t *testing.T) {
+		tree, err := repo.Tree(ctx, ministry.Id)
+		require.NoError(t, err)
+		require.Len(t, tree, 1)
+
+		depth := 0
+		node := tree[0]
+		for node.Children != nil {
+			depth++
+			node = (*node.Children)[0]
+		}
+		assert.Equal(t, 4, depth)
+		assert.Equal(t, team.Id, node.Id)
+		assert.Len(t, *tree[0].Children, 2)
+	})
+
+	t.Run("subtree is ordered by depth", func(t *testing.T) {
+		items, err := repo.Subtree(ctx, department.Id)
+		require.NoError(t, err)
+		require.Len(t, items, 4)
+		assert.Equal(t, department.Id, items[0].Id)
+		assert.Equal(t, team.Id, items[3].Id)
+	})
+
+	t.Run("ancestors are root first", func(t *testing.T) {
+		items, err := repo.Ancestors(ctx, team.Id)
+		require.NoError(t, err)
+		require.Len(t, items, 4)
+		assert.Equal(t, ministry.Id, items[0].Id)
+		assert.Equal(t, chain[3].Id, items[3].Id)
+	})
+
+	t.Run("descendant ids", func(t *testing.T) {
+		ids, err := repo.DescendantIDs(ctx, division.Id)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []int{chain[3].Id, team.Id}, ids)
+	})
+
+	t.Run("move subtree under sibling", func(t *testing.T) {
+		require.NoError(t, repo.Move(ctx, division.Id, &sibling.Id))
+
+		ancestors, err := repo.Ancestors(ctx, team.Id)
+		require.NoError(t, err)
+		require.Len(t, ancestors, 4)
+		assert.Equal(t, sibling.Id, ancestors[1].Id)
+
+		subtree, err := repo.Subtree(ctx, department.Id)
+		require.NoError(t, err)
+		assert.Len(t, subtree, 1)
+	})
+
+	t.Run("move to root", func(t *testing.T) {
+		require.NoError(t, repo.Move(ctx, division.Id, nil))
+
+		ancestors, err := repo.Ancestors(ctx, division.Id)
+		require.NoError(t, err)
+		assert.Empty(t, ancestors)
+	})
+
+	t.Run("move missing organization", func(t *testing.T) {
+		err := repo.Move(ctx, 999999, nil)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
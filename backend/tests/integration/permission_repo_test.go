@@ -4,13 +4,16 @@
 package integration
 
 import (
+	"slices"
 	"testing"
+	"time"
 
 	"templatev25/internal/domain"
 	"templatev25/internal/http/dto"
 	"templatev25/internal/repository"
 
 	"git.gerege.mn/backend-packages/common"
+	xctx "git.gerege.mn/backend-packages/ctx"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
@@ -347,6 +350,179 @@ func TestPermissionRepository_GetUserPermissionCodes(t *testing.T) {
 }
 
 // Helper functions
+func TestPermissionRepository_OrgScopedRoles(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewPermissionRepository(db)
+
+	// Seed
+	user := SeedTestUser(t, db)
+	org := SeedTestOrganization(t, db)
+	system := SeedTestSystem(t, db)
+	module := seedTestModule(t, db, system.ID)
+	perm := seedTestPermission(t, db, module.ID)
+	role := SeedTestRole(t, db, system.ID)
+	db.Exec("INSERT INTO role_permissions (role_id, permission_id, created_date) VALUES (?, ?, NOW())", role.ID, perm.ID)
+
+	// Role granted only within org
+	db.Create(&domain.UserRole{
+		UserId: user.Id,
+		RoleID: role.ID,
+		OrgID:  &org.Id,
+	})
+
+	tests := []struct {
+		name  string
+		orgID int
+		want  bool
+	}{
+		{name: "granted org selected", orgID: org.Id, want: true},
+		{name: "other org selected", orgID: org.Id + 1000, want: false},
+		{name: "no org selected", orgID: 0, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uctx := CreateTestContext()
+			if tt.orgID > 0 {
+				uctx = xctx.WithValue(uctx, xctx.KeyOrgID, tt.orgID)
+			}
+
+			has, err := repo.UserHasPermission(uctx, user.Id, perm.Code)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, has)
+
+			codes, err := repo.GetUserPermissionCodes(uctx, user.Id)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, slices.Contains(codes, perm.Code))
+		})
+	}
+}
+
+func TestPermissionRepository_InheritedRoles(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewPermissionRepository(db)
+	ctx := CreateTestContext()
+
+	// Seed: parent role holds the permission, user only has the child role
+	user := SeedTestUser(t, db)
+	system := SeedTestSystem(t, db)
+	module := seedTestModule(t, db, system.ID)
+	perm := seedTestPermission(t, db, module.ID)
+	parent := seedHierarchyRole(t, db, system.ID, "INHERIT_PARENT")
+	child := seedHierarchyRole(t, db, system.ID, "INHERIT_CHILD")
+	db.Exec("INSERT INTO role_permissions (role_id, permission_id, created_date) VALUES (?, ?, NOW())", parent.ID, perm.ID)
+	db.Create(&domain.RoleParent{RoleID: child.ID, ParentID: parent.ID})
+	db.Create(&domain.UserRole{UserId: user.Id, RoleID: child.ID})
+
+	has, err := repo.UserHasPermission(ctx, user.Id, perm.Code)
+	require.NoError(t, err)
+	assert.True(t, has)
+
+	codes, err := repo.GetUserPermissionCodes(ctx, user.Id)
+	require.NoError(t, err)
+	assert.Contains(t, codes, perm.Code)
+
+	// A cycle written behind the service's back must not hang the query
+	db.Create(&domain.RoleParent{RoleID: parent.ID, ParentID: child.ID})
+	codes, err = repo.GetUserPermissionCodes(ctx, user.Id)
+	require.NoError(t, err)
+	assert.Equal(t, []string{perm.Code}, codes)
+
+	// Inheritance stops at a soft-deleted parent
+	require.NoError(t, db.Delete(&parent).Error)
+	has, err = repo.UserHasPermission(ctx, user.Id, perm.Code)
+	require.NoError(t, err)
+	assert.False(t, has)
+}
+
+func TestPermissionRepository_ExpiredRoleGrant(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewPermissionRepository(db)
+	ctx := CreateTestContext()
+
+	user := SeedTestUser(t, db)
+	system := SeedTestSystem(t, db)
+	module := seedTestModule(t, db, system.ID)
+	perm := seedTestPermission(t, db, module.ID)
+	role := SeedTestRole(t, db, system.ID)
+	db.Exec("INSERT INTO role_permissions (role_id, permission_id, created_date) VALUES (?, ?, NOW())", role.ID, perm.ID)
+
+	future := time.Now().Add(time.Hour)
+	grant := domain.UserRole{UserId: user.Id, RoleID: role.ID, ExpiresAt: &future}
+	require.NoError(t, db.Create(&grant).Error)
+
+	has, err := repo.UserHasPermission(ctx, user.Id, perm.Code)
+	require.NoError(t, err)
+	assert.True(t, has, "grant that has not expired yet authorizes")
+
+	// expires_at өнгөрсөн олголт эрх олгохгүй
+	require.NoError(t, db.Model(&domain.UserRole{}).
+		Where("user_id = ? AND role_id = ?", user.Id, role.ID).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error)
+
+	has, err = repo.UserHasPermission(ctx, user.Id, perm.Code)
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	codes, err := repo.GetUserPermissionCodes(ctx, user.Id)
+	require.NoError(t, err)
+	assert.NotContains(t, codes, perm.Code)
+}
+
+func TestPermissionRepository_TraceUserPermission(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewPermissionRepository(db)
+	ctx := CreateTestContext()
+
+	// Seed: expired direct assignment + valid assignment of a child role
+	user := SeedTestUser(t, db)
+	system := SeedTestSystem(t, db)
+	module := seedTestModule(t, db, system.ID)
+	perm := seedTestPermission(t, db, module.ID)
+	parent := seedHierarchyRole(t, db, system.ID, "TRACE_PARENT")
+	child := seedHierarchyRole(t, db, system.ID, "TRACE_CHILD")
+	db.Exec("INSERT INTO role_permissions (role_id, permission_id, created_date) VALUES (?, ?, NOW())", parent.ID, perm.ID)
+	db.Create(&domain.RoleParent{RoleID: child.ID, ParentID: parent.ID})
+
+	expired := time.Now().Add(-time.Hour)
+	db.Create(&domain.UserRole{UserId: user.Id, RoleID: parent.ID, ExpiresAt: &expired})
+
+	// Expired assignment alone does not grant
+	has, err := repo.UserHasPermission(ctx, user.Id, perm.Code)
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	traces, err := repo.TraceUserPermission(ctx, user.Id, perm.Code)
+	require.NoError(t, err)
+	require.Len(t, traces, 1)
+	assert.Equal(t, parent.ID, traces[0].Assignment.RoleID)
+	require.NotNil(t, traces[0].Assignment.ExpiresAt)
+	assert.Equal(t, perm.ID, traces[0].Permission.ID)
+
+	// Inherited path through the child role
+	db.Create(&domain.UserRole{UserId: user.Id, RoleID: child.ID})
+
+	has, err = repo.UserHasPermission(ctx, user.Id, perm.Code)
+	require.NoError(t, err)
+	assert.True(t, has)
+
+	traces, err = repo.TraceUserPermission(ctx, user.Id, perm.Code)
+	require.NoError(t, err)
+	require.Len(t, traces, 2)
+
+	var inherited *domain.PermissionGrantTrace
+	for i := range traces {
+		if traces[i].Assignment.RoleID == child.ID {
+			inherited = &traces[i]
+		}
+	}
+	require.NotNil(t, inherited)
+	require.Len(t, inherited.Roles, 2)
+	assert.Equal(t, child.ID, inherited.Roles[0].ID)
+	assert.Equal(t, parent.ID, inherited.Roles[1].ID)
+	assert.Nil(t, inherited.Assignment.ExpiresAt)
+}
+
 func seedTestModule(t *testing.T, db *gorm.DB, systemID int) domain.Module {
 	t.Helper()
 	module := domain.Module{
@@ -7,6 +7,7 @@
 package integration
 
 import (
+	"sync"
 	"testing"
 
 	"templatev25/internal/domain"
@@ -16,6 +17,7 @@ import (
 	"git.gerege.mn/backend-packages/common"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 func TestRoleRepository_Create(t *testing.T) {
@@ -386,6 +388,94 @@ func TestRoleRepository_GetUserCount(t *testing.T) {
 	}
 }
 
+func TestRoleRepository_Hierarchy(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewRoleRepository(db)
+	ctx := CreateTestContext()
+
+	// Seed: admin <- manager <- clerk
+	system := SeedTestSystem(t, db)
+	admin := seedHierarchyRole(t, db, system.ID, "HIER_ADMIN")
+	manager := seedHierarchyRole(t, db, system.ID, "HIER_MANAGER")
+	clerk := seedHierarchyRole(t, db, system.ID, "HIER_CLERK")
+	user := SeedTestUser(t, db)
+
+	require.NoError(t, repo.ReplaceParents(ctx, manager.ID, []int{admin.ID}))
+	require.NoError(t, repo.ReplaceParents(ctx, clerk.ID, []int{manager.ID}))
+	db.Create(&domain.UserRole{UserId: user.Id, RoleID: clerk.ID})
+
+	descendants, err := repo.DescendantIDs(ctx, admin.ID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{manager.ID, clerk.ID}, descendants)
+
+	descendants, err = repo.DescendantIDs(ctx, clerk.ID)
+	require.NoError(t, err)
+	assert.Empty(t, descendants)
+
+	userIDs, err := repo.UserIDsByRoles(ctx, append(descendants, admin.ID, manager.ID, clerk.ID))
+	require.NoError(t, err)
+	assert.Equal(t, []int{user.Id}, userIDs)
+
+	// A descendant cannot become a parent
+	assert.ErrorIs(t, repo.ReplaceParents(ctx, admin.ID, []int{clerk.ID}), repository.ErrRoleCycle)
+
+	// Replacing with an empty list detaches the role
+	require.NoError(t, repo.ReplaceParents(ctx, clerk.ID, []int{}))
+	descendants, err = repo.DescendantIDs(ctx, admin.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []int{manager.ID}, descendants)
+}
+
+func TestRoleRepository_UpdateWithParents_ConcurrentCycle(t *testing.T) {
+	// Зэрэг transaction-ууд хэрэгтэй тул transaction-гүй холболт
+	db := GetTestDB(t)
+	repo := repository.NewRoleRepository(db)
+	ctx := CreateTestContext()
+
+	system := SeedTestSystem(t, db)
+	a := seedHierarchyRole(t, db, system.ID, "CYCLE_A")
+	b := seedHierarchyRole(t, db, system.ID, "CYCLE_B")
+	t.Cleanup(func() {
+		db.Where("role_id IN ?", []int{a.ID, b.ID}).Delete(&domain.RoleParent{})
+		db.Unscoped().Where("id IN ?", []int{a.ID, b.ID}).Delete(&domain.Role{})
+		db.Unscoped().Where("id = ?", system.ID).Delete(&domain.System{})
+	})
+
+	// A→B, B→A хоёулаа хуучин графаар шалгагдвал цикл үүснэ
+	errs := make(chan error, 2)
+	var start sync.WaitGroup
+	start.Add(1)
+	for _, pair := range [][2]domain.Role{{a, b}, {b, a}} {
+		go func(role, parent domain.Role) {
+			start.Wait()
+			errs <- repo.UpdateWithParents(ctx, role.ID, domain.Role{Name: role.Name}, []int{parent.ID})
+		}(pair[0], pair[1])
+	}
+	start.Done()
+
+	var failed int
+	for range 2 {
+		if err := <-errs; err != nil {
+			require.ErrorIs(t, err, repository.ErrRoleCycle)
+			failed++
+		}
+	}
+	assert.Equal(t, 1, failed, "exactly one update must be rejected")
+
+	var links int64
+	db.Model(&domain.RoleParent{}).Where("role_id IN ?", []int{a.ID, b.ID}).Count(&links)
+	assert.Equal(t, int64(1), links)
+}
+
+func seedHierarchyRole(t *testing.T, db *gorm.DB, systemID int, code string) domain.Role {
+	t.Helper()
+	role := domain.Role{SystemID: systemID, Code: code, Name: code, IsActive: boolPtr(true)}
+	if err := db.Create(&role).Error; err != nil {
+		t.Fatalf("failed to seed role %s: %v", code, err)
+	}
+	return role
+}
+
 // Helper function for bool pointer
 func boolPtr(b bool) *bool {
 	return &b
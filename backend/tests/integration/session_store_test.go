@@ -0,0 +1,102 @@
+//go:build integration
+
+// Package integration contains integration tests
+//
+// File: session_store_test.go
+// Description: Redis session store against a local Redis server
+package integration
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"templatev25/internal/service"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// redisSessionStore connects to REDIS_TEST_ADDR (docker-compose.test.yml: localhost:6380)
+func redisSessionStore(t *testing.T) *service.RedisSessionStore {
+	t.Helper()
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR is not set")
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() { client.Close() })
+	require.NoError(t, client.Ping(context.Background()).Err())
+
+	return service.NewRedisSessionStore(client, "test:"+uuid.NewString()+":", time.Hour)
+}
+
+func newTestSession(id string) *service.SessionData {
+	now := time.Now()
+	return &service.SessionData{
+		SessionID:      id,
+		UserID:         42,
+		Email:          "user@example.com",
+		OrgID:          1,
+		CreatedAt:      now,
+		ExpiresAt:      now.Add(time.Hour),
+		LastActivityAt: now,
+	}
+}
+
+func TestRedisSessionStore_TouchAfterDeleteDoesNotResurrect(t *testing.T) {
+	store := redisSessionStore(t)
+	ctx := context.Background()
+
+	session := newTestSession(uuid.NewString())
+	require.NoError(t, store.Create(ctx, session))
+	require.NoError(t, store.Delete(ctx, session.SessionID))
+
+	err := store.Touch(ctx, session.SessionID, time.Now())
+	assert.ErrorIs(t, err, service.ErrSessionNotFound)
+
+	got, err := store.Get(ctx, session.SessionID)
+	require.NoError(t, err)
+	assert.Nil(t, got, "deleted session stays gone")
+}
+
+func TestRedisSessionStore_UpdateAfterDeleteDoesNotResurrect(t *testing.T) {
+	store := redisSessionStore(t)
+	ctx := context.Background()
+
+	session := newTestSession(uuid.NewString())
+	require.NoError(t, store.Create(ctx, session))
+	require.NoError(t, store.Delete(ctx, session.SessionID))
+
+	session.OrgID = 2
+	assert.ErrorIs(t, store.Update(ctx, session), service.ErrSessionNotFound)
+
+	got, err := store.Get(ctx, session.SessionID)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestRedisSessionStore_TouchKeepsSessionFields(t *testing.T) {
+	store := redisSessionStore(t)
+	ctx := context.Background()
+
+	session := newTestSession(uuid.NewString())
+	require.NoError(t, store.Create(ctx, session))
+
+	// A touch after an organization change keeps the new OrgID
+	session.OrgID = 2
+	require.NoError(t, store.Update(ctx, session))
+
+	at := time.Now().Add(time.Minute)
+	require.NoError(t, store.Touch(ctx, session.SessionID, at))
+
+	got, err := store.Get(ctx, session.SessionID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, 2, got.OrgID)
+	assert.WithinDuration(t, at, got.LastActivityAt, time.Millisecond)
+	assert.WithinDuration(t, session.ExpiresAt, got.ExpiresAt, time.Millisecond)
+}
@@ -107,7 +107,9 @@ func teardown(ctx context.Context, container testcontainers.Container) {
 func runMigrations(db *gorm.DB) error {
 	return db.AutoMigrate(
 		&domain.User{},
+		&domain.OrganizationType{},
 		&domain.Organization{},
+		&domain.OrganizationUser{},
 		&domain.System{},
 		&domain.Module{},
 		&domain.Role{},
@@ -117,7 +119,21 @@ func runMigrations(db *gorm.DB) error {
 		&domain.News{},
 		&domain.Notification{},
 		&domain.NotificationGroup{},
+		&domain.NotificationRead{},
+		&domain.NotificationReadMark{},
+		&domain.UserSettings{},
+		&domain.UserDevice{},
+		&domain.FileBlob{},
+		&domain.File{},
+		&domain.FileUpload{},
+		&domain.FileUploadChunk{},
+		&domain.NotificationDelivery{},
 		&domain.ChatItem{},
+		&domain.ChatRoom{},
+		&domain.ChatRoomMember{},
+		&domain.ChatMessage{},
+		&domain.ScheduledJob{},
+		&domain.JobExecution{},
 	)
 }
 
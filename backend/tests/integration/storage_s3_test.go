@@ -0,0 +1,107 @@
+//go:build integration
+
+// Package integration contains integration tests
+//
+// File: storage_s3_test.go
+// Description: S3 storage driver against a MinIO container
+package integration
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"templatev25/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	minioUser     = "minioadmin"
+	minioPassword = "minioadmin"
+)
+
+// setupMinIO returns an S3 endpoint. STORAGE_TEST_S3_ENDPOINT points at an
+// already running MinIO (docker-compose.test.yml); otherwise a container is started.
+func setupMinIO(t *testing.T) string {
+	t.Helper()
+	if endpoint := os.Getenv("STORAGE_TEST_S3_ENDPOINT"); endpoint != "" {
+		return endpoint
+	}
+
+	ctx := context.Background()
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "minio/minio:latest",
+			Cmd:          []string{"server", "/data"},
+			ExposedPorts: []string{"9000/tcp"},
+			Env: map[string]string{
+				"MINIO_ROOT_USER":     minioUser,
+				"MINIO_ROOT_PASSWORD": minioPassword,
+			},
+			WaitingFor: wait.ForHTTP("/minio/health/ready").WithPort("9000/tcp").WithStartupTimeout(60 * time.Second),
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Skipf("minio container unavailable: %v", err)
+	}
+	t.Cleanup(func() { _ = container.Terminate(ctx) })
+
+	endpoint, err := container.PortEndpoint(ctx, "9000/tcp", "http")
+	require.NoError(t, err)
+	return endpoint
+}
+
+func TestS3Storage_MinIO(t *testing.T) {
+	endpoint := setupMinIO(t)
+	ctx := context.Background()
+
+	s, err := storage.NewS3Storage(storage.S3Config{
+		Endpoint:  endpoint,
+		Bucket:    "template-files",
+		AccessKey: minioUser,
+		SecretKey: minioPassword,
+		Prefix:    "public",
+		PathStyle: true,
+		Timeout:   10 * time.Second,
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.EnsureBucket(ctx))
+	require.NoError(t, s.EnsureBucket(ctx), "existing bucket is fine")
+
+	key := "3f2a 9c+logo.png"
+	require.NoError(t, s.Put(ctx, key, strings.NewReader("png-bytes"), 9, "image/png"))
+
+	info, err := s.Stat(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), info.Size)
+	assert.Equal(t, "image/png", info.ContentType)
+	assert.False(t, info.LastModified.IsZero())
+
+	obj, err := s.Open(ctx, key)
+	require.NoError(t, err)
+	data, err := io.ReadAll(obj.Body)
+	obj.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "png-bytes", string(data))
+
+	// Size unknown (chunked source)
+	require.NoError(t, s.Put(ctx, "unknown.txt", strings.NewReader("abc"), -1, ""))
+	info, err = s.Stat(ctx, "unknown.txt")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), info.Size)
+
+	require.NoError(t, s.Delete(ctx, key))
+	require.NoError(t, s.Delete(ctx, key))
+	_, err = s.Open(ctx, key)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	_, err = s.Stat(ctx, key)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
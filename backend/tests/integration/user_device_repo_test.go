@@ -0,0 +1,98 @@
+//go:build integration
+
+// Package integration contains integration tests
+//
+// File: user_device_repo_test.go
+// Description: User device repository integration tests
+package integration
+
+import (
+	"errors"
+	"testing"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestUserDeviceRepository_Register(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewUserDeviceRepository(db)
+	ctx := CreateTestContext()
+
+	users := SeedTestUsers(t, db, 2)
+
+	first, err := repo.Register(ctx, domain.UserDevice{UserId: users[0].Id, DeviceId: "phone-1", PushToken: "tok-1", PushProvider: domain.PushProviderFCM})
+	require.NoError(t, err)
+	assert.True(t, first.IsActive)
+
+	// Ижил device_id дахин бүртгэхэд мөр шинэчлэгдэнэ
+	again, err := repo.Register(ctx, domain.UserDevice{UserId: users[0].Id, DeviceId: "phone-1", PushToken: "tok-2", PushProvider: domain.PushProviderFCM, AppVersion: "2.0"})
+	require.NoError(t, err)
+	assert.Equal(t, first.Id, again.Id)
+
+	devices, err := repo.ActivePushDevices(ctx, users[0].Id)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, "tok-2", devices[0].PushToken)
+
+	// Token өөр account руу шилжвэл өмнөх хэрэглэгчээс хасагдана
+	_, err = repo.Register(ctx, domain.UserDevice{UserId: users[1].Id, DeviceId: "phone-1", PushToken: "tok-2", PushProvider: domain.PushProviderFCM})
+	require.NoError(t, err)
+
+	devices, err = repo.ActivePushDevices(ctx, users[0].Id)
+	require.NoError(t, err)
+	assert.Empty(t, devices)
+	devices, err = repo.ActivePushDevices(ctx, users[1].Id)
+	require.NoError(t, err)
+	assert.Len(t, devices, 1)
+}
+
+func TestUserDeviceRepository_DeactivateAndUnregister(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewUserDeviceRepository(db)
+	ctx := CreateTestContext()
+
+	user := SeedTestUser(t, db)
+	d, err := repo.Register(ctx, domain.UserDevice{UserId: user.Id, DeviceId: "ipad", PushToken: "tok", PushProvider: domain.PushProviderAPNs})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Deactivate(ctx, d.Id, "tok"))
+	devices, err := repo.ActivePushDevices(ctx, user.Id)
+	require.NoError(t, err)
+	assert.Empty(t, devices)
+
+	all, err := repo.ListByUser(ctx, user.Id)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.False(t, all[0].IsActive)
+
+	require.NoError(t, repo.Unregister(ctx, user.Id, "ipad"))
+	err = repo.Unregister(ctx, user.Id, "ipad")
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+}
+
+func TestUserDeviceRepository_DeactivateKeepsRotatedToken(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewUserDeviceRepository(db)
+	ctx := CreateTestContext()
+
+	user := SeedTestUser(t, db)
+	d, err := repo.Register(ctx, domain.UserDevice{UserId: user.Id, DeviceId: "pixel", PushToken: "old", PushProvider: domain.PushProviderFCM})
+	require.NoError(t, err)
+
+	// Push илгээж байх хооронд app шинэ token бүртгүүлсэн
+	_, err = repo.Register(ctx, domain.UserDevice{UserId: user.Id, DeviceId: "pixel", PushToken: "new", PushProvider: domain.PushProviderFCM})
+	require.NoError(t, err)
+
+	// Хуучин token-ийн хариу ирэхэд шинэ token идэвхгүй болохгүй
+	require.NoError(t, repo.Deactivate(ctx, d.Id, "old"))
+
+	devices, err := repo.ActivePushDevices(ctx, user.Id)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, "new", devices[0].PushToken)
+}
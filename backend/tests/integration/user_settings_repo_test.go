@@ -0,0 +1,117 @@
+//go:build integration
+
+// Package integration contains integration tests
+//
+// File: user_settings_repo_test.go
+// Description: User settings repository integration tests
+package integration
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func TestUserSettingsRepository_Upsert(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewUserSettingsRepository(db)
+	ctx := CreateTestContext()
+
+	user := SeedTestUser(t, db)
+
+	_, err := repo.ByUserID(ctx, user.Id)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	m := domain.DefaultUserSettings(user.Id)
+	m.NotificationPush = false
+	created, err := repo.Upsert(ctx, m)
+	require.NoError(t, err)
+	assert.Greater(t, created.Id, 0)
+
+	// Дахин хадгалахад ижил мөр шинэчлэгдэнэ; false утга хадгалагдана
+	m.Theme = "dark"
+	m.CustomSettings = datatypes.JSON(`{"page_size": 50}`)
+	updated, err := repo.Upsert(ctx, m)
+	require.NoError(t, err)
+	assert.Equal(t, created.Id, updated.Id)
+
+	got, err := repo.ByUserID(ctx, user.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "dark", got.Theme)
+	assert.False(t, got.NotificationPush)
+	assert.JSONEq(t, `{"page_size": 50}`, string(got.CustomSettings))
+}
+
+func TestUserSettingsRepository_Modify(t *testing.T) {
+	db := GetTestDBWithTx(t)
+	repo := repository.NewUserSettingsRepository(db)
+	ctx := CreateTestContext()
+
+	user := SeedTestUser(t, db)
+
+	// Мөр байхгүй бол default утгаас эхэлнэ
+	created, err := repo.Modify(ctx, user.Id, func(m *domain.UserSettings) {
+		assert.Equal(t, "mn", m.Language)
+		m.Theme = "dark"
+	})
+	require.NoError(t, err)
+	assert.Greater(t, created.Id, 0)
+
+	updated, err := repo.Modify(ctx, user.Id, func(m *domain.UserSettings) {
+		assert.Equal(t, "dark", m.Theme)
+		m.NotificationPush = false
+	})
+	require.NoError(t, err)
+	assert.Equal(t, created.Id, updated.Id)
+
+	got, err := repo.ByUserID(ctx, user.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "dark", got.Theme)
+	assert.False(t, got.NotificationPush)
+}
+
+func TestUserSettingsRepository_Modify_Concurrent(t *testing.T) {
+	// Зэрэг transaction-ууд хэрэгтэй тул transaction-гүй холболт
+	db := GetTestDB(t)
+	repo := repository.NewUserSettingsRepository(db)
+	ctx := CreateTestContext()
+
+	user := SeedTestUser(t, db)
+	t.Cleanup(func() {
+		db.Where("user_id = ?", user.Id).Delete(&domain.UserSettings{})
+		db.Unscoped().Where("id = ?", user.Id).Delete(&domain.User{})
+	})
+
+	// Өөр өөр талбар солих хоёр хүсэлт: аль нь ч нөгөөгийнхөө утгыг дарахгүй
+	apply := []func(m *domain.UserSettings){
+		func(m *domain.UserSettings) { m.Theme = "dark" },
+		func(m *domain.UserSettings) { m.Language = "en" },
+	}
+	errs := make(chan error, len(apply))
+	var start sync.WaitGroup
+	start.Add(1)
+	for _, fn := range apply {
+		go func(fn func(m *domain.UserSettings)) {
+			start.Wait()
+			_, err := repo.Modify(ctx, user.Id, fn)
+			errs <- err
+		}(fn)
+	}
+	start.Done()
+	for range apply {
+		require.NoError(t, <-errs)
+	}
+
+	got, err := repo.ByUserID(ctx, user.Id)
+	require.NoError(t, err)
+	assert.Equal(t, "dark", got.Theme)
+	assert.Equal(t, "en", got.Language)
+}
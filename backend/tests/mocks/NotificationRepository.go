@@ -47,6 +47,34 @@ func (_m *NotificationRepository) AllUserIDs(ctx context.Context) ([]int, error)
 	return r0, r1
 }
 
+// CountUsers provides a mock function with given fields: ctx
+func (_m *NotificationRepository) CountUsers(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountUsers")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateGroup provides a mock function with given fields: ctx, g
 func (_m *NotificationRepository) CreateGroup(ctx context.Context, g domain.NotificationGroup) (domain.NotificationGroup, error) {
 	ret := _m.Called(ctx, g)
@@ -259,6 +287,36 @@ func (_m *NotificationRepository) MarkGroupRead(ctx context.Context, userID int,
 	return r0
 }
 
+// ResolveRecipients provides a mock function with given fields: ctx, t
+func (_m *NotificationRepository) ResolveRecipients(ctx context.Context, t domain.NotificationTarget) ([]int, error) {
+	ret := _m.Called(ctx, t)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResolveRecipients")
+	}
+
+	var r0 []int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.NotificationTarget) ([]int, error)); ok {
+		return rf(ctx, t)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, domain.NotificationTarget) []int); ok {
+		r0 = rf(ctx, t)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, domain.NotificationTarget) error); ok {
+		r1 = rf(ctx, t)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewNotificationRepository creates a new instance of NotificationRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewNotificationRepository(t interface {
@@ -17,6 +17,36 @@ type OrganizationRepository struct {
 	mock.Mock
 }
 
+// Ancestors provides a mock function with given fields: ctx, id
+func (_m *OrganizationRepository) Ancestors(ctx context.Context, id int) ([]domain.Organization, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Ancestors")
+	}
+
+	var r0 []domain.Organization
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]domain.Organization, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []domain.Organization); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Organization)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ByID provides a mock function with given fields: ctx, id
 func (_m *OrganizationRepository) ByID(ctx context.Context, id int) (domain.Organization, error) {
 	ret := _m.Called(ctx, id)
@@ -91,6 +121,36 @@ func (_m *OrganizationRepository) Delete(ctx context.Context, id int) error {
 	return r0
 }
 
+// DescendantIDs provides a mock function with given fields: ctx, id
+func (_m *OrganizationRepository) DescendantIDs(ctx context.Context, id int) ([]int, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DescendantIDs")
+	}
+
+	var r0 []int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]int, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []int); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // List provides a mock function with given fields: ctx, p
 func (_m *OrganizationRepository) List(ctx context.Context, p common.PaginationQuery) ([]domain.Organization, int64, int, int, error) {
 	ret := _m.Called(ctx, p)
@@ -142,6 +202,54 @@ func (_m *OrganizationRepository) List(ctx context.Context, p common.PaginationQ
 	return r0, r1, r2, r3, r4
 }
 
+// Move provides a mock function with given fields: ctx, id, parentID
+func (_m *OrganizationRepository) Move(ctx context.Context, id int, parentID *int) error {
+	ret := _m.Called(ctx, id, parentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Move")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, *int) error); ok {
+		r0 = rf(ctx, id, parentID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Subtree provides a mock function with given fields: ctx, rootID
+func (_m *OrganizationRepository) Subtree(ctx context.Context, rootID int) ([]domain.Organization, error) {
+	ret := _m.Called(ctx, rootID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Subtree")
+	}
+
+	var r0 []domain.Organization
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]domain.Organization, error)); ok {
+		return rf(ctx, rootID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []domain.Organization); ok {
+		r0 = rf(ctx, rootID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Organization)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, rootID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Tree provides a mock function with given fields: ctx, rootID
 func (_m *OrganizationRepository) Tree(ctx context.Context, rootID int) ([]domain.Organization, error) {
 	ret := _m.Called(ctx, rootID)
@@ -206,6 +206,36 @@ func (_m *PermissionRepository) List(ctx context.Context, q dto.PermissionQuery)
 	return r0, r1, r2, r3, r4
 }
 
+// TraceUserPermission provides a mock function with given fields: ctx, userID, permissionCode
+func (_m *PermissionRepository) TraceUserPermission(ctx context.Context, userID int, permissionCode string) ([]domain.PermissionGrantTrace, error) {
+	ret := _m.Called(ctx, userID, permissionCode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TraceUserPermission")
+	}
+
+	var r0 []domain.PermissionGrantTrace
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) ([]domain.PermissionGrantTrace, error)); ok {
+		return rf(ctx, userID, permissionCode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) []domain.PermissionGrantTrace); ok {
+		r0 = rf(ctx, userID, permissionCode)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.PermissionGrantTrace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string) error); ok {
+		r1 = rf(ctx, userID, permissionCode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: ctx, id, m
 func (_m *PermissionRepository) Update(ctx context.Context, id int, m domain.Permission) error {
 	ret := _m.Called(ctx, id, m)
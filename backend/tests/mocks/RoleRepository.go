@@ -79,6 +79,36 @@ func (_m *RoleRepository) Delete(ctx context.Context, id int) error {
 	return r0
 }
 
+// DescendantIDs provides a mock function with given fields: ctx, roleID
+func (_m *RoleRepository) DescendantIDs(ctx context.Context, roleID int) ([]int, error) {
+	ret := _m.Called(ctx, roleID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DescendantIDs")
+	}
+
+	var r0 []int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]int, error)); ok {
+		return rf(ctx, roleID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []int); ok {
+		r0 = rf(ctx, roleID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, roleID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUserCount provides a mock function with given fields: uctx, id
 func (_m *RoleRepository) GetUserCount(uctx context.Context, id int) int64 {
 	ret := _m.Called(uctx, id)
@@ -148,6 +178,36 @@ func (_m *RoleRepository) List(ctx context.Context, p dto.RoleListQuery) ([]doma
 	return r0, r1, r2, r3, r4
 }
 
+// MatchingPermissions provides a mock function with given fields: ctx, patterns
+func (_m *RoleRepository) MatchingPermissions(ctx context.Context, patterns []string) ([]domain.Permission, error) {
+	ret := _m.Called(ctx, patterns)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MatchingPermissions")
+	}
+
+	var r0 []domain.Permission
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]domain.Permission, error)); ok {
+		return rf(ctx, patterns)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []domain.Permission); ok {
+		r0 = rf(ctx, patterns)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Permission)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, patterns)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Permissions provides a mock function with given fields: ctx, q
 func (_m *RoleRepository) Permissions(ctx context.Context, q dto.RolePermissionsQuery) ([]domain.Permission, error) {
 	ret := _m.Called(ctx, q)
@@ -178,6 +238,24 @@ func (_m *RoleRepository) Permissions(ctx context.Context, q dto.RolePermissions
 	return r0, r1
 }
 
+// ReplaceParents provides a mock function with given fields: ctx, roleID, parentIDs
+func (_m *RoleRepository) ReplaceParents(ctx context.Context, roleID int, parentIDs []int) error {
+	ret := _m.Called(ctx, roleID, parentIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReplaceParents")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, []int) error); ok {
+		r0 = rf(ctx, roleID, parentIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ReplacePermissions provides a mock function with given fields: ctx, roleID, permIDs
 func (_m *RoleRepository) ReplacePermissions(ctx context.Context, roleID int, permIDs []int) error {
 	ret := _m.Called(ctx, roleID, permIDs)
@@ -214,6 +292,54 @@ func (_m *RoleRepository) Update(ctx context.Context, id int, m domain.Role) err
 	return r0
 }
 
+// UpdateWithParents provides a mock function with given fields: ctx, id, m, parentIDs
+func (_m *RoleRepository) UpdateWithParents(ctx context.Context, id int, m domain.Role, parentIDs []int) error {
+	ret := _m.Called(ctx, id, m, parentIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateWithParents")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, domain.Role, []int) error); ok {
+		r0 = rf(ctx, id, m, parentIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserIDsByRoles provides a mock function with given fields: ctx, roleIDs
+func (_m *RoleRepository) UserIDsByRoles(ctx context.Context, roleIDs []int) ([]int, error) {
+	ret := _m.Called(ctx, roleIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UserIDsByRoles")
+	}
+
+	var r0 []int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int) ([]int, error)); ok {
+		return rf(ctx, roleIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int) []int); ok {
+		r0 = rf(ctx, roleIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int) error); ok {
+		r1 = rf(ctx, roleIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewRoleRepository creates a new instance of RoleRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewRoleRepository(t interface {
@@ -15,17 +15,17 @@ type UserRoleRepository struct {
 	mock.Mock
 }
 
-// AddRolesToUser provides a mock function with given fields: ctx, userID, roleIDs
-func (_m *UserRoleRepository) AddRolesToUser(ctx context.Context, userID int, roleIDs []int) error {
-	ret := _m.Called(ctx, userID, roleIDs)
+// AddRolesToUser provides a mock function with given fields: ctx, userID, orgID, roleIDs
+func (_m *UserRoleRepository) AddRolesToUser(ctx context.Context, userID int, orgID *int, roleIDs []int) error {
+	ret := _m.Called(ctx, userID, orgID, roleIDs)
 
 	if len(ret) == 0 {
 		panic("no return value specified for AddRolesToUser")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, []int) error); ok {
-		r0 = rf(ctx, userID, roleIDs)
+	if rf, ok := ret.Get(0).(func(context.Context, int, *int, []int) error); ok {
+		r0 = rf(ctx, userID, orgID, roleIDs)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -33,17 +33,17 @@ func (_m *UserRoleRepository) AddRolesToUser(ctx context.Context, userID int, ro
 	return r0
 }
 
-// AddUsersToRole provides a mock function with given fields: ctx, roleID, userIDs
-func (_m *UserRoleRepository) AddUsersToRole(ctx context.Context, roleID int, userIDs []int) error {
-	ret := _m.Called(ctx, roleID, userIDs)
+// AddUsersToRole provides a mock function with given fields: ctx, roleID, orgID, userIDs
+func (_m *UserRoleRepository) AddUsersToRole(ctx context.Context, roleID int, orgID *int, userIDs []int) error {
+	ret := _m.Called(ctx, roleID, orgID, userIDs)
 
 	if len(ret) == 0 {
 		panic("no return value specified for AddUsersToRole")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, []int) error); ok {
-		r0 = rf(ctx, roleID, userIDs)
+	if rf, ok := ret.Get(0).(func(context.Context, int, *int, []int) error); ok {
+		r0 = rf(ctx, roleID, orgID, userIDs)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -51,17 +51,17 @@ func (_m *UserRoleRepository) AddUsersToRole(ctx context.Context, roleID int, us
 	return r0
 }
 
-// Remove provides a mock function with given fields: ctx, userID, roleID
-func (_m *UserRoleRepository) Remove(ctx context.Context, userID int, roleID int) error {
-	ret := _m.Called(ctx, userID, roleID)
+// Remove provides a mock function with given fields: ctx, userID, roleID, orgID
+func (_m *UserRoleRepository) Remove(ctx context.Context, userID int, roleID int, orgID *int) error {
+	ret := _m.Called(ctx, userID, roleID, orgID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Remove")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
-		r0 = rf(ctx, userID, roleID)
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, *int) error); ok {
+		r0 = rf(ctx, userID, roleID, orgID)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -0,0 +1,137 @@
+// Package auth provides implementation for auth
+//
+// File: authenticate_test.go
+// Description: Tests for unified SSO / local session authentication
+package auth_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"templatev25/internal/auth"
+	"templatev25/internal/middleware"
+
+	"git.gerege.mn/backend-packages/config"
+	ssoclient "git.gerege.mn/backend-packages/sso-client"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeSessionStore serves local sessions from memory
+type fakeSessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*middleware.SessionData
+}
+
+func (s *fakeSessionStore) Get(ctx context.Context, id string) (*middleware.SessionData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions[id], nil
+}
+
+func (s *fakeSessionStore) Update(ctx context.Context, session *middleware.SessionData) error {
+	return nil
+}
+
+func (s *fakeSessionStore) Touch(ctx context.Context, id string, at time.Time) error {
+	return nil
+}
+
+func newAuthenticateApp(store middleware.SessionStore, checker auth.PermissionChecker) *fiber.App {
+	// SSO is not configured, so every non-local request falls through to 401
+	requireAuth := auth.Authenticate(&config.Config{}, zap.NewNop(), nil, store)
+
+	app := fiber.New()
+	app.Get("/test", requireAuth, auth.RequirePermission(checker, "admin.user.read"), func(c *fiber.Ctx) error {
+		return c.SendString("OK")
+	})
+	return app
+}
+
+func TestAuthenticate_LocalSession(t *testing.T) {
+	store := &fakeSessionStore{sessions: map[string]*middleware.SessionData{
+		"local-token": {
+			SessionID: "local-token",
+			UserID:    7,
+			Email:     "admin@example.com",
+			OrgID:     3,
+			ExpiresAt: time.Now().Add(time.Hour),
+		},
+		"expired-token": {
+			SessionID: "expired-token",
+			UserID:    8,
+			ExpiresAt: time.Now().Add(-time.Minute),
+		},
+	}}
+
+	checker := &mockPermissionChecker{}
+	checker.On("HasPermission", mock.Anything, 7, "admin.user.read").Return(true, nil)
+	app := newAuthenticateApp(store, checker)
+
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus int
+	}{
+		{"local session", "Bearer local-token", fiber.StatusOK},
+		{"expired local session", "Bearer expired-token", fiber.StatusUnauthorized},
+		{"unknown token falls back to SSO", "Bearer sso-sid", fiber.StatusUnauthorized},
+		{"no token", "", fiber.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/test", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+		})
+	}
+
+	checker.AssertExpectations(t)
+}
+
+func TestAuthenticate_LocalSessionClaims(t *testing.T) {
+	store := &fakeSessionStore{sessions: map[string]*middleware.SessionData{
+		"local-token": {
+			SessionID: "local-token",
+			UserID:    7,
+			Email:     "admin@example.com",
+			OrgID:     3,
+			ExpiresAt: time.Now().Add(time.Hour),
+		},
+	}}
+
+	var (
+		claims    *ssoclient.Claims
+		sessionID any
+	)
+	app := fiber.New()
+	app.Get("/test", auth.Authenticate(&config.Config{}, zap.NewNop(), nil, store), func(c *fiber.Ctx) error {
+		claims, _ = ssoclient.GetClaims(c)
+		sessionID = c.Locals("session_id")
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer local-token")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	require.NotNil(t, claims)
+	assert.Equal(t, 7, claims.UserID)
+	assert.Equal(t, 3, claims.OrgID)
+	assert.Equal(t, "admin@example.com", claims.Username)
+	assert.Equal(t, "local-token", sessionID)
+}
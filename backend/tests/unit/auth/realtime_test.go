@@ -0,0 +1,177 @@
+// Package auth provides implementation for auth
+//
+// File: realtime_test.go
+// Description: Tests for realtime ticket authentication and session checks
+package auth_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"templatev25/internal/auth"
+	"templatev25/internal/middleware"
+
+	"git.gerege.mn/backend-packages/config"
+	ssoclient "git.gerege.mn/backend-packages/sso-client"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeTicketStore keeps realtime tickets in memory
+type fakeTicketStore struct {
+	mu      sync.Mutex
+	tickets map[string]*auth.RealtimeTicket
+}
+
+func (s *fakeTicketStore) StoreRealtimeTicket(ctx context.Context, ticket string, data *auth.RealtimeTicket, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tickets[ticket] = data
+	return nil
+}
+
+func (s *fakeTicketStore) TakeRealtimeTicket(ctx context.Context, ticket string) (*auth.RealtimeTicket, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data := s.tickets[ticket]
+	delete(s.tickets, ticket)
+	return data, nil
+}
+
+// newRealtimeApp mounts the ticket endpoint and a stream route that reports
+// the authenticated user and the result of the session check
+func newRealtimeApp(store *fakeSessionStore, tickets *fakeTicketStore) (*fiber.App, *func(context.Context) bool) {
+	cfg := &config.Config{}
+	log := zap.NewNop()
+	rt := auth.NewRealtimeAuth(cfg, log, nil, store, tickets)
+
+	var check func(context.Context) bool
+	app := fiber.New()
+	app.Post("/realtime/ticket", auth.Authenticate(cfg, log, nil, store), func(c *fiber.Ctx) error {
+		ticket, expiresAt, err := rt.IssueTicket(c)
+		if err != nil {
+			return err
+		}
+		return c.JSON(fiber.Map{"ticket": ticket, "expires_at": expiresAt})
+	})
+	app.Get("/stream", rt.Authenticate(), func(c *fiber.Ctx) error {
+		check = auth.RealtimeSessionCheck(c)
+		return c.JSON(fiber.Map{"user_id": ssoclient.GetUserID(c)})
+	})
+	return app, &check
+}
+
+func issueTicket(t *testing.T, app *fiber.App, token string) string {
+	t.Helper()
+	req := httptest.NewRequest("POST", "/realtime/ticket", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Ticket    string    `json:"ticket"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.NotEmpty(t, body.Ticket)
+	assert.WithinDuration(t, time.Now().Add(auth.RealtimeTicketTTL), body.ExpiresAt, 5*time.Second)
+	return body.Ticket
+}
+
+func TestRealtimeAuth_TicketIsSingleUse(t *testing.T) {
+	store := &fakeSessionStore{sessions: map[string]*middleware.SessionData{
+		"local-token": {SessionID: "local-token", UserID: 7, ExpiresAt: time.Now().Add(time.Hour)},
+	}}
+	tickets := &fakeTicketStore{tickets: map[string]*auth.RealtimeTicket{}}
+	app, _ := newRealtimeApp(store, tickets)
+
+	ticket := issueTicket(t, app, "local-token")
+	assert.Equal(t, &auth.RealtimeTicket{SessionID: "local-token", Local: true}, tickets.tickets[ticket])
+
+	// No Authorization header: the ticket alone authenticates the stream
+	resp, err := app.Test(httptest.NewRequest("GET", "/stream?ticket="+ticket, nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	body, _ := io.ReadAll(resp.Body)
+	assert.JSONEq(t, `{"user_id":7}`, string(body))
+
+	// Second use is rejected
+	resp, err = app.Test(httptest.NewRequest("GET", "/stream?ticket="+ticket, nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestRealtimeAuth_Rejects(t *testing.T) {
+	store := &fakeSessionStore{sessions: map[string]*middleware.SessionData{
+		"expired-token": {SessionID: "expired-token", UserID: 8, ExpiresAt: time.Now().Add(-time.Minute)},
+	}}
+	tickets := &fakeTicketStore{tickets: map[string]*auth.RealtimeTicket{
+		"expired-session": {SessionID: "expired-token", Local: true},
+		"deleted-session": {SessionID: "logged-out", Local: true},
+		"sso-session":     {SessionID: "sso-sid"}, // SSO is not configured
+	}}
+	app, _ := newRealtimeApp(store, tickets)
+
+	for _, ticket := range []string{"unknown", "expired-session", "deleted-session", "sso-session"} {
+		t.Run(ticket, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", "/stream?ticket="+ticket, nil))
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+		})
+	}
+
+	// Issuing a ticket requires a session
+	resp, err := app.Test(httptest.NewRequest("POST", "/realtime/ticket", nil))
+	require.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestRealtimeAuth_SessionCheckFollowsLogout(t *testing.T) {
+	store := &fakeSessionStore{sessions: map[string]*middleware.SessionData{
+		"local-token": {SessionID: "local-token", UserID: 7, ExpiresAt: time.Now().Add(time.Hour)},
+	}}
+	tickets := &fakeTicketStore{tickets: map[string]*auth.RealtimeTicket{}}
+	app, check := newRealtimeApp(store, tickets)
+
+	ticket := issueTicket(t, app, "local-token")
+	resp, err := app.Test(httptest.NewRequest("GET", "/stream?ticket="+ticket, nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.NotNil(t, *check)
+
+	assert.True(t, (*check)(context.Background()))
+
+	// Logout deletes the session; the open connection must notice
+	store.mu.Lock()
+	delete(store.sessions, "local-token")
+	store.mu.Unlock()
+	assert.False(t, (*check)(context.Background()))
+}
+
+func TestRealtimeAuth_HeaderSessionAlsoChecked(t *testing.T) {
+	store := &fakeSessionStore{sessions: map[string]*middleware.SessionData{
+		"local-token": {SessionID: "local-token", UserID: 7, ExpiresAt: time.Now().Add(time.Hour)},
+	}}
+	app, check := newRealtimeApp(store, &fakeTicketStore{tickets: map[string]*auth.RealtimeTicket{}})
+
+	req := httptest.NewRequest("GET", "/stream", nil)
+	req.Header.Set("Authorization", "Bearer local-token")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	require.NotNil(t, *check)
+
+	store.mu.Lock()
+	store.sessions["local-token"].ExpiresAt = time.Now().Add(-time.Second)
+	store.mu.Unlock()
+	assert.False(t, (*check)(context.Background()))
+}
@@ -0,0 +1,395 @@
+// Package service provides implementation for service
+//
+// File: chat_room_service_test.go
+// Description: Unit tests for chat room service
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/http/dto"
+	"templatev25/internal/realtime"
+	"templatev25/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// mockChatRoomRepository for testing
+type mockChatRoomRepository struct {
+	mock.Mock
+}
+
+func (m *mockChatRoomRepository) ListForUser(ctx context.Context, userID int, q dto.ChatRoomListQuery) ([]domain.ChatRoomSummary, int64, int, int, error) {
+	args := m.Called(ctx, userID, q)
+	if args.Get(0) == nil {
+		return nil, 0, 0, 0, args.Error(4)
+	}
+	return args.Get(0).([]domain.ChatRoomSummary), args.Get(1).(int64), args.Get(2).(int), args.Get(3).(int), args.Error(4)
+}
+
+func (m *mockChatRoomRepository) RoomByID(ctx context.Context, id int) (domain.ChatRoom, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.ChatRoom), args.Error(1)
+}
+
+func (m *mockChatRoomRepository) CreateRoom(ctx context.Context, room *domain.ChatRoom, members []domain.ChatRoomMember) error {
+	args := m.Called(ctx, room, members)
+	return args.Error(0)
+}
+
+func (m *mockChatRoomRepository) FindOrCreateDirect(ctx context.Context, room *domain.ChatRoom, members []domain.ChatRoomMember) (bool, error) {
+	args := m.Called(ctx, room, members)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockChatRoomRepository) UpdateRoom(ctx context.Context, id int, fields map[string]any) error {
+	args := m.Called(ctx, id, fields)
+	return args.Error(0)
+}
+
+func (m *mockChatRoomRepository) DeleteRoom(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *mockChatRoomRepository) Member(ctx context.Context, roomID, userID int) (domain.ChatRoomMember, error) {
+	args := m.Called(ctx, roomID, userID)
+	return args.Get(0).(domain.ChatRoomMember), args.Error(1)
+}
+
+func (m *mockChatRoomRepository) Members(ctx context.Context, roomID int) ([]domain.ChatRoomMember, error) {
+	args := m.Called(ctx, roomID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ChatRoomMember), args.Error(1)
+}
+
+func (m *mockChatRoomRepository) MemberUserIDs(ctx context.Context, roomID int) ([]int, error) {
+	args := m.Called(ctx, roomID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *mockChatRoomRepository) AddMembers(ctx context.Context, members []domain.ChatRoomMember) error {
+	args := m.Called(ctx, members)
+	return args.Error(0)
+}
+
+func (m *mockChatRoomRepository) RemoveMember(ctx context.Context, roomID, userID int, at time.Time) error {
+	args := m.Called(ctx, roomID, userID, at)
+	return args.Error(0)
+}
+
+func (m *mockChatRoomRepository) SetMemberRole(ctx context.Context, roomID, userID int, role string) error {
+	args := m.Called(ctx, roomID, userID, role)
+	return args.Error(0)
+}
+
+func (m *mockChatRoomRepository) MarkRead(ctx context.Context, roomID, userID int, at time.Time) error {
+	args := m.Called(ctx, roomID, userID, at)
+	return args.Error(0)
+}
+
+func (m *mockChatRoomRepository) UnreadTotal(ctx context.Context, userID int) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockChatRoomRepository) Messages(ctx context.Context, roomID int, q dto.ChatMessageListQuery) ([]domain.ChatMessage, int64, int, int, error) {
+	args := m.Called(ctx, roomID, q)
+	if args.Get(0) == nil {
+		return nil, 0, 0, 0, args.Error(4)
+	}
+	return args.Get(0).([]domain.ChatMessage), args.Get(1).(int64), args.Get(2).(int), args.Get(3).(int), args.Error(4)
+}
+
+func (m *mockChatRoomRepository) MessageByID(ctx context.Context, id int) (domain.ChatMessage, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.ChatMessage), args.Error(1)
+}
+
+func (m *mockChatRoomRepository) CreateMessage(ctx context.Context, msg *domain.ChatMessage) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func (m *mockChatRoomRepository) EditMessage(ctx context.Context, id int, content string, at time.Time) error {
+	args := m.Called(ctx, id, content, at)
+	return args.Error(0)
+}
+
+func (m *mockChatRoomRepository) SoftDeleteMessage(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// recordingPublisher нь Publish, PublishAll-ийн дуудлагуудыг хадгална.
+type recordingPublisher struct {
+	userIDs [][]int
+	events  []realtime.Event
+	all     []realtime.Event
+}
+
+func (p *recordingPublisher) Publish(userIDs []int, ev realtime.Event) {
+	p.userIDs = append(p.userIDs, userIDs)
+	p.events = append(p.events, ev)
+}
+
+func (p *recordingPublisher) PublishAll(ev realtime.Event) {
+	p.all = append(p.all, ev)
+}
+
+func chatMember(roomID, userID int, role string) domain.ChatRoomMember {
+	return domain.ChatRoomMember{RoomId: roomID, UserId: userID, Role: role}
+}
+
+func intPtr(i int) *int { return &i }
+
+func TestChatRoomService_CreateRoom(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("direct room reuses existing", func(t *testing.T) {
+		repo := new(mockChatRoomRepository)
+		repo.On("FindOrCreateDirect", ctx, mock.Anything, []domain.ChatRoomMember{
+			chatMember(0, 1, domain.ChatRoleMember),
+			chatMember(0, 2, domain.ChatRoleMember),
+		}).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.ChatRoom).Id = 9
+		}).Return(false, nil)
+
+		svc := service.NewChatRoomService(repo, &recordingPublisher{}, zap.NewNop())
+		room, err := svc.CreateRoom(ctx, 1, dto.ChatRoomCreateDto{Type: domain.ChatRoomDirect, MemberIDs: []int{2, 2, 1}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 9, room.Id)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("direct room needs one other member", func(t *testing.T) {
+		svc := service.NewChatRoomService(new(mockChatRoomRepository), &recordingPublisher{}, zap.NewNop())
+		_, err := svc.CreateRoom(ctx, 1, dto.ChatRoomCreateDto{Type: domain.ChatRoomDirect, MemberIDs: []int{1}})
+
+		assert.ErrorIs(t, err, service.ErrChatInvalidRequest)
+	})
+
+	t.Run("group creator becomes owner", func(t *testing.T) {
+		repo := new(mockChatRoomRepository)
+		repo.On("CreateRoom", ctx, mock.Anything, []domain.ChatRoomMember{
+			chatMember(0, 1, domain.ChatRoleOwner),
+			chatMember(0, 2, domain.ChatRoleMember),
+			chatMember(0, 3, domain.ChatRoleMember),
+		}).Return(nil)
+		pub := &recordingPublisher{}
+
+		svc := service.NewChatRoomService(repo, pub, zap.NewNop())
+		_, err := svc.CreateRoom(ctx, 1, dto.ChatRoomCreateDto{Type: domain.ChatRoomGroup, Name: " Team ", MemberIDs: []int{2, 3}})
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+		assert.Equal(t, [][]int{{2, 3}}, pub.userIDs)
+		assert.Equal(t, service.ChatEventMemberAdded, pub.events[0].Type)
+	})
+}
+
+func TestChatRoomService_SendMessage(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("publishes to room members", func(t *testing.T) {
+		repo := new(mockChatRoomRepository)
+		repo.On("Member", ctx, 5, 1).Return(chatMember(5, 1, domain.ChatRoleMember), nil)
+		repo.On("RoomByID", ctx, 5).Return(domain.ChatRoom{Id: 5, Type: domain.ChatRoomGroup}, nil)
+		repo.On("CreateMessage", ctx, mock.MatchedBy(func(m *domain.ChatMessage) bool {
+			return m.RoomId == 5 && *m.SenderId == 1 && m.MessageType == domain.ChatMessageText
+		})).Run(func(args mock.Arguments) {
+			args.Get(1).(*domain.ChatMessage).Id = 42
+		}).Return(nil)
+		repo.On("MemberUserIDs", ctx, 5).Return([]int{1, 2}, nil)
+		pub := &recordingPublisher{}
+
+		svc := service.NewChatRoomService(repo, pub, zap.NewNop())
+		msg, err := svc.SendMessage(ctx, 1, 5, dto.ChatMessageSendDto{Content: "hello"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 42, msg.Id)
+		assert.Equal(t, [][]int{{1, 2}}, pub.userIDs)
+		assert.Equal(t, service.ChatEventMessageCreated, pub.events[0].Type)
+	})
+
+	t.Run("non member", func(t *testing.T) {
+		repo := new(mockChatRoomRepository)
+		repo.On("Member", ctx, 5, 1).Return(domain.ChatRoomMember{}, gorm.ErrRecordNotFound)
+
+		svc := service.NewChatRoomService(repo, &recordingPublisher{}, zap.NewNop())
+		_, err := svc.SendMessage(ctx, 1, 5, dto.ChatMessageSendDto{Content: "hello"})
+
+		assert.ErrorIs(t, err, service.ErrChatRoomNotFound)
+	})
+
+	t.Run("channel is read-only for members", func(t *testing.T) {
+		repo := new(mockChatRoomRepository)
+		repo.On("Member", ctx, 5, 1).Return(chatMember(5, 1, domain.ChatRoleMember), nil)
+		repo.On("RoomByID", ctx, 5).Return(domain.ChatRoom{Id: 5, Type: domain.ChatRoomChannel}, nil)
+
+		svc := service.NewChatRoomService(repo, &recordingPublisher{}, zap.NewNop())
+		_, err := svc.SendMessage(ctx, 1, 5, dto.ChatMessageSendDto{Content: "hello"})
+
+		assert.ErrorIs(t, err, service.ErrChatForbidden)
+		repo.AssertNotCalled(t, "CreateMessage", mock.Anything, mock.Anything)
+	})
+
+	t.Run("parent from another room", func(t *testing.T) {
+		repo := new(mockChatRoomRepository)
+		repo.On("Member", ctx, 5, 1).Return(chatMember(5, 1, domain.ChatRoleMember), nil)
+		repo.On("RoomByID", ctx, 5).Return(domain.ChatRoom{Id: 5, Type: domain.ChatRoomGroup}, nil)
+		repo.On("MessageByID", ctx, 7).Return(domain.ChatMessage{Id: 7, RoomId: 6}, nil)
+
+		svc := service.NewChatRoomService(repo, &recordingPublisher{}, zap.NewNop())
+		_, err := svc.SendMessage(ctx, 1, 5, dto.ChatMessageSendDto{Content: "re", ParentId: intPtr(7)})
+
+		assert.ErrorIs(t, err, service.ErrChatInvalidRequest)
+	})
+}
+
+func TestChatRoomService_EditAndDeleteMessage(t *testing.T) {
+	ctx := context.Background()
+
+	newRepo := func(role string) *mockChatRoomRepository {
+		repo := new(mockChatRoomRepository)
+		repo.On("Member", ctx, 5, 2).Return(chatMember(5, 2, role), nil)
+		repo.On("MessageByID", ctx, 7).Return(domain.ChatMessage{Id: 7, RoomId: 5, SenderId: intPtr(1), Content: "hi"}, nil)
+		repo.On("MemberUserIDs", ctx, 5).Return([]int{1, 2}, nil)
+		return repo
+	}
+
+	t.Run("only sender can edit", func(t *testing.T) {
+		repo := newRepo(domain.ChatRoleOwner)
+		svc := service.NewChatRoomService(repo, &recordingPublisher{}, zap.NewNop())
+
+		_, err := svc.EditMessage(ctx, 2, 5, 7, "changed")
+
+		assert.ErrorIs(t, err, service.ErrChatForbidden)
+		repo.AssertNotCalled(t, "EditMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("member cannot delete others", func(t *testing.T) {
+		repo := newRepo(domain.ChatRoleMember)
+		svc := service.NewChatRoomService(repo, &recordingPublisher{}, zap.NewNop())
+
+		err := svc.DeleteMessage(ctx, 2, 5, 7)
+
+		assert.ErrorIs(t, err, service.ErrChatForbidden)
+	})
+
+	t.Run("moderator deletes others", func(t *testing.T) {
+		repo := newRepo(domain.ChatRoleModerator)
+		repo.On("SoftDeleteMessage", ctx, 7).Return(nil)
+		pub := &recordingPublisher{}
+		svc := service.NewChatRoomService(repo, pub, zap.NewNop())
+
+		err := svc.DeleteMessage(ctx, 2, 5, 7)
+
+		assert.NoError(t, err)
+		assert.Equal(t, service.ChatEventMessageDeleted, pub.events[0].Type)
+	})
+
+	t.Run("message from another room", func(t *testing.T) {
+		repo := new(mockChatRoomRepository)
+		repo.On("Member", ctx, 4, 1).Return(chatMember(4, 1, domain.ChatRoleMember), nil)
+		repo.On("MessageByID", ctx, 7).Return(domain.ChatMessage{Id: 7, RoomId: 5, SenderId: intPtr(1)}, nil)
+		svc := service.NewChatRoomService(repo, &recordingPublisher{}, zap.NewNop())
+
+		_, err := svc.EditMessage(ctx, 1, 4, 7, "changed")
+
+		assert.ErrorIs(t, err, service.ErrChatMessageNotFound)
+	})
+}
+
+func TestChatRoomService_Messages_RedactsDeleted(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockChatRoomRepository)
+	repo.On("Member", ctx, 5, 1).Return(chatMember(5, 1, domain.ChatRoleMember), nil)
+	repo.On("Messages", ctx, 5, dto.ChatMessageListQuery{}).Return([]domain.ChatMessage{
+		{Id: 2, Content: "secret", FileUrl: "/f", IsDeleted: true},
+		{Id: 1, Content: "visible"},
+	}, int64(2), 1, 20, nil)
+
+	svc := service.NewChatRoomService(repo, &recordingPublisher{}, zap.NewNop())
+	items, total, _, _, err := svc.Messages(ctx, 1, 5, dto.ChatMessageListQuery{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Empty(t, items[0].Content)
+	assert.Empty(t, items[0].FileUrl)
+	assert.Equal(t, "visible", items[1].Content)
+}
+
+func TestChatRoomService_RemoveMember(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("member leaves", func(t *testing.T) {
+		repo := new(mockChatRoomRepository)
+		repo.On("Member", ctx, 5, 2).Return(chatMember(5, 2, domain.ChatRoleMember), nil)
+		repo.On("MemberUserIDs", ctx, 5).Return([]int{1, 2}, nil)
+		repo.On("RemoveMember", ctx, 5, 2, mock.Anything).Return(nil)
+		pub := &recordingPublisher{}
+
+		svc := service.NewChatRoomService(repo, pub, zap.NewNop())
+		err := svc.RemoveMember(ctx, 2, 5, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, [][]int{{1, 2}}, pub.userIDs, "removed member is notified too")
+	})
+
+	t.Run("owner cannot leave", func(t *testing.T) {
+		repo := new(mockChatRoomRepository)
+		repo.On("Member", ctx, 5, 1).Return(chatMember(5, 1, domain.ChatRoleOwner), nil)
+
+		svc := service.NewChatRoomService(repo, &recordingPublisher{}, zap.NewNop())
+		err := svc.RemoveMember(ctx, 1, 5, 1)
+
+		assert.ErrorIs(t, err, service.ErrChatOwnerLeave)
+	})
+
+	t.Run("admin cannot remove admin", func(t *testing.T) {
+		repo := new(mockChatRoomRepository)
+		repo.On("Member", ctx, 5, 2).Return(chatMember(5, 2, domain.ChatRoleAdmin), nil)
+		repo.On("Member", ctx, 5, 3).Return(chatMember(5, 3, domain.ChatRoleAdmin), nil)
+
+		svc := service.NewChatRoomService(repo, &recordingPublisher{}, zap.NewNop())
+		err := svc.RemoveMember(ctx, 2, 5, 3)
+
+		assert.ErrorIs(t, err, service.ErrChatForbidden)
+	})
+
+	t.Run("member cannot remove others", func(t *testing.T) {
+		repo := new(mockChatRoomRepository)
+		repo.On("Member", ctx, 5, 2).Return(chatMember(5, 2, domain.ChatRoleMember), nil)
+
+		svc := service.NewChatRoomService(repo, &recordingPublisher{}, zap.NewNop())
+		err := svc.RemoveMember(ctx, 2, 5, 3)
+
+		assert.ErrorIs(t, err, service.ErrChatForbidden)
+	})
+}
+
+func TestChatRoomService_AddMembers_DirectRoom(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockChatRoomRepository)
+	repo.On("Member", ctx, 5, 1).Return(chatMember(5, 1, domain.ChatRoleOwner), nil)
+	repo.On("RoomByID", ctx, 5).Return(domain.ChatRoom{Id: 5, Type: domain.ChatRoomDirect}, nil)
+
+	svc := service.NewChatRoomService(repo, &recordingPublisher{}, zap.NewNop())
+	_, err := svc.AddMembers(ctx, 1, 5, []int{3})
+
+	assert.ErrorIs(t, err, service.ErrChatInvalidRequest)
+	repo.AssertNotCalled(t, "AddMembers", mock.Anything, mock.Anything)
+}
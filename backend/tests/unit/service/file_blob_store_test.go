@@ -0,0 +1,196 @@
+// Package service provides implementation for service
+//
+// File: file_blob_store_test.go
+// Description: Unit tests for content-addressed deduplication of uploads
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/service"
+	"templatev25/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func sha256Of(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// expectNewBlob нь content анх удаа хадгалагдаж id-тай blob болохыг хүлээнэ.
+// Бүртгэгдсэн blob (storage key-тэй нь) буцаах pointer-т бичигдэнэ.
+func expectNewBlob(blobs *mocks.FileBlobRepository, isPublic bool, content string, id int) *domain.FileBlob {
+	checksum := sha256Of(content)
+	blob := &domain.FileBlob{}
+	blobs.On("Acquire", mock.Anything, "local", isPublic, checksum).Return(domain.FileBlob{}, gorm.ErrRecordNotFound).Once()
+	blobs.On("Create", mock.Anything, mock.MatchedBy(func(b domain.FileBlob) bool { return b.Checksum == checksum })).
+		Return(func(_ context.Context, b domain.FileBlob) (domain.FileBlob, bool, error) {
+			b.Id, b.RefCount = id, 1
+			*blob = b
+			return b, true, nil
+		}).Once()
+	return blob
+}
+
+// expectSharedBlob нь ижил агуулгын дараагийн upload blob-ийн reference авахыг хүлээнэ
+func expectSharedBlob(blobs *mocks.FileBlobRepository, isPublic bool, content string, blob *domain.FileBlob) {
+	blobs.On("Acquire", mock.Anything, "local", isPublic, sha256Of(content)).
+		Return(func(context.Context, string, bool, string) (domain.FileBlob, error) {
+			b := *blob
+			b.RefCount++
+			return b, nil
+		}).Once()
+}
+
+func TestPublicFileService_DeduplicatesContent(t *testing.T) {
+	svc, repo, files, blobs, dir := newPublicFileService(t)
+	ctx := context.Background()
+	repo.On("Create", ctx, mock.AnythingOfType("domain.PublicFile")).
+		Return(func(_ context.Context, m domain.PublicFile) (domain.PublicFile, error) { return m, nil })
+	rows := expectFileCreate(files, 3)
+	same := expectNewBlob(blobs, true, "same content", 1)
+	expectSharedBlob(blobs, true, "same content", same)
+	expectNewBlob(blobs, true, "other content", 2)
+
+	first, err := svc.Upload(ctx, 10, multipartFile(t, "a.txt", "same content"), "", "")
+	require.NoError(t, err)
+	second, err := svc.Upload(ctx, 11, multipartFile(t, "b.txt", "same content"), "", "")
+	require.NoError(t, err)
+	_, err = svc.Upload(ctx, 10, multipartFile(t, "c.txt", "other content"), "", "")
+	require.NoError(t, err)
+
+	// Ижил агуулга нэг л удаа хадгалагдсан ч URL тус бүртэй
+	assert.NotEqual(t, first.FileUrl, second.FileUrl)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	// files мөр бүр SHA-256, хэмжээ, MIME, upload хийсэн хэрэглэгчтэй
+	require.Len(t, *rows, 3)
+	a, b := (*rows)[0], (*rows)[1]
+	assert.Equal(t, sha256Of("same content"), a.Checksum)
+	assert.Equal(t, int64(len("same content")), a.FileSize)
+	assert.Equal(t, "text/plain", a.MimeType)
+	assert.True(t, a.IsPublic)
+	assert.Equal(t, first.Name+".txt", a.StoredName)
+	assert.Equal(t, "a.txt", a.OriginalName)
+	require.NotNil(t, a.UserId)
+	require.NotNil(t, b.UserId)
+	assert.Equal(t, 10, *a.UserId)
+	assert.Equal(t, 11, *b.UserId)
+	assert.Equal(t, a.StoragePath, b.StoragePath)
+	assert.Equal(t, *a.BlobId, *b.BlobId)
+}
+
+func TestPublicFileService_DeleteKeepsSharedContent(t *testing.T) {
+	svc, repo, files, blobs, dir := newPublicFileService(t)
+	ctx := context.Background()
+	repo.On("Create", ctx, mock.AnythingOfType("domain.PublicFile")).
+		Return(func(_ context.Context, m domain.PublicFile) (domain.PublicFile, error) { return m, nil })
+	rows := expectFileCreate(files, 2)
+	blob := expectNewBlob(blobs, true, "shared", 1)
+	expectSharedBlob(blobs, true, "shared", blob)
+
+	first, err := svc.Upload(ctx, 10, multipartFile(t, "a.txt", "shared"), "", "")
+	require.NoError(t, err)
+	second, err := svc.Upload(ctx, 10, multipartFile(t, "b.txt", "shared"), "", "")
+	require.NoError(t, err)
+
+	// Устгасан upload-ийн мөр дахин олдохгүй
+	files.On("GetByStoredName", ctx, first.Name+first.Extension).Return((*rows)[0], nil).Once()
+	files.On("GetByStoredName", ctx, first.Name+first.Extension).Return(domain.File{}, gorm.ErrRecordNotFound).Once()
+	files.On("GetByStoredName", ctx, second.Name+second.Extension).Return((*rows)[1], nil).Twice()
+	files.On("Delete", ctx, 1).Return(nil).Once()
+	files.On("Delete", ctx, 2).Return(nil).Once()
+	blobs.On("Release", ctx, 1).Return(*blob, false, nil).Once()
+	blobs.On("Release", ctx, 1).Return(*blob, true, nil).Once()
+
+	repo.On("GetByName", ctx, first.Name).Return(domain.PublicFile{Id: 1, Name: first.Name, Extension: first.Extension}, nil)
+	repo.On("DeleteByID", ctx, 1).Return(domain.PublicFile{Id: 1}, nil)
+	require.NoError(t, svc.Delete(ctx, first.Name))
+
+	_, err = svc.Open(ctx, first.Name+first.Extension, "", "")
+	assert.ErrorIs(t, err, service.ErrFileNotFound)
+	obj, err := svc.Open(ctx, second.Name+second.Extension, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "shared", readObject(t, obj))
+
+	// Сүүлийн reference-тэй хамт агуулга устна
+	repo.On("GetByName", ctx, second.Name).Return(domain.PublicFile{Id: 2, Name: second.Name, Extension: second.Extension}, nil)
+	repo.On("DeleteByID", ctx, 2).Return(domain.PublicFile{Id: 2}, nil)
+	require.NoError(t, svc.Delete(ctx, second.Name))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestPublicFileService_Open_PrivateRecordIsNotServed(t *testing.T) {
+	svc, _, files, _, dir := newPublicFileService(t)
+	ctx := context.Background()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "p.txt"), []byte("secret"), 0o600))
+	files.On("GetByStoredName", ctx, "p.txt").Return(domain.File{Id: 1, StoredName: "p.txt", StoragePath: "p.txt", IsPublic: false}, nil)
+
+	_, err := svc.Open(ctx, "p.txt", "", "")
+	assert.ErrorIs(t, err, service.ErrFileNotFound)
+}
+
+func TestPrivateFileService_DeduplicatesAcrossOwners(t *testing.T) {
+	svc, repo, blobs, store := newPrivateFileService(t)
+	ctx := context.Background()
+	expectFileCreate(repo, 2)
+	blob := expectNewBlob(blobs, false, "identical", 1)
+	expectSharedBlob(blobs, false, "identical", blob)
+
+	mine, err := svc.Upload(ctx, 10, multipartFile(t, "statement.txt", "identical"))
+	require.NoError(t, err)
+	theirs, err := svc.Upload(ctx, 11, multipartFile(t, "copy.txt", "identical"))
+	require.NoError(t, err)
+	assert.NotEqual(t, mine.StoredName, theirs.StoredName)
+	assert.Equal(t, mine.StoragePath, theirs.StoragePath)
+	assert.Equal(t, sha256Of("identical"), mine.Checksum)
+
+	repo.On("GetByStoredName", ctx, mine.StoredName).Return(mine, nil)
+	repo.On("GetByStoredName", ctx, theirs.StoredName).Return(theirs, nil)
+	repo.On("Delete", ctx, mine.Id).Return(nil).Once()
+	repo.On("Delete", ctx, theirs.Id).Return(nil).Once()
+	repo.On("IncrementDownloads", ctx, theirs.Id).Return(nil).Once()
+	blobs.On("Release", ctx, 1).Return(*blob, false, nil).Once()
+	blobs.On("Release", ctx, 1).Return(*blob, true, nil).Once()
+
+	// Агуулга хуваалцсан ч хандах эрх бүртгэл бүрээр
+	_, _, err = svc.Open(ctx, service.FileAccessor{UserID: 11}, mine.StoredName)
+	assert.ErrorIs(t, err, service.ErrFileForbidden)
+
+	require.NoError(t, svc.Delete(ctx, service.FileAccessor{UserID: 10}, mine.StoredName))
+	obj, _, err := svc.Open(ctx, service.FileAccessor{UserID: 11}, theirs.StoredName)
+	require.NoError(t, err)
+	assert.Equal(t, "identical", readObject(t, obj))
+
+	require.NoError(t, svc.Delete(ctx, service.FileAccessor{UserID: 11}, theirs.StoredName))
+	_, err = store.Stat(ctx, theirs.StoragePath)
+	assert.Error(t, err)
+}
+
+func TestPrivateFileService_DeleteLegacyRecord(t *testing.T) {
+	svc, repo, _, store := newPrivateFileService(t)
+	ctx := context.Background()
+	require.NoError(t, store.Put(ctx, "old.txt", strings.NewReader("x"), 1, "text/plain"))
+	owner := 10
+	repo.On("GetByStoredName", ctx, "old.txt").Return(domain.File{Id: 1, UserId: &owner, StoredName: "old.txt", StoragePath: "old.txt"}, nil)
+	repo.On("Delete", ctx, 1).Return(nil).Once()
+
+	require.NoError(t, svc.Delete(ctx, service.FileAccessor{UserID: 10}, "old.txt"))
+	_, err := store.Stat(ctx, "old.txt")
+	assert.Error(t, err)
+}
@@ -0,0 +1,222 @@
+// Package service provides implementation for service
+//
+// File: job_service_test.go
+// Description: Unit tests for scheduled job service
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/http/dto"
+	"templatev25/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// mockJobRepository for testing
+type mockJobRepository struct {
+	mock.Mock
+}
+
+func (m *mockJobRepository) List(ctx context.Context, q dto.JobListQuery) ([]domain.ScheduledJob, int64, int, int, error) {
+	args := m.Called(ctx, q)
+	if args.Get(0) == nil {
+		return nil, 0, 0, 0, args.Error(4)
+	}
+	return args.Get(0).([]domain.ScheduledJob), args.Get(1).(int64), args.Get(2).(int), args.Get(3).(int), args.Error(4)
+}
+
+func (m *mockJobRepository) ByID(ctx context.Context, id int) (domain.ScheduledJob, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.ScheduledJob), args.Error(1)
+}
+
+func (m *mockJobRepository) Due(ctx context.Context, now time.Time, limit int) ([]domain.ScheduledJob, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ScheduledJob), args.Error(1)
+}
+
+func (m *mockJobRepository) SetNextRun(ctx context.Context, id int, next *time.Time) error {
+	args := m.Called(ctx, id, next)
+	return args.Error(0)
+}
+
+func (m *mockJobRepository) SetActive(ctx context.Context, id int, active bool, next *time.Time) error {
+	args := m.Called(ctx, id, active, next)
+	return args.Error(0)
+}
+
+func (m *mockJobRepository) Executions(ctx context.Context, jobID int, q dto.JobExecutionListQuery) ([]domain.JobExecution, int64, int, int, error) {
+	args := m.Called(ctx, jobID, q)
+	if args.Get(0) == nil {
+		return nil, 0, 0, 0, args.Error(4)
+	}
+	return args.Get(0).([]domain.JobExecution), args.Get(1).(int64), args.Get(2).(int), args.Get(3).(int), args.Error(4)
+}
+
+func (m *mockJobRepository) StartExecution(ctx context.Context, exec *domain.JobExecution) error {
+	args := m.Called(ctx, exec)
+	return args.Error(0)
+}
+
+func (m *mockJobRepository) FinishExecution(ctx context.Context, exec domain.JobExecution, next *time.Time) error {
+	args := m.Called(ctx, exec, next)
+	return args.Error(0)
+}
+
+func (m *mockJobRepository) PurgeExecutions(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockJobRepository) TryLock(ctx context.Context, jobID int) (func(), bool, error) {
+	args := m.Called(ctx, jobID)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(func()), args.Bool(1), args.Error(2)
+}
+
+// mockJobTrigger for testing
+type mockJobTrigger struct {
+	mock.Mock
+}
+
+func (m *mockJobTrigger) Trigger(ctx context.Context, jobID int) (domain.JobExecution, error) {
+	args := m.Called(ctx, jobID)
+	return args.Get(0).(domain.JobExecution), args.Error(1)
+}
+
+func TestJobService_Trigger(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		trigger := new(mockJobTrigger)
+		trigger.On("Trigger", ctx, 1).Return(domain.JobExecution{Id: 7, JobId: 1, Status: domain.JobStatusRunning}, nil)
+
+		svc := service.NewJobService(new(mockJobRepository), trigger, zap.NewNop())
+		exec, err := svc.Trigger(ctx, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 7, exec.Id)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		trigger := new(mockJobTrigger)
+		trigger.On("Trigger", ctx, 1).Return(domain.JobExecution{}, gorm.ErrRecordNotFound)
+
+		svc := service.NewJobService(new(mockJobRepository), trigger, zap.NewNop())
+		_, err := svc.Trigger(ctx, 1)
+
+		assert.ErrorIs(t, err, service.ErrJobNotFound)
+	})
+
+	t.Run("already running", func(t *testing.T) {
+		trigger := new(mockJobTrigger)
+		trigger.On("Trigger", ctx, 1).Return(domain.JobExecution{}, service.ErrJobRunning)
+
+		svc := service.NewJobService(new(mockJobRepository), trigger, zap.NewNop())
+		_, err := svc.Trigger(ctx, 1)
+
+		assert.ErrorIs(t, err, service.ErrJobRunning)
+	})
+}
+
+func TestJobService_Pause(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("clears next run", func(t *testing.T) {
+		repo := new(mockJobRepository)
+		repo.On("SetActive", ctx, 1, false, (*time.Time)(nil)).Return(nil)
+		repo.On("ByID", ctx, 1).Return(domain.ScheduledJob{Id: 1}, nil)
+
+		svc := service.NewJobService(repo, new(mockJobTrigger), zap.NewNop())
+		job, err := svc.Pause(ctx, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, job.Id)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		repo := new(mockJobRepository)
+		repo.On("SetActive", ctx, 1, false, (*time.Time)(nil)).Return(gorm.ErrRecordNotFound)
+
+		svc := service.NewJobService(repo, new(mockJobTrigger), zap.NewNop())
+		_, err := svc.Pause(ctx, 1)
+
+		assert.ErrorIs(t, err, service.ErrJobNotFound)
+	})
+}
+
+func TestJobService_Resume(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("recalculates next run", func(t *testing.T) {
+		repo := new(mockJobRepository)
+		repo.On("ByID", ctx, 1).Return(domain.ScheduledJob{Id: 1, CronExpression: "@hourly"}, nil)
+		repo.On("SetActive", ctx, 1, true, mock.MatchedBy(func(next *time.Time) bool {
+			return next != nil && next.After(time.Now()) && next.Minute() == 0
+		})).Return(nil)
+
+		svc := service.NewJobService(repo, new(mockJobTrigger), zap.NewNop())
+		_, err := svc.Resume(ctx, 1)
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("manual-only job", func(t *testing.T) {
+		repo := new(mockJobRepository)
+		repo.On("ByID", ctx, 1).Return(domain.ScheduledJob{Id: 1}, nil)
+		repo.On("SetActive", ctx, 1, true, (*time.Time)(nil)).Return(nil)
+
+		svc := service.NewJobService(repo, new(mockJobTrigger), zap.NewNop())
+		_, err := svc.Resume(ctx, 1)
+
+		assert.NoError(t, err)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("invalid cron", func(t *testing.T) {
+		repo := new(mockJobRepository)
+		repo.On("ByID", ctx, 1).Return(domain.ScheduledJob{Id: 1, CronExpression: "every day"}, nil)
+
+		svc := service.NewJobService(repo, new(mockJobTrigger), zap.NewNop())
+		_, err := svc.Resume(ctx, 1)
+
+		assert.ErrorIs(t, err, service.ErrJobInvalidCron)
+		repo.AssertNotCalled(t, "SetActive", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		repo := new(mockJobRepository)
+		repo.On("ByID", ctx, 1).Return(domain.ScheduledJob{}, gorm.ErrRecordNotFound)
+
+		svc := service.NewJobService(repo, new(mockJobTrigger), zap.NewNop())
+		_, err := svc.Resume(ctx, 1)
+
+		assert.ErrorIs(t, err, service.ErrJobNotFound)
+	})
+}
+
+func TestJobService_Executions_NotFound(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockJobRepository)
+	repo.On("ByID", ctx, 1).Return(domain.ScheduledJob{}, gorm.ErrRecordNotFound)
+
+	svc := service.NewJobService(repo, new(mockJobTrigger), zap.NewNop())
+	_, _, _, _, err := svc.Executions(ctx, 1, dto.JobExecutionListQuery{})
+
+	assert.ErrorIs(t, err, service.ErrJobNotFound)
+	repo.AssertNotCalled(t, "Executions", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,145 @@
+// Package service provides implementation for service
+//
+// File: notification_dispatcher_test.go
+// Description: Unit tests for notification channel dispatcher
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/http/dto"
+	"templatev25/internal/notify"
+	"templatev25/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeDeliveryRepo serves recipients from memory and records the delivery log
+type fakeDeliveryRepo struct {
+	mu         sync.Mutex
+	recipients []domain.NotificationRecipient // id-аар эрэмбэлсэн
+	logged     []domain.NotificationDelivery
+}
+
+func (r *fakeDeliveryRepo) Recipients(_ context.Context, userIDs []int) ([]domain.NotificationRecipient, error) {
+	var out []domain.NotificationRecipient
+	for _, rc := range r.recipients {
+		for _, id := range userIDs {
+			if rc.UserId == id {
+				out = append(out, rc)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeDeliveryRepo) RecipientsAfter(_ context.Context, afterID, limit int) ([]domain.NotificationRecipient, error) {
+	var out []domain.NotificationRecipient
+	for _, rc := range r.recipients {
+		if rc.UserId > afterID && len(out) < limit {
+			out = append(out, rc)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeDeliveryRepo) LogDeliveries(_ context.Context, ds []domain.NotificationDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logged = append(r.logged, ds...)
+	return nil
+}
+
+func (r *fakeDeliveryRepo) List(context.Context, dto.NotificationDeliveryListQuery) ([]domain.NotificationDelivery, int64, int, int, error) {
+	return nil, 0, 0, 0, nil
+}
+
+// fakeProvider records recipients; users in fail get err
+type fakeProvider struct {
+	mu      sync.Mutex
+	channel string
+	fail    map[int]error
+	sent    []int
+}
+
+func (p *fakeProvider) Channel() string { return p.channel }
+func (p *fakeProvider) Name() string    { return "fake-" + p.channel }
+
+func (p *fakeProvider) Send(_ context.Context, r notify.Recipient, _ notify.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err := p.fail[r.UserID]; err != nil {
+		return err
+	}
+	p.sent = append(p.sent, r.UserID)
+	return nil
+}
+
+func TestNotificationDispatcher_Dispatch(t *testing.T) {
+	repo := &fakeDeliveryRepo{recipients: []domain.NotificationRecipient{
+		{UserId: 1, Email: "a@gerege.mn", PhoneNo: "99110001", NotificationEmail: true, NotificationSms: true},
+		{UserId: 2, Email: "b@gerege.mn", NotificationEmail: false, NotificationSms: true}, // утасгүй
+		{UserId: 3, Email: "c@gerege.mn", NotificationEmail: true},
+	}}
+	email := &fakeProvider{channel: domain.NotificationChannelEmail, fail: map[int]error{3: errors.New("smtp down")}}
+	sms := &fakeProvider{channel: domain.NotificationChannelSMS, fail: map[int]error{2: notify.ErrNoAddress}}
+
+	d := service.NewNotificationDispatcher(repo, zap.NewNop(), email, sms)
+	d.Dispatch(context.Background(), []domain.Notification{
+		{Id: 10, UserId: 1, GroupId: 5},
+		{Id: 11, UserId: 2, GroupId: 5},
+		{Id: 12, UserId: 3, GroupId: 5},
+	})
+	d.Stop()
+
+	assert.Equal(t, []int{1}, email.sent)
+	assert.Equal(t, []int{1}, sms.sent)
+
+	status := map[string]string{}
+	for _, l := range repo.logged {
+		assert.Equal(t, 5, l.GroupId)
+		status[l.Channel+":"+strconv.Itoa(l.UserId)] = l.Status
+	}
+	assert.Equal(t, map[string]string{
+		"email:1": domain.NotificationDeliverySent,
+		"sms:1":   domain.NotificationDeliverySent,
+		"sms:2":   domain.NotificationDeliverySkipped,
+		"email:3": domain.NotificationDeliveryFailed,
+	}, status, "disabled channels are not logged")
+}
+
+func TestNotificationDispatcher_DispatchAll(t *testing.T) {
+	repo := &fakeDeliveryRepo{}
+	for id := 1; id <= 1200; id++ {
+		repo.recipients = append(repo.recipients, domain.NotificationRecipient{UserId: id, NotificationPush: id%2 == 0})
+	}
+	push := &fakeProvider{channel: domain.NotificationChannelPush}
+
+	d := service.NewNotificationDispatcher(repo, zap.NewNop(), push)
+	d.DispatchAll(context.Background(), domain.Notification{GroupId: 8, Type: domain.NotificationTypeBroadcast})
+	d.Stop()
+
+	require.Len(t, push.sent, 600)
+	assert.Equal(t, 1200, push.sent[len(push.sent)-1])
+	require.Len(t, repo.logged, 600)
+	assert.Zero(t, repo.logged[0].NotificationId)
+}
+
+func TestNotificationDispatcher_Stop(t *testing.T) {
+	repo := &fakeDeliveryRepo{recipients: []domain.NotificationRecipient{{UserId: 1, NotificationEmail: true}}}
+	email := &fakeProvider{channel: domain.NotificationChannelEmail}
+
+	d := service.NewNotificationDispatcher(repo, zap.NewNop(), email)
+	d.Stop()
+	d.Dispatch(context.Background(), []domain.Notification{{Id: 1, UserId: 1}})
+
+	assert.Empty(t, email.sent)
+	assert.Empty(t, repo.logged)
+}
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"templatev25/internal/domain"
+	"templatev25/internal/http/dto"
 	"templatev25/internal/service"
 
 	"git.gerege.mn/backend-packages/common"
@@ -17,6 +18,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // mockNotificationRepository implements repository.NotificationRepository
@@ -73,6 +75,19 @@ func (m *mockNotificationRepository) AllUserIDs(ctx context.Context) ([]int, err
 	return args.Get(0).([]int), args.Error(1)
 }
 
+func (m *mockNotificationRepository) CountUsers(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *mockNotificationRepository) ResolveRecipients(ctx context.Context, target domain.NotificationTarget) ([]int, error) {
+	args := m.Called(ctx, target)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int), args.Error(1)
+}
+
 func TestNotificationService_List(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -289,3 +304,212 @@ func TestNotificationService_MarkAllRead(t *testing.T) {
 		})
 	}
 }
+
+// recordingTransport records delivered notifications
+type recordingTransport struct {
+	sent      []domain.Notification
+	many      [][]int
+	manySent  []domain.Notification
+	broadcast []domain.Notification
+	err       error
+}
+
+func (r *recordingTransport) Send(_ context.Context, n domain.Notification, _ string) error {
+	r.sent = append(r.sent, n)
+	return r.err
+}
+
+func (r *recordingTransport) SendMany(_ context.Context, userIDs []int, n domain.Notification, _ string) error {
+	r.many = append(r.many, userIDs)
+	r.manySent = append(r.manySent, n)
+	return r.err
+}
+
+func (r *recordingTransport) Broadcast(_ context.Context, n domain.Notification, _ string) error {
+	r.broadcast = append(r.broadcast, n)
+	return r.err
+}
+
+// recordingDispatcher records channel dispatches
+type recordingDispatcher struct {
+	dispatched []domain.Notification
+	all        []domain.Notification
+}
+
+func (r *recordingDispatcher) Dispatch(_ context.Context, ns []domain.Notification) {
+	r.dispatched = append(r.dispatched, ns...)
+}
+
+func (r *recordingDispatcher) DispatchAll(_ context.Context, n domain.Notification) {
+	r.all = append(r.all, n)
+}
+
+func TestNotificationService_Send(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("direct saves then delivers", func(t *testing.T) {
+		repo := &mockNotificationRepository{}
+		repo.On("CreateGroup", ctx, mock.Anything).Return(domain.NotificationGroup{Id: 5}, nil)
+		repo.On("CreateNotification", ctx, mock.MatchedBy(func(n domain.Notification) bool {
+			return n.UserId == 2 && n.GroupId == 5 && n.Type == "dm"
+		})).Return(domain.Notification{Id: 9, UserId: 2, GroupId: 5, Type: "dm"}, nil)
+		rt, socket := &recordingTransport{}, &recordingTransport{}
+
+		svc := service.NewNotificationService(repo, &config.Config{})
+		svc.SetTransports(rt, socket)
+		err := svc.Send(ctx, dto.NotificationSendDto{Tenant: "t", UserID: 2, Title: "Hi"}, "admin")
+
+		assert.NoError(t, err)
+		require.Len(t, rt.sent, 1)
+		assert.Equal(t, 9, rt.sent[0].Id)
+		assert.Len(t, socket.sent, 1)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("targeted stores target and delivers once to all recipients", func(t *testing.T) {
+		repo := &mockNotificationRepository{}
+		want := domain.NotificationTarget{RoleCodes: []string{"ADMIN"}, OrgIDs: []int{3}, IncludeSubOrgs: true, UserIDs: []int{7}}
+		repo.On("ResolveRecipients", ctx, want).Return([]int{4, 7}, nil)
+		repo.On("CreateGroup", ctx, mock.MatchedBy(func(g domain.NotificationGroup) bool {
+			return g.Type == domain.NotificationTypeTargeted && g.RecipientCount == 2 && g.UserId == 0 &&
+				assert.ObjectsAreEqual(want, g.Target.Data())
+		})).Return(domain.NotificationGroup{Id: 5}, nil)
+		repo.On("CreateNotificationsBulk", ctx, mock.MatchedBy(func(ns []domain.Notification) bool {
+			return len(ns) == 2 && ns[0].UserId == 4 && ns[1].Type == domain.NotificationTypeTargeted
+		})).Return(nil)
+		rt := &recordingTransport{}
+
+		svc := service.NewNotificationService(repo, &config.Config{})
+		svc.SetTransports(rt)
+		err := svc.Send(ctx, dto.NotificationSendDto{
+			Tenant: "t",
+			UserID: 7, // merged into target.user_ids
+			Target: &dto.NotificationTargetDto{RoleCodes: []string{"ADMIN"}, OrgIDs: []int{3}, IncludeSubOrgs: true},
+		}, "admin")
+
+		assert.NoError(t, err)
+		assert.Empty(t, rt.sent)
+		require.Len(t, rt.many, 1)
+		assert.Equal(t, []int{4, 7}, rt.many[0])
+		assert.Equal(t, 5, rt.manySent[0].GroupId)
+		assert.Zero(t, rt.manySent[0].UserId)
+		assert.Empty(t, rt.broadcast)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("targeted without recipients", func(t *testing.T) {
+		repo := &mockNotificationRepository{}
+		repo.On("ResolveRecipients", ctx, mock.Anything).Return([]int{}, nil)
+
+		svc := service.NewNotificationService(repo, &config.Config{})
+		err := svc.Send(ctx, dto.NotificationSendDto{
+			Tenant: "t",
+			Target: &dto.NotificationTargetDto{OrgTypeIDs: []int{2}},
+		}, "admin")
+
+		assert.ErrorIs(t, err, service.ErrNotificationNoRecipients)
+		repo.AssertNotCalled(t, "CreateGroup", mock.Anything, mock.Anything)
+	})
+
+	t.Run("broadcast stores only the group", func(t *testing.T) {
+		repo := &mockNotificationRepository{}
+		repo.On("CountUsers", ctx).Return(int64(3), nil)
+		repo.On("CreateGroup", ctx, mock.MatchedBy(func(g domain.NotificationGroup) bool {
+			return g.Type == domain.NotificationTypeBroadcast && g.RecipientCount == 3 && g.FanOutOnRead
+		})).Return(domain.NotificationGroup{Id: 5}, nil)
+		rt := &recordingTransport{}
+
+		svc := service.NewNotificationService(repo, &config.Config{})
+		svc.SetTransports(rt)
+		err := svc.Send(ctx, dto.NotificationSendDto{Tenant: "t", Title: "Hi"}, "admin")
+
+		assert.NoError(t, err)
+		require.Len(t, rt.broadcast, 1)
+		assert.Equal(t, 5, rt.broadcast[0].GroupId)
+		assert.Equal(t, "broadcast_all", rt.broadcast[0].Type)
+		repo.AssertExpectations(t)
+		repo.AssertNotCalled(t, "AllUserIDs", mock.Anything)
+		repo.AssertNotCalled(t, "CreateNotificationsBulk", mock.Anything, mock.Anything)
+	})
+
+	t.Run("delivery error keeps other transports", func(t *testing.T) {
+		repo := &mockNotificationRepository{}
+		repo.On("CreateGroup", ctx, mock.Anything).Return(domain.NotificationGroup{Id: 5}, nil)
+		repo.On("CreateNotification", ctx, mock.Anything).Return(domain.Notification{Id: 9, UserId: 2}, nil)
+		failing := &recordingTransport{err: errors.New("socket down")}
+		rt := &recordingTransport{}
+
+		svc := service.NewNotificationService(repo, &config.Config{})
+		svc.SetTransports(failing, rt)
+		err := svc.Send(ctx, dto.NotificationSendDto{Tenant: "t", UserID: 2}, "admin")
+
+		assert.ErrorContains(t, err, "socket down")
+		assert.Len(t, rt.sent, 1)
+	})
+
+	t.Run("saved notifications go to channel dispatcher", func(t *testing.T) {
+		repo := &mockNotificationRepository{}
+		repo.On("ResolveRecipients", ctx, mock.Anything).Return([]int{4, 7}, nil)
+		repo.On("CountUsers", ctx).Return(int64(3), nil)
+		repo.On("CreateGroup", ctx, mock.Anything).Return(domain.NotificationGroup{Id: 5}, nil)
+		repo.On("CreateNotificationsBulk", ctx, mock.Anything).Return(nil)
+		d := &recordingDispatcher{}
+
+		svc := service.NewNotificationService(repo, &config.Config{})
+		svc.SetDispatcher(d)
+		require.NoError(t, svc.Send(ctx, dto.NotificationSendDto{Tenant: "t", Target: &dto.NotificationTargetDto{UserIDs: []int{4, 7}}}, "admin"))
+		require.NoError(t, svc.Send(ctx, dto.NotificationSendDto{Tenant: "t", Title: "Hi"}, "admin"))
+
+		require.Len(t, d.dispatched, 2)
+		assert.Equal(t, 7, d.dispatched[1].UserId)
+		require.Len(t, d.all, 1)
+		assert.Equal(t, 5, d.all[0].GroupId)
+	})
+
+	t.Run("save error skips delivery", func(t *testing.T) {
+		repo := &mockNotificationRepository{}
+		repo.On("ResolveRecipients", ctx, mock.Anything).Return([]int{1}, nil)
+		repo.On("CreateGroup", ctx, mock.Anything).Return(domain.NotificationGroup{Id: 5}, nil)
+		repo.On("CreateNotificationsBulk", ctx, mock.Anything).Return(errors.New("db error"))
+		rt, d := &recordingTransport{}, &recordingDispatcher{}
+
+		svc := service.NewNotificationService(repo, &config.Config{})
+		svc.SetTransports(rt)
+		svc.SetDispatcher(d)
+		err := svc.Send(ctx, dto.NotificationSendDto{Tenant: "t", Target: &dto.NotificationTargetDto{UserIDs: []int{1}}}, "admin")
+
+		assert.Error(t, err)
+		assert.Empty(t, rt.many)
+		assert.Empty(t, d.dispatched)
+	})
+}
+
+func TestRealtimeNotificationTransport(t *testing.T) {
+	pub := &recordingPublisher{}
+	tr := service.NewRealtimeNotificationTransport(pub)
+
+	require.NoError(t, tr.Send(context.Background(), domain.Notification{Id: 9, UserId: 2}, ""))
+	require.NoError(t, tr.Broadcast(context.Background(), domain.Notification{GroupId: 5}, ""))
+
+	assert.Equal(t, [][]int{{2}}, pub.userIDs)
+	assert.Equal(t, service.EventNotificationCreated, pub.events[0].Type)
+	require.Len(t, pub.all, 1)
+	assert.Equal(t, service.EventNotificationCreated, pub.all[0].Type)
+}
+
+func TestRealtimeNotificationTransport_SendManyBatches(t *testing.T) {
+	pub := &recordingPublisher{}
+	tr := service.NewRealtimeNotificationTransport(pub)
+
+	ids := make([]int, 2500)
+	for i := range ids {
+		ids[i] = i + 1
+	}
+	require.NoError(t, tr.SendMany(context.Background(), ids, domain.Notification{GroupId: 5}, ""))
+
+	// One publish per 1000 recipients, not one per recipient
+	require.Len(t, pub.userIDs, 3)
+	assert.Len(t, pub.userIDs[0], 1000)
+	assert.Len(t, pub.userIDs[2], 500)
+	assert.Equal(t, 2500, pub.userIDs[2][499])
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // mockOrganizationRepository for testing
@@ -61,6 +62,35 @@ func (m *mockOrganizationRepository) Tree(ctx context.Context, rootID int) ([]do
 	return args.Get(0).([]domain.Organization), args.Error(1)
 }
 
+func (m *mockOrganizationRepository) Subtree(ctx context.Context, rootID int) ([]domain.Organization, error) {
+	args := m.Called(ctx, rootID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Organization), args.Error(1)
+}
+
+func (m *mockOrganizationRepository) Ancestors(ctx context.Context, id int) ([]domain.Organization, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Organization), args.Error(1)
+}
+
+func (m *mockOrganizationRepository) DescendantIDs(ctx context.Context, id int) ([]int, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *mockOrganizationRepository) Move(ctx context.Context, id int, parentID *int) error {
+	args := m.Called(ctx, id, parentID)
+	return args.Error(0)
+}
+
 func TestOrganizationService_List(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -394,3 +424,102 @@ func TestOrganizationService_Tree(t *testing.T) {
 		})
 	}
 }
+
+func TestOrganizationService_Move(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+
+	tests := []struct {
+		name      string
+		orgID     int
+		parentID  *int
+		mockSetup func(*mockOrganizationRepository)
+		wantErr   error
+	}{
+		{
+			name:     "success - move under new parent",
+			orgID:    2,
+			parentID: intPtr(5),
+			mockSetup: func(m *mockOrganizationRepository) {
+				m.On("DescendantIDs", mock.Anything, 2).Return([]int{3, 4}, nil)
+				m.On("ByID", mock.Anything, 5).Return(domain.Organization{Id: 5}, nil)
+				m.On("Move", mock.Anything, 2, intPtr(5)).Return(nil)
+			},
+		},
+		{
+			name:     "success - zero parent makes root",
+			orgID:    2,
+			parentID: intPtr(0),
+			mockSetup: func(m *mockOrganizationRepository) {
+				m.On("Move", mock.Anything, 2, (*int)(nil)).Return(nil)
+			},
+		},
+		{
+			name:      "error - parent is itself",
+			orgID:     2,
+			parentID:  intPtr(2),
+			mockSetup: func(m *mockOrganizationRepository) {},
+			wantErr:   service.ErrOrganizationCycle,
+		},
+		{
+			name:     "error - parent is a descendant",
+			orgID:    2,
+			parentID: intPtr(4),
+			mockSetup: func(m *mockOrganizationRepository) {
+				m.On("DescendantIDs", mock.Anything, 2).Return([]int{3, 4}, nil)
+			},
+			wantErr: service.ErrOrganizationCycle,
+		},
+		{
+			name:     "error - parent not found",
+			orgID:    2,
+			parentID: intPtr(9),
+			mockSetup: func(m *mockOrganizationRepository) {
+				m.On("DescendantIDs", mock.Anything, 2).Return([]int{}, nil)
+				m.On("ByID", mock.Anything, 9).Return(domain.Organization{}, gorm.ErrRecordNotFound)
+			},
+			wantErr: service.ErrOrganizationParentNotFound,
+		},
+		{
+			name:     "error - organization not found",
+			orgID:    99,
+			parentID: nil,
+			mockSetup: func(m *mockOrganizationRepository) {
+				m.On("Move", mock.Anything, 99, (*int)(nil)).Return(gorm.ErrRecordNotFound)
+			},
+			wantErr: service.ErrOrganizationNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &mockOrganizationRepository{}
+			tt.mockSetup(mockRepo)
+
+			svc := service.NewOrganizationService(mockRepo, zap.NewNop())
+
+			err := svc.Move(context.Background(), tt.orgID, tt.parentID)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOrganizationService_Update_ParentCycle(t *testing.T) {
+	mockRepo := &mockOrganizationRepository{}
+	mockRepo.On("DescendantIDs", mock.Anything, 1).Return([]int{2, 3}, nil)
+
+	svc := service.NewOrganizationService(mockRepo, zap.NewNop())
+
+	parentID := 3
+	_, err := svc.Update(context.Background(), 1, dto.OrganizationUpdateDto{Name: "Ministry", ParentID: &parentID})
+
+	assert.ErrorIs(t, err, service.ErrOrganizationCycle)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"templatev25/internal/domain"
 	"templatev25/internal/http/dto"
@@ -15,7 +16,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // mockPermissionRepository implements repository.PermissionRepository
@@ -74,6 +77,14 @@ func (m *mockPermissionRepository) GetUserPermissionCodes(ctx context.Context, u
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (m *mockPermissionRepository) TraceUserPermission(ctx context.Context, userID int, permissionCode string) ([]domain.PermissionGrantTrace, error) {
+	args := m.Called(ctx, userID, permissionCode)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.PermissionGrantTrace), args.Error(1)
+}
+
 // mockCacheInvalidator implements auth.CacheInvalidator
 type mockCacheInvalidator struct {
 	mock.Mock
@@ -574,3 +585,144 @@ func TestPermissionService_GetUserPermissions(t *testing.T) {
 		})
 	}
 }
+
+// newGrantTrace builds a valid trace: user → role 1 → admin.user.read
+func newGrantTrace(mutate func(*domain.PermissionGrantTrace)) domain.PermissionGrantTrace {
+	active := true
+	t := domain.PermissionGrantTrace{
+		Assignment: domain.UserRole{UserId: 7, RoleID: 1},
+		Roles:      []domain.Role{{ID: 1, Code: "ADMIN", Name: "Admin"}},
+		Permission: domain.Permission{ID: 10, Code: "admin.user.read", IsActive: &active},
+	}
+	if mutate != nil {
+		mutate(&t)
+	}
+	return t
+}
+
+func TestPermissionService_Explain(t *testing.T) {
+	orgID := 3
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	inactive := false
+
+	tests := []struct {
+		name        string
+		orgID       int
+		traces      []domain.PermissionGrantTrace
+		wantGranted bool
+		wantReasons []string
+	}{
+		{
+			name:        "granted - direct role",
+			traces:      []domain.PermissionGrantTrace{newGrantTrace(nil)},
+			wantGranted: true,
+		},
+		{
+			name:        "denied - no role grants the code",
+			wantReasons: []string{service.DenyReasonNoGrant},
+		},
+		{
+			name: "granted - one valid path among denied ones",
+			traces: []domain.PermissionGrantTrace{
+				newGrantTrace(func(t *domain.PermissionGrantTrace) { t.Assignment.ExpiresAt = &past }),
+				newGrantTrace(func(t *domain.PermissionGrantTrace) { t.Assignment.ExpiresAt = &future }),
+			},
+			wantGranted: true,
+		},
+		{
+			name: "denied - expired and deleted assignment",
+			traces: []domain.PermissionGrantTrace{newGrantTrace(func(t *domain.PermissionGrantTrace) {
+				t.Assignment.ExpiresAt = &past
+				t.Assignment.DeletedDate = gorm.DeletedAt{Valid: true, Time: past}
+			})},
+			wantReasons: []string{service.DenyReasonAssignmentDeleted, service.DenyReasonAssignmentExpired},
+		},
+		{
+			name: "denied - org assignment without org selected",
+			traces: []domain.PermissionGrantTrace{newGrantTrace(func(t *domain.PermissionGrantTrace) {
+				t.Assignment.OrgID = &orgID
+			})},
+			wantReasons: []string{service.DenyReasonAssignmentOrgNotActive},
+		},
+		{
+			name:  "granted - org assignment in selected org",
+			orgID: orgID,
+			traces: []domain.PermissionGrantTrace{newGrantTrace(func(t *domain.PermissionGrantTrace) {
+				t.Assignment.OrgID = &orgID
+			})},
+			wantGranted: true,
+		},
+		{
+			name: "denied - inherited through soft-deleted parent",
+			traces: []domain.PermissionGrantTrace{newGrantTrace(func(t *domain.PermissionGrantTrace) {
+				t.Roles = append(t.Roles, domain.Role{ID: 2, Code: "BASE"})
+				t.Roles[1].DeletedDate = gorm.DeletedAt{Valid: true, Time: past}
+			})},
+			wantReasons: []string{service.DenyReasonRoleDeleted},
+		},
+		{
+			name: "denied - inactive wildcard grant and removed link",
+			traces: []domain.PermissionGrantTrace{newGrantTrace(func(t *domain.PermissionGrantTrace) {
+				t.Permission.Code = "admin.*"
+				t.Permission.IsActive = &inactive
+				t.RolePermissionDeleted = true
+			})},
+			wantReasons: []string{service.DenyReasonRolePermissionDeleted, service.DenyReasonPermissionInactive},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &mockPermissionRepository{}
+			mockRepo.On("TraceUserPermission", mock.Anything, 7, "admin.user.read").Return(tt.traces, nil)
+
+			svc := service.NewPermissionService(mockRepo, zap.NewNop())
+
+			out, err := svc.Explain(context.Background(), dto.PermissionExplainQuery{UserID: 7, Code: "admin.user.read", OrgID: tt.orgID})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantGranted, out.Granted)
+			assert.Equal(t, tt.wantReasons, out.Reasons)
+			assert.Len(t, out.Paths, len(tt.traces))
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPermissionService_Explain_PathDetails(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	trace := newGrantTrace(func(t *domain.PermissionGrantTrace) {
+		t.Roles = append(t.Roles, domain.Role{ID: 2, Code: "BASE", Name: "Base"})
+		t.Permission.Code = "admin.*"
+		t.Assignment.ExpiresAt = &past
+	})
+
+	mockRepo := &mockPermissionRepository{}
+	mockRepo.On("TraceUserPermission", mock.Anything, 7, "admin.user.read").Return([]domain.PermissionGrantTrace{trace}, nil)
+
+	svc := service.NewPermissionService(mockRepo, zap.NewNop())
+	out, err := svc.Explain(context.Background(), dto.PermissionExplainQuery{UserID: 7, Code: "admin.user.read"})
+	require.NoError(t, err)
+	require.Len(t, out.Paths, 1)
+
+	path := out.Paths[0]
+	assert.False(t, path.Granted)
+	assert.Equal(t, []string{service.DenyReasonAssignmentExpired}, path.DenyReasons)
+	assert.Equal(t, 1, path.Assignment.RoleID)
+	assert.Equal(t, []dto.PermissionPathRole{
+		{ID: 1, Code: "ADMIN", Name: "Admin"},
+		{ID: 2, Code: "BASE", Name: "Base"},
+	}, path.Roles)
+	assert.Equal(t, "admin.*", path.Permission.Code)
+	assert.True(t, path.Permission.Active)
+}
+
+func TestPermissionService_Explain_RepoError(t *testing.T) {
+	mockRepo := &mockPermissionRepository{}
+	mockRepo.On("TraceUserPermission", mock.Anything, 7, "admin.user.read").Return(nil, errors.New("db error"))
+
+	svc := service.NewPermissionService(mockRepo, zap.NewNop())
+	_, err := svc.Explain(context.Background(), dto.PermissionExplainQuery{UserID: 7, Code: "admin.user.read"})
+	assert.Error(t, err)
+}
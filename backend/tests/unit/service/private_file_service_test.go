@@ -0,0 +1,247 @@
+// Package service provides implementation for service
+//
+// File: private_file_service_test.go
+// Description: Unit tests for private files and signed download links
+package service_test
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/service"
+	"templatev25/internal/signedurl"
+	"templatev25/internal/storage"
+	"templatev25/internal/upload"
+	"templatev25/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+const testPrivateURL = "https://example.test/api/file/private/"
+
+// expectFileCreate нь files.Create-ийг n удаа хүлээж дараалсан id олгоно.
+// Үүссэн мөрүүд буцаах slice-д нэмэгдэнэ.
+func expectFileCreate(files *mocks.FileRepository, n int) *[]domain.File {
+	rows := &[]domain.File{}
+	files.On("Create", mock.Anything, mock.AnythingOfType("domain.File")).
+		Return(func(_ context.Context, f domain.File) (domain.File, error) {
+			f.Id = len(*rows) + 1
+			*rows = append(*rows, f)
+			return f, nil
+		}).Times(n)
+	return rows
+}
+
+func newPrivateFileService(t *testing.T) (*service.PrivateFileService, *mocks.FileRepository, *mocks.FileBlobRepository, storage.Storage) {
+	t.Helper()
+	store, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	quarantine, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+	signer, err := signedurl.NewSigner([]byte(strings.Repeat("k", signedurl.MinSecretLen)))
+	require.NoError(t, err)
+
+	policy := upload.NewPolicy(1<<20, upload.TypeRule{ContentType: "text/plain"})
+	repo := mocks.NewFileRepository(t)
+	blobs := mocks.NewFileBlobRepository(t)
+	svc := service.NewPrivateFileService(repo, blobs, store, upload.NewGuard(policy, stubScanner{}, quarantine), signer, service.PrivateFileOptions{
+		BaseURL:    testPrivateURL,
+		Driver:     "local",
+		DefaultTTL: 5 * time.Minute,
+		MaxTTL:     time.Hour,
+	})
+	return svc, repo, blobs, store
+}
+
+// signedParams нь Sign-ийн буцаасан link-ээс нэр болон параметрүүдийг салгана
+func signedParams(t *testing.T, link string) (string, signedurl.Params) {
+	t.Helper()
+	require.True(t, strings.HasPrefix(link, testPrivateURL), link)
+	name, rawQuery, _ := strings.Cut(strings.TrimPrefix(link, testPrivateURL), "?")
+	q, err := url.ParseQuery(rawQuery)
+	require.NoError(t, err)
+	return name, signedurl.ParamsFromQuery(func(key string, _ ...string) string { return q.Get(key) })
+}
+
+func readObject(t *testing.T, obj *storage.Object) string {
+	t.Helper()
+	defer obj.Body.Close()
+	data, err := io.ReadAll(obj.Body)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestPrivateFileService_UploadAndOpen(t *testing.T) {
+	svc, repo, blobs, _ := newPrivateFileService(t)
+	ctx := context.Background()
+	expectFileCreate(repo, 1)
+	expectNewBlob(blobs, false, "secret terms", 1)
+
+	f, err := svc.Upload(ctx, 10, multipartFile(t, "contract.txt", "secret terms"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, f.Id)
+	assert.False(t, f.IsPublic)
+	assert.Equal(t, "contract.txt", f.OriginalName)
+	assert.Equal(t, "local", f.StorageProvider)
+	require.NotNil(t, f.UserId)
+	assert.Equal(t, 10, *f.UserId)
+
+	repo.On("ListByUser", ctx, 10, false).Return([]domain.File{f}, nil)
+	items, err := svc.List(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+
+	// Эзэмшигч болон admin татахад download_count нэмэгдэнэ
+	repo.On("GetByStoredName", ctx, f.StoredName).Return(f, nil)
+	repo.On("GetByStoredName", ctx, "missing.txt").Return(domain.File{}, gorm.ErrRecordNotFound)
+	repo.On("IncrementDownloads", ctx, f.Id).Return(nil).Twice()
+
+	// Эзэмшигч
+	obj, got, err := svc.Open(ctx, service.FileAccessor{UserID: 10}, f.StoredName)
+	require.NoError(t, err)
+	assert.Equal(t, "secret terms", readObject(t, obj))
+	assert.Equal(t, f.Id, got.Id)
+
+	// Өөр хэрэглэгч
+	_, _, err = svc.Open(ctx, service.FileAccessor{UserID: 11}, f.StoredName)
+	assert.ErrorIs(t, err, service.ErrFileForbidden)
+
+	// admin.file.private.read эрхтэй
+	obj, _, err = svc.Open(ctx, service.FileAccessor{UserID: 11, CanManage: true}, f.StoredName)
+	require.NoError(t, err)
+	obj.Body.Close()
+
+	_, _, err = svc.Open(ctx, service.FileAccessor{UserID: 10}, "missing.txt")
+	assert.ErrorIs(t, err, service.ErrFileNotFound)
+}
+
+func TestPrivateFileService_Upload_Rejected(t *testing.T) {
+	svc, repo, _, _ := newPrivateFileService(t)
+
+	_, err := svc.Upload(context.Background(), 10, multipartFile(t, "page.html", "<html></html>"))
+	assert.ErrorIs(t, err, upload.ErrRejected)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestPrivateFileService_SignedLink(t *testing.T) {
+	svc, repo, blobs, _ := newPrivateFileService(t)
+	ctx := context.Background()
+	expectFileCreate(repo, 1)
+	expectNewBlob(blobs, false, "hello", 1)
+	f, err := svc.Upload(ctx, 10, multipartFile(t, "a.txt", "hello"))
+	require.NoError(t, err)
+	repo.On("GetByStoredName", ctx, f.StoredName).Return(f, nil)
+	repo.On("IncrementDownloads", ctx, f.Id).Return(nil).Once()
+
+	_, err = svc.Sign(ctx, service.FileAccessor{UserID: 11}, f.StoredName, 0, false)
+	assert.ErrorIs(t, err, service.ErrFileForbidden)
+
+	link, err := svc.Sign(ctx, service.FileAccessor{UserID: 10}, f.StoredName, 0, false)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), link.ExpiresAt, 2*time.Second)
+
+	// Link-тэй хэн ч (нэвтрээгүй ч) татна
+	name, p := signedParams(t, link.URL)
+	assert.Equal(t, f.StoredName, name)
+	obj, _, err := svc.OpenSigned(ctx, name, p, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", readObject(t, obj))
+
+	// Өөр файл, өөрчилсөн хугацаа
+	_, _, err = svc.OpenSigned(ctx, "other.txt", p, 0)
+	assert.ErrorIs(t, err, signedurl.ErrSignatureInvalid)
+	tampered := p
+	tampered.Expires += 3600
+	_, _, err = svc.OpenSigned(ctx, name, tampered, 0)
+	assert.ErrorIs(t, err, signedurl.ErrSignatureInvalid)
+
+	// MaxTTL-ээс урт хугацаа хасагдана
+	link, err = svc.Sign(ctx, service.FileAccessor{UserID: 10}, f.StoredName, 48*time.Hour, false)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), link.ExpiresAt, 2*time.Second)
+}
+
+func TestPrivateFileService_SignedLink_Expired(t *testing.T) {
+	svc, repo, blobs, _ := newPrivateFileService(t)
+	ctx := context.Background()
+	expectFileCreate(repo, 1)
+	expectNewBlob(blobs, false, "hello", 1)
+	f, err := svc.Upload(ctx, 10, multipartFile(t, "a.txt", "hello"))
+	require.NoError(t, err)
+	repo.On("GetByStoredName", ctx, f.StoredName).Return(f, nil).Once()
+
+	// Хугацаа секундээр тоологдох тул 1ns link дараагийн секундэд дуусна
+	expired, err := svc.Sign(ctx, service.FileAccessor{UserID: 10}, f.StoredName, time.Nanosecond, false)
+	require.NoError(t, err)
+	time.Sleep(1100 * time.Millisecond)
+	name, p := signedParams(t, expired.URL)
+	_, _, err = svc.OpenSigned(ctx, name, p, 0)
+	assert.ErrorIs(t, err, signedurl.ErrExpired)
+}
+
+func TestPrivateFileService_SignedLink_BoundUser(t *testing.T) {
+	svc, repo, blobs, _ := newPrivateFileService(t)
+	ctx := context.Background()
+	expectFileCreate(repo, 1)
+	expectNewBlob(blobs, false, "hello", 1)
+	f, err := svc.Upload(ctx, 10, multipartFile(t, "a.txt", "hello"))
+	require.NoError(t, err)
+	repo.On("GetByStoredName", ctx, f.StoredName).Return(f, nil)
+	repo.On("IncrementDownloads", ctx, f.Id).Return(nil).Once()
+
+	link, err := svc.Sign(ctx, service.FileAccessor{UserID: 10}, f.StoredName, 0, true)
+	require.NoError(t, err)
+	name, p := signedParams(t, link.URL)
+	assert.Equal(t, 10, p.UserID)
+
+	for _, userID := range []int{0, 11} {
+		_, _, err = svc.OpenSigned(ctx, name, p, userID)
+		assert.ErrorIs(t, err, service.ErrFileForbidden, userID)
+	}
+	obj, _, err := svc.OpenSigned(ctx, name, p, 10)
+	require.NoError(t, err)
+	obj.Body.Close()
+}
+
+func TestPrivateFileService_PublicRecordIsNotServed(t *testing.T) {
+	svc, repo, _, store := newPrivateFileService(t)
+	ctx := context.Background()
+	require.NoError(t, store.Put(ctx, "pub.txt", strings.NewReader("x"), 1, "text/plain"))
+	owner := 10
+	repo.On("GetByStoredName", ctx, "pub.txt").
+		Return(domain.File{Id: 1, UserId: &owner, StoredName: "pub.txt", StoragePath: "pub.txt", IsPublic: true}, nil)
+
+	_, _, err := svc.Open(ctx, service.FileAccessor{UserID: 10}, "pub.txt")
+	assert.ErrorIs(t, err, service.ErrFileNotFound)
+}
+
+func TestPrivateFileService_Delete(t *testing.T) {
+	svc, repo, blobs, store := newPrivateFileService(t)
+	ctx := context.Background()
+	expectFileCreate(repo, 1)
+	blob := expectNewBlob(blobs, false, "hello", 1)
+	f, err := svc.Upload(ctx, 10, multipartFile(t, "a.txt", "hello"))
+	require.NoError(t, err)
+
+	// Устгасны дараа soft delete хийгдсэн мөр олдохгүй
+	repo.On("GetByStoredName", ctx, f.StoredName).Return(f, nil).Twice()
+	repo.On("GetByStoredName", ctx, f.StoredName).Return(domain.File{}, gorm.ErrRecordNotFound).Once()
+	repo.On("Delete", ctx, f.Id).Return(nil).Once()
+	blobs.On("Release", ctx, 1).Return(*blob, true, nil).Once()
+
+	assert.ErrorIs(t, svc.Delete(ctx, service.FileAccessor{UserID: 11}, f.StoredName), service.ErrFileForbidden)
+	require.NoError(t, svc.Delete(ctx, service.FileAccessor{UserID: 10}, f.StoredName))
+
+	_, err = store.Stat(ctx, f.StoragePath)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	_, _, err = svc.Open(ctx, service.FileAccessor{UserID: 10}, f.StoredName)
+	assert.ErrorIs(t, err, service.ErrFileNotFound)
+}
@@ -0,0 +1,244 @@
+// Package service provides implementation for service
+//
+// File: public_file_service_test.go
+// Description: Unit tests for public file service over the storage layer
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/imagevariant"
+	"templatev25/internal/service"
+	"templatev25/internal/storage"
+	"templatev25/internal/upload"
+	"templatev25/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+const testPublicURL = "https://example.test/api/file/"
+
+// multipartFile builds a *multipart.FileHeader like the one fiber hands to Upload
+func multipartFile(t *testing.T, filename, content string) *multipart.FileHeader {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", filename)
+	require.NoError(t, err)
+	_, err = io.WriteString(part, content)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	req := httptest.NewRequest("POST", "/file/upload", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	require.NoError(t, req.ParseMultipartForm(1<<20))
+	return req.MultipartForm.File["file"][0]
+}
+
+// pngBytes is a PNG signature followed by filler
+const pngBytes = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+
+// stubScanner flags files that contain "EICAR"
+type stubScanner struct{}
+
+func (stubScanner) Scan(_ context.Context, r io.Reader) (upload.ScanResult, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return upload.ScanResult{}, err
+	}
+	if bytes.Contains(data, []byte("EICAR")) {
+		return upload.ScanResult{Infected: true, Signature: "Eicar-Test-Signature"}, nil
+	}
+	return upload.ScanResult{}, nil
+}
+
+func newPublicFileService(t *testing.T) (*service.PublicFileService, *mocks.PublicFileRepository, *mocks.FileRepository, *mocks.FileBlobRepository, string) {
+	svc, repo, files, blobs, dir, _ := newGuardedPublicFileService(t)
+	return svc, repo, files, blobs, dir
+}
+
+// newGuardedPublicFileService нь quarantine директорыг мөн буцаана
+func newGuardedPublicFileService(t *testing.T) (*service.PublicFileService, *mocks.PublicFileRepository, *mocks.FileRepository, *mocks.FileBlobRepository, string, string) {
+	dir, quarantineDir := t.TempDir(), t.TempDir()
+	store, err := storage.NewLocalStorage(dir)
+	require.NoError(t, err)
+	quarantine, err := storage.NewLocalStorage(quarantineDir)
+	require.NoError(t, err)
+
+	policy := upload.NewPolicy(1<<20, upload.TypeRule{ContentType: "image/png"}, upload.TypeRule{ContentType: "text/plain"})
+	repo := mocks.NewPublicFileRepository(t)
+	files := mocks.NewFileRepository(t)
+	blobs := mocks.NewFileBlobRepository(t)
+	variants := imagevariant.NewGenerator(store, imagevariant.Options{
+		Variants: []imagevariant.Spec{{Name: "thumb", Width: 16, Height: 16, Crop: true}},
+		WebP:     true,
+	})
+	svc := service.NewPublicFileService(repo, files, blobs, store, upload.NewGuard(policy, stubScanner{}, quarantine), variants, service.PublicFileOptions{
+		BaseURL: testPublicURL,
+		Driver:  "local",
+	})
+	return svc, repo, files, blobs, dir, quarantineDir
+}
+
+func TestPublicFileService_Upload(t *testing.T) {
+	svc, repo, files, blobs, dir := newPublicFileService(t)
+	ctx := context.Background()
+	rows := expectFileCreate(files, 1)
+	expectNewBlob(blobs, true, pngBytes, 1)
+
+	repo.On("Create", ctx, mock.AnythingOfType("domain.PublicFile")).
+		Return(func(_ context.Context, m domain.PublicFile) (domain.PublicFile, error) {
+			m.Id = 1
+			return m, nil
+		})
+
+	created, err := svc.Upload(ctx, 10, multipartFile(t, "Logo.PNG", pngBytes), "logo", "")
+	require.NoError(t, err)
+	assert.Equal(t, ".png", created.Extension)
+	assert.Equal(t, testPublicURL+created.Name+".png", created.FileUrl)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	data, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	require.NoError(t, err)
+	assert.Equal(t, pngBytes, string(data))
+
+	require.Len(t, *rows, 1)
+	files.On("GetByStoredName", ctx, created.Name+".png").Return((*rows)[0], nil)
+	obj, err := svc.Open(ctx, created.Name+".png", "", "")
+	require.NoError(t, err)
+	defer obj.Body.Close()
+	assert.Equal(t, "image/png", obj.ContentType)
+	assert.Equal(t, pngBytes, readObject(t, obj))
+}
+
+func TestPublicFileService_Upload_RepoErrorRemovesObject(t *testing.T) {
+	svc, repo, files, blobs, dir := newPublicFileService(t)
+	ctx := context.Background()
+
+	expectFileCreate(files, 1)
+	blob := expectNewBlob(blobs, true, "x", 1)
+	repo.On("Create", ctx, mock.AnythingOfType("domain.PublicFile")).
+		Return(domain.PublicFile{}, errors.New("db down"))
+	// PublicFile үүсээгүй тул files мөр болон blob-ийн reference буцаагдана
+	files.On("Delete", ctx, 1).Return(nil).Once()
+	blobs.On("Release", ctx, 1).
+		Return(func(context.Context, int) (domain.FileBlob, bool, error) { return *blob, true, nil }).Once()
+
+	_, err := svc.Upload(ctx, 10, multipartFile(t, "a.txt", "x"), "", "")
+	assert.Error(t, err)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestPublicFileService_Open_NotFound(t *testing.T) {
+	svc, _, files, _, _ := newPublicFileService(t)
+	files.On("GetByStoredName", mock.Anything, "missing.png").Return(domain.File{}, gorm.ErrRecordNotFound)
+
+	// "private/..." нь S3 дээр нийтийн prefix дотор байж болох хувийн файл
+	for _, name := range []string{"missing.png", "../etc/passwd", "private/a.png"} {
+		_, err := svc.Open(context.Background(), name, "", "")
+		assert.ErrorIs(t, err, service.ErrFileNotFound, name)
+	}
+}
+
+func TestPublicFileService_OpenVariant(t *testing.T) {
+	svc, repo, files, _, dir := newPublicFileService(t)
+	ctx := context.Background()
+	// files-д бүртгэлгүй (dedup-ээс өмнөх) файл
+	files.On("GetByStoredName", ctx, mock.Anything).Return(domain.File{}, gorm.ErrRecordNotFound)
+
+	f, err := os.Create(filepath.Join(dir, "img.png"))
+	require.NoError(t, err)
+	require.NoError(t, png.Encode(f, image.NewGray(image.Rect(0, 0, 64, 32))))
+	require.NoError(t, f.Close())
+
+	obj, err := svc.Open(ctx, "img.png", "thumb", "webp")
+	require.NoError(t, err)
+	obj.Body.Close()
+	assert.Equal(t, "image/webp", obj.ContentType)
+	_, err = os.Stat(filepath.Join(dir, "img_thumb.webp"))
+	require.NoError(t, err, "variant is stored next to the original")
+
+	_, err = svc.Open(ctx, "img.png", "huge", "")
+	assert.ErrorIs(t, err, imagevariant.ErrUnavailable)
+	_, err = svc.Open(ctx, "missing.png", "thumb", "")
+	assert.ErrorIs(t, err, service.ErrFileNotFound)
+
+	// Deleting the file removes its variants too
+	repo.On("GetByName", ctx, "img").Return(domain.PublicFile{Id: 3, Name: "img", Extension: ".png"}, nil)
+	repo.On("DeleteByID", ctx, 3).Return(domain.PublicFile{Id: 3}, nil)
+	require.NoError(t, svc.Delete(ctx, "img"))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestPublicFileService_Delete(t *testing.T) {
+	svc, repo, files, _, dir := newPublicFileService(t)
+	ctx := context.Background()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "abc.pdf"), []byte("pdf"), 0o600))
+	files.On("GetByStoredName", ctx, "abc.pdf").Return(domain.File{}, gorm.ErrRecordNotFound)
+
+	repo.On("GetByName", ctx, "abc").Return(domain.PublicFile{Id: 7, Name: "abc", Extension: ".pdf"}, nil)
+	repo.On("DeleteByID", ctx, 7).Return(domain.PublicFile{Id: 7}, nil)
+
+	require.NoError(t, svc.Delete(ctx, "abc"))
+	_, err := os.Stat(filepath.Join(dir, "abc.pdf"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestPublicFileService_Upload_RejectedKeepsOldFile(t *testing.T) {
+	svc, _, _, _, dir := newPublicFileService(t)
+	ctx := context.Background()
+
+	// PNG агуулгатай .pdf, HTML (allowlist-д байхгүй)
+	for name, content := range map[string]string{
+		"fake.pdf":  pngBytes,
+		"page.html": "<html><script>alert(1)</script></html>",
+		"empty.txt": "",
+	} {
+		_, err := svc.Upload(ctx, 10, multipartFile(t, name, content), "", "old-name")
+		assert.ErrorIs(t, err, upload.ErrRejected, name)
+	}
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	// GetByName is never called: old file is not deleted on rejection
+}
+
+func TestPublicFileService_Upload_InfectedIsQuarantined(t *testing.T) {
+	svc, _, _, _, dir, quarantineDir := newGuardedPublicFileService(t)
+
+	_, err := svc.Upload(context.Background(), 10, multipartFile(t, "readme.txt", "X5O!P%@AP EICAR"), "", "")
+
+	var infected *upload.InfectedError
+	require.ErrorAs(t, err, &infected)
+	assert.Equal(t, "Eicar-Test-Signature", infected.Signature)
+	assert.NotEmpty(t, infected.QuarantineKey)
+
+	_, err = os.Stat(filepath.Join(quarantineDir, filepath.FromSlash(infected.QuarantineKey)))
+	assert.NoError(t, err)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "nothing reaches public storage")
+	// Create is never called: no PublicFile row for infected uploads
+}
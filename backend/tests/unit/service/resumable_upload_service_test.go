@@ -0,0 +1,271 @@
+// Package service provides implementation for service
+//
+// File: resumable_upload_service_test.go
+// Description: Unit tests for resumable (tus) uploads
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/service"
+	"templatev25/internal/storage"
+	"templatev25/internal/tus"
+	"templatev25/internal/upload"
+	"templatev25/tests/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// expectUploadCreate нь file_uploads-д бүртгэх мөрийг хэвээр нь буцаана
+func expectUploadCreate(repo *mocks.FileUploadRepository) {
+	repo.On("Create", mock.Anything, mock.AnythingOfType("domain.FileUpload")).
+		Return(func(_ context.Context, u domain.FileUpload) (domain.FileUpload, error) { return u, nil }).Once()
+}
+
+// expectChunks нь id upload-ийн AppendChunk-ийг амжилттай болгож, бүртгэсэн
+// хэсгүүдийг Chunks-ээр буцаана
+func expectChunks(repo *mocks.FileUploadRepository, id string) {
+	var chunks []domain.FileUploadChunk
+	repo.On("AppendChunk", mock.Anything, mock.MatchedBy(func(c domain.FileUploadChunk) bool { return c.UploadId == id }), mock.AnythingOfType("time.Time")).
+		Run(func(args mock.Arguments) { chunks = append(chunks, args.Get(1).(domain.FileUploadChunk)) }).
+		Return(true, nil)
+	repo.On("Chunks", mock.Anything, id).
+		Return(func(context.Context, string) ([]domain.FileUploadChunk, error) { return chunks, nil })
+}
+
+// withOffset нь u-г offset хүртэл хүлээн авсан төлөвөөр буцаана
+func withOffset(u domain.FileUpload, offset int64) domain.FileUpload {
+	u.UploadOffset = offset
+	return u
+}
+
+type resumableFixture struct {
+	svc       *service.ResumableUploadService
+	repo      *mocks.FileUploadRepository
+	files     *service.PublicFileService
+	fileRepo  *mocks.FileRepository
+	blobs     *mocks.FileBlobRepository
+	chunkDir  string
+	publicDir string
+}
+
+func newResumableUploadService(t *testing.T, opts service.ResumableUploadOptions) resumableFixture {
+	t.Helper()
+	files, publicRepo, fileRepo, blobs, publicDir := newPublicFileService(t)
+	publicRepo.On("Create", mock.Anything, mock.AnythingOfType("domain.PublicFile")).
+		Return(func(_ context.Context, m domain.PublicFile) (domain.PublicFile, error) {
+			m.Id = 1
+			return m, nil
+		}).Maybe()
+
+	chunkDir := t.TempDir()
+	chunks, err := storage.NewLocalStorage(chunkDir)
+	require.NoError(t, err)
+	if opts.TempDir == "" {
+		opts.TempDir = t.TempDir()
+	}
+	repo := mocks.NewFileUploadRepository(t)
+	return resumableFixture{
+		svc:       service.NewResumableUploadService(repo, chunks, files, opts),
+		repo:      repo,
+		files:     files,
+		fileRepo:  fileRepo,
+		blobs:     blobs,
+		chunkDir:  chunkDir,
+		publicDir: publicDir,
+	}
+}
+
+// countFiles нь dir доторх файлуудыг (дэд директор оролцуулан) тоолно
+func countFiles(t *testing.T, dir string) int {
+	t.Helper()
+	n := 0
+	require.NoError(t, filepath.WalkDir(dir, func(_ string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			n++
+		}
+		return err
+	}))
+	return n
+}
+
+func TestResumableUploadService_ChunkedUpload(t *testing.T) {
+	f := newResumableUploadService(t, service.ResumableUploadOptions{BaseURL: "https://example.test/api/uploads/"})
+	ctx := context.Background()
+	content := "hello resumable world"
+	rows := expectFileCreate(f.fileRepo, 1)
+	expectNewBlob(f.blobs, true, content, 1)
+	expectUploadCreate(f.repo)
+
+	u, err := f.svc.Create(ctx, 10, int64(len(content)), map[string]string{"filename": "notes.txt", "description": "memo"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.test/api/uploads/"+u.Id, f.svc.URL(u.Id))
+	assert.Equal(t, int64(0), u.UploadOffset)
+	assert.Equal(t, "notes.txt", u.Filename)
+	assert.Equal(t, "memo", u.Description)
+
+	expectChunks(f.repo, u.Id)
+	f.repo.On("Get", ctx, u.Id).Return(u, nil).Once()
+	f.repo.On("Get", ctx, u.Id).Return(withOffset(u, 6), nil).Twice()
+	f.repo.On("Get", ctx, u.Id).Return(withOffset(u, 12), nil).Once()
+	f.repo.On("SetStatus", mock.Anything, u.Id, domain.FileUploadUploading, domain.FileUploadFinalizing).Return(true, nil).Once()
+	f.repo.On("Complete", mock.Anything, u.Id, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).Return(nil).Once()
+
+	u, err = f.svc.Append(ctx, 10, u.Id, 0, []byte(content[:6]), nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), u.UploadOffset)
+	assert.Equal(t, 1, countFiles(t, f.chunkDir))
+
+	// Тасарсны дараа HEAD-ээр offset авч үргэлжлүүлнэ
+	got, err := f.svc.Get(ctx, 10, u.Id)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), got.UploadOffset)
+
+	u, err = f.svc.Append(ctx, 10, u.Id, 6, []byte(content[6:12]), nil)
+	require.NoError(t, err)
+	sum := sha256.Sum256([]byte(content[12:]))
+	u, err = f.svc.Append(ctx, 10, u.Id, 12, []byte(content[12:]), &tus.Checksum{Algorithm: "sha256", Sum: sum[:]})
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.FileUploadCompleted, u.Status)
+	require.NotEmpty(t, u.FileUrl)
+	assert.Equal(t, 0, countFiles(t, f.chunkDir))
+	f.repo.AssertCalled(t, "Complete", mock.Anything, u.Id, u.FileUrl, mock.AnythingOfType("time.Time"))
+
+	require.Len(t, *rows, 1)
+	f.fileRepo.On("GetByStoredName", ctx, filepath.Base(u.FileUrl)).Return((*rows)[0], nil)
+	obj, err := f.files.Open(ctx, filepath.Base(u.FileUrl), "", "")
+	require.NoError(t, err)
+	assert.Equal(t, content, readObject(t, obj))
+
+	// Хариу алдагдсан client хоосон PATCH-ээр file_url-ээ дахин авна
+	f.repo.On("Get", ctx, u.Id).Return(u, nil).Once()
+	again, err := f.svc.Append(ctx, 10, u.Id, int64(len(content)), nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, u.FileUrl, again.FileUrl)
+}
+
+func TestResumableUploadService_CreateTooLarge(t *testing.T) {
+	f := newResumableUploadService(t, service.ResumableUploadOptions{MaxSize: 100})
+	assert.Equal(t, int64(100), f.svc.MaxSize())
+
+	_, err := f.svc.Create(context.Background(), 10, 101, nil)
+	assert.ErrorIs(t, err, service.ErrUploadTooLarge)
+	f.repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+
+	// Policy-ийн хязгаар (1MB) тохиргооноос бага бол тэрийг авна
+	f = newResumableUploadService(t, service.ResumableUploadOptions{MaxSize: 1 << 30})
+	assert.Equal(t, int64(1<<20), f.svc.MaxSize())
+}
+
+func TestResumableUploadService_AppendErrors(t *testing.T) {
+	f := newResumableUploadService(t, service.ResumableUploadOptions{})
+	ctx := context.Background()
+	expectUploadCreate(f.repo)
+	u, err := f.svc.Create(ctx, 10, 10, map[string]string{"filename": "a.txt"})
+	require.NoError(t, err)
+	f.repo.On("Get", ctx, u.Id).Return(u, nil)
+
+	_, err = f.svc.Append(ctx, 10, u.Id, 3, []byte("abc"), nil)
+	assert.ErrorIs(t, err, service.ErrUploadOffsetMismatch)
+
+	_, err = f.svc.Append(ctx, 10, u.Id, 0, []byte("01234567890"), nil)
+	assert.ErrorIs(t, err, service.ErrUploadLengthExceeded)
+
+	_, err = f.svc.Append(ctx, 10, u.Id, 0, []byte("abc"), &tus.Checksum{Algorithm: "sha256", Sum: make([]byte, sha256.Size)})
+	assert.ErrorIs(t, err, service.ErrUploadChecksumMismatch)
+
+	_, err = f.svc.Append(ctx, 11, u.Id, 0, []byte("abc"), nil)
+	assert.ErrorIs(t, err, service.ErrUploadNotFound)
+	_, err = f.svc.Get(ctx, 10, "not-a-uuid")
+	assert.ErrorIs(t, err, service.ErrUploadNotFound)
+
+	// Алдаатай PATCH юу ч хадгалаагүй
+	f.repo.AssertNotCalled(t, "AppendChunk", mock.Anything, mock.Anything, mock.Anything)
+	assert.Equal(t, 0, countFiles(t, f.chunkDir))
+}
+
+func TestResumableUploadService_RejectedFileRemovesUpload(t *testing.T) {
+	f := newResumableUploadService(t, service.ResumableUploadOptions{})
+	ctx := context.Background()
+	content := "<html></html>"
+	expectUploadCreate(f.repo)
+	u, err := f.svc.Create(ctx, 10, int64(len(content)), map[string]string{"filename": "page.html"})
+	require.NoError(t, err)
+
+	expectChunks(f.repo, u.Id)
+	f.repo.On("Get", ctx, u.Id).Return(u, nil).Once()
+	f.repo.On("Get", ctx, u.Id).Return(domain.FileUpload{}, gorm.ErrRecordNotFound).Once()
+	f.repo.On("SetStatus", mock.Anything, u.Id, domain.FileUploadUploading, domain.FileUploadFinalizing).Return(true, nil).Once()
+	f.repo.On("Delete", mock.Anything, u.Id).Return(nil).Once()
+
+	_, err = f.svc.Append(ctx, 10, u.Id, 0, []byte(content), nil)
+	assert.ErrorIs(t, err, upload.ErrRejected)
+
+	_, err = f.svc.Get(ctx, 10, u.Id)
+	assert.ErrorIs(t, err, service.ErrUploadNotFound)
+	assert.Equal(t, 0, countFiles(t, f.chunkDir))
+	assert.Equal(t, 0, countFiles(t, f.publicDir))
+}
+
+func TestResumableUploadService_Terminate(t *testing.T) {
+	f := newResumableUploadService(t, service.ResumableUploadOptions{})
+	ctx := context.Background()
+	expectUploadCreate(f.repo)
+	u, err := f.svc.Create(ctx, 10, 10, nil)
+	require.NoError(t, err)
+
+	expectChunks(f.repo, u.Id)
+	f.repo.On("Get", ctx, u.Id).Return(u, nil).Twice()
+	f.repo.On("Get", ctx, u.Id).Return(withOffset(u, 3), nil).Once()
+	f.repo.On("Get", ctx, u.Id).Return(domain.FileUpload{}, gorm.ErrRecordNotFound).Once()
+	f.repo.On("Delete", ctx, u.Id).Return(nil).Once()
+
+	_, err = f.svc.Append(ctx, 10, u.Id, 0, []byte("abc"), nil)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, f.svc.Terminate(ctx, 11, u.Id), service.ErrUploadNotFound)
+	require.NoError(t, f.svc.Terminate(ctx, 10, u.Id))
+
+	_, err = f.svc.Get(ctx, 10, u.Id)
+	assert.ErrorIs(t, err, service.ErrUploadNotFound)
+	assert.Equal(t, 0, countFiles(t, f.chunkDir))
+}
+
+func TestResumableUploadService_Expiry(t *testing.T) {
+	f := newResumableUploadService(t, service.ResumableUploadOptions{Expiry: time.Minute})
+	ctx := context.Background()
+	expectUploadCreate(f.repo)
+	u, err := f.svc.Create(ctx, 10, 10, nil)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), u.ExpiresAt, 5*time.Second)
+
+	expired := withOffset(u, 3)
+	expired.ExpiresAt = time.Now().Add(-time.Second)
+	expectChunks(f.repo, u.Id)
+	f.repo.On("Get", ctx, u.Id).Return(u, nil).Once()
+	f.repo.On("Get", ctx, u.Id).Return(expired, nil).Once()
+
+	_, err = f.svc.Append(ctx, 10, u.Id, 0, []byte("abc"), nil)
+	require.NoError(t, err)
+
+	_, err = f.svc.Append(ctx, 10, u.Id, 3, []byte("def"), nil)
+	assert.ErrorIs(t, err, service.ErrUploadExpired)
+
+	now := time.Now()
+	f.repo.On("ListExpired", ctx, now, 100).Return([]domain.FileUpload{expired}, nil).Once()
+	f.repo.On("Delete", ctx, u.Id).Return(nil).Once()
+	n, err := f.svc.ExpireUploads(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 0, countFiles(t, f.chunkDir))
+}
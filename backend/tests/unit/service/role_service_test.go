@@ -15,6 +15,7 @@ import (
 
 	"templatev25/internal/domain"
 	"templatev25/internal/http/dto"
+	"templatev25/internal/repository"
 	"templatev25/internal/service"
 
 	"git.gerege.mn/backend-packages/common"
@@ -70,6 +71,40 @@ func (m *mockRoleRepository) ReplacePermissions(ctx context.Context, roleID int,
 	return args.Error(0)
 }
 
+func (m *mockRoleRepository) MatchingPermissions(ctx context.Context, patterns []string) ([]domain.Permission, error) {
+	args := m.Called(ctx, patterns)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Permission), args.Error(1)
+}
+
+func (m *mockRoleRepository) ReplaceParents(ctx context.Context, roleID int, parentIDs []int) error {
+	args := m.Called(ctx, roleID, parentIDs)
+	return args.Error(0)
+}
+
+func (m *mockRoleRepository) UpdateWithParents(ctx context.Context, id int, role domain.Role, parentIDs []int) error {
+	args := m.Called(ctx, id, role, parentIDs)
+	return args.Error(0)
+}
+
+func (m *mockRoleRepository) DescendantIDs(ctx context.Context, roleID int) ([]int, error) {
+	args := m.Called(ctx, roleID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *mockRoleRepository) UserIDsByRoles(ctx context.Context, roleIDs []int) ([]int, error) {
+	args := m.Called(ctx, roleIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int), args.Error(1)
+}
+
 func (m *mockRoleRepository) GetUserCount(ctx context.Context, roleID int) int64 {
 	args := m.Called(ctx, roleID)
 	return int64(args.Int(0))
@@ -294,6 +329,54 @@ func TestRoleService_GetPermissions(t *testing.T) {
 	}
 }
 
+func TestRoleService_GetEffectivePermissions(t *testing.T) {
+	mockRepo := &mockRoleRepository{}
+	mockRepo.On("Permissions", mock.Anything, mock.AnythingOfType("dto.RolePermissionsQuery")).Return([]domain.Permission{
+		{ID: 1, Code: "admin.*"},
+		{ID: 2, Code: "admin.user.*"},
+		{ID: 3, Code: "report.read"},
+	}, nil)
+	mockRepo.On("MatchingPermissions", mock.Anything, []string{"admin.*", "admin.user.*"}).Return([]domain.Permission{
+		{ID: 2, Code: "admin.user.*"},
+		{ID: 4, Code: "admin.role.read"},
+		{ID: 5, Code: "admin.user.read"},
+	}, nil)
+
+	svc := service.NewRoleService(mockRepo, zap.NewNop())
+
+	perms, err := svc.GetEffectivePermissions(context.Background(), dto.RolePermissionsQuery{RoleID: 1})
+	assert.NoError(t, err)
+
+	grantedBy := make(map[string]string, len(perms))
+	for _, p := range perms {
+		grantedBy[p.Code] = p.GrantedBy
+	}
+	assert.Equal(t, map[string]string{
+		"admin.*":         "",
+		"admin.user.*":    "",
+		"report.read":     "",
+		"admin.role.read": "admin.*",
+		"admin.user.read": "admin.user.*",
+	}, grantedBy)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRoleService_GetEffectivePermissions_NoWildcards(t *testing.T) {
+	mockRepo := &mockRoleRepository{}
+	mockRepo.On("Permissions", mock.Anything, mock.AnythingOfType("dto.RolePermissionsQuery")).Return([]domain.Permission{
+		{ID: 3, Code: "report.read"},
+	}, nil)
+
+	svc := service.NewRoleService(mockRepo, zap.NewNop())
+
+	perms, err := svc.GetEffectivePermissions(context.Background(), dto.RolePermissionsQuery{RoleID: 1})
+	assert.NoError(t, err)
+	assert.Len(t, perms, 1)
+
+	mockRepo.AssertNotCalled(t, "MatchingPermissions", mock.Anything, mock.Anything)
+}
+
 func TestRoleService_SetPermissions(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -394,3 +477,109 @@ func TestRoleService_Update(t *testing.T) {
 		})
 	}
 }
+
+func TestRoleService_Update_Parents(t *testing.T) {
+	tests := []struct {
+		name      string
+		roleID    int
+		parentIDs []int
+		mockSetup func(*mockRoleRepository, *mockCacheInvalidator)
+		wantErr   error
+	}{
+		{
+			name:      "success - parents replaced and descendant users invalidated",
+			roleID:    2,
+			parentIDs: []int{1, 1},
+			mockSetup: func(m *mockRoleRepository, c *mockCacheInvalidator) {
+				m.On("UpdateWithParents", mock.Anything, 2, mock.AnythingOfType("domain.Role"), []int{1}).Return(nil)
+				m.On("DescendantIDs", mock.Anything, 2).Return([]int{3, 4}, nil)
+				m.On("UserIDsByRoles", mock.Anything, []int{3, 4, 2}).Return([]int{10, 11}, nil)
+				c.On("InvalidateUsers", []int{10, 11}).Return()
+			},
+		},
+		{
+			name:      "success - empty list clears parents",
+			roleID:    2,
+			parentIDs: []int{},
+			mockSetup: func(m *mockRoleRepository, c *mockCacheInvalidator) {
+				m.On("UpdateWithParents", mock.Anything, 2, mock.AnythingOfType("domain.Role"), []int{}).Return(nil)
+				m.On("DescendantIDs", mock.Anything, 2).Return([]int{}, nil)
+				m.On("UserIDsByRoles", mock.Anything, []int{2}).Return([]int{}, nil)
+				c.On("InvalidateUsers", []int{}).Return()
+			},
+		},
+		{
+			name:      "error - cycle detected in the update transaction",
+			roleID:    2,
+			parentIDs: []int{1, 4},
+			mockSetup: func(m *mockRoleRepository, c *mockCacheInvalidator) {
+				m.On("UpdateWithParents", mock.Anything, 2, mock.AnythingOfType("domain.Role"), []int{1, 4}).Return(repository.ErrRoleCycle)
+			},
+			wantErr: service.ErrRoleCycle,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &mockRoleRepository{}
+			mockCache := &mockCacheInvalidator{}
+			tt.mockSetup(mockRepo, mockCache)
+
+			svc := service.NewRoleService(mockRepo, zap.NewNop())
+			svc.SetCacheInvalidator(mockCache)
+
+			err := svc.Update(context.Background(), tt.roleID, dto.RoleUpdateDto{
+				Name:      "Role",
+				Code:      "ROLE",
+				ParentIDs: tt.parentIDs,
+			})
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				mockCache.AssertNotCalled(t, "InvalidateUsers", mock.Anything)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockCache.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRoleService_SetPermissions_InvalidatesDescendantUsers(t *testing.T) {
+	mockRepo := &mockRoleRepository{}
+	mockCache := &mockCacheInvalidator{}
+
+	mockRepo.On("ReplacePermissions", mock.Anything, 1, []int{5}).Return(nil)
+	mockRepo.On("DescendantIDs", mock.Anything, 1).Return([]int{2}, nil)
+	mockRepo.On("UserIDsByRoles", mock.Anything, []int{2, 1}).Return([]int{7}, nil)
+	mockCache.On("InvalidateUsers", []int{7}).Return()
+
+	svc := service.NewRoleService(mockRepo, zap.NewNop())
+	svc.SetCacheInvalidator(mockCache)
+
+	err := svc.SetPermissions(context.Background(), dto.RolePermissionsUpdateDto{RoleID: 1, PermissionIDs: []int{5}})
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+	mockCache.AssertExpectations(t)
+	mockCache.AssertNotCalled(t, "InvalidateAll")
+}
+
+func TestRoleService_SetPermissions_FallsBackToInvalidateAll(t *testing.T) {
+	mockRepo := &mockRoleRepository{}
+	mockCache := &mockCacheInvalidator{}
+
+	mockRepo.On("ReplacePermissions", mock.Anything, 1, []int{5}).Return(nil)
+	mockRepo.On("DescendantIDs", mock.Anything, 1).Return(nil, errors.New("db down"))
+	mockCache.On("InvalidateAll").Return()
+
+	svc := service.NewRoleService(mockRepo, zap.NewNop())
+	svc.SetCacheInvalidator(mockCache)
+
+	err := svc.SetPermissions(context.Background(), dto.RolePermissionsUpdateDto{RoleID: 1, PermissionIDs: []int{5}})
+	assert.NoError(t, err)
+
+	mockCache.AssertExpectations(t)
+}
@@ -0,0 +1,87 @@
+// Package service provides implementation for service
+//
+// File: user_device_service_test.go
+// Description: Unit tests for user device service
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/http/dto"
+	"templatev25/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// mockUserDeviceRepository for testing
+type mockUserDeviceRepository struct {
+	mock.Mock
+}
+
+func (m *mockUserDeviceRepository) ListByUser(ctx context.Context, userID int) ([]domain.UserDevice, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.UserDevice), args.Error(1)
+}
+
+func (m *mockUserDeviceRepository) Register(ctx context.Context, d domain.UserDevice) (domain.UserDevice, error) {
+	args := m.Called(ctx, d)
+	return args.Get(0).(domain.UserDevice), args.Error(1)
+}
+
+func (m *mockUserDeviceRepository) Unregister(ctx context.Context, userID int, deviceID string) error {
+	args := m.Called(ctx, userID, deviceID)
+	return args.Error(0)
+}
+
+func (m *mockUserDeviceRepository) ActivePushDevices(ctx context.Context, userID int) ([]domain.UserDevice, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.UserDevice), args.Error(1)
+}
+
+func (m *mockUserDeviceRepository) Deactivate(ctx context.Context, id int, pushToken string) error {
+	args := m.Called(ctx, id, pushToken)
+	return args.Error(0)
+}
+
+func TestUserDeviceService_Register(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockUserDeviceRepository)
+	repo.On("Register", ctx, domain.UserDevice{
+		UserId:       3,
+		DeviceId:     "phone-1",
+		DeviceType:   "android",
+		PushToken:    "tok",
+		PushProvider: domain.PushProviderFCM,
+	}).Return(domain.UserDevice{Id: 1, IsActive: true}, nil)
+
+	d, err := service.NewUserDeviceService(repo).Register(ctx, 3, dto.UserDeviceRegisterDto{
+		DeviceId:     "phone-1",
+		DeviceType:   "android",
+		PushToken:    "tok",
+		PushProvider: "fcm",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, d.Id)
+	repo.AssertExpectations(t)
+}
+
+func TestUserDeviceService_Unregister_NotFound(t *testing.T) {
+	ctx := context.Background()
+	repo := new(mockUserDeviceRepository)
+	repo.On("Unregister", ctx, 3, "missing").Return(gorm.ErrRecordNotFound)
+
+	err := service.NewUserDeviceService(repo).Unregister(ctx, 3, "missing")
+
+	assert.ErrorIs(t, err, service.ErrDeviceNotFound)
+}
@@ -0,0 +1,150 @@
+// Package service provides implementation for service
+//
+// File: user_settings_service_test.go
+// Description: Unit tests for user settings service
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"templatev25/internal/domain"
+	"templatev25/internal/http/dto"
+	"templatev25/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// mockUserSettingsRepository for testing
+type mockUserSettingsRepository struct {
+	mock.Mock
+	saved []domain.UserSettings
+}
+
+func (m *mockUserSettingsRepository) ByUserID(ctx context.Context, userID int) (domain.UserSettings, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(domain.UserSettings), args.Error(1)
+}
+
+func (m *mockUserSettingsRepository) Upsert(ctx context.Context, s domain.UserSettings) (domain.UserSettings, error) {
+	args := m.Called(ctx, s)
+	return args.Get(0).(domain.UserSettings), args.Error(1)
+}
+
+// Modify applies fn to the row returned by the "Modify" expectation and
+// records the result as the saved value
+func (m *mockUserSettingsRepository) Modify(ctx context.Context, userID int, apply func(s *domain.UserSettings)) (domain.UserSettings, error) {
+	args := m.Called(ctx, userID)
+	row := args.Get(0).(domain.UserSettings)
+	if err := args.Error(1); err != nil {
+		return domain.UserSettings{}, err
+	}
+	apply(&row)
+	m.saved = append(m.saved, row)
+	return row, nil
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func TestUserSettingsService_Get(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("defaults when no row", func(t *testing.T) {
+		repo := new(mockUserSettingsRepository)
+		repo.On("ByUserID", ctx, 3).Return(domain.UserSettings{}, gorm.ErrRecordNotFound)
+
+		m, err := service.NewUserSettingsService(repo).Get(ctx, 3)
+
+		require.NoError(t, err)
+		assert.Equal(t, domain.DefaultUserSettings(3), m)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repo := new(mockUserSettingsRepository)
+		repo.On("ByUserID", ctx, 3).Return(domain.UserSettings{}, errors.New("db error"))
+
+		_, err := service.NewUserSettingsService(repo).Get(ctx, 3)
+		assert.EqualError(t, err, "db error")
+	})
+}
+
+func TestUserSettingsService_Update(t *testing.T) {
+	ctx := context.Background()
+	stored := domain.UserSettings{Id: 1, UserId: 3, NotificationEmail: true, Theme: "dark", Language: "mn", CustomSettings: []byte(`{"page_size": 20}`)}
+
+	t.Run("applies only sent fields to the locked row", func(t *testing.T) {
+		repo := new(mockUserSettingsRepository)
+		repo.On("Modify", ctx, 3).Return(stored, nil)
+
+		m, err := service.NewUserSettingsService(repo).Update(ctx, 3, dto.UserSettingsUpdateDto{
+			Language:          ptr("en"),
+			NotificationEmail: ptr(false),
+			NotificationSms:   ptr(true),
+			CustomSettings:    json.RawMessage(`null`),
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, m.Id)
+		assert.Equal(t, "dark", m.Theme)
+		assert.Equal(t, "en", m.Language)
+		assert.False(t, m.NotificationEmail)
+		assert.True(t, m.NotificationSms)
+		assert.Equal(t, `{"page_size": 20}`, string(m.CustomSettings))
+		repo.AssertExpectations(t)
+		repo.AssertNotCalled(t, "ByUserID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("new row starts from defaults", func(t *testing.T) {
+		repo := new(mockUserSettingsRepository)
+		repo.On("Modify", ctx, 3).Return(domain.DefaultUserSettings(3), nil)
+
+		m, err := service.NewUserSettingsService(repo).Update(ctx, 3, dto.UserSettingsUpdateDto{
+			Theme:          ptr("light"),
+			CustomSettings: json.RawMessage(` {"sidebar_collapsed": true, "accent": "blue"} `),
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "light", m.Theme)
+		assert.True(t, m.NotificationPush)
+		assert.Equal(t, "mn", m.Language)
+		assert.Equal(t, `{"sidebar_collapsed": true, "accent": "blue"}`, string(m.CustomSettings))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repo := new(mockUserSettingsRepository)
+		repo.On("Modify", ctx, 3).Return(domain.UserSettings{}, errors.New("db error"))
+
+		_, err := service.NewUserSettingsService(repo).Update(ctx, 3, dto.UserSettingsUpdateDto{Theme: ptr("light")})
+		assert.EqualError(t, err, "db error")
+		assert.Empty(t, repo.saved)
+	})
+
+	t.Run("invalid custom settings", func(t *testing.T) {
+		tests := map[string]string{
+			"not an object":  `[1, 2]`,
+			"wrong type":     `{"page_size": "20"}`,
+			"nested unknown": `{"accent": {"color": "blue"}}`,
+			"too large":      `{"note": "` + strings.Repeat("x", 17<<10) + `"}`,
+		}
+		for name, raw := range tests {
+			t.Run(name, func(t *testing.T) {
+				repo := new(mockUserSettingsRepository)
+
+				_, err := service.NewUserSettingsService(repo).Update(ctx, 3, dto.UserSettingsUpdateDto{CustomSettings: json.RawMessage(raw)})
+
+				assert.ErrorIs(t, err, service.ErrUserSettingsInvalid)
+				var verr *service.UserSettingsValidationError
+				require.ErrorAs(t, err, &verr)
+				assert.NotEmpty(t, verr.Errors)
+				repo.AssertNotCalled(t, "Modify", mock.Anything, mock.Anything)
+			})
+		}
+	})
+}